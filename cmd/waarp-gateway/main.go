@@ -17,6 +17,7 @@ type commands struct {
 	} `group:"Connection Options" description:"The options defining how to connect to the gateway"`
 
 	Status wg.Status `command:"status" description:"Show the status of the gateway services"`
+	Drain  wg.Drain  `command:"drain" description:"Stop accepting new transfers, and stop the servers & clients once the running transfers are done"`
 
 	Server struct {
 		Add         wg.ServerAdd       `command:"add" description:"Add a new server"`
@@ -31,6 +32,7 @@ type commands struct {
 		Start       wg.ServerStart     `command:"start" description:"Start an offline server"`
 		Stop        wg.ServerStop      `command:"stop" description:"Stop a running server"`
 		Restart     wg.ServerRestart   `command:"restart" description:"Stop and restart a running server"`
+		Drain       wg.ServerDrain     `command:"drain" description:"Stop a running server once its running transfers are done"`
 		Credentials struct {
 			Args struct {
 				Server wg.ServerArg `required:"yes" positional-arg-name:"server" description:"The server's name"`
@@ -56,6 +58,7 @@ type commands struct {
 		Start   wg.ClientStart   `command:"start" description:"Start an offline client"`
 		Stop    wg.ClientStop    `command:"stop" description:"Stop a running client"`
 		Restart wg.ClientRestart `command:"restart" description:"Stop and restart a running client"`
+		Drain   wg.ClientDrain   `command:"drain" description:"Stop a running client once its running transfers are done"`
 	} `command:"client" description:"Manage the gateway's local clients"`
 
 	Partner struct {
@@ -144,11 +147,14 @@ type commands struct {
 		Add    wg.TransferAdd         `command:"add" description:"Add a new client transfer to be executed"`
 		Reg    wg.TransferPreregister `command:"preregister" description:"Register a server transfer"`
 		Get    wg.TransferGet         `command:"get" description:"Consult a transfer"`
+		Upd    wg.TransferUpdate      `command:"update" description:"Modify a planned, paused or failed transfer"`
 		List   wg.TransferList        `command:"list" description:"List the transfers"`
-		Pau    wg.TransferPause       `command:"pause" description:"Pause a running transfer"`
-		Res    wg.TransferResume      `command:"resume" description:"Resume a paused transfer"`
+		Pau    wg.TransferPause       `command:"pause" description:"Pause a running transfer, or all the transfers matching the filters"`
+		Res    wg.TransferResume      `command:"resume" description:"Resume a paused transfer, or all the transfers matching the filters"`
 		Can    wg.TransferCancel      `command:"cancel" description:"Cancel a transfer"`
-		Ret    wg.TransferRetry       `command:"retry" description:"Reprogram a canceled transfer"`
+		Int    wg.TransferInterrupt   `command:"interrupt" description:"Interrupt a running transfer"`
+		Ret    wg.TransferRetry       `command:"retry" description:"Reprogram a canceled transfer, or all the finished transfers matching the filters"`
+		Resch  wg.TransferReschedule  `command:"reschedule" description:"Change the start date of all the transfers matching the filters"`
 		CanAll wg.TransferCancelAll   `command:"cancel-all" description:"Cancel all transfers in the given status"`
 	} `command:"transfer" description:"Manage the running transfers"`
 
@@ -182,6 +188,10 @@ type commands struct {
 		Del  wg.AuthorityDelete `command:"delete" description:"Delete an authority"`
 	} `command:"authority" description:"Manage the authentication authorities"`
 
+	Certificates struct {
+		Expiring wg.CertExpiring `command:"expiring" description:"List the certificates & keys about to expire"`
+	} `command:"certificate" description:"Monitor the expiration of the certificates & keys"`
+
 	SNMP struct {
 		Monitor struct {
 			Add  wg.SnmpMonitorAdd    `command:"add" description:"Add a new SNMP monitor"`
@@ -206,6 +216,14 @@ type commands struct {
 		Del  wg.CloudDelete `command:"delete" description:"Delete an cloud instance"`
 	} `command:"cloud" description:"Manage the gateway's cloud instances"`
 
+	Broker struct {
+		Add  wg.BrokerAdd    `command:"add" description:"Add a new message broker"`
+		Get  wg.BrokerGet    `command:"get" description:"Retrieve a message broker's information"`
+		List wg.BrokerList   `command:"list" description:"List the known message brokers"`
+		Upd  wg.BrokerUpdate `command:"update" description:"Update an existing message broker"`
+		Del  wg.BrokerDelete `command:"delete" description:"Delete a message broker"`
+	} `command:"broker" description:"Manage the gateway's message brokers"`
+
 	Key struct {
 		Add  wg.CryptoKeysAdd    `command:"add" description:"Add a new cryptographic key"`
 		Get  wg.CryptoKeysGet    `command:"get" description:"Retrieve a cryptographic key"`
@@ -242,6 +260,53 @@ type commands struct {
 		} `command:"credential" description:"Manage the SMTP credentials"`
 	} `command:"email" description:"Manage the gateway's email configuration"`
 
+	Script struct {
+		Add  wg.ScriptAdd    `command:"add" description:"Add a new script"`
+		Get  wg.ScriptGet    `command:"get" description:"Retrieve a script"`
+		List wg.ScriptList   `command:"list" description:"List the scripts"`
+		Upd  wg.ScriptUpdate `command:"update" description:"Update an existing script"`
+		Del  wg.ScriptDelete `command:"delete" description:"Delete a script"`
+	} `command:"script" description:"Manage the scripts executed by the SCRIPT task"`
+
+	Copybook struct {
+		Add  wg.CopybookAdd    `command:"add" description:"Add a new COBOL copybook"`
+		Get  wg.CopybookGet    `command:"get" description:"Retrieve a COBOL copybook"`
+		List wg.CopybookList   `command:"list" description:"List the COBOL copybooks"`
+		Upd  wg.CopybookUpdate `command:"update" description:"Update an existing COBOL copybook"`
+		Del  wg.CopybookDelete `command:"delete" description:"Delete a COBOL copybook"`
+	} `command:"copybook" description:"Manage the COBOL copybooks used by the RECFORMAT task"`
+
+	RetryPolicy struct {
+		Add  wg.RetryPolicyAdd    `command:"add" description:"Add a new retry policy"`
+		Get  wg.RetryPolicyGet    `command:"get" description:"Retrieve a retry policy"`
+		List wg.RetryPolicyList   `command:"list" description:"List the retry policies"`
+		Upd  wg.RetryPolicyUpdate `command:"update" description:"Update an existing retry policy"`
+		Del  wg.RetryPolicyDelete `command:"delete" description:"Delete a retry policy"`
+	} `command:"retry-policy" description:"Manage the transfer retry policies"`
+
+	SLA struct {
+		Add      wg.SLAAdd      `command:"add" description:"Add a new SLA"`
+		Get      wg.SLAGet      `command:"get" description:"Retrieve an SLA and its status"`
+		List     wg.SLAList     `command:"list" description:"List the SLAs"`
+		Upd      wg.SLAUpdate   `command:"update" description:"Update an existing SLA"`
+		Del      wg.SLADelete   `command:"delete" description:"Delete an SLA"`
+		Breaches wg.SLABreaches `command:"breaches" description:"List the breaches of an SLA"`
+	} `command:"sla" description:"Manage the transfers' service level agreements"`
+
+	ExpectedFile struct {
+		Add    wg.ExpectedFileAdd    `command:"add" description:"Add a new expected file"`
+		Get    wg.ExpectedFileGet    `command:"get" description:"Retrieve an expected file and its last check"`
+		List   wg.ExpectedFileList   `command:"list" description:"List the expected files"`
+		Upd    wg.ExpectedFileUpdate `command:"update" description:"Update an existing expected file"`
+		Del    wg.ExpectedFileDelete `command:"delete" description:"Delete an expected file"`
+		Checks wg.ExpectedFileChecks `command:"checks" description:"List the checks of an expected file"`
+	} `command:"expected-file" description:"Manage the monitoring of the expected inbound files"`
+
+	Node struct {
+		Get  wg.NodeGet  `command:"get" description:"Retrieve a cluster node's status"`
+		List wg.NodeList `command:"list" description:"List the cluster nodes"`
+	} `command:"node" description:"Monitor the nodes of the gateway cluster"`
+
 	Version wg.Version `command:"version" description:"Print the program version and exit"`
 }
 
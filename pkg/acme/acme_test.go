@@ -0,0 +1,266 @@
+package acme
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	acmelib "golang.org/x/crypto/acme"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/database/dbtest"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging/logtest"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/authentication/auth"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/modeltest"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/types"
+)
+
+const testProtocol = "test_proto"
+
+//nolint:gochecknoinits // init is used to ease the tests
+func init() {
+	modeltest.AddDummyProtoConfig(testProtocol)
+}
+
+// testIssuer issues self-signed certificates.
+type testIssuer struct {
+	validity time.Duration
+	issued   int
+}
+
+func (t *testIssuer) issue(_ context.Context, domains []string) (string, error) {
+	t.issued++
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return "", err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(int64(t.issued)),
+		Subject:      pkix.Name{CommonName: domains[0]},
+		DNSNames:     domains,
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(t.validity),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return "", err
+	}
+
+	return encodeCertAndKey([][]byte{der}, key)
+}
+
+func makeTestCert(tb testing.TB, validity time.Duration, domains ...string) *x509.Certificate {
+	tb.Helper()
+
+	certAndKey, err := (&testIssuer{validity: validity}).issue(context.Background(), domains)
+	require.NoError(tb, err)
+
+	cert, err := auth.ParseACMECertificate(certAndKey)
+	require.NoError(tb, err)
+
+	return cert.Leaf
+}
+
+func TestNeedsRenewal(t *testing.T) {
+	t.Parallel()
+
+	const renewBefore = 30 * 24 * time.Hour
+
+	valid := makeTestCert(t, 90*24*time.Hour, "gw.example.com", "ftp.example.com")
+	expiring := makeTestCert(t, 10*24*time.Hour, "gw.example.com")
+
+	assert.True(t, needsRenewal(nil, []string{"gw.example.com"}, renewBefore),
+		"a missing certificate should be renewed")
+	assert.True(t, needsRenewal(expiring, []string{"gw.example.com"}, renewBefore),
+		"an expiring certificate should be renewed")
+	assert.True(t, needsRenewal(valid, []string{"gw.example.com"}, renewBefore),
+		"a certificate with different domains should be renewed")
+	assert.False(t, needsRenewal(valid, []string{"FTP.example.com", "gw.example.com"}, renewBefore),
+		"a valid certificate should not be renewed")
+}
+
+func TestChallenges(t *testing.T) {
+	t.Parallel()
+
+	t.Run("TLS-ALPN-01", func(t *testing.T) {
+		t.Parallel()
+
+		cert := tls.Certificate{Certificate: [][]byte{[]byte("challenge")}}
+		cleanup := challenges.addTLSALPN("alpn.example.com", &cert)
+
+		hello := &tls.ClientHelloInfo{
+			ServerName:      "ALPN.example.com",
+			SupportedProtos: []string{acmelib.ALPNProto},
+		}
+
+		config := GetChallengeConfig(hello)
+		require.NotNil(t, config)
+		assert.Equal(t, []string{acmelib.ALPNProto}, config.NextProtos)
+		assert.Equal(t, cert.Certificate, config.Certificates[0].Certificate)
+
+		assert.Nil(t, GetChallengeConfig(&tls.ClientHelloInfo{ServerName: "alpn.example.com"}),
+			"then regular handshakes should not be answered")
+
+		cleanup()
+		assert.Nil(t, GetChallengeConfig(hello), "then the challenge should be removed")
+	})
+
+	t.Run("HTTP-01", func(t *testing.T) {
+		t.Parallel()
+
+		const path = "/.well-known/acme-challenge/token"
+
+		cleanup := challenges.addHTTP(path, "token.thumbprint")
+		defer cleanup()
+
+		rec := httptest.NewRecorder()
+		HTTPChallengeHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "token.thumbprint", rec.Body.String())
+
+		rec = httptest.NewRecorder()
+		HTTPChallengeHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet,
+			"/.well-known/acme-challenge/unknown", nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestRenewServers(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+	db.Config.ACME.RenewBefore = 30 * 24 * time.Hour
+
+	server := &model.LocalAgent{
+		Name: "server", Protocol: testProtocol,
+		Address: types.Addr("localhost", 443),
+	}
+	require.NoError(t, db.Insert(server).Run())
+
+	cred := &model.Credential{
+		LocalAgentID: server.NullableID(),
+		Name:         "acme-cert",
+		Type:         auth.ACMECertificate,
+		Value:        "gw.example.com, ftp.example.com",
+	}
+	require.NoError(t, db.Insert(cred).Run())
+
+	issuer := &testIssuer{validity: 90 * 24 * time.Hour}
+	service := &Service{DB: db, issuer: issuer, logger: logtest.GetTestLogger(t)}
+
+	t.Run("When the certificate has not been issued yet", func(t *testing.T) {
+		service.renewServers(context.Background())
+		require.Equal(t, 1, issuer.issued)
+
+		var dbCred model.Credential
+		require.NoError(t, db.Get(&dbCred, "id=?", cred.ID).Run())
+
+		cert, err := auth.ParseACMECertificate(dbCred.Value2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"gw.example.com", "ftp.example.com"}, cert.Leaf.DNSNames)
+
+		var rawValue2 string
+		require.NoError(t, db.QueryRow("SELECT value2 FROM credentials WHERE id=?",
+			cred.ID).Scan(&rawValue2))
+		assert.NotContains(t, rawValue2, "PRIVATE KEY",
+			"then the private key should be encrypted in database")
+	})
+
+	t.Run("When the certificate is still valid", func(t *testing.T) {
+		service.renewServers(context.Background())
+		assert.Equal(t, 1, issuer.issued, "then the certificate should not be renewed")
+	})
+
+	t.Run("When the certificate is about to expire", func(t *testing.T) {
+		db.Config.ACME.RenewBefore = 100 * 24 * time.Hour
+
+		service.renewServers(context.Background())
+		assert.Equal(t, 2, issuer.issued, "then the certificate should be renewed")
+	})
+}
+
+func TestRenewAdmin(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+	db.Config.ACME.RenewBefore = 30 * 24 * time.Hour
+	db.Config.Admin.ACMEDomains = []string{"admin.example.com"}
+	db.Config.Admin.TLSCert = filepath.Join(t.TempDir(), "admin.crt")
+	db.Config.Admin.TLSKey = filepath.Join(t.TempDir(), "admin.key")
+
+	issuer := &testIssuer{validity: 90 * 24 * time.Hour}
+	service := &Service{DB: db, issuer: issuer, logger: logtest.GetTestLogger(t)}
+
+	service.renewAdmin(context.Background())
+	require.Equal(t, 1, issuer.issued)
+
+	cert, err := tls.LoadX509KeyPair(db.Config.Admin.TLSCert, db.Config.Admin.TLSKey)
+	require.NoError(t, err, "then the certificate files should have been written")
+	assert.Equal(t, []string{"admin.example.com"}, cert.Leaf.DNSNames)
+
+	service.renewAdmin(context.Background())
+	assert.Equal(t, 1, issuer.issued, "then a valid certificate should not be renewed")
+}
+
+func TestACMECredentialValidation(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+
+	partner := &model.RemoteAgent{
+		Name: "partner", Protocol: testProtocol,
+		Address: types.Addr("localhost", 443),
+	}
+	require.NoError(t, db.Insert(partner).Run())
+
+	account := &model.RemoteAccount{RemoteAgentID: partner.ID, Login: "toto"}
+	require.NoError(t, db.Insert(account).Run())
+
+	server := &model.LocalAgent{
+		Name: "server", Protocol: testProtocol,
+		Address: types.Addr("localhost", 443),
+	}
+	require.NoError(t, db.Insert(server).Run())
+
+	t.Run("Given an account credential", func(t *testing.T) {
+		t.Parallel()
+
+		err := db.Insert(&model.Credential{
+			RemoteAccountID: account.NullableID(),
+			Type:            auth.ACMECertificate,
+			Value:           "gw.example.com",
+		}).Run()
+
+		var valErr *database.ValidationError
+		require.ErrorAs(t, err, &valErr)
+		require.ErrorIs(t, err, auth.ErrACMENotServer)
+	})
+
+	t.Run("Given a credential without domains", func(t *testing.T) {
+		t.Parallel()
+
+		err := db.Insert(&model.Credential{
+			LocalAgentID: server.NullableID(),
+			Type:         auth.ACMECertificate,
+			Value:        " , ",
+		}).Run()
+		require.ErrorIs(t, err, auth.ErrACMENoDomain)
+	})
+}
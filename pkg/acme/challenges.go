@@ -0,0 +1,106 @@
+// Package acme implements the automatic management of the gateway's TLS
+// certificates using the ACME protocol (RFC 8555). Certificates are obtained
+// and renewed by the ACME Service, and the challenges sent by the ACME server
+// are answered by the gateway itself, either by the gateway's TLS servers
+// (TLS-ALPN-01) or by a dedicated HTTP listener (HTTP-01).
+package acme
+
+import (
+	"crypto/tls"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	acmelib "golang.org/x/crypto/acme"
+)
+
+const (
+	challengeHTTP01    = "http-01"
+	challengeTLSALPN01 = "tls-alpn-01"
+)
+
+//nolint:gochecknoglobals //a global var is needed since challenges are served by all TLS servers
+var challenges = &challengeStore{
+	tlsALPN: map[string]*tls.Certificate{},
+	http:    map[string]string{},
+}
+
+// challengeStore holds the responses to the pending ACME challenges.
+type challengeStore struct {
+	mx      sync.RWMutex
+	tlsALPN map[string]*tls.Certificate // indexed by domain name
+	http    map[string]string           // indexed by URL path
+}
+
+func (c *challengeStore) addTLSALPN(domain string, cert *tls.Certificate) func() {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	c.tlsALPN[domain] = cert
+
+	return func() {
+		c.mx.Lock()
+		defer c.mx.Unlock()
+
+		delete(c.tlsALPN, domain)
+	}
+}
+
+func (c *challengeStore) addHTTP(path, response string) func() {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	c.http[path] = response
+
+	return func() {
+		c.mx.Lock()
+		defer c.mx.Unlock()
+
+		delete(c.http, path)
+	}
+}
+
+// GetChallengeConfig returns the TLS configuration answering the pending
+// TLS-ALPN-01 challenge for the given client hello, or nil if the client hello
+// is not an ACME challenge request. It should be called by all the TLS servers
+// of the gateway (typically in their tls.Config.GetConfigForClient function)
+// so that any of them can answer the challenges.
+func GetChallengeConfig(hello *tls.ClientHelloInfo) *tls.Config {
+	if !slices.Equal(hello.SupportedProtos, []string{acmelib.ALPNProto}) {
+		return nil
+	}
+
+	challenges.mx.RLock()
+	cert, ok := challenges.tlsALPN[strings.ToLower(hello.ServerName)]
+	challenges.mx.RUnlock()
+
+	if !ok {
+		return nil
+	}
+
+	return &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{*cert},
+		NextProtos:   []string{acmelib.ALPNProto},
+	}
+}
+
+// HTTPChallengeHandler returns an HTTP handler answering the pending HTTP-01
+// challenges.
+func HTTPChallengeHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		challenges.mx.RLock()
+		response, ok := challenges.http[r.URL.Path]
+		challenges.mx.RUnlock()
+
+		if !ok {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(response)) //nolint:errcheck //nothing to do if the write fails
+	})
+}
@@ -0,0 +1,275 @@
+package acme
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	acmelib "golang.org/x/crypto/acme"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/conf"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils"
+	"code.waarp.fr/apps/gateway/gateway/pkg/version"
+)
+
+var (
+	ErrUnsupportedChallenge = errors.New("the ACME server does not offer the required challenge")
+	errBadAccountKey        = errors.New("the ACME account key file does not contain a valid EC private key")
+	errBadCACert            = errors.New("failed to parse the ACME CA certificate")
+)
+
+// issuer is the interface of the objects capable of issuing certificates.
+type issuer interface {
+	// issue returns a new certificate chain & private key (in PEM format)
+	// valid for the given domain names.
+	issue(ctx context.Context, domains []string) (string, error)
+}
+
+// client is an ACME client issuing certificates using the ACME server defined
+// in the configuration.
+type client struct {
+	*acmelib.Client
+
+	challenge string
+	email     string
+
+	registerOnce sync.Once
+	registerErr  error
+}
+
+func newClient(config *conf.ACMEConfig) (*client, error) {
+	key, err := loadAccountKey(config.AccountKey)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient := &http.Client{Timeout: defaultRequestTimeout}
+
+	if config.CACert != "" {
+		pemCert, readErr := os.ReadFile(filepath.Clean(config.CACert))
+		if readErr != nil {
+			return nil, fmt.Errorf("failed to read the ACME CA certificate: %w", readErr)
+		}
+
+		roots := utils.TLSCertPool()
+		if !roots.AppendCertsFromPEM(pemCert) {
+			return nil, errBadCACert
+		}
+
+		httpClient.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12},
+		}
+	}
+
+	cli := &client{
+		Client: &acmelib.Client{
+			Key:          key,
+			DirectoryURL: config.DirectoryURL,
+			HTTPClient:   httpClient,
+			UserAgent:    "waarp-gateway/" + version.Num,
+		},
+		challenge: challengeTLSALPN01,
+		email:     config.Email,
+	}
+
+	if config.HTTPChallengeAddress != "" {
+		cli.challenge = challengeHTTP01
+	}
+
+	return cli, nil
+}
+
+// loadAccountKey loads the ACME account key from the given file. If the file
+// does not exist, a new key is generated and written to it.
+func loadAccountKey(filename string) (crypto.Signer, error) {
+	content, readErr := os.ReadFile(filepath.Clean(filename))
+	if errors.Is(readErr, os.ErrNotExist) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate the ACME account key: %w", err)
+		}
+
+		der, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize the ACME account key: %w", err)
+		}
+
+		block := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+		if err := os.WriteFile(filename, block, keyFilePerms); err != nil {
+			return nil, fmt.Errorf("failed to write the ACME account key file: %w", err)
+		}
+
+		return key, nil
+	} else if readErr != nil {
+		return nil, fmt.Errorf("failed to read the ACME account key file: %w", readErr)
+	}
+
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, errBadAccountKey
+	}
+
+	key, err := x509.ParseECPrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errBadAccountKey, err)
+	}
+
+	return key, nil
+}
+
+// register registers the ACME account (if it does not already exist).
+func (c *client) register(ctx context.Context) error {
+	c.registerOnce.Do(func() {
+		account := &acmelib.Account{}
+		if c.email != "" {
+			account.Contact = []string{"mailto:" + c.email}
+		}
+
+		if _, err := c.Register(ctx, account, acmelib.AcceptTOS); err != nil &&
+			!errors.Is(err, acmelib.ErrAccountAlreadyExists) {
+			c.registerErr = fmt.Errorf("failed to register the ACME account: %w", err)
+		}
+	})
+
+	return c.registerErr
+}
+
+func (c *client) issue(ctx context.Context, domains []string) (string, error) {
+	if err := c.register(ctx); err != nil {
+		return "", err
+	}
+
+	order, err := c.AuthorizeOrder(ctx, acmelib.DomainIDs(domains...))
+	if err != nil {
+		return "", fmt.Errorf("failed to create the ACME order: %w", err)
+	}
+
+	for _, authzURL := range order.AuthzURLs {
+		if err := c.authorize(ctx, authzURL); err != nil {
+			return "", err
+		}
+	}
+
+	if order, err = c.WaitOrder(ctx, order.URI); err != nil {
+		return "", fmt.Errorf("failed to wait for the ACME order: %w", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate the certificate key: %w", err)
+	}
+
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: domains[0]},
+		DNSNames: domains,
+	}, key)
+	if err != nil {
+		return "", fmt.Errorf("failed to create the certificate request: %w", err)
+	}
+
+	chain, _, err := c.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
+	if err != nil {
+		return "", fmt.Errorf("failed to finalize the ACME order: %w", err)
+	}
+
+	return encodeCertAndKey(chain, key)
+}
+
+// authorize completes the ACME authorization at the given URL by answering one
+// of its challenges.
+func (c *client) authorize(ctx context.Context, authzURL string) error {
+	authz, err := c.GetAuthorization(ctx, authzURL)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve the ACME authorization: %w", err)
+	}
+
+	if authz.Status == acmelib.StatusValid {
+		return nil
+	}
+
+	domain := strings.ToLower(authz.Identifier.Value)
+
+	var chal *acmelib.Challenge
+
+	for _, candidate := range authz.Challenges {
+		if candidate.Type == c.challenge {
+			chal = candidate
+
+			break
+		}
+	}
+
+	if chal == nil {
+		return fmt.Errorf("%w %q for %q", ErrUnsupportedChallenge, c.challenge, domain)
+	}
+
+	cleanup, err := c.prepareChallenge(domain, chal)
+	if err != nil {
+		return err
+	}
+
+	defer cleanup()
+
+	if _, err := c.Accept(ctx, chal); err != nil {
+		return fmt.Errorf("failed to accept the %s challenge for %q: %w", chal.Type, domain, err)
+	}
+
+	if _, err := c.WaitAuthorization(ctx, authz.URI); err != nil {
+		return fmt.Errorf("the %s challenge for %q failed: %w", chal.Type, domain, err)
+	}
+
+	return nil
+}
+
+func (c *client) prepareChallenge(domain string, chal *acmelib.Challenge) (func(), error) {
+	switch chal.Type {
+	case challengeTLSALPN01:
+		cert, err := c.TLSALPN01ChallengeCert(chal.Token, domain)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the TLS-ALPN-01 challenge certificate: %w", err)
+		}
+
+		return challenges.addTLSALPN(domain, &cert), nil
+	case challengeHTTP01:
+		response, err := c.HTTP01ChallengeResponse(chal.Token)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the HTTP-01 challenge response: %w", err)
+		}
+
+		return challenges.addHTTP(c.HTTP01ChallengePath(chal.Token), response), nil
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnsupportedChallenge, chal.Type)
+	}
+}
+
+// encodeCertAndKey returns the given DER certificate chain and private key
+// encoded in PEM format.
+func encodeCertAndKey(chain [][]byte, key crypto.PrivateKey) (string, error) {
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize the certificate key: %w", err)
+	}
+
+	var builder strings.Builder
+
+	for _, der := range chain {
+		builder.Write(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	}
+
+	builder.Write(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}))
+
+	return builder.String(), nil
+}
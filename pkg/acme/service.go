@@ -0,0 +1,334 @@
+package acme
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/conf"
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/authentication/auth"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils"
+)
+
+const ServiceName = "ACME"
+
+const (
+	defaultRequestTimeout = 30 * time.Second
+	issueTimeout          = 5 * time.Minute
+
+	keyFilePerms  = 0o600
+	certFilePerms = 0o644
+)
+
+var ErrAdminNoCertFile = errors.New(
+	"the admin TLSCert & TLSKey options are required to store the admin ACME certificate")
+
+// Service is the service responsible for obtaining (and renewing) the ACME
+// certificates of the gateway's servers, and of the admin interface.
+//
+// The servers' certificates are stored in the "acme" credentials of these
+// servers. Since the servers fetch their certificates from the database on each
+// TLS handshake, a renewed certificate is used immediately, without restarting
+// the server. The admin interface's certificate is written to the TLSCert and
+// TLSKey files of the admin configuration, which are then reloaded by the admin
+// service.
+type Service struct {
+	DB *database.DB
+
+	logger     *log.Logger
+	state      utils.State
+	issuer     issuer
+	httpServer *http.Server
+	cancel     context.CancelFunc
+	done       chan struct{}
+}
+
+func (s *Service) Name() string { return ServiceName }
+
+func (s *Service) Start() error {
+	if s.state.IsRunning() {
+		return nil
+	}
+
+	s.logger = logging.NewLogger(ServiceName)
+
+	config := &s.DB.Config.ACME
+	if config.DirectoryURL == "" {
+		s.logger.Info("No ACME server configured, automatic certificate management is disabled.")
+
+		return nil
+	}
+
+	if err := s.start(config); err != nil {
+		s.logger.Errorf("Failed to start service: %v", err)
+		s.state.Set(utils.StateError, err.Error())
+
+		return err
+	}
+
+	s.state.Set(utils.StateRunning, "")
+
+	return nil
+}
+
+func (s *Service) start(config *conf.ACMEConfig) error {
+	if admin := &s.DB.Config.Admin; len(admin.ACMEDomains) != 0 &&
+		(admin.TLSCert == "" || admin.TLSKey == "") {
+		return ErrAdminNoCertFile
+	}
+
+	if s.issuer == nil {
+		cli, err := newClient(config)
+		if err != nil {
+			return err
+		}
+
+		s.issuer = cli
+	}
+
+	if config.HTTPChallengeAddress != "" {
+		list, err := net.Listen("tcp", config.HTTPChallengeAddress)
+		if err != nil {
+			return fmt.Errorf("failed to start the HTTP-01 challenge listener: %w", err)
+		}
+
+		s.httpServer = &http.Server{
+			Handler:           HTTPChallengeHandler(),
+			ErrorLog:          s.logger.AsStdLogger(log.LevelError),
+			ReadHeaderTimeout: time.Second,
+		}
+
+		go func() {
+			if err := s.httpServer.Serve(list); !errors.Is(err, http.ErrServerClosed) {
+				s.logger.Errorf("Unexpected error from the HTTP-01 challenge listener: %v", err)
+			}
+		}()
+	}
+
+	var ctx context.Context
+
+	ctx, s.cancel = context.WithCancel(context.Background())
+	s.done = make(chan struct{})
+
+	go s.run(ctx, config.CheckInterval)
+
+	return nil
+}
+
+func (s *Service) run(ctx context.Context, interval time.Duration) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.renewServers(ctx)
+		s.renewAdmin(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) Stop(ctx context.Context) error {
+	if !s.state.IsRunning() {
+		return utils.ErrNotRunning
+	}
+
+	s.cancel()
+
+	select {
+	case <-s.done:
+	case <-ctx.Done():
+		return fmt.Errorf("failed to stop the ACME service: %w", ctx.Err())
+	}
+
+	if s.httpServer != nil {
+		if err := s.httpServer.Shutdown(ctx); err != nil {
+			s.logger.Errorf("Failed to stop the HTTP-01 challenge listener: %v", err)
+			s.state.Set(utils.StateError, err.Error())
+
+			return fmt.Errorf("failed to stop the HTTP-01 challenge listener: %w", err)
+		}
+	}
+
+	s.state.Set(utils.StateOffline, "")
+
+	return nil
+}
+
+func (s *Service) State() (utils.StateCode, string) { return s.state.Get() }
+
+func (s *Service) issue(ctx context.Context, domains []string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, issueTimeout)
+	defer cancel()
+
+	return s.issuer.issue(ctx, domains)
+}
+
+// renewServers obtains a new certificate for all the servers' ACME credentials
+// which do not have a certificate yet, or whose certificate is about to expire.
+func (s *Service) renewServers(ctx context.Context) {
+	var creds model.Credentials
+	if err := s.DB.Select(&creds).Where("type=? AND local_agent_id IS NOT NULL",
+		auth.ACMECertificate).Run(); err != nil {
+		s.logger.Errorf("Failed to retrieve the ACME credentials: %v", err)
+
+		return
+	}
+
+	for _, cred := range creds {
+		if ctx.Err() != nil {
+			return
+		}
+
+		domains := auth.ParseACMEDomains(cred.Value)
+
+		var current *x509.Certificate
+
+		if cred.Value2 != "" {
+			if cert, err := auth.ParseACMECertificate(cred.Value2); err == nil {
+				current = cert.Leaf
+			}
+		}
+
+		if !needsRenewal(current, domains, s.DB.Config.ACME.RenewBefore) {
+			continue
+		}
+
+		certAndKey, err := s.issue(ctx, domains)
+		if err != nil {
+			s.logger.Errorf("Failed to obtain the %q certificate: %v", cred.Name, err)
+
+			continue
+		}
+
+		cred.Value2 = certAndKey
+		if err := s.DB.Update(cred).Run(); err != nil {
+			s.logger.Errorf("Failed to store the %q certificate: %v", cred.Name, err)
+
+			continue
+		}
+
+		s.logger.Infof("Obtained a new %q certificate for %s", cred.Name,
+			strings.Join(domains, ", "))
+	}
+}
+
+// renewAdmin obtains a new certificate for the admin interface if its current
+// certificate is missing, or is about to expire.
+func (s *Service) renewAdmin(ctx context.Context) {
+	config := &s.DB.Config.Admin
+	if len(config.ACMEDomains) == 0 || ctx.Err() != nil {
+		return
+	}
+
+	var current *x509.Certificate
+
+	if content, err := os.ReadFile(filepath.Clean(config.TLSCert)); err == nil {
+		if block, _ := pem.Decode(content); block != nil {
+			current, _ = x509.ParseCertificate(block.Bytes) //nolint:errcheck //invalid certificates are renewed
+		}
+	}
+
+	if !needsRenewal(current, config.ACMEDomains, s.DB.Config.ACME.RenewBefore) {
+		return
+	}
+
+	certAndKey, err := s.issue(ctx, config.ACMEDomains)
+	if err != nil {
+		s.logger.Errorf("Failed to obtain the admin interface's certificate: %v", err)
+
+		return
+	}
+
+	if err := writeCertFiles(certAndKey, config.TLSCert, config.TLSKey); err != nil {
+		s.logger.Errorf("Failed to write the admin interface's certificate: %v", err)
+
+		return
+	}
+
+	s.logger.Infof("Obtained a new admin interface certificate for %s",
+		strings.Join(config.ACMEDomains, ", "))
+}
+
+// needsRenewal returns whether the given certificate must be renewed, either
+// because it is about to expire, or because its domain names do not match the
+// given ones.
+func needsRenewal(cert *x509.Certificate, domains []string, renewBefore time.Duration) bool {
+	if cert == nil || time.Until(cert.NotAfter) < renewBefore {
+		return true
+	}
+
+	normalize := func(names []string) []string {
+		res := make([]string, len(names))
+		for i, name := range names {
+			res[i] = strings.ToLower(name)
+		}
+
+		slices.Sort(res)
+
+		return slices.Compact(res)
+	}
+
+	return !slices.Equal(normalize(cert.DNSNames), normalize(domains))
+}
+
+// writeCertFiles writes the certificate chain and the private key contained in
+// the given PEM string to the given files.
+func writeCertFiles(certAndKey, certFile, keyFile string) error {
+	var certPEM, keyPEM []byte
+
+	rest := []byte(certAndKey)
+
+	for {
+		var block *pem.Block
+		if block, rest = pem.Decode(rest); block == nil {
+			break
+		}
+
+		if block.Type == "CERTIFICATE" {
+			certPEM = append(certPEM, pem.EncodeToMemory(block)...)
+		} else {
+			keyPEM = append(keyPEM, pem.EncodeToMemory(block)...)
+		}
+	}
+
+	// The key is written first, since the certificate file's modification
+	// is what triggers the reload of the admin certificate.
+	if err := writeFileAtomic(keyFile, keyPEM, keyFilePerms); err != nil {
+		return err
+	}
+
+	return writeFileAtomic(certFile, certPEM, certFilePerms)
+}
+
+func writeFileAtomic(filename string, content []byte, perms os.FileMode) error {
+	tmp := filename + ".tmp"
+
+	if err := os.WriteFile(tmp, content, perms); err != nil {
+		return fmt.Errorf("failed to write file %q: %w", tmp, err)
+	}
+
+	if err := os.Rename(tmp, filename); err != nil {
+		return fmt.Errorf("failed to replace file %q: %w", filename, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package admin
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/acme"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
+)
+
+var errNoACMECert = errors.New("the admin ACME certificate has not been issued yet")
+
+// makeACMETLSConfig returns the TLS configuration of the admin interface when
+// its certificate is managed by the ACME service. The certificate is reloaded
+// from the TLSCert & TLSKey files whenever the ACME service renews it, and
+// TLS-ALPN-01 challenges are answered by the admin interface.
+func (s *Server) makeACMETLSConfig() (*tls.Config, error) {
+	config := &s.DB.Config.Admin
+	if config.TLSCert == "" || config.TLSKey == "" {
+		return nil, acme.ErrAdminNoCertFile
+	}
+
+	reloader := &certReloader{
+		certFile: config.TLSCert,
+		keyFile:  config.TLSKey,
+		logger:   s.logger,
+	}
+
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			return acme.GetChallengeConfig(hello), nil
+		},
+		GetCertificate: reloader.getCertificate,
+	}, nil
+}
+
+// certReloader loads a TLS certificate from a pair of files, and reloads it
+// whenever the certificate file is modified.
+type certReloader struct {
+	certFile, keyFile string
+	logger            *log.Logger
+
+	mx      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+func (c *certReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	info, statErr := os.Stat(c.certFile)
+	if statErr != nil {
+		if c.cert != nil {
+			return c.cert, nil
+		}
+
+		if errors.Is(statErr, os.ErrNotExist) {
+			return nil, errNoACMECert
+		}
+
+		return nil, fmt.Errorf("failed to read the admin certificate: %w", statErr)
+	}
+
+	if c.cert != nil && info.ModTime().Equal(c.modTime) {
+		return c.cert, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		c.logger.Errorf("Failed to load the admin certificate: %v", err)
+
+		if c.cert != nil {
+			return c.cert, nil
+		}
+
+		return nil, fmt.Errorf("failed to load the admin certificate: %w", err)
+	}
+
+	c.cert, c.modTime = &cert, info.ModTime()
+
+	return c.cert, nil
+}
@@ -134,7 +134,14 @@ func initServer(serv *Server) error {
 
 	var tlsConfig *tls.Config
 
-	if serv.DB.Config.Admin.TLSCert != "" {
+	if len(config.ACMEDomains) != 0 {
+		var err error
+		if tlsConfig, err = serv.makeACMETLSConfig(); err != nil {
+			serv.logger.Errorf("Failed to make TLS configuration: %v", err)
+
+			return err
+		}
+	} else if config.TLSCert != "" {
 		var err error
 		if tlsConfig, err = serv.makeTLSConfig(); err != nil {
 			serv.logger.Errorf("Failed to make TLS configuration: %v", err)
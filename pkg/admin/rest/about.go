@@ -6,6 +6,7 @@ import (
 	"code.waarp.fr/apps/gateway/gateway/pkg/admin/rest/api"
 	"code.waarp.fr/apps/gateway/gateway/pkg/gatewayd/services"
 	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
+	"code.waarp.fr/apps/gateway/gateway/pkg/pipeline"
 )
 
 func makeAbout(logger *log.Logger) http.HandlerFunc {
@@ -26,8 +27,8 @@ func makeAbout(logger *log.Logger) http.HandlerFunc {
 			})
 		}
 
-		services.Servers.Range(func(_ int64, serv services.Server) bool {
-			code, reason := serv.State()
+		services.Servers.Range(func(id int64, serv services.Server) bool {
+			code, reason := pipeline.DrainedState(serv, pipeline.List.IsServerDraining(id))
 
 			servers = append(servers, api.Service{
 				Name:   serv.Name(),
@@ -38,8 +39,8 @@ func makeAbout(logger *log.Logger) http.HandlerFunc {
 			return true
 		})
 
-		services.Clients.Range(func(_ int64, cli services.Client) bool {
-			code, reason := cli.State()
+		services.Clients.Range(func(id int64, cli services.Client) bool {
+			code, reason := pipeline.DrainedState(cli, pipeline.List.IsClientDraining(id))
 
 			clients = append(clients, api.Service{
 				Name:   cli.Name(),
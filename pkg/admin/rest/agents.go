@@ -67,6 +67,7 @@ func restPartnerToDB(restPartner *api.InPartner) (*model.RemoteAgent, error) {
 		Name:        restPartner.Name.Value,
 		Protocol:    restPartner.Protocol.Value,
 		ProtoConfig: model.Map[any](restPartner.ProtoConfig),
+		RetryPolicy: restPartner.RetryPolicy.Value,
 	}
 
 	if err := dbPartner.Address.Set(restPartner.Address.Value); err != nil {
@@ -158,6 +159,7 @@ func DBPartnerToREST(db database.ReadAccess, dbPartner *model.RemoteAgent) (*api
 		Credentials:     credentials,
 		ProtoConfig:     dbPartner.ProtoConfig,
 		AuthorizedRules: authorizedRules,
+		RetryPolicy:     dbPartner.RetryPolicy,
 	}, nil
 }
 
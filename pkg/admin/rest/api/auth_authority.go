@@ -5,6 +5,10 @@ type InAuthority struct {
 	Type           Nullable[string] `json:"type,omitzero" yaml:"type,omitempty"`
 	PublicIdentity Nullable[string] `json:"publicIdentity,omitzero" yaml:"publicIdentity,omitempty"`
 	ValidHosts     []string         `json:"validHosts,omitempty" yaml:"validHosts,omitempty"`
+
+	RevocationPolicy Nullable[string] `json:"revocationPolicy,omitzero" yaml:"revocationPolicy,omitempty"`
+	CRLURL           Nullable[string] `json:"crlURL,omitzero" yaml:"crlURL,omitempty"`
+	OCSPURL          Nullable[string] `json:"ocspURL,omitzero" yaml:"ocspURL,omitempty"`
 }
 
 type OutAuthority struct {
@@ -12,4 +16,8 @@ type OutAuthority struct {
 	Type           string   `json:"type" yaml:"type"`
 	PublicIdentity string   `json:"publicIdentity" yaml:"publicIdentity"`
 	ValidHosts     []string `json:"validHosts" yaml:"validHosts"`
+
+	RevocationPolicy string `json:"revocationPolicy,omitempty" yaml:"revocationPolicy,omitempty"`
+	CRLURL           string `json:"crlURL,omitempty" yaml:"crlURL,omitempty"`
+	OCSPURL          string `json:"ocspURL,omitempty" yaml:"ocspURL,omitempty"`
 }
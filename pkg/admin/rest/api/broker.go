@@ -0,0 +1,31 @@
+package api
+
+type GetBrokerRespObject struct {
+	Name    string            `json:"name" yaml:"name"`
+	Type    string            `json:"type,omitempty" yaml:"type,omitempty"`
+	Address string            `json:"address,omitempty" yaml:"address,omitempty"`
+	Login   string            `json:"login,omitempty" yaml:"login,omitempty"`
+	Options map[string]string `json:"options,omitempty" yaml:"options,omitempty"`
+}
+
+type PostBrokerReqObject struct {
+	Name     string        `json:"name,omitempty" yaml:"name,omitempty"`
+	Type     string        `json:"type,omitempty" yaml:"type,omitempty"`
+	Address  string        `json:"address,omitempty" yaml:"address,omitempty"`
+	Login    string        `json:"login,omitempty" yaml:"login,omitempty"`
+	Password string        `json:"password,omitempty" yaml:"password,omitempty"`
+	Options  BrokerOptions `json:"options,omitempty" yaml:"options,omitempty"`
+}
+
+type PatchBrokerReqObject struct {
+	Name     string           `json:"name,omitempty" yaml:"name,omitempty"`
+	Type     string           `json:"type,omitempty" yaml:"type,omitempty"`
+	Address  string           `json:"address,omitempty" yaml:"address,omitempty"`
+	Login    Nullable[string] `json:"login,omitzero" yaml:"login,omitempty"`
+	Password Nullable[string] `json:"password,omitzero" yaml:"password,omitempty"`
+	Options  BrokerOptions    `json:"options,omitempty" yaml:"options,omitempty"`
+}
+
+// BrokerOptions is the map of a message broker's options. Like for cloud
+// instances, booleans and numbers are accepted and converted to strings.
+type BrokerOptions = CloudConfig
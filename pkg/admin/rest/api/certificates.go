@@ -0,0 +1,14 @@
+package api
+
+import "time"
+
+// GetExpiringCertRespObject is the JSON representation of a certificate (or
+// key) which is about to expire, as returned by the REST API.
+type GetExpiringCertRespObject struct {
+	Kind      string    `json:"kind" yaml:"kind"`
+	Owner     string    `json:"owner,omitempty" yaml:"owner,omitempty"`
+	Name      string    `json:"name" yaml:"name"`
+	Type      string    `json:"type" yaml:"type"`
+	ExpiresAt time.Time `json:"expiresAt" yaml:"expiresAt"`
+	DaysLeft  int       `json:"daysLeft" yaml:"daysLeft"`
+}
@@ -0,0 +1,7 @@
+package api
+
+type (
+	GetCopybookObject   = GetStoredTextObject
+	PostCopybookObject  = PostStoredTextObject
+	PatchCopybookObject = PatchStoredTextObject
+)
@@ -0,0 +1,47 @@
+package api
+
+import "time"
+
+// InExpectedFile represents the JSON body for creating or partially updating
+// an expected file via the REST API. All fields are nullable so that a PATCH
+// request only modifies the fields that are explicitly provided.
+type InExpectedFile struct {
+	Name        Nullable[string]  `json:"name,omitzero" yaml:"name,omitempty"`
+	Disabled    Nullable[bool]    `json:"disabled,omitzero" yaml:"disabled,omitempty"`
+	Rule        Nullable[string]  `json:"rule,omitzero" yaml:"rule,omitempty"`
+	Partner     Nullable[string]  `json:"partner,omitzero" yaml:"partner,omitempty"`
+	Server      Nullable[string]  `json:"server,omitzero" yaml:"server,omitempty"`
+	Account     Nullable[string]  `json:"account,omitzero" yaml:"account,omitempty"`
+	Pattern     Nullable[string]  `json:"pattern,omitzero" yaml:"pattern,omitempty"`
+	WindowStart Nullable[string]  `json:"windowStart,omitzero" yaml:"windowStart,omitempty"`
+	WindowEnd   Nullable[string]  `json:"windowEnd,omitzero" yaml:"windowEnd,omitempty"`
+	Days        []string          `json:"days,omitempty" yaml:"days,omitempty"`
+	EmailAlert  map[string]string `json:"emailAlert,omitempty" yaml:"emailAlert,omitempty"`
+}
+
+// OutExpectedFile represents the JSON body returned for GET requests on
+// expected files.
+type OutExpectedFile struct {
+	Name        string                `json:"name" yaml:"name"`
+	Disabled    bool                  `json:"disabled" yaml:"disabled"`
+	Rule        string                `json:"rule" yaml:"rule"`
+	Partner     string                `json:"partner,omitempty" yaml:"partner,omitempty"`
+	Server      string                `json:"server,omitempty" yaml:"server,omitempty"`
+	Account     string                `json:"account,omitempty" yaml:"account,omitempty"`
+	Pattern     string                `json:"pattern" yaml:"pattern"`
+	WindowStart string                `json:"windowStart" yaml:"windowStart"`
+	WindowEnd   string                `json:"windowEnd" yaml:"windowEnd"`
+	Days        []string              `json:"days" yaml:"days"`
+	EmailAlert  map[string]string     `json:"emailAlert,omitempty" yaml:"emailAlert,omitempty"`
+	LastCheck   *OutExpectedFileCheck `json:"lastCheck,omitempty" yaml:"lastCheck,omitempty"`
+}
+
+// OutExpectedFileCheck is the JSON representation of the outcome of the check
+// of an expected file's window, as returned by the REST API.
+type OutExpectedFileCheck struct {
+	WindowStart time.Time `json:"windowStart" yaml:"windowStart"`
+	WindowEnd   time.Time `json:"windowEnd" yaml:"windowEnd"`
+	Status      string    `json:"status" yaml:"status"`
+	Files       []string  `json:"files" yaml:"files"`
+	Checked     time.Time `json:"checked" yaml:"checked"`
+}
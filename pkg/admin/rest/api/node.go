@@ -0,0 +1,15 @@
+package api
+
+import "time"
+
+// GetNodeRespObject is the JSON representation of a cluster node, as returned
+// by the REST API.
+type GetNodeRespObject struct {
+	Name             string    `json:"name" yaml:"name"`
+	Hostname         string    `json:"hostname,omitempty" yaml:"hostname,omitempty"`
+	Status           string    `json:"status" yaml:"status"`
+	StartedAt        time.Time `json:"startedAt" yaml:"startedAt"`
+	LastHeartbeat    time.Time `json:"lastHeartbeat" yaml:"lastHeartbeat"`
+	LeaseExpiry      time.Time `json:"leaseExpiry" yaml:"leaseExpiry"`
+	RunningTransfers int64     `json:"runningTransfers" yaml:"runningTransfers"`
+}
@@ -7,6 +7,7 @@ type InPartner struct {
 	Protocol    Nullable[string]  `json:"protocol,omitzero" yaml:"protocol,omitempty"`
 	Address     Nullable[string]  `json:"address,omitzero" yaml:"address,omitempty"`
 	ProtoConfig UpdateObject[any] `json:"protoConfig,omitempty" yaml:"protoConfig,omitempty"`
+	RetryPolicy Nullable[string]  `json:"retryPolicy,omitzero" yaml:"retryPolicy,omitempty"`
 }
 
 // OutPartner is the JSON representation of a remote partner in responses sent
@@ -18,4 +19,5 @@ type OutPartner struct {
 	Credentials     []string        `json:"credentials" yaml:"credentials"`
 	ProtoConfig     map[string]any  `json:"protoConfig" yaml:"protoConfig"`
 	AuthorizedRules AuthorizedRules `json:"authorizedRules" yaml:"authorizedRules"`
+	RetryPolicy     string          `json:"retryPolicy,omitempty" yaml:"retryPolicy,omitempty"`
 }
@@ -0,0 +1,34 @@
+package api
+
+type GetRetryPolicyObject struct {
+	Name            string            `json:"name" yaml:"name"`
+	MaxAttempts     int8              `json:"maxAttempts" yaml:"maxAttempts"`
+	FirstDelay      Duration          `json:"firstDelay" yaml:"firstDelay"`
+	IncrementFactor float32           `json:"incrementFactor" yaml:"incrementFactor"`
+	MaxDelay        Duration          `json:"maxDelay" yaml:"maxDelay"`
+	Jitter          float32           `json:"jitter" yaml:"jitter"`
+	MaxWindow       Duration          `json:"maxWindow" yaml:"maxWindow"`
+	ErrorCodes      map[string]string `json:"errorCodes,omitempty" yaml:"errorCodes,omitempty"`
+}
+
+type PostRetryPolicyObject struct {
+	Name            string            `json:"name" yaml:"name"`
+	MaxAttempts     int8              `json:"maxAttempts" yaml:"maxAttempts"`
+	FirstDelay      Duration          `json:"firstDelay" yaml:"firstDelay"`
+	IncrementFactor float32           `json:"incrementFactor" yaml:"incrementFactor"`
+	MaxDelay        Duration          `json:"maxDelay" yaml:"maxDelay"`
+	Jitter          float32           `json:"jitter" yaml:"jitter"`
+	MaxWindow       Duration          `json:"maxWindow" yaml:"maxWindow"`
+	ErrorCodes      map[string]string `json:"errorCodes,omitempty" yaml:"errorCodes,omitempty"`
+}
+
+type PatchRetryPolicyObject struct {
+	Name            Nullable[string]   `json:"name,omitzero" yaml:"name,omitempty"`
+	MaxAttempts     Nullable[int8]     `json:"maxAttempts,omitzero" yaml:"maxAttempts,omitempty"`
+	FirstDelay      Nullable[Duration] `json:"firstDelay,omitzero" yaml:"firstDelay,omitempty"`
+	IncrementFactor Nullable[float32]  `json:"incrementFactor,omitzero" yaml:"incrementFactor,omitempty"`
+	MaxDelay        Nullable[Duration] `json:"maxDelay,omitzero" yaml:"maxDelay,omitempty"`
+	Jitter          Nullable[float32]  `json:"jitter,omitzero" yaml:"jitter,omitempty"`
+	MaxWindow       Nullable[Duration] `json:"maxWindow,omitzero" yaml:"maxWindow,omitempty"`
+	ErrorCodes      map[string]string  `json:"errorCodes,omitempty" yaml:"errorCodes,omitempty"`
+}
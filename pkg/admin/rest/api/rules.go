@@ -26,6 +26,7 @@ type InRule struct {
 	LocalDir       Nullable[string] `json:"localDir,omitzero" yaml:"localDir,omitempty"`
 	RemoteDir      Nullable[string] `json:"remoteDir,omitzero" yaml:"remoteDir,omitempty"`
 	TmpLocalRcvDir Nullable[string] `json:"tmpLocalRcvDir,omitzero" yaml:"tmpLocalRcvDir,omitempty"`
+	RetryPolicy    Nullable[string] `json:"retryPolicy,omitzero" yaml:"retryPolicy,omitempty"`
 	PreTasks       []*Task          `json:"preTasks,omitempty" yaml:"preTasks,omitempty"`
 	PostTasks      []*Task          `json:"postTasks,omitempty" yaml:"postTasks,omitempty"`
 	ErrorTasks     []*Task          `json:"errorTasks,omitempty" yaml:"errorTasks,omitempty"`
@@ -46,6 +47,7 @@ type OutRule struct {
 	LocalDir       string     `json:"localDir,omitempty" yaml:"localDir,omitempty"`
 	RemoteDir      string     `json:"remoteDir,omitempty" yaml:"remoteDir,omitempty"`
 	TmpLocalRcvDir string     `json:"tmpLocalRcvDir,omitempty" yaml:"tmpLocalRcvDir,omitempty"`
+	RetryPolicy    string     `json:"retryPolicy,omitempty" yaml:"retryPolicy,omitempty"`
 	Authorized     RuleAccess `json:"authorized,omitzero" yaml:"authorized,omitempty"`
 	PreTasks       []*Task    `json:"preTasks,omitempty" yaml:"preTasks,omitempty"`
 	PostTasks      []*Task    `json:"postTasks,omitempty" yaml:"postTasks,omitempty"`
@@ -0,0 +1,7 @@
+package api
+
+type (
+	GetScriptObject   = GetStoredTextObject
+	PostScriptObject  = PostStoredTextObject
+	PatchScriptObject = PatchStoredTextObject
+)
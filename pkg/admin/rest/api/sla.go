@@ -0,0 +1,41 @@
+package api
+
+import "time"
+
+// InSLA represents the JSON body for creating or partially updating an SLA
+// via the REST API. All fields are nullable so that a PATCH request only
+// modifies the fields that are explicitly provided.
+type InSLA struct {
+	Name          Nullable[string]   `json:"name,omitzero" yaml:"name,omitempty"`
+	Rule          Nullable[string]   `json:"rule,omitzero" yaml:"rule,omitempty"`
+	IsSend        Nullable[bool]     `json:"isSend,omitzero" yaml:"isSend,omitempty"`
+	Partner       Nullable[string]   `json:"partner,omitzero" yaml:"partner,omitempty"`
+	Deadline      Nullable[string]   `json:"deadline,omitzero" yaml:"deadline,omitempty"`
+	DeadlineDays  []string           `json:"deadlineDays,omitempty" yaml:"deadlineDays,omitempty"`
+	MaxDuration   Nullable[Duration] `json:"maxDuration,omitzero" yaml:"maxDuration,omitempty"`
+	MinThroughput Nullable[int64]    `json:"minThroughput,omitzero" yaml:"minThroughput,omitempty"`
+}
+
+// OutSLA represents the JSON body returned for GET requests on SLAs.
+type OutSLA struct {
+	Name          string         `json:"name" yaml:"name"`
+	Rule          string         `json:"rule,omitempty" yaml:"rule,omitempty"`
+	IsSend        Nullable[bool] `json:"isSend,omitzero" yaml:"isSend,omitempty"`
+	Partner       string         `json:"partner,omitempty" yaml:"partner,omitempty"`
+	Deadline      string         `json:"deadline,omitempty" yaml:"deadline,omitempty"`
+	DeadlineDays  []string       `json:"deadlineDays,omitempty" yaml:"deadlineDays,omitempty"`
+	MaxDuration   Duration       `json:"maxDuration,omitzero" yaml:"maxDuration,omitempty"`
+	MinThroughput int64          `json:"minThroughput,omitempty" yaml:"minThroughput,omitempty"`
+	Status        string         `json:"status" yaml:"status"`
+	LastBreach    *OutSLABreach  `json:"lastBreach,omitempty" yaml:"lastBreach,omitempty"`
+}
+
+// OutSLABreach is the JSON representation of an SLA breach, as returned by the
+// REST API.
+type OutSLABreach struct {
+	Kind       string    `json:"kind" yaml:"kind"`
+	TransferID int64     `json:"transferID,omitempty" yaml:"transferID,omitempty"`
+	Date       time.Time `json:"date" yaml:"date"`
+	Detected   time.Time `json:"detected" yaml:"detected"`
+	Details    string    `json:"details" yaml:"details"`
+}
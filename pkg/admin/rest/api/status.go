@@ -4,6 +4,10 @@ package api
 type Status struct {
 	State  string `json:"state" yaml:"state"`
 	Reason string `json:"reason" yaml:"reason"`
+
+	// Nodes gives, in a clustered deployment, the state of the service on
+	// each node of the cluster.
+	Nodes map[string]Status `json:"nodes,omitempty" yaml:"nodes,omitempty"`
 }
 
 // Statuses maps a service name to its state.
@@ -0,0 +1,20 @@
+package api
+
+// GetStoredTextObject is the JSON representation of a named text (such as a
+// script or a copybook) in responses sent by the REST interface.
+type GetStoredTextObject struct {
+	Name    string `json:"name" yaml:"name"`
+	Content string `json:"content" yaml:"content"`
+}
+
+// PostStoredTextObject is the JSON object used to create a named text.
+type PostStoredTextObject struct {
+	Name    string `json:"name" yaml:"name"`
+	Content string `json:"content" yaml:"content"`
+}
+
+// PatchStoredTextObject is the JSON object used to update a named text.
+type PatchStoredTextObject struct {
+	Name    Nullable[string] `json:"name,omitzero" yaml:"name,omitempty"`
+	Content Nullable[string] `json:"content,omitzero" yaml:"content,omitempty"`
+}
@@ -5,4 +5,12 @@ package api
 type Task struct {
 	Type string            `json:"type" yaml:"type"`
 	Args map[string]string `json:"args,omitempty" yaml:"args,omitempty"`
+
+	Condition string `json:"condition,omitempty" yaml:"condition,omitempty"`
+	OnError   string `json:"onError,omitempty" yaml:"onError,omitempty"`
+
+	Retries       int8     `json:"retries,omitempty" yaml:"retries,omitempty"`
+	RetryDelay    Duration `json:"retryDelay,omitzero" yaml:"retryDelay,omitempty"`
+	Timeout       Duration `json:"timeout,omitzero" yaml:"timeout,omitempty"`
+	ParallelGroup string   `json:"parallelGroup,omitempty" yaml:"parallelGroup,omitempty"`
 }
@@ -28,6 +28,19 @@ type InTransfer struct {
 	StartDate  Nullable[time.Time] `json:"startDate,omitzero"`   // Deprecated: replaced by Start
 }
 
+// PatchTransfer is the JSON representation of the modifiable attributes of a
+// transfer in update requests made to the REST interface. In the transfer info
+// map, a null value removes the corresponding key.
+type PatchTransfer struct {
+	Start                Nullable[time.Time] `json:"start,omitzero" yaml:"start,omitempty"`
+	SrcFilename          Nullable[string]    `json:"srcFilename,omitzero" yaml:"srcFilename,omitempty"`
+	DestFilename         Nullable[string]    `json:"destFilename,omitzero" yaml:"destFilename,omitempty"`
+	NbOfAttempts         Nullable[int8]      `json:"nbOfAttempts,omitzero" yaml:"nbOfAttempts,omitempty"`
+	FirstRetryDelay      Nullable[int32]     `json:"firstRetryDelay,omitzero" yaml:"firstRetryDelay,omitempty"`
+	RetryIncrementFactor Nullable[float32]   `json:"retryIncrementFactor,omitzero" yaml:"retryIncrementFactor,omitempty"`
+	TransferInfo         map[string]any      `json:"transferInfo,omitempty" yaml:"transferInfo,omitempty"`
+}
+
 // OutTransfer is the JSON representation of a transfer in responses sent by
 // the REST interface.
 type OutTransfer struct {
@@ -48,6 +61,7 @@ type OutTransfer struct {
 	Start                time.Time            `json:"start" yaml:"start"`
 	Stop                 Nullable[time.Time]  `json:"stop,omitzero" yaml:"stop,omitempty"`
 	Status               types.TransferStatus `json:"status" yaml:"status"`
+	Node                 string               `json:"node,omitempty" yaml:"node,omitempty"`
 	Step                 string               `json:"step,omitempty" yaml:"step,omitempty"`
 	Progress             int64                `json:"progress,omitempty" yaml:"progress,omitempty"`
 	TaskNumber           int8                 `json:"taskNumber,omitempty" yaml:"taskNumber,omitempty"`
@@ -65,3 +79,21 @@ type OutTransfer struct {
 	DestPath     string    `json:"destPath"`     // Deprecated: replaced by LocalFilepath & RemoteFilepath
 	StartDate    time.Time `json:"startDate"`    // Deprecated: replaced by Start
 }
+
+// OutBulkTransferResult is the JSON representation of the outcome of a bulk
+// operation (pause, resume, retry or reschedule) on transfers.
+type OutBulkTransferResult struct {
+	DryRun  bool                   `json:"dryRun" yaml:"dryRun"`
+	Total   uint64                 `json:"total" yaml:"total"`
+	Results []*OutBulkTransferItem `json:"results" yaml:"results"`
+	IDs     []int64                `json:"ids,omitempty" yaml:"ids,omitempty"`
+}
+
+// OutBulkTransferItem is the JSON representation of the outcome of a bulk
+// operation on a single transfer.
+type OutBulkTransferItem struct {
+	ID      int64  `json:"id" yaml:"id"`
+	Success bool   `json:"success" yaml:"success"`
+	NewID   int64  `json:"newID,omitempty" yaml:"newID,omitempty"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+}
@@ -19,6 +19,10 @@ func authorityToModel(jAuthority *api.InAuthority, id int64) *model.Authority {
 		Type:           jAuthority.Type.Value,
 		PublicIdentity: jAuthority.PublicIdentity.Value,
 		ValidHosts:     jAuthority.ValidHosts,
+
+		RevocationPolicy: jAuthority.RevocationPolicy.Value,
+		CRLURL:           jAuthority.CRLURL.Value,
+		OCSPURL:          jAuthority.OCSPURL.Value,
 	}
 }
 
@@ -28,6 +32,10 @@ func modelToAuthority(dbAuthority *model.Authority) *api.OutAuthority {
 		Type:           dbAuthority.Type,
 		PublicIdentity: dbAuthority.PublicIdentity,
 		ValidHosts:     dbAuthority.ValidHosts,
+
+		RevocationPolicy: dbAuthority.RevocationPolicy,
+		CRLURL:           dbAuthority.CRLURL,
+		OCSPURL:          dbAuthority.OCSPURL,
 	}
 }
 
@@ -125,6 +133,10 @@ func updateAuthAuthority(logger *log.Logger, db *database.DB) http.HandlerFunc {
 			Type:           asNullable(old.Type),
 			PublicIdentity: asNullable(old.PublicIdentity),
 			ValidHosts:     old.ValidHosts,
+
+			RevocationPolicy: asNullable(old.RevocationPolicy),
+			CRLURL:           asNullable(old.CRLURL),
+			OCSPURL:          asNullable(old.OCSPURL),
 		}
 		if err := readJSON(r, jAuth); handleError(w, logger, err) {
 			return
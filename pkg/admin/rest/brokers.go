@@ -0,0 +1,198 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/admin/rest/api"
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+)
+
+func retrieveBroker(r *http.Request, db *database.DB) (*model.MessageBroker, error) {
+	brokerName, ok := mux.Vars(r)["broker"]
+	if !ok {
+		return nil, notFound("missing message broker name")
+	}
+
+	var broker model.MessageBroker
+	if err := db.Get(&broker, "name=?", brokerName).Owner().Run(); err != nil {
+		if database.IsNotFound(err) {
+			return nil, notFoundf("message broker %q not found", brokerName)
+		}
+
+		return nil, err //nolint:wrapcheck //wrapping adds nothing here
+	}
+
+	return &broker, nil
+}
+
+func dbBrokerToREST(broker *model.MessageBroker) *api.GetBrokerRespObject {
+	return &api.GetBrokerRespObject{
+		Name:    broker.Name,
+		Type:    broker.Type,
+		Address: broker.Address,
+		Login:   broker.Login,
+		Options: broker.Options,
+	}
+}
+
+func dbBrokersToREST(dbBrokers []*model.MessageBroker) []*api.GetBrokerRespObject {
+	restBrokers := make([]*api.GetBrokerRespObject, len(dbBrokers))
+
+	for i, dbBroker := range dbBrokers {
+		restBrokers[i] = dbBrokerToREST(dbBroker)
+	}
+
+	return restBrokers
+}
+
+func restBrokerToDB(broker *api.PostBrokerReqObject) *model.MessageBroker {
+	return &model.MessageBroker{
+		Name:     broker.Name,
+		Type:     broker.Type,
+		Address:  broker.Address,
+		Login:    broker.Login,
+		Password: broker.Password,
+		Options:  model.Map[string](broker.Options),
+	}
+}
+
+func listBrokers(logger *log.Logger, db *database.DB) http.HandlerFunc {
+	//nolint:goconst //duplicate is for a different type, keep separate
+	validSorting := orders{
+		"default": order{"name", true},
+		"name+":   order{"name", true},
+		"name-":   order{"name", false},
+		"type+":   order{"type", true},
+		"type-":   order{"type", false},
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var dbBrokers model.MessageBrokers
+
+		query, queryErr := parseSelectQuery(r, db, validSorting, &dbBrokers)
+		if handleError(w, logger, queryErr) {
+			return
+		}
+
+		if err := query.Run(); handleError(w, logger, err) {
+			return
+		}
+
+		restBrokers := dbBrokersToREST(dbBrokers)
+		response := map[string][]*api.GetBrokerRespObject{"brokers": restBrokers}
+
+		handleError(w, logger, writeJSON(w, response))
+	}
+}
+
+func getBroker(logger *log.Logger, db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dbBroker, getErr := retrieveBroker(r, db)
+		if handleError(w, logger, getErr) {
+			return
+		}
+
+		handleError(w, logger, writeJSON(w, dbBrokerToREST(dbBroker)))
+	}
+}
+
+//nolint:dupl //duplicate is for a different type, keep separate
+func addBroker(logger *log.Logger, db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var restBroker api.PostBrokerReqObject
+		if err := readJSON(r, &restBroker); handleError(w, logger, err) {
+			return
+		}
+
+		dbBroker := restBrokerToDB(&restBroker)
+		if err := db.Insert(dbBroker).Run(); handleError(w, logger, err) {
+			return
+		}
+
+		w.Header().Set("Location", location(r.URL, dbBroker.Name))
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+func updateBroker(logger *log.Logger, db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		oldBroker, getErr := retrieveBroker(r, db)
+		if handleError(w, logger, getErr) {
+			return
+		}
+
+		restBroker := api.PatchBrokerReqObject{
+			Name:     oldBroker.Name,
+			Type:     oldBroker.Type,
+			Address:  oldBroker.Address,
+			Login:    asNullable(oldBroker.Login),
+			Password: asNullable(oldBroker.Password),
+			Options:  api.BrokerOptions(oldBroker.Options),
+		}
+		if err := readJSON(r, &restBroker); handleError(w, logger, err) {
+			return
+		}
+
+		dbBroker := &model.MessageBroker{
+			Identifier: oldBroker.Identifier,
+			Owner:      oldBroker.Owner,
+			Name:       restBroker.Name,
+			Type:       restBroker.Type,
+			Address:    restBroker.Address,
+			Login:      restBroker.Login.Value,
+			Password:   restBroker.Password.Value,
+			Options:    model.Map[string](restBroker.Options),
+		}
+
+		if err := db.Update(dbBroker).Run(); handleError(w, logger, err) {
+			return
+		}
+
+		w.Header().Set("Location", location(r.URL, dbBroker.Name))
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+func replaceBroker(logger *log.Logger, db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		oldBroker, getErr := retrieveBroker(r, db)
+		if handleError(w, logger, getErr) {
+			return
+		}
+
+		restBroker := api.PostBrokerReqObject{}
+		if err := readJSON(r, &restBroker); handleError(w, logger, err) {
+			return
+		}
+
+		dbBroker := restBrokerToDB(&restBroker)
+		dbBroker.ID = oldBroker.ID
+		dbBroker.Owner = oldBroker.Owner
+
+		if err := db.Update(dbBroker).Run(); handleError(w, logger, err) {
+			return
+		}
+
+		w.Header().Set("Location", location(r.URL, dbBroker.Name))
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+func deleteBroker(logger *log.Logger, db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dbBroker, getErr := retrieveBroker(r, db)
+		if handleError(w, logger, getErr) {
+			return
+		}
+
+		if err := db.Delete(dbBroker).Run(); handleError(w, logger, err) {
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,231 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/admin/rest/api"
+	"code.waarp.fr/apps/gateway/gateway/pkg/brokers/brokerstest"
+	"code.waarp.fr/apps/gateway/gateway/pkg/database/dbtest"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils/testhelpers"
+)
+
+func TestGetBroker(t *testing.T) {
+	t.Parallel()
+
+	brokerType, _ := brokerstest.MakeDummyBackend(t)
+	logger := testhelpers.GetTestLogger(t)
+	db := dbtest.TestDatabase(t)
+	handle := getBroker(logger, db)
+
+	existing := &model.MessageBroker{
+		Name:     "existing",
+		Type:     brokerType,
+		Address:  "localhost:4222",
+		Login:    "login",
+		Password: "password",
+		Options:  map[string]string{"foo": "bar"},
+	}
+	require.NoError(t, db.Insert(existing).Run())
+
+	expectedJSON, jsonErr := json.Marshal(map[string]any{
+		"name":    existing.Name,
+		"type":    existing.Type,
+		"address": existing.Address,
+		"login":   existing.Login,
+		"options": existing.Options,
+	})
+	require.NoError(t, jsonErr)
+
+	t.Run("Given a valid broker name", func(t *testing.T) {
+		r, err := http.NewRequest(http.MethodGet, "", nil)
+		require.NoError(t, err)
+
+		r = mux.SetURLVars(r, map[string]string{"broker": existing.Name})
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code,
+			`Then the response code should be "200 OK"`)
+		assert.JSONEq(t, string(expectedJSON), w.Body.String(),
+			`Then the JSON response body should be as expected (without the password)`)
+	})
+
+	t.Run("Given an unknown broker name", func(t *testing.T) {
+		r, err := http.NewRequest(http.MethodGet, "", nil)
+		require.NoError(t, err)
+
+		const name = "unknown"
+		r = mux.SetURLVars(r, map[string]string{"broker": name})
+
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code,
+			`Then the response code should be "404 Not Found"`)
+		assert.Equal(t,
+			fmt.Sprintf("message broker %q not found\n", name),
+			w.Body.String(),
+			`Then the body should contain an error message`)
+	})
+}
+
+func TestAddBroker(t *testing.T) {
+	t.Parallel()
+
+	brokerType, _ := brokerstest.MakeDummyBackend(t)
+
+	t.Run("Given a valid broker object", func(t *testing.T) {
+		logger := testhelpers.GetTestLogger(t)
+		db := dbtest.TestDatabase(t)
+		handle := addBroker(logger, db)
+		body := bytes.Buffer{}
+
+		input := api.PostBrokerReqObject{
+			Name:     "input",
+			Type:     brokerType,
+			Address:  "localhost:4222",
+			Login:    "login",
+			Password: "password",
+			Options:  map[string]string{"foo": "bar"},
+		}
+		require.NoError(t, json.NewEncoder(&body).Encode(input))
+
+		expectedBroker := model.MessageBroker{
+			Identifier: model.ID(1),
+			Owner:      db.Config.GatewayName,
+			Name:       input.Name,
+			Type:       input.Type,
+			Address:    input.Address,
+			Login:      input.Login,
+			Password:   input.Password,
+			Options:    model.Map[string](input.Options),
+		}
+
+		r, err := http.NewRequest(http.MethodPost, "brokers", &body)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusCreated, w.Code,
+			`Then the response code should be "201 Created"`)
+		assert.Equal(t, path.Join("brokers", input.Name), w.Header().Get("Location"),
+			`Then the response location header should have been set correctly`)
+
+		var dbBroker model.MessageBroker
+
+		require.NoError(t, db.Get(&dbBroker, "name=?", input.Name).Owner().Run())
+		assert.EqualExportedValues(t, expectedBroker, dbBroker,
+			`Then the message broker should have been inserted in the database`)
+	})
+
+	t.Run("Given an unknown broker option", func(t *testing.T) {
+		logger := testhelpers.GetTestLogger(t)
+		db := dbtest.TestDatabase(t)
+		handle := addBroker(logger, db)
+		body := bytes.Buffer{}
+
+		require.NoError(t, json.NewEncoder(&body).Encode(api.PostBrokerReqObject{
+			Name:    "input",
+			Type:    brokerType,
+			Address: "localhost:4222",
+			Options: map[string]string{"unknown": "val"},
+		}))
+
+		r, err := http.NewRequest(http.MethodPost, "brokers", &body)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code,
+			`Then the response code should be "400 Bad Request"`)
+		assert.Contains(t, w.Body.String(), `invalid message broker configuration`,
+			`Then the response body should contain an error message`)
+
+		var dbBrokers model.MessageBrokers
+
+		require.NoError(t, db.Select(&dbBrokers).Run())
+		assert.Empty(t, dbBrokers, `Then there should be no message brokers in the database`)
+	})
+}
+
+func TestUpdateBroker(t *testing.T) {
+	t.Parallel()
+
+	brokerType, _ := brokerstest.MakeDummyBackend(t)
+	logger := testhelpers.GetTestLogger(t)
+	db := dbtest.TestDatabase(t)
+	handle := updateBroker(logger, db)
+
+	existing := &model.MessageBroker{
+		Name:     "existing",
+		Type:     brokerType,
+		Address:  "localhost:4222",
+		Login:    "login",
+		Password: "password",
+	}
+	require.NoError(t, db.Insert(existing).Run())
+
+	t.Run("Given a partial broker object", func(t *testing.T) {
+		body := bytes.NewBufferString(`{"name":"new_name","login":null}`)
+
+		r, err := http.NewRequest(http.MethodPatch, "brokers/existing", body)
+		require.NoError(t, err)
+
+		r = mux.SetURLVars(r, map[string]string{"broker": existing.Name})
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusCreated, w.Code,
+			`Then the response code should be "201 Created"`)
+		assert.Equal(t, "brokers/new_name", w.Header().Get("Location"),
+			`Then the response location header should have been set correctly`)
+
+		var dbBroker model.MessageBroker
+
+		require.NoError(t, db.Get(&dbBroker, "id=?", existing.ID).Run())
+		assert.Equal(t, "new_name", dbBroker.Name, `Then the name should have been updated`)
+		assert.Empty(t, dbBroker.Login, `Then the login should have been removed`)
+		assert.Equal(t, existing.Password, dbBroker.Password,
+			`Then the password should have been left unchanged`)
+	})
+}
+
+func TestDeleteBroker(t *testing.T) {
+	t.Parallel()
+
+	brokerType, _ := brokerstest.MakeDummyBackend(t)
+	logger := testhelpers.GetTestLogger(t)
+	db := dbtest.TestDatabase(t)
+	handle := deleteBroker(logger, db)
+
+	existing := &model.MessageBroker{Name: "existing", Type: brokerType, Address: "localhost:4222"}
+	require.NoError(t, db.Insert(existing).Run())
+
+	r, err := http.NewRequest(http.MethodDelete, "", nil)
+	require.NoError(t, err)
+
+	r = mux.SetURLVars(r, map[string]string{"broker": existing.Name})
+	w := httptest.NewRecorder()
+	handle.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusNoContent, w.Code,
+		`Then the response code should be "204 No Content"`)
+
+	var dbBrokers model.MessageBrokers
+
+	require.NoError(t, db.Select(&dbBrokers).Run())
+	assert.Empty(t, dbBrokers, `Then the message broker should have been deleted`)
+}
@@ -0,0 +1,53 @@
+package rest
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/karrick/tparse/v2"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/admin/rest/api"
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/expiry"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
+)
+
+const defaultExpiringWithin = "30d"
+
+func listExpiringCertificates(logger *log.Logger, db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		within := defaultExpiringWithin
+		if values := r.URL.Query()["within"]; len(values) != 0 {
+			within = values[0]
+		}
+
+		now := time.Now()
+
+		before, parseErr := tparse.AddDuration(now, within)
+		if parseErr != nil {
+			handleError(w, logger, badRequestf("%q is not a valid duration", within))
+
+			return
+		}
+
+		items, listErr := expiry.List(db, before)
+		if handleError(w, logger, listErr) {
+			return
+		}
+
+		restCerts := make([]*api.GetExpiringCertRespObject, len(items))
+		for i, item := range items {
+			restCerts[i] = &api.GetExpiringCertRespObject{
+				Kind:      item.Kind,
+				Owner:     item.Owner,
+				Name:      item.Name,
+				Type:      item.Type,
+				ExpiresAt: item.NotAfter.Local(),
+				DaysLeft:  item.DaysLeft(now),
+			}
+		}
+
+		resp := map[string][]*api.GetExpiringCertRespObject{"certificates": restCerts}
+		handleError(w, logger, writeJSON(w, resp))
+	}
+}
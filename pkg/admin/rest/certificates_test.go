@@ -0,0 +1,72 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/admin/rest/api"
+	"code.waarp.fr/apps/gateway/gateway/pkg/database/dbtest"
+	"code.waarp.fr/apps/gateway/gateway/pkg/expiry"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/authentication/auth"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils/testhelpers"
+)
+
+func TestListExpiringCertificates(t *testing.T) {
+	t.Parallel()
+
+	logger := testhelpers.GetTestLogger(t)
+	db := dbtest.TestDatabase(t)
+	handler := listExpiringCertificates(logger, db)
+
+	require.NoError(t, db.Insert(&model.Authority{
+		Name:           "authority",
+		Type:           auth.AuthorityTLS,
+		PublicIdentity: testhelpers.LocalhostCert,
+	}).Run())
+
+	listCerts := func(tb testing.TB, within string) []*api.GetExpiringCertRespObject {
+		tb.Helper()
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet,
+			"/api/certificates/expiring?within="+within, nil))
+		require.Equal(tb, http.StatusOK, w.Code, w.Body.String())
+
+		var resp map[string][]*api.GetExpiringCertRespObject
+		require.NoError(tb, json.Unmarshal(w.Body.Bytes(), &resp))
+
+		return resp["certificates"]
+	}
+
+	t.Run("Given a certificate which does not expire soon", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Empty(t, listCerts(t, "30d"))
+	})
+
+	t.Run("Given a long enough duration", func(t *testing.T) {
+		t.Parallel()
+
+		certs := listCerts(t, "100y")
+		require.Len(t, certs, 1)
+		assert.Equal(t, expiry.KindAuthority, certs[0].Kind)
+		assert.Equal(t, "authority", certs[0].Name)
+		assert.Equal(t, auth.AuthorityTLS, certs[0].Type)
+		assert.Positive(t, certs[0].DaysLeft)
+	})
+
+	t.Run("Given an invalid duration", func(t *testing.T) {
+		t.Parallel()
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet,
+			"/api/certificates/expiring?within=soon", nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
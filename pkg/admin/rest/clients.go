@@ -11,6 +11,7 @@ import (
 	"code.waarp.fr/apps/gateway/gateway/pkg/gatewayd/services"
 	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
 	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/pipeline"
 	"code.waarp.fr/apps/gateway/gateway/pkg/protocols"
 )
 
@@ -276,6 +277,8 @@ func restartClient(logger *log.Logger, db *database.DB) http.HandlerFunc {
 			return
 		}
 
+		pipeline.List.EndClientDrain(dbClient.ID)
+
 		if err := services.Clients.Restart(r.Context(), dbClient); handleError(w, logger, err) {
 			return
 		}
@@ -0,0 +1,127 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database/dbtest"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils/testhelpers"
+)
+
+const (
+	CopybooksPath = "/copybooks"
+	CopybookPath  = "/copybooks/{copybook}"
+)
+
+func TestAddCopybook(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+	logger := testhelpers.GetTestLogger(t)
+	handle := addStoredText[model.Copybook](logger, db)
+
+	t.Run("Valid request", func(t *testing.T) {
+		reqBody := bytes.Buffer{}
+		require.NoError(t, json.NewEncoder(&reqBody).Encode(map[string]any{
+			"name":    "copybook1",
+			"content": `01 REC. 05 NAME PIC X(10).`,
+		}))
+
+		req := httptest.NewRequest(http.MethodPost, CopybooksPath, &reqBody)
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, req)
+
+		t.Run("Check response", func(t *testing.T) {
+			assert.Equal(t, http.StatusCreated, w.Code, `201 response code`)
+			assert.Equal(t, CopybooksPath+"/copybook1", w.Header().Get("Location"), `Correct Location header`)
+			assert.Empty(t, w.Body.String(), `Empty response body`)
+		})
+
+		t.Run("Check database", func(t *testing.T) {
+			var check model.Copybook
+			require.NoError(t, db.Get(&check, "name=?", "copybook1").Run())
+
+			assert.Equal(t, `01 REC. 05 NAME PIC X(10).`, check.Content)
+		})
+	})
+
+	t.Run("Invalid copybook", func(t *testing.T) {
+		reqBody := bytes.Buffer{}
+		require.NoError(t, json.NewEncoder(&reqBody).Encode(map[string]any{
+			"name":    "copybook2",
+			"content": `01 REC. 05 NAME PIC X(0).`,
+		}))
+
+		req := httptest.NewRequest(http.MethodPost, CopybooksPath, &reqBody)
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, `400 response code`)
+		assert.Contains(t, w.Body.String(), "the copybook is invalid", `Correct response body`)
+	})
+}
+
+func TestListCopybooks(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+	logger := testhelpers.GetTestLogger(t)
+	handle := listStoredTexts[model.Copybook](logger, db)
+
+	dbCopybook1 := &model.Copybook{Name: "copybook1", Content: `01 REC. 05 NAME PIC X(10).`}
+	require.NoError(t, db.Insert(dbCopybook1).Run())
+
+	dbCopybook2 := &model.Copybook{Name: "copybook2", Content: `01 REC. 05 CODE PIC 9(5).`}
+	require.NoError(t, db.Insert(dbCopybook2).Run())
+
+	req := httptest.NewRequest(http.MethodGet, CopybooksPath+"?sort=name-", nil)
+	w := httptest.NewRecorder()
+	handle.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, `200 response code`)
+	assert.JSONEq(t, marshal(t, map[string]any{
+		"copybooks": []any{
+			map[string]any{"name": dbCopybook2.Name, "content": dbCopybook2.Content},
+			map[string]any{"name": dbCopybook1.Name, "content": dbCopybook1.Content},
+		},
+	}), w.Body.String(), `Correct response body`)
+}
+
+func TestUpdateCopybook(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+	logger := testhelpers.GetTestLogger(t)
+	handle := updateStoredText[model.Copybook](logger, db)
+
+	dbCopybook := &model.Copybook{Name: "copybook1", Content: `01 REC. 05 NAME PIC X(10).`}
+	require.NoError(t, db.Insert(dbCopybook).Run())
+
+	reqBody := bytes.Buffer{}
+	require.NoError(t, json.NewEncoder(&reqBody).Encode(map[string]any{
+		"content": `01 REC. 05 NAME PIC X(0).`,
+	}))
+
+	req := makeRequest(http.MethodPatch, &reqBody, CopybookPath, dbCopybook.Name)
+	w := httptest.NewRecorder()
+	handle.ServeHTTP(w, req)
+
+	t.Run("Check response", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, w.Code, `400 response code`)
+		assert.Contains(t, w.Body.String(), "the copybook is invalid", `Correct response body`)
+	})
+
+	t.Run("Check database", func(t *testing.T) {
+		var check model.Copybook
+		require.NoError(t, db.Get(&check, "name=?", dbCopybook.Name).Run())
+
+		assert.Equal(t, dbCopybook.Content, check.Content, "The content should be unchanged")
+	})
+}
@@ -0,0 +1,191 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/gatewayd/services"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
+	"code.waarp.fr/apps/gateway/gateway/pkg/pipeline"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils"
+)
+
+const (
+	// defaultDrainDeadline is the maximum time given to the running transfers to
+	// end when draining a service, if no deadline is given in the request.
+	defaultDrainDeadline = 10 * time.Minute
+
+	// drainStopTimeout is the time given to a drained service to stop once
+	// its transfers are done (or once the drain deadline has been reached).
+	drainStopTimeout = 10 * time.Second
+)
+
+func getDrainDeadline(r *http.Request) (time.Duration, error) {
+	deadlineStr := r.FormValue("deadline")
+	if deadlineStr == "" {
+		return defaultDrainDeadline, nil
+	}
+
+	deadline, err := time.ParseDuration(deadlineStr)
+	if err != nil || deadline <= 0 {
+		return 0, badRequestf("%q is not a valid drain deadline", deadlineStr)
+	}
+
+	return deadline, nil
+}
+
+// drainService waits (in the background) for the transfers of a drained service
+// to end, before stopping the service. If the transfers are not done before the
+// deadline, they are interrupted when the service is stopped.
+func drainService(logger *log.Logger, name string, deadline time.Duration,
+	wait func(context.Context) error, stop func(context.Context) error,
+	stillDraining func() bool, endDrain func(),
+) {
+	logger.Infof("Draining %s, waiting up to %s for its transfers to end", name, deadline)
+
+	go func() {
+		defer endDrain()
+
+		waitCtx, cancelWait := context.WithTimeout(context.Background(), deadline)
+		defer cancelWait()
+
+		if err := wait(waitCtx); err != nil {
+			logger.Warningf("The drain deadline of %s has been reached (%v), the "+
+				"remaining transfers will be interrupted", name, err)
+		}
+
+		if !stillDraining() {
+			logger.Infof("The drain of %s has been canceled", name)
+
+			return
+		}
+
+		stopCtx, cancelStop := context.WithTimeout(context.Background(), drainStopTimeout)
+		defer cancelStop()
+
+		if err := stop(stopCtx); err != nil {
+			logger.Errorf("Failed to stop %s after draining it: %v", name, err)
+
+			return
+		}
+
+		logger.Infof("The drain of %s is complete, the service has been stopped", name)
+	}()
+}
+
+// drainGateway drains the whole gateway. Once the drain is complete, the
+// gateway's servers and clients are stopped, and the gateway itself is shut
+// down.
+func drainGateway(logger *log.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		deadline, err := getDrainDeadline(r)
+		if handleError(w, logger, err) {
+			return
+		}
+
+		if !pipeline.List.DrainGateway() {
+			handleError(w, logger, conflictf("the gateway is already being drained"))
+
+			return
+		}
+
+		drainService(logger, "the gateway", deadline, pipeline.List.WaitAll,
+			func(ctx context.Context) error {
+				defer services.RequestShutdown()
+
+				return errors.Join(services.Servers.StopAll(ctx), services.Clients.StopAll(ctx))
+			},
+			func() bool { return true },
+			func() {}, // the gateway remains drained until it is restarted
+		)
+
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func drainServer(logger *log.Logger, db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dbServer, getErr := getDBServer(r, db)
+		if handleError(w, logger, getErr) {
+			return
+		}
+
+		deadline, err := getDrainDeadline(r)
+		if handleError(w, logger, err) {
+			return
+		}
+
+		if serv, ok := services.Servers.Get(dbServer); !ok || !isRunning(serv) {
+			handleError(w, logger, badRequestf("server %q is not running", dbServer.Name))
+
+			return
+		}
+
+		if !pipeline.List.DrainServer(dbServer.ID) {
+			handleError(w, logger, conflictf("server %q is already being drained", dbServer.Name))
+
+			return
+		}
+
+		drainService(logger, "server "+dbServer.Name, deadline,
+			func(ctx context.Context) error { return pipeline.List.WaitServer(ctx, dbServer.ID) },
+			func(ctx context.Context) error {
+				_, stopErr := services.Servers.Stop(ctx, dbServer)
+
+				return stopErr
+			},
+			func() bool { return pipeline.List.IsServerDraining(dbServer.ID) },
+			func() { pipeline.List.EndServerDrain(dbServer.ID) },
+		)
+
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func drainClient(logger *log.Logger, db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dbClient, getErr := getDBClient(r, db)
+		if handleError(w, logger, getErr) {
+			return
+		}
+
+		deadline, err := getDrainDeadline(r)
+		if handleError(w, logger, err) {
+			return
+		}
+
+		if cli, ok := services.Clients.Get(dbClient); !ok || !isRunning(cli) {
+			handleError(w, logger, badRequestf("client %q is not running", dbClient.Name))
+
+			return
+		}
+
+		if !pipeline.List.DrainClient(dbClient.ID) {
+			handleError(w, logger, conflictf("client %q is already being drained", dbClient.Name))
+
+			return
+		}
+
+		drainService(logger, "client "+dbClient.Name, deadline,
+			func(ctx context.Context) error { return pipeline.List.WaitClient(ctx, dbClient.ID) },
+			func(ctx context.Context) error {
+				_, stopErr := services.Clients.Stop(ctx, dbClient)
+
+				return stopErr
+			},
+			func() bool { return pipeline.List.IsClientDraining(dbClient.ID) },
+			func() { pipeline.List.EndClientDrain(dbClient.ID) },
+		)
+
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func isRunning(serv services.Service) bool {
+	state, _ := serv.State()
+
+	return state == utils.StateRunning
+}
@@ -0,0 +1,133 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database/dbtest"
+	"code.waarp.fr/apps/gateway/gateway/pkg/gatewayd/services"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/types"
+	"code.waarp.fr/apps/gateway/gateway/pkg/pipeline"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils/testhelpers"
+)
+
+const (
+	ServerDrainPath = "/servers/{server}/drain"
+	ClientDrainPath = "/clients/{client}/drain"
+)
+
+func TestDrainServer(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+	logger := testhelpers.GetTestLogger(t)
+	handle := drainServer(logger, db)
+
+	dbServer := &model.LocalAgent{
+		Name: "drained_server", Protocol: testProto1,
+		Address: types.Addr("localhost", 1),
+	}
+	require.NoError(t, db.Insert(dbServer).Run())
+
+	service := makeAndStartTestService(dbServer.Name)
+	services.Servers.Add(dbServer, service)
+	defer services.Servers.Remove(t.Context(), dbServer)
+
+	t.Run("Invalid deadline", func(t *testing.T) {
+		req := makeRequest(http.MethodPut, nil, ServerDrainPath+"?deadline=foo", dbServer.Name)
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, `400 response code`)
+		assert.Equal(t, `"foo" is not a valid drain deadline`+"\n", w.Body.String())
+		assert.False(t, pipeline.List.IsServerDraining(dbServer.ID))
+	})
+
+	t.Run("Already draining", func(t *testing.T) {
+		pipeline.List.DrainServer(dbServer.ID)
+		defer pipeline.List.EndServerDrain(dbServer.ID)
+
+		req := makeRequest(http.MethodPut, nil, ServerDrainPath, dbServer.Name)
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code, `409 response code`)
+		assert.Equal(t, `server "drained_server" is already being drained`+"\n", w.Body.String())
+		assert.Equal(t, utils.StateRunning, stateCode(service))
+	})
+
+	t.Run("Valid drain", func(t *testing.T) {
+		req := makeRequest(http.MethodPut, nil, ServerDrainPath+"?deadline=1m", dbServer.Name)
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusAccepted, w.Code, `202 response code`)
+
+		// The server has no running transfers, so it should be stopped right away.
+		require.Eventually(t, func() bool {
+			return stateCode(service) == utils.StateOffline
+		}, 5*time.Second, 10*time.Millisecond)
+		assert.Eventually(t, func() bool {
+			return !pipeline.List.IsServerDraining(dbServer.ID)
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("Server not running", func(t *testing.T) {
+		req := makeRequest(http.MethodPut, nil, ServerDrainPath, dbServer.Name)
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, `400 response code`)
+		assert.Equal(t, `server "drained_server" is not running`+"\n", w.Body.String())
+	})
+}
+
+func TestDrainClient(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+	logger := testhelpers.GetTestLogger(t)
+	handle := drainClient(logger, db)
+
+	dbClient := &model.Client{Name: "drained_client", Protocol: testProto1}
+	require.NoError(t, db.Insert(dbClient).Run())
+
+	service := makeAndStartTestService(dbClient.Name)
+	services.Clients.Add(dbClient, service)
+	defer services.Clients.Remove(t.Context(), dbClient)
+
+	t.Run("Already draining", func(t *testing.T) {
+		pipeline.List.DrainClient(dbClient.ID)
+		defer pipeline.List.EndClientDrain(dbClient.ID)
+
+		req := makeRequest(http.MethodPut, nil, ClientDrainPath, dbClient.Name)
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code, `409 response code`)
+		assert.Equal(t, `client "drained_client" is already being drained`+"\n", w.Body.String())
+		assert.Equal(t, utils.StateRunning, stateCode(service))
+	})
+
+	t.Run("Valid drain", func(t *testing.T) {
+		req := makeRequest(http.MethodPut, nil, ClientDrainPath, dbClient.Name)
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusAccepted, w.Code, `202 response code`)
+
+		require.Eventually(t, func() bool {
+			return stateCode(service) == utils.StateOffline
+		}, 5*time.Second, 10*time.Millisecond)
+		assert.Eventually(t, func() bool {
+			return !pipeline.List.IsClientDraining(dbClient.ID)
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+}
@@ -17,6 +17,10 @@ type notFoundError struct{ msg string }
 
 func (e *notFoundError) Error() string { return e.msg }
 
+type conflictError struct{ msg string }
+
+func (c *conflictError) Error() string { return c.msg }
+
 type internalError struct{ msg string }
 
 func (i *internalError) Error() string { return i.msg }
@@ -37,6 +41,10 @@ func notFoundf(format string, args ...any) *notFoundError {
 	return &notFoundError{msg: fmt.Sprintf(format, args...)}
 }
 
+func conflictf(format string, args ...any) *conflictError {
+	return &conflictError{msg: fmt.Sprintf(format, args...)}
+}
+
 func internalf(format string, args ...any) *internalError {
 	return &internalError{msg: fmt.Sprintf(format, args...)}
 }
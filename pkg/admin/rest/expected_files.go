@@ -0,0 +1,365 @@
+package rest
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/admin/rest/api"
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+)
+
+func retrieveDBExpectedFile(r *http.Request, db *database.DB) (*model.ExpectedFile, error) {
+	name, ok := mux.Vars(r)["expected_file"]
+	if !ok {
+		return nil, notFound("missing expected file name")
+	}
+
+	var dbFile model.ExpectedFile
+	if err := db.Get(&dbFile, "name=?", name).Owner().Run(); err != nil {
+		if database.IsNotFound(err) {
+			return nil, notFoundf("expected file %q not found", name)
+		}
+
+		return nil, fmt.Errorf("failed to retrieve expected file %q: %w", name, err)
+	}
+
+	return &dbFile, nil
+}
+
+func dbExpectedFileCheckToREST(check *model.ExpectedFileCheck) *api.OutExpectedFileCheck {
+	return &api.OutExpectedFileCheck{
+		WindowStart: check.WindowStart.Local(),
+		WindowEnd:   check.WindowEnd.Local(),
+		Status:      string(check.Status),
+		Files:       check.Files,
+		Checked:     check.Checked.Local(),
+	}
+}
+
+// expectedFileScope returns the names of the rule, partner, server & local
+// account with which the given file is expected (the last 3 can be empty).
+func expectedFileScope(db database.ReadAccess, dbFile *model.ExpectedFile,
+) (rule, partner, server, account string, err error) {
+	var dbRule model.Rule
+	if err = db.Get(&dbRule, "id=?", dbFile.RuleID).Run(); err != nil {
+		return "", "", "", "", fmt.Errorf("failed to retrieve the expected file's rule: %w", err)
+	}
+
+	if dbFile.RemoteAgentID.Valid {
+		var dbPartner model.RemoteAgent
+		if err = db.Get(&dbPartner, "id=?", dbFile.RemoteAgentID.Int64).Run(); err != nil {
+			return "", "", "", "", fmt.Errorf("failed to retrieve the expected file's partner: %w", err)
+		}
+
+		partner = dbPartner.Name
+	}
+
+	if dbFile.LocalAccountID.Valid {
+		var dbAccount model.LocalAccount
+		if err = db.Get(&dbAccount, "id=?", dbFile.LocalAccountID.Int64).Run(); err != nil {
+			return "", "", "", "", fmt.Errorf("failed to retrieve the expected file's account: %w", err)
+		}
+
+		var dbServer model.LocalAgent
+		if err = db.Get(&dbServer, "id=?", dbAccount.LocalAgentID).Run(); err != nil {
+			return "", "", "", "", fmt.Errorf("failed to retrieve the expected file's server: %w", err)
+		}
+
+		server, account = dbServer.Name, dbAccount.Login
+	}
+
+	return dbRule.Name, partner, server, account, nil
+}
+
+func dbExpectedFileToREST(db database.ReadAccess, dbFile *model.ExpectedFile,
+) (*api.OutExpectedFile, error) {
+	rule, partner, server, account, scopeErr := expectedFileScope(db, dbFile)
+	if scopeErr != nil {
+		return nil, scopeErr
+	}
+
+	restFile := &api.OutExpectedFile{
+		Name:        dbFile.Name,
+		Disabled:    dbFile.Disabled,
+		Rule:        rule,
+		Partner:     partner,
+		Server:      server,
+		Account:     account,
+		Pattern:     dbFile.Pattern,
+		WindowStart: dbFile.WindowStart,
+		WindowEnd:   dbFile.WindowEnd,
+		Days:        dbFile.Days,
+		EmailAlert:  dbFile.EmailAlert,
+	}
+
+	var lastCheck model.ExpectedFileCheck
+	if err := db.Get(&lastCheck, "expected_file_id=?", dbFile.ID).
+		OrderBy("window_start", false).Run(); err == nil {
+		restFile.LastCheck = dbExpectedFileCheckToREST(&lastCheck)
+	} else if !database.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to retrieve the expected file's last check: %w", err)
+	}
+
+	return restFile, nil
+}
+
+//nolint:funlen //splitting the function would hurt readability
+func restExpectedFileToDB(db database.ReadAccess, restFile *api.InExpectedFile,
+) (*model.ExpectedFile, error) {
+	dbFile := &model.ExpectedFile{
+		Days:       restFile.Days,
+		EmailAlert: restFile.EmailAlert,
+	}
+
+	setIfValid(&dbFile.Name, restFile.Name)
+	setIfValid(&dbFile.Disabled, restFile.Disabled)
+	setIfValid(&dbFile.Pattern, restFile.Pattern)
+	setIfValid(&dbFile.WindowStart, restFile.WindowStart)
+	setIfValid(&dbFile.WindowEnd, restFile.WindowEnd)
+
+	if restFile.Rule.Valid && restFile.Rule.Value != "" {
+		var rule model.Rule
+		if err := db.Get(&rule, "name=? AND is_send=?", restFile.Rule.Value, false).
+			Run(); err != nil {
+			if database.IsNotFound(err) {
+				return nil, badRequestf("no receive rule %q found", restFile.Rule.Value)
+			}
+
+			return nil, fmt.Errorf("failed to retrieve rule %q: %w", restFile.Rule.Value, err)
+		}
+
+		dbFile.RuleID = rule.ID
+	}
+
+	if restFile.Partner.Valid && restFile.Partner.Value != "" {
+		var partner model.RemoteAgent
+		if err := db.Get(&partner, "name=?", restFile.Partner.Value).Owner().Run(); err != nil {
+			if database.IsNotFound(err) {
+				return nil, badRequestf("no partner %q found", restFile.Partner.Value)
+			}
+
+			return nil, fmt.Errorf("failed to retrieve partner %q: %w", restFile.Partner.Value, err)
+		}
+
+		dbFile.RemoteAgentID = sql.NullInt64{Int64: partner.ID, Valid: true}
+	}
+
+	hasServer := restFile.Server.Valid && restFile.Server.Value != ""
+	hasAccount := restFile.Account.Valid && restFile.Account.Value != ""
+
+	switch {
+	case hasServer && hasAccount:
+		var server model.LocalAgent
+		if err := db.Get(&server, "name=?", restFile.Server.Value).Owner().Run(); err != nil {
+			if database.IsNotFound(err) {
+				return nil, badRequestf("no server %q found", restFile.Server.Value)
+			}
+
+			return nil, fmt.Errorf("failed to retrieve server %q: %w", restFile.Server.Value, err)
+		}
+
+		var account model.LocalAccount
+		if err := db.Get(&account, "local_agent_id=? AND login=?", server.ID,
+			restFile.Account.Value).Run(); err != nil {
+			if database.IsNotFound(err) {
+				return nil, badRequestf("no account %q found for server %q",
+					restFile.Account.Value, restFile.Server.Value)
+			}
+
+			return nil, fmt.Errorf("failed to retrieve account %q: %w", restFile.Account.Value, err)
+		}
+
+		dbFile.LocalAccountID = sql.NullInt64{Int64: account.ID, Valid: true}
+	case hasServer:
+		return nil, badRequest("the local account is missing")
+	case hasAccount:
+		return nil, badRequest("the local account's server is missing")
+	}
+
+	return dbFile, nil
+}
+
+func addExpectedFile(logger *log.Logger, db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var restFile api.InExpectedFile
+		if err := readJSON(r, &restFile); handleError(w, logger, err) {
+			return
+		}
+
+		dbFile, convErr := restExpectedFileToDB(db, &restFile)
+		if handleError(w, logger, convErr) {
+			return
+		}
+
+		if err := db.Insert(dbFile).Run(); handleError(w, logger, err) {
+			return
+		}
+
+		w.Header().Set("Location", location(r.URL, dbFile.Name))
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+func listExpectedFiles(logger *log.Logger, db *database.DB) http.HandlerFunc {
+	//nolint:goconst //duplicate is for a different type, keep separate
+	validSorting := orders{
+		"default": order{"name", true},
+		"name+":   order{"name", true},
+		"name-":   order{"name", false},
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var dbFiles model.ExpectedFiles
+
+		query, queryErr := parseSelectQuery(r, db, validSorting, &dbFiles)
+		if handleError(w, logger, queryErr) {
+			return
+		}
+
+		if err := query.Run(); handleError(w, logger, err) {
+			return
+		}
+
+		restFiles := make([]*api.OutExpectedFile, len(dbFiles))
+
+		for i, dbFile := range dbFiles {
+			restFile, convErr := dbExpectedFileToREST(db, dbFile)
+			if handleError(w, logger, convErr) {
+				return
+			}
+
+			restFiles[i] = restFile
+		}
+
+		response := map[string][]*api.OutExpectedFile{"expectedFiles": restFiles}
+		handleError(w, logger, writeJSON(w, response))
+	}
+}
+
+func getExpectedFile(logger *log.Logger, db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dbFile, getErr := retrieveDBExpectedFile(r, db)
+		if handleError(w, logger, getErr) {
+			return
+		}
+
+		restFile, convErr := dbExpectedFileToREST(db, dbFile)
+		if handleError(w, logger, convErr) {
+			return
+		}
+
+		handleError(w, logger, writeJSON(w, restFile))
+	}
+}
+
+func updateExpectedFile(logger *log.Logger, db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		oldFile, getErr := retrieveDBExpectedFile(r, db)
+		if handleError(w, logger, getErr) {
+			return
+		}
+
+		rule, partner, server, account, scopeErr := expectedFileScope(db, oldFile)
+		if handleError(w, logger, scopeErr) {
+			return
+		}
+
+		restFile := &api.InExpectedFile{
+			Name:        asNullable(oldFile.Name),
+			Disabled:    asNullableBool(oldFile.Disabled),
+			Rule:        asNullable(rule),
+			Partner:     asNullable(partner),
+			Server:      asNullable(server),
+			Account:     asNullable(account),
+			Pattern:     asNullable(oldFile.Pattern),
+			WindowStart: asNullable(oldFile.WindowStart),
+			WindowEnd:   asNullable(oldFile.WindowEnd),
+			Days:        oldFile.Days,
+		}
+
+		if err := readJSON(r, restFile); handleError(w, logger, err) {
+			return
+		}
+
+		// The email alert is replaced as a whole (rather than merged) when
+		// present in the request.
+		if restFile.EmailAlert == nil {
+			restFile.EmailAlert = oldFile.EmailAlert
+		}
+
+		dbFile, convErr := restExpectedFileToDB(db, restFile)
+		if handleError(w, logger, convErr) {
+			return
+		}
+
+		dbFile.ID = oldFile.ID
+		dbFile.Created = oldFile.Created
+
+		if err := db.Update(dbFile).Run(); handleError(w, logger, err) {
+			return
+		}
+
+		w.Header().Set("Location", locationUpdate(r.URL, dbFile.Name))
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+func deleteExpectedFile(logger *log.Logger, db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dbFile, getErr := retrieveDBExpectedFile(r, db)
+		if handleError(w, logger, getErr) {
+			return
+		}
+
+		if err := db.Delete(dbFile).Run(); handleError(w, logger, err) {
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func listExpectedFileChecks(logger *log.Logger, db *database.DB) http.HandlerFunc {
+	validSorting := orders{
+		"default": order{"window_start", false},
+		"date+":   order{"window_start", true},
+		"date-":   order{"window_start", false},
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		dbFile, getErr := retrieveDBExpectedFile(r, db)
+		if handleError(w, logger, getErr) {
+			return
+		}
+
+		var dbChecks model.ExpectedFileChecks
+
+		query, queryErr := parseSelectQuery(r, db, validSorting, &dbChecks)
+		if handleError(w, logger, queryErr) {
+			return
+		}
+
+		query.Where("expected_file_id=?", dbFile.ID)
+
+		if statuses := r.Form["status"]; len(statuses) > 0 {
+			query.In("status", statuses)
+		}
+
+		if err := query.Run(); handleError(w, logger, err) {
+			return
+		}
+
+		restChecks := make([]*api.OutExpectedFileCheck, len(dbChecks))
+		for i, dbCheck := range dbChecks {
+			restChecks[i] = dbExpectedFileCheckToREST(dbCheck)
+		}
+
+		response := map[string][]*api.OutExpectedFileCheck{"checks": restChecks}
+		handleError(w, logger, writeJSON(w, response))
+	}
+}
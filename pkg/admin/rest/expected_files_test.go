@@ -0,0 +1,378 @@
+package rest
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/database/dbtest"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/types"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils/testhelpers"
+)
+
+const (
+	ExpectedFilesPath      = "/expected_files"
+	ExpectedFilePath       = "/expected_files/{expected_file}"
+	ExpectedFileChecksPath = "/expected_files/{expected_file}/checks"
+)
+
+func TestAddExpectedFile(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+	logger := testhelpers.GetTestLogger(t)
+	handle := addExpectedFile(logger, db)
+
+	dbRule := &model.Rule{Name: "rule", IsSend: false, Path: "/rule"}
+	require.NoError(t, db.Insert(dbRule).Run())
+
+	dbServer := &model.LocalAgent{
+		Name: "server", Protocol: testProto1,
+		Address: types.Addr("localhost", 1),
+	}
+	require.NoError(t, db.Insert(dbServer).Run())
+
+	dbAccount := &model.LocalAccount{LocalAgentID: dbServer.ID, Login: "toto"}
+	require.NoError(t, db.Insert(dbAccount).Run())
+
+	t.Run("Valid request", func(t *testing.T) {
+		reqBody := bytes.Buffer{}
+		require.NoError(t, json.NewEncoder(&reqBody).Encode(map[string]any{
+			"name":        "file1",
+			"rule":        dbRule.Name,
+			"server":      dbServer.Name,
+			"account":     dbAccount.Login,
+			"pattern":     "invoices_*.csv",
+			"windowStart": "06:00",
+			"windowEnd":   "07:00",
+		}))
+
+		req := httptest.NewRequest(http.MethodPost, ExpectedFilesPath, &reqBody)
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, req)
+
+		t.Run("Check response", func(t *testing.T) {
+			assert.Equal(t, http.StatusCreated, w.Code, `201 response code`)
+			assert.Equal(t, ExpectedFilesPath+"/file1", w.Header().Get("Location"), `Correct Location header`)
+			assert.Empty(t, w.Body.String(), `Empty response body`)
+		})
+
+		t.Run("Check database", func(t *testing.T) {
+			var check model.ExpectedFile
+			require.NoError(t, db.Get(&check, "name=?", "file1").Run())
+
+			assert.Equal(t, dbRule.ID, check.RuleID)
+			assert.False(t, check.RemoteAgentID.Valid)
+			assert.Equal(t, sql.NullInt64{Int64: dbAccount.ID, Valid: true}, check.LocalAccountID)
+			assert.Equal(t, "invoices_*.csv", check.Pattern)
+			assert.Equal(t, "06:00", check.WindowStart)
+			assert.Equal(t, "07:00", check.WindowEnd)
+			assert.Equal(t, model.List[string]{"monday", "tuesday", "wednesday", "thursday", "friday"},
+				check.Days)
+		})
+	})
+
+	t.Run("Missing server", func(t *testing.T) {
+		reqBody := bytes.Buffer{}
+		require.NoError(t, json.NewEncoder(&reqBody).Encode(map[string]any{
+			"name":        "file2",
+			"rule":        dbRule.Name,
+			"account":     dbAccount.Login,
+			"windowStart": "06:00",
+			"windowEnd":   "07:00",
+		}))
+
+		req := httptest.NewRequest(http.MethodPost, ExpectedFilesPath, &reqBody)
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, `400 response code`)
+		assert.Contains(t, w.Body.String(), "the local account's server is missing", `Correct response body`)
+	})
+
+	t.Run("Unknown rule", func(t *testing.T) {
+		reqBody := bytes.Buffer{}
+		require.NoError(t, json.NewEncoder(&reqBody).Encode(map[string]any{
+			"name":        "file3",
+			"rule":        "unknown",
+			"windowStart": "06:00",
+			"windowEnd":   "07:00",
+		}))
+
+		req := httptest.NewRequest(http.MethodPost, ExpectedFilesPath, &reqBody)
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, `400 response code`)
+		assert.Contains(t, w.Body.String(), `no receive rule "unknown" found`, `Correct response body`)
+	})
+}
+
+func TestListExpectedFiles(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+	logger := testhelpers.GetTestLogger(t)
+	handle := listExpectedFiles(logger, db)
+
+	dbRule := &model.Rule{Name: "rule", IsSend: false, Path: "/rule"}
+	require.NoError(t, db.Insert(dbRule).Run())
+
+	dbPartner := &model.RemoteAgent{
+		Name: "partner", Protocol: testProto1,
+		Address: types.Addr("localhost", 1),
+	}
+	require.NoError(t, db.Insert(dbPartner).Run())
+
+	dbFile1 := &model.ExpectedFile{
+		Name: "file1", RuleID: dbRule.ID,
+		WindowStart: "06:00", WindowEnd: "07:00", Days: model.List[string]{"monday"},
+	}
+	require.NoError(t, db.Insert(dbFile1).Run())
+
+	dbFile2 := &model.ExpectedFile{
+		Name: "file2", RuleID: dbRule.ID, Disabled: true,
+		RemoteAgentID: sql.NullInt64{Int64: dbPartner.ID, Valid: true},
+		Pattern:       "*.csv", WindowStart: "22:00", WindowEnd: "02:00",
+		Days: model.List[string]{"sunday"},
+	}
+	require.NoError(t, db.Insert(dbFile2).Run())
+
+	req := httptest.NewRequest(http.MethodGet, ExpectedFilesPath+"?sort=name-", nil)
+	w := httptest.NewRecorder()
+	handle.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, `200 response code`)
+	assert.JSONEq(t, marshal(t, map[string]any{
+		"expectedFiles": []any{
+			map[string]any{
+				"name": dbFile2.Name, "disabled": true, "rule": dbRule.Name,
+				"partner": dbPartner.Name, "pattern": "*.csv",
+				"windowStart": "22:00", "windowEnd": "02:00", "days": []any{"sunday"},
+			},
+			map[string]any{
+				"name": dbFile1.Name, "disabled": false, "rule": dbRule.Name,
+				"pattern": "*", "windowStart": "06:00", "windowEnd": "07:00",
+				"days": []any{"monday"},
+			},
+		},
+	}), w.Body.String(), `Correct response body`)
+}
+
+func TestGetExpectedFile(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+	logger := testhelpers.GetTestLogger(t)
+	handle := getExpectedFile(logger, db)
+
+	dbRule := &model.Rule{Name: "rule", IsSend: false, Path: "/rule"}
+	require.NoError(t, db.Insert(dbRule).Run())
+
+	dbFile := &model.ExpectedFile{
+		Name: "file1", RuleID: dbRule.ID,
+		WindowStart: "06:00", WindowEnd: "07:00", Days: model.List[string]{"monday"},
+	}
+	require.NoError(t, db.Insert(dbFile).Run())
+
+	now := time.Now().Truncate(time.Second)
+	dbCheck := &model.ExpectedFileCheck{
+		ExpectedFileID: dbFile.ID, Status: model.ExpectedFileMissing,
+		WindowStart: now.Add(-2 * time.Hour), WindowEnd: now.Add(-time.Hour),
+		Checked: now,
+	}
+	require.NoError(t, db.Insert(dbCheck).Run())
+
+	t.Run("Valid request", func(t *testing.T) {
+		req := makeRequest(http.MethodGet, nil, ExpectedFilePath, dbFile.Name)
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, `200 response code`)
+		assert.JSONEq(t, marshal(t, map[string]any{
+			"name":        dbFile.Name,
+			"disabled":    false,
+			"rule":        dbRule.Name,
+			"pattern":     "*",
+			"windowStart": "06:00",
+			"windowEnd":   "07:00",
+			"days":        []any{"monday"},
+			"lastCheck": map[string]any{
+				"windowStart": dbCheck.WindowStart.Local(),
+				"windowEnd":   dbCheck.WindowEnd.Local(),
+				"status":      "missing",
+				"files":       []any{},
+				"checked":     dbCheck.Checked.Local(),
+			},
+		}), w.Body.String(), `Correct response body`)
+	})
+
+	t.Run("Unknown expected file", func(t *testing.T) {
+		req := makeRequest(http.MethodGet, nil, ExpectedFilePath, "unknown")
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code, `404 response code`)
+	})
+}
+
+func TestUpdateExpectedFile(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+	logger := testhelpers.GetTestLogger(t)
+	handle := updateExpectedFile(logger, db)
+
+	dbRule := &model.Rule{Name: "rule", IsSend: false, Path: "/rule"}
+	require.NoError(t, db.Insert(dbRule).Run())
+
+	dbPartner := &model.RemoteAgent{
+		Name: "partner", Protocol: testProto1,
+		Address: types.Addr("localhost", 1),
+	}
+	require.NoError(t, db.Insert(dbPartner).Run())
+
+	dbFile := &model.ExpectedFile{
+		Name: "file1", RuleID: dbRule.ID, Pattern: "*.csv",
+		WindowStart: "06:00", WindowEnd: "07:00",
+	}
+	require.NoError(t, db.Insert(dbFile).Run())
+
+	reqBody := bytes.Buffer{}
+	require.NoError(t, json.NewEncoder(&reqBody).Encode(map[string]any{
+		"name":      "file1_new",
+		"partner":   dbPartner.Name,
+		"windowEnd": "08:30",
+		"disabled":  true,
+	}))
+
+	req := makeRequest(http.MethodPatch, &reqBody, ExpectedFilePath, dbFile.Name)
+	w := httptest.NewRecorder()
+	handle.ServeHTTP(w, req)
+
+	t.Run("Check response", func(t *testing.T) {
+		assert.Equal(t, http.StatusCreated, w.Code, `201 response code`)
+		assert.Equal(t, ExpectedFilesPath+"/file1_new", w.Header().Get("Location"), `Correct Location header`)
+	})
+
+	t.Run("Check database", func(t *testing.T) {
+		var check model.ExpectedFile
+		require.NoError(t, db.Get(&check, "name=?", "file1_new").Run())
+
+		assert.True(t, check.Disabled)
+		assert.Equal(t, sql.NullInt64{Int64: dbPartner.ID, Valid: true}, check.RemoteAgentID)
+		assert.Equal(t, "*.csv", check.Pattern, "The pattern should be unchanged")
+		assert.Equal(t, "06:00", check.WindowStart, "The window start should be unchanged")
+		assert.Equal(t, "08:30", check.WindowEnd)
+		assert.True(t, dbFile.Created.Equal(check.Created), "The creation date should be unchanged")
+	})
+}
+
+func TestDeleteExpectedFile(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+	logger := testhelpers.GetTestLogger(t)
+	handle := deleteExpectedFile(logger, db)
+
+	dbRule := &model.Rule{Name: "rule", IsSend: false, Path: "/rule"}
+	require.NoError(t, db.Insert(dbRule).Run())
+
+	dbFile := &model.ExpectedFile{
+		Name: "file1", RuleID: dbRule.ID,
+		WindowStart: "06:00", WindowEnd: "07:00",
+	}
+	require.NoError(t, db.Insert(dbFile).Run())
+
+	require.NoError(t, db.Insert(&model.ExpectedFileCheck{
+		ExpectedFileID: dbFile.ID, Status: model.ExpectedFileReceived,
+		WindowStart: time.Now(), WindowEnd: time.Now(), Checked: time.Now(),
+	}).Run())
+
+	req := makeRequest(http.MethodDelete, nil, ExpectedFilePath, dbFile.Name)
+	w := httptest.NewRecorder()
+	handle.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code, `204 response code`)
+
+	var nfe *database.NotFoundError
+	require.ErrorAs(t, db.Get(&model.ExpectedFile{}, "name=?", dbFile.Name).Run(), &nfe)
+
+	var checks model.ExpectedFileChecks
+	require.NoError(t, db.Select(&checks).Run())
+	assert.Empty(t, checks, "The expected file's checks should have been deleted")
+}
+
+func TestListExpectedFileChecks(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+	logger := testhelpers.GetTestLogger(t)
+	handle := listExpectedFileChecks(logger, db)
+
+	dbRule := &model.Rule{Name: "rule", IsSend: false, Path: "/rule"}
+	require.NoError(t, db.Insert(dbRule).Run())
+
+	dbFile := &model.ExpectedFile{
+		Name: "file1", RuleID: dbRule.ID,
+		WindowStart: "06:00", WindowEnd: "07:00",
+	}
+	require.NoError(t, db.Insert(dbFile).Run())
+
+	now := time.Now().Truncate(time.Second)
+
+	dbCheck1 := &model.ExpectedFileCheck{
+		ExpectedFileID: dbFile.ID, Status: model.ExpectedFileDuplicate,
+		WindowStart: now.Add(-48 * time.Hour), WindowEnd: now.Add(-47 * time.Hour),
+		Files: model.List[string]{"a.csv", "b.csv"}, Checked: now.Add(-47 * time.Hour),
+	}
+	require.NoError(t, db.Insert(dbCheck1).Run())
+
+	dbCheck2 := &model.ExpectedFileCheck{
+		ExpectedFileID: dbFile.ID, Status: model.ExpectedFileReceived,
+		WindowStart: now.Add(-24 * time.Hour), WindowEnd: now.Add(-23 * time.Hour),
+		Files: model.List[string]{"c.csv"}, Checked: now.Add(-23 * time.Hour),
+	}
+	require.NoError(t, db.Insert(dbCheck2).Run())
+
+	t.Run("All checks", func(t *testing.T) {
+		req := makeRequest(http.MethodGet, nil, ExpectedFileChecksPath, dbFile.Name)
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, `200 response code`)
+		assert.JSONEq(t, marshal(t, map[string]any{
+			"checks": []any{
+				map[string]any{
+					"windowStart": dbCheck2.WindowStart.Local(), "windowEnd": dbCheck2.WindowEnd.Local(),
+					"status": "received", "files": []any{"c.csv"},
+					"checked": dbCheck2.Checked.Local(),
+				},
+				map[string]any{
+					"windowStart": dbCheck1.WindowStart.Local(), "windowEnd": dbCheck1.WindowEnd.Local(),
+					"status": "duplicate", "files": []any{"a.csv", "b.csv"},
+					"checked": dbCheck1.Checked.Local(),
+				},
+			},
+		}), w.Body.String(), `Correct response body`)
+	})
+
+	t.Run("Filtered by status", func(t *testing.T) {
+		req := makeRequest(http.MethodGet, nil, ExpectedFileChecksPath+"?status=duplicate", dbFile.Name)
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, `200 response code`)
+		assert.Contains(t, w.Body.String(), `"status":"duplicate"`)
+		assert.NotContains(t, w.Body.String(), `"status":"received"`)
+	})
+}
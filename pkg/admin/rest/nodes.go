@@ -0,0 +1,98 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/admin/rest/api"
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+)
+
+func retrieveNode(r *http.Request, db *database.DB) (*model.ClusterNode, error) {
+	name, ok := mux.Vars(r)["node"]
+	if !ok {
+		return nil, notFound("missing node name")
+	}
+
+	var node model.ClusterNode
+	if err := db.Get(&node, "name=?", name).Run(); err != nil {
+		if database.IsNotFound(err) {
+			return nil, notFoundf("node %q not found", name)
+		}
+
+		return nil, fmt.Errorf("failed to retrieve node %q: %w", name, err)
+	}
+
+	return &node, nil
+}
+
+func dbNodeToREST(db *database.DB, node *model.ClusterNode) (*api.GetNodeRespObject, error) {
+	running, err := db.Count(&model.RunningTransfer{}).Where("node=?", node.Name).Run()
+	if err != nil {
+		return nil, fmt.Errorf("failed to count the running transfers of node %q: %w",
+			node.Name, err)
+	}
+
+	return &api.GetNodeRespObject{
+		Name:             node.Name,
+		Hostname:         node.Hostname,
+		Status:           node.Status(),
+		StartedAt:        node.StartedAt,
+		LastHeartbeat:    node.LastHeartbeat,
+		LeaseExpiry:      node.LeaseExpiry,
+		RunningTransfers: int64(running),
+	}, nil
+}
+
+func listNodes(logger *log.Logger, db *database.DB) http.HandlerFunc {
+	validSorting := orders{
+		"default": order{"name", true},
+		"name+":   order{"name", true},
+		"name-":   order{"name", false},
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var dbNodes model.ClusterNodes
+
+		query, queryErr := parseSelectQuery(r, db, validSorting, &dbNodes)
+		if handleError(w, logger, queryErr) {
+			return
+		}
+
+		if err := query.Run(); handleError(w, logger, err) {
+			return
+		}
+
+		restNodes := make([]*api.GetNodeRespObject, len(dbNodes))
+
+		for i, dbNode := range dbNodes {
+			var err error
+			if restNodes[i], err = dbNodeToREST(db, dbNode); handleError(w, logger, err) {
+				return
+			}
+		}
+
+		response := map[string][]*api.GetNodeRespObject{"nodes": restNodes}
+		handleError(w, logger, writeJSON(w, response))
+	}
+}
+
+func getNode(logger *log.Logger, db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dbNode, getErr := retrieveNode(r, db)
+		if handleError(w, logger, getErr) {
+			return
+		}
+
+		restNode, convErr := dbNodeToREST(db, dbNode)
+		if handleError(w, logger, convErr) {
+			return
+		}
+
+		handleError(w, logger, writeJSON(w, restNode))
+	}
+}
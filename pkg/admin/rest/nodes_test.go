@@ -0,0 +1,134 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/admin/rest/api"
+	"code.waarp.fr/apps/gateway/gateway/pkg/database/dbtest"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/types"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils/testhelpers"
+)
+
+const (
+	NodesPath = "/nodes"
+	NodePath  = "/nodes/{node}"
+)
+
+func TestListNodes(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+	logger := testhelpers.GetTestLogger(t)
+	handle := listNodes(logger, db)
+
+	now := time.Now()
+
+	alive := &model.ClusterNode{
+		Name: "node1", Hostname: "host1", StartedAt: now.Add(-time.Hour),
+		LastHeartbeat: now, LeaseExpiry: now.Add(time.Minute),
+	}
+	require.NoError(t, db.Insert(alive).Run())
+
+	dead := &model.ClusterNode{
+		Name: "node2", Hostname: "host2", StartedAt: now.Add(-time.Hour),
+		LastHeartbeat: now.Add(-time.Hour), LeaseExpiry: now.Add(-time.Hour),
+	}
+	require.NoError(t, db.Insert(dead).Run())
+
+	req := httptest.NewRequest(http.MethodGet, NodesPath+"?sort=name-", nil)
+	w := httptest.NewRecorder()
+	handle.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code, `200 response code`)
+
+	var body map[string][]*api.GetNodeRespObject
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Len(t, body["nodes"], 2)
+
+	assert.Equal(t, "node2", body["nodes"][0].Name)
+	assert.Equal(t, "host2", body["nodes"][0].Hostname)
+	assert.Equal(t, model.NodeStatusDead, body["nodes"][0].Status)
+
+	assert.Equal(t, "node1", body["nodes"][1].Name)
+	assert.Equal(t, "host1", body["nodes"][1].Hostname)
+	assert.Equal(t, model.NodeStatusAlive, body["nodes"][1].Status)
+}
+
+func TestGetNode(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+	logger := testhelpers.GetTestLogger(t)
+	handle := getNode(logger, db)
+
+	now := time.Now()
+
+	node := &model.ClusterNode{
+		Name: "node1", Hostname: "host1", StartedAt: now.Add(-time.Hour),
+		LastHeartbeat: now, LeaseExpiry: now.Add(time.Minute),
+	}
+	require.NoError(t, db.Insert(node).Run())
+
+	client := &model.Client{Name: "client", Protocol: testProto1}
+	require.NoError(t, db.Insert(client).Run())
+
+	partner := &model.RemoteAgent{
+		Name: "partner", Protocol: testProto1,
+		Address: types.Addr("localhost", 1),
+	}
+	require.NoError(t, db.Insert(partner).Run())
+
+	account := &model.RemoteAccount{RemoteAgentID: partner.ID, Login: "toto"}
+	require.NoError(t, db.Insert(account).Run())
+
+	rule := &model.Rule{Name: "rule", IsSend: true, Path: "path"}
+	require.NoError(t, db.Insert(rule).Run())
+
+	trans := &model.Transfer{
+		RuleID:          rule.ID,
+		ClientID:        client.NullableID(),
+		RemoteAccountID: account.NullableID(),
+		SrcFilename:     "file",
+		Status:          types.StatusRunning,
+	}
+	require.NoError(t, db.Insert(trans).Run())
+	require.NoError(t, db.Insert(&model.RunningTransfer{
+		TransferID: trans.ID, Node: node.Name,
+		Since: now, LeaseExpiry: node.LeaseExpiry,
+	}).Run())
+
+	t.Run("Valid request", func(t *testing.T) {
+		req := makeRequest(http.MethodGet, nil, NodePath, node.Name)
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code, `200 response code`)
+
+		var body api.GetNodeRespObject
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+
+		assert.Equal(t, node.Name, body.Name)
+		assert.Equal(t, node.Hostname, body.Hostname)
+		assert.Equal(t, model.NodeStatusAlive, body.Status)
+		assert.WithinDuration(t, node.StartedAt, body.StartedAt, time.Second)
+		assert.WithinDuration(t, node.LastHeartbeat, body.LastHeartbeat, time.Second)
+		assert.WithinDuration(t, node.LeaseExpiry, body.LeaseExpiry, time.Second)
+		assert.EqualValues(t, 1, body.RunningTransfers)
+	})
+
+	t.Run("Unknown node", func(t *testing.T) {
+		req := makeRequest(http.MethodGet, nil, NodePath, "unknown")
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code, `404 response code`)
+	})
+}
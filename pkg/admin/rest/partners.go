@@ -133,6 +133,7 @@ func updatePartner(logger *log.Logger, db *database.DB) http.HandlerFunc {
 			Protocol:    asNullable(oldPartner.Protocol),
 			Address:     asNullable(oldPartner.Address.String()),
 			ProtoConfig: api.UpdateObject[any](oldPartner.ProtoConfig),
+			RetryPolicy: asNullable(oldPartner.RetryPolicy),
 		}
 		if err := readJSON(r, restPartner); handleError(w, logger, err) {
 			return
@@ -142,6 +143,7 @@ func updatePartner(logger *log.Logger, db *database.DB) http.HandlerFunc {
 			Name:        restPartner.Name.Value,
 			Protocol:    restPartner.Protocol.Value,
 			ProtoConfig: model.Map[any](restPartner.ProtoConfig),
+			RetryPolicy: restPartner.RetryPolicy.Value,
 		}
 
 		if err := dbPartner.Address.Set(restPartner.Address.Value); handleError(w, logger, err) {
@@ -109,6 +109,13 @@ func handleError(w http.ResponseWriter, logger *log.Logger, err error) bool {
 		return true
 	}
 
+	var co *conflictError
+	if errors.As(err, &co) {
+		http.Error(w, co.Error(), http.StatusConflict)
+
+		return true
+	}
+
 	logger.Errorf("Unexpected error: %v", err)
 	http.Error(w, err.Error(), http.StatusInternalServerError)
 
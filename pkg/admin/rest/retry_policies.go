@@ -0,0 +1,180 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/admin/rest/api"
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+)
+
+func retrieveRetryPolicy(r *http.Request, db *database.DB) (*model.RetryPolicy, error) {
+	name, ok := mux.Vars(r)["retry_policy"]
+	if !ok {
+		return nil, notFound("missing retry policy name")
+	}
+
+	var policy model.RetryPolicy
+	if err := db.Get(&policy, "name=?", name).Run(); err != nil {
+		if database.IsNotFound(err) {
+			return nil, notFoundf("retry policy %q not found", name)
+		}
+
+		return nil, fmt.Errorf("failed to retrieve retry policy %q: %w", name, err)
+	}
+
+	return &policy, nil
+}
+
+func dbRetryPolicyToREST(policy *model.RetryPolicy) *api.GetRetryPolicyObject {
+	errorCodes := make(map[string]string, len(policy.ErrorCodes))
+	for code, action := range policy.ErrorCodes {
+		errorCodes[code] = string(action)
+	}
+
+	return &api.GetRetryPolicyObject{
+		Name:            policy.Name,
+		MaxAttempts:     policy.MaxAttempts,
+		FirstDelay:      api.Duration(policy.FirstDelay),
+		IncrementFactor: policy.IncrementFactor,
+		MaxDelay:        api.Duration(policy.MaxDelay),
+		Jitter:          policy.Jitter,
+		MaxWindow:       api.Duration(policy.MaxWindow),
+		ErrorCodes:      errorCodes,
+	}
+}
+
+func restRetryActions(errorCodes map[string]string) model.Map[model.RetryAction] {
+	actions := make(model.Map[model.RetryAction], len(errorCodes))
+	for code, action := range errorCodes {
+		actions[code] = model.RetryAction(action)
+	}
+
+	return actions
+}
+
+func setDurationIfValid(field *time.Duration, value api.Nullable[api.Duration]) {
+	if value.Valid {
+		*field = time.Duration(value.Value)
+	}
+}
+
+func addRetryPolicy(logger *log.Logger, db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var restPolicy api.PostRetryPolicyObject
+		if err := readJSON(r, &restPolicy); handleError(w, logger, err) {
+			return
+		}
+
+		dbPolicy := model.RetryPolicy{
+			Name:            restPolicy.Name,
+			MaxAttempts:     restPolicy.MaxAttempts,
+			FirstDelay:      time.Duration(restPolicy.FirstDelay),
+			IncrementFactor: restPolicy.IncrementFactor,
+			MaxDelay:        time.Duration(restPolicy.MaxDelay),
+			Jitter:          restPolicy.Jitter,
+			MaxWindow:       time.Duration(restPolicy.MaxWindow),
+			ErrorCodes:      restRetryActions(restPolicy.ErrorCodes),
+		}
+		if err := db.Insert(&dbPolicy).Run(); handleError(w, logger, err) {
+			return
+		}
+
+		w.Header().Set("Location", location(r.URL, dbPolicy.Name))
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+func listRetryPolicies(logger *log.Logger, db *database.DB) http.HandlerFunc {
+	//nolint:goconst //duplicate is for a different type, keep separate
+	validSorting := orders{
+		"default": order{"name", true},
+		"name+":   order{"name", true},
+		"name-":   order{"name", false},
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var dbPolicies model.RetryPolicies
+
+		query, queryErr := parseSelectQuery(r, db, validSorting, &dbPolicies)
+		if handleError(w, logger, queryErr) {
+			return
+		}
+
+		if err := query.Run(); handleError(w, logger, err) {
+			return
+		}
+
+		restPolicies := make([]*api.GetRetryPolicyObject, len(dbPolicies))
+		for i, dbPolicy := range dbPolicies {
+			restPolicies[i] = dbRetryPolicyToREST(dbPolicy)
+		}
+
+		response := map[string][]*api.GetRetryPolicyObject{"retryPolicies": restPolicies}
+		handleError(w, logger, writeJSON(w, response))
+	}
+}
+
+func getRetryPolicy(logger *log.Logger, db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dbPolicy, getErr := retrieveRetryPolicy(r, db)
+		if handleError(w, logger, getErr) {
+			return
+		}
+
+		handleError(w, logger, writeJSON(w, dbRetryPolicyToREST(dbPolicy)))
+	}
+}
+
+func updateRetryPolicy(logger *log.Logger, db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dbPolicy, getErr := retrieveRetryPolicy(r, db)
+		if handleError(w, logger, getErr) {
+			return
+		}
+
+		var restPolicy api.PatchRetryPolicyObject
+		if err := readJSON(r, &restPolicy); handleError(w, logger, err) {
+			return
+		}
+
+		setIfValid(&dbPolicy.Name, restPolicy.Name)
+		setIfValid(&dbPolicy.MaxAttempts, restPolicy.MaxAttempts)
+		setDurationIfValid(&dbPolicy.FirstDelay, restPolicy.FirstDelay)
+		setIfValid(&dbPolicy.IncrementFactor, restPolicy.IncrementFactor)
+		setDurationIfValid(&dbPolicy.MaxDelay, restPolicy.MaxDelay)
+		setIfValid(&dbPolicy.Jitter, restPolicy.Jitter)
+		setDurationIfValid(&dbPolicy.MaxWindow, restPolicy.MaxWindow)
+
+		if restPolicy.ErrorCodes != nil {
+			dbPolicy.ErrorCodes = restRetryActions(restPolicy.ErrorCodes)
+		}
+
+		if err := db.Update(dbPolicy).Run(); handleError(w, logger, err) {
+			return
+		}
+
+		w.Header().Set("Location", locationUpdate(r.URL, dbPolicy.Name))
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+func deleteRetryPolicy(logger *log.Logger, db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dbPolicy, getErr := retrieveRetryPolicy(r, db)
+		if handleError(w, logger, getErr) {
+			return
+		}
+
+		if err := db.Delete(dbPolicy).Run(); handleError(w, logger, err) {
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,241 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/database/dbtest"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/types"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils/testhelpers"
+)
+
+const (
+	RetryPoliciesPath = "/retry_policies"
+	RetryPolicyPath   = "/retry_policies/{retry_policy}"
+)
+
+func TestAddRetryPolicy(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+	logger := testhelpers.GetTestLogger(t)
+	handle := addRetryPolicy(logger, db)
+
+	t.Run("Valid request", func(t *testing.T) {
+		reqBody := bytes.Buffer{}
+		require.NoError(t, json.NewEncoder(&reqBody).Encode(map[string]any{
+			"name":            "policy1",
+			"maxAttempts":     5,
+			"firstDelay":      "30s",
+			"incrementFactor": 2,
+			"maxDelay":        "1h",
+			"jitter":          0.5,
+			"maxWindow":       "24h",
+			"errorCodes":      map[string]any{"TeForbidden": "never"},
+		}))
+
+		req := httptest.NewRequest(http.MethodPost, RetryPoliciesPath, &reqBody)
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, req)
+
+		t.Run("Check response", func(t *testing.T) {
+			assert.Equal(t, http.StatusCreated, w.Code, `201 response code`)
+			assert.Equal(t, RetryPoliciesPath+"/policy1", w.Header().Get("Location"), `Correct Location header`)
+			assert.Empty(t, w.Body.String(), `Empty response body`)
+		})
+
+		t.Run("Check database", func(t *testing.T) {
+			var check model.RetryPolicy
+			require.NoError(t, db.Get(&check, "name=?", "policy1").Run())
+
+			assert.EqualValues(t, 5, check.MaxAttempts)
+			assert.Equal(t, 30*time.Second, check.FirstDelay)
+			assert.InDelta(t, 2, check.IncrementFactor, 0)
+			assert.Equal(t, time.Hour, check.MaxDelay)
+			assert.InDelta(t, 0.5, check.Jitter, 0)
+			assert.Equal(t, 24*time.Hour, check.MaxWindow)
+			assert.Equal(t, model.Map[model.RetryAction]{"TeForbidden": model.RetryActionNever},
+				check.ErrorCodes)
+		})
+	})
+
+	t.Run("Invalid error code", func(t *testing.T) {
+		reqBody := bytes.Buffer{}
+		require.NoError(t, json.NewEncoder(&reqBody).Encode(map[string]any{
+			"name":       "policy2",
+			"errorCodes": map[string]any{"TeUnknown": "never"},
+		}))
+
+		req := httptest.NewRequest(http.MethodPost, RetryPoliciesPath, &reqBody)
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, `400 response code`)
+		assert.Contains(t, w.Body.String(), "the retry policy is invalid", `Correct response body`)
+	})
+}
+
+func TestListRetryPolicies(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+	logger := testhelpers.GetTestLogger(t)
+	handle := listRetryPolicies(logger, db)
+
+	dbPolicy1 := &model.RetryPolicy{Name: "policy1", MaxAttempts: 3, FirstDelay: time.Minute}
+	require.NoError(t, db.Insert(dbPolicy1).Run())
+
+	dbPolicy2 := &model.RetryPolicy{
+		Name: "policy2", MaxAttempts: 5, MaxWindow: time.Hour,
+		ErrorCodes: model.Map[model.RetryAction]{"TeConnection": model.RetryActionAlways},
+	}
+	require.NoError(t, db.Insert(dbPolicy2).Run())
+
+	req := httptest.NewRequest(http.MethodGet, RetryPoliciesPath+"?sort=name-", nil)
+	w := httptest.NewRecorder()
+	handle.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, `200 response code`)
+	assert.JSONEq(t, marshal(t, map[string]any{
+		"retryPolicies": []any{
+			map[string]any{
+				"name": dbPolicy2.Name, "maxAttempts": 5, "firstDelay": "0s",
+				"incrementFactor": 1, "maxDelay": "0s", "jitter": 0, "maxWindow": "1h0m0s",
+				"errorCodes": map[string]any{"TeConnection": "always"},
+			},
+			map[string]any{
+				"name": dbPolicy1.Name, "maxAttempts": 3, "firstDelay": "1m0s",
+				"incrementFactor": 1, "maxDelay": "0s", "jitter": 0, "maxWindow": "0s",
+			},
+		},
+	}), w.Body.String(), `Correct response body`)
+}
+
+func TestGetRetryPolicy(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+	logger := testhelpers.GetTestLogger(t)
+	handle := getRetryPolicy(logger, db)
+
+	dbPolicy := &model.RetryPolicy{
+		Name: "policy1", MaxAttempts: 5, FirstDelay: 30 * time.Second,
+		IncrementFactor: 2, Jitter: 0.25,
+	}
+	require.NoError(t, db.Insert(dbPolicy).Run())
+
+	t.Run("Valid request", func(t *testing.T) {
+		req := makeRequest(http.MethodGet, nil, RetryPolicyPath, dbPolicy.Name)
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, `200 response code`)
+		assert.JSONEq(t, marshal(t, map[string]any{
+			"name":            dbPolicy.Name,
+			"maxAttempts":     5,
+			"firstDelay":      "30s",
+			"incrementFactor": 2,
+			"maxDelay":        "0s",
+			"jitter":          0.25,
+			"maxWindow":       "0s",
+		}), w.Body.String(), `Correct response body`)
+	})
+
+	t.Run("Unknown retry policy", func(t *testing.T) {
+		req := makeRequest(http.MethodGet, nil, RetryPolicyPath, "unknown")
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code, `404 response code`)
+	})
+}
+
+func TestUpdateRetryPolicy(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+	logger := testhelpers.GetTestLogger(t)
+	handle := updateRetryPolicy(logger, db)
+
+	dbPolicy := &model.RetryPolicy{Name: "policy1", MaxAttempts: 5, FirstDelay: time.Minute}
+	require.NoError(t, db.Insert(dbPolicy).Run())
+
+	dbRule := &model.Rule{Name: "rule", IsSend: true, Path: "/rule", RetryPolicy: dbPolicy.Name}
+	require.NoError(t, db.Insert(dbRule).Run())
+
+	reqBody := bytes.Buffer{}
+	require.NoError(t, json.NewEncoder(&reqBody).Encode(map[string]any{
+		"name":      "policy1_new",
+		"maxWindow": "2h",
+	}))
+
+	req := makeRequest(http.MethodPatch, &reqBody, RetryPolicyPath, dbPolicy.Name)
+	w := httptest.NewRecorder()
+	handle.ServeHTTP(w, req)
+
+	t.Run("Check response", func(t *testing.T) {
+		assert.Equal(t, http.StatusCreated, w.Code, `201 response code`)
+		assert.Equal(t, RetryPoliciesPath+"/policy1_new", w.Header().Get("Location"), `Correct Location header`)
+	})
+
+	t.Run("Check database", func(t *testing.T) {
+		var check model.RetryPolicy
+		require.NoError(t, db.Get(&check, "name=?", "policy1_new").Run())
+
+		assert.Equal(t, 2*time.Hour, check.MaxWindow)
+		assert.Equal(t, dbPolicy.FirstDelay, check.FirstDelay, "The first delay should be unchanged")
+
+		var rule model.Rule
+		require.NoError(t, db.Get(&rule, "id=?", dbRule.ID).Run())
+		assert.Equal(t, "policy1_new", rule.RetryPolicy, "The rule's policy should have been renamed")
+	})
+}
+
+func TestDeleteRetryPolicy(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+	logger := testhelpers.GetTestLogger(t)
+	handle := deleteRetryPolicy(logger, db)
+
+	t.Run("Unused policy", func(t *testing.T) {
+		dbPolicy := &model.RetryPolicy{Name: "policy1"}
+		require.NoError(t, db.Insert(dbPolicy).Run())
+
+		req := makeRequest(http.MethodDelete, nil, RetryPolicyPath, dbPolicy.Name)
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code, `204 response code`)
+
+		var nfe *database.NotFoundError
+		assert.ErrorAs(t, db.Get(&model.RetryPolicy{}, "name=?", dbPolicy.Name).Run(), &nfe)
+	})
+
+	t.Run("Policy in use", func(t *testing.T) {
+		dbPolicy := &model.RetryPolicy{Name: "policy2"}
+		require.NoError(t, db.Insert(dbPolicy).Run())
+
+		dbPartner := &model.RemoteAgent{
+			Name: "partner", Protocol: testProto1, RetryPolicy: dbPolicy.Name,
+			Address: types.Addr("localhost", 1),
+		}
+		require.NoError(t, db.Insert(dbPartner).Run())
+
+		req := makeRequest(http.MethodDelete, nil, RetryPolicyPath, dbPolicy.Name)
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, `400 response code`)
+		assert.Contains(t, w.Body.String(), "the retry policy is still in use", `Correct response body`)
+	})
+}
@@ -25,7 +25,7 @@ func MakeRESTHandler(logger *log.Logger, db *database.DB, router *mux.Router) {
 	)
 
 	router.Name("GET /status").Path("/status").Methods(http.MethodGet).
-		Handler(getStatus(logger))
+		Handler(getStatus(logger, db))
 	router.Name("GET /about").Path("/about").Methods(http.MethodGet).
 		Handler(makeAbout(logger))
 
@@ -41,10 +41,19 @@ func MakeRESTHandler(logger *log.Logger, db *database.DB, router *mux.Router) {
 	makeClientHandlers(mkHandler)
 	makeFilewatcherHandlers(mkHandler)
 	makeCloudHandlers(mkHandler)
+	makeBrokerHandlers(mkHandler)
 	makeAuthoritiesHandlers(mkHandler)
+	makeCertificatesHandlers(mkHandler)
 	makeSNMPHandlers(mkHandler)
 	makeKeysHandlers(mkHandler)
 	makeEmailHandlers(mkHandler)
+	makeScriptHandlers(mkHandler)
+	makeCopybookHandlers(mkHandler)
+	makeRetryPolicyHandlers(mkHandler)
+	makeSLAHandlers(mkHandler)
+	makeExpectedFileHandlers(mkHandler)
+	makeNodeHandlers(mkHandler)
+	mkHandler.noDB("/drain", drainGateway, model.PermAdminWrite, http.MethodPut)
 
 	if db.Config.Overrides != nil {
 		makeOverrideHandlers(mkHandler)
@@ -93,17 +102,29 @@ func makeTransferHandlers(mkHandler HandlerFactory) {
 		transPausePath  = "/transfers/{transfer}/pause"
 		transResumePath = "/transfers/{transfer}/resume"
 		transCancelPath = "/transfers/{transfer}/cancel"
+		transIntPath    = "/transfers/{transfer}/interrupt"
 		transRetryPath  = "/transfers/{transfer}/retry"
+
+		transfersPausePath      = "/transfers/pause"
+		transfersResumePath     = "/transfers/resume"
+		transfersRetryPath      = "/transfers/retry"
+		transfersReschedulePath = "/transfers/reschedule"
 	)
 
 	mkHandler(transfersPath, listTransfers, model.PermTransfersRead, http.MethodGet)
 	mkHandler(transfersPath, addTransfer, model.PermTransfersWrite, http.MethodPost)
 	mkHandler(transfersPath, preregisterServerTransfer, model.PermTransfersWrite, http.MethodPut)
 	mkHandler(transfersPath, cancelTransfers, model.PermTransfersWrite, http.MethodDelete)
+	mkHandler(transfersPausePath, pauseTransfers, model.PermTransfersWrite, http.MethodPut)
+	mkHandler(transfersResumePath, resumeTransfers, model.PermTransfersWrite, http.MethodPut)
+	mkHandler(transfersRetryPath, retryTransfers, model.PermTransfersWrite, http.MethodPut)
+	mkHandler(transfersReschedulePath, rescheduleTransfers, model.PermTransfersWrite, http.MethodPut)
 	mkHandler(transferPath, getTransfer, model.PermTransfersRead, http.MethodGet)
+	mkHandler(transferPath, updateTransfer, model.PermTransfersWrite, http.MethodPatch)
 	mkHandler(transPausePath, pauseTransfer, model.PermTransfersWrite, http.MethodPut)
 	mkHandler(transResumePath, resumeTransfer, model.PermTransfersWrite, http.MethodPut)
 	mkHandler(transCancelPath, cancelTransfer, model.PermTransfersWrite, http.MethodPut)
+	mkHandler(transIntPath, interruptTransfer, model.PermTransfersWrite, http.MethodPut)
 	mkHandler(transRetryPath, retryTransfer, model.PermTransfersWrite, http.MethodPut)
 
 	// History (deprecated)
@@ -128,6 +149,7 @@ func makeServerHandlers(mkHandler HandlerFactory) {
 		serverStartPath     = "/servers/{server}/start"
 		serverStopPath      = "/servers/{server}/stop"
 		serverRestartPath   = "/servers/{server}/restart"
+		serverDrainPath     = "/servers/{server}/drain"
 		serverCertsPath     = "/servers/{server}/certificates"
 		serverCertPath      = "/servers/{server}/certificates/{certificate}"
 		serverAuthorizePath = "/servers/{server}/authorize/{rule}/{direction:send|receive}"
@@ -155,6 +177,7 @@ func makeServerHandlers(mkHandler HandlerFactory) {
 	mkHandler(serverStartPath, startServer, model.PermServersWrite, http.MethodPut)
 	mkHandler(serverStopPath, stopServer, model.PermServersWrite, http.MethodPut)
 	mkHandler(serverRestartPath, restartServer, model.PermServersWrite, http.MethodPut)
+	mkHandler(serverDrainPath, drainServer, model.PermServersWrite, http.MethodPut)
 	mkHandler(serverCredsPath, addServerCred, model.PermServersWrite, http.MethodPost)
 	mkHandler(serverCredPath, getServerCred, model.PermServersRead, http.MethodGet)
 	mkHandler(serverCredPath, removeServerCred, model.PermServersWrite, http.MethodDelete)
@@ -263,6 +286,7 @@ func makeClientHandlers(mkHandler HandlerFactory) {
 		clientStartPath   = "/clients/{client}/start"
 		clientStopPath    = "/clients/{client}/stop"
 		clientRestartPath = "/clients/{client}/restart"
+		clientDrainPath   = "/clients/{client}/drain"
 	)
 
 	mkHandler(clientsPath, createClient, model.PermServersWrite, http.MethodPost)
@@ -274,6 +298,7 @@ func makeClientHandlers(mkHandler HandlerFactory) {
 	mkHandler(clientStartPath, startClient, model.PermServersWrite, http.MethodPut)
 	mkHandler(clientStopPath, stopClient, model.PermServersWrite, http.MethodPut)
 	mkHandler(clientRestartPath, restartClient, model.PermServersWrite, http.MethodPut)
+	mkHandler(clientDrainPath, drainClient, model.PermServersWrite, http.MethodPut)
 }
 
 func makeOverrideHandlers(mkHandler HandlerFactory) {
@@ -321,6 +346,20 @@ func makeCloudHandlers(mkHandler HandlerFactory) {
 	mkHandler(cloudInstancePath, deleteCloud, model.PermAdminDelete, http.MethodDelete)
 }
 
+func makeBrokerHandlers(mkHandler HandlerFactory) {
+	const (
+		brokersPath = "/brokers"
+		brokerPath  = "/brokers/{broker}"
+	)
+
+	mkHandler(brokersPath, listBrokers, model.PermAdminRead, http.MethodGet)
+	mkHandler(brokersPath, addBroker, model.PermAdminWrite, http.MethodPost)
+	mkHandler(brokerPath, getBroker, model.PermAdminRead, http.MethodGet)
+	mkHandler(brokerPath, updateBroker, model.PermAdminWrite, http.MethodPatch)
+	mkHandler(brokerPath, replaceBroker, model.PermAdminWrite, http.MethodPut)
+	mkHandler(brokerPath, deleteBroker, model.PermAdminDelete, http.MethodDelete)
+}
+
 func makeAuthoritiesHandlers(mkHandler HandlerFactory) {
 	const (
 		authAuthoritiesPath = "/authorities"
@@ -335,6 +374,12 @@ func makeAuthoritiesHandlers(mkHandler HandlerFactory) {
 	mkHandler(authAuthorityPath, deleteAuthAuthority, model.PermAdminWrite, http.MethodDelete)
 }
 
+func makeCertificatesHandlers(mkHandler HandlerFactory) {
+	const expiringCertsPath = "/certificates/expiring"
+
+	mkHandler(expiringCertsPath, listExpiringCertificates, model.PermAdminRead, http.MethodGet)
+}
+
 func makeSNMPHandlers(mkHandler HandlerFactory) {
 	const (
 		snmpServerPath   = "/snmp/server"
@@ -392,3 +437,82 @@ func makeEmailHandlers(mkHandler HandlerFactory) {
 	mkHandler(smtpCredentialPath, updateSMTPCredential, model.PermRulesWrite, http.MethodPatch)
 	mkHandler(smtpCredentialPath, deleteSMTPCredential, model.PermRulesDelete, http.MethodDelete)
 }
+
+func makeScriptHandlers(mkHandler HandlerFactory) {
+	const (
+		scriptsPath = "/scripts"
+		scriptPath  = "/scripts/{script}"
+	)
+
+	mkHandler(scriptsPath, addStoredText[model.Script], model.PermRulesWrite, http.MethodPost)
+	mkHandler(scriptsPath, listStoredTexts[model.Script], model.PermRulesRead, http.MethodGet)
+	mkHandler(scriptPath, getStoredText[model.Script], model.PermRulesRead, http.MethodGet)
+	mkHandler(scriptPath, updateStoredText[model.Script], model.PermRulesWrite, http.MethodPatch)
+	mkHandler(scriptPath, deleteStoredText[model.Script], model.PermRulesDelete, http.MethodDelete)
+}
+
+func makeCopybookHandlers(mkHandler HandlerFactory) {
+	const (
+		copybooksPath = "/copybooks"
+		copybookPath  = "/copybooks/{copybook}"
+	)
+
+	mkHandler(copybooksPath, addStoredText[model.Copybook], model.PermRulesWrite, http.MethodPost)
+	mkHandler(copybooksPath, listStoredTexts[model.Copybook], model.PermRulesRead, http.MethodGet)
+	mkHandler(copybookPath, getStoredText[model.Copybook], model.PermRulesRead, http.MethodGet)
+	mkHandler(copybookPath, updateStoredText[model.Copybook], model.PermRulesWrite, http.MethodPatch)
+	mkHandler(copybookPath, deleteStoredText[model.Copybook], model.PermRulesDelete, http.MethodDelete)
+}
+
+func makeRetryPolicyHandlers(mkHandler HandlerFactory) {
+	const (
+		retryPoliciesPath = "/retry_policies"
+		retryPolicyPath   = "/retry_policies/{retry_policy}"
+	)
+
+	mkHandler(retryPoliciesPath, addRetryPolicy, model.PermRulesWrite, http.MethodPost)
+	mkHandler(retryPoliciesPath, listRetryPolicies, model.PermRulesRead, http.MethodGet)
+	mkHandler(retryPolicyPath, getRetryPolicy, model.PermRulesRead, http.MethodGet)
+	mkHandler(retryPolicyPath, updateRetryPolicy, model.PermRulesWrite, http.MethodPatch)
+	mkHandler(retryPolicyPath, deleteRetryPolicy, model.PermRulesDelete, http.MethodDelete)
+}
+
+func makeSLAHandlers(mkHandler HandlerFactory) {
+	const (
+		slasPath        = "/slas"
+		slaPath         = "/slas/{sla}"
+		slaBreachesPath = "/slas/{sla}/breaches"
+	)
+
+	mkHandler(slasPath, addSLA, model.PermRulesWrite, http.MethodPost)
+	mkHandler(slasPath, listSLAs, model.PermRulesRead, http.MethodGet)
+	mkHandler(slaPath, getSLA, model.PermRulesRead, http.MethodGet)
+	mkHandler(slaPath, updateSLA, model.PermRulesWrite, http.MethodPatch)
+	mkHandler(slaPath, deleteSLA, model.PermRulesDelete, http.MethodDelete)
+	mkHandler(slaBreachesPath, listSLABreaches, model.PermRulesRead, http.MethodGet)
+}
+
+func makeExpectedFileHandlers(mkHandler HandlerFactory) {
+	const (
+		expectedFilesPath      = "/expected_files"
+		expectedFilePath       = "/expected_files/{expected_file}"
+		expectedFileChecksPath = "/expected_files/{expected_file}/checks"
+	)
+
+	mkHandler(expectedFilesPath, addExpectedFile, model.PermRulesWrite, http.MethodPost)
+	mkHandler(expectedFilesPath, listExpectedFiles, model.PermRulesRead, http.MethodGet)
+	mkHandler(expectedFilePath, getExpectedFile, model.PermRulesRead, http.MethodGet)
+	mkHandler(expectedFilePath, updateExpectedFile, model.PermRulesWrite, http.MethodPatch)
+	mkHandler(expectedFilePath, deleteExpectedFile, model.PermRulesDelete, http.MethodDelete)
+	mkHandler(expectedFileChecksPath, listExpectedFileChecks, model.PermRulesRead, http.MethodGet)
+}
+
+func makeNodeHandlers(mkHandler HandlerFactory) {
+	const (
+		nodesPath = "/nodes"
+		nodePath  = "/nodes/{node}"
+	)
+
+	mkHandler(nodesPath, listNodes, model.PermAdminRead, http.MethodGet)
+	mkHandler(nodePath, getNode, model.PermAdminRead, http.MethodGet)
+}
@@ -2,6 +2,7 @@ package rest
 
 import (
 	"fmt"
+	"time"
 
 	"code.waarp.fr/apps/gateway/gateway/pkg/admin/rest/api"
 	"code.waarp.fr/apps/gateway/gateway/pkg/database"
@@ -11,8 +12,14 @@ import (
 // taskToDB transforms the JSON task into its database equivalent.
 func taskToDB(task *api.Task) *model.Task {
 	return &model.Task{
-		Type: task.Type,
-		Args: task.Args,
+		Type:          task.Type,
+		Args:          task.Args,
+		Condition:     task.Condition,
+		OnError:       model.OnError(task.OnError),
+		Retries:       task.Retries,
+		RetryDelay:    time.Duration(task.RetryDelay),
+		Timeout:       time.Duration(task.Timeout),
+		ParallelGroup: task.ParallelGroup,
 	}
 }
 
@@ -22,8 +29,14 @@ func FromRuleTasks(ts []*model.Task) []*api.Task {
 	tasks := make([]*api.Task, len(ts))
 	for i, task := range ts {
 		tasks[i] = &api.Task{
-			Type: task.Type,
-			Args: task.Args,
+			Type:          task.Type,
+			Args:          task.Args,
+			Condition:     task.Condition,
+			OnError:       string(task.OnError),
+			Retries:       task.Retries,
+			RetryDelay:    api.Duration(task.RetryDelay),
+			Timeout:       api.Duration(task.Timeout),
+			ParallelGroup: task.ParallelGroup,
 		}
 	}
 
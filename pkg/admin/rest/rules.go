@@ -61,6 +61,7 @@ func restRuleToDB(rule *api.InRule, logger *log.Logger) (*model.Rule, error) {
 	setIfValid(&dbRule.IsSend, rule.IsSend)
 	setIfValid(&dbRule.Comment, rule.Comment)
 	setIfValid(&dbRule.Path, rule.Path)
+	setIfValid(&dbRule.RetryPolicy, rule.RetryPolicy)
 
 	return dbRule, nil
 }
@@ -93,6 +94,7 @@ func DBRuleToREST(db *database.DB, dbRule *model.Rule) (*api.OutRule, error) {
 		LocalDir:       dbRule.LocalDir,
 		RemoteDir:      dbRule.RemoteDir,
 		TmpLocalRcvDir: dbRule.TmpLocalRcvDir,
+		RetryPolicy:    dbRule.RetryPolicy,
 		Authorized:     *access,
 	}
 	if err := doListTasks(db, rule, dbRule.ID); err != nil {
@@ -238,6 +240,7 @@ func updateRule(logger *log.Logger, db *database.DB) http.HandlerFunc {
 			LocalDir:       asNullable(oldRule.LocalDir),
 			RemoteDir:      asNullable(oldRule.RemoteDir),
 			TmpLocalRcvDir: asNullable(oldRule.TmpLocalRcvDir),
+			RetryPolicy:    asNullable(oldRule.RetryPolicy),
 			PreTasks:       nil,
 			PostTasks:      nil,
 			ErrorTasks:     nil,
@@ -0,0 +1,177 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/database/dbtest"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils/testhelpers"
+)
+
+const (
+	ScriptsPath = "/scripts"
+	ScriptPath  = "/scripts/{script}"
+)
+
+func TestAddScript(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+	logger := testhelpers.GetTestLogger(t)
+	handle := addStoredText[model.Script](logger, db)
+
+	t.Run("Valid request", func(t *testing.T) {
+		reqBody := bytes.Buffer{}
+		require.NoError(t, json.NewEncoder(&reqBody).Encode(map[string]any{
+			"name":    "script1",
+			"content": `print("hello")`,
+		}))
+
+		req := httptest.NewRequest(http.MethodPost, ScriptsPath, &reqBody)
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, req)
+
+		t.Run("Check response", func(t *testing.T) {
+			assert.Equal(t, http.StatusCreated, w.Code, `201 response code`)
+			assert.Equal(t, ScriptsPath+"/script1", w.Header().Get("Location"), `Correct Location header`)
+			assert.Empty(t, w.Body.String(), `Empty response body`)
+		})
+
+		t.Run("Check database", func(t *testing.T) {
+			var check model.Script
+			require.NoError(t, db.Get(&check, "name=?", "script1").Run())
+
+			assert.Equal(t, `print("hello")`, check.Content)
+		})
+	})
+
+	t.Run("Invalid script", func(t *testing.T) {
+		reqBody := bytes.Buffer{}
+		require.NoError(t, json.NewEncoder(&reqBody).Encode(map[string]any{
+			"name":    "script2",
+			"content": `print("hello"`,
+		}))
+
+		req := httptest.NewRequest(http.MethodPost, ScriptsPath, &reqBody)
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, `400 response code`)
+		assert.Contains(t, w.Body.String(), "the script is invalid", `Correct response body`)
+	})
+}
+
+func TestListScripts(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+	logger := testhelpers.GetTestLogger(t)
+	handle := listStoredTexts[model.Script](logger, db)
+
+	dbScript1 := &model.Script{Name: "script1", Content: `print("hello")`}
+	require.NoError(t, db.Insert(dbScript1).Run())
+
+	dbScript2 := &model.Script{Name: "script2", Content: `print("world")`}
+	require.NoError(t, db.Insert(dbScript2).Run())
+
+	req := httptest.NewRequest(http.MethodGet, ScriptsPath+"?sort=name-", nil)
+	w := httptest.NewRecorder()
+	handle.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, `200 response code`)
+	assert.JSONEq(t, marshal(t, map[string]any{
+		"scripts": []any{
+			map[string]any{"name": dbScript2.Name, "content": dbScript2.Content},
+			map[string]any{"name": dbScript1.Name, "content": dbScript1.Content},
+		},
+	}), w.Body.String(), `Correct response body`)
+}
+
+func TestGetScript(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+	logger := testhelpers.GetTestLogger(t)
+	handle := getStoredText[model.Script](logger, db)
+
+	dbScript := &model.Script{Name: "script1", Content: `print("hello")`}
+	require.NoError(t, db.Insert(dbScript).Run())
+
+	t.Run("Valid request", func(t *testing.T) {
+		req := makeRequest(http.MethodGet, nil, ScriptPath, dbScript.Name)
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, `200 response code`)
+		assert.JSONEq(t, marshal(t, map[string]any{
+			"name":    dbScript.Name,
+			"content": dbScript.Content,
+		}), w.Body.String(), `Correct response body`)
+	})
+
+	t.Run("Unknown script", func(t *testing.T) {
+		req := makeRequest(http.MethodGet, nil, ScriptPath, "unknown")
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code, `404 response code`)
+	})
+}
+
+func TestUpdateScript(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+	logger := testhelpers.GetTestLogger(t)
+	handle := updateStoredText[model.Script](logger, db)
+
+	dbScript := &model.Script{Name: "script1", Content: `print("hello")`}
+	require.NoError(t, db.Insert(dbScript).Run())
+
+	reqBody := bytes.Buffer{}
+	require.NoError(t, json.NewEncoder(&reqBody).Encode(map[string]any{"name": "script1_new"}))
+
+	req := makeRequest(http.MethodPatch, &reqBody, ScriptPath, dbScript.Name)
+	w := httptest.NewRecorder()
+	handle.ServeHTTP(w, req)
+
+	t.Run("Check response", func(t *testing.T) {
+		assert.Equal(t, http.StatusCreated, w.Code, `201 response code`)
+		assert.Equal(t, ScriptsPath+"/script1_new", w.Header().Get("Location"), `Correct Location header`)
+	})
+
+	t.Run("Check database", func(t *testing.T) {
+		var check model.Script
+		require.NoError(t, db.Get(&check, "name=?", "script1_new").Run())
+
+		assert.Equal(t, dbScript.Content, check.Content, "The content should be unchanged")
+	})
+}
+
+func TestDeleteScript(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+	logger := testhelpers.GetTestLogger(t)
+	handle := deleteStoredText[model.Script](logger, db)
+
+	dbScript := &model.Script{Name: "script1", Content: `print("hello")`}
+	require.NoError(t, db.Insert(dbScript).Run())
+
+	req := makeRequest(http.MethodDelete, nil, ScriptPath, dbScript.Name)
+	w := httptest.NewRecorder()
+	handle.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code, `204 response code`)
+
+	var nfe *database.NotFoundError
+	assert.ErrorAs(t, db.Get(&model.Script{}, "name=?", dbScript.Name).Run(), &nfe)
+}
@@ -11,6 +11,7 @@ import (
 	"code.waarp.fr/apps/gateway/gateway/pkg/gatewayd/services"
 	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
 	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/pipeline"
 	"code.waarp.fr/apps/gateway/gateway/pkg/protocols"
 )
 
@@ -399,6 +400,8 @@ func restartServer(logger *log.Logger, db *database.DB) http.HandlerFunc {
 			return
 		}
 
+		pipeline.List.EndServerDrain(dbServer.ID)
+
 		if err := services.Servers.Restart(r.Context(), dbServer); handleError(w, logger, err) {
 			return
 		}
@@ -0,0 +1,327 @@
+package rest
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/admin/rest/api"
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/sla"
+)
+
+func retrieveDBSLA(r *http.Request, db *database.DB) (*model.SLA, error) {
+	name, ok := mux.Vars(r)["sla"]
+	if !ok {
+		return nil, notFound("missing SLA name")
+	}
+
+	var dbSLA model.SLA
+	if err := db.Get(&dbSLA, "name=?", name).Owner().Run(); err != nil {
+		if database.IsNotFound(err) {
+			return nil, notFoundf("SLA %q not found", name)
+		}
+
+		return nil, fmt.Errorf("failed to retrieve SLA %q: %w", name, err)
+	}
+
+	return &dbSLA, nil
+}
+
+func dbSLABreachToREST(breach *model.SLABreach) *api.OutSLABreach {
+	return &api.OutSLABreach{
+		Kind:       string(breach.Kind),
+		TransferID: breach.TransferID.Int64,
+		Date:       breach.Date.Local(),
+		Detected:   breach.Detected.Local(),
+		Details:    breach.Details,
+	}
+}
+
+// slaScope returns the rule & partner to which the given SLA applies (both
+// can be nil).
+func slaScope(db database.ReadAccess, dbSLA *model.SLA) (*model.Rule, *model.RemoteAgent, error) {
+	var (
+		rule    *model.Rule
+		partner *model.RemoteAgent
+	)
+
+	if dbSLA.RuleID.Valid {
+		rule = &model.Rule{}
+		if err := db.Get(rule, "id=?", dbSLA.RuleID.Int64).Run(); err != nil {
+			return nil, nil, fmt.Errorf("failed to retrieve the SLA's rule: %w", err)
+		}
+	}
+
+	if dbSLA.RemoteAgentID.Valid {
+		partner = &model.RemoteAgent{}
+		if err := db.Get(partner, "id=?", dbSLA.RemoteAgentID.Int64).Run(); err != nil {
+			return nil, nil, fmt.Errorf("failed to retrieve the SLA's partner: %w", err)
+		}
+	}
+
+	return rule, partner, nil
+}
+
+func dbSLAToREST(db database.ReadAccess, dbSLA *model.SLA) (*api.OutSLA, error) {
+	rule, partner, err := slaScope(db, dbSLA)
+	if err != nil {
+		return nil, err
+	}
+
+	status, lastBreach, statusErr := sla.Status(db, dbSLA, time.Now())
+	if statusErr != nil {
+		return nil, fmt.Errorf("failed to retrieve the SLA's status: %w", statusErr)
+	}
+
+	restSLA := &api.OutSLA{
+		Name:          dbSLA.Name,
+		Deadline:      dbSLA.Deadline,
+		DeadlineDays:  dbSLA.DeadlineDays,
+		MaxDuration:   api.Duration(dbSLA.MaxDuration),
+		MinThroughput: dbSLA.MinThroughput,
+		Status:        status,
+	}
+
+	if rule != nil {
+		restSLA.Rule = rule.Name
+		restSLA.IsSend = asNullableBool(rule.IsSend)
+	}
+
+	if partner != nil {
+		restSLA.Partner = partner.Name
+	}
+
+	if lastBreach != nil {
+		restSLA.LastBreach = dbSLABreachToREST(lastBreach)
+	}
+
+	return restSLA, nil
+}
+
+func restSLAToDB(db database.ReadAccess, restSLA *api.InSLA) (*model.SLA, error) {
+	dbSLA := &model.SLA{DeadlineDays: restSLA.DeadlineDays}
+
+	setIfValid(&dbSLA.Name, restSLA.Name)
+	setIfValid(&dbSLA.Deadline, restSLA.Deadline)
+	setIfValid(&dbSLA.MinThroughput, restSLA.MinThroughput)
+	setDurationIfValid(&dbSLA.MaxDuration, restSLA.MaxDuration)
+
+	if restSLA.Rule.Valid && restSLA.Rule.Value != "" {
+		if !restSLA.IsSend.Valid {
+			return nil, badRequest("the rule direction (isSend) is missing")
+		}
+
+		var rule model.Rule
+		if err := db.Get(&rule, "name=? AND is_send=?", restSLA.Rule.Value,
+			restSLA.IsSend.Value).Run(); err != nil {
+			if database.IsNotFound(err) {
+				return nil, badRequestf("no %s rule %q found",
+					ruleDirection(&model.Rule{IsSend: restSLA.IsSend.Value}), restSLA.Rule.Value)
+			}
+
+			return nil, fmt.Errorf("failed to retrieve rule %q: %w", restSLA.Rule.Value, err)
+		}
+
+		dbSLA.RuleID = sql.NullInt64{Int64: rule.ID, Valid: true}
+	}
+
+	if restSLA.Partner.Valid && restSLA.Partner.Value != "" {
+		var partner model.RemoteAgent
+		if err := db.Get(&partner, "name=?", restSLA.Partner.Value).Owner().Run(); err != nil {
+			if database.IsNotFound(err) {
+				return nil, badRequestf("no partner %q found", restSLA.Partner.Value)
+			}
+
+			return nil, fmt.Errorf("failed to retrieve partner %q: %w", restSLA.Partner.Value, err)
+		}
+
+		dbSLA.RemoteAgentID = sql.NullInt64{Int64: partner.ID, Valid: true}
+	}
+
+	return dbSLA, nil
+}
+
+func addSLA(logger *log.Logger, db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var restSLA api.InSLA
+		if err := readJSON(r, &restSLA); handleError(w, logger, err) {
+			return
+		}
+
+		dbSLA, convErr := restSLAToDB(db, &restSLA)
+		if handleError(w, logger, convErr) {
+			return
+		}
+
+		if err := db.Insert(dbSLA).Run(); handleError(w, logger, err) {
+			return
+		}
+
+		w.Header().Set("Location", location(r.URL, dbSLA.Name))
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+func listSLAs(logger *log.Logger, db *database.DB) http.HandlerFunc {
+	//nolint:goconst //duplicate is for a different type, keep separate
+	validSorting := orders{
+		"default": order{"name", true},
+		"name+":   order{"name", true},
+		"name-":   order{"name", false},
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var dbSLAs model.SLAs
+
+		query, queryErr := parseSelectQuery(r, db, validSorting, &dbSLAs)
+		if handleError(w, logger, queryErr) {
+			return
+		}
+
+		if err := query.Run(); handleError(w, logger, err) {
+			return
+		}
+
+		restSLAs := make([]*api.OutSLA, len(dbSLAs))
+
+		for i, dbSLA := range dbSLAs {
+			restSLA, convErr := dbSLAToREST(db, dbSLA)
+			if handleError(w, logger, convErr) {
+				return
+			}
+
+			restSLAs[i] = restSLA
+		}
+
+		response := map[string][]*api.OutSLA{"slas": restSLAs}
+		handleError(w, logger, writeJSON(w, response))
+	}
+}
+
+func getSLA(logger *log.Logger, db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dbSLA, getErr := retrieveDBSLA(r, db)
+		if handleError(w, logger, getErr) {
+			return
+		}
+
+		restSLA, convErr := dbSLAToREST(db, dbSLA)
+		if handleError(w, logger, convErr) {
+			return
+		}
+
+		handleError(w, logger, writeJSON(w, restSLA))
+	}
+}
+
+func updateSLA(logger *log.Logger, db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		oldSLA, getErr := retrieveDBSLA(r, db)
+		if handleError(w, logger, getErr) {
+			return
+		}
+
+		rule, partner, scopeErr := slaScope(db, oldSLA)
+		if handleError(w, logger, scopeErr) {
+			return
+		}
+
+		restSLA := &api.InSLA{
+			Name:          asNullable(oldSLA.Name),
+			Deadline:      asNullable(oldSLA.Deadline),
+			DeadlineDays:  oldSLA.DeadlineDays,
+			MaxDuration:   asNullable(api.Duration(oldSLA.MaxDuration)),
+			MinThroughput: asNullable(oldSLA.MinThroughput),
+		}
+
+		if rule != nil {
+			restSLA.Rule = asNullable(rule.Name)
+			restSLA.IsSend = asNullableBool(rule.IsSend)
+		}
+
+		if partner != nil {
+			restSLA.Partner = asNullable(partner.Name)
+		}
+
+		if err := readJSON(r, restSLA); handleError(w, logger, err) {
+			return
+		}
+
+		dbSLA, convErr := restSLAToDB(db, restSLA)
+		if handleError(w, logger, convErr) {
+			return
+		}
+
+		dbSLA.ID = oldSLA.ID
+
+		if err := db.Update(dbSLA).Run(); handleError(w, logger, err) {
+			return
+		}
+
+		w.Header().Set("Location", locationUpdate(r.URL, dbSLA.Name))
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+func deleteSLA(logger *log.Logger, db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dbSLA, getErr := retrieveDBSLA(r, db)
+		if handleError(w, logger, getErr) {
+			return
+		}
+
+		if err := db.Delete(dbSLA).Run(); handleError(w, logger, err) {
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func listSLABreaches(logger *log.Logger, db *database.DB) http.HandlerFunc {
+	validSorting := orders{
+		"default":   order{"detected", false},
+		"detected+": order{"detected", true},
+		"detected-": order{"detected", false},
+		"date+":     order{"date", true},
+		"date-":     order{"date", false},
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		dbSLA, getErr := retrieveDBSLA(r, db)
+		if handleError(w, logger, getErr) {
+			return
+		}
+
+		var dbBreaches model.SLABreaches
+
+		query, queryErr := parseSelectQuery(r, db, validSorting, &dbBreaches)
+		if handleError(w, logger, queryErr) {
+			return
+		}
+
+		query.Where("sla_id=?", dbSLA.ID)
+
+		if kinds := r.Form["kind"]; len(kinds) > 0 {
+			query.In("kind", kinds)
+		}
+
+		if err := query.Run(); handleError(w, logger, err) {
+			return
+		}
+
+		restBreaches := make([]*api.OutSLABreach, len(dbBreaches))
+		for i, dbBreach := range dbBreaches {
+			restBreaches[i] = dbSLABreachToREST(dbBreach)
+		}
+
+		response := map[string][]*api.OutSLABreach{"breaches": restBreaches}
+		handleError(w, logger, writeJSON(w, response))
+	}
+}
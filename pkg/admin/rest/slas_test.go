@@ -0,0 +1,355 @@
+package rest
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/database/dbtest"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/types"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils/testhelpers"
+)
+
+const (
+	SLAsPath        = "/slas"
+	SLAPath         = "/slas/{sla}"
+	SLABreachesPath = "/slas/{sla}/breaches"
+)
+
+func TestAddSLA(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+	logger := testhelpers.GetTestLogger(t)
+	handle := addSLA(logger, db)
+
+	dbRule := &model.Rule{Name: "rule", IsSend: false, Path: "/rule"}
+	require.NoError(t, db.Insert(dbRule).Run())
+
+	t.Run("Valid request", func(t *testing.T) {
+		reqBody := bytes.Buffer{}
+		require.NoError(t, json.NewEncoder(&reqBody).Encode(map[string]any{
+			"name":          "sla1",
+			"rule":          dbRule.Name,
+			"isSend":        false,
+			"deadline":      "07:00",
+			"maxDuration":   "30m",
+			"minThroughput": 1000,
+		}))
+
+		req := httptest.NewRequest(http.MethodPost, SLAsPath, &reqBody)
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, req)
+
+		t.Run("Check response", func(t *testing.T) {
+			assert.Equal(t, http.StatusCreated, w.Code, `201 response code`)
+			assert.Equal(t, SLAsPath+"/sla1", w.Header().Get("Location"), `Correct Location header`)
+			assert.Empty(t, w.Body.String(), `Empty response body`)
+		})
+
+		t.Run("Check database", func(t *testing.T) {
+			var check model.SLA
+			require.NoError(t, db.Get(&check, "name=?", "sla1").Run())
+
+			assert.Equal(t, sql.NullInt64{Int64: dbRule.ID, Valid: true}, check.RuleID)
+			assert.False(t, check.RemoteAgentID.Valid)
+			assert.Equal(t, "07:00", check.Deadline)
+			assert.Equal(t, model.List[string]{"monday", "tuesday", "wednesday", "thursday", "friday"},
+				check.DeadlineDays)
+			assert.Equal(t, 30*time.Minute, check.MaxDuration)
+			assert.EqualValues(t, 1000, check.MinThroughput)
+		})
+	})
+
+	t.Run("Missing rule direction", func(t *testing.T) {
+		reqBody := bytes.Buffer{}
+		require.NoError(t, json.NewEncoder(&reqBody).Encode(map[string]any{
+			"name":        "sla2",
+			"rule":        dbRule.Name,
+			"maxDuration": "30m",
+		}))
+
+		req := httptest.NewRequest(http.MethodPost, SLAsPath, &reqBody)
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, `400 response code`)
+		assert.Contains(t, w.Body.String(), "the rule direction (isSend) is missing", `Correct response body`)
+	})
+
+	t.Run("Unknown partner", func(t *testing.T) {
+		reqBody := bytes.Buffer{}
+		require.NoError(t, json.NewEncoder(&reqBody).Encode(map[string]any{
+			"name":        "sla3",
+			"partner":     "unknown",
+			"maxDuration": "30m",
+		}))
+
+		req := httptest.NewRequest(http.MethodPost, SLAsPath, &reqBody)
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, `400 response code`)
+		assert.Contains(t, w.Body.String(), `no partner "unknown" found`, `Correct response body`)
+	})
+}
+
+func TestListSLAs(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+	logger := testhelpers.GetTestLogger(t)
+	handle := listSLAs(logger, db)
+
+	dbRule := &model.Rule{Name: "rule", IsSend: true, Path: "/rule"}
+	require.NoError(t, db.Insert(dbRule).Run())
+
+	dbPartner := &model.RemoteAgent{
+		Name: "partner", Protocol: testProto1,
+		Address: types.Addr("localhost", 1),
+	}
+	require.NoError(t, db.Insert(dbPartner).Run())
+
+	dbSLA1 := &model.SLA{
+		Name: "sla1", RuleID: sql.NullInt64{Int64: dbRule.ID, Valid: true},
+		MaxDuration: time.Hour,
+	}
+	require.NoError(t, db.Insert(dbSLA1).Run())
+
+	dbSLA2 := &model.SLA{
+		Name: "sla2", RemoteAgentID: sql.NullInt64{Int64: dbPartner.ID, Valid: true},
+		Deadline: "07:00", DeadlineDays: model.List[string]{"monday"},
+	}
+	require.NoError(t, db.Insert(dbSLA2).Run())
+
+	req := httptest.NewRequest(http.MethodGet, SLAsPath+"?sort=name-", nil)
+	w := httptest.NewRecorder()
+	handle.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, `200 response code`)
+	assert.JSONEq(t, marshal(t, map[string]any{
+		"slas": []any{
+			map[string]any{
+				"name": dbSLA2.Name, "partner": dbPartner.Name, "deadline": "07:00",
+				"deadlineDays": []any{"monday"}, "status": "OK",
+			},
+			map[string]any{
+				"name": dbSLA1.Name, "rule": dbRule.Name, "isSend": true,
+				"maxDuration": "1h0m0s", "status": "OK",
+			},
+		},
+	}), w.Body.String(), `Correct response body`)
+}
+
+func TestGetSLA(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+	logger := testhelpers.GetTestLogger(t)
+	handle := getSLA(logger, db)
+
+	dbRule := &model.Rule{Name: "rule", IsSend: false, Path: "/rule"}
+	require.NoError(t, db.Insert(dbRule).Run())
+
+	dbSLA := &model.SLA{
+		Name: "sla1", RuleID: sql.NullInt64{Int64: dbRule.ID, Valid: true},
+		MinThroughput: 1000,
+	}
+	require.NoError(t, db.Insert(dbSLA).Run())
+
+	now := time.Now().Truncate(time.Second)
+	dbBreach := &model.SLABreach{
+		SLAID: dbSLA.ID, Kind: model.SLABreachThroughput,
+		TransferID: sql.NullInt64{Int64: 1234, Valid: true},
+		Date:       now.Add(-time.Hour), Detected: now,
+		Details: "transfer n°1234 had an average throughput of 10 B/s (minimum: 1000 B/s)",
+	}
+	require.NoError(t, db.Insert(dbBreach).Run())
+
+	t.Run("Valid request", func(t *testing.T) {
+		req := makeRequest(http.MethodGet, nil, SLAPath, dbSLA.Name)
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, `200 response code`)
+		assert.JSONEq(t, marshal(t, map[string]any{
+			"name":          dbSLA.Name,
+			"rule":          dbRule.Name,
+			"isSend":        false,
+			"minThroughput": 1000,
+			"status":        "BREACHED",
+			"lastBreach": map[string]any{
+				"kind":       "throughput",
+				"transferID": 1234,
+				"date":       dbBreach.Date.Local(),
+				"detected":   dbBreach.Detected.Local(),
+				"details":    dbBreach.Details,
+			},
+		}), w.Body.String(), `Correct response body`)
+	})
+
+	t.Run("Unknown SLA", func(t *testing.T) {
+		req := makeRequest(http.MethodGet, nil, SLAPath, "unknown")
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code, `404 response code`)
+	})
+}
+
+func TestUpdateSLA(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+	logger := testhelpers.GetTestLogger(t)
+	handle := updateSLA(logger, db)
+
+	dbRule := &model.Rule{Name: "rule", IsSend: true, Path: "/rule"}
+	require.NoError(t, db.Insert(dbRule).Run())
+
+	dbPartner := &model.RemoteAgent{
+		Name: "partner", Protocol: testProto1,
+		Address: types.Addr("localhost", 1),
+	}
+	require.NoError(t, db.Insert(dbPartner).Run())
+
+	dbSLA := &model.SLA{
+		Name: "sla1", RuleID: sql.NullInt64{Int64: dbRule.ID, Valid: true},
+		MaxDuration: time.Hour,
+	}
+	require.NoError(t, db.Insert(dbSLA).Run())
+
+	reqBody := bytes.Buffer{}
+	require.NoError(t, json.NewEncoder(&reqBody).Encode(map[string]any{
+		"name":    "sla1_new",
+		"rule":    "",
+		"partner": dbPartner.Name,
+	}))
+
+	req := makeRequest(http.MethodPatch, &reqBody, SLAPath, dbSLA.Name)
+	w := httptest.NewRecorder()
+	handle.ServeHTTP(w, req)
+
+	t.Run("Check response", func(t *testing.T) {
+		assert.Equal(t, http.StatusCreated, w.Code, `201 response code`)
+		assert.Equal(t, SLAsPath+"/sla1_new", w.Header().Get("Location"), `Correct Location header`)
+	})
+
+	t.Run("Check database", func(t *testing.T) {
+		var check model.SLA
+		require.NoError(t, db.Get(&check, "name=?", "sla1_new").Run())
+
+		assert.False(t, check.RuleID.Valid, "The rule should have been removed")
+		assert.Equal(t, sql.NullInt64{Int64: dbPartner.ID, Valid: true}, check.RemoteAgentID)
+		assert.Equal(t, time.Hour, check.MaxDuration, "The maximum duration should be unchanged")
+	})
+}
+
+func TestDeleteSLA(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+	logger := testhelpers.GetTestLogger(t)
+	handle := deleteSLA(logger, db)
+
+	dbRule := &model.Rule{Name: "rule", IsSend: true, Path: "/rule"}
+	require.NoError(t, db.Insert(dbRule).Run())
+
+	dbSLA := &model.SLA{
+		Name: "sla1", RuleID: sql.NullInt64{Int64: dbRule.ID, Valid: true},
+		MaxDuration: time.Hour,
+	}
+	require.NoError(t, db.Insert(dbSLA).Run())
+
+	require.NoError(t, db.Insert(&model.SLABreach{
+		SLAID: dbSLA.ID, Kind: model.SLABreachDuration, Date: time.Now(), Detected: time.Now(),
+	}).Run())
+
+	req := makeRequest(http.MethodDelete, nil, SLAPath, dbSLA.Name)
+	w := httptest.NewRecorder()
+	handle.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code, `204 response code`)
+
+	var nfe *database.NotFoundError
+	require.ErrorAs(t, db.Get(&model.SLA{}, "name=?", dbSLA.Name).Run(), &nfe)
+
+	var breaches model.SLABreaches
+	require.NoError(t, db.Select(&breaches).Run())
+	assert.Empty(t, breaches, "The SLA's breaches should have been deleted")
+}
+
+func TestListSLABreaches(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+	logger := testhelpers.GetTestLogger(t)
+	handle := listSLABreaches(logger, db)
+
+	dbRule := &model.Rule{Name: "rule", IsSend: true, Path: "/rule"}
+	require.NoError(t, db.Insert(dbRule).Run())
+
+	dbSLA := &model.SLA{
+		Name: "sla1", RuleID: sql.NullInt64{Int64: dbRule.ID, Valid: true},
+		Deadline: "07:00", MaxDuration: time.Hour,
+	}
+	require.NoError(t, db.Insert(dbSLA).Run())
+
+	now := time.Now().Truncate(time.Second)
+
+	dbBreach1 := &model.SLABreach{
+		SLAID: dbSLA.ID, Kind: model.SLABreachDeadline,
+		Date: now.Add(-2 * time.Hour), Detected: now.Add(-time.Hour),
+		Details: "no transfer completed before 07:00",
+	}
+	require.NoError(t, db.Insert(dbBreach1).Run())
+
+	dbBreach2 := &model.SLABreach{
+		SLAID: dbSLA.ID, Kind: model.SLABreachDuration,
+		TransferID: sql.NullInt64{Int64: 1234, Valid: true},
+		Date:       now.Add(-3 * time.Hour), Detected: now,
+		Details: "transfer n°1234 took 2h0m0s to complete (maximum: 1h0m0s)",
+	}
+	require.NoError(t, db.Insert(dbBreach2).Run())
+
+	t.Run("All breaches", func(t *testing.T) {
+		req := makeRequest(http.MethodGet, nil, SLABreachesPath, dbSLA.Name)
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, `200 response code`)
+		assert.JSONEq(t, marshal(t, map[string]any{
+			"breaches": []any{
+				map[string]any{
+					"kind": "duration", "transferID": 1234,
+					"date": dbBreach2.Date.Local(), "detected": dbBreach2.Detected.Local(),
+					"details": dbBreach2.Details,
+				},
+				map[string]any{
+					"kind": "deadline",
+					"date": dbBreach1.Date.Local(), "detected": dbBreach1.Detected.Local(),
+					"details": dbBreach1.Details,
+				},
+			},
+		}), w.Body.String(), `Correct response body`)
+	})
+
+	t.Run("Filtered by kind", func(t *testing.T) {
+		req := makeRequest(http.MethodGet, nil, SLABreachesPath+"?kind=deadline", dbSLA.Name)
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, `200 response code`)
+		assert.Contains(t, w.Body.String(), `"kind":"deadline"`)
+		assert.NotContains(t, w.Body.String(), `"kind":"duration"`)
+	})
+}
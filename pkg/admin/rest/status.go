@@ -1,36 +1,157 @@
 package rest
 
 import (
+	"fmt"
 	"net/http"
+	"slices"
+	"strings"
 
 	"code.waarp.fr/apps/gateway/gateway/pkg/admin/rest/api"
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/filewatcher"
 	"code.waarp.fr/apps/gateway/gateway/pkg/gatewayd/services"
 	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/pipeline"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils"
 )
 
 // getStatus is called when an HTTP request is received on the StatusURI path.
-func getStatus(logger *log.Logger) http.HandlerFunc {
+func getStatus(logger *log.Logger, db *database.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, _ *http.Request) {
-		statuses := make(api.Statuses)
+		statuses := localStatuses()
 
-		for _, serv := range services.Core {
-			code, reason := serv.State()
-			statuses[serv.Name()] = api.Status{
-				State:  code.String(),
-				Reason: reason,
+		if db.Config.NodeID != "" {
+			var err error
+			if statuses, err = clusterStatuses(db, statuses); handleError(w, logger, err) {
+				return
 			}
 		}
 
-		services.Servers.Range(func(_ int64, serv services.Server) bool {
-			code, reason := serv.State()
-			statuses[serv.Name()] = api.Status{
-				State:  code.String(),
-				Reason: reason,
-			}
+		handleError(w, logger, writeJSON(w, statuses))
+	}
+}
 
-			return true
-		})
+// localStatuses returns the state of all the services running on the node
+// which received the request.
+func localStatuses() api.Statuses {
+	statuses := make(api.Statuses)
 
-		handleError(w, logger, writeJSON(w, statuses))
+	addStatus := func(serv services.Service, draining bool) {
+		code, reason := pipeline.DrainedState(serv, draining)
+		statuses[serv.Name()] = api.Status{
+			State:  code.String(),
+			Reason: reason,
+		}
+	}
+
+	for _, serv := range services.Core {
+		addStatus(serv, false)
+	}
+
+	services.Servers.Range(func(id int64, serv services.Server) bool {
+		addStatus(serv, pipeline.List.IsServerDraining(id))
+
+		return true
+	})
+
+	services.Clients.Range(func(id int64, cli services.Client) bool {
+		addStatus(cli, pipeline.List.IsClientDraining(id))
+
+		return true
+	})
+
+	filewatcher.Filewatchers.Range(func(_ int64, fw *filewatcher.Service) bool {
+		addStatus(fw, false)
+
+		return true
+	})
+
+	return statuses
+}
+
+// clusterStatuses aggregates the given local statuses with the statuses of the
+// services of the other (alive) nodes of the cluster, as reported during their
+// last heartbeat. The state of each node's service is given in the "nodes"
+// field, while the main state is the worst of these states.
+func clusterStatuses(db *database.DB, local api.Statuses) (api.Statuses, error) {
+	var nodes model.ClusterNodes
+	if err := db.Select(&nodes).Where("name<>?", db.Config.NodeID).Run(); err != nil {
+		return nil, fmt.Errorf("failed to retrieve the cluster's nodes: %w", err)
+	}
+
+	perNode := map[string]map[string]api.Status{}
+	addStatus := func(service, node string, status api.Status) {
+		if perNode[service] == nil {
+			perNode[service] = map[string]api.Status{}
+		}
+
+		perNode[service][node] = status
+	}
+
+	for name, status := range local {
+		addStatus(name, db.Config.NodeID, status)
+	}
+
+	for _, node := range nodes {
+		if node.Status() != model.NodeStatusAlive {
+			continue
+		}
+
+		for name, state := range node.Services {
+			addStatus(name, node.Name, api.Status{State: state.State, Reason: state.Reason})
+		}
+	}
+
+	statuses := make(api.Statuses, len(perNode))
+	for name, nodeStatuses := range perNode {
+		statuses[name] = aggregateStatuses(nodeStatuses)
+	}
+
+	return statuses, nil
+}
+
+// aggregateStatuses merges the states of a service on the different nodes of
+// the cluster into a single state. The service is only considered running if it
+// is running on all the nodes.
+func aggregateStatuses(nodeStatuses map[string]api.Status) api.Status {
+	severity := func(state string) int {
+		switch state {
+		case utils.StateError.String():
+			return 2 //nolint:mnd //severity order, no need for a constant
+		case utils.StateRunning.String():
+			return 0
+		default:
+			return 1
+		}
+	}
+
+	nodes := make([]string, 0, len(nodeStatuses))
+	for node := range nodeStatuses {
+		nodes = append(nodes, node)
+	}
+
+	slices.Sort(nodes)
+
+	state := utils.StateRunning.String()
+
+	for _, node := range nodes {
+		if nodeState := nodeStatuses[node].State; severity(nodeState) > severity(state) {
+			state = nodeState
+		}
+	}
+
+	var reasons []string
+
+	for _, node := range nodes {
+		if status := nodeStatuses[node]; status.State == state && status.Reason != "" {
+			reasons = append(reasons, fmt.Sprintf("%s: %s", node, status.Reason))
+		}
+	}
+
+	return api.Status{
+		State:  state,
+		Reason: strings.Join(reasons, "; "),
+		Nodes:  nodeStatuses,
 	}
 }
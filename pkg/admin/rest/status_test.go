@@ -6,11 +6,14 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	. "github.com/smartystreets/goconvey/convey"
 
 	"code.waarp.fr/apps/gateway/gateway/pkg/admin/rest/api"
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
 	"code.waarp.fr/apps/gateway/gateway/pkg/gatewayd/services"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
 	"code.waarp.fr/apps/gateway/gateway/pkg/pipeline"
 	"code.waarp.fr/apps/gateway/gateway/pkg/protocols/protocol"
 	"code.waarp.fr/apps/gateway/gateway/pkg/utils"
@@ -62,7 +65,7 @@ func TestStatus(t *testing.T) {
 		services.Servers.Add(id(2), &testService{name: "Offline Server", state: utils.NewState(utils.StateOffline, "")})
 		services.Servers.Add(id(3), &testService{name: "Error Server", state: utils.NewState(utils.StateError, "Test Reason")})
 
-		statuses := map[string]api.Status{
+		statuses := api.Statuses{
 			"Error Core Service":   {State: utils.StateError.String(), Reason: "Test Reason"},
 			"Offline Core Service": {State: utils.StateOffline.String()},
 			"Running Core Service": {State: utils.StateRunning.String()},
@@ -73,16 +76,27 @@ func TestStatus(t *testing.T) {
 
 		Convey("Given the REST status handler", func() {
 			logger := testhelpers.TestLogger(c, "rest_status_test")
-			handler := getStatus(logger)
+			db := database.TestDatabase(c)
+			handler := getStatus(logger, db)
 
-			Convey("Given a status request", func() {
+			sendRequest := func() (*httptest.ResponseRecorder, api.Statuses) {
 				w := httptest.NewRecorder()
 				r, err := http.NewRequest(http.MethodGet, "/api/status", nil)
-
 				So(err, ShouldBeNil)
 
-				Convey("When the request is sent to the handler", func() {
-					handler.ServeHTTP(w, r)
+				handler.ServeHTTP(w, r)
+
+				response := api.Statuses{}
+				So(json.Unmarshal(w.Body.Bytes(), &response), ShouldBeNil)
+
+				return w, response
+			}
+
+			Convey("Given a standalone gateway", func() {
+				db.Config.NodeID = ""
+
+				Convey("When a status request is sent to the handler", func() {
+					w, response := sendRequest()
 
 					Convey("Then the handler should reply 'OK'", func() {
 						So(w.Code, ShouldEqual, http.StatusOK)
@@ -95,14 +109,65 @@ func TestStatus(t *testing.T) {
 					})
 
 					Convey("Then the response body should contain the services in JSON format", func() {
-						response := map[string]api.Status{}
-						err := json.Unmarshal(w.Body.Bytes(), &response)
-
-						So(err, ShouldBeNil)
 						So(response, ShouldResemble, statuses)
 					})
 				})
 			})
+
+			Convey("Given a clustered gateway", func() {
+				node := db.Config.NodeID
+
+				So(db.Insert(&model.ClusterNode{
+					Name:        "other_node",
+					LeaseExpiry: time.Now().Add(time.Minute),
+					Services: model.Map[model.ServiceState]{
+						"Running Core Service": {State: utils.StateRunning.String()},
+						"Running Server":       {State: utils.StateError.String(), Reason: "Other Reason"},
+					},
+				}).Run(), ShouldBeNil)
+
+				So(db.Insert(&model.ClusterNode{
+					Name:        "dead_node",
+					LeaseExpiry: time.Now().Add(-time.Minute),
+					Services: model.Map[model.ServiceState]{
+						"Running Core Service": {State: utils.StateError.String(), Reason: "Dead Reason"},
+					},
+				}).Run(), ShouldBeNil)
+
+				Convey("When a status request is sent to the handler", func() {
+					w, response := sendRequest()
+					So(w.Code, ShouldEqual, http.StatusOK)
+
+					Convey("Then the services running on all nodes should be running", func() {
+						So(response["Running Core Service"], ShouldResemble, api.Status{
+							State: utils.StateRunning.String(),
+							Nodes: map[string]api.Status{
+								node:         {State: utils.StateRunning.String()},
+								"other_node": {State: utils.StateRunning.String()},
+							},
+						})
+					})
+
+					Convey("Then the services in error on one node should be in error", func() {
+						So(response["Running Server"], ShouldResemble, api.Status{
+							State:  utils.StateError.String(),
+							Reason: "other_node: Other Reason",
+							Nodes: map[string]api.Status{
+								node:         {State: utils.StateRunning.String()},
+								"other_node": {State: utils.StateError.String(), Reason: "Other Reason"},
+							},
+						})
+
+						So(response["Error Core Service"], ShouldResemble, api.Status{
+							State:  utils.StateError.String(),
+							Reason: node + ": Test Reason",
+							Nodes: map[string]api.Status{
+								node: {State: utils.StateError.String(), Reason: "Test Reason"},
+							},
+						})
+					})
+				})
+			})
 		})
 	})
 }
@@ -0,0 +1,153 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/admin/rest/api"
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+)
+
+// The handlers below are shared by all the named text models (scripts and
+// copybooks). The name of the URL variable identifying the entry is the
+// model's appellation, and the list key is its table name.
+
+func retrieveStoredText[T any, PT model.StoredText[T]](r *http.Request,
+	db *database.DB,
+) (PT, error) {
+	text := PT(new(T))
+	kind := text.Appellation()
+
+	name, ok := mux.Vars(r)[kind]
+	if !ok {
+		return nil, notFoundf("missing %s name", kind)
+	}
+
+	if err := db.Get(text, "name=?", name).Run(); err != nil {
+		if database.IsNotFound(err) {
+			return nil, notFoundf("%s %q not found", kind, name)
+		}
+
+		return nil, fmt.Errorf("failed to retrieve %s %q: %w", kind, name, err)
+	}
+
+	return text, nil
+}
+
+func dbStoredTextToREST[T any, PT model.StoredText[T]](text PT) *api.GetStoredTextObject {
+	name, content := text.TextFields()
+
+	return &api.GetStoredTextObject{
+		Name:    *name,
+		Content: *content,
+	}
+}
+
+func addStoredText[T any, PT model.StoredText[T]](logger *log.Logger, db *database.DB,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var restText api.PostStoredTextObject
+		if err := readJSON(r, &restText); handleError(w, logger, err) {
+			return
+		}
+
+		dbText := PT(new(T))
+		name, content := dbText.TextFields()
+		*name, *content = restText.Name, restText.Content
+
+		if err := db.Insert(dbText).Run(); handleError(w, logger, err) {
+			return
+		}
+
+		w.Header().Set("Location", location(r.URL, *name))
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+func listStoredTexts[T any, PT model.StoredText[T]](logger *log.Logger, db *database.DB,
+) http.HandlerFunc {
+	validSorting := orders{
+		"default": order{"name", true},
+		"name+":   order{"name", true},
+		"name-":   order{"name", false},
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var dbTexts model.Slice[PT]
+
+		query, queryErr := parseSelectQuery(r, db, validSorting, &dbTexts)
+		if handleError(w, logger, queryErr) {
+			return
+		}
+
+		if err := query.Run(); handleError(w, logger, err) {
+			return
+		}
+
+		restTexts := make([]*api.GetStoredTextObject, len(dbTexts))
+		for i, dbText := range dbTexts {
+			restTexts[i] = dbStoredTextToREST(dbText)
+		}
+
+		response := map[string][]*api.GetStoredTextObject{dbTexts.TableName(): restTexts}
+		handleError(w, logger, writeJSON(w, response))
+	}
+}
+
+func getStoredText[T any, PT model.StoredText[T]](logger *log.Logger, db *database.DB,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dbText, getErr := retrieveStoredText[T, PT](r, db)
+		if handleError(w, logger, getErr) {
+			return
+		}
+
+		handleError(w, logger, writeJSON(w, dbStoredTextToREST(dbText)))
+	}
+}
+
+func updateStoredText[T any, PT model.StoredText[T]](logger *log.Logger, db *database.DB,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dbText, getErr := retrieveStoredText[T, PT](r, db)
+		if handleError(w, logger, getErr) {
+			return
+		}
+
+		var restText api.PatchStoredTextObject
+		if err := readJSON(r, &restText); handleError(w, logger, err) {
+			return
+		}
+
+		name, content := dbText.TextFields()
+		setIfValid(name, restText.Name)
+		setIfValid(content, restText.Content)
+
+		if err := db.Update(dbText).Run(); handleError(w, logger, err) {
+			return
+		}
+
+		w.Header().Set("Location", locationUpdate(r.URL, *name))
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+func deleteStoredText[T any, PT model.StoredText[T]](logger *log.Logger, db *database.DB,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dbText, getErr := retrieveStoredText[T, PT](r, db)
+		if handleError(w, logger, getErr) {
+			return
+		}
+
+		if err := db.Delete(dbText).Run(); handleError(w, logger, err) {
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
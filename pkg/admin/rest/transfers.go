@@ -205,6 +205,14 @@ func getTransfer(logger *log.Logger, db *database.DB) http.HandlerFunc {
 		}
 
 		json := DBTransferToREST(result)
+
+		if result.IsTransfer && result.Status == types.StatusRunning {
+			var nodeErr error
+			if json.Node, nodeErr = model.GetTransferNode(db, result.ID); handleError(w, logger, nodeErr) {
+				return
+			}
+		}
+
 		handleError(w, logger, writeJSON(w, json))
 	}
 }
@@ -235,55 +243,55 @@ func pauseTransfer(logger *log.Logger, db *database.DB) http.HandlerFunc {
 			return
 		}
 
-		switch trans.Status {
-		case types.StatusPlanned:
-			trans.Status = types.StatusPaused
-			if err := db.Update(trans).Cols("status").Run(); handleError(w, logger, err) {
-				return
-			}
-
-			w.WriteHeader(http.StatusAccepted)
-
+		if err := pauseDBTransfer(r.Context(), db, logger, trans); handleError(w, logger, err) {
 			return
-		case types.StatusRunning:
-			pip := pipeline.List.Get(trans.ID)
-			if pip == nil {
-				if db.Config.NodeID != "" {
-					handleError(w, logger, internalf("pipeline for transfer %d not found", trans.ID))
-
-					return
-				}
-
-				logger.Warningf("Pipeline for transfer %d not found", trans.ID)
+		}
 
-				trans.Status = types.StatusPaused
-				if err := db.Update(trans).Cols("status").Run(); handleError(w, logger, err) {
-					return
-				}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
 
-				w.WriteHeader(http.StatusAccepted)
+// pauseDBTransfer pauses the given transfer. Planned transfers are simply
+// marked as paused, while running transfers are paused by their pipeline (or
+// by the cluster node executing them).
+func pauseDBTransfer(rCtx context.Context, db *database.DB, logger *log.Logger,
+	trans *model.Transfer,
+) error {
+	switch trans.Status {
+	case types.StatusPlanned:
+		trans.Status = types.StatusPaused
+		if err := db.Update(trans).Cols("status").Run(); err != nil {
+			return fmt.Errorf("failed to pause transfer %d: %w", trans.ID, err)
+		}
 
-				return
+		return nil
+	case types.StatusRunning:
+		pip := pipeline.List.Get(trans.ID)
+		if pip == nil {
+			if db.Config.NodeID != "" {
+				return sendClusterCommand(db, trans.ID, model.TransferCommandPause)
 			}
 
-			ctx, cancel := context.WithTimeout(r.Context(), time.Second)
-			defer cancel()
-
-			if err := pip.Pause(ctx); err != nil {
-				handleError(w, logger, err)
+			logger.Warningf("Pipeline for transfer %d not found", trans.ID)
 
-				return
+			trans.Status = types.StatusPaused
+			if err := db.Update(trans).Cols("status").Run(); err != nil {
+				return fmt.Errorf("failed to pause transfer %d: %w", trans.ID, err)
 			}
 
-			w.WriteHeader(http.StatusAccepted)
+			return nil
+		}
 
-			return
-		default:
-			handleError(w, logger, badRequest("cannot pause an already "+
-				"interrupted transfer"))
+		ctx, cancel := context.WithTimeout(rCtx, time.Second)
+		defer cancel()
 
-			return
+		if err := pip.Pause(ctx); err != nil {
+			return fmt.Errorf("failed to pause transfer %d: %w", trans.ID, err)
 		}
+
+		return nil
+	default:
+		return badRequest("cannot pause an already interrupted transfer")
 	}
 }
 
@@ -310,7 +318,14 @@ func cancelTransfer(logger *log.Logger, db *database.DB) http.HandlerFunc {
 		pip := pipeline.List.Get(trans.ID)
 		if pip == nil {
 			if db.Config.NodeID != "" {
-				handleError(w, logger, internalf("pipeline for transfer %d not found", trans.ID))
+				if err := sendClusterCommand(db, trans.ID,
+					model.TransferCommandCancel); handleError(w, logger, err) {
+					return
+				}
+
+				r.URL.Path = "/api/history"
+				w.Header().Set("Location", location(r.URL, utils.FormatInt(trans.ID)))
+				w.WriteHeader(http.StatusAccepted)
 
 				return
 			}
@@ -340,6 +355,78 @@ func cancelTransfer(logger *log.Logger, db *database.DB) http.HandlerFunc {
 	}
 }
 
+func interruptTransfer(logger *log.Logger, db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		trans, tErr := getDBTrans(r, db)
+		if handleError(w, logger, tErr) {
+			return
+		}
+
+		if trans.Status != types.StatusRunning {
+			handleError(w, logger, badRequest("cannot interrupt a transfer which "+
+				"is not running"))
+
+			return
+		}
+
+		pip := pipeline.List.Get(trans.ID)
+		if pip == nil {
+			if db.Config.NodeID != "" {
+				if err := sendClusterCommand(db, trans.ID,
+					model.TransferCommandInterrupt); handleError(w, logger, err) {
+					return
+				}
+
+				w.WriteHeader(http.StatusAccepted)
+
+				return
+			}
+
+			logger.Warningf("Pipeline for transfer %d not found", trans.ID)
+
+			trans.Status = types.StatusInterrupted
+			if err := db.Update(trans).Cols("status").Run(); handleError(w, logger, err) {
+				return
+			}
+
+			w.WriteHeader(http.StatusAccepted)
+
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+		defer cancel()
+
+		if err := pip.Interrupt(ctx); err != nil {
+			handleError(w, logger, err)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+// sendClusterCommand sends the given command to the cluster node executing the
+// given transfer. The command is then executed asynchronously by that node.
+func sendClusterCommand(db *database.DB, transID int64, command model.TransferCommand) error {
+	var running model.RunningTransfer
+	if err := db.Get(&running, "transfer_id=?", transID).Run(); err != nil {
+		if database.IsNotFound(err) {
+			return internalf("pipeline for transfer %d not found", transID)
+		}
+
+		return fmt.Errorf("failed to retrieve the node of transfer %d: %w", transID, err)
+	}
+
+	running.Command = command
+	if err := db.Update(&running).Cols("command").Run(); err != nil {
+		return fmt.Errorf("failed to send the command to node %q: %w", running.Node, err)
+	}
+
+	return nil
+}
+
 func resumeTransfer(logger *log.Logger, db *database.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		dbTrans, getErr := getDBTransView(r, db)
@@ -395,12 +482,17 @@ func cancelDBTransfer(db *database.DB, logger *log.Logger, w http.ResponseWriter
 		statuses[i] = status[i]
 	}
 
+	// In a cluster, the transfers running on the other nodes are canceled by
+	// these nodes (see cancelRunningTransfers), and must thus be left untouched.
+	const notRunningElsewhere = "id NOT IN (SELECT transfer_id FROM running_transfers WHERE node<>?)"
+
 	tErr := db.Transaction(func(ses *database.Session) error {
 		const batchSize = 20
 
 		for i := 0; ; i += batchSize {
 			var transfers model.Transfers
-			if err := ses.Select(&transfers).In("status", statuses...).Eager().
+			if err := ses.Select(&transfers).In("status", statuses...).
+				Where(notRunningElsewhere, db.Config.NodeID).Eager().
 				Limit(batchSize, i).Run(); err != nil {
 				logger.Errorf("Failed to retrieve transfers: %v", err)
 
@@ -426,7 +518,8 @@ func cancelDBTransfer(db *database.DB, logger *log.Logger, w http.ResponseWriter
 			}
 		}
 
-		if err := ses.DeleteAll(&model.Transfer{}).In("status", statuses...).Run(); err != nil {
+		if err := ses.DeleteAll(&model.Transfer{}).In("status", statuses...).
+			Where(notRunningElsewhere, db.Config.NodeID).Run(); err != nil {
 			return fmt.Errorf("failed to cancel transfers: %w", err)
 		}
 
@@ -448,6 +541,13 @@ func cancelRunningTransfers(rCtx context.Context, db *database.DB,
 		return false
 	}
 
+	if node := db.Config.NodeID; node != "" {
+		if err := db.Exec("UPDATE running_transfers SET command=? WHERE node<>?",
+			model.TransferCommandCancel, node); handleError(w, logger, err) {
+			return false
+		}
+	}
+
 	return cancelDBTransfer(db, logger, w, types.StatusRunning)
 }
 
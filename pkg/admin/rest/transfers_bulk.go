@@ -0,0 +1,272 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/admin/rest/api"
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/types"
+)
+
+// defaultBulkLimit is the maximum number of transfers processed by a single
+// bulk operation request when no limit is given.
+const defaultBulkLimit = 100
+
+// bulkTransferOperation describes an operation which can be applied in bulk to
+// all the transfers matching a set of filters.
+type bulkTransferOperation struct {
+	// name is the name of the operation, as used in the logs.
+	name string
+	// dateRequired indicates whether the request must have a 'date' parameter.
+	// If the parameter is not required, it defaults to the current date.
+	dateRequired bool
+	// restrict limits the selected transfers to those on which the operation
+	// can be applied.
+	restrict func(query *database.SelectQuery)
+	// defaultStatuses, if not empty, are the statuses to which the selected
+	// transfers are limited when the request does not filter on the status.
+	defaultStatuses []any
+	// apply applies the operation to the given transfer. If the operation
+	// creates a new transfer, the ID of that transfer is returned.
+	apply func(ctx context.Context, db *database.DB, logger *log.Logger,
+		trans *model.NormalizedTransferView, date time.Time) (int64, error)
+}
+
+type bulkTransferRequest struct {
+	dryRun bool
+	limit  int
+	after  int64
+	date   time.Time
+}
+
+func parseBulkTransferRequest(r *http.Request, op *bulkTransferOperation,
+) (*bulkTransferRequest, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, fmt.Errorf("cannot parse form data: %w", err)
+	}
+
+	req := &bulkTransferRequest{limit: defaultBulkLimit, date: time.Now()}
+
+	if dryRunStr := r.FormValue("dryRun"); dryRunStr != "" {
+		var err error
+		if req.dryRun, err = strconv.ParseBool(dryRunStr); err != nil {
+			return nil, badRequest("'dryRun' must be a boolean")
+		}
+	}
+
+	if limStr := r.FormValue("limit"); limStr != "" {
+		var err error
+		if req.limit, err = strconv.Atoi(limStr); err != nil || req.limit <= 0 {
+			return nil, badRequest("'limit' must be a positive int")
+		}
+	}
+
+	if afterStr := r.FormValue("after"); afterStr != "" {
+		var err error
+		if req.after, err = strconv.ParseInt(afterStr, 10, 64); err != nil {
+			return nil, badRequest("'after' must be an int")
+		}
+	}
+
+	if dateStr := r.FormValue("date"); dateStr != "" {
+		var err error
+		if req.date, err = time.Parse(time.RFC3339Nano, dateStr); err != nil {
+			return nil, badRequestf("%q is not a valid date", dateStr)
+		}
+	} else if op.dateRequired {
+		return nil, badRequest("missing 'date' parameter")
+	}
+
+	return req, nil
+}
+
+// bulkTransferHandler returns the handler applying the given operation to the
+// transfers matching the filters given in the request (the same filters as for
+// the transfer listing).
+//
+// The transfers are processed in ascending ID order, at most 'limit' at a time,
+// starting after the ID given in the 'after' parameter. This allows clients to
+// process a large number of transfers in several batches, and to report on the
+// progress of the operation. When 'dryRun' is set, no transfer is modified, and
+// only the number of transfers matching the filters is returned, along with the
+// IDs of the transfers of the batch which would have been processed.
+func bulkTransferHandler(logger *log.Logger, db *database.DB, op *bulkTransferOperation,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, reqErr := parseBulkTransferRequest(r, op)
+		if handleError(w, logger, reqErr) {
+			return
+		}
+
+		var transfers model.NormalizedTransfers
+
+		query := db.Select(&transfers).Eager().Where("id > ?", req.after)
+		if err := parseTransferFilters(r, query); handleError(w, logger, err) {
+			return
+		}
+
+		op.restrict(query)
+
+		if _, hasStatus := r.Form["status"]; !hasStatus && len(op.defaultStatuses) != 0 {
+			query.In("status", op.defaultStatuses...)
+		}
+
+		total, countErr := query.Count()
+		if handleError(w, logger, countErr) {
+			return
+		}
+
+		result := &api.OutBulkTransferResult{
+			DryRun:  req.dryRun,
+			Total:   total,
+			Results: []*api.OutBulkTransferItem{},
+		}
+
+		if err := query.OrderBy("id", true).Limit(req.limit, 0).Run(); handleError(w, logger, err) {
+			return
+		}
+
+		if req.dryRun {
+			result.IDs = make([]int64, len(transfers))
+			for i, trans := range transfers {
+				result.IDs[i] = trans.ID
+			}
+
+			handleError(w, logger, writeJSON(w, result))
+
+			return
+		}
+
+		for _, trans := range transfers {
+			item := &api.OutBulkTransferItem{ID: trans.ID, Success: true}
+
+			newID, err := op.apply(r.Context(), db, logger, trans, req.date)
+			if err != nil {
+				logger.Warningf("Failed to %s transfer %d: %v", op.name, trans.ID, err)
+
+				item.Success = false
+				item.Error = err.Error()
+			} else {
+				item.NewID = newID
+			}
+
+			result.Results = append(result.Results, item)
+		}
+
+		handleError(w, logger, writeJSON(w, result))
+	}
+}
+
+func getBulkDBTrans(db *database.DB, view *model.NormalizedTransferView) (*model.Transfer, error) {
+	var trans model.Transfer
+	if err := db.Get(&trans, "id=?", view.ID).Eager().Run(); err != nil {
+		return nil, fmt.Errorf("failed to retrieve transfer %d: %w", view.ID, err)
+	}
+
+	return &trans, nil
+}
+
+func pauseTransfers(logger *log.Logger, db *database.DB) http.HandlerFunc {
+	return bulkTransferHandler(logger, db, &bulkTransferOperation{
+		name: "pause",
+		restrict: func(query *database.SelectQuery) {
+			query.Where("is_transfer=?", true).
+				In("status", types.StatusPlanned, types.StatusRunning)
+		},
+		apply: func(ctx context.Context, db *database.DB, logger *log.Logger,
+			view *model.NormalizedTransferView, _ time.Time,
+		) (int64, error) {
+			trans, err := getBulkDBTrans(db, view)
+			if err != nil {
+				return 0, err
+			}
+
+			return 0, pauseDBTransfer(ctx, db, logger, trans)
+		},
+	})
+}
+
+func resumeTransfers(logger *log.Logger, db *database.DB) http.HandlerFunc {
+	return bulkTransferHandler(logger, db, &bulkTransferOperation{
+		name: "resume",
+		restrict: func(query *database.SelectQuery) {
+			query.Where("is_transfer=?", true).Where("is_server=?", false).
+				In("status", types.StatusPaused, types.StatusInterrupted, types.StatusError)
+		},
+		apply: func(_ context.Context, db *database.DB, _ *log.Logger,
+			view *model.NormalizedTransferView, _ time.Time,
+		) (int64, error) {
+			//nolint:wrapcheck //errors are already explicit enough
+			return 0, view.Resume(db, time.Now())
+		},
+	})
+}
+
+func retryTransfers(logger *log.Logger, db *database.DB) http.HandlerFunc {
+	return bulkTransferHandler(logger, db, &bulkTransferOperation{
+		name: "retry",
+		restrict: func(query *database.SelectQuery) {
+			query.Where("is_transfer=?", false)
+		},
+		// Unless the statuses are explicitly given, only the transfers which
+		// did not complete are retried.
+		defaultStatuses: []any{types.StatusError, types.StatusCancelled},
+		apply: func(_ context.Context, db *database.DB, _ *log.Logger,
+			view *model.NormalizedTransferView, date time.Time,
+		) (int64, error) {
+			trans, err := view.Restart(db, date)
+			if err != nil {
+				return 0, fmt.Errorf("failed to restart transfer %d: %w", view.ID, err)
+			}
+
+			if err := db.Insert(trans).Run(); err != nil {
+				return 0, fmt.Errorf("failed to restart transfer %d: %w", view.ID, err)
+			}
+
+			return trans.ID, nil
+		},
+	})
+}
+
+func rescheduleTransfers(logger *log.Logger, db *database.DB) http.HandlerFunc {
+	return bulkTransferHandler(logger, db, &bulkTransferOperation{
+		name:         "reschedule",
+		dateRequired: true,
+		restrict: func(query *database.SelectQuery) {
+			query.Where("is_transfer=?", true).In("status", types.StatusPlanned,
+				types.StatusPaused, types.StatusInterrupted, types.StatusError)
+		},
+		apply: func(_ context.Context, db *database.DB, _ *log.Logger,
+			view *model.NormalizedTransferView, date time.Time,
+		) (int64, error) {
+			// Transfers which have not started yet are simply moved to the new
+			// date, while the others are resumed at that date.
+			if view.Status != types.StatusPlanned {
+				//nolint:wrapcheck //errors are already explicit enough
+				return 0, view.Resume(db, date)
+			}
+
+			trans, err := getBulkDBTrans(db, view)
+			if err != nil {
+				return 0, err
+			}
+
+			trans.Start = date
+			if !trans.IsServer() {
+				trans.NextRetry = date
+			}
+
+			if err := db.Update(trans).Cols("start", "next_retry").Run(); err != nil {
+				return 0, fmt.Errorf("failed to reschedule transfer %d: %w", trans.ID, err)
+			}
+
+			return 0, nil
+		},
+	})
+}
@@ -0,0 +1,272 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/admin/rest/api"
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/database/dbtest"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/types"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils/testhelpers"
+)
+
+type bulkTestEnv struct {
+	db      *database.DB
+	client  *model.Client
+	partner *model.RemoteAgent
+	account *model.RemoteAccount
+	rule    *model.Rule
+}
+
+func makeBulkTestEnv(t *testing.T) *bulkTestEnv {
+	t.Helper()
+
+	db := dbtest.TestDatabase(t)
+
+	client := &model.Client{Name: "client", Protocol: testProto1}
+	require.NoError(t, db.Insert(client).Run())
+
+	partner := &model.RemoteAgent{
+		Name: "partner", Protocol: testProto1,
+		Address: types.Addr("localhost", 1),
+	}
+	require.NoError(t, db.Insert(partner).Run())
+
+	account := &model.RemoteAccount{RemoteAgentID: partner.ID, Login: "toto"}
+	require.NoError(t, db.Insert(account).Run())
+
+	rule := &model.Rule{Name: "rule", IsSend: true, Path: "/rule"}
+	require.NoError(t, db.Insert(rule).Run())
+
+	return &bulkTestEnv{db: db, client: client, partner: partner, account: account, rule: rule}
+}
+
+func (e *bulkTestEnv) addTransfer(t *testing.T, status types.TransferStatus) *model.Transfer {
+	t.Helper()
+
+	trans := &model.Transfer{
+		RuleID:          e.rule.ID,
+		ClientID:        e.client.NullableID(),
+		RemoteAccountID: e.account.NullableID(),
+		SrcFilename:     "file",
+		Start:           time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		Status:          status,
+	}
+	require.NoError(t, e.db.Insert(trans).Run())
+
+	return trans
+}
+
+func doBulkRequest(t *testing.T, handler http.HandlerFunc, query string,
+) (*httptest.ResponseRecorder, *api.OutBulkTransferResult) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPut, "/api/transfers/op?"+query, nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		return w, nil
+	}
+
+	var result api.OutBulkTransferResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+
+	return w, &result
+}
+
+func TestPauseTransfers(t *testing.T) {
+	t.Parallel()
+
+	env := makeBulkTestEnv(t)
+	handler := pauseTransfers(testhelpers.GetTestLogger(t), env.db)
+
+	planned1 := env.addTransfer(t, types.StatusPlanned)
+	planned2 := env.addTransfer(t, types.StatusPlanned)
+	errored := env.addTransfer(t, types.StatusError)
+
+	t.Run("Dry run", func(t *testing.T) {
+		w, result := doBulkRequest(t, handler, "dryRun=true")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		assert.True(t, result.DryRun)
+		assert.EqualValues(t, 2, result.Total, "only the planned transfers can be paused")
+		assert.Empty(t, result.Results)
+		assert.Equal(t, []int64{planned1.ID, planned2.ID}, result.IDs,
+			"the IDs of the matching transfers should be returned")
+
+		var check model.Transfer
+		require.NoError(t, env.db.Get(&check, "id=?", planned1.ID).Run())
+		assert.Equal(t, types.StatusPlanned, check.Status, "a dry run should not modify the transfers")
+	})
+
+	t.Run("First batch", func(t *testing.T) {
+		w, result := doBulkRequest(t, handler, "limit=1")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		assert.EqualValues(t, 2, result.Total)
+		assert.Equal(t, []*api.OutBulkTransferItem{{ID: planned1.ID, Success: true}},
+			result.Results)
+	})
+
+	t.Run("Next batch", func(t *testing.T) {
+		w, result := doBulkRequest(t, handler, "limit=1&after="+utils.FormatInt(planned1.ID))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		assert.EqualValues(t, 1, result.Total)
+		assert.Equal(t, []*api.OutBulkTransferItem{{ID: planned2.ID, Success: true}},
+			result.Results)
+	})
+
+	t.Run("Check database", func(t *testing.T) {
+		var transfers model.Transfers
+		require.NoError(t, env.db.Select(&transfers).OrderBy("id", true).Run())
+		require.Len(t, transfers, 3)
+
+		assert.Equal(t, types.StatusPaused, transfers[0].Status)
+		assert.Equal(t, types.StatusPaused, transfers[1].Status)
+		assert.Equal(t, errored.ID, transfers[2].ID)
+		assert.Equal(t, types.StatusError, transfers[2].Status)
+	})
+}
+
+func TestResumeTransfers(t *testing.T) {
+	t.Parallel()
+
+	env := makeBulkTestEnv(t)
+	handler := resumeTransfers(testhelpers.GetTestLogger(t), env.db)
+
+	paused := env.addTransfer(t, types.StatusPaused)
+	errored := env.addTransfer(t, types.StatusError)
+	env.addTransfer(t, types.StatusPlanned)
+
+	w, result := doBulkRequest(t, handler, "status=ERROR")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	assert.EqualValues(t, 1, result.Total)
+	assert.Equal(t, []*api.OutBulkTransferItem{{ID: errored.ID, Success: true}},
+		result.Results)
+
+	var check model.Transfer
+	require.NoError(t, env.db.Get(&check, "id=?", errored.ID).Run())
+	assert.Equal(t, types.StatusPlanned, check.Status)
+
+	require.NoError(t, env.db.Get(&check, "id=?", paused.ID).Run())
+	assert.Equal(t, types.StatusPaused, check.Status, "the paused transfer should not match the filter")
+}
+
+func TestRetryTransfers(t *testing.T) {
+	t.Parallel()
+
+	env := makeBulkTestEnv(t)
+	handler := retryTransfers(testhelpers.GetTestLogger(t), env.db)
+
+	addHistory := func(id int64, status types.TransferStatus, code types.TransferErrorCode) {
+		require.NoError(t, env.db.Insert(&model.HistoryEntry{
+			Identifier:       model.Identifier{ID: id},
+			RemoteTransferID: utils.FormatInt(id),
+			IsSend:           true,
+			Rule:             env.rule.Name,
+			Account:          env.account.Login,
+			Agent:            env.partner.Name,
+			Client:           env.client.Name,
+			Protocol:         testProto1,
+			SrcFilename:      "file",
+			Start:            time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			Stop:             time.Date(2026, 1, 1, 0, 1, 0, 0, time.UTC),
+			Status:           status,
+			ErrCode:          code,
+		}).Run())
+	}
+
+	addHistory(1000, types.StatusCancelled, types.TeConnection)
+	addHistory(1001, types.StatusCancelled, types.TeForbidden)
+	addHistory(1002, types.StatusDone, types.TeOk)
+	addHistory(1003, types.StatusError, types.TeConnection)
+
+	t.Run("Dry run", func(t *testing.T) {
+		w, result := doBulkRequest(t, handler, "dryRun=true&limit=2")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		assert.EqualValues(t, 3, result.Total, "the completed transfer should not be retried")
+		assert.Equal(t, []int64{1000, 1001}, result.IDs)
+		assert.Empty(t, result.Results)
+	})
+
+	t.Run("Explicit status", func(t *testing.T) {
+		w, result := doBulkRequest(t, handler, "dryRun=true&status=DONE")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		assert.EqualValues(t, 1, result.Total)
+		assert.Equal(t, []int64{1002}, result.IDs)
+	})
+
+	date := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	w, result := doBulkRequest(t, handler,
+		"errorCode=TeConnection&status=CANCELLED&date="+date.Format(time.RFC3339))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	assert.EqualValues(t, 1, result.Total)
+	require.Len(t, result.Results, 1)
+	assert.EqualValues(t, 1000, result.Results[0].ID)
+	assert.True(t, result.Results[0].Success)
+
+	var trans model.Transfer
+	require.NoError(t, env.db.Get(&trans, "id=?", result.Results[0].NewID).Run())
+	assert.Equal(t, types.StatusPlanned, trans.Status)
+	assert.True(t, date.Equal(trans.Start))
+
+	t.Run("Invalid error code", func(t *testing.T) {
+		w, _ := doBulkRequest(t, handler, "errorCode=NotACode")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `"NotACode" is not a valid error code`)
+	})
+}
+
+func TestRescheduleTransfers(t *testing.T) {
+	t.Parallel()
+
+	env := makeBulkTestEnv(t)
+	handler := rescheduleTransfers(testhelpers.GetTestLogger(t), env.db)
+
+	planned := env.addTransfer(t, types.StatusPlanned)
+	paused := env.addTransfer(t, types.StatusPaused)
+
+	t.Run("Missing date", func(t *testing.T) {
+		w, _ := doBulkRequest(t, handler, "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "missing 'date' parameter")
+	})
+
+	t.Run("Valid request", func(t *testing.T) {
+		date := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		w, result := doBulkRequest(t, handler, "date="+date.Format(time.RFC3339))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		assert.EqualValues(t, 2, result.Total)
+		assert.Equal(t, []*api.OutBulkTransferItem{
+			{ID: planned.ID, Success: true},
+			{ID: paused.ID, Success: true},
+		}, result.Results)
+
+		var check model.Transfer
+		require.NoError(t, env.db.Get(&check, "id=?", planned.ID).Run())
+		assert.True(t, date.Equal(check.Start))
+		assert.True(t, date.Equal(check.NextRetry))
+
+		require.NoError(t, env.db.Get(&check, "id=?", paused.ID).Run())
+		assert.Equal(t, types.StatusPlanned, check.Status)
+		assert.True(t, date.Equal(check.NextRetry))
+	})
+}
@@ -911,6 +911,144 @@ func TestCancelTransfer(t *testing.T) {
 	})
 }
 
+func TestInterruptTransfer(t *testing.T) {
+	t.Parallel()
+
+	Convey("Testing the transfer interrupt handler", t, func(c C) {
+		logger := testhelpers.TestLogger(c, "rest_transfer_interrupt_test")
+		db := database.TestDatabase(c)
+		handler := interruptTransfer(logger, db)
+		w := httptest.NewRecorder()
+
+		Convey("Given a database with 1 transfer running on another node", func() {
+			client := &model.Client{Name: "test_client", Protocol: testProto1}
+			So(db.Insert(client).Run(), ShouldBeNil)
+
+			partner := &model.RemoteAgent{
+				Name: "test_server", Protocol: client.Protocol,
+				Address: types.Addr("localhost", 1),
+			}
+			So(db.Insert(partner).Run(), ShouldBeNil)
+
+			account := &model.RemoteAccount{
+				RemoteAgentID: partner.ID,
+				Login:         "toto",
+			}
+			So(db.Insert(account).Run(), ShouldBeNil)
+
+			rule := &model.Rule{Name: "test_rule", IsSend: false, Path: "path"}
+			So(db.Insert(rule).Run(), ShouldBeNil)
+
+			trans := &model.Transfer{
+				RuleID:          rule.ID,
+				ClientID:        client.NullableID(),
+				RemoteAccountID: account.NullableID(),
+				SrcFilename:     "file.src",
+				DestFilename:    "file.dst",
+				Start:           time.Date(2020, 1, 2, 3, 4, 5, 678000, time.Local),
+				Status:          types.StatusRunning,
+				Step:            types.StepData,
+				Progress:        10,
+			}
+			So(db.Insert(trans).Run(), ShouldBeNil)
+
+			So(db.Insert(&model.RunningTransfer{
+				TransferID: trans.ID,
+				Node:       "other_node",
+			}).Run(), ShouldBeNil)
+
+			Convey("Given a request with the valid transfer ID parameter", func() {
+				id := utils.FormatInt(trans.ID)
+				req, err := http.NewRequest(http.MethodPut, "", nil)
+				So(err, ShouldBeNil)
+
+				req = mux.SetURLVars(req, map[string]string{"transfer": id})
+
+				Convey("When sending the request to the handler", func() {
+					handler.ServeHTTP(w, req)
+
+					Convey("Then it should reply 'Accepted'", func() {
+						So(w.Body.String(), ShouldBeBlank)
+						So(w.Code, ShouldEqual, http.StatusAccepted)
+					})
+
+					Convey("Then the command should have been sent to the other node", func() {
+						var running model.RunningTransfer
+						So(db.Get(&running, "transfer_id=?", trans.ID).Run(), ShouldBeNil)
+						So(running.Node, ShouldEqual, "other_node")
+						So(running.Command, ShouldEqual, model.TransferCommandInterrupt)
+					})
+
+					Convey("Then the transfer should be left for the other node to interrupt", func() {
+						var check model.Transfer
+						So(db.Get(&check, "id=?", trans.ID).Run(), ShouldBeNil)
+						So(check.Status, ShouldEqual, types.StatusRunning)
+					})
+				})
+			})
+
+			Convey("Given a request to get the transfer", func() {
+				req, err := http.NewRequest(http.MethodGet, "", nil)
+				So(err, ShouldBeNil)
+
+				req = mux.SetURLVars(req, map[string]string{"transfer": utils.FormatInt(trans.ID)})
+
+				Convey("When sending the request to the handler", func() {
+					getTransfer(logger, db).ServeHTTP(w, req)
+
+					Convey("Then it should return the node executing the transfer", func() {
+						So(w.Code, ShouldEqual, http.StatusOK)
+
+						var out OutTransfer
+						So(json.Unmarshal(w.Body.Bytes(), &out), ShouldBeNil)
+						So(out.Node, ShouldEqual, "other_node")
+					})
+				})
+			})
+		})
+
+		Convey("Given a database with 1 planned transfer", func() {
+			rule := &model.Rule{Name: "test_rule", IsSend: true, Path: "path"}
+			So(db.Insert(rule).Run(), ShouldBeNil)
+
+			client := &model.Client{Name: "test_client", Protocol: testProto1}
+			So(db.Insert(client).Run(), ShouldBeNil)
+
+			partner := &model.RemoteAgent{
+				Name: "test_server", Protocol: client.Protocol,
+				Address: types.Addr("localhost", 1),
+			}
+			So(db.Insert(partner).Run(), ShouldBeNil)
+
+			account := &model.RemoteAccount{RemoteAgentID: partner.ID, Login: "toto"}
+			So(db.Insert(account).Run(), ShouldBeNil)
+
+			trans := &model.Transfer{
+				RuleID:          rule.ID,
+				ClientID:        client.NullableID(),
+				RemoteAccountID: account.NullableID(),
+				SrcFilename:     "file.src",
+				Status:          types.StatusPlanned,
+			}
+			So(db.Insert(trans).Run(), ShouldBeNil)
+
+			Convey("When sending a request to the handler", func() {
+				req, err := http.NewRequest(http.MethodPut, "", nil)
+				So(err, ShouldBeNil)
+
+				req = mux.SetURLVars(req, map[string]string{"transfer": utils.FormatInt(trans.ID)})
+				handler.ServeHTTP(w, req)
+
+				Convey("Then it should reply 'Bad Request'", func() {
+					So(w.Code, ShouldEqual, http.StatusBadRequest)
+					So(w.Body.String(), ShouldEqual, "cannot interrupt a transfer "+
+						"which is not running\n")
+				})
+			})
+		})
+	})
+}
+
 func TestRestartTransfer(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,237 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/admin/rest/api"
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/types"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils"
+)
+
+// transferChanges records the modifications made to a transfer by an update
+// request, so that they can be written to the audit log.
+type transferChanges model.List[model.AuditChange]
+
+func (c *transferChanges) add(field string, oldVal, newVal any) {
+	if reflect.DeepEqual(oldVal, newVal) {
+		return
+	}
+
+	*c = append(*c, model.AuditChange{Field: field, OldValue: oldVal, NewValue: newVal})
+}
+
+func (c transferChanges) String() string {
+	changes := make([]string, len(c))
+	for i, change := range c {
+		changes[i] = fmt.Sprintf("%s: %v -> %v", change.Field, change.OldValue, change.NewValue)
+	}
+
+	return strings.Join(changes, ", ")
+}
+
+func patchTransferField[T any](changes *transferChanges, name string, field *T,
+	value api.Nullable[T],
+) {
+	if value.Valid {
+		changes.add(name, *field, value.Value)
+		*field = value.Value
+	}
+}
+
+// checkTransferRetryPolicy checks that the retry settings of the given update stay
+// within the limits of the transfer's retry policy (see model.GetRetryPolicy),
+// if it has one.
+func checkTransferRetryPolicy(db database.ReadAccess, trans *model.Transfer, patch *api.PatchTransfer) error {
+	if !patch.NbOfAttempts.Valid && !patch.FirstRetryDelay.Valid {
+		return nil
+	}
+
+	var rule model.Rule
+	if err := db.Get(&rule, "id=?", trans.RuleID).Run(); err != nil {
+		return fmt.Errorf("failed to retrieve the transfer's rule: %w", err)
+	}
+
+	var partner model.RemoteAgent
+	if err := db.Get(&partner, "id=(SELECT remote_agent_id FROM remote_accounts WHERE id=?)",
+		trans.RemoteAccountID.Int64).Run(); err != nil {
+		return fmt.Errorf("failed to retrieve the transfer's partner: %w", err)
+	}
+
+	policy, err := model.GetRetryPolicy(db, &rule, &partner)
+	if err != nil {
+		return err //nolint:wrapcheck //error is already explicit enough
+	} else if policy == nil {
+		return nil
+	}
+
+	if patch.NbOfAttempts.Valid && patch.NbOfAttempts.Value > policy.MaxAttempts {
+		return badRequestf("the number of attempts cannot exceed the %d allowed by the retry policy %q",
+			policy.MaxAttempts, policy.Name)
+	}
+
+	if patch.FirstRetryDelay.Valid && policy.MaxDelay > 0 &&
+		time.Duration(patch.FirstRetryDelay.Value)*time.Second > policy.MaxDelay {
+		return badRequestf("the retry delay cannot exceed the %s allowed by the retry policy %q",
+			policy.MaxDelay, policy.Name)
+	}
+
+	return nil
+}
+
+// patchTransfer applies the given update to the transfer, and returns the list
+// of the modifications made.
+func patchTransfer(db database.ReadAccess, trans *model.Transfer, patch *api.PatchTransfer,
+) (transferChanges, error) {
+	switch trans.Status {
+	case types.StatusPlanned, types.StatusPaused, types.StatusError:
+	default:
+		return nil, badRequestf("cannot update a transfer in %s status", trans.Status)
+	}
+
+	if trans.IsServer() && (patch.NbOfAttempts.Valid || patch.FirstRetryDelay.Valid ||
+		patch.RetryIncrementFactor.Valid) {
+		return nil, badRequest("the retry settings of a server transfer cannot be changed")
+	}
+
+	if err := checkTransferRetryPolicy(db, trans, patch); err != nil {
+		return nil, err
+	}
+
+	var changes transferChanges
+
+	patchTransferField(&changes, "start", &trans.Start, patch.Start)
+	patchTransferField(&changes, "remaining attempts", &trans.RemainingTries, patch.NbOfAttempts)
+	patchTransferField(&changes, "retry delay", &trans.NextRetryDelay, patch.FirstRetryDelay)
+	patchTransferField(&changes, "retry increment factor", &trans.RetryIncrementFactor,
+		patch.RetryIncrementFactor)
+
+	if patch.Start.Valid && !trans.IsServer() {
+		trans.NextRetry = trans.Start
+	}
+
+	nbChanges := len(changes)
+
+	patchTransferField(&changes, "source file", &trans.SrcFilename, patch.SrcFilename)
+	patchTransferField(&changes, "destination file", &trans.DestFilename, patch.DestFilename)
+
+	// If the file names changed, the file paths must be computed again, and
+	// the data transfer must start over.
+	if len(changes) != nbChanges {
+		trans.LocalPath = ""
+		trans.RemotePath = ""
+		trans.Filesize = model.UnknownSize
+		trans.Progress = 0
+		trans.Step = types.StepNone
+		trans.TaskNumber = 0
+	}
+
+	infoKeys := make([]string, 0, len(patch.TransferInfo))
+	for key := range patch.TransferInfo {
+		infoKeys = append(infoKeys, key)
+	}
+
+	slices.Sort(infoKeys)
+
+	for _, key := range infoKeys {
+		oldVal, newVal := trans.TransferInfo[key], patch.TransferInfo[key]
+		changes.add(fmt.Sprintf("transfer info %q", key), oldVal, newVal)
+
+		if newVal == nil {
+			delete(trans.TransferInfo, key)
+		} else {
+			trans.TransferInfo[key] = newVal
+		}
+	}
+
+	return changes, nil
+}
+
+// updateTransferEntry applies the given update to the transfer with the given
+// ID, on behalf of the given user. The transfer is locked while its status is
+// checked and the update is written, so that it cannot be started by the
+// controller in the meantime. The modifications are recorded in the audit log
+// in the same transaction.
+func updateTransferEntry(db *database.DB, user string, id int64, patch *api.PatchTransfer,
+) (transferChanges, error) {
+	var changes transferChanges
+
+	if err := db.Transaction(func(ses *database.Session) error {
+		var transfers model.Transfers
+		if err := ses.SelectForUpdate(&transfers).Where("id=?", id).Run(); err != nil {
+			return fmt.Errorf("failed to retrieve transfer %d: %w", id, err)
+		}
+
+		if len(transfers) == 0 {
+			return notFoundf("transfer %v not found", id)
+		}
+
+		trans := transfers[0]
+		if trans.TransferInfo == nil {
+			trans.TransferInfo = map[string]any{}
+		}
+
+		var patchErr error
+		if changes, patchErr = patchTransfer(ses, trans, patch); patchErr != nil {
+			return patchErr
+		}
+
+		if err := ses.Update(trans).Run(); err != nil {
+			return fmt.Errorf("failed to update transfer %d: %w", id, err)
+		}
+
+		if len(changes) == 0 {
+			return nil
+		}
+
+		if err := ses.Insert(&model.AuditEntry{
+			Username:   user,
+			ObjectType: model.AuditObjectTransfer,
+			ObjectID:   trans.ID,
+			Changes:    model.List[model.AuditChange](changes),
+		}).Run(); err != nil {
+			return fmt.Errorf("failed to write the audit entry of transfer %d: %w", id, err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err //nolint:wrapcheck //already wrapped
+	}
+
+	return changes, nil
+}
+
+func updateTransfer(logger *log.Logger, db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		trans, getErr := getDBTrans(r, db)
+		if handleError(w, logger, getErr) {
+			return
+		}
+
+		var patch api.PatchTransfer
+		if err := readJSON(r, &patch); handleError(w, logger, err) {
+			return
+		}
+
+		login, _, _ := r.BasicAuth()
+
+		changes, updErr := updateTransferEntry(db, login, trans.ID, &patch)
+		if handleError(w, logger, updErr) {
+			return
+		}
+
+		if len(changes) != 0 {
+			logger.Infof("Transfer %d was updated by user %q (%s)", trans.ID, login, changes)
+		}
+
+		w.Header().Set("Location", locationUpdate(r.URL, utils.FormatInt(trans.ID)))
+		w.WriteHeader(http.StatusCreated)
+	}
+}
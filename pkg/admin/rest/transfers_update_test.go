@@ -0,0 +1,213 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/admin/rest/api"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/types"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils/testhelpers"
+)
+
+const TransferPath = "/transfers/{transfer}"
+
+func TestUpdateTransfer(t *testing.T) {
+	t.Parallel()
+
+	env := makeBulkTestEnv(t)
+	handle := updateTransfer(testhelpers.GetTestLogger(t), env.db)
+
+	doRequest := func(t *testing.T, id int64, body map[string]any) *httptest.ResponseRecorder {
+		t.Helper()
+
+		reqBody := bytes.Buffer{}
+		require.NoError(t, json.NewEncoder(&reqBody).Encode(body))
+
+		req := makeRequest(http.MethodPatch, &reqBody, TransferPath, utils.FormatInt(id))
+		req.SetBasicAuth("admin", "admin_password")
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, req)
+
+		return w
+	}
+
+	t.Run("Given a paused transfer", func(t *testing.T) {
+		trans := env.addTransfer(t, types.StatusPaused)
+
+		trans.LocalPath = "/local/file"
+		trans.RemotePath = "/remote/file"
+		trans.Progress = 100
+		trans.Step = types.StepData
+		trans.TransferInfo = map[string]any{"key1": "val1", "key2": "val2"}
+		require.NoError(t, env.db.Update(trans).Run())
+
+		start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		w := doRequest(t, trans.ID, map[string]any{
+			"start":        start,
+			"srcFilename":  "new_file",
+			"nbOfAttempts": 5,
+			"transferInfo": map[string]any{"key1": nil, "key3": "val3"},
+		})
+
+		t.Run("Then it should have updated the transfer", func(t *testing.T) {
+			require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+			assert.Equal(t, "/transfers/"+utils.FormatInt(trans.ID), w.Header().Get("Location"))
+
+			var check model.Transfer
+			require.NoError(t, env.db.Get(&check, "id=?", trans.ID).Run())
+
+			assert.Equal(t, types.StatusPaused, check.Status)
+			assert.True(t, start.Equal(check.Start))
+			assert.True(t, start.Equal(check.NextRetry))
+			assert.Equal(t, "new_file", check.SrcFilename)
+			assert.EqualValues(t, 5, check.RemainingTries)
+
+			assert.Empty(t, check.LocalPath, "the local path should be reset")
+			assert.Empty(t, check.RemotePath, "the remote path should be reset")
+			assert.Zero(t, check.Progress, "the progress should be reset")
+			assert.Equal(t, types.StepNone, check.Step, "the step should be reset")
+
+			assert.Equal(t, "val2", check.TransferInfo["key2"])
+			assert.Equal(t, "val3", check.TransferInfo["key3"])
+			assert.NotContains(t, check.TransferInfo, "key1")
+		})
+
+		t.Run("Then it should have written the changes to the audit log", func(t *testing.T) {
+			var entries model.AuditEntries
+			require.NoError(t, env.db.Select(&entries).Where("object_type=? AND object_id=?",
+				model.AuditObjectTransfer, trans.ID).Run())
+			require.Len(t, entries, 1)
+
+			entry := entries[0]
+			assert.Equal(t, "admin", entry.Username)
+			assert.False(t, entry.Date.IsZero())
+
+			fields := make([]string, len(entry.Changes))
+			for i, change := range entry.Changes {
+				fields[i] = change.Field
+			}
+
+			assert.Equal(t, []string{
+				"start", "remaining attempts", "source file",
+				`transfer info "key1"`, `transfer info "key3"`,
+			}, fields)
+			assert.Equal(t, model.AuditChange{
+				Field: "source file", OldValue: "file", NewValue: "new_file",
+			}, entry.Changes[2])
+		})
+	})
+
+	t.Run("Given a transfer with an invalid update", func(t *testing.T) {
+		trans := env.addTransfer(t, types.StatusPlanned)
+
+		w := doRequest(t, trans.ID, map[string]any{"srcFilename": ""})
+
+		t.Run("Then it should reply with a 'Bad Request' error", func(t *testing.T) {
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), "the source file is missing")
+		})
+	})
+
+	t.Run("Given a running transfer", func(t *testing.T) {
+		trans := env.addTransfer(t, types.StatusRunning)
+
+		w := doRequest(t, trans.ID, map[string]any{"srcFilename": "new_file"})
+
+		t.Run("Then it should reply with a 'Bad Request' error", func(t *testing.T) {
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), "cannot update a transfer in RUNNING status")
+
+			var check model.Transfer
+			require.NoError(t, env.db.Get(&check, "id=?", trans.ID).Run())
+			assert.Equal(t, "file", check.SrcFilename)
+		})
+	})
+
+	t.Run("Given a transfer started between the read and the write", func(t *testing.T) {
+		trans := env.addTransfer(t, types.StatusPlanned)
+
+		// The transfer is started after it was read by the request.
+		require.NoError(t, env.db.Exec("UPDATE transfers SET status=? WHERE id=?",
+			types.StatusRunning, trans.ID))
+
+		_, err := updateTransferEntry(env.db, "admin", trans.ID, &api.PatchTransfer{
+			SrcFilename: api.NewNullable("new_file"),
+		})
+
+		t.Run("Then it should refuse the update", func(t *testing.T) {
+			var br *badRequestError
+			require.ErrorAs(t, err, &br)
+			assert.Contains(t, err.Error(), "cannot update a transfer in RUNNING status")
+
+			var check model.Transfer
+			require.NoError(t, env.db.Get(&check, "id=?", trans.ID).Run())
+			assert.Equal(t, types.StatusRunning, check.Status)
+			assert.Equal(t, "file", check.SrcFilename)
+		})
+	})
+
+	t.Run("Given a transfer with a retry policy", func(t *testing.T) {
+		policy := &model.RetryPolicy{
+			Name: "policy", MaxAttempts: 3, FirstDelay: time.Minute,
+			IncrementFactor: 1, MaxDelay: time.Hour,
+		}
+		require.NoError(t, env.db.Insert(policy).Run())
+
+		rule := &model.Rule{Name: "policy_rule", IsSend: true, Path: "/policy_rule", RetryPolicy: policy.Name}
+		require.NoError(t, env.db.Insert(rule).Run())
+
+		trans := &model.Transfer{
+			RuleID:          rule.ID,
+			ClientID:        env.client.NullableID(),
+			RemoteAccountID: env.account.NullableID(),
+			SrcFilename:     "file",
+			Start:           time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			Status:          types.StatusError,
+		}
+		require.NoError(t, env.db.Insert(trans).Run())
+
+		t.Run("When the update exceeds the policy's number of attempts", func(t *testing.T) {
+			w := doRequest(t, trans.ID, map[string]any{"nbOfAttempts": 4})
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(),
+				`the number of attempts cannot exceed the 3 allowed by the retry policy "policy"`)
+		})
+
+		t.Run("When the update exceeds the policy's maximum delay", func(t *testing.T) {
+			w := doRequest(t, trans.ID, map[string]any{"firstRetryDelay": 7200})
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(),
+				`the retry delay cannot exceed the 1h0m0s allowed by the retry policy "policy"`)
+		})
+
+		t.Run("When the update is within the policy's limits", func(t *testing.T) {
+			w := doRequest(t, trans.ID, map[string]any{"nbOfAttempts": 2, "firstRetryDelay": 60})
+			require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+			var check model.Transfer
+			require.NoError(t, env.db.Get(&check, "id=?", trans.ID).Run())
+			assert.EqualValues(t, 2, check.RemainingTries)
+			assert.EqualValues(t, 60, check.NextRetryDelay)
+		})
+	})
+
+	t.Run("Given an unknown transfer", func(t *testing.T) {
+		w := doRequest(t, 1000, map[string]any{"srcFilename": "new_file"})
+
+		t.Run("Then it should reply with a 'Not Found' error", func(t *testing.T) {
+			assert.Equal(t, http.StatusNotFound, w.Code)
+		})
+	})
+}
@@ -11,6 +11,7 @@ import (
 	"code.waarp.fr/apps/gateway/gateway/pkg/database"
 	"code.waarp.fr/apps/gateway/gateway/pkg/model"
 	"code.waarp.fr/apps/gateway/gateway/pkg/model/compatibility"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/types"
 )
 
 //nolint:funlen //no easy way to split the function
@@ -131,14 +132,53 @@ func parseTransferListQuery(r *http.Request, db *database.DB,
 
 	query.Limit(limit, offset)
 
+	if err := parseTransferFilters(r, query); err != nil {
+		return nil, err
+	}
+
+	sort := sorting["default"]
+
+	if sortStr := r.FormValue("sort"); sortStr != "" {
+		var ok bool
+		sort, ok = sorting[sortStr]
+
+		if !ok {
+			return nil, badRequestf("%q is not a valid order", sortStr)
+		}
+	}
+
+	query.OrderBy(sort.col, sort.asc)
+
+	return query, nil
+}
+
+// parseTransferFilters adds to the given query the transfer filters found in
+// the request's parameters. These filters are shared by the transfer listing
+// and the bulk transfer operations. The request's form must have already been
+// parsed.
+func parseTransferFilters(r *http.Request, query *database.SelectQuery) error {
 	if rules, ok := r.Form["rule"]; ok {
 		query.In("rule", rules)
 	}
 
+	if partners, ok := r.Form["partner"]; ok {
+		query.In("agent", partners)
+	}
+
 	if statuses, ok := r.Form["status"]; ok {
 		query.In("status", statuses)
 	}
 
+	if codes, ok := r.Form["errorCode"]; ok {
+		for _, code := range codes {
+			if types.TecFromString(code).String() != code {
+				return badRequestf("%q is not a valid error code", code)
+			}
+		}
+
+		query.In("error_code", codes)
+	}
+
 	if followID := r.FormValue("followID"); followID != "" {
 		query.Where("id IN (SELECT CONCAT(transfer_id, history_id) "+
 			"FROM transfer_info WHERE name=? AND value=?)",
@@ -148,24 +188,20 @@ func parseTransferListQuery(r *http.Request, db *database.DB,
 	if startStr := r.FormValue("start"); startStr != "" {
 		start, err := time.Parse(time.RFC3339Nano, startStr)
 		if err != nil {
-			return nil, badRequestf("%q is not a valid date", startStr)
+			return badRequestf("%q is not a valid date", startStr)
 		}
 
 		query.Where("start >= ?", start.UTC())
 	}
 
-	sort := sorting["default"]
-
-	if sortStr := r.FormValue("sort"); sortStr != "" {
-		var ok bool
-		sort, ok = sorting[sortStr]
-
-		if !ok {
-			return nil, badRequestf("%q is not a valid order", sortStr)
+	if endStr := r.FormValue("end"); endStr != "" {
+		end, err := time.Parse(time.RFC3339Nano, endStr)
+		if err != nil {
+			return badRequestf("%q is not a valid date", endStr)
 		}
-	}
 
-	query.OrderBy(sort.col, sort.asc)
+		query.Where("start <= ?", end.UTC())
+	}
 
-	return query, nil
+	return nil
 }
@@ -27,6 +27,10 @@ func exportAuthorities(logger *log.Logger, db database.ReadAccess) ([]*file.Auth
 			Type:           authority.Type,
 			PublicIdentity: authority.PublicIdentity,
 			ValidHosts:     authority.ValidHosts,
+
+			RevocationPolicy: authority.RevocationPolicy,
+			CRLURL:           authority.CRLURL,
+			OCSPURL:          authority.OCSPURL,
 		}
 	}
 
@@ -29,6 +29,9 @@ func importAuthorities(logger *log.Logger, db database.Access,
 		dbAuth.Type = authority.Type
 		dbAuth.PublicIdentity = authority.PublicIdentity
 		dbAuth.ValidHosts = authority.ValidHosts
+		dbAuth.RevocationPolicy = authority.RevocationPolicy
+		dbAuth.CRLURL = authority.CRLURL
+		dbAuth.OCSPURL = authority.OCSPURL
 
 		var dbErr error
 
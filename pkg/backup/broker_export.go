@@ -0,0 +1,34 @@
+package backup
+
+import (
+	"fmt"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/backup/file"
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+)
+
+func exportBrokers(logger *log.Logger, db database.ReadAccess) ([]file.Broker, error) {
+	var dbBrokers model.MessageBrokers
+	if err := db.Select(&dbBrokers).Run(); err != nil {
+		return nil, fmt.Errorf("failed to retrieve message brokers: %w", err)
+	}
+
+	brokers := make([]file.Broker, len(dbBrokers))
+
+	for i, dbBroker := range dbBrokers {
+		brokers[i] = file.Broker{
+			Name:     dbBroker.Name,
+			Type:     dbBroker.Type,
+			Address:  dbBroker.Address,
+			Login:    dbBroker.Login,
+			Password: dbBroker.Password,
+			Options:  dbBroker.Options,
+		}
+
+		logger.Infof("Exported message broker %q", dbBroker.Name)
+	}
+
+	return brokers, nil
+}
@@ -0,0 +1,44 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/backup/file"
+	"code.waarp.fr/apps/gateway/gateway/pkg/brokers/brokerstest"
+	"code.waarp.fr/apps/gateway/gateway/pkg/database/dbtest"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils/testhelpers"
+)
+
+func TestExportBrokers(t *testing.T) {
+	t.Parallel()
+
+	logger := testhelpers.GetTestLogger(t)
+	brokerType, _ := brokerstest.MakeDummyBackend(t)
+	db := dbtest.TestDatabase(t)
+
+	dbBroker := model.MessageBroker{
+		Name:     "broker",
+		Type:     brokerType,
+		Address:  "localhost:4222",
+		Login:    "login",
+		Password: "password",
+		Options:  map[string]string{"foo": "bar"},
+	}
+	require.NoError(t, db.Insert(&dbBroker).Run())
+
+	res, err := exportBrokers(logger, db)
+	require.NoError(t, err)
+
+	assert.Equal(t, []file.Broker{{
+		Name:     dbBroker.Name,
+		Type:     dbBroker.Type,
+		Address:  dbBroker.Address,
+		Login:    dbBroker.Login,
+		Password: dbBroker.Password,
+		Options:  dbBroker.Options.Map(),
+	}}, res)
+}
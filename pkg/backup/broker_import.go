@@ -0,0 +1,58 @@
+package backup
+
+import (
+	"fmt"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/backup/file"
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+)
+
+func importBrokers(logger *log.Logger, db database.Access, brokers []file.Broker, reset bool,
+) error {
+	if reset {
+		if err := db.DeleteAll(&model.MessageBroker{}).Run(); err != nil {
+			return fmt.Errorf("failed to purge existing message brokers: %w", err)
+		}
+	}
+
+	for _, broker := range brokers {
+		exists := true
+
+		var dbBroker model.MessageBroker
+		if err := db.Get(&dbBroker, "name=?", broker.Name).Run(); database.IsNotFound(err) {
+			exists = false
+		} else if err != nil {
+			return fmt.Errorf("failed to retrieve message broker %q: %w", broker.Name, err)
+		}
+
+		dbBroker.Name = broker.Name
+		dbBroker.Type = broker.Type
+		dbBroker.Address = broker.Address
+		dbBroker.Login = broker.Login
+		dbBroker.Password = broker.Password
+		dbBroker.Options = broker.Options
+
+		var (
+			dbErr  error
+			logMsg string
+		)
+
+		if exists {
+			dbErr = db.Update(&dbBroker).Run()
+			logMsg = fmt.Sprintf("Updated existing message broker %q", broker.Name)
+		} else {
+			dbErr = db.Insert(&dbBroker).Run()
+			logMsg = fmt.Sprintf("Inserted new message broker %q", broker.Name)
+		}
+
+		if dbErr != nil {
+			return fmt.Errorf("failed to import message broker %q: %w", broker.Name, dbErr)
+		}
+
+		logger.Info(logMsg)
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/backup/file"
+	"code.waarp.fr/apps/gateway/gateway/pkg/brokers/brokerstest"
+	"code.waarp.fr/apps/gateway/gateway/pkg/database/dbtest"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils/testhelpers"
+)
+
+func TestImportBrokers(t *testing.T) {
+	t.Parallel()
+
+	logger := testhelpers.GetTestLogger(t)
+	brokerType, _ := brokerstest.MakeDummyBackend(t)
+
+	existing := &model.MessageBroker{
+		Name:    "existing",
+		Type:    brokerType,
+		Address: "localhost:1883",
+	}
+
+	newBroker := file.Broker{
+		Name:     "broker",
+		Type:     brokerType,
+		Address:  "localhost:4222",
+		Login:    "login",
+		Password: "password",
+		Options:  map[string]string{"foo": "bar"},
+	}
+
+	checkBroker := func(tb testing.TB, expected file.Broker, actual *model.MessageBroker) {
+		tb.Helper()
+
+		assert.Equal(tb, expected.Name, actual.Name)
+		assert.Equal(tb, expected.Type, actual.Type)
+		assert.Equal(tb, expected.Address, actual.Address)
+		assert.Equal(tb, expected.Login, actual.Login)
+		assert.Equal(tb, expected.Password, actual.Password)
+		assert.Equal(tb, expected.Options, actual.Options.Map())
+	}
+
+	t.Run("With reset", func(t *testing.T) {
+		db := dbtest.TestDatabase(t)
+		require.NoError(t, db.Insert(existing).Run())
+
+		require.NoError(t, importBrokers(logger, db, []file.Broker{newBroker}, true))
+
+		var dbBrokers model.MessageBrokers
+		require.NoError(t, db.Select(&dbBrokers).OrderBy("id", true).Run())
+		require.Len(t, dbBrokers, 1)
+
+		checkBroker(t, newBroker, dbBrokers[0])
+	})
+
+	t.Run("Without reset", func(t *testing.T) {
+		db := dbtest.TestDatabase(t)
+		require.NoError(t, db.Insert(existing).Run())
+
+		require.NoError(t, importBrokers(logger, db, []file.Broker{newBroker}, false))
+
+		var dbBrokers model.MessageBrokers
+		require.NoError(t, db.Select(&dbBrokers).OrderBy("id", true).Run())
+		require.Len(t, dbBrokers, 2)
+
+		assert.Equal(t, existing.Name, dbBrokers[0].Name)
+		checkBroker(t, newBroker, dbBrokers[1])
+	})
+
+	t.Run("Imported broker already exists", func(t *testing.T) {
+		db := dbtest.TestDatabase(t)
+		require.NoError(t, db.Insert(existing).Run())
+
+		updated := newBroker
+		updated.Name = existing.Name
+
+		require.NoError(t, importBrokers(logger, db, []file.Broker{updated}, false))
+
+		var dbBrokers model.MessageBrokers
+		require.NoError(t, db.Select(&dbBrokers).OrderBy("id", true).Run())
+		require.Len(t, dbBrokers, 1)
+
+		checkBroker(t, updated, dbBrokers[0])
+	})
+}
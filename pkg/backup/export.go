@@ -13,6 +13,7 @@ import (
 	"code.waarp.fr/apps/gateway/gateway/pkg/backup/file"
 	"code.waarp.fr/apps/gateway/gateway/pkg/database"
 	"code.waarp.fr/apps/gateway/gateway/pkg/logging"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
 	"code.waarp.fr/apps/gateway/gateway/pkg/utils"
 	"code.waarp.fr/apps/gateway/gateway/pkg/version"
 )
@@ -87,6 +88,34 @@ func ExportData(db database.ReadAccess, w *os.File, targets []string) error {
 		}
 	}
 
+	if utils.ContainsOneOf(targets, "brokers", "all") {
+		data.Brokers, expErr = exportBrokers(logger, db)
+		if expErr != nil {
+			return expErr
+		}
+	}
+
+	if utils.ContainsOneOf(targets, "scripts", "all") {
+		data.Scripts, expErr = exportStoredTexts[model.Script](logger, db)
+		if expErr != nil {
+			return expErr
+		}
+	}
+
+	if utils.ContainsOneOf(targets, "copybooks", "all") {
+		data.Copybooks, expErr = exportStoredTexts[model.Copybook](logger, db)
+		if expErr != nil {
+			return expErr
+		}
+	}
+
+	if utils.ContainsOneOf(targets, "retry_policies", "all") {
+		data.RetryPolicies, expErr = exportRetryPolicies(logger, db)
+		if expErr != nil {
+			return expErr
+		}
+	}
+
 	if utils.ContainsOneOf(targets, "snmp", "all") {
 		data.SNMPConfig, expErr = exportSNMPConfig(logger, db)
 		if expErr != nil {
@@ -16,11 +16,16 @@ type Data struct {
 	Rules        []Rule        `json:"rules,omitempty" yaml:"rules,omitempty"`
 	Users        []User        `json:"users,omitempty" yaml:"users,omitempty"`
 	Clouds       []Cloud       `json:"clouds,omitempty" yaml:"clouds,omitempty"`
+	Brokers      []Broker      `json:"brokers,omitempty" yaml:"brokers,omitempty"`
 	SNMPConfig   *SNMPConfig   `json:"snmpConfig,omitempty" yaml:"snmpConfig,omitempty"`
 	Authorities  []*Authority  `json:"authorities,omitempty" yaml:"authorities,omitempty"`
 	CryptoKeys   []*CryptoKey  `json:"cryptoKeys,omitempty" yaml:"cryptoKeys,omitempty"`
 	EmailConfig  *EmailConfig  `json:"emailConfig,omitempty" yaml:"emailConfig,omitempty"`
 	Filewatchers *FileWatchers `json:"filewatchers,omitempty" yaml:"filewatchers,omitempty"`
+	Scripts      []StoredText  `json:"scripts,omitempty" yaml:"scripts,omitempty"`
+	Copybooks    []StoredText  `json:"copybooks,omitempty" yaml:"copybooks,omitempty"`
+
+	RetryPolicies []RetryPolicy `json:"retryPolicies,omitempty" yaml:"retryPolicies,omitempty"`
 }
 
 // LocalAgent is the JSON struct representing a local server along with its
@@ -83,6 +88,7 @@ type RemoteAgent struct {
 	Configuration map[string]any  `json:"configuration" yaml:"configuration"`
 	Accounts      []RemoteAccount `json:"accounts" yaml:"accounts"`
 	Credentials   []Credential    `json:"credentials" yaml:"credentials"`
+	RetryPolicy   string          `json:"retryPolicy,omitempty" yaml:"retryPolicy,omitempty"`
 
 	// Deprecated fields.
 	Certificates []Certificate `json:"certificates,omitempty" yaml:"certificates,omitempty"` // Deprecated: use Credentials instead.
@@ -123,6 +129,7 @@ type Rule struct {
 	Pre            []Task   `json:"pre,omitempty" yaml:"pre,omitempty"`
 	Post           []Task   `json:"post,omitempty" yaml:"post,omitempty"`
 	Error          []Task   `json:"error,omitempty" yaml:"error,omitempty"`
+	RetryPolicy    string   `json:"retryPolicy,omitempty" yaml:"retryPolicy,omitempty"`
 
 	// Deprecated fields.
 	InPath   string `json:"inPath,omitempty" yaml:"inPath,omitempty"`     // Deprecated: replaced by LocalDir & RemoteDir
@@ -134,6 +141,14 @@ type Rule struct {
 type Task struct {
 	Type string            `json:"type" yaml:"type"`
 	Args map[string]string `json:"args" yaml:"args"`
+
+	Condition string `json:"condition,omitempty" yaml:"condition,omitempty"`
+	OnError   string `json:"onError,omitempty" yaml:"onError,omitempty"`
+
+	Retries       int8     `json:"retries,omitempty" yaml:"retries,omitempty"`
+	RetryDelay    Duration `json:"retryDelay,omitzero" yaml:"retryDelay,omitempty"`
+	Timeout       Duration `json:"timeout,omitzero" yaml:"timeout,omitempty"`
+	ParallelGroup string   `json:"parallelGroup,omitempty" yaml:"parallelGroup,omitempty"`
 }
 
 // User is the JSON struct representing a gateway user.
@@ -198,6 +213,36 @@ type Cloud struct {
 	Options map[string]string `json:"options" yaml:"options"`
 }
 
+type Broker struct {
+	Name     string            `json:"name" yaml:"name"`
+	Type     string            `json:"type" yaml:"type"`
+	Address  string            `json:"address" yaml:"address"`
+	Login    string            `json:"login,omitempty" yaml:"login,omitempty"`
+	Password string            `json:"password,omitempty" yaml:"password,omitempty"`
+	Options  map[string]string `json:"options,omitempty" yaml:"options,omitempty"`
+}
+
+// StoredText is the JSON struct representing a named text stored in the
+// database, such as a script or a copybook.
+type StoredText struct {
+	Name    string `json:"name" yaml:"name"`
+	Content string `json:"content" yaml:"content"`
+}
+
+// RetryPolicy is the JSON struct representing a transfer retry policy.
+//
+//nolint:lll //tags are long
+type RetryPolicy struct {
+	Name            string            `json:"name" yaml:"name"`
+	MaxAttempts     int8              `json:"maxAttempts,omitempty" yaml:"maxAttempts,omitempty"`
+	FirstDelay      Duration          `json:"firstDelay,omitzero" yaml:"firstDelay,omitempty"`
+	IncrementFactor float32           `json:"incrementFactor,omitempty" yaml:"incrementFactor,omitempty"`
+	MaxDelay        Duration          `json:"maxDelay,omitzero" yaml:"maxDelay,omitempty"`
+	Jitter          float32           `json:"jitter,omitempty" yaml:"jitter,omitempty"`
+	MaxWindow       Duration          `json:"maxWindow,omitzero" yaml:"maxWindow,omitempty"`
+	ErrorCodes      map[string]string `json:"errorCodes,omitempty" yaml:"errorCodes,omitempty"`
+}
+
 type SNMPConfig struct {
 	Server   *SNMPServer    `json:"server,omitempty" yaml:"server,omitempty"`
 	Monitors []*SNMPMonitor `json:"monitors,omitempty" yaml:"monitors,omitempty"`
@@ -236,6 +281,10 @@ type Authority struct {
 	Type           string   `json:"type" yaml:"type"`
 	PublicIdentity string   `json:"publicIdentity" yaml:"publicIdentity"`
 	ValidHosts     []string `json:"validHosts,omitempty" yaml:"validHosts,omitempty"`
+
+	RevocationPolicy string `json:"revocationPolicy,omitempty" yaml:"revocationPolicy,omitempty"`
+	CRLURL           string `json:"crlURL,omitempty" yaml:"crlURL,omitempty"`
+	OCSPURL          string `json:"ocspURL,omitempty" yaml:"ocspURL,omitempty"`
 }
 
 type CryptoKey struct {
@@ -73,6 +73,18 @@ func Import(db database.Access, logger *log.Logger, data *file.Data, targets []s
 			}
 		}
 
+		if utils.ContainsOneOf(targets, "brokers", "all") {
+			if err := importBrokers(logger, ses, data.Brokers, reset); err != nil {
+				return err
+			}
+		}
+
+		if utils.ContainsOneOf(targets, "retry_policies", "all") {
+			if err := importRetryPolicies(logger, ses, data.RetryPolicies, reset); err != nil {
+				return err
+			}
+		}
+
 		if utils.ContainsOneOf(targets, "clients", "all") {
 			if err := importClients(logger, ses, data.Clients, reset); err != nil {
 				return err
@@ -103,6 +115,18 @@ func Import(db database.Access, logger *log.Logger, data *file.Data, targets []s
 			}
 		}
 
+		if utils.ContainsOneOf(targets, "scripts", "all") {
+			if err := importStoredTexts[model.Script](logger, ses, data.Scripts, reset); err != nil {
+				return err
+			}
+		}
+
+		if utils.ContainsOneOf(targets, "copybooks", "all") {
+			if err := importStoredTexts[model.Copybook](logger, ses, data.Copybooks, reset); err != nil {
+				return err
+			}
+		}
+
 		if utils.ContainsOneOf(targets, "rules", "all") {
 			if err := importRules(logger, ses, data.Rules, reset); err != nil {
 				return err
@@ -38,6 +38,7 @@ func exportRemotes(logger *log.Logger, db database.ReadAccess) ([]file.RemoteAge
 			Configuration: src.ProtoConfig,
 			Accounts:      accounts,
 			Credentials:   credentials,
+			RetryPolicy:   src.RetryPolicy,
 			Certificates:  certs,
 		}
 
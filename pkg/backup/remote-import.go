@@ -45,6 +45,7 @@ func importRemoteAgents(logger *log.Logger, db database.Access,
 		agent.Name = src.Name
 		agent.Protocol = src.Protocol
 		agent.ProtoConfig = src.Configuration
+		agent.RetryPolicy = src.RetryPolicy
 
 		if err := agent.Address.Set(src.Address); err != nil {
 			return database.NewValidationError(err.Error())
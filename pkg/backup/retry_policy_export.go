@@ -0,0 +1,44 @@
+package backup
+
+import (
+	"fmt"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/backup/file"
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+)
+
+func exportRetryPolicies(logger *log.Logger, db database.ReadAccess) ([]file.RetryPolicy, error) {
+	var dbPolicies model.RetryPolicies
+	if err := db.Select(&dbPolicies).Run(); err != nil {
+		return nil, fmt.Errorf("failed to retrieve retry policies: %w", err)
+	}
+
+	policies := make([]file.RetryPolicy, len(dbPolicies))
+
+	for i, dbPolicy := range dbPolicies {
+		var errorCodes map[string]string
+		if len(dbPolicy.ErrorCodes) != 0 {
+			errorCodes = make(map[string]string, len(dbPolicy.ErrorCodes))
+			for code, action := range dbPolicy.ErrorCodes {
+				errorCodes[code] = string(action)
+			}
+		}
+
+		policies[i] = file.RetryPolicy{
+			Name:            dbPolicy.Name,
+			MaxAttempts:     dbPolicy.MaxAttempts,
+			FirstDelay:      file.Duration(dbPolicy.FirstDelay),
+			IncrementFactor: dbPolicy.IncrementFactor,
+			MaxDelay:        file.Duration(dbPolicy.MaxDelay),
+			Jitter:          dbPolicy.Jitter,
+			MaxWindow:       file.Duration(dbPolicy.MaxWindow),
+			ErrorCodes:      errorCodes,
+		}
+
+		logger.Infof("Exported retry policy %q", dbPolicy.Name)
+	}
+
+	return policies, nil
+}
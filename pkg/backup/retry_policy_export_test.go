@@ -0,0 +1,53 @@
+package backup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/backup/file"
+	"code.waarp.fr/apps/gateway/gateway/pkg/database/dbtest"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils/testhelpers"
+)
+
+func TestExportRetryPolicies(t *testing.T) {
+	t.Parallel()
+
+	logger := testhelpers.GetTestLogger(t)
+	db := dbtest.TestDatabase(t)
+
+	dbPolicy := model.RetryPolicy{
+		Name:            "policy",
+		MaxAttempts:     5,
+		FirstDelay:      30 * time.Second,
+		IncrementFactor: 2,
+		MaxDelay:        time.Hour,
+		Jitter:          0.5,
+		MaxWindow:       24 * time.Hour,
+		ErrorCodes: model.Map[model.RetryAction]{
+			"TeConnection": model.RetryActionAlways,
+			"TeForbidden":  model.RetryActionNever,
+		},
+	}
+	require.NoError(t, db.Insert(&dbPolicy).Run())
+
+	res, err := exportRetryPolicies(logger, db)
+	require.NoError(t, err)
+
+	assert.Equal(t, []file.RetryPolicy{{
+		Name:            dbPolicy.Name,
+		MaxAttempts:     dbPolicy.MaxAttempts,
+		FirstDelay:      file.Duration(dbPolicy.FirstDelay),
+		IncrementFactor: dbPolicy.IncrementFactor,
+		MaxDelay:        file.Duration(dbPolicy.MaxDelay),
+		Jitter:          dbPolicy.Jitter,
+		MaxWindow:       file.Duration(dbPolicy.MaxWindow),
+		ErrorCodes: map[string]string{
+			"TeConnection": "always",
+			"TeForbidden":  "never",
+		},
+	}}, res)
+}
@@ -0,0 +1,66 @@
+package backup
+
+import (
+	"fmt"
+	"time"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/backup/file"
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+)
+
+func importRetryPolicies(logger *log.Logger, db database.Access, policies []file.RetryPolicy,
+	reset bool,
+) error {
+	if reset {
+		if err := db.DeleteAll(&model.RetryPolicy{}).Run(); err != nil {
+			return fmt.Errorf("failed to purge existing retry policies: %w", err)
+		}
+	}
+
+	for _, policy := range policies {
+		exists := true
+
+		var dbPolicy model.RetryPolicy
+		if err := db.Get(&dbPolicy, "name=?", policy.Name).Run(); database.IsNotFound(err) {
+			exists = false
+		} else if err != nil {
+			return fmt.Errorf("failed to retrieve retry policy %q: %w", policy.Name, err)
+		}
+
+		dbPolicy.Name = policy.Name
+		dbPolicy.MaxAttempts = policy.MaxAttempts
+		dbPolicy.FirstDelay = time.Duration(policy.FirstDelay)
+		dbPolicy.IncrementFactor = policy.IncrementFactor
+		dbPolicy.MaxDelay = time.Duration(policy.MaxDelay)
+		dbPolicy.Jitter = policy.Jitter
+		dbPolicy.MaxWindow = time.Duration(policy.MaxWindow)
+		dbPolicy.ErrorCodes = make(model.Map[model.RetryAction], len(policy.ErrorCodes))
+
+		for code, action := range policy.ErrorCodes {
+			dbPolicy.ErrorCodes[code] = model.RetryAction(action)
+		}
+
+		var (
+			dbErr  error
+			logMsg string
+		)
+
+		if exists {
+			dbErr = db.Update(&dbPolicy).Run()
+			logMsg = fmt.Sprintf("Updated existing retry policy %q", policy.Name)
+		} else {
+			dbErr = db.Insert(&dbPolicy).Run()
+			logMsg = fmt.Sprintf("Inserted new retry policy %q", policy.Name)
+		}
+
+		if dbErr != nil {
+			return fmt.Errorf("failed to import retry policy %q: %w", policy.Name, dbErr)
+		}
+
+		logger.Info(logMsg)
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package backup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/backup/file"
+	"code.waarp.fr/apps/gateway/gateway/pkg/database/dbtest"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils/testhelpers"
+)
+
+func TestImportRetryPolicies(t *testing.T) {
+	t.Parallel()
+
+	logger := testhelpers.GetTestLogger(t)
+
+	existing := &model.RetryPolicy{Name: "existing", MaxAttempts: 3}
+	newPolicy := file.RetryPolicy{
+		Name:            "policy",
+		MaxAttempts:     5,
+		FirstDelay:      file.Duration(30 * time.Second),
+		IncrementFactor: 2,
+		MaxDelay:        file.Duration(time.Hour),
+		Jitter:          0.5,
+		MaxWindow:       file.Duration(24 * time.Hour),
+		ErrorCodes:      map[string]string{"TeForbidden": "never"},
+	}
+
+	t.Run("With reset", func(t *testing.T) {
+		db := dbtest.TestDatabase(t)
+		require.NoError(t, db.Insert(existing).Run())
+
+		require.NoError(t, importRetryPolicies(logger, db, []file.RetryPolicy{newPolicy}, true))
+
+		var dbPolicies model.RetryPolicies
+		require.NoError(t, db.Select(&dbPolicies).OrderBy("id", true).Run())
+		require.Len(t, dbPolicies, 1)
+
+		assert.Equal(t, newPolicy.Name, dbPolicies[0].Name)
+		assert.Equal(t, newPolicy.MaxAttempts, dbPolicies[0].MaxAttempts)
+		assert.Equal(t, time.Duration(newPolicy.FirstDelay), dbPolicies[0].FirstDelay)
+		assert.Equal(t, newPolicy.IncrementFactor, dbPolicies[0].IncrementFactor)
+		assert.Equal(t, time.Duration(newPolicy.MaxDelay), dbPolicies[0].MaxDelay)
+		assert.Equal(t, newPolicy.Jitter, dbPolicies[0].Jitter)
+		assert.Equal(t, time.Duration(newPolicy.MaxWindow), dbPolicies[0].MaxWindow)
+		assert.Equal(t, model.Map[model.RetryAction]{"TeForbidden": model.RetryActionNever},
+			dbPolicies[0].ErrorCodes)
+	})
+
+	t.Run("Without reset", func(t *testing.T) {
+		db := dbtest.TestDatabase(t)
+		require.NoError(t, db.Insert(existing).Run())
+
+		require.NoError(t, importRetryPolicies(logger, db, []file.RetryPolicy{newPolicy}, false))
+
+		var dbPolicies model.RetryPolicies
+		require.NoError(t, db.Select(&dbPolicies).OrderBy("id", true).Run())
+		require.Len(t, dbPolicies, 2)
+
+		assert.Equal(t, existing.Name, dbPolicies[0].Name)
+		assert.Equal(t, newPolicy.Name, dbPolicies[1].Name)
+	})
+
+	t.Run("Imported policy already exists", func(t *testing.T) {
+		db := dbtest.TestDatabase(t)
+		require.NoError(t, db.Insert(existing).Run())
+
+		updated := newPolicy
+		updated.Name = existing.Name
+
+		require.NoError(t, importRetryPolicies(logger, db, []file.RetryPolicy{updated}, false))
+
+		var dbPolicies model.RetryPolicies
+		require.NoError(t, db.Select(&dbPolicies).OrderBy("id", true).Run())
+		require.Len(t, dbPolicies, 1)
+
+		assert.Equal(t, updated.MaxAttempts, dbPolicies[0].MaxAttempts)
+	})
+
+	t.Run("Invalid policy", func(t *testing.T) {
+		db := dbtest.TestDatabase(t)
+
+		require.ErrorIs(t, importRetryPolicies(logger, db, []file.RetryPolicy{
+			{Name: "invalid", ErrorCodes: map[string]string{"TeUnknownCode": "never"}},
+		}, false), model.ErrRetryPolicyInvalid)
+	})
+}
@@ -52,6 +52,7 @@ func exportRules(logger *log.Logger, db database.ReadAccess) ([]file.Rule, error
 			Pre:            pre,
 			Post:           post,
 			Error:          errT,
+			RetryPolicy:    src.RetryPolicy,
 		}
 	}
 
@@ -130,8 +131,14 @@ func exportRuleTasks(db database.ReadAccess, ruleID int64, chain string) ([]file
 
 	for i, src := range dbTasks {
 		res[i] = file.Task{
-			Type: src.Type,
-			Args: src.Args,
+			Type:          src.Type,
+			Args:          src.Args,
+			Condition:     src.Condition,
+			OnError:       string(src.OnError),
+			Retries:       src.Retries,
+			RetryDelay:    file.Duration(src.RetryDelay),
+			Timeout:       file.Duration(src.Timeout),
+			ParallelGroup: src.ParallelGroup,
 		}
 	}
 
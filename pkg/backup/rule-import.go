@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"code.waarp.fr/apps/gateway/gateway/pkg/backup/file"
 	"code.waarp.fr/apps/gateway/gateway/pkg/database"
@@ -63,6 +64,7 @@ func importRules(logger *log.Logger, db database.Access, list []file.Rule,
 		rule.LocalDir = src.LocalDir
 		rule.RemoteDir = src.RemoteDir
 		rule.TmpLocalRcvDir = src.TmpLocalRcvDir
+		rule.RetryPolicy = src.RetryPolicy
 
 		importRuleCheckDeprecated(logger, src, &rule)
 
@@ -247,6 +249,12 @@ func importRuleTasks(logger *log.Logger, db database.Access, list []file.Task,
 		task.Rank = int8(i)
 		task.Type = src.Type
 		task.Args = src.Args
+		task.Condition = src.Condition
+		task.OnError = model.OnError(src.OnError)
+		task.Retries = src.Retries
+		task.RetryDelay = time.Duration(src.RetryDelay)
+		task.Timeout = time.Duration(src.Timeout)
+		task.ParallelGroup = src.ParallelGroup
 
 		// Create/Update
 		logger.Infof("Create task type %s at chain %s rank %d", task.Type, chain, i)
@@ -0,0 +1,82 @@
+package backup
+
+import (
+	"fmt"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/backup/file"
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+)
+
+// exportStoredTexts exports the stored texts (scripts or copybooks) of the
+// given model.
+func exportStoredTexts[T any, PT model.StoredText[T]](logger *log.Logger, db database.ReadAccess,
+) ([]file.StoredText, error) {
+	kind := PT(new(T)).Appellation()
+
+	var dbTexts model.Slice[PT]
+	if err := db.Select(&dbTexts).Run(); err != nil {
+		return nil, fmt.Errorf("failed to retrieve %ss: %w", kind, err)
+	}
+
+	texts := make([]file.StoredText, len(dbTexts))
+
+	for i, dbText := range dbTexts {
+		name, content := dbText.TextFields()
+		texts[i] = file.StoredText{Name: *name, Content: *content}
+
+		logger.Infof("Exported %s %q", kind, *name)
+	}
+
+	return texts, nil
+}
+
+// importStoredTexts imports the given stored texts (scripts or copybooks) as
+// entries of the given model. Existing entries with the same name are updated.
+func importStoredTexts[T any, PT model.StoredText[T]](logger *log.Logger, db database.Access,
+	texts []file.StoredText, reset bool,
+) error {
+	kind := PT(new(T)).Appellation()
+
+	if reset {
+		if err := db.DeleteAll(PT(new(T))).Run(); err != nil {
+			return fmt.Errorf("failed to purge existing %ss: %w", kind, err)
+		}
+	}
+
+	for _, text := range texts {
+		exists := true
+
+		dbText := PT(new(T))
+		if err := db.Get(dbText, "name=?", text.Name).Run(); database.IsNotFound(err) {
+			exists = false
+		} else if err != nil {
+			return fmt.Errorf("failed to retrieve %s %q: %w", kind, text.Name, err)
+		}
+
+		name, content := dbText.TextFields()
+		*name, *content = text.Name, text.Content
+
+		var (
+			dbErr  error
+			logMsg string
+		)
+
+		if exists {
+			dbErr = db.Update(dbText).Run()
+			logMsg = fmt.Sprintf("Updated existing %s %q", kind, text.Name)
+		} else {
+			dbErr = db.Insert(dbText).Run()
+			logMsg = fmt.Sprintf("Inserted new %s %q", kind, text.Name)
+		}
+
+		if dbErr != nil {
+			return fmt.Errorf("failed to import %s %q: %w", kind, text.Name, dbErr)
+		}
+
+		logger.Info(logMsg)
+	}
+
+	return nil
+}
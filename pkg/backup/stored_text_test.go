@@ -0,0 +1,128 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/backup/file"
+	"code.waarp.fr/apps/gateway/gateway/pkg/database/dbtest"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils/testhelpers"
+)
+
+func TestExportStoredTexts(t *testing.T) {
+	t.Parallel()
+
+	logger := testhelpers.GetTestLogger(t)
+	db := dbtest.TestDatabase(t)
+
+	dbScript := model.Script{Name: "script", Content: `print("hello")`}
+	require.NoError(t, db.Insert(&dbScript).Run())
+
+	dbCopybook := model.Copybook{Name: "copybook", Content: `01 REC. 05 CODE PIC 9(5).`}
+	require.NoError(t, db.Insert(&dbCopybook).Run())
+
+	scripts, err := exportStoredTexts[model.Script](logger, db)
+	require.NoError(t, err)
+
+	assert.Equal(t, []file.StoredText{{
+		Name:    dbScript.Name,
+		Content: dbScript.Content,
+	}}, scripts)
+
+	copybooks, err := exportStoredTexts[model.Copybook](logger, db)
+	require.NoError(t, err)
+
+	assert.Equal(t, []file.StoredText{{
+		Name:    dbCopybook.Name,
+		Content: dbCopybook.Content,
+	}}, copybooks)
+}
+
+func TestImportStoredTexts(t *testing.T) {
+	t.Parallel()
+
+	logger := testhelpers.GetTestLogger(t)
+
+	existing := &model.Script{Name: "existing", Content: `print("existing")`}
+	newScript := file.StoredText{Name: "script", Content: `print("hello")`}
+
+	t.Run("With reset", func(t *testing.T) {
+		db := dbtest.TestDatabase(t)
+		require.NoError(t, db.Insert(existing).Run())
+
+		require.NoError(t, importStoredTexts[model.Script](logger, db, []file.StoredText{newScript}, true))
+
+		var dbScripts model.Scripts
+		require.NoError(t, db.Select(&dbScripts).OrderBy("id", true).Run())
+		require.Len(t, dbScripts, 1)
+
+		assert.Equal(t, newScript.Name, dbScripts[0].Name)
+		assert.Equal(t, newScript.Content, dbScripts[0].Content)
+	})
+
+	t.Run("Without reset", func(t *testing.T) {
+		db := dbtest.TestDatabase(t)
+		require.NoError(t, db.Insert(existing).Run())
+
+		require.NoError(t, importStoredTexts[model.Script](logger, db, []file.StoredText{newScript}, false))
+
+		var dbScripts model.Scripts
+		require.NoError(t, db.Select(&dbScripts).OrderBy("id", true).Run())
+		require.Len(t, dbScripts, 2)
+
+		assert.Equal(t, existing.Name, dbScripts[0].Name)
+		assert.Equal(t, newScript.Name, dbScripts[1].Name)
+		assert.Equal(t, newScript.Content, dbScripts[1].Content)
+	})
+
+	t.Run("Imported script already exists", func(t *testing.T) {
+		db := dbtest.TestDatabase(t)
+		require.NoError(t, db.Insert(existing).Run())
+
+		updated := newScript
+		updated.Name = existing.Name
+
+		require.NoError(t, importStoredTexts[model.Script](logger, db, []file.StoredText{updated}, false))
+
+		var dbScripts model.Scripts
+		require.NoError(t, db.Select(&dbScripts).OrderBy("id", true).Run())
+		require.Len(t, dbScripts, 1)
+
+		assert.Equal(t, updated.Content, dbScripts[0].Content)
+	})
+
+	t.Run("Invalid script", func(t *testing.T) {
+		db := dbtest.TestDatabase(t)
+
+		require.ErrorIs(t, importStoredTexts[model.Script](logger, db, []file.StoredText{
+			{Name: "invalid", Content: `print("hello"`},
+		}, false), model.ErrScriptInvalid)
+	})
+
+	t.Run("Copybooks", func(t *testing.T) {
+		db := dbtest.TestDatabase(t)
+		copybook := file.StoredText{Name: "copybook", Content: `01 REC. 05 NAME PIC X(10).`}
+
+		require.NoError(t, importStoredTexts[model.Copybook](logger, db,
+			[]file.StoredText{copybook}, false))
+
+		var dbCopybook model.Copybook
+		require.NoError(t, db.Get(&dbCopybook, "name=?", copybook.Name).Run())
+		assert.Equal(t, copybook.Content, dbCopybook.Content)
+
+		var dbScripts model.Scripts
+		require.NoError(t, db.Select(&dbScripts).Run())
+		assert.Empty(t, dbScripts, "the copybook should not be imported as a script")
+	})
+
+	t.Run("Invalid copybook", func(t *testing.T) {
+		db := dbtest.TestDatabase(t)
+
+		require.ErrorIs(t, importStoredTexts[model.Copybook](logger, db, []file.StoredText{
+			{Name: "invalid", Content: `01 REC. 05 NAME PIC X(0).`},
+		}, false), model.ErrCopybookInvalid)
+	})
+}
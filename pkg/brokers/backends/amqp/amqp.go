@@ -0,0 +1,233 @@
+// Package amqp provides a publish-only AMQP 0-9-1 client (as used by RabbitMQ
+// & co), based on the RabbitMQ AMQP client.
+package amqp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/brokers"
+	"code.waarp.fr/apps/gateway/gateway/pkg/version"
+)
+
+const (
+	DefaultPort    = "5672"
+	DefaultTLSPort = "5671"
+
+	// OptVHost is the option specifying the virtual host to connect to
+	// (defaults to "/").
+	OptVHost = "vhost"
+	// OptExchange is the option specifying the exchange to which the messages
+	// are published (defaults to the default exchange).
+	OptExchange = "exchange"
+	// OptConfirm is the option specifying whether the publication should be
+	// confirmed by the broker (defaults to true).
+	OptConfirm = "confirm"
+	// OptMandatory is the option specifying whether the publication should
+	// fail if the message cannot be routed to any queue (defaults to false).
+	OptMandatory = "mandatory"
+	// OptPersistent is the option specifying whether the messages should be
+	// persisted by the broker (defaults to true).
+	OptPersistent = "persistent"
+
+	defaultVHost = "/"
+	appID        = "waarp-gateway"
+	maxShortStr  = 255
+)
+
+var (
+	ErrChannelClosed   = errors.New("the channel was closed by the AMQP server")
+	ErrMessageReturned = errors.New("the message was returned by the AMQP server")
+	ErrMessageNacked   = errors.New("the message was rejected by the AMQP server")
+	ErrStringTooLong   = errors.New("the AMQP short string is too long")
+)
+
+type Backend struct{}
+
+func (Backend) Options() []string {
+	return []string{OptVHost, OptExchange, OptConfirm, OptMandatory, OptPersistent}
+}
+
+func (Backend) ValidateConfig(conf *brokers.Config) error {
+	for _, opt := range []string{OptConfirm, OptMandatory, OptPersistent} {
+		if val, ok := conf.Options[opt]; ok {
+			if _, err := strconv.ParseBool(val); err != nil {
+				return fmt.Errorf("%w %q: %q is not a boolean", brokers.ErrInvalidOption, opt, val)
+			}
+		}
+	}
+
+	for _, opt := range []string{OptVHost, OptExchange} {
+		if len(conf.Options[opt]) > maxShortStr {
+			return fmt.Errorf("%w %q: %w", brokers.ErrInvalidOption, opt, ErrStringTooLong)
+		}
+	}
+
+	return nil
+}
+
+func boolOption(opts map[string]string, key string, def bool) bool {
+	if b, err := strconv.ParseBool(opts[key]); err == nil {
+		return b
+	}
+
+	return def
+}
+
+type Client struct {
+	conn    *amqp.Connection
+	channel *amqp.Channel
+	closes  chan *amqp.Error
+	returns chan amqp.Return
+
+	exchange   string
+	confirm    bool
+	mandatory  bool
+	persistent bool
+}
+
+func (Backend) Dial(ctx context.Context, conf *brokers.Config) (brokers.Client, error) {
+	scheme, defaultPort := "amqp://", DefaultPort
+	if conf.UseTLS() {
+		scheme, defaultPort = "amqps://", DefaultTLSPort
+	}
+
+	vhost := conf.Options[OptVHost]
+	if vhost == "" {
+		vhost = defaultVHost
+	}
+
+	props := amqp.NewConnectionProperties()
+	props.SetClientConnectionName(appID)
+	props["product"] = "Waarp Gateway"
+	props["version"] = version.Num
+
+	var errs []error
+
+	for _, addr := range brokers.Addresses(conf.Address, defaultPort) {
+		conn, err := amqp.DialConfig(scheme+addr+"/", amqp.Config{
+			SASL:            []amqp.Authentication{&amqp.PlainAuth{Username: conf.Login, Password: conf.Password}},
+			Vhost:           vhost,
+			TLSClientConfig: conf.TLS(addr),
+			Properties:      props,
+			Dial: func(_, dialAddr string) (net.Conn, error) {
+				return brokers.DialTCP(ctx, dialAddr)
+			},
+		})
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		client := &Client{
+			conn:       conn,
+			exchange:   conf.Options[OptExchange],
+			confirm:    boolOption(conf.Options, OptConfirm, true),
+			mandatory:  boolOption(conf.Options, OptMandatory, false),
+			persistent: boolOption(conf.Options, OptPersistent, true),
+		}
+
+		if err := client.openChannel(); err != nil {
+			_ = conn.Close() //nolint:errcheck //error is irrelevant at this point
+
+			return nil, err
+		}
+
+		return client, nil
+	}
+
+	return nil, errors.Join(errs...)
+}
+
+func (c *Client) openChannel() error {
+	var err error
+	if c.channel, err = c.conn.Channel(); err != nil {
+		return fmt.Errorf("failed to open the AMQP channel: %w", err)
+	}
+
+	c.closes = c.channel.NotifyClose(make(chan *amqp.Error, 1))
+	c.returns = c.channel.NotifyReturn(make(chan amqp.Return, 1))
+
+	if !c.confirm {
+		return nil
+	}
+
+	if err := c.channel.Confirm(false); err != nil {
+		return fmt.Errorf("failed to enable the AMQP publisher confirms: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Client) Publish(ctx context.Context, msg *brokers.Message) error {
+	if len(msg.Topic) > maxShortStr || len(msg.Key) > maxShortStr ||
+		len(msg.ContentType) > maxShortStr {
+		return ErrStringTooLong
+	}
+
+	deliveryMode := amqp.Transient
+	if c.persistent {
+		deliveryMode = amqp.Persistent
+	}
+
+	confirm, err := c.channel.PublishWithDeferredConfirmWithContext(ctx, c.exchange, msg.Topic,
+		c.mandatory, false, amqp.Publishing{
+			ContentType:  msg.ContentType,
+			DeliveryMode: deliveryMode,
+			MessageId:    msg.Key,
+			Timestamp:    time.Now(),
+			AppId:        appID,
+			Body:         msg.Payload,
+		})
+	if err != nil {
+		return c.channelError(err)
+	}
+
+	if !c.confirm {
+		return nil
+	}
+
+	acked, err := confirm.WaitContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to wait for the AMQP publisher confirm: %w", err)
+	}
+
+	// When the channel is closed, the pending confirms are released without
+	// being acknowledged, and the reason is sent on the close notifications.
+	if !acked {
+		return c.channelError(ErrMessageNacked)
+	}
+
+	// The server always sends the returned messages before their confirm.
+	select {
+	case ret := <-c.returns:
+		return fmt.Errorf("%w: %d %s", ErrMessageReturned, ret.ReplyCode, ret.ReplyText)
+	default:
+		return nil
+	}
+}
+
+// channelError returns the reason why the channel was closed (if it was),
+// or the given error otherwise.
+func (c *Client) channelError(err error) error {
+	select {
+	case closeErr, ok := <-c.closes:
+		if ok && closeErr != nil {
+			return fmt.Errorf("%w: %d %s", ErrChannelClosed, closeErr.Code, closeErr.Reason)
+		}
+	default:
+	}
+
+	return err
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close() //nolint:wrapcheck //wrapping adds nothing here
+}
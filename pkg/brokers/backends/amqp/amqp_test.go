@@ -0,0 +1,350 @@
+package amqp
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/brokers"
+)
+
+const (
+	frameMethod    = 1
+	frameHeader    = 2
+	frameBody      = 3
+	frameHeartbeat = 8
+	frameEnd       = 0xCE
+	frameOverhead  = 8 // type (1) + channel (2) + size (4) + end (1)
+	testFrameMax   = 4096
+
+	channelID = 1
+)
+
+// Basic content properties flags.
+const (
+	propContentType  = 1 << 15
+	propDeliveryMode = 1 << 12
+	propMessageID    = 1 << 7
+	propTimestamp    = 1 << 6
+	propAppID        = 1 << 3
+)
+
+type method struct{ class, id uint16 }
+
+//nolint:gochecknoglobals //global vars are needed here for the methods
+var (
+	methodConnStart       = method{10, 10}
+	methodConnStartOk     = method{10, 11}
+	methodConnTune        = method{10, 30}
+	methodConnTuneOk      = method{10, 31}
+	methodConnOpen        = method{10, 40}
+	methodConnOpenOk      = method{10, 41}
+	methodConnClose       = method{10, 50}
+	methodConnCloseOk     = method{10, 51}
+	methodChanOpen        = method{20, 10}
+	methodChanOpenOk      = method{20, 11}
+	methodChanClose       = method{20, 40}
+	methodConfirmSelect   = method{85, 10}
+	methodConfirmSelectOk = method{85, 11}
+	methodBasicPublish    = method{60, 40}
+	methodBasicAck        = method{60, 80}
+)
+
+type encoder struct{ buf []byte }
+
+func (e *encoder) octet(b byte)      { e.buf = append(e.buf, b) }
+func (e *encoder) short(v uint16)    { e.buf = binary.BigEndian.AppendUint16(e.buf, v) }
+func (e *encoder) long(v uint32)     { e.buf = binary.BigEndian.AppendUint32(e.buf, v) }
+func (e *encoder) longLong(v uint64) { e.buf = binary.BigEndian.AppendUint64(e.buf, v) }
+
+func (e *encoder) shortStr(str string) {
+	e.octet(byte(len(str)))
+	e.buf = append(e.buf, str...)
+}
+
+func (e *encoder) longStr(str string) {
+	e.long(uint32(len(str))) //nolint:gosec //test values are small
+	e.buf = append(e.buf, str...)
+}
+
+type decoder struct{ buf []byte }
+
+func (d *decoder) next(n int) []byte {
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+
+	return b
+}
+
+func (d *decoder) octet() byte      { return d.next(1)[0] }
+func (d *decoder) short() uint16    { return binary.BigEndian.Uint16(d.next(2)) } //nolint:mnd //short size
+func (d *decoder) long() uint32     { return binary.BigEndian.Uint32(d.next(4)) } //nolint:mnd //long size
+func (d *decoder) shortStr() string { return string(d.next(int(d.octet()))) }
+func (d *decoder) longStr() string  { return string(d.next(int(d.long()))) }
+
+// fakeConn is the server side of an AMQP connection.
+type fakeConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func (f *fakeConn) writeFrame(typ byte, channel uint16, payload []byte) error {
+	frame := []byte{typ}
+	frame = binary.BigEndian.AppendUint16(frame, channel)
+	frame = binary.BigEndian.AppendUint32(frame, uint32(len(payload))) //nolint:gosec //test values are small
+	frame = append(frame, payload...)
+	frame = append(frame, frameEnd)
+
+	_, err := f.conn.Write(frame)
+
+	return err //nolint:wrapcheck //test helper
+}
+
+func (f *fakeConn) writeMethod(channel uint16, meth method, args []byte) error {
+	payload := binary.BigEndian.AppendUint16(nil, meth.class)
+	payload = binary.BigEndian.AppendUint16(payload, meth.id)
+
+	return f.writeFrame(frameMethod, channel, append(payload, args...))
+}
+
+func (f *fakeConn) readFrame() (typ byte, payload []byte, err error) {
+	for {
+		var header [7]byte
+		if _, err = io.ReadFull(f.reader, header[:]); err != nil {
+			return 0, nil, err //nolint:wrapcheck //test helper
+		}
+
+		payload = make([]byte, binary.BigEndian.Uint32(header[3:])+1)
+		if _, err = io.ReadFull(f.reader, payload); err != nil {
+			return 0, nil, err //nolint:wrapcheck //test helper
+		}
+
+		if header[0] != frameHeartbeat {
+			return header[0], payload[:len(payload)-1], nil
+		}
+	}
+}
+
+func (f *fakeConn) expectMethod(expected method) ([]byte, error) {
+	typ, payload, err := f.readFrame()
+	if err != nil {
+		return nil, err
+	}
+
+	if meth := (method{
+		binary.BigEndian.Uint16(payload), binary.BigEndian.Uint16(payload[2:]),
+	}); typ != frameMethod || meth != expected {
+		return nil, fmt.Errorf("expected method %v, got frame %d with method %v", //nolint:err113 //test
+			expected, typ, meth)
+	}
+
+	return payload[4:], nil
+}
+
+type published struct {
+	exchange, routingKey string
+	flags                uint16
+	contentType          string
+	body                 string
+}
+
+// fakeServer starts a minimal AMQP server which accepts a single connection,
+// and sends the received message on the returned channel. If closeCode is not
+// zero, the channel is closed with that code instead of acknowledging the
+// message.
+func fakeServer(t *testing.T, closeCode uint16) (string, <-chan string, <-chan published) {
+	t.Helper()
+
+	list, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { list.Close() })
+
+	auths := make(chan string, 1)
+	msgs := make(chan published, 1)
+
+	go func() {
+		conn, accErr := list.Accept()
+		if !assert.NoError(t, accErr) {
+			return
+		}
+		defer conn.Close()
+
+		srv := &fakeConn{conn: conn, reader: bufio.NewReader(conn)}
+		runFakeServer(t, srv, closeCode, auths, msgs)
+	}()
+
+	return list.Addr().String(), auths, msgs
+}
+
+//nolint:funlen //test server, splitting would add complexity
+func runFakeServer(t *testing.T, srv *fakeConn, closeCode uint16, auths chan<- string,
+	msgs chan<- published,
+) {
+	t.Helper()
+
+	header := make([]byte, 8) //nolint:mnd //protocol header size
+	if _, err := io.ReadFull(srv.reader, header); !assert.NoError(t, err) ||
+		!assert.Equal(t, []byte{'A', 'M', 'Q', 'P', 0, 0, 9, 1}, header) {
+		return
+	}
+
+	var start encoder
+	start.octet(0)
+	start.octet(9) //nolint:mnd //AMQP 0-9
+	start.long(0)  // server properties
+	start.longStr("AMQPLAIN PLAIN")
+	start.longStr("en_US")
+
+	require.NoError(t, srv.writeMethod(0, methodConnStart, start.buf))
+
+	startOk, err := srv.expectMethod(methodConnStartOk)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	dec := decoder{buf: startOk}
+	dec.next(int(dec.long())) // client properties
+	assert.Equal(t, "PLAIN", dec.shortStr())
+	auths <- dec.longStr()
+
+	var tune encoder
+	tune.short(0)
+	tune.long(testFrameMax) // small frame size to test body splitting
+	tune.short(0)
+
+	require.NoError(t, srv.writeMethod(0, methodConnTune, tune.buf))
+
+	if _, err = srv.expectMethod(methodConnTuneOk); !assert.NoError(t, err) {
+		return
+	}
+
+	open, err := srv.expectMethod(methodConnOpen)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	dec = decoder{buf: open}
+	assert.Equal(t, "/test", dec.shortStr())
+	require.NoError(t, srv.writeMethod(0, methodConnOpenOk, []byte{0}))
+
+	_, err = srv.expectMethod(methodChanOpen)
+	require.NoError(t, err)
+	require.NoError(t, srv.writeMethod(channelID, methodChanOpenOk, []byte{0, 0, 0, 0}))
+
+	_, err = srv.expectMethod(methodConfirmSelect)
+	require.NoError(t, err)
+	require.NoError(t, srv.writeMethod(channelID, methodConfirmSelectOk, nil))
+
+	pub, err := srv.expectMethod(methodBasicPublish)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var msg published
+
+	dec = decoder{buf: pub}
+	dec.short()
+	msg.exchange, msg.routingKey = dec.shortStr(), dec.shortStr()
+
+	// content header
+	typ, hdr, err := srv.readFrame()
+	require.NoError(t, err)
+	require.Equal(t, byte(frameHeader), typ)
+
+	size := binary.BigEndian.Uint64(hdr[4:12])
+	msg.flags = binary.BigEndian.Uint16(hdr[12:14])
+	dec = decoder{buf: hdr[14:]}
+	msg.contentType = dec.shortStr()
+
+	// content body
+	for uint64(len(msg.body)) < size {
+		typ, body, err := srv.readFrame()
+		require.NoError(t, err)
+		require.Equal(t, byte(frameBody), typ)
+		assert.LessOrEqual(t, len(body), testFrameMax-frameOverhead)
+
+		msg.body += string(body)
+	}
+
+	msgs <- msg
+
+	if closeCode != 0 {
+		var closeArgs encoder
+		closeArgs.short(closeCode)
+		closeArgs.shortStr("NOT_FOUND - no exchange 'unknown'")
+		closeArgs.short(methodBasicPublish.class)
+		closeArgs.short(methodBasicPublish.id)
+
+		require.NoError(t, srv.writeMethod(channelID, methodChanClose, closeArgs.buf))
+
+		return
+	}
+
+	var ack encoder
+	ack.longLong(1)
+	ack.octet(0)
+
+	require.NoError(t, srv.writeMethod(channelID, methodBasicAck, ack.buf))
+
+	_, err = srv.expectMethod(methodConnClose)
+	require.NoError(t, err)
+	require.NoError(t, srv.writeMethod(0, methodConnCloseOk, nil))
+}
+
+func TestAMQPPublish(t *testing.T) {
+	t.Parallel()
+
+	kind := t.Name()
+	brokers.Register(kind, Backend{})
+	t.Cleanup(func() { brokers.Unregister(kind) })
+
+	payload := make([]byte, 10_000) //nolint:mnd //larger than the frame size
+	for i := range payload {
+		payload[i] = byte('a' + i%26)
+	}
+
+	t.Run("Given a valid configuration", func(t *testing.T) {
+		t.Parallel()
+
+		addr, auths, msgs := fakeServer(t, 0)
+		conf := &brokers.Config{
+			Address: addr, Login: "user", Password: "pswd",
+			Options: map[string]string{OptVHost: "/test", OptExchange: "waarp"},
+		}
+
+		require.NoError(t, brokers.Publish(t.Context(), kind, conf, &brokers.Message{
+			Topic: "transfers", Key: "123", ContentType: "text/plain", Payload: payload,
+		}))
+
+		assert.Equal(t, "\x00user\x00pswd", <-auths)
+
+		msg := <-msgs
+		assert.Equal(t, "waarp", msg.exchange)
+		assert.Equal(t, "transfers", msg.routingKey)
+		assert.Equal(t, uint16(propContentType|propDeliveryMode|propMessageID|propTimestamp|propAppID),
+			msg.flags)
+		assert.Equal(t, "text/plain", msg.contentType)
+		assert.Equal(t, string(payload), msg.body)
+	})
+
+	t.Run("Given that the server closes the channel", func(t *testing.T) {
+		t.Parallel()
+
+		addr, _, _ := fakeServer(t, 404) //nolint:mnd //NOT_FOUND
+		conf := &brokers.Config{
+			Address: addr, Options: map[string]string{OptVHost: "/test", OptExchange: "unknown"},
+		}
+
+		err := brokers.Publish(t.Context(), kind, conf, &brokers.Message{
+			Topic: "transfers", Payload: []byte("hello"),
+		})
+		require.ErrorIs(t, err, ErrChannelClosed)
+		require.ErrorContains(t, err, "404 NOT_FOUND - no exchange 'unknown'")
+	})
+}
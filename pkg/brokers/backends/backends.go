@@ -0,0 +1,27 @@
+// Package backends registers all the message broker clients supported by the
+// gateway.
+package backends
+
+import (
+	"code.waarp.fr/apps/gateway/gateway/pkg/brokers"
+	"code.waarp.fr/apps/gateway/gateway/pkg/brokers/backends/amqp"
+	"code.waarp.fr/apps/gateway/gateway/pkg/brokers/backends/kafka"
+	"code.waarp.fr/apps/gateway/gateway/pkg/brokers/backends/mqtt"
+	"code.waarp.fr/apps/gateway/gateway/pkg/brokers/backends/nats"
+)
+
+//nolint:gochecknoinits //init is used by design
+func init() {
+	// AMQP 0-9-1
+	brokers.Register("amqp", amqp.Backend{})
+	brokers.Register("rabbitmq", amqp.Backend{})
+
+	// Kafka
+	brokers.Register("kafka", kafka.Backend{})
+
+	// MQTT 3.1.1
+	brokers.Register("mqtt", mqtt.Backend{})
+
+	// NATS
+	brokers.Register("nats", nats.Backend{})
+}
@@ -0,0 +1,188 @@
+// Package kafka provides a publish-only Kafka client, based on the franz-go
+// Kafka client.
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/sasl/plain"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/brokers"
+)
+
+const (
+	DefaultPort = "9092"
+
+	// OptClientID is the option specifying the client ID sent to the broker
+	// (defaults to "waarp-gateway").
+	OptClientID = "clientId"
+	// OptAcks is the option specifying the number of acknowledgments the
+	// leader must have received before answering ("all", "0" or "1", defaults
+	// to "all").
+	OptAcks = "acks"
+	// OptPartition is the option specifying the partition to which the
+	// messages are sent. If empty, the partition is chosen using the message
+	// key (like the default Kafka partitioner), or randomly if the message has
+	// no key.
+	OptPartition = "partition"
+
+	defaultClientID = "waarp-gateway"
+	maxStringLength = 32767
+)
+
+var (
+	ErrInvalidAcks      = errors.New(`the Kafka acks must be either "all", "0" or "1"`)
+	ErrInvalidPartition = errors.New("the Kafka partition must be a positive integer")
+	ErrTopicTooLong     = errors.New("the Kafka topic name is too long")
+	ErrBrokerError      = errors.New("the Kafka broker returned an error")
+)
+
+type Backend struct{}
+
+func (Backend) Options() []string { return []string{OptClientID, OptAcks, OptPartition} }
+
+func (Backend) ValidateConfig(conf *brokers.Config) error {
+	if _, err := parseAcks(conf.Options); err != nil {
+		return err
+	}
+
+	if _, err := parsePartition(conf.Options); err != nil {
+		return err
+	}
+
+	if len(conf.Options[OptClientID]) > maxStringLength {
+		return fmt.Errorf("%w %q: the client ID is too long", brokers.ErrInvalidOption, OptClientID)
+	}
+
+	return nil
+}
+
+func parseAcks(opts map[string]string) (kgo.Acks, error) {
+	switch opts[OptAcks] {
+	case "", "all", "-1":
+		return kgo.AllISRAcks(), nil
+	case "0":
+		return kgo.NoAck(), nil
+	case "1":
+		return kgo.LeaderAck(), nil
+	default:
+		return kgo.Acks{}, fmt.Errorf("%w (got %q)", ErrInvalidAcks, opts[OptAcks])
+	}
+}
+
+func parsePartition(opts map[string]string) (int32, error) {
+	val, ok := opts[OptPartition]
+	if !ok || val == "" {
+		return -1, nil
+	}
+
+	part, err := strconv.ParseInt(val, 10, 32)
+	if err != nil || part < 0 {
+		return 0, fmt.Errorf("%w (got %q)", ErrInvalidPartition, val)
+	}
+
+	return int32(part), nil
+}
+
+type Client struct {
+	client    *kgo.Client
+	partition int32
+}
+
+func (Backend) Dial(ctx context.Context, conf *brokers.Config) (brokers.Client, error) {
+	acks, err := parseAcks(conf.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	partition, err := parsePartition(conf.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	clientID := conf.Options[OptClientID]
+	if clientID == "" {
+		clientID = defaultClientID
+	}
+
+	opts := []kgo.Opt{
+		kgo.SeedBrokers(brokers.Addresses(conf.Address, DefaultPort)...),
+		kgo.ClientID(clientID),
+		kgo.DialTimeout(conf.Timeout()),
+		kgo.RequiredAcks(acks),
+		// The messages are published one at a time on short-lived connections,
+		// so batching, compression and idempotence would only add overhead
+		// (and require additional ACLs on older brokers).
+		kgo.DisableIdempotentWrite(),
+		kgo.ProducerBatchCompression(kgo.NoCompression()),
+		kgo.ProduceRequestTimeout(conf.Timeout()),
+		kgo.RecordDeliveryTimeout(conf.Timeout()),
+		kgo.UnknownTopicRetries(0),
+	}
+
+	if conf.UseTLS() {
+		opts = append(opts, kgo.DialTLSConfig(conf.TLS("")))
+	}
+
+	if conf.Login != "" {
+		opts = append(opts, kgo.SASL(plain.Auth{User: conf.Login, Pass: conf.Password}.AsMechanism()))
+	}
+
+	if partition >= 0 {
+		opts = append(opts, kgo.RecordPartitioner(kgo.ManualPartitioner()))
+	}
+
+	client, err := kgo.NewClient(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the Kafka client: %w", err)
+	}
+
+	// The client connects lazily, so the connection is checked beforehand to
+	// distinguish the connection errors from the publication ones.
+	if err := client.Ping(ctx); err != nil {
+		client.Close()
+
+		return nil, kafkaError(err)
+	}
+
+	return &Client{client: client, partition: partition}, nil
+}
+
+// kafkaError wraps the errors returned by the broker with ErrBrokerError.
+func kafkaError(err error) error {
+	var kErr *kerr.Error
+	if errors.As(err, &kErr) {
+		return fmt.Errorf("%w: %w", ErrBrokerError, err)
+	}
+
+	return err
+}
+
+func (c *Client) Publish(ctx context.Context, msg *brokers.Message) error {
+	if len(msg.Topic) > maxStringLength {
+		return ErrTopicTooLong
+	}
+
+	record := &kgo.Record{Topic: msg.Topic, Partition: c.partition, Value: msg.Payload}
+
+	if msg.Key != "" {
+		record.Key = []byte(msg.Key)
+	}
+
+	if msg.ContentType != "" {
+		record.Headers = []kgo.RecordHeader{{Key: "content-type", Value: []byte(msg.ContentType)}}
+	}
+
+	return kafkaError(c.client.ProduceSync(ctx, record).FirstErr())
+}
+
+func (c *Client) Close() error {
+	c.client.Close()
+
+	return nil
+}
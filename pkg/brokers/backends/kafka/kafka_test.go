@@ -0,0 +1,336 @@
+package kafka
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kbin"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/brokers"
+)
+
+type record struct {
+	topic     string
+	partition int32
+	acks      int16
+	key       string
+	value     string
+	headers   map[string]string
+}
+
+// fakeBroker starts a minimal Kafka broker hosting the given topic (with the
+// given number of partitions), and sends the produced records on the returned
+// channel.
+func fakeBroker(t *testing.T, topic string, partitions int32) (string, <-chan record, <-chan string) {
+	t.Helper()
+
+	list, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { list.Close() })
+
+	records := make(chan record, 1)
+	auths := make(chan string, 1)
+
+	host, portStr, _ := net.SplitHostPort(list.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+
+	broker := &fakeHandler{
+		host: host, port: int32(port), //nolint:gosec //test port
+		topic: topic, partitions: partitions,
+		records: records, auths: auths,
+	}
+
+	go func() {
+		for {
+			conn, accErr := list.Accept()
+			if accErr != nil {
+				return
+			}
+
+			go broker.serve(t, conn)
+		}
+	}()
+
+	return list.Addr().String(), records, auths
+}
+
+type fakeHandler struct {
+	host       string
+	port       int32
+	topic      string
+	partitions int32
+	records    chan<- record
+	auths      chan<- string
+}
+
+func (f *fakeHandler) serve(t *testing.T, conn net.Conn) {
+	t.Helper()
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+
+	for {
+		var size [4]byte
+		if _, err := io.ReadFull(reader, size[:]); err != nil {
+			return
+		}
+
+		buf := make([]byte, binary.BigEndian.Uint32(size[:]))
+		if _, err := io.ReadFull(reader, buf); !assert.NoError(t, err) {
+			return
+		}
+
+		hdr := kbin.Reader{Src: buf}
+		key, version, corrID := hdr.Int16(), hdr.Int16(), hdr.Int32()
+		_ = hdr.NullableString() // client ID
+
+		req := kmsg.RequestForKey(key)
+		if !assert.NotNil(t, req, "unknown API key %d", key) {
+			return
+		}
+
+		req.SetVersion(version)
+
+		if req.IsFlexible() {
+			for range hdr.Uvarint() { // tagged fields
+				_ = hdr.Uvarint()
+				hdr.Span(int(hdr.Uvarint()))
+			}
+		}
+
+		if !assert.NoError(t, req.ReadFrom(hdr.Src)) {
+			return
+		}
+
+		resp := f.handle(t, req)
+		if resp == nil {
+			continue // no response expected
+		}
+
+		out := binary.BigEndian.AppendUint32(make([]byte, 4), uint32(corrID)) //nolint:gosec //wire format
+
+		// The ApiVersions response header is never flexible.
+		if resp.IsFlexible() && key != kmsg.ApiVersions.Int16() {
+			out = append(out, 0) // no tagged fields
+		}
+
+		out = resp.AppendTo(out)
+		binary.BigEndian.PutUint32(out, uint32(len(out)-4)) //nolint:gosec //wire format
+
+		if _, err := conn.Write(out); !assert.NoError(t, err) {
+			return
+		}
+	}
+}
+
+func (f *fakeHandler) handle(t *testing.T, req kmsg.Request) kmsg.Response {
+	t.Helper()
+
+	switch req := req.(type) {
+	case *kmsg.ApiVersionsRequest:
+		resp := kmsg.NewPtrApiVersionsResponse()
+		resp.SetVersion(req.Version)
+
+		for _, key := range []kmsg.Key{
+			kmsg.ApiVersions, kmsg.Metadata, kmsg.Produce, kmsg.SASLHandshake, kmsg.SASLAuthenticate,
+		} {
+			apiKey := kmsg.NewApiVersionsResponseApiKey()
+			apiKey.ApiKey = key.Int16()
+			apiKey.MaxVersion = kmsg.RequestForKey(key.Int16()).MaxVersion()
+			resp.ApiKeys = append(resp.ApiKeys, apiKey)
+		}
+
+		return resp
+	case *kmsg.SASLHandshakeRequest:
+		assert.Equal(t, "PLAIN", req.Mechanism)
+
+		resp := kmsg.NewPtrSASLHandshakeResponse()
+		resp.SetVersion(req.Version)
+		resp.SupportedMechanisms = []string{"PLAIN"}
+
+		return resp
+	case *kmsg.SASLAuthenticateRequest:
+		// The client opens several connections, only the first
+		// authentication is reported.
+		select {
+		case f.auths <- string(req.SASLAuthBytes):
+		default:
+		}
+
+		resp := kmsg.NewPtrSASLAuthenticateResponse()
+		resp.SetVersion(req.Version)
+
+		return resp
+	case *kmsg.MetadataRequest:
+		return f.metadata(req)
+	case *kmsg.ProduceRequest:
+		return f.produce(t, req)
+	default:
+		assert.Failf(t, "unexpected request", "API key %d", req.Key())
+
+		return nil
+	}
+}
+
+func (f *fakeHandler) metadata(req *kmsg.MetadataRequest) kmsg.Response {
+	resp := kmsg.NewPtrMetadataResponse()
+	resp.SetVersion(req.Version)
+
+	broker := kmsg.NewMetadataResponseBroker()
+	broker.Host, broker.Port = f.host, f.port
+	resp.Brokers = append(resp.Brokers, broker)
+
+	for _, reqTopic := range req.Topics {
+		topic := kmsg.NewMetadataResponseTopic()
+		topic.Topic = reqTopic.Topic
+
+		if reqTopic.Topic == nil || *reqTopic.Topic != f.topic {
+			topic.ErrorCode = kerr.UnknownTopicOrPartition.Code
+			resp.Topics = append(resp.Topics, topic)
+
+			continue
+		}
+
+		for i := range f.partitions {
+			part := kmsg.NewMetadataResponseTopicPartition()
+			part.Partition = i
+			part.Replicas = []int32{0}
+			part.ISR = []int32{0}
+			topic.Partitions = append(topic.Partitions, part)
+		}
+
+		resp.Topics = append(resp.Topics, topic)
+	}
+
+	return resp
+}
+
+func (f *fakeHandler) produce(t *testing.T, req *kmsg.ProduceRequest) kmsg.Response {
+	t.Helper()
+
+	resp := kmsg.NewPtrProduceResponse()
+	resp.SetVersion(req.Version)
+
+	for _, reqTopic := range req.Topics {
+		topic := kmsg.NewProduceResponseTopic()
+		topic.Topic = reqTopic.Topic
+
+		for _, reqPart := range reqTopic.Partitions {
+			var batch kmsg.RecordBatch
+			require.NoError(t, batch.ReadFrom(reqPart.Records))
+			require.Equal(t, int32(1), batch.NumRecords)
+
+			var rec kmsg.Record
+			require.NoError(t, rec.ReadFrom(batch.Records))
+
+			headers := map[string]string{}
+			for _, h := range rec.Headers {
+				headers[h.Key] = string(h.Value)
+			}
+
+			f.records <- record{
+				topic: reqTopic.Topic, partition: reqPart.Partition, acks: req.Acks,
+				key: string(rec.Key), value: string(rec.Value), headers: headers,
+			}
+
+			part := kmsg.NewProduceResponseTopicPartition()
+			part.Partition = reqPart.Partition
+			part.BaseOffset = 42
+			topic.Partitions = append(topic.Partitions, part)
+		}
+
+		resp.Topics = append(resp.Topics, topic)
+	}
+
+	if req.Acks == 0 {
+		return nil
+	}
+
+	return resp
+}
+
+func TestKafkaPublish(t *testing.T) {
+	t.Parallel()
+
+	kind := t.Name()
+	brokers.Register(kind, Backend{})
+	t.Cleanup(func() { brokers.Unregister(kind) })
+
+	t.Run("Given a valid configuration", func(t *testing.T) {
+		t.Parallel()
+
+		addr, records, auths := fakeBroker(t, "transfers", 3) //nolint:mnd //test value
+		conf := &brokers.Config{Address: addr, Login: "user", Password: "pswd"}
+
+		require.NoError(t, brokers.Publish(t.Context(), kind, conf, &brokers.Message{
+			Topic: "transfers", Key: "foobar", ContentType: "application/json",
+			Payload: []byte(`{"foo":"bar"}`),
+		}))
+
+		assert.Equal(t, "\x00user\x00pswd", <-auths)
+
+		rec := <-records
+		assert.Equal(t, "transfers", rec.topic)
+		assert.Equal(t, int32(0), rec.partition,
+			`the partition should be the (positive) murmur2 hash of "foobar" modulo 3`)
+		assert.Equal(t, int16(-1), rec.acks, "acks should be 'all'")
+		assert.Equal(t, "foobar", rec.key)
+		assert.JSONEq(t, `{"foo":"bar"}`, rec.value)
+		assert.Equal(t, map[string]string{"content-type": "application/json"}, rec.headers)
+	})
+
+	t.Run("Given a fixed partition", func(t *testing.T) {
+		t.Parallel()
+
+		addr, records, _ := fakeBroker(t, "transfers", 3) //nolint:mnd //test value
+		conf := &brokers.Config{
+			Address: addr,
+			Options: map[string]string{OptPartition: "2", OptAcks: "1"},
+		}
+
+		require.NoError(t, brokers.Publish(t.Context(), kind, conf, &brokers.Message{
+			Topic: "transfers", Key: "foobar", Payload: []byte("hello"),
+		}))
+
+		rec := <-records
+		assert.Equal(t, int32(2), rec.partition)
+		assert.Equal(t, int16(1), rec.acks)
+	})
+
+	t.Run("Given an unknown topic", func(t *testing.T) {
+		t.Parallel()
+
+		addr, _, _ := fakeBroker(t, "transfers", 1)
+		conf := &brokers.Config{Address: addr}
+
+		err := brokers.Publish(t.Context(), kind, conf, &brokers.Message{
+			Topic: "unknown", Payload: []byte("hello"),
+		})
+		require.ErrorIs(t, err, ErrBrokerError)
+		require.ErrorIs(t, err, kerr.UnknownTopicOrPartition)
+	})
+}
+
+func TestKafkaValidateConfig(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, Backend{}.ValidateConfig(&brokers.Config{
+		Options: map[string]string{OptAcks: "1", OptPartition: "2"},
+	}))
+
+	require.ErrorIs(t, Backend{}.ValidateConfig(&brokers.Config{
+		Options: map[string]string{OptAcks: "2"},
+	}), ErrInvalidAcks)
+
+	require.ErrorIs(t, Backend{}.ValidateConfig(&brokers.Config{
+		Options: map[string]string{OptPartition: "-1"},
+	}), ErrInvalidPartition)
+}
@@ -0,0 +1,187 @@
+// Package mqtt provides a publish-only MQTT 3.1.1 client, based on the Eclipse
+// Paho MQTT client.
+package mqtt
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/eclipse/paho.mqtt.golang/packets"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/brokers"
+)
+
+const (
+	DefaultPort    = "1883"
+	DefaultTLSPort = "8883"
+
+	// OptClientID is the option specifying the MQTT client identifier. If
+	// empty, a random identifier is generated for each connection.
+	OptClientID = "clientId"
+	// OptQoS is the option specifying the QoS level of the published
+	// messages (0 or 1, defaults to 1).
+	OptQoS = "qos"
+	// OptRetain is the option specifying whether the published messages
+	// should be retained by the broker.
+	OptRetain = "retain"
+
+	defaultQoS      = 1
+	protocolVersion = 4 // MQTT 3.1.1
+	keepAlive       = 60 * time.Second
+
+	// disconnectQuiesce is the time (in milliseconds) given to the client to
+	// finish its pending work when disconnecting.
+	disconnectQuiesce = 250
+
+	maxStringLength = 65535
+)
+
+var (
+	ErrInvalidQoS        = errors.New("the MQTT QoS level must be either 0 or 1")
+	ErrConnectionRefused = errors.New("the MQTT broker refused the connection")
+	ErrTooLarge          = errors.New("the MQTT packet is too large")
+)
+
+type Backend struct{}
+
+func (Backend) Options() []string { return []string{OptClientID, OptQoS, OptRetain} }
+
+func (Backend) ValidateConfig(conf *brokers.Config) error {
+	if _, err := parseQoS(conf.Options); err != nil {
+		return err
+	}
+
+	if retain, ok := conf.Options[OptRetain]; ok {
+		if _, err := strconv.ParseBool(retain); err != nil {
+			return fmt.Errorf("%w %q: %q is not a boolean", brokers.ErrInvalidOption, OptRetain, retain)
+		}
+	}
+
+	if len(conf.Options[OptClientID]) > maxStringLength {
+		return fmt.Errorf("%w %q: the client ID is too long", brokers.ErrInvalidOption, OptClientID)
+	}
+
+	return nil
+}
+
+func parseQoS(opts map[string]string) (byte, error) {
+	qos, ok := opts[OptQoS]
+	if !ok || qos == "" {
+		return defaultQoS, nil
+	}
+
+	switch qos {
+	case "0":
+		return 0, nil
+	case "1":
+		return 1, nil
+	default:
+		return 0, fmt.Errorf("%w (got %q)", ErrInvalidQoS, qos)
+	}
+}
+
+type Client struct {
+	client paho.Client
+	qos    byte
+	retain bool
+}
+
+func (Backend) Dial(ctx context.Context, conf *brokers.Config) (brokers.Client, error) {
+	qos, err := parseQoS(conf.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	retain, _ := strconv.ParseBool(conf.Options[OptRetain])
+
+	clientID := conf.Options[OptClientID]
+	if clientID == "" {
+		clientID = randomClientID()
+	}
+
+	scheme, defaultPort := "tcp://", DefaultPort
+	if conf.UseTLS() {
+		scheme, defaultPort = "ssl://", DefaultTLSPort
+	}
+
+	opts := paho.NewClientOptions().
+		SetProtocolVersion(protocolVersion).
+		SetClientID(clientID).
+		SetUsername(conf.Login).
+		SetPassword(conf.Password).
+		SetCleanSession(true).
+		SetKeepAlive(keepAlive).
+		SetAutoReconnect(false).
+		SetConnectRetry(false).
+		SetConnectTimeout(conf.Timeout())
+
+	if deadline, ok := ctx.Deadline(); ok {
+		opts.SetConnectTimeout(time.Until(deadline))
+	}
+
+	if conf.UseTLS() {
+		opts.SetTLSConfig(conf.TLS(""))
+	}
+
+	for _, addr := range brokers.Addresses(conf.Address, defaultPort) {
+		opts.AddBroker(scheme + addr)
+	}
+
+	client := paho.NewClient(opts)
+	token := client.Connect()
+
+	if err := waitToken(ctx, token); err != nil {
+		//nolint:forcetypeassert //Connect always returns a ConnectToken
+		if code := token.(*paho.ConnectToken).ReturnCode(); code != packets.Accepted && code < packets.ErrNetworkError {
+			return nil, fmt.Errorf("%w: %w", ErrConnectionRefused, err)
+		}
+
+		return nil, err
+	}
+
+	return &Client{client: client, qos: qos, retain: retain}, nil
+}
+
+func randomClientID() string {
+	const idLen = 8
+
+	buf := make([]byte, idLen)
+	_, _ = rand.Read(buf) //nolint:errcheck //crypto/rand.Read never returns an error
+
+	return "waarp-gateway-" + hex.EncodeToString(buf)
+}
+
+// waitToken waits for the completion of the given token, or for the
+// cancellation of the given context, whichever comes first.
+func waitToken(ctx context.Context, token paho.Token) error {
+	select {
+	case <-token.Done():
+		return token.Error() //nolint:wrapcheck //wrapping adds nothing here
+	case <-ctx.Done():
+		return ctx.Err() //nolint:wrapcheck //wrapping adds nothing here
+	}
+}
+
+func (c *Client) Publish(ctx context.Context, msg *brokers.Message) error {
+	if len(msg.Topic) > maxStringLength {
+		return fmt.Errorf("%w: the topic name is too long", ErrTooLarge)
+	}
+
+	if err := waitToken(ctx, c.client.Publish(msg.Topic, c.qos, c.retain, msg.Payload)); err != nil {
+		return fmt.Errorf("failed to publish the MQTT message: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Client) Close() error {
+	c.client.Disconnect(disconnectQuiesce)
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package mqtt
+
+import (
+	"net"
+	"testing"
+
+	"github.com/eclipse/paho.mqtt.golang/packets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/brokers"
+)
+
+type published struct {
+	qos     byte
+	retain  bool
+	topic   string
+	payload string
+}
+
+type connectPacket struct {
+	clientID, login, password string
+}
+
+// fakeServer starts a minimal MQTT broker which accepts a single connection,
+// answers the CONNECT packet with the given return code, and sends the
+// received message on the returned channel.
+func fakeServer(t *testing.T, connAckCode byte) (string, <-chan connectPacket, <-chan published) {
+	t.Helper()
+
+	list, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { list.Close() })
+
+	connects := make(chan connectPacket, 1)
+	msgs := make(chan published, 1)
+
+	go func() {
+		conn, accErr := list.Accept()
+		if !assert.NoError(t, accErr) {
+			return
+		}
+		defer conn.Close()
+
+		pkt, readErr := packets.ReadPacket(conn)
+		if !assert.NoError(t, readErr) {
+			return
+		}
+
+		connect, ok := pkt.(*packets.ConnectPacket)
+		if !assert.True(t, ok, "the first packet should be a CONNECT") {
+			return
+		}
+
+		connects <- connectPacket{
+			clientID: connect.ClientIdentifier,
+			login:    connect.Username,
+			password: string(connect.Password),
+		}
+
+		//nolint:forcetypeassert //the type is always correct here
+		connAck := packets.NewControlPacket(packets.Connack).(*packets.ConnackPacket)
+		connAck.ReturnCode = connAckCode
+
+		if !assert.NoError(t, connAck.Write(conn)) || connAckCode != 0 {
+			return
+		}
+
+		for {
+			if pkt, readErr = packets.ReadPacket(conn); readErr != nil {
+				return
+			}
+
+			switch pkt := pkt.(type) {
+			case *packets.PublishPacket:
+				if pkt.Qos > 0 {
+					//nolint:forcetypeassert //the type is always correct here
+					pubAck := packets.NewControlPacket(packets.Puback).(*packets.PubackPacket)
+					pubAck.MessageID = pkt.MessageID
+					assert.NoError(t, pubAck.Write(conn))
+				}
+
+				msgs <- published{
+					qos: pkt.Qos, retain: pkt.Retain,
+					topic: pkt.TopicName, payload: string(pkt.Payload),
+				}
+			case *packets.PingreqPacket:
+				pingResp := packets.NewControlPacket(packets.Pingresp)
+				assert.NoError(t, pingResp.Write(conn))
+			case *packets.DisconnectPacket:
+				return
+			}
+		}
+	}()
+
+	return list.Addr().String(), connects, msgs
+}
+
+func TestMQTTPublish(t *testing.T) {
+	t.Parallel()
+
+	kind := t.Name()
+	brokers.Register(kind, Backend{})
+	t.Cleanup(func() { brokers.Unregister(kind) })
+
+	t.Run("Given a valid configuration", func(t *testing.T) {
+		t.Parallel()
+
+		addr, connects, msgs := fakeServer(t, 0)
+		conf := &brokers.Config{
+			Address: addr, Login: "user", Password: "pswd",
+			Options: map[string]string{OptClientID: "gw-test", OptRetain: "true"},
+		}
+
+		require.NoError(t, brokers.Publish(t.Context(), kind, conf, &brokers.Message{
+			Topic: "waarp/transfers", Payload: []byte(`{"foo":"bar"}`),
+		}))
+
+		assert.Equal(t, connectPacket{clientID: "gw-test", login: "user", password: "pswd"},
+			<-connects)
+
+		msg := <-msgs
+		assert.Equal(t, byte(1), msg.qos)
+		assert.True(t, msg.retain)
+		assert.Equal(t, "waarp/transfers", msg.topic)
+		assert.JSONEq(t, `{"foo":"bar"}`, msg.payload)
+	})
+
+	t.Run("Given that the connection is refused", func(t *testing.T) {
+		t.Parallel()
+
+		addr, _, _ := fakeServer(t, 4) //nolint:mnd //bad user name or password
+		conf := &brokers.Config{Address: addr, Login: "user", Password: "wrong"}
+
+		err := brokers.Publish(t.Context(), kind, conf, &brokers.Message{
+			Topic: "waarp/transfers", Payload: []byte("hello"),
+		})
+		require.ErrorIs(t, err, ErrConnectionRefused)
+		require.ErrorContains(t, err, "bad user name or password")
+	})
+}
+
+func TestMQTTValidateConfig(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, Backend{}.ValidateConfig(&brokers.Config{
+		Options: map[string]string{OptQoS: "0", OptRetain: "false"},
+	}))
+
+	require.ErrorIs(t, Backend{}.ValidateConfig(&brokers.Config{
+		Options: map[string]string{OptQoS: "2"},
+	}), ErrInvalidQoS)
+
+	require.ErrorIs(t, Backend{}.ValidateConfig(&brokers.Config{
+		Options: map[string]string{OptRetain: "maybe"},
+	}), brokers.ErrInvalidOption)
+}
@@ -0,0 +1,124 @@
+// Package nats provides a publish-only NATS client, based on the official
+// NATS Go client.
+package nats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	natsgo "github.com/nats-io/nats.go"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/brokers"
+)
+
+const (
+	DefaultPort = "4222"
+
+	// OptToken is the option used to authenticate with a token instead of
+	// a login & password.
+	OptToken = "token"
+	// OptHeaderKey is the option specifying the name of the header in which
+	// the message key is sent. If empty, the key is not sent.
+	OptHeaderKey = "keyHeader"
+
+	clientName = "waarp-gateway"
+)
+
+var (
+	ErrServerError    = errors.New("the NATS server returned an error")
+	ErrInvalidSubject = errors.New("invalid NATS subject")
+)
+
+type Backend struct{}
+
+func (Backend) Options() []string { return []string{OptToken, OptHeaderKey} }
+
+func (Backend) ValidateConfig(*brokers.Config) error { return nil }
+
+type Client struct {
+	conn      *natsgo.Conn
+	keyHeader string
+}
+
+func (Backend) Dial(ctx context.Context, conf *brokers.Config) (brokers.Client, error) {
+	scheme := "nats://"
+	if conf.UseTLS() {
+		scheme = "tls://"
+	}
+
+	opts := natsgo.GetDefaultOptions()
+	opts.Name = clientName
+	opts.User = conf.Login
+	opts.Password = conf.Password
+	opts.Token = conf.Options[OptToken]
+	opts.AllowReconnect = false
+	opts.NoRandomize = true // the servers are tried in the given order
+	opts.Timeout = conf.Timeout()
+	opts.TLSConfig = conf.TLS("")
+
+	for _, addr := range brokers.Addresses(conf.Address, DefaultPort) {
+		opts.Servers = append(opts.Servers, scheme+addr)
+	}
+
+	// The NATS client does not accept a context when connecting, so the
+	// context's deadline is used as connection timeout instead.
+	if deadline, ok := ctx.Deadline(); ok {
+		opts.Timeout = time.Until(deadline)
+	}
+
+	conn, err := opts.Connect()
+	if err != nil {
+		return nil, err //nolint:wrapcheck //wrapping adds nothing here
+	}
+
+	return &Client{conn: conn, keyHeader: conf.Options[OptHeaderKey]}, nil
+}
+
+func (c *Client) Publish(ctx context.Context, msg *brokers.Message) error {
+	if strings.ContainsAny(msg.Topic, " \t\r\n") {
+		return fmt.Errorf("%w %q", ErrInvalidSubject, msg.Topic)
+	}
+
+	natsMsg := natsgo.NewMsg(msg.Topic)
+	natsMsg.Data = msg.Payload
+
+	if c.keyHeader != "" && msg.Key != "" {
+		natsMsg.Header.Set(c.keyHeader, msg.Key)
+	}
+
+	if msg.ContentType != "" {
+		natsMsg.Header.Set("Content-Type", msg.ContentType)
+	}
+
+	if len(natsMsg.Header) == 0 {
+		natsMsg.Header = nil
+	}
+
+	if err := c.conn.PublishMsg(natsMsg); err != nil {
+		return fmt.Errorf("failed to send the NATS message: %w", err)
+	}
+
+	// Since the server processes the messages in order, a successful flush
+	// means that the message was accepted, unless an error was received in
+	// the meantime.
+	flushErr := c.conn.FlushWithContext(ctx)
+
+	if err := c.conn.LastError(); err != nil {
+		return fmt.Errorf("%w: %w", ErrServerError, err)
+	}
+
+	if flushErr != nil {
+		return fmt.Errorf("failed to flush the NATS connection: %w", flushErr)
+	}
+
+	return nil
+}
+
+func (c *Client) Close() error {
+	c.conn.Close()
+
+	return nil
+}
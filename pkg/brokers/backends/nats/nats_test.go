@@ -0,0 +1,157 @@
+package nats
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/brokers"
+)
+
+type published struct {
+	subject, headers, payload string
+}
+
+type connectInfo struct {
+	User    string `json:"user"`
+	Pass    string `json:"pass"`
+	Headers bool   `json:"headers"`
+}
+
+// fakeServer starts a minimal NATS server which accepts a single connection,
+// and sends the received message on the returned channel.
+func fakeServer(t *testing.T, pubErr string) (string, <-chan published, <-chan connectInfo) {
+	t.Helper()
+
+	list, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { list.Close() })
+
+	msgs := make(chan published, 1)
+	connects := make(chan connectInfo, 1)
+
+	go func() {
+		conn, accErr := list.Accept()
+		if !assert.NoError(t, accErr) {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+
+		fmt.Fprint(conn, `INFO {"server_id":"test","headers":true,"max_payload":1048576}`+"\r\n")
+
+		for {
+			line, readErr := reader.ReadString('\n')
+			if readErr != nil {
+				return
+			}
+
+			line = strings.TrimSpace(line)
+			verb, args, _ := strings.Cut(line, " ")
+
+			switch verb {
+			case "CONNECT":
+				var info connectInfo
+				assert.NoError(t, json.Unmarshal([]byte(args), &info))
+				connects <- info
+			case "PING":
+				fmt.Fprint(conn, "PONG\r\n")
+			case "PUB", "HPUB":
+				fields := strings.Fields(args)
+				total, _ := strconv.Atoi(fields[len(fields)-1])
+				hdrLen := 0
+
+				if verb == "HPUB" {
+					hdrLen, _ = strconv.Atoi(fields[1])
+				}
+
+				buf := make([]byte, total+2) //nolint:mnd //payload + CRLF
+				if _, err := io.ReadFull(reader, buf); !assert.NoError(t, err) {
+					return
+				}
+
+				if pubErr != "" {
+					fmt.Fprintf(conn, "-ERR '%s'\r\n", pubErr)
+
+					continue
+				}
+
+				msgs <- published{
+					subject: fields[0],
+					headers: string(buf[:hdrLen]),
+					payload: string(buf[hdrLen:total]),
+				}
+			}
+		}
+	}()
+
+	return list.Addr().String(), msgs, connects
+}
+
+func TestNATSPublish(t *testing.T) {
+	t.Parallel()
+
+	kind := t.Name()
+	brokers.Register(kind, Backend{})
+	t.Cleanup(func() { brokers.Unregister(kind) })
+
+	t.Run("Given a valid configuration", func(t *testing.T) {
+		t.Parallel()
+
+		addr, msgs, connects := fakeServer(t, "")
+		conf := &brokers.Config{
+			Address: addr, Login: "user", Password: "pswd",
+			Options: map[string]string{OptHeaderKey: "Nats-Msg-Id"},
+		}
+
+		require.NoError(t, brokers.Publish(t.Context(), kind, conf, &brokers.Message{
+			Topic: "waarp.transfers", Key: "123", ContentType: "application/json",
+			Payload: []byte(`{"foo":"bar"}`),
+		}))
+
+		info := <-connects
+		assert.Equal(t, "user", info.User)
+		assert.Equal(t, "pswd", info.Pass)
+		assert.True(t, info.Headers)
+
+		msg := <-msgs
+		assert.Equal(t, "waarp.transfers", msg.subject)
+		assert.True(t, strings.HasPrefix(msg.headers, "NATS/1.0\r\n"))
+		assert.Contains(t, msg.headers, "\r\nNats-Msg-Id: 123\r\n")
+		assert.Contains(t, msg.headers, "\r\nContent-Type: application/json\r\n")
+		assert.JSONEq(t, `{"foo":"bar"}`, msg.payload)
+	})
+
+	t.Run("Given that the server returns an error", func(t *testing.T) {
+		t.Parallel()
+
+		addr, _, _ := fakeServer(t, "Permissions Violation for Publish")
+		conf := &brokers.Config{Address: addr}
+
+		err := brokers.Publish(t.Context(), kind, conf, &brokers.Message{
+			Topic: "forbidden", Payload: []byte("hello"),
+		})
+		require.ErrorIs(t, err, ErrServerError)
+		require.ErrorContains(t, err, "Permissions Violation for Publish")
+	})
+
+	t.Run("Given an invalid subject", func(t *testing.T) {
+		t.Parallel()
+
+		addr, _, _ := fakeServer(t, "")
+		conf := &brokers.Config{Address: addr}
+
+		require.ErrorIs(t, brokers.Publish(t.Context(), kind, conf, &brokers.Message{
+			Topic: "invalid subject", Payload: []byte("hello"),
+		}), ErrInvalidSubject)
+	})
+}
@@ -0,0 +1,190 @@
+// Package brokers provides publish-only clients for the message brokers (AMQP,
+// Kafka, MQTT, NATS...) supported by the gateway. The clients themselves are
+// implemented in the "backends" sub-package (on top of each broker's client
+// library), and must be registered using the Register function before they
+// can be used.
+package brokers
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/puzpuzpuz/xsync/v4"
+)
+
+var (
+	ErrUnknownBrokerType = errors.New("unknown message broker type")
+	ErrMissingAddress    = errors.New("the message broker's address cannot be empty")
+	ErrMissingTopic      = errors.New("the message topic cannot be empty")
+	ErrUnknownOption     = errors.New("unknown message broker option")
+	ErrInvalidOption     = errors.New("invalid message broker option value")
+)
+
+const (
+	// OptTLS is the (common) option used to enable TLS on the broker connection.
+	OptTLS = "tls"
+	// OptTimeout is the (common) option used to set the timeout of the
+	// connection and publication operations.
+	OptTimeout = "timeout"
+
+	DefaultTimeout = 30 * time.Second
+)
+
+//nolint:gochecknoglobals //global var is needed here for the registry
+var backends = xsync.NewMap[string, Backend]()
+
+// Config is the configuration of a message broker connection.
+type Config struct {
+	// Address is the address of the broker. Depending on the broker type, it
+	// can contain multiple comma-separated addresses.
+	Address  string
+	Login    string
+	Password string
+	Options  map[string]string
+
+	// TLSConfig is the TLS configuration used when the "tls" option is
+	// enabled. If nil, a default configuration is used.
+	TLSConfig *tls.Config
+}
+
+// UseTLS returns whether TLS is enabled for the connection.
+func (c *Config) UseTLS() bool {
+	b, _ := strconv.ParseBool(c.Options[OptTLS])
+
+	return b
+}
+
+// Timeout returns the connection and publication timeout.
+func (c *Config) Timeout() time.Duration {
+	if d, err := time.ParseDuration(c.Options[OptTimeout]); err == nil && d > 0 {
+		return d
+	}
+
+	return DefaultTimeout
+}
+
+// Message is a message to be published on a broker.
+type Message struct {
+	// Topic is the destination of the message (the routing key for AMQP,
+	// the topic for Kafka & MQTT, and the subject for NATS).
+	Topic string
+	// Key is the (optional) message key. Its use depends on the broker type.
+	Key         string
+	ContentType string
+	Payload     []byte
+}
+
+// Client is a connection to a message broker.
+type Client interface {
+	Publish(ctx context.Context, msg *Message) error
+	Close() error
+}
+
+// Backend is the interface implemented by all the message broker backends.
+type Backend interface {
+	// Options returns the list of options (besides the common ones) accepted
+	// by the backend.
+	Options() []string
+	// ValidateConfig checks that the given configuration is valid for the
+	// backend.
+	ValidateConfig(conf *Config) error
+	// Dial opens a new connection to the broker.
+	Dial(ctx context.Context, conf *Config) (Client, error)
+}
+
+// Register adds the given backend to the list of known broker types.
+func Register(kind string, backend Backend) {
+	backends.Store(kind, backend)
+}
+
+// Unregister removes the given broker type from the list of known types.
+func Unregister(kind string) {
+	backends.Delete(kind)
+}
+
+func getBackend(kind string) (Backend, error) {
+	backend, ok := backends.Load(kind)
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownBrokerType, kind)
+	}
+
+	return backend, nil
+}
+
+// ValidateConfig checks that the given configuration is valid for the given
+// broker type.
+func ValidateConfig(kind string, conf *Config) error {
+	backend, err := getBackend(kind)
+	if err != nil {
+		return err
+	}
+
+	if conf.Address == "" {
+		return ErrMissingAddress
+	}
+
+	if err := validateCommonOptions(conf.Options, backend.Options()); err != nil {
+		return err
+	}
+
+	return backend.ValidateConfig(conf) //nolint:wrapcheck //wrapping adds nothing here
+}
+
+func validateCommonOptions(opts map[string]string, allowed []string) error {
+	for key, val := range opts {
+		switch key {
+		case OptTLS:
+			if _, err := strconv.ParseBool(val); err != nil {
+				return fmt.Errorf("%w %q: %q is not a boolean", ErrInvalidOption, key, val)
+			}
+		case OptTimeout:
+			if d, err := time.ParseDuration(val); err != nil || d <= 0 {
+				return fmt.Errorf("%w %q: %q is not a valid duration", ErrInvalidOption, key, val)
+			}
+		default:
+			if !slices.Contains(allowed, key) {
+				return fmt.Errorf("%w %q", ErrUnknownOption, key)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Publish opens a connection to a broker of the given type, publishes the given
+// message, and then closes the connection.
+func Publish(ctx context.Context, kind string, conf *Config, msg *Message) error {
+	backend, err := getBackend(kind)
+	if err != nil {
+		return err
+	}
+
+	if msg.Topic == "" {
+		return ErrMissingTopic
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, conf.Timeout())
+	defer cancel()
+
+	client, dialErr := backend.Dial(ctx, conf)
+	if dialErr != nil {
+		return fmt.Errorf("failed to connect to the %s broker: %w", kind, dialErr)
+	}
+
+	if err := client.Publish(ctx, msg); err != nil {
+		_ = client.Close() //nolint:errcheck //error is irrelevant at this point
+
+		return fmt.Errorf("failed to publish the message: %w", err)
+	}
+
+	if err := client.Close(); err != nil {
+		return fmt.Errorf("failed to close the %s broker connection: %w", kind, err)
+	}
+
+	return nil
+}
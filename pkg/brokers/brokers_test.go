@@ -0,0 +1,101 @@
+package brokers_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/brokers"
+	"code.waarp.fr/apps/gateway/gateway/pkg/brokers/brokerstest"
+)
+
+func TestValidateConfig(t *testing.T) {
+	t.Parallel()
+
+	kind, _ := brokerstest.MakeDummyBackend(t)
+
+	for name, test := range map[string]struct {
+		kind string
+		conf brokers.Config
+		err  error
+	}{
+		"Valid": {
+			kind: kind,
+			conf: brokers.Config{Address: "localhost", Options: map[string]string{
+				brokers.OptTLS: "true", brokers.OptTimeout: "10s", "foo": "bar",
+			}},
+		},
+		"Unknown type": {
+			kind: "unknown",
+			conf: brokers.Config{Address: "localhost"},
+			err:  brokers.ErrUnknownBrokerType,
+		},
+		"Missing address": {
+			kind: kind,
+			err:  brokers.ErrMissingAddress,
+		},
+		"Unknown option": {
+			kind: kind,
+			conf: brokers.Config{Address: "localhost", Options: map[string]string{"bar": "baz"}},
+			err:  brokers.ErrUnknownOption,
+		},
+		"Invalid TLS option": {
+			kind: kind,
+			conf: brokers.Config{Address: "localhost", Options: map[string]string{brokers.OptTLS: "yes!"}},
+			err:  brokers.ErrInvalidOption,
+		},
+		"Invalid timeout option": {
+			kind: kind,
+			conf: brokers.Config{Address: "localhost", Options: map[string]string{brokers.OptTimeout: "-1s"}},
+			err:  brokers.ErrInvalidOption,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := brokers.ValidateConfig(test.kind, &test.conf)
+			if test.err == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestConfig(t *testing.T) {
+	t.Parallel()
+
+	conf := brokers.Config{}
+	assert.False(t, conf.UseTLS())
+	assert.Equal(t, brokers.DefaultTimeout, conf.Timeout())
+
+	conf.Options = map[string]string{brokers.OptTLS: "true", brokers.OptTimeout: "5s"}
+	assert.True(t, conf.UseTLS())
+	assert.Equal(t, 5*time.Second, conf.Timeout())
+}
+
+func TestAddresses(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t,
+		[]string{"localhost:1234", "example.com:9092", "[::1]:9092"},
+		brokers.Addresses("localhost:1234, example.com,,[::1]", "9092"))
+}
+
+func TestPublish(t *testing.T) {
+	t.Parallel()
+
+	kind, backend := brokerstest.MakeDummyBackend(t)
+	msg := brokers.Message{Topic: "topic", Key: "key", Payload: []byte("payload")}
+
+	require.NoError(t, brokers.Publish(t.Context(), kind, &brokers.Config{}, &msg))
+	assert.Equal(t, []brokers.Message{msg}, backend.Messages())
+
+	require.ErrorIs(t, brokers.Publish(t.Context(), kind, &brokers.Config{},
+		&brokers.Message{}), brokers.ErrMissingTopic)
+	require.ErrorIs(t, brokers.Publish(t.Context(), "unknown", &brokers.Config{},
+		&msg), brokers.ErrUnknownBrokerType)
+}
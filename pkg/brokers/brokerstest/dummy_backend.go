@@ -0,0 +1,73 @@
+// Package brokerstest provides message broker utilities for testing the gateway.
+package brokerstest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/brokers"
+)
+
+var ErrInvalidConfig = errors.New("invalid message broker configuration")
+
+// DummyBackend is a message broker backend which stores the published
+// messages in memory.
+type DummyBackend struct {
+	// Validate is the (optional) function called to validate the backend's
+	// configuration.
+	Validate func(conf *brokers.Config) error
+
+	mu       sync.Mutex
+	messages []brokers.Message
+}
+
+// MakeDummyBackend registers a new dummy message broker backend, and returns
+// its type name along with the backend itself.
+func MakeDummyBackend(tb testing.TB) (string, *DummyBackend) {
+	tb.Helper()
+
+	kind := tb.Name()
+	backend := &DummyBackend{}
+
+	brokers.Register(kind, backend)
+	tb.Cleanup(func() { brokers.Unregister(kind) })
+
+	return kind, backend
+}
+
+func (*DummyBackend) Options() []string { return []string{"foo"} }
+
+func (d *DummyBackend) ValidateConfig(conf *brokers.Config) error {
+	if d.Validate != nil {
+		return d.Validate(conf)
+	}
+
+	return nil
+}
+
+func (d *DummyBackend) Dial(context.Context, *brokers.Config) (brokers.Client, error) {
+	return dummyClient{d}, nil
+}
+
+// Messages returns the messages published on the backend.
+func (d *DummyBackend) Messages() []brokers.Message {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]brokers.Message(nil), d.messages...)
+}
+
+type dummyClient struct{ *DummyBackend }
+
+func (d dummyClient) Publish(_ context.Context, msg *brokers.Message) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.messages = append(d.messages, *msg)
+
+	return nil
+}
+
+func (dummyClient) Close() error { return nil }
@@ -0,0 +1,76 @@
+package brokers
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Addresses splits the given comma-separated list of addresses, and adds the
+// given default port to the addresses which do not specify one.
+func Addresses(address, defaultPort string) []string {
+	var addrs []string
+
+	for _, addr := range strings.Split(address, ",") {
+		addr = strings.TrimSpace(addr)
+		if addr == "" {
+			continue
+		}
+
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			addr = net.JoinHostPort(strings.Trim(addr, "[]"), defaultPort)
+		}
+
+		addrs = append(addrs, addr)
+	}
+
+	return addrs
+}
+
+// DialTCP opens a plain TCP connection to the given address. The context's
+// deadline (if any) is applied to the connection.
+func DialTCP(ctx context.Context, addr string) (net.Conn, error) {
+	var dialer net.Dialer
+
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %q: %w", addr, err)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			_ = conn.Close() //nolint:errcheck //error is irrelevant at this point
+
+			return nil, fmt.Errorf("failed to set the connection deadline: %w", err)
+		}
+	}
+
+	return conn, nil
+}
+
+// TLS returns the TLS configuration to use for the connection, or nil if TLS
+// is disabled. If the configuration has no server name, the host of the given
+// address is used instead.
+func (c *Config) TLS(addr string) *tls.Config {
+	if !c.UseTLS() {
+		return nil
+	}
+
+	tlsConf := &tls.Config{MinVersion: tls.VersionTLS12}
+	if c.TLSConfig != nil {
+		tlsConf = c.TLSConfig.Clone()
+	}
+
+	if tlsConf.ServerName == "" && addr != "" {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			host = addr
+		}
+
+		tlsConf.ServerName = host
+	}
+
+	return tlsConf
+}
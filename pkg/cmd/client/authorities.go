@@ -29,6 +29,12 @@ func displayAuthority(w io.Writer, authority *api.OutAuthority) error {
 	Style22.PrintL(w, "Type", authority.Type)
 	Style22.PrintL(w, "Valid for hosts", withDefault(join(authority.ValidHosts), "<all>"))
 
+	if authority.Type == auth.AuthorityTLS {
+		Style22.PrintL(w, "Revocation policy", withDefault(authority.RevocationPolicy, "<default>"))
+		Style22.PrintL(w, "CRL URL", withDefault(authority.CRLURL, "<from certificates>"))
+		Style22.PrintL(w, "OCSP responder URL", withDefault(authority.OCSPURL, "<from certificates>"))
+	}
+
 	var err error
 
 	switch authority.Type {
@@ -51,6 +57,10 @@ type AuthorityAdd struct {
 	Type           string   `required:"yes" short:"t" long:"type" description:"The type of authority" choice:"tls_authority" choice:"ssh_cert_authority" json:"type,omitempty"`
 	PublicIdentity textFile `required:"yes" short:"i" long:"identity-file" description:"The authority's public identity file" json:"publicIdentity,omitzero"`
 	ValidHosts     []string `short:"h" long:"host" description:"The hosts on which the authority is valid. Can be repeated." json:"validHosts,omitempty"`
+
+	RevocationPolicy string `short:"r" long:"revocation-policy" description:"The revocation checking policy of the certificates issued by the authority (TLS only). Defaults to the global policy." choice:"none" choice:"soft-fail" choice:"hard-fail" json:"revocationPolicy,omitempty"`
+	CRLURL           string `long:"crl-url" description:"The URL (or file path) of the authority's CRL, overriding the certificates' distribution points (TLS only)" json:"crlURL,omitempty"`
+	OCSPURL          string `long:"ocsp-url" description:"The URL of the authority's OCSP responder, overriding the certificates' responders (TLS only)" json:"ocspURL,omitempty"`
 }
 
 func (a *AuthorityAdd) Execute([]string) error { return a.execute(stdOutput) }
@@ -124,6 +134,10 @@ type AuthorityUpdate struct {
 	Type         *string   `short:"t" long:"type" description:"The type of authority" choice:"tls_authority" choice:"ssh_cert_authority" json:"type,omitempty"`
 	IdentityFile *textFile `short:"i" long:"identity-file" description:"The authority's public identity file" json:"publicIdentity,omitempty"`
 	ValidHosts   *[]string `short:"h" long:"host" description:"The hosts on which the authority is valid. Can be repeated. Will replace the existing list. Can be called with an empty host to delete all existing hosts." json:"validHosts,omitempty"`
+
+	RevocationPolicy *string `short:"r" long:"revocation-policy" description:"The revocation checking policy of the certificates issued by the authority (TLS only). Can be called with an empty value to use the global policy." choice:"" choice:"none" choice:"soft-fail" choice:"hard-fail" json:"revocationPolicy,omitempty"`
+	CRLURL           *string `long:"crl-url" description:"The URL (or file path) of the authority's CRL, overriding the certificates' distribution points (TLS only)" json:"crlURL,omitempty"`
+	OCSPURL          *string `long:"ocsp-url" description:"The URL of the authority's OCSP responder, overriding the certificates' responders (TLS only)" json:"ocspURL,omitempty"`
 }
 
 func (a *AuthorityUpdate) Execute([]string) error { return a.execute(stdOutput) }
@@ -38,10 +38,12 @@ func TestAuthorityAdd(t *testing.T) {
 			method: http.MethodPost,
 			path:   path,
 			body: map[string]any{
-				"name":           authorityName,
-				"type":           authorityType,
-				"publicIdentity": authorityIdentity,
-				"validHosts":     []any{authorityHost1, authorityHost2},
+				"name":             authorityName,
+				"type":             authorityType,
+				"publicIdentity":   authorityIdentity,
+				"validHosts":       []any{authorityHost1, authorityHost2},
+				"revocationPolicy": "hard-fail",
+				"ocspURL":          "http://ocsp.example.com",
 			},
 		}
 
@@ -59,6 +61,8 @@ func TestAuthorityAdd(t *testing.T) {
 					"--type", auth.AuthorityTLS,
 					"--identity-file", identityFile,
 					"--host", authorityHost1, "--host", authorityHost2,
+					"--revocation-policy", "hard-fail",
+					"--ocsp-url", "http://ocsp.example.com",
 				), "Then it should not return an error")
 
 				assert.Equal(t,
@@ -94,10 +98,12 @@ func TestAuthorityGet(t *testing.T) {
 		result := &expectedResponse{
 			status: http.StatusOK,
 			body: map[string]any{
-				"name":           authorityName,
-				"type":           authorityType,
-				"publicIdentity": authorityIdentity,
-				"validHosts":     []string{authorityHost1, authorityHost2},
+				"name":             authorityName,
+				"type":             authorityType,
+				"publicIdentity":   authorityIdentity,
+				"validHosts":       []string{authorityHost1, authorityHost2},
+				"revocationPolicy": "hard-fail",
+				"crlURL":           "http://crl.example.com/ca.crl",
 			},
 		}
 
@@ -113,6 +119,9 @@ func TestAuthorityGet(t *testing.T) {
 						`-Authority "{{.name}}"`,
 						`  -Type: {{.type}}`,
 						`  -Valid for hosts: {{ join .validHosts }}`,
+						`  -Revocation policy: {{.revocationPolicy}}`,
+						`  -CRL URL: {{.crlURL}}`,
+						`  -OCSP responder URL: <from certificates>`,
 						`  -Certificate "{{.name}}":`,
 						`    -Subject`,
 						`      -Common Name: `,
@@ -214,6 +223,9 @@ func TestAuthorityList(t *testing.T) {
 						`-Authority "{{.name}}"`,
 						`  -Type: {{.type}}`,
 						`  -Valid for hosts: {{ join .validHosts }}`,
+						`  -Revocation policy: <default>`,
+						`  -CRL URL: <from certificates>`,
+						`  -OCSP responder URL: <from certificates>`,
 						`  -Certificate "{{.name}}":`,
 						`    -Subject`,
 						`      -Common Name: `,
@@ -245,6 +257,9 @@ func TestAuthorityList(t *testing.T) {
 						`-Authority "{{.name}}"`,
 						`  -Type: {{.type}}`,
 						`  -Valid for hosts: {{ join .validHosts }}`,
+						`  -Revocation policy: <default>`,
+						`  -CRL URL: <from certificates>`,
+						`  -OCSP responder URL: <from certificates>`,
 						`  -Certificate "{{.name}}":`,
 						`    -Subject`,
 						`      -Common Name: foo`,
@@ -0,0 +1,158 @@
+package wg
+
+import (
+	"fmt"
+	"io"
+	"path"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/admin/rest/api"
+)
+
+const brokersAPIPath = "/api/brokers"
+
+func displayBrokers(w io.Writer, brokers []*api.GetBrokerRespObject) error {
+	Style0.Printf(w, "=== Message brokers ===")
+	for _, broker := range brokers {
+		if err := displayBroker(w, broker); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func displayBroker(w io.Writer, broker *api.GetBrokerRespObject) error {
+	Style1.Printf(w, "Message broker %q (%s)", broker.Name, broker.Type)
+	Style22.PrintL(w, "Address", broker.Address)
+	Style22.PrintL(w, "Login", withDefault(broker.Login, none))
+
+	if len(broker.Options) == 0 {
+		Style22.PrintL(w, "Options", none)
+	} else {
+		Style22.Printf(w, "Options:")
+		displayMap(w, Style333, broker.Options)
+	}
+
+	return nil
+}
+
+type BrokerGet struct {
+	OutputFormat
+
+	Args struct {
+		Name string `required:"yes" positional-arg-name:"name" description:"The name of the message broker"`
+	} `positional-args:"yes"`
+}
+
+func (b *BrokerGet) Execute([]string) error { return execute(b) }
+func (b *BrokerGet) execute(w io.Writer) error {
+	addr.Path = path.Join(brokersAPIPath, b.Args.Name)
+
+	broker := &api.GetBrokerRespObject{}
+	if err := get(broker); err != nil {
+		return err
+	}
+
+	return outputObject(w, broker, &b.OutputFormat, displayBroker)
+}
+
+//nolint:lll //tags can be long for flags
+type BrokerAdd struct {
+	Name     string             `required:"yes" short:"n" long:"name" description:"The name of the message broker" json:"name,omitempty"`
+	Type     string             `required:"yes" short:"t" long:"type" description:"The type of the message broker" json:"type,omitempty"`
+	Address  string             `required:"yes" short:"a" long:"address" description:"The address(es) of the message broker" json:"address,omitempty"`
+	Login    string             `short:"l" long:"login" description:"The login used to authenticate on the message broker" json:"login,omitempty"`
+	Password string             `short:"p" long:"password" description:"The password used to authenticate on the message broker" json:"password,omitempty"`
+	Options  map[string]confVal `short:"o" long:"options" description:"The options of the message broker, in key:val format. Can be repeated." json:"options,omitempty"`
+}
+
+func (b *BrokerAdd) Execute([]string) error { return execute(b) }
+func (b *BrokerAdd) execute(w io.Writer) error {
+	addr.Path = brokersAPIPath
+
+	if _, err := add(w, b); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "The message broker %q was successfully added.\n", b.Name)
+
+	return nil
+}
+
+type BrokerDelete struct {
+	Args struct {
+		Name string `required:"yes" positional-arg-name:"name" description:"The name of the message broker"`
+	} `positional-args:"yes"`
+}
+
+func (b *BrokerDelete) Execute([]string) error { return execute(b) }
+func (b *BrokerDelete) execute(w io.Writer) error {
+	addr.Path = path.Join(brokersAPIPath, b.Args.Name)
+
+	if err := remove(w); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "The message broker %q was successfully deleted.\n", b.Args.Name)
+
+	return nil
+}
+
+//nolint:lll //tags can be long for flags
+type BrokerUpdate struct {
+	Args struct {
+		Name string `required:"yes" positional-arg-name:"name" description:"The name of the message broker"`
+	} `positional-args:"yes" json:"-"`
+
+	Name     *string             `short:"n" long:"name" description:"The name of the message broker" json:"name,omitempty"`
+	Type     *string             `short:"t" long:"type" description:"The type of the message broker" json:"type,omitempty"`
+	Address  *string             `short:"a" long:"address" description:"The address(es) of the message broker" json:"address,omitempty"`
+	Login    *string             `short:"l" long:"login" description:"The login used to authenticate on the message broker" json:"login,omitempty"`
+	Password *string             `short:"p" long:"password" description:"The password used to authenticate on the message broker" json:"password,omitempty"`
+	Options  *map[string]confVal `short:"o" long:"options" description:"The options of the message broker, in key:val format. Can be repeated." json:"options,omitempty"`
+}
+
+func (b *BrokerUpdate) Execute([]string) error { return execute(b) }
+func (b *BrokerUpdate) execute(w io.Writer) error {
+	addr.Path = path.Join(brokersAPIPath, b.Args.Name)
+
+	if err := update(w, b); err != nil {
+		return err
+	}
+
+	finalName := b.Args.Name
+	if b.Name != nil && *b.Name != "" {
+		finalName = *b.Name
+	}
+
+	fmt.Fprintf(w, "The message broker %q was successfully updated.\n", finalName)
+
+	return nil
+}
+
+//nolint:lll //tags can be long for flags
+type BrokerList struct {
+	ListOptions
+
+	SortBy string `short:"s" long:"sort" description:"The property to sort by." choice:"name+" choice:"name-" choice:"type+" choice:"type-" default:"name+"`
+}
+
+func (b *BrokerList) Execute([]string) error { return execute(b) }
+func (b *BrokerList) execute(w io.Writer) error {
+	addr.Path = brokersAPIPath
+
+	listURL(&b.ListOptions, b.SortBy)
+
+	body := map[string][]*api.GetBrokerRespObject{}
+	if err := list(&body); err != nil {
+		return err
+	}
+
+	if brokers := body["brokers"]; brokers != nil {
+		return outputObject(w, brokers, &b.OutputFormat, displayBrokers)
+	}
+
+	fmt.Fprintf(w, "No message brokers found.\n")
+
+	return nil
+}
@@ -0,0 +1,276 @@
+package wg
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBrokerGet(t *testing.T) {
+	const (
+		brokerName = "broker_name"
+		brokerType = "broker_type"
+		brokerAddr = "localhost:4222"
+
+		opt1, val1 = "opt1", "val1"
+
+		path = brokersAPIPath + "/" + brokerName
+	)
+
+	t.Run(`Testing the broker "get" command`, func(t *testing.T) {
+		w := &strings.Builder{}
+		command := &BrokerGet{}
+
+		expected := &expectedRequest{
+			method: http.MethodGet,
+			path:   path,
+		}
+
+		response := &expectedResponse{
+			status: http.StatusOK,
+			body: map[string]any{
+				"name":    brokerName,
+				"type":    brokerType,
+				"address": brokerAddr,
+				"options": map[string]string{opt1: val1},
+			},
+		}
+
+		t.Run("Given a dummy gateway REST interface", func(t *testing.T) {
+			testServer(t, expected, response)
+
+			t.Run("When executing the command", func(t *testing.T) {
+				require.NoError(t, executeCommand(t, w, command, brokerName),
+					"Then it should not return an error")
+
+				assert.Equal(t,
+					expectedOutput(t, response.body,
+						`-Message broker "{{.name}}" ({{.type}})`,
+						`  -Address: {{.address}}`,
+						`  -Login: <none>`,
+						`  -Options:`,
+						`    {{- range $option, $value := .options }}`,
+						`    -{{$option}}: {{$value}}`,
+						`    {{- end }}`,
+					),
+					w.String(),
+					"Then it should display the message broker's info",
+				)
+			})
+		})
+	})
+}
+
+func TestBrokerAdd(t *testing.T) {
+	const (
+		brokerName = "broker_name"
+		brokerType = "broker_type"
+		brokerAddr = "localhost:4222"
+		brokerUser = "broker_login"
+		brokerPswd = "broker_password"
+
+		opt1, val1 = "opt1", "val1"
+
+		location = brokersAPIPath + "/" + brokerName
+	)
+
+	t.Run(`Testing the broker "add" command`, func(t *testing.T) {
+		w := &strings.Builder{}
+		command := &BrokerAdd{}
+
+		expRequest := &expectedRequest{
+			method: http.MethodPost,
+			path:   brokersAPIPath,
+			body: map[string]any{
+				"name":     brokerName,
+				"type":     brokerType,
+				"address":  brokerAddr,
+				"login":    brokerUser,
+				"password": brokerPswd,
+				"options":  map[string]any{opt1: val1},
+			},
+		}
+		expResponse := &expectedResponse{
+			status:  http.StatusCreated,
+			headers: map[string][]string{"Location": {location}},
+		}
+
+		testServer(t, expRequest, expResponse)
+
+		t.Run("When executing the command", func(t *testing.T) {
+			require.NoError(t, executeCommand(t, w, command,
+				"--name", brokerName,
+				"--type", brokerType,
+				"--address", brokerAddr,
+				"--login", brokerUser,
+				"--password", brokerPswd,
+				"--options", fmt.Sprintf("%s:%s", opt1, val1),
+			),
+				"Then it should not return an error",
+			)
+
+			assert.Equal(t,
+				fmt.Sprintf("The message broker %q was successfully added.\n", brokerName),
+				w.String(),
+				"Then it should display a message saying the message broker was added",
+			)
+		})
+	})
+}
+
+func TestBrokerDelete(t *testing.T) {
+	const (
+		brokerName = "broker_name"
+
+		path = brokersAPIPath + "/" + brokerName
+	)
+
+	t.Run(`Testing the broker "delete" command`, func(t *testing.T) {
+		w := &strings.Builder{}
+		command := &BrokerDelete{}
+
+		expected := &expectedRequest{
+			method: http.MethodDelete,
+			path:   path,
+		}
+
+		response := &expectedResponse{status: http.StatusNoContent}
+
+		t.Run("Given a dummy gateway REST interface", func(t *testing.T) {
+			testServer(t, expected, response)
+
+			t.Run("When executing the command", func(t *testing.T) {
+				require.NoError(t, executeCommand(t, w, command, brokerName),
+					"Then it should not return an error")
+
+				assert.Equal(t,
+					fmt.Sprintf("The message broker %q was successfully deleted.\n", brokerName),
+					w.String(),
+					"Then it should display a message saying the message broker was deleted",
+				)
+			})
+		})
+	})
+}
+
+func TestBrokerUpdate(t *testing.T) {
+	const (
+		oldBrokerName = "old_broker_name"
+
+		brokerName = "broker_name"
+		brokerAddr = "localhost:4222"
+
+		path     = brokersAPIPath + "/" + oldBrokerName
+		location = brokersAPIPath + "/" + brokerName
+	)
+
+	t.Run(`Testing the broker "update" command`, func(t *testing.T) {
+		w := &strings.Builder{}
+		command := &BrokerUpdate{}
+
+		expected := &expectedRequest{
+			method: http.MethodPatch,
+			path:   path,
+			body: map[string]any{
+				"name":    brokerName,
+				"address": brokerAddr,
+			},
+		}
+		response := &expectedResponse{
+			status:  http.StatusCreated,
+			headers: map[string][]string{"Location": {location}},
+		}
+
+		t.Run("Given a dummy gateway REST interface", func(t *testing.T) {
+			testServer(t, expected, response)
+
+			t.Run("When executing the command", func(t *testing.T) {
+				require.NoError(t, executeCommand(t, w, command,
+					oldBrokerName,
+					"--name", brokerName,
+					"--address", brokerAddr,
+				),
+					"Then it should not return an error",
+				)
+
+				assert.Equal(t,
+					fmt.Sprintf("The message broker %q was successfully updated.\n", brokerName),
+					w.String(),
+					"Then it should display a message saying the message broker was updated",
+				)
+			})
+		})
+	})
+}
+
+func TestBrokerList(t *testing.T) {
+	const (
+		sort   = "name-"
+		limit  = "10"
+		offset = "5"
+	)
+
+	t.Run(`Testing the broker "list" command`, func(t *testing.T) {
+		w := &strings.Builder{}
+		command := &BrokerList{}
+
+		expected := &expectedRequest{
+			method: http.MethodGet,
+			path:   brokersAPIPath,
+			values: url.Values{
+				"sort":   {sort},
+				"limit":  {limit},
+				"offset": {offset},
+			},
+		}
+
+		brokers := []map[string]any{
+			{"name": "broker1", "type": "nats", "address": "localhost:4222"},
+			{"name": "broker2", "type": "kafka", "address": "localhost:9092", "login": "user"},
+		}
+
+		response := &expectedResponse{
+			status: http.StatusOK,
+			body:   map[string]any{"brokers": brokers},
+		}
+
+		t.Run("Given a dummy gateway REST interface", func(t *testing.T) {
+			testServer(t, expected, response)
+
+			t.Run("When executing the command", func(t *testing.T) {
+				require.NoError(t, executeCommand(t, w, command,
+					"--sort", sort,
+					"--limit", limit,
+					"--offset", offset,
+				),
+					"Then it should not return an error",
+				)
+
+				assert.Equal(t,
+					expectedOutput(t, brokers,
+						`=== Message brokers ===`,
+						`{{- with index . 0 }}`,
+						`-Message broker "{{.name}}" ({{.type}})`,
+						`  -Address: {{.address}}`,
+						`  -Login: <none>`,
+						`  -Options: <none>`,
+						`{{- end }}`,
+						`{{- with index . 1 }}`,
+						`-Message broker "{{.name}}" ({{.type}})`,
+						`  -Address: {{.address}}`,
+						`  -Login: {{.login}}`,
+						`  -Options: <none>`,
+						`{{- end }}`,
+					),
+					w.String(),
+					"Then it should display the message brokers",
+				)
+			})
+		})
+	})
+}
@@ -0,0 +1,58 @@
+package wg
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"time"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/admin/rest/api"
+)
+
+func displayExpiringCerts(w io.Writer, certs []*api.GetExpiringCertRespObject) error {
+	Style0.Printf(w, "=== Expiring certificates ===")
+
+	for _, cert := range certs {
+		displayExpiringCert(w, cert)
+	}
+
+	return nil
+}
+
+func displayExpiringCert(w io.Writer, cert *api.GetExpiringCertRespObject) {
+	Style1.Printf(w, "Certificate %q", cert.Name)
+	Style22.PrintL(w, "Kind", cert.Kind)
+	Style22.PrintL(w, "Owner", withDefault(cert.Owner, none))
+	Style22.PrintL(w, "Type", cert.Type)
+	Style22.PrintL(w, "Expires on", cert.ExpiresAt.Format(time.RFC1123))
+	Style22.PrintL(w, "Days left", cert.DaysLeft)
+}
+
+// ######################## EXPIRING ##########################
+
+//nolint:lll //flag tags are long
+type CertExpiring struct {
+	OutputFormat
+
+	Within string `short:"w" long:"within" description:"List the certificates & keys expiring within this duration (ex: 12h, 30d, 2w, 6mo)" default:"30d"`
+}
+
+func (c *CertExpiring) Execute([]string) error { return c.execute(stdOutput) }
+
+func (c *CertExpiring) execute(w io.Writer) error {
+	addr.Path = "/api/certificates/expiring"
+	addr.RawQuery = url.Values{"within": {c.Within}}.Encode()
+
+	body := map[string][]*api.GetExpiringCertRespObject{}
+	if err := list(&body); err != nil {
+		return err
+	}
+
+	if certs := body["certificates"]; len(certs) > 0 {
+		return outputObject(w, certs, &c.OutputFormat, displayExpiringCerts)
+	}
+
+	fmt.Fprintf(w, "No certificates expiring within %s found.\n", c.Within)
+
+	return nil
+}
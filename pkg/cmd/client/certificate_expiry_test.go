@@ -0,0 +1,82 @@
+package wg
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/authentication/auth"
+)
+
+func TestCertExpiring(t *testing.T) {
+	const (
+		within = "15d"
+		path   = "/api/certificates/expiring"
+	)
+
+	t.Run(`Testing the certificate "expiring" command`, func(t *testing.T) {
+		w := newTestOutput()
+		command := &CertExpiring{}
+
+		expected := &expectedRequest{
+			method: http.MethodGet,
+			path:   path,
+			values: url.Values{"within": []string{within}},
+		}
+
+		certs := []map[string]any{{
+			"kind":      "authority",
+			"name":      "authority",
+			"type":      auth.AuthorityTLS,
+			"expiresAt": "2026-01-02T03:04:05Z",
+			"daysLeft":  3,
+		}, {
+			"kind":      "credential",
+			"owner":     `server "sftp"`,
+			"name":      "sftp-cert",
+			"type":      auth.TLSCertificate,
+			"expiresAt": "2026-01-10T00:00:00Z",
+			"daysLeft":  10,
+		}}
+
+		result := &expectedResponse{
+			status: http.StatusOK,
+			body:   map[string]any{"certificates": certs},
+		}
+
+		t.Run("Given a dummy gateway REST interface", func(t *testing.T) {
+			testServer(t, expected, result)
+
+			t.Run("When executing the command", func(t *testing.T) {
+				require.NoError(t, executeCommand(t, w, command, "--within", within))
+
+				assert.Equal(t,
+					expectedOutput(t, certs,
+						"=== Expiring certificates ===",
+						`{{- with (index . 0) }}`,
+						`-Certificate "{{.name}}"`,
+						`  -Kind: {{.kind}}`,
+						`  -Owner: <none>`,
+						`  -Type: {{.type}}`,
+						`  -Expires on: Fri, 02 Jan 2026 03:04:05 UTC`,
+						`  -Days left: {{.daysLeft}}`,
+						`{{- end }}`,
+						`{{- with (index . 1) }}`,
+						`-Certificate "{{.name}}"`,
+						`  -Kind: {{.kind}}`,
+						`  -Owner: {{.owner}}`,
+						`  -Type: {{.type}}`,
+						`  -Expires on: Sat, 10 Jan 2026 00:00:00 UTC`,
+						`  -Days left: {{.daysLeft}}`,
+						`{{- end }}`,
+					),
+					w.String(),
+					"Then it should display the expiring certificates",
+				)
+			})
+		})
+	})
+}
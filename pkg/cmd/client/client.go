@@ -282,3 +282,25 @@ func (c *ClientRestart) execute(w io.Writer) error {
 
 	return nil
 }
+
+//nolint:lll //flag tags are long
+type ClientDrain struct {
+	Args struct {
+		Name string `required:"yes" positional-arg-name:"name" description:"The client's name"`
+	} `positional-args:"yes"`
+	Deadline string `short:"d" long:"deadline" description:"The maximum time given to the running transfers to end before stopping the client (ex: 30s, 10m, 1h)"`
+}
+
+func (c *ClientDrain) Execute([]string) error { return execute(c) }
+func (c *ClientDrain) execute(w io.Writer) error {
+	addr.RawQuery = drainQuery(c.Deadline)
+
+	if err := exec(w, fmt.Sprintf("/api/clients/%s/drain", c.Args.Name)); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "The client %q is being drained. It will be stopped once its "+
+		"running transfers are done.\n", c.Args.Name)
+
+	return nil
+}
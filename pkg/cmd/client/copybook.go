@@ -0,0 +1,85 @@
+package wg
+
+import (
+	"io"
+)
+
+//nolint:gochecknoglobals //constant struct
+var copybookKind = &storedTextKind{
+	name:    "copybook",
+	title:   "Copybook",
+	plural:  "copybooks",
+	apiPath: "/api/copybooks",
+}
+
+// ######################## ADD ##########################
+
+//nolint:lll //struct tags are long
+type CopybookAdd struct {
+	Name    string          `required:"yes" short:"n" long:"name" description:"The copybook's name" json:"name,omitempty"`
+	Content textFileOrValue `required:"yes" short:"c" long:"content" description:"The copybook's content, or the path of a file containing it" json:"content,omitzero"`
+}
+
+func (s *CopybookAdd) Execute([]string) error { return execute(s) }
+func (s *CopybookAdd) execute(w io.Writer) error {
+	return copybookKind.add(w, s, s.Name)
+}
+
+// ######################## LIST ##########################
+
+//nolint:lll //struct tags are long
+type CopybookList struct {
+	ListOptions
+
+	SortBy string `short:"s" long:"sort" description:"Attribute used to sort the returned entries" choice:"name+" choice:"name-" default:"name+"`
+}
+
+func (s *CopybookList) Execute([]string) error { return execute(s) }
+func (s *CopybookList) execute(w io.Writer) error {
+	return copybookKind.list(w, &s.ListOptions, s.SortBy)
+}
+
+// ######################## GET ##########################
+
+type CopybookGet struct {
+	OutputFormat
+
+	Args struct {
+		Name string `required:"yes" positional-arg-name:"name" description:"The copybook's name"`
+	} `positional-args:"yes"`
+}
+
+func (s *CopybookGet) Execute([]string) error { return execute(s) }
+func (s *CopybookGet) execute(w io.Writer) error {
+	return copybookKind.get(w, s.Args.Name, &s.OutputFormat)
+}
+
+// ######################## UPDATE ##########################
+
+//nolint:lll //struct tags are long
+type CopybookUpdate struct {
+	Args struct {
+		Name string `required:"yes" positional-arg-name:"name" description:"The old copybook's name"`
+	} `positional-args:"yes" json:"-"`
+
+	Name    string          `short:"n" long:"name" description:"The copybook's name" json:"name,omitempty"`
+	Content textFileOrValue `short:"c" long:"content" description:"The copybook's content, or the path of a file containing it" json:"content,omitzero"`
+}
+
+func (s *CopybookUpdate) Execute([]string) error { return execute(s) }
+func (s *CopybookUpdate) execute(w io.Writer) error {
+	return copybookKind.update(w, s, s.Args.Name, s.Name)
+}
+
+// ######################## DELETE ##########################
+
+type CopybookDelete struct {
+	Args struct {
+		Name string `required:"yes" positional-arg-name:"name" description:"The copybook's name"`
+	} `positional-args:"yes"`
+}
+
+func (s *CopybookDelete) Execute([]string) error { return execute(s) }
+func (s *CopybookDelete) execute(w io.Writer) error {
+	return copybookKind.remove(w, s.Args.Name)
+}
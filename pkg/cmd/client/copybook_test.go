@@ -0,0 +1,248 @@
+package wg
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopybookAdd(t *testing.T) {
+	const (
+		copybookName    = "copybook"
+		copybookContent = `01 REC. 05 NAME PIC X(10).`
+
+		path     = "/api/copybooks"
+		location = path + "/" + copybookName
+	)
+
+	t.Run(`Given the copybook "add" command`, func(t *testing.T) {
+		w := newTestOutput()
+		command := &CopybookAdd{}
+
+		expected := &expectedRequest{
+			method: http.MethodPost,
+			path:   path,
+			body: map[string]any{
+				"name":    copybookName,
+				"content": copybookContent,
+			},
+		}
+
+		result := &expectedResponse{
+			status:  http.StatusCreated,
+			headers: map[string][]string{"Location": {location}},
+		}
+
+		t.Run("Given a dummy gateway REST interface", func(t *testing.T) {
+			testServer(t, expected, result)
+
+			t.Run("When executing the command", func(t *testing.T) {
+				require.NoError(t, executeCommand(t, w, command,
+					"--name", copybookName,
+					"--content", copybookContent,
+				),
+					"Then it should not return an error",
+				)
+
+				assert.Equal(t,
+					fmt.Sprintf("The copybook %q was successfully added.\n", copybookName),
+					w.String(),
+					"Then it should display a message saying the copybook was added",
+				)
+			})
+		})
+	})
+}
+
+func TestCopybookList(t *testing.T) {
+	const (
+		path = "/api/copybooks"
+
+		sort   = "name-"
+		limit  = "10"
+		offset = "5"
+	)
+
+	t.Run(`Given the copybook "list" command`, func(t *testing.T) {
+		w := newTestOutput()
+		command := &CopybookList{}
+
+		expected := &expectedRequest{
+			method: http.MethodGet,
+			values: map[string][]string{
+				"limit":  {limit},
+				"offset": {offset},
+				"sort":   {sort},
+			},
+			path: path,
+		}
+
+		copybooks := []map[string]any{
+			{"name": "copybook2", "content": `01 REC. 05 CODE PIC 9(5).`},
+			{"name": "copybook1", "content": `01 REC. 05 NAME PIC X(10).`},
+		}
+
+		result := &expectedResponse{
+			status: http.StatusOK,
+			body:   map[string]any{"copybooks": copybooks},
+		}
+
+		t.Run("Given a dummy gateway REST interface", func(t *testing.T) {
+			testServer(t, expected, result)
+
+			t.Run("When executing the command", func(t *testing.T) {
+				require.NoError(t, executeCommand(t, w, command,
+					"--limit", limit, "--offset", offset, "--sort", sort,
+				),
+					"Then it should not return an error",
+				)
+
+				assert.Equal(t,
+					expectedOutput(t, copybooks,
+						`=== Copybooks ===`,
+						`{{- range . }}`,
+						`-Copybook "{{.name}}"`,
+						`  -Content: {{.content}}`,
+						`{{- end }}`,
+					),
+					w.String(),
+					"Then it should display the copybooks",
+				)
+			})
+		})
+	})
+}
+
+func TestCopybookGet(t *testing.T) {
+	const (
+		copybookName    = "copybook"
+		copybookContent = `01  REC.
+    05  NAME  PIC X(10).`
+
+		path = "/api/copybooks/" + copybookName
+	)
+
+	t.Run(`Given the copybook "get" command`, func(t *testing.T) {
+		w := newTestOutput()
+		command := &CopybookGet{}
+
+		expected := &expectedRequest{
+			method: http.MethodGet,
+			path:   path,
+		}
+
+		result := &expectedResponse{
+			status: http.StatusOK,
+			body: map[string]any{
+				"name":    copybookName,
+				"content": copybookContent,
+			},
+		}
+
+		t.Run("Given a dummy gateway REST interface", func(t *testing.T) {
+			testServer(t, expected, result)
+
+			t.Run("When executing the command", func(t *testing.T) {
+				require.NoError(t, executeCommand(t, w, command, copybookName),
+					"Then it should not return an error")
+
+				assert.Equal(t,
+					expectedOutput(t, result.body,
+						`-Copybook "{{.name}}"`,
+						`  -Content: {{- lines .content 5}}`,
+					),
+					w.String(),
+					"Then it should display the copybook",
+				)
+			})
+		})
+	})
+}
+
+func TestCopybookUpdate(t *testing.T) {
+	const (
+		oldCopybookName    = "old_copybook"
+		newCopybookName    = "new_copybook"
+		newCopybookContent = `01 REC. 05 NAME PIC X(10).`
+
+		path     = "/api/copybooks/" + oldCopybookName
+		location = "/api/copybooks/" + newCopybookName
+	)
+
+	t.Run(`Given the copybook "update" command`, func(t *testing.T) {
+		w := newTestOutput()
+		command := &CopybookUpdate{}
+
+		expected := &expectedRequest{
+			method: http.MethodPatch,
+			path:   path,
+			body: map[string]any{
+				"name":    newCopybookName,
+				"content": newCopybookContent,
+			},
+		}
+
+		result := &expectedResponse{
+			status:  http.StatusCreated,
+			headers: map[string][]string{"Location": {location}},
+		}
+
+		t.Run("Given a dummy gateway REST interface", func(t *testing.T) {
+			testServer(t, expected, result)
+
+			t.Run("When executing the command", func(t *testing.T) {
+				require.NoError(t, executeCommand(t, w, command, oldCopybookName,
+					"--name", newCopybookName,
+					"--content", newCopybookContent,
+				),
+					"Then it should not return an error",
+				)
+
+				assert.Equal(t,
+					fmt.Sprintf("The copybook %q was successfully updated.\n", newCopybookName),
+					w.String(),
+					"Then it should display a message saying the copybook was updated",
+				)
+			})
+		})
+	})
+}
+
+func TestCopybookDelete(t *testing.T) {
+	const (
+		copybookName = "copybook"
+		path         = "/api/copybooks/" + copybookName
+	)
+
+	t.Run(`Given the copybook "delete" command`, func(t *testing.T) {
+		w := newTestOutput()
+		command := &CopybookDelete{}
+
+		expected := &expectedRequest{
+			method: http.MethodDelete,
+			path:   path,
+		}
+
+		result := &expectedResponse{
+			status: http.StatusNoContent,
+		}
+
+		t.Run("Given a dummy gateway REST interface", func(t *testing.T) {
+			testServer(t, expected, result)
+
+			t.Run("When executing the command", func(t *testing.T) {
+				require.NoError(t, executeCommand(t, w, command, copybookName),
+					"Then it should not return an error")
+
+				assert.Equal(t,
+					fmt.Sprintf("The copybook %q was successfully deleted.\n", copybookName),
+					w.String(),
+					"Then it should display a message saying the copybook was deleted",
+				)
+			})
+		})
+	})
+}
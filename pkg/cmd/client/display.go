@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"sort"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	"github.com/dustin/go-humanize"
@@ -195,10 +196,33 @@ func mapFlatten(m map[string][]string) string {
 }
 
 func displayTask(w io.Writer, index int, task *api.Task) {
+	header := fmt.Sprintf("Task #%d %q", index+1, task.Type)
+
+	if task.Condition != "" {
+		header += " when " + task.Condition
+	}
+
+	if task.OnError != "" {
+		header += " (on error: " + task.OnError + ")"
+	}
+
+	if task.Retries > 0 {
+		header += fmt.Sprintf(" (retries: %d, delay: %s)", task.Retries,
+			time.Duration(task.RetryDelay))
+	}
+
+	if task.Timeout > 0 {
+		header += fmt.Sprintf(" (timeout: %s)", time.Duration(task.Timeout))
+	}
+
+	if task.ParallelGroup != "" {
+		header += fmt.Sprintf(" (parallel group: %s)", task.ParallelGroup)
+	}
+
 	if len(task.Args) == 0 {
-		Style333.Printf(w, "Task #%d %q", index+1, task.Type)
+		Style333.PrintV(w, header)
 	} else {
-		Style333.Printf(w, "Task #%d %q with args:", index+1, task.Type)
+		Style333.PrintV(w, header+" with args:")
 		displayMap(w, Style4444, task.Args)
 	}
 }
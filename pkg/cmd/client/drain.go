@@ -0,0 +1,34 @@
+package wg
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+)
+
+func drainQuery(deadline string) string {
+	if deadline == "" {
+		return ""
+	}
+
+	return url.Values{"deadline": {deadline}}.Encode()
+}
+
+//nolint:lll //flag tags are long
+type Drain struct {
+	Deadline string `short:"d" long:"deadline" description:"The maximum time given to the running transfers to end before stopping the servers & clients (ex: 30s, 10m, 1h)"`
+}
+
+func (d *Drain) Execute([]string) error { return execute(d) }
+func (d *Drain) execute(w io.Writer) error {
+	addr.RawQuery = drainQuery(d.Deadline)
+
+	if err := exec(w, "/api/drain"); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w, "The gateway is being drained. Its servers & clients will be "+
+		"stopped once the running transfers are done.")
+
+	return nil
+}
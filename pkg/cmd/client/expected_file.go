@@ -0,0 +1,261 @@
+package wg
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/admin/rest/api"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils"
+)
+
+const expectedFilesAPIPath = "/api/expected_files"
+
+func displayExpectedFiles(w io.Writer, files []*api.OutExpectedFile) error {
+	Style0.PrintV(w, "=== Expected files ===")
+
+	for _, file := range files {
+		if err := displayExpectedFile(w, file); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func displayExpectedFile(w io.Writer, file *api.OutExpectedFile) error {
+	account := ""
+	if file.Account != "" {
+		account = fmt.Sprintf("%s (server %q)", file.Account, file.Server)
+	}
+
+	Style1.Printf(w, "Expected file %q [%s]", file.Name, coloredEnabled(!file.Disabled))
+	Style22.PrintL(w, "Rule", file.Rule)
+	Style22.Defaul(w, "Partner", file.Partner, none)
+	Style22.Defaul(w, "Local account", account, none)
+	Style22.PrintL(w, "Pattern", file.Pattern)
+	Style22.PrintL(w, "Window", fmt.Sprintf("%s - %s (%s)", file.WindowStart,
+		file.WindowEnd, join(file.Days)))
+
+	if len(file.EmailAlert) == 0 {
+		Style22.PrintL(w, "Email alert", none)
+	} else {
+		Style22.Printf(w, "Email alert:")
+		displayMap(w, Style333, file.EmailAlert)
+	}
+
+	if file.LastCheck == nil {
+		Style22.PrintL(w, "Last check", none)
+
+		return nil
+	}
+
+	Style22.Printf(w, "Last check:")
+	displayExpectedFileCheckInfo(w, Style333, file.LastCheck)
+
+	return nil
+}
+
+func displayExpectedFileChecks(w io.Writer, checks []*api.OutExpectedFileCheck) error {
+	Style0.PrintV(w, "=== Expected file checks ===")
+
+	for _, check := range checks {
+		Style1.Printf(w, "Window of %s [%s]", check.WindowStart.Local().String(), check.Status)
+		displayExpectedFileCheckInfo(w, Style22, check)
+	}
+
+	return nil
+}
+
+func displayExpectedFileCheckInfo(w io.Writer, style *style, check *api.OutExpectedFileCheck) {
+	style.PrintL(w, "Status", check.Status)
+	style.PrintL(w, "Window start", check.WindowStart.Local().String())
+	style.PrintL(w, "Window end", check.WindowEnd.Local().String())
+	style.Defaul(w, "Files", join(check.Files), none)
+	style.PrintL(w, "Checked", check.Checked.Local().String())
+}
+
+// ######################## ADD ##########################
+
+//nolint:lll //struct tags are long
+type ExpectedFileAdd struct {
+	Name        string            `required:"yes" short:"n" long:"name" description:"The expected file's name" json:"name,omitempty"`
+	Disabled    bool              `long:"disabled" description:"Create the expected file in disabled state" json:"disabled,omitempty"`
+	Rule        string            `required:"yes" short:"r" long:"rule" description:"The reception rule with which the file is expected" json:"rule,omitempty"`
+	Partner     string            `short:"p" long:"partner" description:"The partner from which the file is expected" json:"partner,omitempty"`
+	Server      string            `short:"s" long:"server" description:"The local server of the account from which the file is expected" json:"server,omitempty"`
+	Account     string            `short:"a" long:"account" description:"The local account from which the file is expected" json:"account,omitempty"`
+	Pattern     string            `short:"P" long:"pattern" description:"The pattern the received file's name must match. Defaults to '*'" json:"pattern,omitempty"`
+	WindowStart string            `required:"yes" short:"b" long:"window-start" description:"The time of day (in HH:MM format) at which the reception window opens" json:"windowStart,omitempty"`
+	WindowEnd   string            `required:"yes" short:"e" long:"window-end" description:"The time of day (in HH:MM format) at which the reception window closes" json:"windowEnd,omitempty"`
+	Days        []string          `short:"D" long:"day" description:"A day of the week on which the file is expected. Can be repeated. Defaults to monday through friday" json:"days,omitempty"`
+	EmailAlert  map[string]string `short:"m" long:"email-alert" description:"An argument of the EMAIL task sent when the file is missing or duplicated, in key:val format. Can be repeated." json:"emailAlert,omitempty"`
+}
+
+func (e *ExpectedFileAdd) Execute([]string) error { return execute(e) }
+func (e *ExpectedFileAdd) execute(w io.Writer) error {
+	addr.Path = expectedFilesAPIPath
+
+	if _, err := add(w, e); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "The expected file %q was successfully added.\n", e.Name)
+
+	return nil
+}
+
+// ######################## LIST ##########################
+
+//nolint:lll //struct tags are long
+type ExpectedFileList struct {
+	ListOptions
+
+	SortBy string `short:"s" long:"sort" description:"Attribute used to sort the returned entries" choice:"name+" choice:"name-" default:"name+"`
+}
+
+func (e *ExpectedFileList) Execute([]string) error { return execute(e) }
+func (e *ExpectedFileList) execute(w io.Writer) error {
+	addr.Path = expectedFilesAPIPath
+
+	listURL(&e.ListOptions, e.SortBy)
+
+	var body map[string][]*api.OutExpectedFile
+	if err := list(&body); err != nil {
+		return err
+	}
+
+	if files := body["expectedFiles"]; len(files) > 0 {
+		return outputObject(w, files, &e.OutputFormat, displayExpectedFiles)
+	}
+
+	fmt.Fprintln(w, "No expected files found.")
+
+	return nil
+}
+
+// ######################## GET ##########################
+
+type ExpectedFileGet struct {
+	OutputFormat
+
+	Args struct {
+		Name string `required:"yes" positional-arg-name:"name" description:"The expected file's name"`
+	} `positional-args:"yes"`
+}
+
+func (e *ExpectedFileGet) Execute([]string) error { return execute(e) }
+func (e *ExpectedFileGet) execute(w io.Writer) error {
+	addr.Path = path.Join(expectedFilesAPIPath, e.Args.Name)
+
+	var file api.OutExpectedFile
+	if err := get(&file); err != nil {
+		return err
+	}
+
+	return outputObject(w, &file, &e.OutputFormat, displayExpectedFile)
+}
+
+// ######################## UPDATE ##########################
+
+//nolint:lll //struct tags are long
+type ExpectedFileUpdate struct {
+	Args struct {
+		Name string `required:"yes" positional-arg-name:"name" description:"The old expected file's name"`
+	} `positional-args:"yes" json:"-"`
+
+	Name        *string            `short:"n" long:"name" description:"The expected file's name" json:"name,omitempty"`
+	Disabled    *bool              `long:"disabled" description:"Enable or disable the expected file" json:"disabled,omitempty"`
+	Rule        *string            `short:"r" long:"rule" description:"The reception rule with which the file is expected" json:"rule,omitempty"`
+	Partner     *string            `short:"p" long:"partner" description:"The partner from which the file is expected (empty to remove it)" json:"partner,omitempty"`
+	Server      *string            `short:"s" long:"server" description:"The local server of the account from which the file is expected (empty to remove it)" json:"server,omitempty"`
+	Account     *string            `short:"a" long:"account" description:"The local account from which the file is expected (empty to remove it)" json:"account,omitempty"`
+	Pattern     *string            `short:"P" long:"pattern" description:"The pattern the received file's name must match" json:"pattern,omitempty"`
+	WindowStart *string            `short:"b" long:"window-start" description:"The time of day (in HH:MM format) at which the reception window opens" json:"windowStart,omitempty"`
+	WindowEnd   *string            `short:"e" long:"window-end" description:"The time of day (in HH:MM format) at which the reception window closes" json:"windowEnd,omitempty"`
+	Days        *[]string          `short:"D" long:"day" description:"A day of the week on which the file is expected. Can be repeated" json:"days,omitempty"`
+	EmailAlert  *map[string]string `short:"m" long:"email-alert" description:"An argument of the EMAIL task sent when the file is missing or duplicated, in key:val format. Can be repeated." json:"emailAlert,omitempty"`
+}
+
+func (e *ExpectedFileUpdate) Execute([]string) error { return execute(e) }
+func (e *ExpectedFileUpdate) execute(w io.Writer) error {
+	addr.Path = path.Join(expectedFilesAPIPath, e.Args.Name)
+
+	if err := update(w, e); err != nil {
+		return err
+	}
+
+	name := e.Args.Name
+	if e.Name != nil && *e.Name != "" {
+		name = *e.Name
+	}
+
+	fmt.Fprintf(w, "The expected file %q was successfully updated.\n", name)
+
+	return nil
+}
+
+// ######################## DELETE ##########################
+
+type ExpectedFileDelete struct {
+	Args struct {
+		Name string `required:"yes" positional-arg-name:"name" description:"The expected file's name"`
+	} `positional-args:"yes"`
+}
+
+func (e *ExpectedFileDelete) Execute([]string) error { return execute(e) }
+func (e *ExpectedFileDelete) execute(w io.Writer) error {
+	addr.Path = path.Join(expectedFilesAPIPath, e.Args.Name)
+
+	if err := remove(w); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "The expected file %q was successfully deleted.\n", e.Args.Name)
+
+	return nil
+}
+
+// ######################## CHECKS ##########################
+
+//nolint:lll //struct tags are long
+type ExpectedFileChecks struct {
+	ListOptions
+
+	Args struct {
+		Name string `required:"yes" positional-arg-name:"name" description:"The expected file's name"`
+	} `positional-args:"yes"`
+
+	SortBy   string   `short:"s" long:"sort" description:"Attribute used to sort the returned entries" choice:"date+" choice:"date-" default:"date-"`
+	Statuses []string `short:"t" long:"status" description:"Filter the checks based on their status. Can be repeated." choice:"received" choice:"missing" choice:"duplicate"`
+}
+
+func (e *ExpectedFileChecks) Execute([]string) error { return execute(e) }
+func (e *ExpectedFileChecks) execute(w io.Writer) error {
+	addr.Path = path.Join(expectedFilesAPIPath, e.Args.Name, "checks")
+
+	query := url.Values{}
+	query.Set("limit", utils.FormatUint(e.Limit))
+	query.Set("offset", utils.FormatUint(e.Offset))
+	query.Set("sort", e.SortBy)
+
+	for _, status := range e.Statuses {
+		query.Add("status", status)
+	}
+
+	addr.RawQuery = query.Encode()
+
+	var body map[string][]*api.OutExpectedFileCheck
+	if err := list(&body); err != nil {
+		return err
+	}
+
+	if checks := body["checks"]; len(checks) > 0 {
+		return outputObject(w, checks, &e.OutputFormat, displayExpectedFileChecks)
+	}
+
+	fmt.Fprintf(w, "No checks found for expected file %q.\n", e.Args.Name)
+
+	return nil
+}
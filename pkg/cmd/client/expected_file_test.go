@@ -0,0 +1,394 @@
+package wg
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpectedFileAdd(t *testing.T) {
+	const (
+		fileName    = "file"
+		rule        = "rule"
+		partner     = "partner"
+		pattern     = "invoices_*.csv"
+		windowStart = "06:00"
+		windowEnd   = "07:00"
+
+		path     = "/api/expected_files"
+		location = path + "/" + fileName
+	)
+
+	t.Run(`Given the expected file "add" command`, func(t *testing.T) {
+		w := newTestOutput()
+		command := &ExpectedFileAdd{}
+
+		expected := &expectedRequest{
+			method: http.MethodPost,
+			path:   path,
+			body: map[string]any{
+				"name":        fileName,
+				"rule":        rule,
+				"partner":     partner,
+				"pattern":     pattern,
+				"windowStart": windowStart,
+				"windowEnd":   windowEnd,
+				"days":        []any{"monday", "tuesday"},
+				"emailAlert": map[string]any{
+					"sender":     "gateway@example.com",
+					"recipients": "ops@example.com",
+					"template":   "expected-file-alert",
+				},
+			},
+		}
+
+		result := &expectedResponse{
+			status:  http.StatusCreated,
+			headers: map[string][]string{"Location": {location}},
+		}
+
+		t.Run("Given a dummy gateway REST interface", func(t *testing.T) {
+			testServer(t, expected, result)
+
+			t.Run("When executing the command", func(t *testing.T) {
+				require.NoError(t, executeCommand(t, w, command,
+					"--name", fileName,
+					"--rule", rule,
+					"--partner", partner,
+					"--pattern", pattern,
+					"--window-start", windowStart,
+					"--window-end", windowEnd,
+					"--day", "monday",
+					"--day", "tuesday",
+					"--email-alert", "sender:gateway@example.com",
+					"--email-alert", "recipients:ops@example.com",
+					"--email-alert", "template:expected-file-alert",
+				),
+					"Then it should not return an error",
+				)
+
+				assert.Equal(t,
+					fmt.Sprintf("The expected file %q was successfully added.\n", fileName),
+					w.String(),
+					"Then it should display a message saying the expected file was added",
+				)
+			})
+		})
+	})
+}
+
+func TestExpectedFileList(t *testing.T) {
+	const (
+		path = "/api/expected_files"
+
+		sort   = "name-"
+		limit  = "10"
+		offset = "5"
+	)
+
+	t.Run(`Given the expected file "list" command`, func(t *testing.T) {
+		w := newTestOutput()
+		command := &ExpectedFileList{}
+
+		expected := &expectedRequest{
+			method: http.MethodGet,
+			values: map[string][]string{
+				"limit":  {limit},
+				"offset": {offset},
+				"sort":   {sort},
+			},
+			path: path,
+		}
+
+		files := []map[string]any{{
+			"name":        "file2",
+			"disabled":    true,
+			"rule":        "rule",
+			"server":      "server",
+			"account":     "toto",
+			"pattern":     "*",
+			"windowStart": "22:00",
+			"windowEnd":   "02:00",
+			"days":        []string{"sunday"},
+		}, {
+			"name":        "file1",
+			"rule":        "rule",
+			"partner":     "partner",
+			"pattern":     "*.csv",
+			"windowStart": "06:00",
+			"windowEnd":   "07:00",
+			"days":        []string{"monday", "friday"},
+			"emailAlert":  map[string]string{"recipients": "ops@example.com"},
+		}}
+
+		result := &expectedResponse{
+			status: http.StatusOK,
+			body:   map[string]any{"expectedFiles": files},
+		}
+
+		t.Run("Given a dummy gateway REST interface", func(t *testing.T) {
+			testServer(t, expected, result)
+
+			t.Run("When executing the command", func(t *testing.T) {
+				require.NoError(t, executeCommand(t, w, command,
+					"--limit", limit, "--offset", offset, "--sort", sort,
+				),
+					"Then it should not return an error",
+				)
+
+				assert.Equal(t,
+					`=== Expected files ===`+"\n"+
+						`-Expected file "file2" [Disabled]`+"\n"+
+						`  -Rule: rule`+"\n"+
+						`  -Partner: <none>`+"\n"+
+						`  -Local account: toto (server "server")`+"\n"+
+						`  -Pattern: *`+"\n"+
+						`  -Window: 22:00 - 02:00 (sunday)`+"\n"+
+						`  -Email alert: <none>`+"\n"+
+						`  -Last check: <none>`+"\n"+
+						`-Expected file "file1" [Enabled]`+"\n"+
+						`  -Rule: rule`+"\n"+
+						`  -Partner: partner`+"\n"+
+						`  -Local account: <none>`+"\n"+
+						`  -Pattern: *.csv`+"\n"+
+						`  -Window: 06:00 - 07:00 (monday, friday)`+"\n"+
+						`  -Email alert:`+"\n"+
+						`    -recipients: ops@example.com`+"\n"+
+						`  -Last check: <none>`+"\n",
+					w.String(),
+					"Then it should display the expected files",
+				)
+			})
+		})
+	})
+}
+
+func TestExpectedFileGet(t *testing.T) {
+	const (
+		fileName = "file"
+
+		path = "/api/expected_files/" + fileName
+	)
+
+	start := time.Date(2026, 10, 19, 6, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	checked := end.Add(time.Minute)
+
+	t.Run(`Given the expected file "get" command`, func(t *testing.T) {
+		w := newTestOutput()
+		command := &ExpectedFileGet{}
+
+		expected := &expectedRequest{
+			method: http.MethodGet,
+			path:   path,
+		}
+
+		result := &expectedResponse{
+			status: http.StatusOK,
+			body: map[string]any{
+				"name":        fileName,
+				"rule":        "rule",
+				"pattern":     "*",
+				"windowStart": "06:00",
+				"windowEnd":   "07:00",
+				"days":        []string{"monday"},
+				"lastCheck": map[string]any{
+					"windowStart": start,
+					"windowEnd":   end,
+					"status":      "missing",
+					"files":       []string{},
+					"checked":     checked,
+				},
+			},
+		}
+
+		t.Run("Given a dummy gateway REST interface", func(t *testing.T) {
+			testServer(t, expected, result)
+
+			t.Run("When executing the command", func(t *testing.T) {
+				require.NoError(t, executeCommand(t, w, command, fileName),
+					"Then it should not return an error")
+
+				assert.Equal(t,
+					`-Expected file "file" [Enabled]`+"\n"+
+						`  -Rule: rule`+"\n"+
+						`  -Partner: <none>`+"\n"+
+						`  -Local account: <none>`+"\n"+
+						`  -Pattern: *`+"\n"+
+						`  -Window: 06:00 - 07:00 (monday)`+"\n"+
+						`  -Email alert: <none>`+"\n"+
+						`  -Last check:`+"\n"+
+						`    -Status: missing`+"\n"+
+						`    -Window start: `+start.Local().String()+"\n"+
+						`    -Window end: `+end.Local().String()+"\n"+
+						`    -Files: <none>`+"\n"+
+						`    -Checked: `+checked.Local().String()+"\n",
+					w.String(),
+					"Then it should display the expected file",
+				)
+			})
+		})
+	})
+}
+
+func TestExpectedFileUpdate(t *testing.T) {
+	const (
+		oldFileName = "old_file"
+		newFileName = "new_file"
+		windowEnd   = "08:30"
+
+		path     = "/api/expected_files/" + oldFileName
+		location = "/api/expected_files/" + newFileName
+	)
+
+	t.Run(`Given the expected file "update" command`, func(t *testing.T) {
+		w := newTestOutput()
+		command := &ExpectedFileUpdate{}
+
+		expected := &expectedRequest{
+			method: http.MethodPatch,
+			path:   path,
+			body: map[string]any{
+				"name":      newFileName,
+				"disabled":  true,
+				"partner":   "",
+				"windowEnd": windowEnd,
+			},
+		}
+
+		result := &expectedResponse{
+			status:  http.StatusCreated,
+			headers: map[string][]string{"Location": {location}},
+		}
+
+		t.Run("Given a dummy gateway REST interface", func(t *testing.T) {
+			testServer(t, expected, result)
+
+			t.Run("When executing the command", func(t *testing.T) {
+				require.NoError(t, executeCommand(t, w, command, oldFileName,
+					"--name", newFileName,
+					"--disabled",
+					"--partner", "",
+					"--window-end", windowEnd,
+				),
+					"Then it should not return an error",
+				)
+
+				assert.Equal(t,
+					fmt.Sprintf("The expected file %q was successfully updated.\n", newFileName),
+					w.String(),
+					"Then it should display a message saying the expected file was updated",
+				)
+			})
+		})
+	})
+}
+
+func TestExpectedFileDelete(t *testing.T) {
+	const (
+		fileName = "file"
+		path     = "/api/expected_files/" + fileName
+	)
+
+	t.Run(`Given the expected file "delete" command`, func(t *testing.T) {
+		w := newTestOutput()
+		command := &ExpectedFileDelete{}
+
+		expected := &expectedRequest{
+			method: http.MethodDelete,
+			path:   path,
+		}
+
+		result := &expectedResponse{
+			status: http.StatusNoContent,
+		}
+
+		t.Run("Given a dummy gateway REST interface", func(t *testing.T) {
+			testServer(t, expected, result)
+
+			t.Run("When executing the command", func(t *testing.T) {
+				require.NoError(t, executeCommand(t, w, command, fileName),
+					"Then it should not return an error")
+
+				assert.Equal(t,
+					fmt.Sprintf("The expected file %q was successfully deleted.\n", fileName),
+					w.String(),
+					"Then it should display a message saying the expected file was deleted",
+				)
+			})
+		})
+	})
+}
+
+func TestExpectedFileChecks(t *testing.T) {
+	const (
+		fileName = "file"
+		path     = "/api/expected_files/" + fileName + "/checks"
+
+		sort   = "date+"
+		limit  = "10"
+		offset = "0"
+	)
+
+	start := time.Date(2026, 10, 19, 6, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	checked := end.Add(time.Minute)
+
+	t.Run(`Given the expected file "checks" command`, func(t *testing.T) {
+		w := newTestOutput()
+		command := &ExpectedFileChecks{}
+
+		expected := &expectedRequest{
+			method: http.MethodGet,
+			values: map[string][]string{
+				"limit":  {limit},
+				"offset": {offset},
+				"sort":   {sort},
+				"status": {"missing", "duplicate"},
+			},
+			path: path,
+		}
+
+		checks := []map[string]any{{
+			"windowStart": start,
+			"windowEnd":   end,
+			"status":      "duplicate",
+			"files":       []string{"a.csv", "b.csv"},
+			"checked":     checked,
+		}}
+
+		result := &expectedResponse{
+			status: http.StatusOK,
+			body:   map[string]any{"checks": checks},
+		}
+
+		t.Run("Given a dummy gateway REST interface", func(t *testing.T) {
+			testServer(t, expected, result)
+
+			t.Run("When executing the command", func(t *testing.T) {
+				require.NoError(t, executeCommand(t, w, command, fileName,
+					"--limit", limit, "--offset", offset, "--sort", sort,
+					"--status", "missing", "--status", "duplicate",
+				),
+					"Then it should not return an error",
+				)
+
+				assert.Equal(t,
+					`=== Expected file checks ===`+"\n"+
+						`-Window of `+start.Local().String()+` [duplicate]`+"\n"+
+						`  -Status: duplicate`+"\n"+
+						`  -Window start: `+start.Local().String()+"\n"+
+						`  -Window end: `+end.Local().String()+"\n"+
+						`  -Files: a.csv, b.csv`+"\n"+
+						`  -Checked: `+checked.Local().String()+"\n",
+					w.String(),
+					"Then it should display the expected file's checks",
+				)
+			})
+		})
+	})
+}
@@ -0,0 +1,85 @@
+package wg
+
+import (
+	"fmt"
+	"io"
+	"path"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/admin/rest/api"
+)
+
+const nodesAPIPath = "/api/nodes"
+
+func displayNodes(w io.Writer, nodes []*api.GetNodeRespObject) error {
+	Style0.PrintV(w, "=== Cluster nodes ===")
+
+	for _, node := range nodes {
+		if err := displayNode(w, node); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func displayNode(w io.Writer, node *api.GetNodeRespObject) error {
+	Style1.Printf(w, "Node %q [%s]", node.Name, node.Status)
+	Style22.Option(w, "Hostname", node.Hostname)
+	Style22.PrintL(w, "Started on", node.StartedAt.Local().String())
+	Style22.PrintL(w, "Last heartbeat", node.LastHeartbeat.Local().String())
+	Style22.PrintL(w, "Lease expiry", node.LeaseExpiry.Local().String())
+	Style22.PrintL(w, "Running transfers", node.RunningTransfers)
+
+	return nil
+}
+
+// ######################## LIST ##########################
+
+//nolint:lll //struct tags are long
+type NodeList struct {
+	ListOptions
+
+	SortBy string `short:"s" long:"sort" description:"Attribute used to sort the returned entries" choice:"name+" choice:"name-" default:"name+"`
+}
+
+func (n *NodeList) Execute([]string) error { return execute(n) }
+func (n *NodeList) execute(w io.Writer) error {
+	addr.Path = nodesAPIPath
+
+	listURL(&n.ListOptions, n.SortBy)
+
+	var body map[string][]*api.GetNodeRespObject
+	if err := list(&body); err != nil {
+		return err
+	}
+
+	if nodes := body["nodes"]; len(nodes) > 0 {
+		return outputObject(w, nodes, &n.OutputFormat, displayNodes)
+	}
+
+	fmt.Fprintln(w, "No cluster nodes found.")
+
+	return nil
+}
+
+// ######################## GET ##########################
+
+type NodeGet struct {
+	OutputFormat
+
+	Args struct {
+		Name string `required:"yes" positional-arg-name:"name" description:"The node's name"`
+	} `positional-args:"yes"`
+}
+
+func (n *NodeGet) Execute([]string) error { return execute(n) }
+func (n *NodeGet) execute(w io.Writer) error {
+	addr.Path = path.Join(nodesAPIPath, n.Args.Name)
+
+	var node api.GetNodeRespObject
+	if err := get(&node); err != nil {
+		return err
+	}
+
+	return outputObject(w, &node, &n.OutputFormat, displayNode)
+}
@@ -0,0 +1,136 @@
+package wg
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeList(t *testing.T) {
+	const (
+		path = "/api/nodes"
+
+		sort   = "name-"
+		limit  = "10"
+		offset = "5"
+	)
+
+	t.Run(`Given the node "list" command`, func(t *testing.T) {
+		w := newTestOutput()
+		command := &NodeList{}
+
+		expected := &expectedRequest{
+			method: http.MethodGet,
+			values: map[string][]string{
+				"limit":  {limit},
+				"offset": {offset},
+				"sort":   {sort},
+			},
+			path: path,
+		}
+
+		nodes := []map[string]any{{
+			"name":             "node2",
+			"hostname":         "host2",
+			"status":           "DEAD",
+			"startedAt":        "2026-01-01T01:00:00Z",
+			"lastHeartbeat":    "2026-01-01T02:00:00Z",
+			"leaseExpiry":      "2026-01-01T02:00:30Z",
+			"runningTransfers": 0,
+		}, {
+			"name":             "node1",
+			"hostname":         "host1",
+			"status":           "ALIVE",
+			"startedAt":        "2026-01-02T01:00:00Z",
+			"lastHeartbeat":    "2026-01-02T02:00:00Z",
+			"leaseExpiry":      "2026-01-02T02:00:30Z",
+			"runningTransfers": 3,
+		}}
+
+		result := &expectedResponse{
+			status: http.StatusOK,
+			body:   map[string]any{"nodes": nodes},
+		}
+
+		t.Run("Given a dummy gateway REST interface", func(t *testing.T) {
+			testServer(t, expected, result)
+
+			t.Run("When executing the command", func(t *testing.T) {
+				require.NoError(t, executeCommand(t, w, command,
+					"--limit", limit, "--offset", offset, "--sort", sort,
+				),
+					"Then it should not return an error",
+				)
+
+				assert.Equal(t,
+					expectedOutput(t, nodes,
+						`=== Cluster nodes ===`,
+						`{{- range . }}`,
+						`-Node "{{.name}}" [{{.status}}]`,
+						`  -Hostname: {{.hostname}}`,
+						`  -Started on: {{local .startedAt}}`,
+						`  -Last heartbeat: {{local .lastHeartbeat}}`,
+						`  -Lease expiry: {{local .leaseExpiry}}`,
+						`  -Running transfers: {{.runningTransfers}}`,
+						`{{- end }}`,
+					),
+					w.String(),
+					"Then it should display the nodes",
+				)
+			})
+		})
+	})
+}
+
+func TestNodeGet(t *testing.T) {
+	const (
+		nodeName = "node1"
+
+		path = "/api/nodes/" + nodeName
+	)
+
+	t.Run(`Given the node "get" command`, func(t *testing.T) {
+		w := newTestOutput()
+		command := &NodeGet{}
+
+		expected := &expectedRequest{
+			method: http.MethodGet,
+			path:   path,
+		}
+
+		result := &expectedResponse{
+			status: http.StatusOK,
+			body: map[string]any{
+				"name":             nodeName,
+				"status":           "ALIVE",
+				"startedAt":        "2026-01-02T01:00:00Z",
+				"lastHeartbeat":    "2026-01-02T02:00:00Z",
+				"leaseExpiry":      "2026-01-02T02:00:30Z",
+				"runningTransfers": 1,
+			},
+		}
+
+		t.Run("Given a dummy gateway REST interface", func(t *testing.T) {
+			testServer(t, expected, result)
+
+			t.Run("When executing the command", func(t *testing.T) {
+				require.NoError(t, executeCommand(t, w, command, nodeName),
+					"Then it should not return an error")
+
+				assert.Equal(t,
+					expectedOutput(t, result.body,
+						`-Node "{{.name}}" [{{.status}}]`,
+						`  -Started on: {{local .startedAt}}`,
+						`  -Last heartbeat: {{local .lastHeartbeat}}`,
+						`  -Lease expiry: {{local .leaseExpiry}}`,
+						`  -Running transfers: {{.runningTransfers}}`,
+					),
+					w.String(),
+					"Then it should display the node",
+				)
+			})
+		})
+	})
+}
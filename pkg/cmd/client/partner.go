@@ -36,6 +36,7 @@ func displayPartner(w io.Writer, partner *api.OutPartner) error {
 	Style22.PrintL(w, "Address", partner.Address)
 	Style22.PrintL(w, "Credentials",
 		withDefault(join(partner.Credentials), none))
+	Style22.Option(w, "Retry policy", partner.RetryPolicy)
 
 	displayProtoConfig(w, partner.ProtoConfig)
 	displayAuthorizedRules(w, partner.AuthorizedRules)
@@ -51,6 +52,7 @@ type PartnerAdd struct {
 	Protocol    string             `required:"yes" short:"p" long:"protocol" description:"The partner's protocol" json:"protocol,omitempty"`
 	Address     string             `required:"yes" short:"a" long:"address" description:"The partner's [address:port]" json:"address,omitempty"`
 	ProtoConfig map[string]confVal `short:"c" long:"config" description:"The partner's configuration, in key:val format. Can be repeated." json:"protoConfig,omitempty"`
+	RetryPolicy string             `long:"retry-policy" description:"The retry policy applied to the transfers made with the partner" json:"retryPolicy,omitempty"`
 }
 
 func (p *PartnerAdd) Execute([]string) error { return execute(p) }
@@ -151,6 +153,7 @@ type PartnerUpdate struct {
 	Protocol    *string             `short:"p" long:"protocol" description:"The partner's protocol'" json:"protocol,omitempty"`
 	Address     *string             `short:"a" long:"address" description:"The partner's [address:port]" json:"address,omitempty"`
 	ProtoConfig *map[string]confVal `short:"c" long:"config" description:"The partner's configuration, in key:val format. Can be repeated." json:"protoConfig,omitempty"`
+	RetryPolicy *string             `long:"retry-policy" description:"The retry policy applied to the transfers made with the partner" json:"retryPolicy,omitempty"`
 }
 
 func (p *PartnerUpdate) Execute([]string) error { return execute(p) }
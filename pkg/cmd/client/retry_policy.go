@@ -0,0 +1,187 @@
+package wg
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/admin/rest/api"
+)
+
+const retryPoliciesAPIPath = "/api/retry_policies"
+
+func displayRetryPolicies(w io.Writer, policies []*api.GetRetryPolicyObject) error {
+	Style0.PrintV(w, "=== Retry policies ===")
+	for _, policy := range policies {
+		if err := displayRetryPolicy(w, policy); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func displayRetryPolicy(w io.Writer, policy *api.GetRetryPolicyObject) error {
+	Style1.Printf(w, "Retry policy %q", policy.Name)
+	Style22.PrintL(w, "Max attempts", policy.MaxAttempts)
+	Style22.PrintL(w, "First delay", time.Duration(policy.FirstDelay).String())
+	Style22.PrintL(w, "Increment factor", policy.IncrementFactor)
+	Style22.Defaul(w, "Max delay", durationOption(policy.MaxDelay), none)
+	Style22.PrintL(w, "Jitter", policy.Jitter)
+	Style22.Defaul(w, "Max window", durationOption(policy.MaxWindow), none)
+
+	if len(policy.ErrorCodes) == 0 {
+		Style22.PrintL(w, "Error codes", none)
+
+		return nil
+	}
+
+	Style22.Printf(w, "Error codes:")
+	displayMap(w, Style333, policy.ErrorCodes)
+
+	return nil
+}
+
+func durationOption(d api.Duration) string {
+	if d == 0 {
+		return ""
+	}
+
+	return time.Duration(d).String()
+}
+
+// ######################## ADD ##########################
+
+//nolint:lll //struct tags are long
+type RetryPolicyAdd struct {
+	Name            string            `required:"yes" short:"n" long:"name" description:"The retry policy's name" json:"name,omitempty"`
+	MaxAttempts     int8              `short:"a" long:"max-attempts" description:"The maximum number of attempts of a transfer (including the first one)" json:"maxAttempts,omitempty"`
+	FirstDelay      string            `short:"d" long:"first-delay" description:"The delay before the first retry (e.g. 30s, 5m)" json:"firstDelay,omitempty"`
+	IncrementFactor float32           `short:"f" long:"increment-factor" description:"The factor by which the delay is multiplied at each retry" json:"incrementFactor,omitempty"`
+	MaxDelay        string            `short:"m" long:"max-delay" description:"The maximum delay between 2 attempts (e.g. 1h)" json:"maxDelay,omitempty"`
+	Jitter          float32           `short:"j" long:"jitter" description:"The random variation applied to the delays, as a fraction of the delay (between 0 and 1)" json:"jitter,omitempty"`
+	MaxWindow       string            `short:"w" long:"max-window" description:"The maximum time during which a transfer can be retried (e.g. 24h)" json:"maxWindow,omitempty"`
+	ErrorCodes      map[string]string `short:"e" long:"error-code" description:"The action (retry, never or always) taken for an error code, in code:action format. Can be repeated." json:"errorCodes,omitempty"`
+}
+
+func (r *RetryPolicyAdd) Execute([]string) error { return execute(r) }
+func (r *RetryPolicyAdd) execute(w io.Writer) error {
+	addr.Path = retryPoliciesAPIPath
+
+	if _, err := add(w, r); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "The retry policy %q was successfully added.\n", r.Name)
+
+	return nil
+}
+
+// ######################## LIST ##########################
+
+//nolint:lll //struct tags are long
+type RetryPolicyList struct {
+	ListOptions
+
+	SortBy string `short:"s" long:"sort" description:"Attribute used to sort the returned entries" choice:"name+" choice:"name-" default:"name+"`
+}
+
+func (r *RetryPolicyList) Execute([]string) error { return execute(r) }
+func (r *RetryPolicyList) execute(w io.Writer) error {
+	addr.Path = retryPoliciesAPIPath
+
+	listURL(&r.ListOptions, r.SortBy)
+
+	var body map[string][]*api.GetRetryPolicyObject
+	if err := list(&body); err != nil {
+		return err
+	}
+
+	if policies := body["retryPolicies"]; len(policies) > 0 {
+		return outputObject(w, policies, &r.OutputFormat, displayRetryPolicies)
+	}
+
+	fmt.Fprintln(w, "No retry policies found.")
+
+	return nil
+}
+
+// ######################## GET ##########################
+
+type RetryPolicyGet struct {
+	OutputFormat
+
+	Args struct {
+		Name string `required:"yes" positional-arg-name:"name" description:"The retry policy's name"`
+	} `positional-args:"yes"`
+}
+
+func (r *RetryPolicyGet) Execute([]string) error { return execute(r) }
+func (r *RetryPolicyGet) execute(w io.Writer) error {
+	addr.Path = path.Join(retryPoliciesAPIPath, r.Args.Name)
+
+	var policy api.GetRetryPolicyObject
+	if err := get(&policy); err != nil {
+		return err
+	}
+
+	return outputObject(w, &policy, &r.OutputFormat, displayRetryPolicy)
+}
+
+// ######################## UPDATE ##########################
+
+//nolint:lll //struct tags are long
+type RetryPolicyUpdate struct {
+	Args struct {
+		Name string `required:"yes" positional-arg-name:"name" description:"The old retry policy's name"`
+	} `positional-args:"yes" json:"-"`
+
+	Name            *string            `short:"n" long:"name" description:"The retry policy's name" json:"name,omitempty"`
+	MaxAttempts     *int8              `short:"a" long:"max-attempts" description:"The maximum number of attempts of a transfer (including the first one)" json:"maxAttempts,omitempty"`
+	FirstDelay      *string            `short:"d" long:"first-delay" description:"The delay before the first retry (e.g. 30s, 5m)" json:"firstDelay,omitempty"`
+	IncrementFactor *float32           `short:"f" long:"increment-factor" description:"The factor by which the delay is multiplied at each retry" json:"incrementFactor,omitempty"`
+	MaxDelay        *string            `short:"m" long:"max-delay" description:"The maximum delay between 2 attempts (e.g. 1h)" json:"maxDelay,omitempty"`
+	Jitter          *float32           `short:"j" long:"jitter" description:"The random variation applied to the delays, as a fraction of the delay (between 0 and 1)" json:"jitter,omitempty"`
+	MaxWindow       *string            `short:"w" long:"max-window" description:"The maximum time during which a transfer can be retried (e.g. 24h)" json:"maxWindow,omitempty"`
+	ErrorCodes      *map[string]string `short:"e" long:"error-code" description:"The action (retry, never or always) taken for an error code, in code:action format. Can be repeated." json:"errorCodes,omitempty"`
+}
+
+func (r *RetryPolicyUpdate) Execute([]string) error { return execute(r) }
+func (r *RetryPolicyUpdate) execute(w io.Writer) error {
+	addr.Path = path.Join(retryPoliciesAPIPath, r.Args.Name)
+
+	if err := update(w, r); err != nil {
+		return err
+	}
+
+	name := r.Args.Name
+	if r.Name != nil && *r.Name != "" {
+		name = *r.Name
+	}
+
+	fmt.Fprintf(w, "The retry policy %q was successfully updated.\n", name)
+
+	return nil
+}
+
+// ######################## DELETE ##########################
+
+type RetryPolicyDelete struct {
+	Args struct {
+		Name string `required:"yes" positional-arg-name:"name" description:"The retry policy's name"`
+	} `positional-args:"yes"`
+}
+
+func (r *RetryPolicyDelete) Execute([]string) error { return execute(r) }
+func (r *RetryPolicyDelete) execute(w io.Writer) error {
+	addr.Path = path.Join(retryPoliciesAPIPath, r.Args.Name)
+
+	if err := remove(w); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "The retry policy %q was successfully deleted.\n", r.Args.Name)
+
+	return nil
+}
@@ -0,0 +1,314 @@
+package wg
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryPolicyAdd(t *testing.T) {
+	const (
+		policyName      = "policy"
+		maxAttempts     = 5.0
+		firstDelay      = "30s"
+		incrementFactor = 2.0
+		maxDelay        = "1h"
+		jitter          = 0.5
+		maxWindow       = "24h"
+
+		path     = "/api/retry_policies"
+		location = path + "/" + policyName
+	)
+
+	t.Run(`Given the retry policy "add" command`, func(t *testing.T) {
+		w := newTestOutput()
+		command := &RetryPolicyAdd{}
+
+		expected := &expectedRequest{
+			method: http.MethodPost,
+			path:   path,
+			body: map[string]any{
+				"name":            policyName,
+				"maxAttempts":     maxAttempts,
+				"firstDelay":      firstDelay,
+				"incrementFactor": incrementFactor,
+				"maxDelay":        maxDelay,
+				"jitter":          jitter,
+				"maxWindow":       maxWindow,
+				"errorCodes": map[string]any{
+					"TeConnection": "always",
+					"TeForbidden":  "never",
+				},
+			},
+		}
+
+		result := &expectedResponse{
+			status:  http.StatusCreated,
+			headers: map[string][]string{"Location": {location}},
+		}
+
+		t.Run("Given a dummy gateway REST interface", func(t *testing.T) {
+			testServer(t, expected, result)
+
+			t.Run("When executing the command", func(t *testing.T) {
+				require.NoError(t, executeCommand(t, w, command,
+					"--name", policyName,
+					"--max-attempts", fmt.Sprint(maxAttempts),
+					"--first-delay", firstDelay,
+					"--increment-factor", fmt.Sprint(incrementFactor),
+					"--max-delay", maxDelay,
+					"--jitter", fmt.Sprint(jitter),
+					"--max-window", maxWindow,
+					"--error-code", "TeConnection:always",
+					"--error-code", "TeForbidden:never",
+				),
+					"Then it should not return an error",
+				)
+
+				assert.Equal(t,
+					fmt.Sprintf("The retry policy %q was successfully added.\n", policyName),
+					w.String(),
+					"Then it should display a message saying the retry policy was added",
+				)
+			})
+		})
+	})
+}
+
+func TestRetryPolicyList(t *testing.T) {
+	const (
+		path = "/api/retry_policies"
+
+		sort   = "name-"
+		limit  = "10"
+		offset = "5"
+	)
+
+	t.Run(`Given the retry policy "list" command`, func(t *testing.T) {
+		w := newTestOutput()
+		command := &RetryPolicyList{}
+
+		expected := &expectedRequest{
+			method: http.MethodGet,
+			values: map[string][]string{
+				"limit":  {limit},
+				"offset": {offset},
+				"sort":   {sort},
+			},
+			path: path,
+		}
+
+		policies := []map[string]any{{
+			"name":            "policy2",
+			"maxAttempts":     3,
+			"firstDelay":      "1m0s",
+			"incrementFactor": 1,
+			"maxDelay":        "0s",
+			"jitter":          0,
+			"maxWindow":       "0s",
+		}, {
+			"name":            "policy1",
+			"maxAttempts":     5,
+			"firstDelay":      "30s",
+			"incrementFactor": 2,
+			"maxDelay":        "1h0m0s",
+			"jitter":          0.5,
+			"maxWindow":       "24h0m0s",
+			"errorCodes":      map[string]any{"TeForbidden": "never"},
+		}}
+
+		result := &expectedResponse{
+			status: http.StatusOK,
+			body:   map[string]any{"retryPolicies": policies},
+		}
+
+		t.Run("Given a dummy gateway REST interface", func(t *testing.T) {
+			testServer(t, expected, result)
+
+			t.Run("When executing the command", func(t *testing.T) {
+				require.NoError(t, executeCommand(t, w, command,
+					"--limit", limit, "--offset", offset, "--sort", sort,
+				),
+					"Then it should not return an error",
+				)
+
+				assert.Equal(t,
+					`=== Retry policies ===`+"\n"+
+						`-Retry policy "policy2"`+"\n"+
+						`  -Max attempts: 3`+"\n"+
+						`  -First delay: 1m0s`+"\n"+
+						`  -Increment factor: 1`+"\n"+
+						`  -Max delay: <none>`+"\n"+
+						`  -Jitter: 0`+"\n"+
+						`  -Max window: <none>`+"\n"+
+						`  -Error codes: <none>`+"\n"+
+						`-Retry policy "policy1"`+"\n"+
+						`  -Max attempts: 5`+"\n"+
+						`  -First delay: 30s`+"\n"+
+						`  -Increment factor: 2`+"\n"+
+						`  -Max delay: 1h0m0s`+"\n"+
+						`  -Jitter: 0.5`+"\n"+
+						`  -Max window: 24h0m0s`+"\n"+
+						`  -Error codes:`+"\n"+
+						`    -TeForbidden: never`+"\n",
+					w.String(),
+					"Then it should display the retry policies",
+				)
+			})
+		})
+	})
+}
+
+func TestRetryPolicyGet(t *testing.T) {
+	const (
+		policyName = "policy"
+
+		path = "/api/retry_policies/" + policyName
+	)
+
+	t.Run(`Given the retry policy "get" command`, func(t *testing.T) {
+		w := newTestOutput()
+		command := &RetryPolicyGet{}
+
+		expected := &expectedRequest{
+			method: http.MethodGet,
+			path:   path,
+		}
+
+		result := &expectedResponse{
+			status: http.StatusOK,
+			body: map[string]any{
+				"name":            policyName,
+				"maxAttempts":     5,
+				"firstDelay":      "30s",
+				"incrementFactor": 1.5,
+				"maxDelay":        "10m0s",
+				"jitter":          0.25,
+				"maxWindow":       "0s",
+				"errorCodes": map[string]any{
+					"TeForbidden":  "never",
+					"TeConnection": "always",
+				},
+			},
+		}
+
+		t.Run("Given a dummy gateway REST interface", func(t *testing.T) {
+			testServer(t, expected, result)
+
+			t.Run("When executing the command", func(t *testing.T) {
+				require.NoError(t, executeCommand(t, w, command, policyName),
+					"Then it should not return an error")
+
+				assert.Equal(t,
+					expectedOutput(t, result.body,
+						`-Retry policy "{{.name}}"`,
+						`  -Max attempts: {{.maxAttempts}}`,
+						`  -First delay: {{.firstDelay}}`,
+						`  -Increment factor: {{.incrementFactor}}`,
+						`  -Max delay: {{.maxDelay}}`,
+						`  -Jitter: {{.jitter}}`,
+						`  -Max window: <none>`,
+						`  -Error codes:`,
+						`    -TeConnection: always`,
+						`    -TeForbidden: never`,
+					),
+					w.String(),
+					"Then it should display the retry policy",
+				)
+			})
+		})
+	})
+}
+
+func TestRetryPolicyUpdate(t *testing.T) {
+	const (
+		oldPolicyName = "old_policy"
+		newPolicyName = "new_policy"
+		maxAttempts   = 10.0
+		maxWindow     = "48h"
+
+		path     = "/api/retry_policies/" + oldPolicyName
+		location = "/api/retry_policies/" + newPolicyName
+	)
+
+	t.Run(`Given the retry policy "update" command`, func(t *testing.T) {
+		w := newTestOutput()
+		command := &RetryPolicyUpdate{}
+
+		expected := &expectedRequest{
+			method: http.MethodPatch,
+			path:   path,
+			body: map[string]any{
+				"name":        newPolicyName,
+				"maxAttempts": maxAttempts,
+				"maxWindow":   maxWindow,
+				"errorCodes":  map[string]any{"TeIntegrity": "never"},
+			},
+		}
+
+		result := &expectedResponse{
+			status:  http.StatusCreated,
+			headers: map[string][]string{"Location": {location}},
+		}
+
+		t.Run("Given a dummy gateway REST interface", func(t *testing.T) {
+			testServer(t, expected, result)
+
+			t.Run("When executing the command", func(t *testing.T) {
+				require.NoError(t, executeCommand(t, w, command, oldPolicyName,
+					"--name", newPolicyName,
+					"--max-attempts", fmt.Sprint(maxAttempts),
+					"--max-window", maxWindow,
+					"--error-code", "TeIntegrity:never",
+				),
+					"Then it should not return an error",
+				)
+
+				assert.Equal(t,
+					fmt.Sprintf("The retry policy %q was successfully updated.\n", newPolicyName),
+					w.String(),
+					"Then it should display a message saying the retry policy was updated",
+				)
+			})
+		})
+	})
+}
+
+func TestRetryPolicyDelete(t *testing.T) {
+	const (
+		policyName = "policy"
+		path       = "/api/retry_policies/" + policyName
+	)
+
+	t.Run(`Given the retry policy "delete" command`, func(t *testing.T) {
+		w := newTestOutput()
+		command := &RetryPolicyDelete{}
+
+		expected := &expectedRequest{
+			method: http.MethodDelete,
+			path:   path,
+		}
+
+		result := &expectedResponse{
+			status: http.StatusNoContent,
+		}
+
+		t.Run("Given a dummy gateway REST interface", func(t *testing.T) {
+			testServer(t, expected, result)
+
+			t.Run("When executing the command", func(t *testing.T) {
+				require.NoError(t, executeCommand(t, w, command, policyName),
+					"Then it should not return an error")
+
+				assert.Equal(t,
+					fmt.Sprintf("The retry policy %q was successfully deleted.\n", policyName),
+					w.String(),
+					"Then it should display a message saying the retry policy was deleted",
+				)
+			})
+		})
+	})
+}
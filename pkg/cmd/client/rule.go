@@ -38,6 +38,7 @@ func displayRule(w io.Writer, rule *api.OutRule) error {
 	Style22.Option(w, "Local directory", rule.LocalDir)
 	Style22.Option(w, "Remote directory", rule.RemoteDir)
 	Style22.Option(w, "Temp receive directory", rule.TmpLocalRcvDir)
+	Style22.Option(w, "Retry policy", rule.RetryPolicy)
 
 	displayTaskChain(w, "Pre tasks", rule.PreTasks)
 	displayTaskChain(w, "Post tasks", rule.PostTasks)
@@ -105,6 +106,7 @@ type RuleAdd struct {
 	PreTasks      []jsonObject `short:"r" long:"pre" description:"A pre-transfer task in JSON format, can be repeated" json:"preTasks,omitempty"`
 	PostTasks     []jsonObject `short:"s" long:"post" description:"A post-transfer task in JSON format, can be repeated" json:"postTasks,omitempty"`
 	ErrorTasks    []jsonObject `short:"e" long:"err" description:"A transfer error task in JSON format, can be repeated" json:"errorTasks,omitempty"`
+	RetryPolicy   string       `long:"retry-policy" description:"The retry policy applied to the rule's transfers" json:"retryPolicy,omitempty"`
 
 	// Deprecated options
 	InPath   string `short:"i" long:"in_path" description:"[DEPRECATED] The path to the destination of the file" json:"inPath,omitempty"` // Deprecated: replaced by LocalDir & RemoteDir
@@ -215,6 +217,7 @@ type RuleUpdate struct {
 	PreTasks      *jsonObjects `short:"r" long:"pre" description:"A pre-transfer task in JSON format, can be repeated" json:"preTasks,omitempty"`
 	PostTasks     *jsonObjects `short:"s" long:"post" description:"A post-transfer task in JSON format, can be repeated" json:"postTasks,omitempty"`
 	ErrorTasks    *jsonObjects `short:"e" long:"err" description:"A transfer error task in JSON format, can be repeated" json:"errorTasks,omitempty"`
+	RetryPolicy   *string      `long:"retry-policy" description:"The retry policy applied to the rule's transfers" json:"retryPolicy,omitempty"`
 
 	// Deprecated options
 	InPath   *string `short:"i" long:"in_path" description:"[DEPRECATED] The path to the destination of the file" json:"inPath,omitempty"` // Deprecated: replaced by LocalDir & RemoteDir
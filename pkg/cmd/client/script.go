@@ -0,0 +1,85 @@
+package wg
+
+import (
+	"io"
+)
+
+//nolint:gochecknoglobals //constant struct
+var scriptKind = &storedTextKind{
+	name:    "script",
+	title:   "Script",
+	plural:  "scripts",
+	apiPath: "/api/scripts",
+}
+
+// ######################## ADD ##########################
+
+//nolint:lll //struct tags are long
+type ScriptAdd struct {
+	Name    string          `required:"yes" short:"n" long:"name" description:"The script's name" json:"name,omitempty"`
+	Content textFileOrValue `required:"yes" short:"c" long:"content" description:"The script's content, or the path of a file containing it" json:"content,omitzero"`
+}
+
+func (s *ScriptAdd) Execute([]string) error { return execute(s) }
+func (s *ScriptAdd) execute(w io.Writer) error {
+	return scriptKind.add(w, s, s.Name)
+}
+
+// ######################## LIST ##########################
+
+//nolint:lll //struct tags are long
+type ScriptList struct {
+	ListOptions
+
+	SortBy string `short:"s" long:"sort" description:"Attribute used to sort the returned entries" choice:"name+" choice:"name-" default:"name+"`
+}
+
+func (s *ScriptList) Execute([]string) error { return execute(s) }
+func (s *ScriptList) execute(w io.Writer) error {
+	return scriptKind.list(w, &s.ListOptions, s.SortBy)
+}
+
+// ######################## GET ##########################
+
+type ScriptGet struct {
+	OutputFormat
+
+	Args struct {
+		Name string `required:"yes" positional-arg-name:"name" description:"The script's name"`
+	} `positional-args:"yes"`
+}
+
+func (s *ScriptGet) Execute([]string) error { return execute(s) }
+func (s *ScriptGet) execute(w io.Writer) error {
+	return scriptKind.get(w, s.Args.Name, &s.OutputFormat)
+}
+
+// ######################## UPDATE ##########################
+
+//nolint:lll //struct tags are long
+type ScriptUpdate struct {
+	Args struct {
+		Name string `required:"yes" positional-arg-name:"name" description:"The old script's name"`
+	} `positional-args:"yes" json:"-"`
+
+	Name    string          `short:"n" long:"name" description:"The script's name" json:"name,omitempty"`
+	Content textFileOrValue `short:"c" long:"content" description:"The script's content, or the path of a file containing it" json:"content,omitzero"`
+}
+
+func (s *ScriptUpdate) Execute([]string) error { return execute(s) }
+func (s *ScriptUpdate) execute(w io.Writer) error {
+	return scriptKind.update(w, s, s.Args.Name, s.Name)
+}
+
+// ######################## DELETE ##########################
+
+type ScriptDelete struct {
+	Args struct {
+		Name string `required:"yes" positional-arg-name:"name" description:"The script's name"`
+	} `positional-args:"yes"`
+}
+
+func (s *ScriptDelete) Execute([]string) error { return execute(s) }
+func (s *ScriptDelete) execute(w io.Writer) error {
+	return scriptKind.remove(w, s.Args.Name)
+}
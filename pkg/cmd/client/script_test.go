@@ -0,0 +1,248 @@
+package wg
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScriptAdd(t *testing.T) {
+	const (
+		scriptName    = "script"
+		scriptContent = `print("hello")`
+
+		path     = "/api/scripts"
+		location = path + "/" + scriptName
+	)
+
+	t.Run(`Given the script "add" command`, func(t *testing.T) {
+		w := newTestOutput()
+		command := &ScriptAdd{}
+
+		expected := &expectedRequest{
+			method: http.MethodPost,
+			path:   path,
+			body: map[string]any{
+				"name":    scriptName,
+				"content": scriptContent,
+			},
+		}
+
+		result := &expectedResponse{
+			status:  http.StatusCreated,
+			headers: map[string][]string{"Location": {location}},
+		}
+
+		t.Run("Given a dummy gateway REST interface", func(t *testing.T) {
+			testServer(t, expected, result)
+
+			t.Run("When executing the command", func(t *testing.T) {
+				require.NoError(t, executeCommand(t, w, command,
+					"--name", scriptName,
+					"--content", scriptContent,
+				),
+					"Then it should not return an error",
+				)
+
+				assert.Equal(t,
+					fmt.Sprintf("The script %q was successfully added.\n", scriptName),
+					w.String(),
+					"Then it should display a message saying the script was added",
+				)
+			})
+		})
+	})
+}
+
+func TestScriptList(t *testing.T) {
+	const (
+		path = "/api/scripts"
+
+		sort   = "name-"
+		limit  = "10"
+		offset = "5"
+	)
+
+	t.Run(`Given the script "list" command`, func(t *testing.T) {
+		w := newTestOutput()
+		command := &ScriptList{}
+
+		expected := &expectedRequest{
+			method: http.MethodGet,
+			values: map[string][]string{
+				"limit":  {limit},
+				"offset": {offset},
+				"sort":   {sort},
+			},
+			path: path,
+		}
+
+		scripts := []map[string]any{
+			{"name": "script2", "content": `print("world")`},
+			{"name": "script1", "content": `print("hello")`},
+		}
+
+		result := &expectedResponse{
+			status: http.StatusOK,
+			body:   map[string]any{"scripts": scripts},
+		}
+
+		t.Run("Given a dummy gateway REST interface", func(t *testing.T) {
+			testServer(t, expected, result)
+
+			t.Run("When executing the command", func(t *testing.T) {
+				require.NoError(t, executeCommand(t, w, command,
+					"--limit", limit, "--offset", offset, "--sort", sort,
+				),
+					"Then it should not return an error",
+				)
+
+				assert.Equal(t,
+					expectedOutput(t, scripts,
+						`=== Scripts ===`,
+						`{{- range . }}`,
+						`-Script "{{.name}}"`,
+						`  -Content: {{.content}}`,
+						`{{- end }}`,
+					),
+					w.String(),
+					"Then it should display the scripts",
+				)
+			})
+		})
+	})
+}
+
+func TestScriptGet(t *testing.T) {
+	const (
+		scriptName    = "script"
+		scriptContent = `content = fs.read(transfer.local_path)
+print(len(content))`
+
+		path = "/api/scripts/" + scriptName
+	)
+
+	t.Run(`Given the script "get" command`, func(t *testing.T) {
+		w := newTestOutput()
+		command := &ScriptGet{}
+
+		expected := &expectedRequest{
+			method: http.MethodGet,
+			path:   path,
+		}
+
+		result := &expectedResponse{
+			status: http.StatusOK,
+			body: map[string]any{
+				"name":    scriptName,
+				"content": scriptContent,
+			},
+		}
+
+		t.Run("Given a dummy gateway REST interface", func(t *testing.T) {
+			testServer(t, expected, result)
+
+			t.Run("When executing the command", func(t *testing.T) {
+				require.NoError(t, executeCommand(t, w, command, scriptName),
+					"Then it should not return an error")
+
+				assert.Equal(t,
+					expectedOutput(t, result.body,
+						`-Script "{{.name}}"`,
+						`  -Content: {{- lines .content 5}}`,
+					),
+					w.String(),
+					"Then it should display the script",
+				)
+			})
+		})
+	})
+}
+
+func TestScriptUpdate(t *testing.T) {
+	const (
+		oldScriptName    = "old_script"
+		newScriptName    = "new_script"
+		newScriptContent = `print("hello")`
+
+		path     = "/api/scripts/" + oldScriptName
+		location = "/api/scripts/" + newScriptName
+	)
+
+	t.Run(`Given the script "update" command`, func(t *testing.T) {
+		w := newTestOutput()
+		command := &ScriptUpdate{}
+
+		expected := &expectedRequest{
+			method: http.MethodPatch,
+			path:   path,
+			body: map[string]any{
+				"name":    newScriptName,
+				"content": newScriptContent,
+			},
+		}
+
+		result := &expectedResponse{
+			status:  http.StatusCreated,
+			headers: map[string][]string{"Location": {location}},
+		}
+
+		t.Run("Given a dummy gateway REST interface", func(t *testing.T) {
+			testServer(t, expected, result)
+
+			t.Run("When executing the command", func(t *testing.T) {
+				require.NoError(t, executeCommand(t, w, command, oldScriptName,
+					"--name", newScriptName,
+					"--content", newScriptContent,
+				),
+					"Then it should not return an error",
+				)
+
+				assert.Equal(t,
+					fmt.Sprintf("The script %q was successfully updated.\n", newScriptName),
+					w.String(),
+					"Then it should display a message saying the script was updated",
+				)
+			})
+		})
+	})
+}
+
+func TestScriptDelete(t *testing.T) {
+	const (
+		scriptName = "script"
+		path       = "/api/scripts/" + scriptName
+	)
+
+	t.Run(`Given the script "delete" command`, func(t *testing.T) {
+		w := newTestOutput()
+		command := &ScriptDelete{}
+
+		expected := &expectedRequest{
+			method: http.MethodDelete,
+			path:   path,
+		}
+
+		result := &expectedResponse{
+			status: http.StatusNoContent,
+		}
+
+		t.Run("Given a dummy gateway REST interface", func(t *testing.T) {
+			testServer(t, expected, result)
+
+			t.Run("When executing the command", func(t *testing.T) {
+				require.NoError(t, executeCommand(t, w, command, scriptName),
+					"Then it should not return an error")
+
+				assert.Equal(t,
+					fmt.Sprintf("The script %q was successfully deleted.\n", scriptName),
+					w.String(),
+					"Then it should display a message saying the script was deleted",
+				)
+			})
+		})
+	})
+}
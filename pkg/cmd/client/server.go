@@ -395,3 +395,25 @@ func (s *ServerRestart) execute(w io.Writer) error {
 
 	return nil
 }
+
+//nolint:lll //flag tags are long
+type ServerDrain struct {
+	Args struct {
+		Name string `required:"yes" positional-arg-name:"name" description:"The server's name"`
+	} `positional-args:"yes"`
+	Deadline string `short:"d" long:"deadline" description:"The maximum time given to the running transfers to end before stopping the server (ex: 30s, 10m, 1h)"`
+}
+
+func (s *ServerDrain) Execute([]string) error { return execute(s) }
+func (s *ServerDrain) execute(w io.Writer) error {
+	addr.RawQuery = drainQuery(s.Deadline)
+
+	if err := exec(w, fmt.Sprintf("/api/servers/%s/drain", s.Args.Name)); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "The server %q is being drained. It will be stopped once its "+
+		"running transfers are done.\n", s.Args.Name)
+
+	return nil
+}
@@ -0,0 +1,268 @@
+package wg
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/admin/rest/api"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils"
+)
+
+const slasAPIPath = "/api/slas"
+
+func displaySLAs(w io.Writer, slas []*api.OutSLA) error {
+	Style0.PrintV(w, "=== SLAs ===")
+	for _, sla := range slas {
+		if err := displaySLA(w, sla); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func displaySLA(w io.Writer, sla *api.OutSLA) error {
+	rule := ""
+	if sla.Rule != "" {
+		rule = fmt.Sprintf("%s (%s)", sla.Rule, direction(sla.IsSend.Value))
+	}
+
+	deadline := ""
+	if sla.Deadline != "" {
+		deadline = fmt.Sprintf("%s (%s)", sla.Deadline, join(sla.DeadlineDays))
+	}
+
+	minThroughput := ""
+	if sla.MinThroughput > 0 {
+		minThroughput = fmt.Sprintf("%d B/s", sla.MinThroughput)
+	}
+
+	Style1.Printf(w, "SLA %q [%s]", sla.Name, sla.Status)
+	Style22.Defaul(w, "Rule", rule, none)
+	Style22.Defaul(w, "Partner", sla.Partner, none)
+	Style22.Defaul(w, "Deadline", deadline, none)
+	Style22.Defaul(w, "Max duration", durationOption(sla.MaxDuration), none)
+	Style22.Defaul(w, "Min throughput", minThroughput, none)
+
+	if sla.LastBreach == nil {
+		Style22.PrintL(w, "Last breach", none)
+
+		return nil
+	}
+
+	Style22.Printf(w, "Last breach:")
+	displaySLABreachInfo(w, Style333, sla.LastBreach)
+
+	return nil
+}
+
+func displaySLABreaches(w io.Writer, breaches []*api.OutSLABreach) error {
+	Style0.PrintV(w, "=== SLA breaches ===")
+
+	for _, breach := range breaches {
+		Style1.Printf(w, "Breach of the %s objective", breach.Kind)
+		displaySLABreachInfo(w, Style22, breach)
+	}
+
+	return nil
+}
+
+func displaySLABreachInfo(w io.Writer, style *style, breach *api.OutSLABreach) {
+	style.PrintL(w, "Kind", breach.Kind)
+	style.PrintL(w, "Date", breach.Date.Local().String())
+	style.PrintL(w, "Detected", breach.Detected.Local().String())
+	style.Defaul(w, "Transfer ID", breach.TransferID, notApplicable)
+	style.PrintL(w, "Details", breach.Details)
+}
+
+// ######################## ADD ##########################
+
+//nolint:lll //struct tags are long
+type SLAAdd struct {
+	Name          string   `required:"yes" short:"n" long:"name" description:"The SLA's name" json:"name,omitempty"`
+	Rule          string   `short:"r" long:"rule" description:"The rule to which the SLA applies" json:"rule,omitempty"`
+	Direction     string   `short:"w" long:"way" description:"The direction of the SLA's rule" choice:"send" choice:"receive" json:"-"`
+	IsSend        *bool    `json:"isSend,omitempty"`
+	Partner       string   `short:"p" long:"partner" description:"The partner to which the SLA applies" json:"partner,omitempty"`
+	Deadline      string   `short:"d" long:"deadline" description:"The time of day (in HH:MM format) before which a transfer must have completed" json:"deadline,omitempty"`
+	DeadlineDays  []string `short:"D" long:"deadline-day" description:"A day of the week on which the deadline applies. Can be repeated. Defaults to monday through friday" json:"deadlineDays,omitempty"`
+	MaxDuration   string   `short:"m" long:"max-duration" description:"The maximum duration of a transfer (e.g. 30m, 2h)" json:"maxDuration,omitempty"`
+	MinThroughput int64    `short:"t" long:"min-throughput" description:"The minimum average throughput of a transfer (in bytes per second)" json:"minThroughput,omitempty"`
+}
+
+func (s *SLAAdd) Execute([]string) error { return execute(s) }
+func (s *SLAAdd) execute(w io.Writer) error {
+	addr.Path = slasAPIPath
+
+	if s.Direction != "" {
+		isSend := s.Direction == directionSend
+		s.IsSend = &isSend
+	}
+
+	if _, err := add(w, s); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "The SLA %q was successfully added.\n", s.Name)
+
+	return nil
+}
+
+// ######################## LIST ##########################
+
+//nolint:lll //struct tags are long
+type SLAList struct {
+	ListOptions
+
+	SortBy string `short:"s" long:"sort" description:"Attribute used to sort the returned entries" choice:"name+" choice:"name-" default:"name+"`
+}
+
+func (s *SLAList) Execute([]string) error { return execute(s) }
+func (s *SLAList) execute(w io.Writer) error {
+	addr.Path = slasAPIPath
+
+	listURL(&s.ListOptions, s.SortBy)
+
+	var body map[string][]*api.OutSLA
+	if err := list(&body); err != nil {
+		return err
+	}
+
+	if slas := body["slas"]; len(slas) > 0 {
+		return outputObject(w, slas, &s.OutputFormat, displaySLAs)
+	}
+
+	fmt.Fprintln(w, "No SLAs found.")
+
+	return nil
+}
+
+// ######################## GET ##########################
+
+type SLAGet struct {
+	OutputFormat
+
+	Args struct {
+		Name string `required:"yes" positional-arg-name:"name" description:"The SLA's name"`
+	} `positional-args:"yes"`
+}
+
+func (s *SLAGet) Execute([]string) error { return execute(s) }
+func (s *SLAGet) execute(w io.Writer) error {
+	addr.Path = path.Join(slasAPIPath, s.Args.Name)
+
+	var sla api.OutSLA
+	if err := get(&sla); err != nil {
+		return err
+	}
+
+	return outputObject(w, &sla, &s.OutputFormat, displaySLA)
+}
+
+// ######################## UPDATE ##########################
+
+//nolint:lll //struct tags are long
+type SLAUpdate struct {
+	Args struct {
+		Name string `required:"yes" positional-arg-name:"name" description:"The old SLA's name"`
+	} `positional-args:"yes" json:"-"`
+
+	Name          *string   `short:"n" long:"name" description:"The SLA's name" json:"name,omitempty"`
+	Rule          *string   `short:"r" long:"rule" description:"The rule to which the SLA applies (empty to remove it)" json:"rule,omitempty"`
+	Direction     string    `short:"w" long:"way" description:"The direction of the SLA's rule" choice:"send" choice:"receive" json:"-"`
+	IsSend        *bool     `json:"isSend,omitempty"`
+	Partner       *string   `short:"p" long:"partner" description:"The partner to which the SLA applies (empty to remove it)" json:"partner,omitempty"`
+	Deadline      *string   `short:"d" long:"deadline" description:"The time of day (in HH:MM format) before which a transfer must have completed" json:"deadline,omitempty"`
+	DeadlineDays  *[]string `short:"D" long:"deadline-day" description:"A day of the week on which the deadline applies. Can be repeated" json:"deadlineDays,omitempty"`
+	MaxDuration   *string   `short:"m" long:"max-duration" description:"The maximum duration of a transfer (e.g. 30m, 2h)" json:"maxDuration,omitempty"`
+	MinThroughput *int64    `short:"t" long:"min-throughput" description:"The minimum average throughput of a transfer (in bytes per second)" json:"minThroughput,omitempty"`
+}
+
+func (s *SLAUpdate) Execute([]string) error { return execute(s) }
+func (s *SLAUpdate) execute(w io.Writer) error {
+	addr.Path = path.Join(slasAPIPath, s.Args.Name)
+
+	if s.Direction != "" {
+		isSend := s.Direction == directionSend
+		s.IsSend = &isSend
+	}
+
+	if err := update(w, s); err != nil {
+		return err
+	}
+
+	name := s.Args.Name
+	if s.Name != nil && *s.Name != "" {
+		name = *s.Name
+	}
+
+	fmt.Fprintf(w, "The SLA %q was successfully updated.\n", name)
+
+	return nil
+}
+
+// ######################## DELETE ##########################
+
+type SLADelete struct {
+	Args struct {
+		Name string `required:"yes" positional-arg-name:"name" description:"The SLA's name"`
+	} `positional-args:"yes"`
+}
+
+func (s *SLADelete) Execute([]string) error { return execute(s) }
+func (s *SLADelete) execute(w io.Writer) error {
+	addr.Path = path.Join(slasAPIPath, s.Args.Name)
+
+	if err := remove(w); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "The SLA %q was successfully deleted.\n", s.Args.Name)
+
+	return nil
+}
+
+// ######################## BREACHES ##########################
+
+//nolint:lll //struct tags are long
+type SLABreaches struct {
+	ListOptions
+
+	Args struct {
+		Name string `required:"yes" positional-arg-name:"name" description:"The SLA's name"`
+	} `positional-args:"yes"`
+
+	SortBy string   `short:"s" long:"sort" description:"Attribute used to sort the returned entries" choice:"detected+" choice:"detected-" choice:"date+" choice:"date-" default:"detected-"`
+	Kinds  []string `short:"k" long:"kind" description:"Filter the breaches based on their kind. Can be repeated." choice:"deadline" choice:"duration" choice:"throughput"`
+}
+
+func (s *SLABreaches) Execute([]string) error { return execute(s) }
+func (s *SLABreaches) execute(w io.Writer) error {
+	addr.Path = path.Join(slasAPIPath, s.Args.Name, "breaches")
+
+	query := url.Values{}
+	query.Set("limit", utils.FormatUint(s.Limit))
+	query.Set("offset", utils.FormatUint(s.Offset))
+	query.Set("sort", s.SortBy)
+
+	for _, kind := range s.Kinds {
+		query.Add("kind", kind)
+	}
+
+	addr.RawQuery = query.Encode()
+
+	var body map[string][]*api.OutSLABreach
+	if err := list(&body); err != nil {
+		return err
+	}
+
+	if breaches := body["breaches"]; len(breaches) > 0 {
+		return outputObject(w, breaches, &s.OutputFormat, displaySLABreaches)
+	}
+
+	fmt.Fprintf(w, "No breaches found for SLA %q.\n", s.Args.Name)
+
+	return nil
+}
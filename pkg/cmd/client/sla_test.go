@@ -0,0 +1,378 @@
+package wg
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSLAAdd(t *testing.T) {
+	const (
+		slaName       = "sla"
+		rule          = "rule"
+		partner       = "partner"
+		deadline      = "07:00"
+		maxDuration   = "30m"
+		minThroughput = 1000.0
+
+		path     = "/api/slas"
+		location = path + "/" + slaName
+	)
+
+	t.Run(`Given the SLA "add" command`, func(t *testing.T) {
+		w := newTestOutput()
+		command := &SLAAdd{}
+
+		expected := &expectedRequest{
+			method: http.MethodPost,
+			path:   path,
+			body: map[string]any{
+				"name":          slaName,
+				"rule":          rule,
+				"isSend":        true,
+				"partner":       partner,
+				"deadline":      deadline,
+				"deadlineDays":  []any{"monday", "tuesday"},
+				"maxDuration":   maxDuration,
+				"minThroughput": minThroughput,
+			},
+		}
+
+		result := &expectedResponse{
+			status:  http.StatusCreated,
+			headers: map[string][]string{"Location": {location}},
+		}
+
+		t.Run("Given a dummy gateway REST interface", func(t *testing.T) {
+			testServer(t, expected, result)
+
+			t.Run("When executing the command", func(t *testing.T) {
+				require.NoError(t, executeCommand(t, w, command,
+					"--name", slaName,
+					"--rule", rule,
+					"--way", directionSend,
+					"--partner", partner,
+					"--deadline", deadline,
+					"--deadline-day", "monday",
+					"--deadline-day", "tuesday",
+					"--max-duration", maxDuration,
+					"--min-throughput", fmt.Sprint(minThroughput),
+				),
+					"Then it should not return an error",
+				)
+
+				assert.Equal(t,
+					fmt.Sprintf("The SLA %q was successfully added.\n", slaName),
+					w.String(),
+					"Then it should display a message saying the SLA was added",
+				)
+			})
+		})
+	})
+}
+
+func TestSLAList(t *testing.T) {
+	const (
+		path = "/api/slas"
+
+		sort   = "name-"
+		limit  = "10"
+		offset = "5"
+	)
+
+	t.Run(`Given the SLA "list" command`, func(t *testing.T) {
+		w := newTestOutput()
+		command := &SLAList{}
+
+		expected := &expectedRequest{
+			method: http.MethodGet,
+			values: map[string][]string{
+				"limit":  {limit},
+				"offset": {offset},
+				"sort":   {sort},
+			},
+			path: path,
+		}
+
+		slas := []map[string]any{{
+			"name":        "sla2",
+			"partner":     "partner",
+			"maxDuration": "1h0m0s",
+			"status":      "OK",
+		}, {
+			"name":          "sla1",
+			"rule":          "rule",
+			"isSend":        false,
+			"deadline":      "07:00",
+			"deadlineDays":  []string{"monday", "friday"},
+			"minThroughput": 1000,
+			"status":        "OK",
+		}}
+
+		result := &expectedResponse{
+			status: http.StatusOK,
+			body:   map[string]any{"slas": slas},
+		}
+
+		t.Run("Given a dummy gateway REST interface", func(t *testing.T) {
+			testServer(t, expected, result)
+
+			t.Run("When executing the command", func(t *testing.T) {
+				require.NoError(t, executeCommand(t, w, command,
+					"--limit", limit, "--offset", offset, "--sort", sort,
+				),
+					"Then it should not return an error",
+				)
+
+				assert.Equal(t,
+					`=== SLAs ===`+"\n"+
+						`-SLA "sla2" [OK]`+"\n"+
+						`  -Rule: <none>`+"\n"+
+						`  -Partner: partner`+"\n"+
+						`  -Deadline: <none>`+"\n"+
+						`  -Max duration: 1h0m0s`+"\n"+
+						`  -Min throughput: <none>`+"\n"+
+						`  -Last breach: <none>`+"\n"+
+						`-SLA "sla1" [OK]`+"\n"+
+						`  -Rule: rule (receive)`+"\n"+
+						`  -Partner: <none>`+"\n"+
+						`  -Deadline: 07:00 (monday, friday)`+"\n"+
+						`  -Max duration: <none>`+"\n"+
+						`  -Min throughput: 1000 B/s`+"\n"+
+						`  -Last breach: <none>`+"\n",
+					w.String(),
+					"Then it should display the SLAs",
+				)
+			})
+		})
+	})
+}
+
+func TestSLAGet(t *testing.T) {
+	const (
+		slaName = "sla"
+
+		path = "/api/slas/" + slaName
+	)
+
+	date := time.Date(2026, 10, 19, 7, 0, 0, 0, time.UTC)
+	detected := date.Add(time.Minute)
+
+	t.Run(`Given the SLA "get" command`, func(t *testing.T) {
+		w := newTestOutput()
+		command := &SLAGet{}
+
+		expected := &expectedRequest{
+			method: http.MethodGet,
+			path:   path,
+		}
+
+		result := &expectedResponse{
+			status: http.StatusOK,
+			body: map[string]any{
+				"name":         slaName,
+				"rule":         "rule",
+				"isSend":       true,
+				"deadline":     "07:00",
+				"deadlineDays": []string{"monday"},
+				"status":       "BREACHED",
+				"lastBreach": map[string]any{
+					"kind":     "deadline",
+					"date":     date,
+					"detected": detected,
+					"details":  "no transfer completed before 07:00 on 2026-10-19",
+				},
+			},
+		}
+
+		t.Run("Given a dummy gateway REST interface", func(t *testing.T) {
+			testServer(t, expected, result)
+
+			t.Run("When executing the command", func(t *testing.T) {
+				require.NoError(t, executeCommand(t, w, command, slaName),
+					"Then it should not return an error")
+
+				assert.Equal(t,
+					`-SLA "sla" [BREACHED]`+"\n"+
+						`  -Rule: rule (send)`+"\n"+
+						`  -Partner: <none>`+"\n"+
+						`  -Deadline: 07:00 (monday)`+"\n"+
+						`  -Max duration: <none>`+"\n"+
+						`  -Min throughput: <none>`+"\n"+
+						`  -Last breach:`+"\n"+
+						`    -Kind: deadline`+"\n"+
+						`    -Date: `+date.Local().String()+"\n"+
+						`    -Detected: `+detected.Local().String()+"\n"+
+						`    -Transfer ID: N/A`+"\n"+
+						`    -Details: no transfer completed before 07:00 on 2026-10-19`+"\n",
+					w.String(),
+					"Then it should display the SLA",
+				)
+			})
+		})
+	})
+}
+
+func TestSLAUpdate(t *testing.T) {
+	const (
+		oldSLAName  = "old_sla"
+		newSLAName  = "new_sla"
+		rule        = "rule"
+		maxDuration = "2h"
+
+		path     = "/api/slas/" + oldSLAName
+		location = "/api/slas/" + newSLAName
+	)
+
+	t.Run(`Given the SLA "update" command`, func(t *testing.T) {
+		w := newTestOutput()
+		command := &SLAUpdate{}
+
+		expected := &expectedRequest{
+			method: http.MethodPatch,
+			path:   path,
+			body: map[string]any{
+				"name":        newSLAName,
+				"rule":        rule,
+				"isSend":      false,
+				"partner":     "",
+				"maxDuration": maxDuration,
+			},
+		}
+
+		result := &expectedResponse{
+			status:  http.StatusCreated,
+			headers: map[string][]string{"Location": {location}},
+		}
+
+		t.Run("Given a dummy gateway REST interface", func(t *testing.T) {
+			testServer(t, expected, result)
+
+			t.Run("When executing the command", func(t *testing.T) {
+				require.NoError(t, executeCommand(t, w, command, oldSLAName,
+					"--name", newSLAName,
+					"--rule", rule,
+					"--way", directionRecv,
+					"--partner", "",
+					"--max-duration", maxDuration,
+				),
+					"Then it should not return an error",
+				)
+
+				assert.Equal(t,
+					fmt.Sprintf("The SLA %q was successfully updated.\n", newSLAName),
+					w.String(),
+					"Then it should display a message saying the SLA was updated",
+				)
+			})
+		})
+	})
+}
+
+func TestSLADelete(t *testing.T) {
+	const (
+		slaName = "sla"
+		path    = "/api/slas/" + slaName
+	)
+
+	t.Run(`Given the SLA "delete" command`, func(t *testing.T) {
+		w := newTestOutput()
+		command := &SLADelete{}
+
+		expected := &expectedRequest{
+			method: http.MethodDelete,
+			path:   path,
+		}
+
+		result := &expectedResponse{
+			status: http.StatusNoContent,
+		}
+
+		t.Run("Given a dummy gateway REST interface", func(t *testing.T) {
+			testServer(t, expected, result)
+
+			t.Run("When executing the command", func(t *testing.T) {
+				require.NoError(t, executeCommand(t, w, command, slaName),
+					"Then it should not return an error")
+
+				assert.Equal(t,
+					fmt.Sprintf("The SLA %q was successfully deleted.\n", slaName),
+					w.String(),
+					"Then it should display a message saying the SLA was deleted",
+				)
+			})
+		})
+	})
+}
+
+func TestSLABreaches(t *testing.T) {
+	const (
+		slaName = "sla"
+		path    = "/api/slas/" + slaName + "/breaches"
+
+		sort   = "date+"
+		limit  = "10"
+		offset = "0"
+	)
+
+	date := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	detected := date.Add(time.Hour)
+
+	t.Run(`Given the SLA "breaches" command`, func(t *testing.T) {
+		w := newTestOutput()
+		command := &SLABreaches{}
+
+		expected := &expectedRequest{
+			method: http.MethodGet,
+			values: map[string][]string{
+				"limit":  {limit},
+				"offset": {offset},
+				"sort":   {sort},
+				"kind":   {"duration", "throughput"},
+			},
+			path: path,
+		}
+
+		breaches := []map[string]any{{
+			"kind":       "duration",
+			"transferID": 2,
+			"date":       date,
+			"detected":   detected,
+			"details":    "transfer n°2 took 1h0m0s to complete (maximum: 30m0s)",
+		}}
+
+		result := &expectedResponse{
+			status: http.StatusOK,
+			body:   map[string]any{"breaches": breaches},
+		}
+
+		t.Run("Given a dummy gateway REST interface", func(t *testing.T) {
+			testServer(t, expected, result)
+
+			t.Run("When executing the command", func(t *testing.T) {
+				require.NoError(t, executeCommand(t, w, command, slaName,
+					"--limit", limit, "--offset", offset, "--sort", sort,
+					"--kind", "duration", "--kind", "throughput",
+				),
+					"Then it should not return an error",
+				)
+
+				assert.Equal(t,
+					`=== SLA breaches ===`+"\n"+
+						`-Breach of the duration objective`+"\n"+
+						`  -Kind: duration`+"\n"+
+						`  -Date: `+date.Local().String()+"\n"+
+						`  -Detected: `+detected.Local().String()+"\n"+
+						`  -Transfer ID: 2`+"\n"+
+						`  -Details: transfer n°2 took 1h0m0s to complete (maximum: 30m0s)`+"\n",
+					w.String(),
+					"Then it should display the SLA's breaches",
+				)
+			})
+		})
+	})
+}
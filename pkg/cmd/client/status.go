@@ -37,6 +37,7 @@ func showStatus(w io.Writer, title string, services []*api.Service) {
 	var (
 		running = "[" + color.HiGreen.Sprint("ACTIVE ") + "]"
 		inError = "[" + color.HiRed.Sprint("ERROR  ") + "]"
+		drained = "[" + color.HiYellow.Sprint("DRAIN  ") + "]"
 		offline = "[" + color.Gray.Sprint("OFFLINE") + "]"
 
 		nameColor = color.Bold
@@ -44,7 +45,7 @@ func showStatus(w io.Writer, title string, services []*api.Service) {
 
 	Style1.PrintV(w, title+":")
 
-	var errors, actives, offlines []*api.Service
+	var errors, drainings, actives, offlines []*api.Service
 
 	sort.Slice(services, func(i, j int) bool {
 		return services[i].Name < services[j].Name
@@ -56,6 +57,8 @@ func showStatus(w io.Writer, title string, services []*api.Service) {
 			actives = append(actives, service)
 		case utils.StateError.String():
 			errors = append(errors, service)
+		case utils.StateDraining.String():
+			drainings = append(drainings, service)
 		default:
 			offlines = append(offlines, service)
 		}
@@ -66,6 +69,11 @@ func showStatus(w io.Writer, title string, services []*api.Service) {
 			nameColor.Render(service.Name), service.Reason)
 	}
 
+	for _, service := range drainings {
+		color.Fprintf(w, "%s%s %s (%s)\n", Style22.bulletPrefix, drained,
+			nameColor.Render(service.Name), service.Reason)
+	}
+
 	for _, service := range actives {
 		color.Fprintf(w, "%s%s %s\n", Style22.bulletPrefix, running,
 			nameColor.Render(service.Name))
@@ -0,0 +1,108 @@
+package wg
+
+import (
+	"fmt"
+	"io"
+	"path"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/admin/rest/api"
+)
+
+// storedTextKind describes a kind of named text stored in the database (such
+// as scripts or copybooks). All these kinds are managed the same way, so the
+// commands share the functions below.
+type storedTextKind struct {
+	name    string // the name of the kind (ex: "script")
+	title   string // the capitalized name of the kind (ex: "Script")
+	plural  string // the plural name, also used as key in the REST list response
+	apiPath string
+}
+
+func (k *storedTextKind) displayAll(w io.Writer, texts []*api.GetStoredTextObject) error {
+	Style0.PrintV(w, fmt.Sprintf("=== %ss ===", k.title))
+	for _, text := range texts {
+		if err := k.display(w, text); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (k *storedTextKind) display(w io.Writer, text *api.GetStoredTextObject) error {
+	Style1.Printf(w, "%s %q", k.title, text.Name)
+	Style22.MultiL(w, "Content", text.Content)
+
+	return nil
+}
+
+func (k *storedTextKind) add(w io.Writer, object any, name string) error {
+	addr.Path = k.apiPath
+
+	if _, err := add(w, object); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "The %s %q was successfully added.\n", k.name, name)
+
+	return nil
+}
+
+func (k *storedTextKind) list(w io.Writer, opts *ListOptions, sortBy string) error {
+	addr.Path = k.apiPath
+
+	listURL(opts, sortBy)
+
+	var body map[string][]*api.GetStoredTextObject
+	if err := list(&body); err != nil {
+		return err
+	}
+
+	if texts := body[k.plural]; len(texts) > 0 {
+		return outputObject(w, texts, &opts.OutputFormat, k.displayAll)
+	}
+
+	fmt.Fprintf(w, "No %s found.\n", k.plural)
+
+	return nil
+}
+
+func (k *storedTextKind) get(w io.Writer, name string, format *OutputFormat) error {
+	addr.Path = path.Join(k.apiPath, name)
+
+	var text api.GetStoredTextObject
+	if err := get(&text); err != nil {
+		return err
+	}
+
+	return outputObject(w, &text, format, k.display)
+}
+
+func (k *storedTextKind) update(w io.Writer, object any, oldName, newName string) error {
+	addr.Path = path.Join(k.apiPath, oldName)
+
+	if err := update(w, object); err != nil {
+		return err
+	}
+
+	name := oldName
+	if newName != "" {
+		name = newName
+	}
+
+	fmt.Fprintf(w, "The %s %q was successfully updated.\n", k.name, name)
+
+	return nil
+}
+
+func (k *storedTextKind) remove(w io.Writer, name string) error {
+	addr.Path = path.Join(k.apiPath, name)
+
+	if err := remove(w); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "The %s %q was successfully deleted.\n", k.name, name)
+
+	return nil
+}
@@ -57,6 +57,7 @@ func displayTransfer(w io.Writer, trans *api.OutTransfer) error {
 	Style22.PrintL(w, "Requested by", trans.Requester)
 	Style22.PrintL(w, "Requested to", trans.Requested)
 	Style22.Option(w, "With client", trans.Client)
+	Style22.Option(w, "Running on node", trans.Node)
 	Style22.PrintL(w, "Full local path", trans.LocalFilepath)
 	Style22.PrintL(w, "Full remote path", trans.RemoteFilepath)
 	Style22.PrintL(w, "File size", prettyBytes(trans.Filesize))
@@ -163,6 +164,48 @@ func (t *TransferGet) execute(w io.Writer) error {
 	return outputObject(w, trans, &t.OutputFormat, displayTransfer)
 }
 
+// ######################## UPDATE ##########################
+
+//nolint:lll //struct tags are long
+type TransferUpdate struct {
+	Args struct {
+		ID uint64 `required:"yes" positional-arg-name:"id" description:"The transfer's ID"`
+	} `positional-args:"yes" json:"-"`
+
+	Date                 *string            `short:"d" long:"date" description:"The new starting date (in ISO 8601 format) of the transfer" json:"start,omitempty"`
+	File                 *string            `short:"f" long:"file" description:"The new source file of the transfer" json:"srcFilename,omitempty"`
+	Out                  *string            `short:"o" long:"out" description:"The new destination file of the transfer" json:"destFilename,omitempty"`
+	TransferInfo         map[string]confVal `short:"i" long:"info" description:"Custom information about the transfer, in key:val format. Use 'key:null' to remove a key. Can be repeated." json:"transferInfo,omitempty"`
+	NumberOfAttempts     *int8              `long:"nb-of-attempts" description:"The number of times the transfer will be automatically retried if it failed" json:"nbOfAttempts,omitempty"`
+	FirstRetryDelayDur   time.Duration      `long:"retry-delay" description:"The amount of time between automatic retries. Accepted units: 's', 'm' & 'h'" json:"-"`
+	FirstRetryDelay      int32              `json:"firstRetryDelay,omitempty"`
+	RetryIncrementFactor *float32           `long:"retry-increment-factor" description:"The factor by which the retry delay will increase after each retry" json:"retryIncrementFactor,omitempty"`
+}
+
+func (t *TransferUpdate) Execute([]string) error { return execute(t) }
+func (t *TransferUpdate) execute(w io.Writer) error {
+	addr.Path = fmt.Sprintf("/api/transfers/%d", t.Args.ID)
+
+	if t.Date != nil {
+		if _, err := time.Parse(time.RFC3339Nano, *t.Date); err != nil {
+			return fmt.Errorf("'%s' is not a valid date (accepted format: '%s'): %w",
+				*t.Date, time.RFC3339Nano, errInvalidDate)
+		}
+	}
+
+	if t.FirstRetryDelayDur != 0 {
+		t.FirstRetryDelay = int32(t.FirstRetryDelayDur.Seconds())
+	}
+
+	if err := update(w, t); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "The transfer \"%d\" was successfully updated.\n", t.Args.ID)
+
+	return nil
+}
+
 // ######################## LIST ##########################
 
 //nolint:lll // struct tags can be long for command line args
@@ -172,9 +215,12 @@ type TransferList struct {
 	SortBy string   `short:"s" long:"sort" description:"Attribute used to sort the returned entries" choice:"start+" choice:"start-" choice:"id+" choice:"id-" choice:"rule+" choice:"rule-" default:"start+"`
 	Rules  []string `short:"r" long:"rule" description:"Filter the transfers based on the name of the transfer rule used. Can be repeated multiple times to filter multiple rules."`
 	//nolint:misspell //spelling mistake CANCELLED must be kept for backward compatibility
-	Statuses []string `short:"t" long:"status" description:"Filter the transfers based on the transfer's status. Can be repeated multiple times to filter multiple statuses." choice:"PLANNED" choice:"RUNNING" choice:"INTERRUPTED" choice:"PAUSED" choice:"CANCELLED" choice:"DONE" choice:"ERROR"`
-	Start    string   `short:"d" long:"date" description:"Filter the transfers which started after a given date. Date must be in ISO 8601 format."`
-	FollowID string   `short:"f" long:"follow-id" description:"Filter the transfers based on their follow ID."`
+	Statuses   []string `short:"t" long:"status" description:"Filter the transfers based on the transfer's status. Can be repeated multiple times to filter multiple statuses." choice:"PLANNED" choice:"RUNNING" choice:"INTERRUPTED" choice:"PAUSED" choice:"CANCELLED" choice:"DONE" choice:"ERROR"`
+	Start      string   `short:"d" long:"date" description:"Filter the transfers which started after a given date. Date must be in ISO 8601 format."`
+	End        string   `short:"e" long:"end" description:"Filter the transfers which started before a given date. Date must be in ISO 8601 format."`
+	Partners   []string `short:"p" long:"partner" description:"Filter the transfers based on the partner (or local server) with which they are made. Can be repeated multiple times to filter multiple partners."`
+	ErrorCodes []string `short:"c" long:"error-code" description:"Filter the transfers based on their error code. Can be repeated multiple times to filter multiple error codes."`
+	FollowID   string   `short:"f" long:"follow-id" description:"Filter the transfers based on their follow ID."`
 }
 
 func (t *TransferList) listURL() error {
@@ -184,26 +230,18 @@ func (t *TransferList) listURL() error {
 	query.Set("offset", utils.FormatUint(t.Offset))
 	query.Set("sort", t.SortBy)
 
-	for _, rule := range t.Rules {
-		query.Add("rule", rule)
-	}
-
-	for _, status := range t.Statuses {
-		query.Add("status", status)
-	}
-
-	if t.Start != "" {
-		_, err := time.Parse(time.RFC3339Nano, t.Start)
-		if err != nil {
-			return fmt.Errorf("'%s' is not a valid date (accepted format: '%s'): %w",
-				t.Start, time.RFC3339Nano, errInvalidDate)
-		}
-
-		query.Set("start", t.Start)
+	filters := &TransferFilters{
+		Rules:      t.Rules,
+		Partners:   t.Partners,
+		Statuses:   t.Statuses,
+		Start:      t.Start,
+		End:        t.End,
+		ErrorCodes: t.ErrorCodes,
+		FollowID:   t.FollowID,
 	}
 
-	if t.FollowID != "" {
-		query.Set("followID", t.FollowID)
+	if err := filters.addTo(query); err != nil {
+		return err
 	}
 
 	addr.RawQuery = query.Encode()
@@ -236,13 +274,19 @@ func (t *TransferList) execute(w io.Writer) error {
 // ######################## PAUSE ##########################
 
 type TransferPause struct {
+	BulkTransferOptions
+
 	Args struct {
-		ID uint64 `required:"yes" positional-arg-name:"id" description:"The transfer's ID"`
+		ID uint64 `positional-arg-name:"id" description:"The transfer's ID. If omitted, all the transfers matching the filters are paused."`
 	} `positional-args:"yes"`
 }
 
 func (t *TransferPause) Execute([]string) error { return execute(t) }
 func (t *TransferPause) execute(w io.Writer) error {
+	if t.Args.ID == 0 {
+		return bulkTransferOperation(w, &t.BulkTransferOptions, "pause", "paused", nil)
+	}
+
 	return putTransferRequest(w, t.Args.ID, "pause",
 		`paused. It can be resumed using the "resume" command`)
 }
@@ -250,13 +294,19 @@ func (t *TransferPause) execute(w io.Writer) error {
 // ######################## RESUME ##########################
 
 type TransferResume struct {
+	BulkTransferOptions
+
 	Args struct {
-		ID uint64 `required:"yes" positional-arg-name:"id" description:"The transfer's ID"`
+		ID uint64 `positional-arg-name:"id" description:"The transfer's ID. If omitted, all the transfers matching the filters are resumed."`
 	} `positional-args:"yes"`
 }
 
 func (t *TransferResume) Execute([]string) error { return execute(t) }
 func (t *TransferResume) execute(w io.Writer) error {
+	if t.Args.ID == 0 {
+		return bulkTransferOperation(w, &t.BulkTransferOptions, "resume", "resumed", nil)
+	}
+
 	return putTransferRequest(w, t.Args.ID, "resume", "resumed")
 }
 
@@ -273,12 +323,28 @@ func (t *TransferCancel) execute(w io.Writer) error {
 	return putTransferRequest(w, t.Args.ID, "cancel", "canceled")
 }
 
+// ######################## INTERRUPT ##########################
+
+type TransferInterrupt struct {
+	Args struct {
+		ID uint64 `required:"yes" positional-arg-name:"id" description:"The transfer's ID"`
+	} `positional-args:"yes"`
+}
+
+func (t *TransferInterrupt) Execute([]string) error { return execute(t) }
+func (t *TransferInterrupt) execute(w io.Writer) error {
+	return putTransferRequest(w, t.Args.ID, "interrupt",
+		`interrupted. It can be resumed using the "resume" command`)
+}
+
 // ######################## RESTART ##########################
 
 //nolint:lll // struct tags can be long for command line args
 type TransferRetry struct {
+	BulkTransferOptions
+
 	Args struct {
-		ID uint64 `required:"yes" positional-arg-name:"id" description:"The transfer's ID"`
+		ID uint64 `positional-arg-name:"id" description:"The transfer's ID. If omitted, all the finished transfers matching the filters are retried."`
 	} `positional-args:"yes"`
 	Date string `short:"d" long:"date" description:"Set the date at which the transfer should restart. Date must be in RFC3339 format."`
 }
@@ -287,8 +353,6 @@ func (t *TransferRetry) Execute([]string) error { return execute(t) }
 
 //nolint:dupl //history & transfer commands should be kept separate for future-proofing
 func (t *TransferRetry) execute(w io.Writer) error {
-	addr.Path = fmt.Sprintf("/api/transfers/%d/retry", t.Args.ID)
-
 	query := url.Values{}
 
 	if t.Date != "" {
@@ -301,6 +365,11 @@ func (t *TransferRetry) execute(w io.Writer) error {
 		query.Set("date", start.Format(time.RFC3339Nano))
 	}
 
+	if t.Args.ID == 0 {
+		return bulkTransferOperation(w, &t.BulkTransferOptions, "retry", "retried", query)
+	}
+
+	addr.Path = fmt.Sprintf("/api/transfers/%d/retry", t.Args.ID)
 	addr.RawQuery = query.Encode()
 
 	ctx, cancel := context.WithTimeout(context.Background(), httpTimeout)
@@ -333,6 +402,29 @@ func (t *TransferRetry) execute(w io.Writer) error {
 	}
 }
 
+// ######################## RESCHEDULE ##########################
+
+//nolint:lll // struct tags can be long for command line args
+type TransferReschedule struct {
+	BulkTransferOptions
+
+	Date string `required:"yes" short:"d" long:"date" description:"The date at which the transfers should be (re)started. Date must be in RFC3339 format."`
+}
+
+func (t *TransferReschedule) Execute([]string) error { return execute(t) }
+func (t *TransferReschedule) execute(w io.Writer) error {
+	date, err := time.Parse(time.RFC3339Nano, t.Date)
+	if err != nil {
+		return fmt.Errorf("'%s' is not a valid date (accepted format: '%s'): %w",
+			t.Date, time.RFC3339Nano, errInvalidDate)
+	}
+
+	query := url.Values{}
+	query.Set("date", date.Format(time.RFC3339Nano))
+
+	return bulkTransferOperation(w, &t.BulkTransferOptions, "reschedule", "rescheduled", query)
+}
+
 //nolint:lll // struct tags can be long for command line args
 type TransferCancelAll struct {
 	Target string `required:"yes" short:"t" long:"target" description:"The status of the transfers to cancel" choice:"planned" choice:"running" choice:"paused" choice:"interrupted" choice:"error" choice:"all"`
@@ -0,0 +1,207 @@
+package wg
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/admin/rest/api"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils"
+)
+
+// TransferFilters are the options used to select the transfers targeted by a
+// bulk operation. They are the same as the filters of the "list" command.
+//
+//nolint:lll // struct tags can be long for command line args
+type TransferFilters struct {
+	Rules    []string `short:"r" long:"rule" description:"Filter the transfers based on the name of the transfer rule used. Can be repeated multiple times to filter multiple rules."`
+	Partners []string `short:"p" long:"partner" description:"Filter the transfers based on the partner (or local server) with which they are made. Can be repeated multiple times to filter multiple partners."`
+	//nolint:misspell //spelling mistake CANCELLED must be kept for backward compatibility
+	Statuses   []string `short:"t" long:"status" description:"Filter the transfers based on the transfer's status. Can be repeated multiple times to filter multiple statuses." choice:"PLANNED" choice:"RUNNING" choice:"INTERRUPTED" choice:"PAUSED" choice:"CANCELLED" choice:"DONE" choice:"ERROR"`
+	Start      string   `short:"s" long:"start" description:"Filter the transfers which started after a given date. Date must be in ISO 8601 format."`
+	End        string   `short:"e" long:"end" description:"Filter the transfers which started before a given date. Date must be in ISO 8601 format."`
+	ErrorCodes []string `short:"c" long:"error-code" description:"Filter the transfers based on their error code. Can be repeated multiple times to filter multiple error codes."`
+	FollowID   string   `short:"f" long:"follow-id" description:"Filter the transfers based on their follow ID."`
+}
+
+func (f *TransferFilters) isEmpty() bool {
+	return len(f.Rules) == 0 && len(f.Partners) == 0 && len(f.Statuses) == 0 &&
+		f.Start == "" && f.End == "" && len(f.ErrorCodes) == 0 && f.FollowID == ""
+}
+
+func (f *TransferFilters) addTo(query url.Values) error {
+	for _, rule := range f.Rules {
+		query.Add("rule", rule)
+	}
+
+	for _, partner := range f.Partners {
+		query.Add("partner", partner)
+	}
+
+	for _, status := range f.Statuses {
+		query.Add("status", status)
+	}
+
+	for _, code := range f.ErrorCodes {
+		query.Add("errorCode", code)
+	}
+
+	for param, date := range map[string]string{"start": f.Start, "end": f.End} {
+		if date == "" {
+			continue
+		}
+
+		if _, err := time.Parse(time.RFC3339Nano, date); err != nil {
+			return fmt.Errorf("'%s' is not a valid date (accepted format: '%s'): %w",
+				date, time.RFC3339Nano, errInvalidDate)
+		}
+
+		query.Set(param, date)
+	}
+
+	if f.FollowID != "" {
+		query.Set("followID", f.FollowID)
+	}
+
+	return nil
+}
+
+// BulkTransferOptions are the options of the commands which can be applied in
+// bulk to all the transfers matching a set of filters.
+//
+//nolint:lll // struct tags can be long for command line args
+type BulkTransferOptions struct {
+	TransferFilters
+
+	DryRun    bool `short:"n" long:"dry-run" description:"Only display the number and the first IDs of the transfers matching the filters, without modifying them."`
+	BatchSize uint `short:"b" long:"batch-size" description:"The number of transfers processed by each request sent to the gateway." default:"100"`
+}
+
+// bulkTransferOperation sends the given bulk operation to the gateway for all
+// the transfers matching the filters. The transfers are processed in batches,
+// and the result of each transfer, along with the overall progress, is
+// displayed as soon as each batch is done.
+func bulkTransferOperation(w io.Writer, opts *BulkTransferOptions, endpoint,
+	action string, extra url.Values,
+) error {
+	if opts.isEmpty() {
+		return fmt.Errorf("at least one filter must be given to select the transfers: %w",
+			errBadArgs)
+	}
+
+	query := url.Values{}
+	if err := opts.addTo(query); err != nil {
+		return err
+	}
+
+	for key, vals := range extra {
+		query[key] = vals
+	}
+
+	query.Set("limit", utils.FormatUint(opts.BatchSize))
+
+	if opts.DryRun {
+		query.Set("dryRun", "true")
+
+		result, err := sendBulkTransferRequest(endpoint, query)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(w, "%d transfer(s) would be %s.\n", result.Total, action)
+
+		if len(result.IDs) != 0 {
+			ids := make([]string, len(result.IDs))
+			for i, id := range result.IDs {
+				ids[i] = utils.FormatInt(id)
+			}
+
+			fmt.Fprintf(w, "First matching transfer(s): %s\n", strings.Join(ids, ", "))
+		}
+
+		return nil
+	}
+
+	result, err := sendBulkTransferRequest(endpoint, query)
+	if err != nil {
+		return err
+	}
+
+	total := result.Total
+	if total == 0 {
+		fmt.Fprintln(w, "No transfers match the given filters.")
+
+		return nil
+	}
+
+	var succeeded, failed int
+
+	for len(result.Results) != 0 {
+		for _, item := range result.Results {
+			switch {
+			case !item.Success:
+				failed++
+
+				fmt.Fprintf(w, "Transfer %d: failed (%s)\n", item.ID, item.Error)
+			case item.NewID != 0:
+				succeeded++
+
+				fmt.Fprintf(w, "Transfer %d: %s under the ID %d\n", item.ID, action, item.NewID)
+			default:
+				succeeded++
+
+				fmt.Fprintf(w, "Transfer %d: %s\n", item.ID, action)
+			}
+
+			query.Set("after", utils.FormatInt(item.ID))
+		}
+
+		fmt.Fprintf(w, "Progress: %d/%d transfer(s) processed\n", succeeded+failed, total)
+
+		if uint(len(result.Results)) < opts.BatchSize {
+			break
+		}
+
+		if result, err = sendBulkTransferRequest(endpoint, query); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(w, "%d transfer(s) were successfully %s, %d failed.\n", succeeded, action, failed)
+
+	return nil
+}
+
+func sendBulkTransferRequest(endpoint string, query url.Values) (*api.OutBulkTransferResult, error) {
+	addr.Path = path.Join(apiTransfersPath, endpoint)
+	addr.RawQuery = query.Encode()
+
+	ctx, cancel := context.WithTimeout(context.Background(), httpTimeout)
+	defer cancel()
+
+	resp, err := sendRequest(ctx, nil, http.MethodPut)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() //nolint:errcheck,gosec // error is irrelevant
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var result api.OutBulkTransferResult
+		if err := unmarshalBody(resp.Body, &result); err != nil {
+			return nil, err
+		}
+
+		return &result, nil
+	case http.StatusBadRequest:
+		return nil, getResponseErrorMessage(resp)
+	default:
+		return nil, fmt.Errorf("unexpected error (%s): %w", resp.Status,
+			getResponseErrorMessage(resp))
+	}
+}
@@ -0,0 +1,180 @@
+package wg
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferPauseBulk(t *testing.T) {
+	const (
+		path = "/api/transfers/pause"
+
+		rule    = "rule"
+		partner = "partner"
+		status  = "RUNNING"
+	)
+
+	t.Run(`Given the transfer "pause" command without an ID`, func(t *testing.T) {
+		w := newTestOutput()
+		command := &TransferPause{}
+
+		expected := &expectedRequest{
+			method: http.MethodPut,
+			path:   path,
+			values: url.Values{
+				"rule": {rule}, "partner": {partner}, "status": {status},
+				"limit": {"10"},
+			},
+		}
+
+		result := &expectedResponse{
+			status: http.StatusOK,
+			body: map[string]any{
+				"dryRun": false,
+				"total":  2,
+				"results": []map[string]any{
+					{"id": 1, "success": true},
+					{"id": 2, "success": false, "error": "cannot pause transfer"},
+				},
+			},
+		}
+
+		t.Run("Given a dummy gateway REST interface", func(t *testing.T) {
+			testServer(t, expected, result)
+
+			t.Run("When executing the command", func(t *testing.T) {
+				require.NoError(t, executeCommand(t, w, command,
+					"--rule", rule, "--partner", partner, "--status", status,
+					"--batch-size", "10",
+				),
+					"Then it should not return an error",
+				)
+
+				assert.Equal(t,
+					"Transfer 1: paused\n"+
+						"Transfer 2: failed (cannot pause transfer)\n"+
+						"Progress: 2/2 transfer(s) processed\n"+
+						"1 transfer(s) were successfully paused, 1 failed.\n",
+					w.String(),
+					"Then it should display the result of each transfer",
+				)
+			})
+		})
+	})
+
+	t.Run(`Given the transfer "pause" command without an ID or filters`, func(t *testing.T) {
+		w := newTestOutput()
+		command := &TransferPause{}
+
+		t.Run("When executing the command", func(t *testing.T) {
+			require.ErrorIs(t, executeCommand(t, w, command), errBadArgs,
+				"Then it should return an error")
+		})
+	})
+}
+
+func TestTransferRetryBulk(t *testing.T) {
+	const (
+		path = "/api/transfers/retry"
+
+		errorCode = "TeConnection"
+		date      = "2030-01-01T00:00:00Z"
+	)
+
+	t.Run(`Given the transfer "retry" command in dry run mode`, func(t *testing.T) {
+		w := newTestOutput()
+		command := &TransferRetry{}
+
+		expected := &expectedRequest{
+			method: http.MethodPut,
+			path:   path,
+			values: url.Values{
+				"errorCode": {errorCode}, "date": {date}, "dryRun": {"true"},
+				"limit": {"100"},
+			},
+		}
+
+		result := &expectedResponse{
+			status: http.StatusOK,
+			body: map[string]any{
+				"dryRun": true, "total": 42, "results": []any{},
+				"ids": []any{1, 2, 3},
+			},
+		}
+
+		t.Run("Given a dummy gateway REST interface", func(t *testing.T) {
+			testServer(t, expected, result)
+
+			t.Run("When executing the command", func(t *testing.T) {
+				require.NoError(t, executeCommand(t, w, command,
+					"--error-code", errorCode, "--date", date, "--dry-run",
+				),
+					"Then it should not return an error",
+				)
+
+				assert.Equal(t,
+					"42 transfer(s) would be retried.\n"+
+						"First matching transfer(s): 1, 2, 3\n",
+					w.String(),
+					"Then it should display the number and IDs of the matching transfers",
+				)
+			})
+		})
+	})
+}
+
+func TestTransferReschedule(t *testing.T) {
+	const (
+		path = "/api/transfers/reschedule"
+
+		start = "2026-01-01T00:00:00Z"
+		end   = "2026-02-01T00:00:00Z"
+		date  = "2030-01-01T00:00:00Z"
+	)
+
+	t.Run(`Given the transfer "reschedule" command`, func(t *testing.T) {
+		w := newTestOutput()
+		command := &TransferReschedule{}
+
+		expected := &expectedRequest{
+			method: http.MethodPut,
+			path:   path,
+			values: url.Values{
+				"start": {start}, "end": {end}, "date": {date}, "limit": {"100"},
+			},
+		}
+
+		result := &expectedResponse{
+			status: http.StatusOK,
+			body: map[string]any{
+				"dryRun":  false,
+				"total":   1,
+				"results": []map[string]any{{"id": 5, "success": true}},
+			},
+		}
+
+		t.Run("Given a dummy gateway REST interface", func(t *testing.T) {
+			testServer(t, expected, result)
+
+			t.Run("When executing the command", func(t *testing.T) {
+				require.NoError(t, executeCommand(t, w, command,
+					"--start", start, "--end", end, "--date", date,
+				),
+					"Then it should not return an error",
+				)
+
+				assert.Equal(t,
+					"Transfer 5: rescheduled\n"+
+						"Progress: 1/1 transfer(s) processed\n"+
+						"1 transfer(s) were successfully rescheduled, 0 failed.\n",
+					w.String(),
+					"Then it should display the result of each transfer",
+				)
+			})
+		})
+	})
+}
@@ -221,6 +221,59 @@ func TestTransferGet(t *testing.T) {
 	})
 }
 
+func TestTransferUpdate(t *testing.T) {
+	const (
+		id   = "1234"
+		date = "2030-01-01T00:00:00Z"
+		file = "new_file"
+
+		path = "/api/transfers/" + id
+	)
+
+	t.Run(`Testing the transfer "update" command`, func(t *testing.T) {
+		w := newTestOutput()
+		command := &TransferUpdate{}
+
+		expected := &expectedRequest{
+			method: http.MethodPatch,
+			path:   path,
+			body: map[string]any{
+				"start":           date,
+				"srcFilename":     file,
+				"firstRetryDelay": float64(60),
+				"transferInfo":    map[string]any{"key1": nil, "key2": "val2"},
+			},
+		}
+
+		result := &expectedResponse{
+			status:  http.StatusCreated,
+			headers: map[string][]string{"Location": {path}},
+		}
+
+		t.Run("Given a dummy gateway REST interface", func(t *testing.T) {
+			testServer(t, expected, result)
+
+			t.Run("When executing the command", func(t *testing.T) {
+				require.NoError(t, executeCommand(t, w, command, id,
+					"--date", date,
+					"--file", file,
+					"--retry-delay", "1m",
+					"--info", "key1:null",
+					"--info", "key2:val2",
+				),
+					"Then it should not return an error",
+				)
+
+				assert.Equal(t,
+					fmt.Sprintf("The transfer %q was successfully updated.\n", id),
+					w.String(),
+					"Then it should display a message saying the transfer was updated",
+				)
+			})
+		})
+	})
+}
+
 func TestTransferList(t *testing.T) {
 	const (
 		path = "/api/transfers"
@@ -501,6 +554,42 @@ func TestTransferCancel(t *testing.T) {
 	})
 }
 
+func TestTransferInterrupt(t *testing.T) {
+	const (
+		id = "1234"
+
+		path = "/api/transfers/" + id + "/interrupt"
+	)
+
+	t.Run(`Testing the transfer "interrupt" command`, func(t *testing.T) {
+		w := newTestOutput()
+		command := &TransferInterrupt{}
+
+		expected := &expectedRequest{
+			method: http.MethodPut,
+			path:   path,
+		}
+
+		result := &expectedResponse{status: http.StatusAccepted}
+
+		t.Run("Given a dummy gateway REST interface", func(t *testing.T) {
+			testServer(t, expected, result)
+
+			t.Run("When executing the command", func(t *testing.T) {
+				require.NoError(t, executeCommand(t, w, command, id),
+					"Then it should not return an error")
+
+				assert.Equal(t,
+					fmt.Sprintf("The transfer %q was successfully interrupted. "+
+						"It can be resumed using the \"resume\" command.\n", id),
+					w.String(),
+					"Then it should display a message saying the transfer was interrupted",
+				)
+			})
+		})
+	})
+}
+
 func TestTransferRetry(t *testing.T) {
 	const (
 		id    = "1234"
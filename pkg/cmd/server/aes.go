@@ -2,20 +2,28 @@ package wgd
 
 import (
 	"crypto/cipher"
+	"errors"
 	"fmt"
+	"io/fs"
+	"os"
+	"slices"
 
 	"code.waarp.fr/apps/gateway/gateway/pkg/conf"
 	"code.waarp.fr/apps/gateway/gateway/pkg/database"
 	"code.waarp.fr/apps/gateway/gateway/pkg/model"
 	"code.waarp.fr/apps/gateway/gateway/pkg/model/authentication/auth"
+	"code.waarp.fr/apps/gateway/gateway/pkg/protocols/modules/pesit"
 	"code.waarp.fr/apps/gateway/gateway/pkg/protocols/modules/r66"
 	"code.waarp.fr/apps/gateway/gateway/pkg/protocols/modules/sftp"
+	"code.waarp.fr/apps/gateway/gateway/pkg/snmp"
 	parse "code.waarp.fr/apps/gateway/gateway/pkg/tk/config"
 )
 
+//nolint:lll // tags can be long for flags
 type ChangeAESPassphrase struct {
 	ConfigFile string `short:"c" long:"config" description:"The configuration file to use"`
-	NewFile    string `short:"f" long:"file" description:"The file containing the new AES passphrase"`
+	NewFile    string `short:"f" long:"file" description:"The file containing the new AES passphrase. If the file does not exist, a new random passphrase is generated (and wrapped if a key wrapper is configured)."`
+	NoKeepOld  bool   `long:"no-keep-previous" description:"Do not keep the previous passphrase in the configuration. Only use this if no other gateway instance is still running with the previous passphrase."`
 }
 
 func (c *ChangeAESPassphrase) Execute([]string) error {
@@ -28,7 +36,18 @@ func (c *ChangeAESPassphrase) Execute([]string) error {
 }
 
 func (c *ChangeAESPassphrase) run(db *database.DB) error {
-	newAEAD, gcmErr := database.NewAEAD(c.NewFile)
+	wrapper, wrapErr := database.GetKeyWrapper(&db.Config.Database)
+	if wrapErr != nil {
+		return wrapErr
+	}
+
+	if _, err := os.Stat(c.NewFile); errors.Is(err, fs.ErrNotExist) {
+		if genErr := database.GenerateAESKeyFile(c.NewFile, wrapper); genErr != nil {
+			return fmt.Errorf("failed to generate the new AES passphrase: %w", genErr)
+		}
+	}
+
+	newAEAD, gcmErr := database.LoadAEAD(c.NewFile, wrapper)
 	if gcmErr != nil {
 		return fmt.Errorf("failed to load the AES passphrase file: %w", gcmErr)
 	}
@@ -43,7 +62,19 @@ func (c *ChangeAESPassphrase) run(db *database.DB) error {
 		return err
 	}
 
-	db.Config.Database.AESPassphrase = c.NewFile
+	dbConf := &db.Config.Database
+	oldFile := dbConf.AESPassphrase
+	dbConf.AESPassphrase = c.NewFile
+
+	dbConf.AESPreviousPassphrases = slices.DeleteFunc(dbConf.AESPreviousPassphrases,
+		func(file string) bool { return file == c.NewFile || file == oldFile })
+
+	if c.NoKeepOld {
+		dbConf.AESPreviousPassphrases = nil
+	} else if oldFile != "" && oldFile != c.NewFile {
+		dbConf.AESPreviousPassphrases = append([]string{oldFile}, dbConf.AESPreviousPassphrases...)
+	}
+
 	serverConfig = *db.Config
 
 	if err := parser.WriteFile(c.ConfigFile); err != nil {
@@ -61,6 +92,15 @@ func (*noHookLocalAgent) TableName() string                 { return model.Table
 func (*noHookLocalAgent) Appellation() string               { return model.NameLocalAgent }
 func (*noHookLocalAgent) AfterUpdate(database.Access) error { return nil }
 
+// changeAgentsAESPassphrase re-encrypts all the secrets of the gateway with
+// the given AEAD. Only the secrets belonging to this gateway (as given by the
+// GatewayName setting) are re-encrypted, since the other gateways sharing the
+// database have their own AES passphrase. For all the tables with an owner,
+// this filter is added by the select queries themselves. The credentials have
+// no owner of their own, so they are filtered on the owner of the agent or
+// account they belong to.
+//
+//nolint:funlen //splitting the function would add complexity
 func changeAgentsAESPassphrase(db *database.DB, newAEAD cipher.AEAD) error {
 	owner := db.Config.GatewayName
 
@@ -78,7 +118,8 @@ func changeAgentsAESPassphrase(db *database.DB, newAEAD cipher.AEAD) error {
 		remote_account_id IN (SELECT id FROM remote_accounts WHERE
 			remote_agent_id IN (SELECT id FROM remote_agents WHERE owner=?))`,
 		owner, owner, owner, owner).
-		In("type", auth.Password, auth.TLSCertificate, sftp.AuthSSHPrivateKey).Run(); err != nil {
+		In("type", auth.Password, auth.TLSCertificate, auth.ACMECertificate,
+			sftp.AuthSSHPrivateKey, pesit.PreConnectionAuth).Run(); err != nil {
 		return fmt.Errorf("failed to retrieve the credentials: %w", err)
 	}
 
@@ -87,6 +128,31 @@ func changeAgentsAESPassphrase(db *database.DB, newAEAD cipher.AEAD) error {
 		return fmt.Errorf("failed to retrieve the cloud instances: %w", err)
 	}
 
+	var msgBrokers model.MessageBrokers
+	if err := db.Select(&msgBrokers).Where("password<>''").Run(); err != nil {
+		return fmt.Errorf("failed to retrieve the message brokers: %w", err)
+	}
+
+	var cryptoKeys model.Slice[*model.CryptoKey]
+	if err := db.Select(&cryptoKeys).Run(); err != nil {
+		return fmt.Errorf("failed to retrieve the cryptographic keys: %w", err)
+	}
+
+	var smtpCreds model.Slice[*model.SMTPCredential]
+	if err := db.Select(&smtpCreds).Run(); err != nil {
+		return fmt.Errorf("failed to retrieve the SMTP credentials: %w", err)
+	}
+
+	var snmpServers model.Slice[*snmp.ServerConfig]
+	if err := db.Select(&snmpServers).Run(); err != nil {
+		return fmt.Errorf("failed to retrieve the SNMP server configuration: %w", err)
+	}
+
+	var snmpMonitors model.Slice[*snmp.MonitorConfig]
+	if err := db.Select(&snmpMonitors).Run(); err != nil {
+		return fmt.Errorf("failed to retrieve the SNMP monitors: %w", err)
+	}
+
 	db.ChangeAEAD(newAEAD)
 
 	//nolint:wrapcheck //wrapping adds nothing here
@@ -109,6 +175,36 @@ func changeAgentsAESPassphrase(db *database.DB, newAEAD cipher.AEAD) error {
 			}
 		}
 
+		for _, broker := range msgBrokers {
+			if err := db.Update(broker).Run(); err != nil {
+				return fmt.Errorf("failed to update the message broker %q: %w", broker.Name, err)
+			}
+		}
+
+		for _, key := range cryptoKeys {
+			if err := db.Update(key).Run(); err != nil {
+				return fmt.Errorf("failed to update the cryptographic key %q: %w", key.Name, err)
+			}
+		}
+
+		for _, smtpCred := range smtpCreds {
+			if err := db.Update(smtpCred).Run(); err != nil {
+				return fmt.Errorf("failed to update the SMTP credential %q: %w", smtpCred.EmailAddress, err)
+			}
+		}
+
+		for _, server := range snmpServers {
+			if err := db.Update(server).Run(); err != nil {
+				return fmt.Errorf("failed to update the SNMP server configuration: %w", err)
+			}
+		}
+
+		for _, monitor := range snmpMonitors {
+			if err := db.Update(monitor).Run(); err != nil {
+				return fmt.Errorf("failed to update the SNMP monitor %q: %w", monitor.Name, err)
+			}
+		}
+
 		return nil
 	})
 }
@@ -11,6 +11,7 @@ import (
 
 	. "github.com/smartystreets/goconvey/convey"
 
+	"code.waarp.fr/apps/gateway/gateway/pkg/brokers/brokerstest"
 	"code.waarp.fr/apps/gateway/gateway/pkg/conf"
 	"code.waarp.fr/apps/gateway/gateway/pkg/database"
 	"code.waarp.fr/apps/gateway/gateway/pkg/fs/fstest"
@@ -36,9 +37,14 @@ func TestChangeAESPassphrase(t *testing.T) {
 		servTLSKey  = testhelpers.LocalhostKey
 
 		cloudSecret = "cld_sec"
+
+		brokerPassword = "brk_pwd"
+
+		cryptoKeyValue = "0123456789abcdefhijklABCDEFHIJKL"
 	)
 
 	cloudType := fstest.MakeDummyBackend(t)
+	brokerType, _ := brokerstest.MakeDummyBackend(t)
 
 	Convey("Given a test database", t, func(c C) {
 		db := database.TestDatabase(c)
@@ -110,6 +116,42 @@ func TestChangeAESPassphrase(t *testing.T) {
 		}
 		So(db.Insert(cloud).Run(), ShouldBeNil)
 
+		broker := &model.MessageBroker{
+			Name:     "broker",
+			Type:     brokerType,
+			Address:  "localhost:5672",
+			Login:    "login",
+			Password: brokerPassword,
+		}
+		So(db.Insert(broker).Run(), ShouldBeNil)
+
+		cryptoKey := &model.CryptoKey{
+			Name: "aes-key",
+			Type: model.CryptoKeyTypeAES,
+			Key:  cryptoKeyValue,
+		}
+		So(db.Insert(cryptoKey).Run(), ShouldBeNil)
+
+		// A secret belonging to another gateway sharing the same database.
+		owner := db.Config.GatewayName
+		db.Config.GatewayName = "other_gateway"
+
+		otherCloud := &model.CloudInstance{
+			Name:   "other_cloud",
+			Type:   cloudType,
+			Secret: cloudSecret,
+		}
+		So(db.Insert(otherCloud).Run(), ShouldBeNil)
+
+		db.Config.GatewayName = owner
+
+		var otherCipherText string
+		So(db.QueryRow("SELECT secret FROM cloud_instances WHERE id=?", otherCloud.ID).
+			Scan(&otherCipherText), ShouldBeNil)
+
+		oldPassphrase := filepath.Join(testDir, "old_aes.key")
+		db.Config.Database.AESPassphrase = oldPassphrase
+
 		Convey("Given a new AES passphrase", func() {
 			newPassphrase := make([]byte, 32)
 
@@ -201,7 +243,7 @@ func TestChangeAESPassphrase(t *testing.T) {
 					})
 
 					Convey("Then the cloud secrets should have been re-encrypted", func() {
-						row := db.QueryRow("SELECT secret FROM cloud_instances")
+						row := db.QueryRow("SELECT secret FROM cloud_instances WHERE id=?", cloud.ID)
 
 						var cipherText string
 						So(row.Scan(&cipherText), ShouldBeNil)
@@ -210,6 +252,37 @@ func TestChangeAESPassphrase(t *testing.T) {
 						So(aesErr, ShouldBeNil)
 						So(pswd, ShouldEqual, cloudSecret)
 					})
+
+					Convey("Then the secrets of the other gateways should not have been modified", func() {
+						row := db.QueryRow("SELECT secret FROM cloud_instances WHERE id=?", otherCloud.ID)
+
+						var cipherText string
+						So(row.Scan(&cipherText), ShouldBeNil)
+						So(cipherText, ShouldEqual, otherCipherText)
+					})
+
+					Convey("Then the message brokers passwords should have been re-encrypted", func() {
+						row := db.QueryRow("SELECT password FROM message_brokers")
+
+						var cipherText string
+						So(row.Scan(&cipherText), ShouldBeNil)
+
+						pswd, aesErr := database.AESDecrypt(newAEAD, cipherText)
+						So(aesErr, ShouldBeNil)
+						So(pswd, ShouldEqual, brokerPassword)
+					})
+
+					Convey("Then the cryptographic keys should have been re-encrypted", func() {
+						row := db.QueryRow("SELECT value FROM crypto_keys")
+
+						var cipherText string
+						So(row.Scan(&cipherText), ShouldBeNil)
+						So(cipherText, ShouldStartWith, "$"+database.KeyID(newAEAD)+"$")
+
+						key, aesErr := database.AESDecrypt(newAEAD, cipherText)
+						So(aesErr, ShouldBeNil)
+						So(key, ShouldEqual, cryptoKeyValue)
+					})
 				})
 
 				Convey("Then the AES passphrase file should have been changed", func() {
@@ -224,6 +297,11 @@ func TestChangeAESPassphrase(t *testing.T) {
 
 					So(parser.ParseFile(configFile), ShouldBeNil)
 					So(serverConfig.Database.AESPassphrase, ShouldEqual, aesFile)
+
+					Convey("Then the previous passphrase should have been kept", func() {
+						So(serverConfig.Database.AESPreviousPassphrases, ShouldResemble,
+							[]string{oldPassphrase})
+					})
 				})
 			})
 		})
@@ -11,6 +11,7 @@ import (
 	"code.waarp.fr/apps/gateway/gateway/pkg/database"
 	"code.waarp.fr/apps/gateway/gateway/pkg/logging"
 	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
+	"code.waarp.fr/apps/gateway/gateway/pkg/secrets"
 )
 
 var ErrResetPipe = errors.New("cannot use -r without -s")
@@ -48,6 +49,10 @@ func initImportExport(configFile string, verbose []bool) (*database.DB, *log.Log
 		return nil, nil, fmt.Errorf("cannot initialize log backend: %w", err2)
 	}
 
+	if err = secrets.Configure(&config.Secrets); err != nil {
+		return nil, nil, fmt.Errorf("cannot configure the secret providers: %w", err)
+	}
+
 	db := database.NewDB(config)
 
 	err = db.Start()
@@ -62,7 +67,7 @@ func initImportExport(configFile string, verbose []bool) (*database.DB, *log.Log
 type ImportCommand struct {
 	ConfigFile string   `short:"c" long:"config" description:"The configuration file to use."`
 	File       string   `short:"s" long:"source" description:"The data file to import. If none is given, the content will be read from the standard output."`
-	Target     []string `short:"t" long:"target" default:"all" choice:"rules" choice:"servers" choice:"partners" choice:"clients" choice:"users" choice:"clouds" choice:"snmp" choice:"authorities" choice:"keys" choice:"email" choice:"filewatchers" choice:"all" description:"Limit the import to a subset of data. Can be repeated to import multiple subsets."`
+	Target     []string `short:"t" long:"target" default:"all" choice:"rules" choice:"servers" choice:"partners" choice:"clients" choice:"users" choice:"clouds" choice:"brokers" choice:"scripts" choice:"copybooks" choice:"snmp" choice:"authorities" choice:"keys" choice:"email" choice:"filewatchers" choice:"retry_policies" choice:"all" description:"Limit the import to a subset of data. Can be repeated to import multiple subsets."`
 	Dry        bool     `short:"d" long:"dry-run" description:"Do not make any changes, but simulate the import of the file."`
 	Verbose    []bool   `short:"v" long:"verbose" description:"Show verbose debug information. Can be repeated to increase verbosity."`
 	Reset      bool     `short:"r" long:"reset-before-import" description:"Empty the database tables before importing the elements from the file. Cannot be used without the -s option."`
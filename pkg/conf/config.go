@@ -30,11 +30,16 @@ type ServerConfig struct {
 	NodeID    string
 	Overrides *ConfigOverride
 
-	Paths      PathsConfig      `group:"paths"`
-	Log        LogConfig        `group:"log"`
-	Admin      AdminConfig      `group:"admin"`
-	Database   DatabaseConfig   `group:"database"`
-	Controller ControllerConfig `group:"controller"`
+	Paths        PathsConfig        `group:"paths"`
+	Log          LogConfig          `group:"log"`
+	Admin        AdminConfig        `group:"admin"`
+	Database     DatabaseConfig     `group:"database"`
+	Controller   ControllerConfig   `group:"controller"`
+	Secrets      SecretsConfig      `group:"secrets"`
+	ACME         ACMEConfig         `group:"acme"`
+	Certificates CertificatesConfig `group:"certificates"`
+	Revocation   RevocationConfig   `group:"revocation"`
+	SLA          SLAConfig          `group:"sla"`
 }
 
 // PathsConfig holds the server paths.
@@ -67,12 +72,13 @@ type LogConfig struct {
 //
 //nolint:lll // cannot split struct tags
 type AdminConfig struct {
-	Host          string `ini-name:"Host" default:"0.0.0.0" description:"The address used by the admin interface."`
-	Port          uint16 `ini-name:"Port" default:"8080" description:"The port used by the admin interface. If the port is 0, a random free port will automatically be chosen."`
-	DisableWebUI  bool   `ini-name:"DisableWebUI" description:"If set to true, the web admin interface will be disabled."`
-	TLSCert       string `ini-name:"TLSCert" description:"Path of the TLS certificate for the admin interface."`
-	TLSKey        string `ini-name:"TLSKey" description:"Path of the key of the TLS certificate."`
-	TLSPassphrase string `ini-name:"TLSPassphrase" description:""`
+	Host          string   `ini-name:"Host" default:"0.0.0.0" description:"The address used by the admin interface."`
+	Port          uint16   `ini-name:"Port" default:"8080" description:"The port used by the admin interface. If the port is 0, a random free port will automatically be chosen."`
+	DisableWebUI  bool     `ini-name:"DisableWebUI" description:"If set to true, the web admin interface will be disabled."`
+	TLSCert       string   `ini-name:"TLSCert" description:"Path of the TLS certificate for the admin interface."`
+	TLSKey        string   `ini-name:"TLSKey" description:"Path of the key of the TLS certificate."`
+	TLSPassphrase string   `ini-name:"TLSPassphrase" description:""`
+	ACMEDomains   []string `ini-name:"ACMEDomain" description:"A domain name for which the admin interface's TLS certificate is obtained from the ACME server. The certificate is written to the TLSCert & TLSKey files. Can be repeated."`
 }
 
 // DatabaseConfig holds the server database options.
@@ -87,6 +93,9 @@ type DatabaseConfig struct {
 	TLSCert       string `ini-name:"TLSCert" description:"Path of the database TLS certificate file."`
 	TLSKey        string `ini-name:"TLSKey" description:"Path of the key of the TLS certificate file."`
 	AESPassphrase string `ini-name:"AESPassphrase" default:"passphrase.aes" description:"The path to the file containing the passphrase used to encrypt account passwords using AES"`
+
+	AESPreviousPassphrases []string `ini-name:"AESPreviousPassphrase" description:"The path to a file containing a previous AES passphrase, which is still used to decrypt the secrets encrypted with it. Can be repeated."`
+	AESKeyWrapper          string   `ini-name:"AESKeyWrapper" description:"The external KMS key used to wrap the AES passphrases (envelope encryption), of the form 'vault-transit://<mount>/<key name>'. When set, the passphrase files contain the wrapped passphrases."`
 }
 
 // ControllerConfig holds the transfer controller options.
@@ -96,6 +105,69 @@ type ControllerConfig struct {
 	Delay           time.Duration `ini-name:"Delay" default:"5s" description:"The frequency at which the database will be probed for new transfers"`
 	MaxTransfersIn  uint64        `ini-name:"MaxTransferIn" description:"The maximum number of concurrent incoming transfers allowed on the gateway (0 = unlimited)."`
 	MaxTransfersOut uint64        `ini-name:"MaxTransferOut" description:"The maximum number of concurrent outgoing transfers allowed on the gateway (0 = unlimited)."`
+	NodeLease       time.Duration `ini-name:"NodeLease" default:"30s" description:"In a cluster, the duration after which a node which stopped sending heartbeats is considered dead, and its transfers are taken over by the other nodes"`
+}
+
+// SecretsConfig holds the options of the external secret stores used to
+// resolve secret references.
+//
+//nolint:lll // cannot split struct tags
+type SecretsConfig struct {
+	CacheTTL       time.Duration `ini-name:"CacheTTL" default:"5m" description:"How long a resolved secret reference is kept in memory before being fetched again (0 = no cache)."`
+	VaultAddress   string        `ini-name:"VaultAddress" description:"The address of the HashiCorp Vault server used to resolve 'ref+vault://' references. Defaults to the VAULT_ADDR environment variable."`
+	VaultToken     string        `ini-name:"VaultToken" description:"The token used to authenticate with Vault. Defaults to the VAULT_TOKEN environment variable."`
+	VaultTokenFile string        `ini-name:"VaultTokenFile" description:"The path of a file containing the token used to authenticate with Vault. Takes precedence over VaultToken."`
+	VaultNamespace string        `ini-name:"VaultNamespace" description:"The Vault namespace (Vault Enterprise only)."`
+	VaultCACert    string        `ini-name:"VaultCACert" description:"The path of the CA certificate used to validate the Vault server's TLS certificate."`
+}
+
+// ACMEConfig holds the options of the ACME server used to automatically obtain
+// and renew the TLS certificates of the gateway's servers.
+//
+//nolint:lll // cannot split struct tags
+type ACMEConfig struct {
+	DirectoryURL         string        `ini-name:"DirectoryURL" description:"The URL of the ACME server's directory. If empty, ACME is disabled."`
+	Email                string        `ini-name:"Email" description:"The contact email address of the ACME account."`
+	AccountKey           string        `ini-name:"AccountKey" default:"acme_account.key" description:"The path of the file containing the private key of the ACME account. If the file does not exist, a new key is generated."`
+	CACert               string        `ini-name:"CACert" description:"The path of the CA certificate used to validate the ACME server's TLS certificate."`
+	HTTPChallengeAddress string        `ini-name:"HTTPChallengeAddress" description:"The address on which HTTP-01 challenges are served (ex: ':80'). If empty, TLS-ALPN-01 challenges are used instead."`
+	RenewBefore          time.Duration `ini-name:"RenewBefore" default:"720h" description:"How long before its expiration a certificate is renewed."`
+	CheckInterval        time.Duration `ini-name:"CheckInterval" default:"1h" description:"How often the certificates are checked for renewal."`
+}
+
+// CertificatesConfig holds the options of the monitoring of the expiration
+// dates of the certificates & keys stored in the database.
+//
+//nolint:lll // cannot split struct tags
+type CertificatesConfig struct {
+	CheckInterval   time.Duration `ini-name:"CheckInterval" default:"12h" description:"How often the certificates & keys are checked for expiration (0 = disabled)."`
+	AlertThresholds []uint        `ini-name:"AlertThreshold" description:"The number of days before expiration at which an alert is sent. Can be repeated to send multiple alerts. Defaults to 30, 7 and 1 days."`
+	EmailSender     string        `ini-name:"EmailSender" description:"The address of the SMTP credential used to send the expiration alerts by email. If empty, no email is sent."`
+	EmailRecipients []string      `ini-name:"EmailRecipient" description:"The address to which the expiration alerts are sent by email. Can be repeated to send the alerts to multiple addresses."`
+	EmailTemplate   string        `ini-name:"EmailTemplate" description:"The name of the email template used for the expiration alerts. If empty, a default message is used."`
+}
+
+// RevocationConfig holds the options of the revocation checks (CRL & OCSP)
+// performed on the TLS certificates of the transfer partners and clients.
+//
+//nolint:lll // cannot split struct tags
+type RevocationConfig struct {
+	Policy        string        `ini-name:"Policy" default:"none" description:"The default revocation checking policy: 'none' (no check), 'soft-fail' (only revoked certificates are refused) or 'hard-fail' (certificates whose status cannot be determined are also refused). Can be overridden per authority."`
+	DisableCRL    bool          `ini-name:"DisableCRL" description:"If set to true, the certificates will not be checked against their issuer's CRL."`
+	DisableOCSP   bool          `ini-name:"DisableOCSP" description:"If set to true, the certificates' status will not be checked with their issuer's OCSP responder. Otherwise, OCSP takes precedence over the CRL when both are available."`
+	CacheDuration time.Duration `ini-name:"CacheDuration" default:"1h" description:"The maximum duration for which a CRL or an OCSP response is kept in memory before being fetched again."`
+	Timeout       time.Duration `ini-name:"Timeout" default:"5s" description:"The timeout of the requests made to the CRL distribution points and the OCSP responders."`
+	OCSPStapling  bool          `ini-name:"OCSPStapling" description:"If set to true, the gateway's TLS servers will staple an OCSP response of their own certificate in the TLS handshakes."`
+}
+
+// SLAConfig holds the options of the monitoring of the transfers' SLAs.
+//
+//nolint:lll // cannot split struct tags
+type SLAConfig struct {
+	CheckInterval   time.Duration `ini-name:"CheckInterval" default:"1m" description:"How often the SLAs are evaluated (0 = disabled)."`
+	EmailSender     string        `ini-name:"EmailSender" description:"The address of the SMTP credential used to send the SLA breach alerts by email. If empty, no email is sent."`
+	EmailRecipients []string      `ini-name:"EmailRecipient" description:"The address to which the SLA breach alerts are sent by email. Can be repeated to send the alerts to multiple addresses."`
+	EmailTemplate   string        `ini-name:"EmailTemplate" description:"The name of the email template used for the SLA breach alerts. If empty, a default message is used."`
 }
 
 func normalizePaths(configFile *ServerConfig, logger *log.Logger) error {
@@ -16,6 +16,8 @@ import (
 // Run checks the database for new planned transfers and starts
 // them, as long as there are available transfer slots.
 func (c *Controller) Run() {
+	c.takeOverOrphanedTransfers()
+	c.runClusterCommands()
 	c.runClientTransfers()
 	c.checkServerTransfers()
 }
@@ -109,7 +111,21 @@ func (c *Controller) retrieveClientTransfers() (model.Transfers, error) {
 	var transfers model.Transfers
 
 	if tErr := c.DB.Transaction(func(ses *database.Session) error {
+		if pipeline.List.IsGatewayDraining() {
+			return nil // the gateway is being drained, no new transfers can be started
+		}
+
 		lim := pipeline.List.GetAvailableOut()
+
+		if c.DB.Config.NodeID != "" {
+			clusterLim, err := pipeline.GetClusterAvailableOut(ses)
+			if err != nil {
+				return fmt.Errorf("failed to retrieve the cluster's transfer count: %w", err)
+			}
+
+			lim = min(lim, clusterLim)
+		}
+
 		if lim == 0 {
 			return nil // cannot start more transfers, limit has been reached
 		}
@@ -119,6 +135,10 @@ func (c *Controller) retrieveClientTransfers() (model.Transfers, error) {
 			Where("remote_account_id IS NOT NULL").
 			Where("next_retry <= ?", time.Now().UTC())
 
+		if draining := pipeline.List.DrainingClients(); len(draining) != 0 {
+			query.Where("client_id NOT IN ?", draining)
+		}
+
 		if lim <= math.MaxInt {
 			query.Limit(int(lim), 0)
 		}
@@ -0,0 +1,372 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/filewatcher"
+	"code.waarp.fr/apps/gateway/gateway/pkg/gatewayd/services"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/types"
+	"code.waarp.fr/apps/gateway/gateway/pkg/pipeline"
+)
+
+const (
+	clusterCommandTimeout = 5 * time.Second
+
+	// leaseRenewals is the number of times a node renews its lease during the
+	// lease's duration, so that a late renewal does not cause the lease to
+	// expire.
+	leaseRenewals = 3
+)
+
+var ErrNodeLeaseTooShort = errors.New("the node lease must be longer than twice the controller delay")
+
+// checkNodeLease checks that the nodes' lease is long enough compared to the
+// controller's delay. Otherwise, the node's transfers could be taken over by
+// the other nodes while the node is simply busy.
+func (c *Controller) checkNodeLease() error {
+	if c.DB.Config.NodeID == "" {
+		return nil
+	}
+
+	lease := model.NodeLease(c.DB)
+	if delay := c.DB.Config.Controller.Delay; lease <= 2*delay {
+		return fmt.Errorf("%w (lease: %s, delay: %s)", ErrNodeLeaseTooShort, lease, delay)
+	}
+
+	return nil
+}
+
+// renewLeases periodically renews the node's leases until the controller is
+// stopped. The leases are renewed separately from the controller's other
+// operations, so that a slow database scan cannot delay the renewal.
+func (c *Controller) renewLeases() {
+	ticker := time.NewTicker(model.NodeLease(c.DB) / leaseRenewals)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+			c.heartbeat()
+		}
+	}
+}
+
+// resetNodeTransfers interrupts the transfers which were still registered as
+// running on the current node when it was last stopped. Since the node has just
+// started, these transfers cannot actually be running anymore.
+func (c *Controller) resetNodeTransfers() error {
+	node := c.DB.Config.NodeID
+
+	if err := c.DB.Exec(`UPDATE transfers SET status=? WHERE status=? AND id IN
+		(SELECT transfer_id FROM running_transfers WHERE node=?)`,
+		types.StatusInterrupted, types.StatusRunning, node,
+	); err != nil {
+		return fmt.Errorf("failed to interrupt the node's transfers: %w", err)
+	}
+
+	if err := c.DB.DeleteAll(&model.RunningTransfer{}).Where("node=?", node).Run(); err != nil {
+		return fmt.Errorf("failed to reset the node's running transfers: %w", err)
+	}
+
+	return nil
+}
+
+// registerClusterNode adds (or resets) the current node's entry in the
+// cluster's node registry.
+func (c *Controller) registerClusterNode() error {
+	node := c.DB.Config.NodeID
+
+	var entry model.ClusterNode
+	if err := c.DB.Get(&entry, "name=?", node).Run(); err != nil && !database.IsNotFound(err) {
+		return fmt.Errorf("failed to retrieve the node's registry entry: %w", err)
+	}
+
+	now := time.Now()
+	entry.Name = node
+	entry.StartedAt = now
+	entry.LastHeartbeat = now
+	entry.LeaseExpiry = now.Add(model.NodeLease(c.DB))
+
+	if hostname, err := os.Hostname(); err == nil {
+		entry.Hostname = hostname
+	}
+
+	if entry.ID == 0 {
+		if err := c.DB.Insert(&entry).Run(); err != nil {
+			return fmt.Errorf("failed to register the node: %w", err)
+		}
+	} else if err := c.DB.Update(&entry).Run(); err != nil {
+		return fmt.Errorf("failed to register the node: %w", err)
+	}
+
+	c.node = &entry
+
+	return nil
+}
+
+// heartbeat renews the current node's lease in the cluster's node registry, as
+// well as its leases on the transfers it is currently executing. The transfers
+// on which the node has lost its lease are then stopped.
+func (c *Controller) heartbeat() {
+	node := c.DB.Config.NodeID
+	if node == "" {
+		return
+	}
+
+	if c.node == nil {
+		if err := c.registerClusterNode(); err != nil {
+			c.logger.Errorf("Failed to register node %q: %v", node, err)
+
+			return
+		}
+	}
+
+	now := time.Now()
+	c.node.LastHeartbeat = now
+	c.node.LeaseExpiry = now.Add(model.NodeLease(c.DB))
+	c.node.Services = localServices()
+
+	if err := c.DB.Update(c.node).Cols("last_heartbeat", "lease_expiry",
+		"services").Run(); err != nil {
+		c.logger.Errorf("Failed to renew the lease of node %q: %v", node, err)
+
+		return
+	}
+
+	if err := c.DB.Exec("UPDATE running_transfers SET lease_expiry=? WHERE node=?",
+		c.node.LeaseExpiry.UTC(), node); err != nil {
+		c.logger.Errorf("Failed to renew the leases of the node's transfers: %v", err)
+
+		return
+	}
+
+	c.fenceLostTransfers()
+}
+
+// fenceLostTransfers stops the transfers running on the current node whose
+// lease has been lost (meaning their running transfer entry was deleted, or
+// taken over by another node). Since another node may now be executing these
+// transfers, they must not be continued locally.
+func (c *Controller) fenceLostTransfers() {
+	lost, err := pipeline.List.LostLeases(c.DB, c.DB.Config.NodeID)
+	if err != nil {
+		c.logger.Errorf("Failed to check the leases of the node's transfers: %v", err)
+
+		return
+	}
+
+	for _, pip := range lost {
+		transID := pip.TransCtx.Transfer.ID
+
+		c.logger.Warningf("The node has lost its lease on transfer %d, the "+
+			"transfer is stopped", transID)
+
+		c.wg.Add(1)
+
+		go func() {
+			defer c.wg.Done()
+
+			ctx, cancel := context.WithTimeout(c.ctx, clusterCommandTimeout)
+			defer cancel()
+
+			if err := pip.Fence(ctx); err != nil {
+				c.logger.Errorf("Failed to stop transfer %d: %v", transID, err)
+			}
+		}()
+	}
+}
+
+// localServices returns the current state of all the node's services (core
+// services, servers, clients and file watchers), so that it can be shared with
+// the other nodes of the cluster.
+func localServices() model.Map[model.ServiceState] {
+	states := model.Map[model.ServiceState]{}
+
+	addState := func(serv services.Service, draining bool) {
+		code, reason := pipeline.DrainedState(serv, draining)
+		states[serv.Name()] = model.ServiceState{State: code.String(), Reason: reason}
+	}
+
+	for _, serv := range services.Core {
+		addState(serv, false)
+	}
+
+	services.Servers.Range(func(id int64, serv services.Server) bool {
+		addState(serv, pipeline.List.IsServerDraining(id))
+
+		return true
+	})
+
+	services.Clients.Range(func(id int64, cli services.Client) bool {
+		addState(cli, pipeline.List.IsClientDraining(id))
+
+		return true
+	})
+
+	filewatcher.Filewatchers.Range(func(_ int64, fw *filewatcher.Service) bool {
+		addState(fw, false)
+
+		return true
+	})
+
+	return states
+}
+
+// takeOverOrphanedTransfers requeues the transfers whose lease has expired
+// (meaning the node executing them has stopped renewing it, most likely
+// because it crashed). These transfers are set as interrupted, so that they
+// can be resumed by the surviving nodes of the cluster.
+func (c *Controller) takeOverOrphanedTransfers() {
+	node := c.DB.Config.NodeID
+	if node == "" {
+		return
+	}
+
+	var orphans model.RunningTransfers
+
+	if err := c.DB.Transaction(func(ses *database.Session) error {
+		if err := ses.SelectForUpdate(&orphans).Where("node<>? AND lease_expiry<?",
+			node, time.Now().UTC()).Run(); err != nil {
+			return fmt.Errorf("failed to retrieve the orphaned transfers: %w", err)
+		}
+
+		for _, orphan := range orphans {
+			if err := ses.Exec("UPDATE transfers SET status=? WHERE id=? AND status=?",
+				types.StatusInterrupted, orphan.TransferID, types.StatusRunning); err != nil {
+				return fmt.Errorf("failed to requeue transfer %d: %w", orphan.TransferID, err)
+			}
+
+			if err := ses.Delete(orphan).Run(); err != nil {
+				return fmt.Errorf("failed to delete the running transfer entry of "+
+					"transfer %d: %w", orphan.TransferID, err)
+			}
+		}
+
+		return nil
+	}); err != nil {
+		c.logger.Errorf("Failed to take over the orphaned transfers: %v", err)
+
+		return
+	}
+
+	for _, orphan := range orphans {
+		c.logger.Warningf("The lease of node %q on transfer %d has expired, the "+
+			"transfer has been interrupted so that it can be resumed", orphan.Node,
+			orphan.TransferID)
+	}
+}
+
+// runClusterCommands executes the commands sent by the other nodes of the
+// cluster to the transfers running on the current node.
+func (c *Controller) runClusterCommands() {
+	node := c.DB.Config.NodeID
+	if node == "" {
+		return
+	}
+
+	var entries model.RunningTransfers
+	if err := c.DB.Select(&entries).Where("node=? AND command<>?", node,
+		model.TransferCommandNone).Run(); err != nil {
+		c.logger.Errorf("Failed to retrieve the cluster transfer commands: %v", err)
+
+		return
+	}
+
+	for _, entry := range entries {
+		command := entry.Command
+
+		entry.Command = model.TransferCommandNone
+		if err := c.DB.Update(entry).Cols("command").Run(); err != nil {
+			c.logger.Errorf("Failed to acknowledge the %s command of transfer %d: %v",
+				command, entry.TransferID, err)
+
+			continue
+		}
+
+		pip := pipeline.List.Get(entry.TransferID)
+		if pip == nil {
+			c.logger.Warningf("Pipeline for transfer %d not found", entry.TransferID)
+			c.applyStaleCommand(entry, command)
+
+			continue
+		}
+
+		c.logger.Infof("Executing the %s command received for transfer %d",
+			command, entry.TransferID)
+
+		c.wg.Add(1)
+
+		go func() {
+			defer c.wg.Done()
+
+			ctx, cancel := context.WithTimeout(c.ctx, clusterCommandTimeout)
+			defer cancel()
+
+			var err error
+
+			switch command {
+			case model.TransferCommandPause:
+				err = pip.Pause(ctx)
+			case model.TransferCommandInterrupt:
+				err = pip.Interrupt(ctx)
+			case model.TransferCommandCancel:
+				err = pip.Cancel(ctx)
+			default:
+			}
+
+			if err != nil {
+				c.logger.Errorf("Failed to execute the %s command of transfer %d: %v",
+					command, entry.TransferID, err)
+			}
+		}()
+	}
+}
+
+// applyStaleCommand applies the given command directly on the database entry
+// of a transfer which is registered on the current node, but which has no
+// pipeline (because it has already ended, or because the node was restarted).
+func (c *Controller) applyStaleCommand(entry *model.RunningTransfer, command model.TransferCommand) {
+	if err := c.DB.Delete(entry).Run(); err != nil {
+		c.logger.Errorf("Failed to delete the running transfer entry of transfer %d: %v",
+			entry.TransferID, err)
+	}
+
+	var trans model.Transfer
+	if err := c.DB.Get(&trans, "id=?", entry.TransferID).Eager().Run(); err != nil {
+		c.logger.Errorf("Failed to retrieve transfer %d: %v", entry.TransferID, err)
+
+		return
+	}
+
+	if trans.Status != types.StatusRunning {
+		return
+	}
+
+	var err error
+
+	switch command {
+	case model.TransferCommandPause:
+		trans.Status = types.StatusPaused
+		err = c.DB.Update(&trans).Cols("status").Run()
+	case model.TransferCommandInterrupt:
+		trans.Status = types.StatusInterrupted
+		err = c.DB.Update(&trans).Cols("status").Run()
+	case model.TransferCommandCancel:
+		trans.Status = types.StatusCancelled
+		err = trans.MoveToHistory(c.DB, c.logger, time.Time{})
+	default:
+	}
+
+	if err != nil {
+		c.logger.Errorf("Failed to apply the %s command to transfer %d: %v",
+			command, entry.TransferID, err)
+	}
+}
@@ -0,0 +1,239 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/types"
+	"code.waarp.fr/apps/gateway/gateway/pkg/pipeline"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils/testhelpers"
+)
+
+func TestControllerClusterCommands(t *testing.T) {
+	Convey("Given a clustered gateway", t, func(c C) {
+		logger := testhelpers.TestLogger(c, "test_controller_cluster")
+		db := database.TestDatabase(c)
+		node := db.Config.NodeID
+
+		client := &model.Client{Name: "client", Protocol: testProtocol}
+		So(db.Insert(client).Run(), ShouldBeNil)
+
+		remote := &model.RemoteAgent{
+			Name: "test remote", Protocol: client.Protocol,
+			Address: types.Addr("localhost", 1111),
+		}
+		So(db.Insert(remote).Run(), ShouldBeNil)
+
+		account := &model.RemoteAccount{RemoteAgentID: remote.ID, Login: "test login"}
+		So(db.Insert(account).Run(), ShouldBeNil)
+
+		rule := &model.Rule{Name: "test rule", IsSend: true}
+		So(db.Insert(rule).Run(), ShouldBeNil)
+
+		mkRunning := func(file, owner string, command model.TransferCommand,
+			lease ...time.Time,
+		) *model.Transfer {
+			trans := &model.Transfer{
+				RuleID:          rule.ID,
+				ClientID:        client.NullableID(),
+				RemoteAccountID: account.NullableID(),
+				SrcFilename:     file,
+				Start:           time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC),
+				Status:          types.StatusRunning,
+			}
+			So(db.Insert(trans).Run(), ShouldBeNil)
+
+			entry := &model.RunningTransfer{
+				TransferID: trans.ID,
+				Node:       owner,
+				Command:    command,
+			}
+			if len(lease) > 0 {
+				entry.LeaseExpiry = lease[0]
+			}
+
+			So(db.Insert(entry).Run(), ShouldBeNil)
+
+			return trans
+		}
+
+		cont := &Controller{
+			DB:     db,
+			logger: logger,
+			wg:     new(sync.WaitGroup),
+			ctx:    context.Background(),
+		}
+
+		Convey("Given commands sent to transfers without pipeline", func() {
+			paused := mkRunning("file_1", node, model.TransferCommandPause)
+			canceled := mkRunning("file_2", node, model.TransferCommandCancel)
+			other := mkRunning("file_3", "other_node", model.TransferCommandPause)
+
+			Convey("When running the cluster commands", func() {
+				cont.runClusterCommands()
+				cont.wg.Wait()
+
+				Convey("Then the paused transfer should have been paused", func() {
+					var check model.Transfer
+					So(db.Get(&check, "id=?", paused.ID).Run(), ShouldBeNil)
+					So(check.Status, ShouldEqual, types.StatusPaused)
+				})
+
+				Convey("Then the canceled transfer should have been moved to history", func() {
+					var check model.HistoryEntry
+					So(db.Get(&check, "id=?", canceled.ID).Run(), ShouldBeNil)
+					So(check.Status, ShouldEqual, types.StatusCancelled)
+				})
+
+				Convey("Then the other node's transfer should be left untouched", func() {
+					var check model.Transfer
+					So(db.Get(&check, "id=?", other.ID).Run(), ShouldBeNil)
+					So(check.Status, ShouldEqual, types.StatusRunning)
+
+					var entries model.RunningTransfers
+					So(db.Select(&entries).Run(), ShouldBeNil)
+					So(entries, ShouldHaveLength, 1)
+					So(entries[0].Node, ShouldEqual, "other_node")
+					So(entries[0].Command, ShouldEqual, model.TransferCommandPause)
+				})
+			})
+		})
+
+		Convey("Given transfers left running by a previous execution of the node", func() {
+			own := mkRunning("file_1", node, model.TransferCommandNone)
+			other := mkRunning("file_2", "other_node", model.TransferCommandNone)
+
+			Convey("When resetting the node's transfers", func() {
+				So(cont.resetNodeTransfers(), ShouldBeNil)
+
+				Convey("Then the node's transfer should have been interrupted", func() {
+					var check model.Transfer
+					So(db.Get(&check, "id=?", own.ID).Run(), ShouldBeNil)
+					So(check.Status, ShouldEqual, types.StatusInterrupted)
+
+					node, err := model.GetTransferNode(db, own.ID)
+					So(err, ShouldBeNil)
+					So(node, ShouldBeBlank)
+				})
+
+				Convey("Then the other node's transfer should be left untouched", func() {
+					var check model.Transfer
+					So(db.Get(&check, "id=?", other.ID).Run(), ShouldBeNil)
+					So(check.Status, ShouldEqual, types.StatusRunning)
+
+					node, err := model.GetTransferNode(db, other.ID)
+					So(err, ShouldBeNil)
+					So(node, ShouldEqual, "other_node")
+				})
+			})
+		})
+
+		Convey("Given that the cluster reached its outgoing transfer limit", func() {
+			pipeline.List.SetLimits(0, 1)
+			defer pipeline.List.SetLimits(0, 0)
+
+			mkRunning("file_1", "other_node", model.TransferCommandNone, time.Now().Add(time.Minute))
+
+			planned := &model.Transfer{
+				RuleID:          rule.ID,
+				ClientID:        client.NullableID(),
+				RemoteAccountID: account.NullableID(),
+				SrcFilename:     "file_2",
+			}
+			So(db.Insert(planned).Run(), ShouldBeNil)
+
+			Convey("When the controller retrieves new transfers", func() {
+				plannedTrans, err := cont.retrieveClientTransfers()
+				So(err, ShouldBeNil)
+
+				Convey("Then it should not return any transfer", func() {
+					So(plannedTrans, ShouldBeEmpty)
+				})
+			})
+		})
+
+		Convey("Given transfers with leases held by various nodes", func() {
+			expired := time.Now().Add(-time.Minute)
+			valid := time.Now().Add(time.Minute)
+
+			own := mkRunning("file_1", node, model.TransferCommandNone, expired)
+			alive := mkRunning("file_2", "alive_node", model.TransferCommandNone, valid)
+			dead := mkRunning("file_3", "dead_node", model.TransferCommandNone, expired)
+
+			Convey("When the controller runs its cluster maintenance", func() {
+				cont.heartbeat()
+				cont.takeOverOrphanedTransfers()
+
+				Convey("Then the node should have been registered", func() {
+					var nodes model.ClusterNodes
+					So(db.Select(&nodes).Run(), ShouldBeNil)
+					So(nodes, ShouldHaveLength, 1)
+					So(nodes[0].Name, ShouldEqual, node)
+					So(nodes[0].Status(), ShouldEqual, model.NodeStatusAlive)
+				})
+
+				Convey("Then the node's own lease should have been renewed", func() {
+					var entry model.RunningTransfer
+					So(db.Get(&entry, "transfer_id=?", own.ID).Run(), ShouldBeNil)
+					So(entry.LeaseExpiry, ShouldHappenAfter, time.Now())
+
+					var check model.Transfer
+					So(db.Get(&check, "id=?", own.ID).Run(), ShouldBeNil)
+					So(check.Status, ShouldEqual, types.StatusRunning)
+				})
+
+				Convey("Then the transfer held by the alive node should be left untouched", func() {
+					var check model.Transfer
+					So(db.Get(&check, "id=?", alive.ID).Run(), ShouldBeNil)
+					So(check.Status, ShouldEqual, types.StatusRunning)
+
+					owner, err := model.GetTransferNode(db, alive.ID)
+					So(err, ShouldBeNil)
+					So(owner, ShouldEqual, "alive_node")
+				})
+
+				Convey("Then the transfer orphaned by the dead node should have been requeued", func() {
+					var check model.Transfer
+					So(db.Get(&check, "id=?", dead.ID).Run(), ShouldBeNil)
+					So(check.Status, ShouldEqual, types.StatusInterrupted)
+
+					owner, err := model.GetTransferNode(db, dead.ID)
+					So(err, ShouldBeNil)
+					So(owner, ShouldBeBlank)
+				})
+			})
+		})
+	})
+}
+
+func TestControllerNodeLease(t *testing.T) {
+	Convey("Given a clustered gateway", t, func(c C) {
+		db := database.TestDatabase(c)
+		cont := &Controller{DB: db}
+
+		db.Config.Controller.Delay = time.Second
+
+		Convey("Given a node lease longer than twice the controller delay", func() {
+			db.Config.Controller.NodeLease = 3 * time.Second
+
+			Convey("Then the configuration should be accepted", func() {
+				So(cont.checkNodeLease(), ShouldBeNil)
+			})
+		})
+
+		Convey("Given a node lease shorter than twice the controller delay", func() {
+			db.Config.Controller.NodeLease = 2 * time.Second
+
+			Convey("Then the configuration should be rejected", func() {
+				So(errors.Is(cont.checkNodeLease(), ErrNodeLeaseTooShort), ShouldBeTrue)
+			})
+		})
+	})
+}
@@ -11,6 +11,7 @@ import (
 	"code.waarp.fr/apps/gateway/gateway/pkg/database"
 	"code.waarp.fr/apps/gateway/gateway/pkg/logging"
 	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
 	"code.waarp.fr/apps/gateway/gateway/pkg/model/types"
 	"code.waarp.fr/apps/gateway/gateway/pkg/pipeline"
 	"code.waarp.fr/apps/gateway/gateway/pkg/snmp"
@@ -27,6 +28,7 @@ type Controller struct {
 	ticker *time.Ticker
 	logger *log.Logger
 	state  utils.State
+	node   *model.ClusterNode
 
 	wg     *sync.WaitGroup
 	done   chan struct{}
@@ -49,8 +51,33 @@ func (c *Controller) listen() error {
 
 			return fmt.Errorf("failed to access database: %w", err)
 		}
+	} else {
+		if err := c.resetNodeTransfers(); err != nil {
+			c.logger.Errorf("Failed to access database: %v", err)
+
+			return err
+		}
+
+		if err := c.registerClusterNode(); err != nil {
+			c.logger.Errorf("Failed to register the node: %v", err)
+
+			return err
+		}
+
+		c.wg.Add(1)
+
+		go func() {
+			defer c.wg.Done()
+
+			c.renewLeases()
+		}()
 	}
 
+	// New transfers are started as soon as they are notified. The periodic
+	// polling is only kept as a fallback, for the transfers whose notification
+	// was missed, and for the transfers whose start date is in the future.
+	notifications := c.DB.Listen(c.ctx, database.TransfersChannel)
+
 	go func() {
 		for {
 			select {
@@ -61,6 +88,8 @@ func (c *Controller) listen() error {
 				return
 			case <-c.ticker.C:
 				c.Run()
+			case <-notifications:
+				c.runClientTransfers()
 			}
 		}
 	}()
@@ -76,6 +105,13 @@ func (c *Controller) Start() error {
 
 	c.logger = logging.NewLogger(ServiceName)
 
+	if err := c.checkNodeLease(); err != nil {
+		c.logger.Errorf("Invalid cluster configuration: %v", err)
+		c.state.Set(utils.StateError, err.Error())
+
+		return err
+	}
+
 	config := c.DB.Config.Controller
 	pipeline.List.SetLimits(config.MaxTransfersIn, config.MaxTransfersOut)
 	c.ticker = time.NewTicker(config.Delay)
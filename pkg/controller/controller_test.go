@@ -127,6 +127,70 @@ func TestControllerListen(t *testing.T) {
 				})
 			})
 
+			Convey("Given a controller listening for notifications", func() {
+				// The ticker never fires during the test, so the transfer can
+				// only be started if the controller was notified.
+				cont.ticker = time.NewTicker(time.Hour)
+				So(cont.listen(), ShouldBeNil)
+
+				defer func() {
+					cont.cancel()
+					<-cont.done
+				}()
+
+				Convey("When a new planned transfer is added", func() {
+					path1 := path.Join(rootPath, "out", "file_1")
+					So(fs.MkdirAll(path.Dir(path1)), ShouldBeNil)
+					So(fs.WriteFullFile(path1, []byte("hello world")), ShouldBeNil)
+
+					trans := &model.Transfer{
+						RuleID:          rule.ID,
+						ClientID:        client.NullableID(),
+						RemoteAccountID: account.NullableID(),
+						SrcFilename:     "file_1",
+					}
+					So(db.Insert(trans).Run(), ShouldBeNil)
+
+					Convey("Then the transfer should be executed without waiting for the ticker", func() {
+						var historyEntries model.HistoryEntries
+
+						for range 100 {
+							So(db.Select(&historyEntries).Run(), ShouldBeNil)
+
+							if len(historyEntries) > 0 {
+								break
+							}
+
+							time.Sleep(50 * time.Millisecond)
+						}
+
+						So(historyEntries, ShouldHaveLength, 1)
+					})
+				})
+			})
+
+			Convey("Given a planned transfer of a drained client", func() {
+				trans := &model.Transfer{
+					RuleID:          rule.ID,
+					ClientID:        client.NullableID(),
+					RemoteAccountID: account.NullableID(),
+					SrcFilename:     "file_1",
+				}
+				So(db.Insert(trans).Run(), ShouldBeNil)
+
+				pipeline.List.DrainClient(client.ID)
+				defer pipeline.List.EndClientDrain(client.ID)
+
+				Convey("When the controller retrieves new transfers", func() {
+					plannedTrans, dbErr := cont.retrieveClientTransfers()
+					So(dbErr, ShouldBeNil)
+
+					Convey("Then it should not return the drained client's transfer", func() {
+						So(plannedTrans, ShouldBeEmpty)
+					})
+				})
+			})
+
 			Convey("Given that we reached the transfer limit", func() {
 				path1 := path.Join(rootPath, "out", "file_1")
 				So(fs.MkdirAll(path.Dir(path1)), ShouldBeNil)
@@ -16,6 +16,7 @@ import (
 	"code.waarp.fr/apps/gateway/gateway/pkg/conf"
 	"code.waarp.fr/apps/gateway/gateway/pkg/logging"
 	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
+	"code.waarp.fr/apps/gateway/gateway/pkg/secrets"
 	"code.waarp.fr/apps/gateway/gateway/pkg/utils"
 )
 
@@ -32,10 +33,15 @@ var errUnsupportedDB = errors.New("unsupported database")
 type DB struct {
 	Logger *log.Logger
 	Config *conf.ServerConfig
-	AEAD   cipher.AEAD
-
-	engine *gorm.DB
-	state  utils.State
+	// AEAD, if set before the service is started, is used as AES key instead
+	// of the configured passphrase file. It is never updated afterward, the
+	// current key must be fetched with Keyring().Primary() instead.
+	AEAD cipher.AEAD
+
+	engine   *gorm.DB
+	state    utils.State
+	keyring  *Keyring
+	notifier notifier
 }
 
 func NewDB(config *conf.ServerConfig) *DB {
@@ -44,46 +50,164 @@ func NewDB(config *conf.ServerConfig) *DB {
 
 func (db *DB) Name() string { return ServiceName }
 
+// ChangeAEAD changes the primary AES key used to encrypt the database secrets.
+// The previous keys are kept, and can still be used to decrypt the secrets
+// which were encrypted with them.
 func (db *DB) ChangeAEAD(newAEAD cipher.AEAD) {
-	db.AEAD = newAEAD
+	db.getKeyring().SetPrimary(newAEAD)
 
 	if db.engine != nil {
-		registerAEAD(db.engine, newAEAD)
+		registerKeyring(db.engine, db.keyring)
 	}
 }
 
+// Keyring returns the keyring holding all the AES keys known to the database.
+func (db *DB) Keyring() *Keyring { return db.getKeyring() }
+
+func (db *DB) getKeyring() *Keyring {
+	if db.keyring == nil {
+		db.keyring = NewKeyring(db.AEAD)
+	}
+
+	return db.keyring
+}
+
 func (db *DB) loadAESKey() error {
-	if db.AEAD != nil {
-		return nil
+	if db.getKeyring().Primary() != nil {
+		return nil // the key was given before startup
+	}
+
+	wrapper, wrapErr := GetKeyWrapper(&db.Config.Database)
+	if wrapErr != nil {
+		return wrapErr
 	}
 
 	filename := db.Config.Database.AESPassphrase
 	if _, statErr := os.Stat(filepath.Clean(filename)); os.IsNotExist(statErr) {
 		db.Logger.Infof("Creating AES passphrase file at %q", filename)
 
-		key := make([]byte, aesKeySize)
+		if err := GenerateAESKeyFile(filename, wrapper); err != nil {
+			return err
+		}
+	}
+
+	primary, previous, loadErr := loadAESKeys(&db.Config.Database)
+	if loadErr != nil {
+		return loadErr
+	}
+
+	db.keyring = NewKeyring(primary, previous...)
+	db.keyring.reload = db.reloadAESKeys
+
+	return nil
+}
+
+// reloadAESKeys reads the AES passphrase files listed in the configuration file
+// on disk, to fetch the keys which might have been added since the gateway's
+// startup (typically by a passphrase rotation).
+func (db *DB) reloadAESKeys(current cipher.AEAD) (cipher.AEAD, []cipher.AEAD, error) {
+	configFile := os.Getenv(conf.ConfigFileEnvVar)
+	if configFile == "" {
+		return current, nil, nil
+	}
+
+	config, err := conf.ParseServerConfig(configFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse the configuration file: %w", err)
+	}
+
+	primary, previous, err := loadAESKeys(&config.Database)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if KeyID(primary) != KeyID(current) {
+		db.Logger.Infof("The AES passphrase has been changed, now using key %q", KeyID(primary))
+	}
+
+	return primary, previous, nil
+}
+
+// GetKeyWrapper returns the key wrapper used to wrap the AES passphrase files,
+// as defined in the given configuration. If no wrapper is configured, it
+// returns nil.
+func GetKeyWrapper(config *conf.DatabaseConfig) (secrets.KeyWrapper, error) {
+	if config.AESKeyWrapper == "" {
+		return nil, nil //nolint:nilnil //no wrapper is a valid value
+	}
+
+	wrapper, err := secrets.NewKeyWrapper(config.AESKeyWrapper)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize the AES key wrapper: %w", err)
+	}
+
+	return wrapper, nil
+}
+
+func loadAESKeys(config *conf.DatabaseConfig) (cipher.AEAD, []cipher.AEAD, error) {
+	wrapper, wrapErr := GetKeyWrapper(config)
+	if wrapErr != nil {
+		return nil, nil, wrapErr
+	}
+
+	primary, err := LoadAEAD(config.AESPassphrase, wrapper)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	previous := make([]cipher.AEAD, len(config.AESPreviousPassphrases))
 
-		if _, err := rand.Read(key); err != nil {
-			return fmt.Errorf("cannot generate AES key: %w", err)
+	for i, filename := range config.AESPreviousPassphrases {
+		if previous[i], err = LoadAEAD(filename, wrapper); err != nil {
+			return nil, nil, err
 		}
+	}
+
+	return primary, previous, nil
+}
+
+// GenerateAESKeyFile generates a new random AES passphrase, and writes it to
+// the given file. If a key wrapper is given, the passphrase is wrapped before
+// being written.
+func GenerateAESKeyFile(filename string, wrapper secrets.KeyWrapper) error {
+	key := make([]byte, aesKeySize)
+
+	if _, err := rand.Read(key); err != nil {
+		return fmt.Errorf("cannot generate AES key: %w", err)
+	}
 
-		if err := os.WriteFile(filepath.Clean(filename), key, 0o600); err != nil {
-			return fmt.Errorf("cannot write AES key to file %q: %w", filename, err)
+	if wrapper != nil {
+		var err error
+		if key, err = wrapper.WrapKey(key); err != nil {
+			return fmt.Errorf("cannot wrap AES key: %w", err)
 		}
 	}
 
-	var gcmErr error
-	db.AEAD, gcmErr = NewAEAD(filename)
+	if err := os.WriteFile(filepath.Clean(filename), key, 0o600); err != nil {
+		return fmt.Errorf("cannot write AES key to file %q: %w", filename, err)
+	}
 
-	return gcmErr
+	return nil
 }
 
 func NewAEAD(filename string) (cipher.AEAD, error) {
+	return LoadAEAD(filename, nil)
+}
+
+// LoadAEAD reads the AES passphrase contained in the given file. If a key
+// wrapper is given, the file's content is unwrapped before use.
+func LoadAEAD(filename string, wrapper secrets.KeyWrapper) (cipher.AEAD, error) {
 	key, err := os.ReadFile(filepath.Clean(filename))
 	if err != nil {
 		return nil, fmt.Errorf("cannot read AES key from file %q: %w", filename, err)
 	}
 
+	if wrapper != nil {
+		if key, err = wrapper.UnwrapKey(key); err != nil {
+			return nil, fmt.Errorf("cannot unwrap AES key from file %q: %w", filename, err)
+		}
+	}
+
 	c, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, fmt.Errorf("cannot initialize AES key: %w", err)
@@ -135,7 +259,7 @@ func (db *DB) initEngine() error {
 		return fmt.Errorf("cannot initialize database access: %w", err)
 	}
 
-	registerAEAD(db.engine, db.AEAD)
+	registerKeyring(db.engine, db.getKeyring())
 
 	return nil
 }
@@ -125,6 +125,10 @@ type Access interface {
 
 	// Same as Transaction, but with a timeout.
 	TransactionWithTimeout(dur time.Duration, fun TransactionFunc) error
+
+	// Notify sends a notification on the given channel, once the current
+	// transaction (if any) has been committed.
+	Notify(channel string)
 }
 
 // DeletionHook is an interface which adds a function which will be run before
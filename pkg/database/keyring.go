@@ -0,0 +1,206 @@
+package database
+
+import (
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	keyIDSeparator = "$"
+	keyIDLength    = 8
+
+	keyringReloadInterval = 10 * time.Second
+)
+
+var ErrUnknownAESKey = errors.New("the secret was encrypted with an unknown AES key")
+
+// KeyID returns the identifier of the given AES key. The identifier is derived
+// from the key itself (without revealing it), so the same key always has the
+// same identifier, regardless of where it is loaded from.
+func KeyID(gcm cipher.AEAD) string {
+	const idLabel = "waarp-gateway key ID"
+
+	tag := gcm.Seal(nil, make([]byte, gcm.NonceSize()), nil, []byte(idLabel))
+	sum := sha256.Sum256(tag)
+
+	return hex.EncodeToString(sum[:])[:keyIDLength]
+}
+
+// splitKeyID splits the given cipher text into the ID of the key used to
+// encrypt it, and the actual base64 cipher text. Cipher texts produced before
+// the introduction of key IDs have no ID, in which case the returned ID is empty.
+func splitKeyID(cipherStr string) (keyID, cipherText string) {
+	if !strings.HasPrefix(cipherStr, keyIDSeparator) {
+		return "", cipherStr
+	}
+
+	id, text, ok := strings.Cut(cipherStr[len(keyIDSeparator):], keyIDSeparator)
+	if !ok {
+		return "", cipherStr
+	}
+
+	return id, text
+}
+
+// Keyring holds the AES keys used to encrypt the database secrets. The primary
+// key is used to encrypt all new secrets, while the other keys are only used to
+// decrypt the secrets which were encrypted with them (typically before a
+// passphrase rotation). Since each cipher text is tagged with the ID of the key
+// used to produce it, multiple key versions can be active at the same time.
+type Keyring struct {
+	mx      sync.RWMutex
+	primary cipher.AEAD
+	keys    map[string]cipher.AEAD
+
+	// reload, if not nil, is called (with the current primary key) when a
+	// cipher text was encrypted with an unknown key, and returns the up-to-date
+	// primary & previous keys.
+	reload     func(current cipher.AEAD) (cipher.AEAD, []cipher.AEAD, error)
+	lastReload time.Time
+}
+
+// NewKeyring returns a new keyring with the given primary key, and the given
+// previous keys.
+func NewKeyring(primary cipher.AEAD, previous ...cipher.AEAD) *Keyring {
+	keyring := &Keyring{keys: map[string]cipher.AEAD{}}
+	keyring.set(primary, previous)
+
+	return keyring
+}
+
+func (k *Keyring) set(primary cipher.AEAD, previous []cipher.AEAD) {
+	for _, key := range previous {
+		k.keys[KeyID(key)] = key
+	}
+
+	if primary != nil {
+		k.keys[KeyID(primary)] = primary
+	}
+
+	k.primary = primary
+}
+
+// Primary returns the keyring's primary key.
+func (k *Keyring) Primary() cipher.AEAD {
+	k.mx.RLock()
+	defer k.mx.RUnlock()
+
+	return k.primary
+}
+
+// SetPrimary changes the keyring's primary key. The previous primary key is
+// kept in the keyring, and can still be used to decrypt secrets.
+func (k *Keyring) SetPrimary(primary cipher.AEAD) {
+	k.mx.Lock()
+	defer k.mx.Unlock()
+
+	k.set(primary, nil)
+}
+
+// IDs returns the IDs of all the keys in the keyring.
+func (k *Keyring) IDs() []string {
+	k.mx.RLock()
+	defer k.mx.RUnlock()
+
+	ids := make([]string, 0, len(k.keys))
+	for id := range k.keys {
+		ids = append(ids, id)
+	}
+
+	return ids
+}
+
+// Encrypt encrypts the given text using the keyring's primary key.
+func (k *Keyring) Encrypt(plain string) (string, error) {
+	return AESEncrypt(k.Primary(), plain)
+}
+
+// Decrypt decrypts the given cipher text using the keyring key which was used
+// to encrypt it. Cipher texts without a key ID are decrypted using the primary
+// key first, and then using the other keys.
+func (k *Keyring) Decrypt(cipherStr string) (string, error) {
+	keyID, _ := splitKeyID(cipherStr)
+	if keyID == "" {
+		return k.decryptLegacy(cipherStr)
+	}
+
+	key, err := k.getKey(keyID)
+	if err != nil {
+		return "", err
+	}
+
+	return AESDecrypt(key, cipherStr)
+}
+
+func (k *Keyring) getKey(keyID string) (cipher.AEAD, error) {
+	k.mx.RLock()
+	key, ok := k.keys[keyID]
+	k.mx.RUnlock()
+
+	if ok {
+		return key, nil
+	}
+
+	if err := k.tryReload(); err != nil {
+		return nil, err
+	}
+
+	k.mx.RLock()
+	defer k.mx.RUnlock()
+
+	if key, ok = k.keys[keyID]; !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownAESKey, keyID)
+	}
+
+	return key, nil
+}
+
+// tryReload reloads the keyring (if possible) to fetch new keys, typically
+// after another process rotated the AES passphrase.
+func (k *Keyring) tryReload() error {
+	k.mx.Lock()
+	defer k.mx.Unlock()
+
+	if k.reload == nil || time.Since(k.lastReload) < keyringReloadInterval {
+		return nil
+	}
+
+	k.lastReload = time.Now()
+
+	primary, previous, err := k.reload(k.primary)
+	if err != nil {
+		return fmt.Errorf("failed to reload the AES keys: %w", err)
+	}
+
+	k.set(primary, previous)
+
+	return nil
+}
+
+func (k *Keyring) decryptLegacy(cipherStr string) (string, error) {
+	k.mx.RLock()
+	defer k.mx.RUnlock()
+
+	plain, err := AESDecrypt(k.primary, cipherStr)
+	if err == nil {
+		return plain, nil
+	}
+
+	for _, key := range k.keys {
+		if key == k.primary {
+			continue
+		}
+
+		if plain, keyErr := AESDecrypt(key, cipherStr); keyErr == nil {
+			return plain, nil
+		}
+	}
+
+	return "", err
+}
@@ -0,0 +1,113 @@
+package database
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestKey(tb testing.TB) cipher.AEAD {
+	tb.Helper()
+
+	key := make([]byte, aesKeySize)
+	_, err := rand.Read(key)
+	require.NoError(tb, err)
+
+	block, err := aes.NewCipher(key)
+	require.NoError(tb, err)
+
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(tb, err)
+
+	return gcm
+}
+
+func TestKeyring(t *testing.T) {
+	t.Parallel()
+
+	oldKey, newKey := newTestKey(t), newTestKey(t)
+
+	require.Equal(t, KeyID(oldKey), KeyID(oldKey), "key IDs should be deterministic")
+	require.NotEqual(t, KeyID(oldKey), KeyID(newKey), "key IDs should be unique")
+
+	legacy, err := AESEncrypt(oldKey, "legacy")
+	require.NoError(t, err)
+
+	_, legacy = splitKeyID(legacy) // simulate a cipher text without key ID
+
+	keyring := NewKeyring(oldKey)
+
+	oldCipher, err := keyring.Encrypt("old secret")
+	require.NoError(t, err)
+	assert.Equal(t, "$"+KeyID(oldKey)+"$", oldCipher[:len(KeyID(oldKey))+2])
+
+	t.Run("When changing the primary key", func(t *testing.T) {
+		t.Parallel()
+
+		keyring.SetPrimary(newKey)
+
+		newCipher, err := keyring.Encrypt("new secret")
+		require.NoError(t, err)
+
+		t.Run("Then new secrets should be encrypted with the new key", func(t *testing.T) {
+			t.Parallel()
+
+			plain, err := AESDecrypt(newKey, newCipher)
+			require.NoError(t, err)
+			assert.Equal(t, "new secret", plain)
+
+			_, err = AESDecrypt(oldKey, newCipher)
+			require.Error(t, err)
+		})
+
+		t.Run("Then old secrets should still be decryptable", func(t *testing.T) {
+			t.Parallel()
+
+			plain, err := keyring.Decrypt(oldCipher)
+			require.NoError(t, err)
+			assert.Equal(t, "old secret", plain)
+
+			plain, err = keyring.Decrypt(legacy)
+			require.NoError(t, err)
+			assert.Equal(t, "legacy", plain)
+		})
+
+		t.Run("Then secrets from unknown keys should not be decryptable", func(t *testing.T) {
+			t.Parallel()
+
+			unknown, err := AESEncrypt(newTestKey(t), "unknown")
+			require.NoError(t, err)
+
+			_, err = keyring.Decrypt(unknown)
+			require.ErrorIs(t, err, ErrUnknownAESKey)
+		})
+	})
+}
+
+func TestKeyringReload(t *testing.T) {
+	t.Parallel()
+
+	oldKey, newKey := newTestKey(t), newTestKey(t)
+	keyring := NewKeyring(oldKey)
+
+	reloaded := 0
+	keyring.reload = func(cipher.AEAD) (cipher.AEAD, []cipher.AEAD, error) {
+		reloaded++
+
+		return newKey, []cipher.AEAD{oldKey}, nil
+	}
+
+	newCipher, err := AESEncrypt(newKey, "sesame")
+	require.NoError(t, err)
+
+	plain, err := keyring.Decrypt(newCipher)
+	require.NoError(t, err)
+	assert.Equal(t, "sesame", plain)
+	assert.Equal(t, 1, reloaded, "the keyring should have been reloaded")
+	assert.Equal(t, KeyID(newKey), KeyID(keyring.Primary()),
+		"the new key should have become the primary key")
+}
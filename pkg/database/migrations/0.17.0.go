@@ -0,0 +1,479 @@
+package migrations
+
+import "fmt"
+
+func ver0_17_0AddAuthorityRevocationUp(db Actions) error {
+	if err := db.AlterTable("auth_authorities",
+		AddColumn{Name: "revocation_policy", Type: Varchar(50), NotNull: true, Default: ""},
+		AddColumn{Name: "crl_url", Type: Text{}, NotNull: true, Default: ""},
+		AddColumn{Name: "ocsp_url", Type: Text{}, NotNull: true, Default: ""},
+	); err != nil {
+		return fmt.Errorf(`failed to add the authorities' revocation columns: %w`, err)
+	}
+
+	return nil
+}
+
+func ver0_17_0AddAuthorityRevocationDown(db Actions) error {
+	if err := db.AlterTable("auth_authorities",
+		DropColumn{Name: "revocation_policy"},
+		DropColumn{Name: "crl_url"},
+		DropColumn{Name: "ocsp_url"},
+	); err != nil {
+		return fmt.Errorf(`failed to drop the authorities' revocation columns: %w`, err)
+	}
+
+	return nil
+}
+
+func ver0_17_0AddTaskConditionUp(db Actions) error {
+	if err := db.AlterTable("tasks",
+		AddColumn{Name: quote(db, "condition"), Type: Text{}, NotNull: true, Default: ""},
+		AddColumn{Name: "on_error", Type: Varchar(50), NotNull: true, Default: ""},
+	); err != nil {
+		return fmt.Errorf(`failed to add the tasks' condition columns: %w`, err)
+	}
+
+	return nil
+}
+
+func ver0_17_0AddTaskConditionDown(db Actions) error {
+	if err := db.AlterTable("tasks",
+		DropColumn{Name: quote(db, "condition")},
+		DropColumn{Name: "on_error"},
+	); err != nil {
+		return fmt.Errorf(`failed to drop the tasks' condition columns: %w`, err)
+	}
+
+	return nil
+}
+
+func ver0_17_0AddTaskExecutionParamsUp(db Actions) error {
+	if err := db.AlterTable("tasks",
+		AddColumn{Name: "retries", Type: TinyInt{}, NotNull: true, Default: 0},
+		AddColumn{Name: "retry_delay", Type: BigInt{}, NotNull: true, Default: 0},
+		AddColumn{Name: "timeout", Type: BigInt{}, NotNull: true, Default: 0},
+		AddColumn{Name: "parallel_group", Type: Varchar(100), NotNull: true, Default: ""},
+	); err != nil {
+		return fmt.Errorf(`failed to add the tasks' execution parameters columns: %w`, err)
+	}
+
+	return nil
+}
+
+func ver0_17_0AddTaskExecutionParamsDown(db Actions) error {
+	if err := db.AlterTable("tasks",
+		DropColumn{Name: "retries"},
+		DropColumn{Name: "retry_delay"},
+		DropColumn{Name: "timeout"},
+		DropColumn{Name: "parallel_group"},
+	); err != nil {
+		return fmt.Errorf(`failed to drop the tasks' execution parameters columns: %w`, err)
+	}
+
+	return nil
+}
+
+func ver0_17_0AddMessageBrokersUp(db Actions) error {
+	if err := db.CreateTable("message_brokers", &Table{
+		Columns: []Column{
+			{Name: "id", Type: BigInt{}, NotNull: true, Default: AutoIncr{}},
+			{Name: "owner", Type: Varchar(100), NotNull: true},
+			{Name: "name", Type: Varchar(100), NotNull: true},
+			{Name: "type", Type: Varchar(50), NotNull: true},
+			{Name: "address", Type: Text{}, NotNull: true},
+			{Name: "login", Type: Text{}, NotNull: true, Default: ""},
+			{Name: "password", Type: Text{}, NotNull: true, Default: ""},
+			{Name: "options", Type: Text{}, NotNull: true, Default: "{}"},
+		},
+		PrimaryKey: &PrimaryKey{Name: "message_brokers_pkey", Cols: []string{"id"}},
+		Uniques: []Unique{{
+			Name: "unique_message_broker", Cols: []string{"owner", "name"},
+		}},
+	}); err != nil {
+		return fmt.Errorf(`failed to create the "message_brokers" table: %w`, err)
+	}
+
+	return nil
+}
+
+func ver0_17_0AddMessageBrokersDown(db Actions) error {
+	if err := db.DropTable("message_brokers"); err != nil {
+		return fmt.Errorf(`failed to drop the "message_brokers" table: %w`, err)
+	}
+
+	return nil
+}
+
+func ver0_17_0AddScriptsUp(db Actions) error {
+	if err := db.CreateTable("scripts", &Table{
+		Columns: []Column{
+			{Name: "id", Type: BigInt{}, NotNull: true, Default: AutoIncr{}},
+			{Name: "name", Type: Varchar(100), NotNull: true},
+			{Name: "content", Type: Text{}, NotNull: true},
+		},
+		PrimaryKey: &PrimaryKey{Name: "scripts_pkey", Cols: []string{"id"}},
+		Uniques:    []Unique{{Name: "unique_script", Cols: []string{"name"}}},
+	}); err != nil {
+		return fmt.Errorf(`failed to create the "scripts" table: %w`, err)
+	}
+
+	return nil
+}
+
+func ver0_17_0AddScriptsDown(db Actions) error {
+	if err := db.DropTable("scripts"); err != nil {
+		return fmt.Errorf(`failed to drop the "scripts" table: %w`, err)
+	}
+
+	return nil
+}
+
+func ver0_17_0AddCopybooksUp(db Actions) error {
+	if err := db.CreateTable("copybooks", &Table{
+		Columns: []Column{
+			{Name: "id", Type: BigInt{}, NotNull: true, Default: AutoIncr{}},
+			{Name: "name", Type: Varchar(100), NotNull: true},
+			{Name: "content", Type: Text{}, NotNull: true},
+		},
+		PrimaryKey: &PrimaryKey{Name: "copybooks_pkey", Cols: []string{"id"}},
+		Uniques:    []Unique{{Name: "unique_copybook", Cols: []string{"name"}}},
+	}); err != nil {
+		return fmt.Errorf(`failed to create the "copybooks" table: %w`, err)
+	}
+
+	return nil
+}
+
+func ver0_17_0AddCopybooksDown(db Actions) error {
+	if err := db.DropTable("copybooks"); err != nil {
+		return fmt.Errorf(`failed to drop the "copybooks" table: %w`, err)
+	}
+
+	return nil
+}
+
+func ver0_17_0AddRunningTransfersUp(db Actions) error {
+	if err := db.CreateTable("running_transfers", &Table{
+		Columns: []Column{
+			{Name: "id", Type: BigInt{}, NotNull: true, Default: AutoIncr{}},
+			{Name: "transfer_id", Type: BigInt{}, NotNull: true},
+			{Name: "node", Type: Varchar(100), NotNull: true},
+			{Name: "command", Type: Varchar(50), NotNull: true, Default: ""},
+			{Name: "since", Type: DateTime{}, NotNull: true},
+		},
+		PrimaryKey: &PrimaryKey{Name: "running_transfers_pkey", Cols: []string{"id"}},
+		ForeignKeys: []ForeignKey{{
+			Name: "running_transfer_fkey", Cols: []string{"transfer_id"},
+			RefTbl: "transfers", RefCols: []string{"id"},
+			OnUpdate: Restrict, OnDelete: Cascade,
+		}},
+		Uniques: []Unique{{Name: "unique_running_transfer", Cols: []string{"transfer_id"}}},
+	}); err != nil {
+		return fmt.Errorf(`failed to create the "running_transfers" table: %w`, err)
+	}
+
+	return nil
+}
+
+func ver0_17_0AddRunningTransfersDown(db Actions) error {
+	if err := db.DropTable("running_transfers"); err != nil {
+		return fmt.Errorf(`failed to drop the "running_transfers" table: %w`, err)
+	}
+
+	return nil
+}
+
+func ver0_17_0AddClusterNodesUp(db Actions) error {
+	if err := db.CreateTable("cluster_nodes", &Table{
+		Columns: []Column{
+			{Name: "id", Type: BigInt{}, NotNull: true, Default: AutoIncr{}},
+			{Name: "name", Type: Varchar(100), NotNull: true},
+			{Name: "hostname", Type: Varchar(255), NotNull: true, Default: ""},
+			{Name: "started_at", Type: DateTime{}, NotNull: true},
+			{Name: "last_heartbeat", Type: DateTime{}, NotNull: true},
+			{Name: "lease_expiry", Type: DateTime{}, NotNull: true},
+		},
+		PrimaryKey: &PrimaryKey{Name: "cluster_nodes_pkey", Cols: []string{"id"}},
+		Uniques:    []Unique{{Name: "unique_cluster_node", Cols: []string{"name"}}},
+	}); err != nil {
+		return fmt.Errorf(`failed to create the "cluster_nodes" table: %w`, err)
+	}
+
+	if err := db.AlterTable("running_transfers",
+		AddColumn{Name: "lease_expiry", Type: DateTime{}},
+	); err != nil {
+		return fmt.Errorf(`failed to add the running transfers' lease column: %w`, err)
+	}
+
+	return nil
+}
+
+func ver0_17_0AddClusterNodesDown(db Actions) error {
+	if err := db.AlterTable("running_transfers",
+		DropColumn{Name: "lease_expiry"},
+	); err != nil {
+		return fmt.Errorf(`failed to drop the running transfers' lease column: %w`, err)
+	}
+
+	if err := db.DropTable("cluster_nodes"); err != nil {
+		return fmt.Errorf(`failed to drop the "cluster_nodes" table: %w`, err)
+	}
+
+	return nil
+}
+
+func ver0_17_0AddClusterLimitsUp(db Actions) error {
+	if err := db.AlterTable("running_transfers",
+		AddColumn{Name: "is_server", Type: Boolean{}, NotNull: true, Default: false},
+	); err != nil {
+		return fmt.Errorf(`failed to add the running transfers' "is_server" column: %w`, err)
+	}
+
+	if err := db.AlterTable("cluster_nodes",
+		AddColumn{Name: "services", Type: Text{}, NotNull: true, Default: "{}"},
+	); err != nil {
+		return fmt.Errorf(`failed to add the cluster nodes' "services" column: %w`, err)
+	}
+
+	return nil
+}
+
+func ver0_17_0AddClusterLimitsDown(db Actions) error {
+	if err := db.AlterTable("cluster_nodes",
+		DropColumn{Name: "services"},
+	); err != nil {
+		return fmt.Errorf(`failed to drop the cluster nodes' "services" column: %w`, err)
+	}
+
+	if err := db.AlterTable("running_transfers",
+		DropColumn{Name: "is_server"},
+	); err != nil {
+		return fmt.Errorf(`failed to drop the running transfers' "is_server" column: %w`, err)
+	}
+
+	return nil
+}
+
+func ver0_17_0AddRetryPoliciesUp(db Actions) error {
+	if err := db.CreateTable("retry_policies", &Table{
+		Columns: []Column{
+			{Name: "id", Type: BigInt{}, NotNull: true, Default: AutoIncr{}},
+			{Name: "name", Type: Varchar(100), NotNull: true},
+			{Name: "max_attempts", Type: TinyInt{}, NotNull: true, Default: 0},
+			{Name: "first_delay", Type: BigInt{}, NotNull: true, Default: 0},
+			{Name: "increment_factor", Type: Float{}, NotNull: true, Default: 1},
+			{Name: "max_delay", Type: BigInt{}, NotNull: true, Default: 0},
+			{Name: "jitter", Type: Float{}, NotNull: true, Default: 0},
+			{Name: "max_window", Type: BigInt{}, NotNull: true, Default: 0},
+			{Name: "error_codes", Type: Text{}, NotNull: true, Default: "{}"},
+		},
+		PrimaryKey: &PrimaryKey{Name: "retry_policies_pkey", Cols: []string{"id"}},
+		Uniques:    []Unique{{Name: "unique_retry_policy", Cols: []string{"name"}}},
+	}); err != nil {
+		return fmt.Errorf(`failed to create the "retry_policies" table: %w`, err)
+	}
+
+	if err := db.AlterTable("rules",
+		AddColumn{Name: "retry_policy", Type: Varchar(100), NotNull: true, Default: ""},
+	); err != nil {
+		return fmt.Errorf(`failed to add the rules' "retry_policy" column: %w`, err)
+	}
+
+	if err := db.AlterTable("remote_agents",
+		AddColumn{Name: "retry_policy", Type: Varchar(100), NotNull: true, Default: ""},
+	); err != nil {
+		return fmt.Errorf(`failed to add the partners' "retry_policy" column: %w`, err)
+	}
+
+	return nil
+}
+
+func ver0_17_0AddRetryPoliciesDown(db Actions) error {
+	if err := db.AlterTable("remote_agents",
+		DropColumn{Name: "retry_policy"},
+	); err != nil {
+		return fmt.Errorf(`failed to drop the partners' "retry_policy" column: %w`, err)
+	}
+
+	if err := db.AlterTable("rules",
+		DropColumn{Name: "retry_policy"},
+	); err != nil {
+		return fmt.Errorf(`failed to drop the rules' "retry_policy" column: %w`, err)
+	}
+
+	if err := db.DropTable("retry_policies"); err != nil {
+		return fmt.Errorf(`failed to drop the "retry_policies" table: %w`, err)
+	}
+
+	return nil
+}
+
+func ver0_17_0AddSLAsUp(db Actions) error {
+	if err := db.CreateTable("slas", &Table{
+		Columns: []Column{
+			{Name: "id", Type: BigInt{}, NotNull: true, Default: AutoIncr{}},
+			{Name: "owner", Type: Varchar(100), NotNull: true},
+			{Name: "name", Type: Varchar(100), NotNull: true},
+			{Name: "rule_id", Type: BigInt{}},
+			{Name: "remote_agent_id", Type: BigInt{}},
+			{Name: "deadline", Type: Varchar(5), NotNull: true, Default: ""},
+			{Name: "deadline_days", Type: Text{}, NotNull: true, Default: "[]"},
+			{Name: "max_duration", Type: BigInt{}, NotNull: true, Default: 0},
+			{Name: "min_throughput", Type: BigInt{}, NotNull: true, Default: 0},
+		},
+		PrimaryKey: &PrimaryKey{Name: "slas_pkey", Cols: []string{"id"}},
+		ForeignKeys: []ForeignKey{{
+			Name: "sla_rule_fkey", Cols: []string{"rule_id"},
+			RefTbl: "rules", RefCols: []string{"id"},
+			OnUpdate: Restrict, OnDelete: Cascade,
+		}, {
+			Name: "sla_remote_agent_fkey", Cols: []string{"remote_agent_id"},
+			RefTbl: "remote_agents", RefCols: []string{"id"},
+			OnUpdate: Restrict, OnDelete: Cascade,
+		}},
+		Uniques: []Unique{{Name: "unique_sla", Cols: []string{"owner", "name"}}},
+	}); err != nil {
+		return fmt.Errorf(`failed to create the "slas" table: %w`, err)
+	}
+
+	if err := db.CreateTable("sla_breaches", &Table{
+		Columns: []Column{
+			{Name: "id", Type: BigInt{}, NotNull: true, Default: AutoIncr{}},
+			{Name: "sla_id", Type: BigInt{}, NotNull: true},
+			{Name: "kind", Type: Varchar(50), NotNull: true},
+			{Name: "transfer_id", Type: BigInt{}},
+			{Name: "date", Type: DateTime{}, NotNull: true},
+			{Name: "detected", Type: DateTime{}, NotNull: true},
+			{Name: "details", Type: Text{}, NotNull: true, Default: ""},
+		},
+		PrimaryKey: &PrimaryKey{Name: "sla_breaches_pkey", Cols: []string{"id"}},
+		ForeignKeys: []ForeignKey{{
+			Name: "sla_breach_fkey", Cols: []string{"sla_id"},
+			RefTbl: "slas", RefCols: []string{"id"},
+			OnUpdate: Restrict, OnDelete: Cascade,
+		}},
+		Uniques: []Unique{{
+			Name: "unique_sla_breach",
+			Cols: []string{"sla_id", "kind", "transfer_id", "date"},
+		}},
+	}); err != nil {
+		return fmt.Errorf(`failed to create the "sla_breaches" table: %w`, err)
+	}
+
+	return nil
+}
+
+func ver0_17_0AddSLAsDown(db Actions) error {
+	if err := db.DropTable("sla_breaches"); err != nil {
+		return fmt.Errorf(`failed to drop the "sla_breaches" table: %w`, err)
+	}
+
+	if err := db.DropTable("slas"); err != nil {
+		return fmt.Errorf(`failed to drop the "slas" table: %w`, err)
+	}
+
+	return nil
+}
+
+func ver0_17_0AddExpectedFilesUp(db Actions) error {
+	if err := db.CreateTable("expected_files", &Table{
+		Columns: []Column{
+			{Name: "id", Type: BigInt{}, NotNull: true, Default: AutoIncr{}},
+			{Name: "owner", Type: Varchar(100), NotNull: true},
+			{Name: "name", Type: Varchar(100), NotNull: true},
+			{Name: "disabled", Type: Boolean{}, NotNull: true, Default: false},
+			{Name: "rule_id", Type: BigInt{}, NotNull: true},
+			{Name: "remote_agent_id", Type: BigInt{}},
+			{Name: "local_account_id", Type: BigInt{}},
+			{Name: "pattern", Type: Text{}, NotNull: true, Default: "*"},
+			{Name: "window_start", Type: Varchar(5), NotNull: true},
+			{Name: "window_end", Type: Varchar(5), NotNull: true},
+			{Name: "days", Type: Text{}, NotNull: true, Default: "[]"},
+			{Name: "email_alert", Type: Text{}, NotNull: true, Default: "{}"},
+			{Name: "created", Type: DateTime{}, NotNull: true},
+		},
+		PrimaryKey: &PrimaryKey{Name: "expected_files_pkey", Cols: []string{"id"}},
+		ForeignKeys: []ForeignKey{{
+			Name: "expected_file_rule_fkey", Cols: []string{"rule_id"},
+			RefTbl: "rules", RefCols: []string{"id"},
+			OnUpdate: Restrict, OnDelete: Cascade,
+		}, {
+			Name: "expected_file_remote_agent_fkey", Cols: []string{"remote_agent_id"},
+			RefTbl: "remote_agents", RefCols: []string{"id"},
+			OnUpdate: Restrict, OnDelete: Cascade,
+		}, {
+			Name: "expected_file_local_account_fkey", Cols: []string{"local_account_id"},
+			RefTbl: "local_accounts", RefCols: []string{"id"},
+			OnUpdate: Restrict, OnDelete: Cascade,
+		}},
+		Uniques: []Unique{{Name: "unique_expected_file", Cols: []string{"owner", "name"}}},
+	}); err != nil {
+		return fmt.Errorf(`failed to create the "expected_files" table: %w`, err)
+	}
+
+	if err := db.CreateTable("expected_file_checks", &Table{
+		Columns: []Column{
+			{Name: "id", Type: BigInt{}, NotNull: true, Default: AutoIncr{}},
+			{Name: "expected_file_id", Type: BigInt{}, NotNull: true},
+			{Name: "window_start", Type: DateTime{}, NotNull: true},
+			{Name: "window_end", Type: DateTime{}, NotNull: true},
+			{Name: "status", Type: Varchar(50), NotNull: true},
+			{Name: "files", Type: Text{}, NotNull: true, Default: "[]"},
+			{Name: "checked", Type: DateTime{}, NotNull: true},
+		},
+		PrimaryKey: &PrimaryKey{Name: "expected_file_checks_pkey", Cols: []string{"id"}},
+		ForeignKeys: []ForeignKey{{
+			Name: "expected_file_check_fkey", Cols: []string{"expected_file_id"},
+			RefTbl: "expected_files", RefCols: []string{"id"},
+			OnUpdate: Restrict, OnDelete: Cascade,
+		}},
+		Uniques: []Unique{{
+			Name: "unique_expected_file_check",
+			Cols: []string{"expected_file_id", "window_start"},
+		}},
+	}); err != nil {
+		return fmt.Errorf(`failed to create the "expected_file_checks" table: %w`, err)
+	}
+
+	return nil
+}
+
+func ver0_17_0AddExpectedFilesDown(db Actions) error {
+	if err := db.DropTable("expected_file_checks"); err != nil {
+		return fmt.Errorf(`failed to drop the "expected_file_checks" table: %w`, err)
+	}
+
+	if err := db.DropTable("expected_files"); err != nil {
+		return fmt.Errorf(`failed to drop the "expected_files" table: %w`, err)
+	}
+
+	return nil
+}
+
+func ver0_17_0AddAuditLogsUp(db Actions) error {
+	if err := db.CreateTable("audit_logs", &Table{
+		Columns: []Column{
+			{Name: "id", Type: BigInt{}, NotNull: true, Default: AutoIncr{}},
+			{Name: "owner", Type: Varchar(100), NotNull: true},
+			{Name: "date", Type: DateTime{}, NotNull: true},
+			{Name: "username", Type: Varchar(100), NotNull: true},
+			{Name: "object_type", Type: Varchar(50), NotNull: true},
+			{Name: "object_id", Type: BigInt{}, NotNull: true},
+			{Name: "changes", Type: Text{}, NotNull: true, Default: "[]"},
+		},
+		PrimaryKey: &PrimaryKey{Name: "audit_logs_pkey", Cols: []string{"id"}},
+	}); err != nil {
+		return fmt.Errorf(`failed to create the "audit_logs" table: %w`, err)
+	}
+
+	return nil
+}
+
+func ver0_17_0AddAuditLogsDown(db Actions) error {
+	if err := db.DropTable("audit_logs"); err != nil {
+		return fmt.Errorf(`failed to drop the "audit_logs" table: %w`, err)
+	}
+
+	return nil
+}
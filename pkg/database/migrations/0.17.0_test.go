@@ -0,0 +1,383 @@
+package migrations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testVer0_17_0AddAuthorityRevocation(t *testing.T, eng *testEngine) Change {
+	mig := Migrations[66]
+
+	t.Run("When applying the 0.17.0 authorities revocation addition", func(t *testing.T) {
+		tableShouldNotHaveColumns(t, eng.DB, "auth_authorities",
+			"revocation_policy", "crl_url", "ocsp_url")
+
+		require.NoError(t, eng.Upgrade(mig), "The migration should not fail")
+
+		t.Run("Then it should have added the new columns", func(t *testing.T) {
+			tableShouldHaveColumns(t, eng.DB, "auth_authorities",
+				"revocation_policy", "crl_url", "ocsp_url")
+		})
+
+		t.Run("When reverting the migration", func(t *testing.T) {
+			require.NoError(t, eng.Downgrade(mig), "Reverting the migration should not fail")
+
+			t.Run("Then it should have dropped the new columns", func(t *testing.T) {
+				tableShouldNotHaveColumns(t, eng.DB, "auth_authorities",
+					"revocation_policy", "crl_url", "ocsp_url")
+			})
+		})
+	})
+
+	return mig
+}
+
+func testVer0_17_0AddTaskCondition(t *testing.T, eng *testEngine) Change {
+	mig := Migrations[67]
+
+	t.Run("When applying the 0.17.0 tasks condition addition", func(t *testing.T) {
+		tableShouldNotHaveColumns(t, eng.DB, "tasks", "condition", "on_error")
+
+		require.NoError(t, eng.Upgrade(mig), "The migration should not fail")
+
+		t.Run("Then it should have added the new columns", func(t *testing.T) {
+			tableShouldHaveColumns(t, eng.DB, "tasks", "condition", "on_error")
+		})
+
+		t.Run("When reverting the migration", func(t *testing.T) {
+			require.NoError(t, eng.Downgrade(mig), "Reverting the migration should not fail")
+
+			t.Run("Then it should have dropped the new columns", func(t *testing.T) {
+				tableShouldNotHaveColumns(t, eng.DB, "tasks", "condition", "on_error")
+			})
+		})
+	})
+
+	return mig
+}
+
+func testVer0_17_0AddTaskExecutionParams(t *testing.T, eng *testEngine) Change {
+	mig := Migrations[68]
+
+	t.Run("When applying the 0.17.0 tasks execution parameters addition", func(t *testing.T) {
+		tableShouldNotHaveColumns(t, eng.DB, "tasks", "retries", "retry_delay",
+			"timeout", "parallel_group")
+
+		require.NoError(t, eng.Upgrade(mig), "The migration should not fail")
+
+		t.Run("Then it should have added the new columns", func(t *testing.T) {
+			tableShouldHaveColumns(t, eng.DB, "tasks", "retries", "retry_delay",
+				"timeout", "parallel_group")
+		})
+
+		t.Run("When reverting the migration", func(t *testing.T) {
+			require.NoError(t, eng.Downgrade(mig), "Reverting the migration should not fail")
+
+			t.Run("Then it should have dropped the new columns", func(t *testing.T) {
+				tableShouldNotHaveColumns(t, eng.DB, "tasks", "retries", "retry_delay",
+					"timeout", "parallel_group")
+			})
+		})
+	})
+
+	return mig
+}
+
+func testVer0_17_0AddMessageBrokers(t *testing.T, eng *testEngine) Change {
+	mig := Migrations[69]
+
+	t.Run("When applying the 0.17.0 message brokers addition", func(t *testing.T) {
+		require.False(t, doesTableExist(t, eng.DB, eng.Dialect, "message_brokers"))
+
+		require.NoError(t, eng.Upgrade(mig), "The migration should not fail")
+
+		t.Run("Then it should have added the new table", func(t *testing.T) {
+			assert.True(t, doesTableExist(t, eng.DB, eng.Dialect, "message_brokers"))
+			tableShouldHaveColumns(t, eng.DB, "message_brokers", "id", "owner",
+				"name", "type", "address", "login", "password", "options")
+		})
+
+		t.Run("When reverting the migration", func(t *testing.T) {
+			require.NoError(t, eng.Downgrade(mig), "Reverting the migration should not fail")
+
+			t.Run("Then it should have dropped the new table", func(t *testing.T) {
+				assert.False(t, doesTableExist(t, eng.DB, eng.Dialect, "message_brokers"))
+			})
+		})
+	})
+
+	return mig
+}
+
+func testVer0_17_0AddScripts(t *testing.T, eng *testEngine) Change {
+	mig := Migrations[70]
+
+	t.Run("When applying the 0.17.0 scripts addition", func(t *testing.T) {
+		require.False(t, doesTableExist(t, eng.DB, eng.Dialect, "scripts"))
+
+		require.NoError(t, eng.Upgrade(mig), "The migration should not fail")
+
+		t.Run("Then it should have added the new table", func(t *testing.T) {
+			assert.True(t, doesTableExist(t, eng.DB, eng.Dialect, "scripts"))
+			tableShouldHaveColumns(t, eng.DB, "scripts", "id", "name", "content")
+		})
+
+		t.Run("When reverting the migration", func(t *testing.T) {
+			require.NoError(t, eng.Downgrade(mig), "Reverting the migration should not fail")
+
+			t.Run("Then it should have dropped the new table", func(t *testing.T) {
+				assert.False(t, doesTableExist(t, eng.DB, eng.Dialect, "scripts"))
+			})
+		})
+	})
+
+	return mig
+}
+
+func testVer0_17_0AddCopybooks(t *testing.T, eng *testEngine) Change {
+	mig := Migrations[71]
+
+	t.Run("When applying the 0.17.0 copybooks addition", func(t *testing.T) {
+		require.False(t, doesTableExist(t, eng.DB, eng.Dialect, "copybooks"))
+
+		require.NoError(t, eng.Upgrade(mig), "The migration should not fail")
+
+		t.Run("Then it should have added the new table", func(t *testing.T) {
+			assert.True(t, doesTableExist(t, eng.DB, eng.Dialect, "copybooks"))
+			tableShouldHaveColumns(t, eng.DB, "copybooks", "id", "name", "content")
+		})
+
+		t.Run("When reverting the migration", func(t *testing.T) {
+			require.NoError(t, eng.Downgrade(mig), "Reverting the migration should not fail")
+
+			t.Run("Then it should have dropped the new table", func(t *testing.T) {
+				assert.False(t, doesTableExist(t, eng.DB, eng.Dialect, "copybooks"))
+			})
+		})
+	})
+
+	return mig
+}
+
+func testVer0_17_0AddRunningTransfers(t *testing.T, eng *testEngine) Change {
+	mig := Migrations[72]
+
+	t.Run("When applying the 0.17.0 running transfers addition", func(t *testing.T) {
+		require.False(t, doesTableExist(t, eng.DB, eng.Dialect, "running_transfers"))
+
+		require.NoError(t, eng.Upgrade(mig), "The migration should not fail")
+
+		t.Run("Then it should have added the new table", func(t *testing.T) {
+			assert.True(t, doesTableExist(t, eng.DB, eng.Dialect, "running_transfers"))
+			tableShouldHaveColumns(t, eng.DB, "running_transfers", "id",
+				"transfer_id", "node", "command", "since")
+		})
+
+		t.Run("When reverting the migration", func(t *testing.T) {
+			require.NoError(t, eng.Downgrade(mig), "Reverting the migration should not fail")
+
+			t.Run("Then it should have dropped the new table", func(t *testing.T) {
+				assert.False(t, doesTableExist(t, eng.DB, eng.Dialect, "running_transfers"))
+			})
+		})
+	})
+
+	return mig
+}
+
+func testVer0_17_0AddClusterNodes(t *testing.T, eng *testEngine) Change {
+	mig := Migrations[73]
+
+	t.Run("When applying the 0.17.0 cluster nodes addition", func(t *testing.T) {
+		require.False(t, doesTableExist(t, eng.DB, eng.Dialect, "cluster_nodes"))
+		tableShouldNotHaveColumns(t, eng.DB, "running_transfers", "lease_expiry")
+
+		require.NoError(t, eng.Upgrade(mig), "The migration should not fail")
+
+		t.Run("Then it should have added the new table", func(t *testing.T) {
+			assert.True(t, doesTableExist(t, eng.DB, eng.Dialect, "cluster_nodes"))
+			tableShouldHaveColumns(t, eng.DB, "cluster_nodes", "id", "name",
+				"hostname", "started_at", "last_heartbeat", "lease_expiry")
+		})
+
+		t.Run("Then it should have added the lease column", func(t *testing.T) {
+			tableShouldHaveColumns(t, eng.DB, "running_transfers", "lease_expiry")
+		})
+
+		t.Run("When reverting the migration", func(t *testing.T) {
+			require.NoError(t, eng.Downgrade(mig), "Reverting the migration should not fail")
+
+			t.Run("Then it should have dropped the new table", func(t *testing.T) {
+				assert.False(t, doesTableExist(t, eng.DB, eng.Dialect, "cluster_nodes"))
+			})
+
+			t.Run("Then it should have dropped the lease column", func(t *testing.T) {
+				tableShouldNotHaveColumns(t, eng.DB, "running_transfers", "lease_expiry")
+			})
+		})
+	})
+
+	return mig
+}
+
+func testVer0_17_0AddClusterLimits(t *testing.T, eng *testEngine) Change {
+	mig := Migrations[74]
+
+	t.Run("When applying the 0.17.0 cluster limits columns addition", func(t *testing.T) {
+		tableShouldNotHaveColumns(t, eng.DB, "running_transfers", "is_server")
+		tableShouldNotHaveColumns(t, eng.DB, "cluster_nodes", "services")
+
+		require.NoError(t, eng.Upgrade(mig), "The migration should not fail")
+
+		t.Run("Then it should have added the new columns", func(t *testing.T) {
+			tableShouldHaveColumns(t, eng.DB, "running_transfers", "is_server")
+			tableShouldHaveColumns(t, eng.DB, "cluster_nodes", "services")
+		})
+
+		t.Run("When reverting the migration", func(t *testing.T) {
+			require.NoError(t, eng.Downgrade(mig), "Reverting the migration should not fail")
+
+			t.Run("Then it should have dropped the new columns", func(t *testing.T) {
+				tableShouldNotHaveColumns(t, eng.DB, "running_transfers", "is_server")
+				tableShouldNotHaveColumns(t, eng.DB, "cluster_nodes", "services")
+			})
+		})
+	})
+
+	return mig
+}
+
+func testVer0_17_0AddRetryPolicies(t *testing.T, eng *testEngine) Change {
+	mig := Migrations[75]
+
+	t.Run("When applying the 0.17.0 retry policies addition", func(t *testing.T) {
+		require.False(t, doesTableExist(t, eng.DB, eng.Dialect, "retry_policies"))
+		tableShouldNotHaveColumns(t, eng.DB, "rules", "retry_policy")
+		tableShouldNotHaveColumns(t, eng.DB, "remote_agents", "retry_policy")
+
+		require.NoError(t, eng.Upgrade(mig), "The migration should not fail")
+
+		t.Run("Then it should have added the new table", func(t *testing.T) {
+			assert.True(t, doesTableExist(t, eng.DB, eng.Dialect, "retry_policies"))
+			tableShouldHaveColumns(t, eng.DB, "retry_policies", "id", "name",
+				"max_attempts", "first_delay", "increment_factor", "max_delay",
+				"jitter", "max_window", "error_codes")
+		})
+
+		t.Run("Then it should have added the new columns", func(t *testing.T) {
+			tableShouldHaveColumns(t, eng.DB, "rules", "retry_policy")
+			tableShouldHaveColumns(t, eng.DB, "remote_agents", "retry_policy")
+		})
+
+		t.Run("When reverting the migration", func(t *testing.T) {
+			require.NoError(t, eng.Downgrade(mig), "Reverting the migration should not fail")
+
+			t.Run("Then it should have dropped the new table", func(t *testing.T) {
+				assert.False(t, doesTableExist(t, eng.DB, eng.Dialect, "retry_policies"))
+			})
+
+			t.Run("Then it should have dropped the new columns", func(t *testing.T) {
+				tableShouldNotHaveColumns(t, eng.DB, "rules", "retry_policy")
+				tableShouldNotHaveColumns(t, eng.DB, "remote_agents", "retry_policy")
+			})
+		})
+	})
+
+	return mig
+}
+
+func testVer0_17_0AddSLAs(t *testing.T, eng *testEngine) Change {
+	mig := Migrations[76]
+
+	t.Run("When applying the 0.17.0 SLAs addition", func(t *testing.T) {
+		require.False(t, doesTableExist(t, eng.DB, eng.Dialect, "slas"))
+		require.False(t, doesTableExist(t, eng.DB, eng.Dialect, "sla_breaches"))
+
+		require.NoError(t, eng.Upgrade(mig), "The migration should not fail")
+
+		t.Run("Then it should have added the new tables", func(t *testing.T) {
+			assert.True(t, doesTableExist(t, eng.DB, eng.Dialect, "slas"))
+			tableShouldHaveColumns(t, eng.DB, "slas", "id", "owner", "name",
+				"rule_id", "remote_agent_id", "deadline", "deadline_days",
+				"max_duration", "min_throughput")
+
+			assert.True(t, doesTableExist(t, eng.DB, eng.Dialect, "sla_breaches"))
+			tableShouldHaveColumns(t, eng.DB, "sla_breaches", "id", "sla_id",
+				"kind", "transfer_id", "date", "detected", "details")
+		})
+
+		t.Run("When reverting the migration", func(t *testing.T) {
+			require.NoError(t, eng.Downgrade(mig), "Reverting the migration should not fail")
+
+			t.Run("Then it should have dropped the new tables", func(t *testing.T) {
+				assert.False(t, doesTableExist(t, eng.DB, eng.Dialect, "sla_breaches"))
+				assert.False(t, doesTableExist(t, eng.DB, eng.Dialect, "slas"))
+			})
+		})
+	})
+
+	return mig
+}
+
+func testVer0_17_0AddExpectedFiles(t *testing.T, eng *testEngine) Change {
+	mig := Migrations[77]
+
+	t.Run("When applying the 0.17.0 expected files addition", func(t *testing.T) {
+		require.False(t, doesTableExist(t, eng.DB, eng.Dialect, "expected_files"))
+		require.False(t, doesTableExist(t, eng.DB, eng.Dialect, "expected_file_checks"))
+
+		require.NoError(t, eng.Upgrade(mig), "The migration should not fail")
+
+		t.Run("Then it should have added the new tables", func(t *testing.T) {
+			assert.True(t, doesTableExist(t, eng.DB, eng.Dialect, "expected_files"))
+			tableShouldHaveColumns(t, eng.DB, "expected_files", "id", "owner",
+				"name", "disabled", "rule_id", "remote_agent_id", "local_account_id",
+				"pattern", "window_start", "window_end", "days", "email_alert",
+				"created")
+
+			assert.True(t, doesTableExist(t, eng.DB, eng.Dialect, "expected_file_checks"))
+			tableShouldHaveColumns(t, eng.DB, "expected_file_checks", "id",
+				"expected_file_id", "window_start", "window_end", "status", "files",
+				"checked")
+		})
+
+		t.Run("When reverting the migration", func(t *testing.T) {
+			require.NoError(t, eng.Downgrade(mig), "Reverting the migration should not fail")
+
+			t.Run("Then it should have dropped the new tables", func(t *testing.T) {
+				assert.False(t, doesTableExist(t, eng.DB, eng.Dialect, "expected_file_checks"))
+				assert.False(t, doesTableExist(t, eng.DB, eng.Dialect, "expected_files"))
+			})
+		})
+	})
+
+	return mig
+}
+
+func testVer0_17_0AddAuditLogs(t *testing.T, eng *testEngine) Change {
+	mig := Migrations[78]
+
+	t.Run("When applying the 0.17.0 audit logs addition", func(t *testing.T) {
+		require.False(t, doesTableExist(t, eng.DB, eng.Dialect, "audit_logs"))
+
+		require.NoError(t, eng.Upgrade(mig), "The migration should not fail")
+
+		t.Run("Then it should have added the new table", func(t *testing.T) {
+			assert.True(t, doesTableExist(t, eng.DB, eng.Dialect, "audit_logs"))
+			tableShouldHaveColumns(t, eng.DB, "audit_logs", "id", "owner", "date",
+				"username", "object_type", "object_id", "changes")
+		})
+
+		t.Run("When reverting the migration", func(t *testing.T) {
+			require.NoError(t, eng.Downgrade(mig), "Reverting the migration should not fail")
+
+			t.Run("Then it should have dropped the new table", func(t *testing.T) {
+				assert.False(t, doesTableExist(t, eng.DB, eng.Dialect, "audit_logs"))
+			})
+		})
+	})
+
+	return mig
+}
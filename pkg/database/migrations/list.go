@@ -346,4 +346,69 @@ var Migrations = []Change{
 		Up:          ver0_16_0AddNormalizedTransferInfoViewUp,
 		Down:        ver0_16_0AddNormalizedTransferInfoViewDown,
 	},
+	{ // #66
+		Description: `Add the revocation columns to the "auth_authorities" table`,
+		Up:          ver0_17_0AddAuthorityRevocationUp,
+		Down:        ver0_17_0AddAuthorityRevocationDown,
+	},
+	{ // #67
+		Description: `Add the condition columns to the "tasks" table`,
+		Up:          ver0_17_0AddTaskConditionUp,
+		Down:        ver0_17_0AddTaskConditionDown,
+	},
+	{ // #68
+		Description: `Add the execution parameters columns to the "tasks" table`,
+		Up:          ver0_17_0AddTaskExecutionParamsUp,
+		Down:        ver0_17_0AddTaskExecutionParamsDown,
+	},
+	{ // #69
+		Description: `Add the "message_brokers" table`,
+		Up:          ver0_17_0AddMessageBrokersUp,
+		Down:        ver0_17_0AddMessageBrokersDown,
+	},
+	{ // #70
+		Description: `Add the "scripts" table`,
+		Up:          ver0_17_0AddScriptsUp,
+		Down:        ver0_17_0AddScriptsDown,
+	},
+	{ // #71
+		Description: `Add the "copybooks" table`,
+		Up:          ver0_17_0AddCopybooksUp,
+		Down:        ver0_17_0AddCopybooksDown,
+	},
+	{ // #72
+		Description: `Add the "running_transfers" table`,
+		Up:          ver0_17_0AddRunningTransfersUp,
+		Down:        ver0_17_0AddRunningTransfersDown,
+	},
+	{ // #73
+		Description: `Add the "cluster_nodes" table and the running transfers' leases`,
+		Up:          ver0_17_0AddClusterNodesUp,
+		Down:        ver0_17_0AddClusterNodesDown,
+	},
+	{ // #74
+		Description: `Add the columns needed for the cluster-wide limits and status`,
+		Up:          ver0_17_0AddClusterLimitsUp,
+		Down:        ver0_17_0AddClusterLimitsDown,
+	},
+	{ // #75
+		Description: `Add the "retry_policies" table and the rules' & partners' retry policy`,
+		Up:          ver0_17_0AddRetryPoliciesUp,
+		Down:        ver0_17_0AddRetryPoliciesDown,
+	},
+	{ // #76
+		Description: `Add the "slas" and "sla_breaches" tables`,
+		Up:          ver0_17_0AddSLAsUp,
+		Down:        ver0_17_0AddSLAsDown,
+	},
+	{ // #77
+		Description: `Add the "expected_files" and "expected_file_checks" tables`,
+		Up:          ver0_17_0AddExpectedFilesUp,
+		Down:        ver0_17_0AddExpectedFilesDown,
+	},
+	{ // #78
+		Description: `Add the "audit_logs" table`,
+		Up:          ver0_17_0AddAuditLogsUp,
+		Down:        ver0_17_0AddAuditLogsDown,
+	},
 }
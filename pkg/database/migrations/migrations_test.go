@@ -174,4 +174,19 @@ func testMigrations(t *testing.T, eng *testEngine) {
 	// 0.16.0
 	apply(testVer0_16_0AddFilewatchers(t, eng))
 	apply(testVer0_16_0AddNormalizedInfo(t, eng))
+
+	// 0.17.0
+	apply(testVer0_17_0AddAuthorityRevocation(t, eng))
+	apply(testVer0_17_0AddTaskCondition(t, eng))
+	apply(testVer0_17_0AddTaskExecutionParams(t, eng))
+	apply(testVer0_17_0AddMessageBrokers(t, eng))
+	apply(testVer0_17_0AddScripts(t, eng))
+	apply(testVer0_17_0AddCopybooks(t, eng))
+	apply(testVer0_17_0AddRunningTransfers(t, eng))
+	apply(testVer0_17_0AddClusterNodes(t, eng))
+	apply(testVer0_17_0AddClusterLimits(t, eng))
+	apply(testVer0_17_0AddRetryPolicies(t, eng))
+	apply(testVer0_17_0AddSLAs(t, eng))
+	apply(testVer0_17_0AddExpectedFiles(t, eng))
+	apply(testVer0_17_0AddAuditLogs(t, eng))
 }
@@ -80,6 +80,7 @@ var VersionsMap = map[string]int{
 	"0.16.0":  65,
 	"0.16.1":  65,
 	"0.16.2":  65,
+	"0.17.0":  78,
 
 	VersionNone: -1,
 	version.Num: len(Migrations) - 1,
@@ -0,0 +1,182 @@
+package database
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database/migrations"
+)
+
+// TransfersChannel is the notification channel used to signal that new
+// transfers are ready to be executed.
+const TransfersChannel = "waarp_gateway_transfers"
+
+// postgresListenRetryDelay is the delay before trying to reconnect after the
+// connection listening for PostgreSQL notifications has been lost.
+const postgresListenRetryDelay = 5 * time.Second
+
+var errListenConnClosed = errors.New("notification connection closed")
+
+// notifier dispatches notifications to the listeners registered in the
+// current process.
+type notifier struct {
+	mux       sync.Mutex
+	listeners map[string][]chan struct{}
+
+	originOnce sync.Once
+	origin     string
+}
+
+// originID returns the random ID sent as payload of the PostgreSQL
+// notifications sent by this notifier. Since the listeners of the current
+// process are notified directly, this allows the PostgreSQL listener to ignore
+// the notifications sent by the current process, which would otherwise wake
+// the local listeners twice.
+func (n *notifier) originID() string {
+	n.originOnce.Do(func() { n.origin = rand.Text() })
+
+	return n.origin
+}
+
+func (n *notifier) subscribe(channel string) chan struct{} {
+	n.mux.Lock()
+	defer n.mux.Unlock()
+
+	if n.listeners == nil {
+		n.listeners = map[string][]chan struct{}{}
+	}
+
+	listener := make(chan struct{}, 1)
+	n.listeners[channel] = append(n.listeners[channel], listener)
+
+	return listener
+}
+
+func (n *notifier) unsubscribe(channel string, listener chan struct{}) {
+	n.mux.Lock()
+	defer n.mux.Unlock()
+
+	listeners := n.listeners[channel]
+	for i := range listeners {
+		if listeners[i] == listener {
+			n.listeners[channel] = append(listeners[:i], listeners[i+1:]...)
+
+			break
+		}
+	}
+}
+
+func (n *notifier) notify(channel string) {
+	n.mux.Lock()
+	defer n.mux.Unlock()
+
+	for _, listener := range n.listeners[channel] {
+		// The listeners' channels are buffered, so if a notification is already
+		// pending, there is no need to send another one.
+		select {
+		case listener <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Notify sends a notification on the given channel. The notification is
+// received by all the listeners of the channel registered in the current
+// process (see DB.Listen). With PostgreSQL, the notification is also sent to
+// the other processes (and thus to the other nodes of a cluster) via the
+// NOTIFY command.
+//
+// Notifications are best-effort, and should only be used to reduce latency. A
+// missed notification should never prevent an operation from happening.
+func (db *DB) Notify(channel string) {
+	if db.Config.Database.Type == PostgreSQL {
+		if err := db.Exec("SELECT pg_notify(?, ?)", channel, db.notifier.originID()); err != nil {
+			db.Logger.Warningf("Failed to send the %q notification: %v", channel, err)
+		}
+	}
+
+	db.notifier.notify(channel)
+}
+
+// Notify sends a notification on the given channel once the session's
+// transaction has been committed. See DB.Notify for more details.
+func (s *Session) Notify(channel string) {
+	if s.db.Config.Database.Type == PostgreSQL {
+		// PostgreSQL only delivers the notification once the transaction has
+		// been committed, so it can be sent right away, as part of the
+		// session's transaction.
+		if err := s.Exec("SELECT pg_notify(?, ?)", channel, s.db.notifier.originID()); err != nil {
+			s.getLogger().Warningf("Failed to send the %q notification: %v", channel, err)
+		}
+	}
+
+	s.notifications = append(s.notifications, channel)
+}
+
+// Listen registers a new listener on the given notification channel. The
+// returned channel receives a value whenever a notification is sent on the
+// channel (see DB.Notify). Multiple notifications may be coalesced into a
+// single one if they are sent before the listener had time to receive them.
+// The listener is unregistered once the given context is done.
+func (db *DB) Listen(ctx context.Context, channel string) <-chan struct{} {
+	listener := db.notifier.subscribe(channel)
+
+	if db.Config.Database.Type == PostgreSQL {
+		go db.listenPostgres(ctx, channel)
+	}
+
+	go func() {
+		<-ctx.Done()
+		db.notifier.unsubscribe(channel, listener)
+	}()
+
+	return listener
+}
+
+// listenPostgres listens for the notifications sent on the given channel by
+// the other processes using the PostgreSQL LISTEN command, and forwards them
+// to the current process's listeners. The notifications sent by the current
+// process are ignored, since its listeners have already been notified directly.
+func (db *DB) listenPostgres(ctx context.Context, channel string) {
+	for {
+		if err := db.waitPostgresNotifications(ctx, channel); ctx.Err() == nil {
+			db.Logger.Warningf("Stopped listening for %q notifications: %v", channel, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(postgresListenRetryDelay):
+		}
+	}
+}
+
+func (db *DB) waitPostgresNotifications(ctx context.Context, channel string) error {
+	conn, err := pgx.Connect(ctx, migrations.PostgresDSN(&db.Config.Database))
+	if err != nil {
+		return fmt.Errorf("failed to open the notification connection: %w", err)
+	}
+
+	defer conn.Close(context.Background()) //nolint:errcheck //error is irrelevant here
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		return fmt.Errorf("failed to listen for notifications: %w", err)
+	}
+
+	for {
+		notif, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("%w: %w", errListenConnClosed, err)
+		}
+
+		if notif.Payload != db.notifier.originID() {
+			db.notifier.notify(channel)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var errNotifyTestRollback = errors.New("rollback")
+
+func isNotified(listener <-chan struct{}) bool {
+	select {
+	case <-listener:
+		return true
+	default:
+		return false
+	}
+}
+
+func waitNotified(listener <-chan struct{}, timeout time.Duration) bool {
+	select {
+	case <-listener:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+func TestNotify(t *testing.T) {
+	Convey("Given a database with a listener", t, func(c C) {
+		db := TestDatabase(c)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		Reset(cancel)
+
+		listener := db.Listen(ctx, TransfersChannel)
+
+		Convey("When sending a notification", func() {
+			db.Notify(TransfersChannel)
+
+			Convey("Then the listener should have been notified", func() {
+				So(isNotified(listener), ShouldBeTrue)
+			})
+		})
+
+		Convey("When sending a notification on another channel", func() {
+			db.Notify("other_channel")
+
+			Convey("Then the listener should not have been notified", func() {
+				So(isNotified(listener), ShouldBeFalse)
+			})
+		})
+
+		Convey("When sending multiple notifications", func() {
+			db.Notify(TransfersChannel)
+			db.Notify(TransfersChannel)
+
+			Convey("Then they should have been coalesced", func() {
+				So(isNotified(listener), ShouldBeTrue)
+				So(isNotified(listener), ShouldBeFalse)
+			})
+		})
+
+		Convey("When sending a notification inside a transaction", func() {
+			So(db.Transaction(func(ses *Session) error {
+				ses.Notify(TransfersChannel)
+
+				Convey("Then the listener should not be notified before the commit", func() {
+					So(isNotified(listener), ShouldBeFalse)
+				})
+
+				return nil
+			}), ShouldBeNil)
+
+			Convey("Then the listener should be notified after the commit", func() {
+				So(isNotified(listener), ShouldBeTrue)
+			})
+		})
+
+		if db.Config.Database.Type == PostgreSQL {
+			Convey("When the PostgreSQL listener is active", func() {
+				// A notification sent by another process proves that the
+				// LISTEN connection has been established.
+				So(db.Exec("SELECT pg_notify(?, 'other_process')", TransfersChannel), ShouldBeNil)
+
+				So(waitNotified(listener, 5*time.Second), ShouldBeTrue)
+
+				Convey("When the process sends a notification", func() {
+					db.Notify(TransfersChannel)
+
+					Convey("Then the listener should only be notified once", func() {
+						So(isNotified(listener), ShouldBeTrue)
+						So(waitNotified(listener, 200*time.Millisecond), ShouldBeFalse)
+					})
+				})
+			})
+		}
+
+		Convey("When sending a notification inside a transaction which fails", func() {
+			So(db.Transaction(func(ses *Session) error {
+				ses.Notify(TransfersChannel)
+
+				return errNotifyTestRollback
+			}), ShouldNotBeNil)
+
+			Convey("Then the listener should not have been notified", func() {
+				So(isNotified(listener), ShouldBeFalse)
+			})
+		})
+	})
+}
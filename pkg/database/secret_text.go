@@ -2,7 +2,6 @@ package database
 
 import (
 	"context"
-	"crypto/cipher"
 	"errors"
 	"fmt"
 	"reflect"
@@ -11,19 +10,19 @@ import (
 	"gorm.io/gorm/schema"
 )
 
-type aeadKeyType string
+type keyringKeyType string
 
-const aeadKey aeadKeyType = "aead"
+const keyringKey keyringKeyType = "keyring"
 
-var errNoAEAD = errors.New("no aead key found in context")
+var errNoKeyring = errors.New("no AES keyring found in context")
 
 //nolint:gochecknoinits //init is needed here
 func init() {
 	schema.RegisterSerializer("secret", secretText{})
 }
 
-func registerAEAD(db *gorm.DB, aead cipher.AEAD) {
-	ctx := context.WithValue(context.Background(), aeadKey, aead)
+func registerKeyring(db *gorm.DB, keyring *Keyring) {
+	ctx := context.WithValue(context.Background(), keyringKey, keyring)
 	*db = *db.WithContext(ctx)
 }
 
@@ -32,9 +31,9 @@ func registerAEAD(db *gorm.DB, aead cipher.AEAD) {
 type secretText struct{}
 
 func (secretText) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue any) error {
-	key, ok := ctx.Value(aeadKey).(cipher.AEAD)
+	keyring, ok := ctx.Value(keyringKey).(*Keyring)
 	if !ok {
-		return errNoAEAD
+		return errNoKeyring
 	}
 
 	var (
@@ -44,9 +43,9 @@ func (secretText) Scan(ctx context.Context, field *schema.Field, dst reflect.Val
 
 	switch val := dbValue.(type) {
 	case string:
-		cipherText, err = AESDecrypt(key, val)
+		cipherText, err = keyring.Decrypt(val)
 	case []byte:
-		cipherText, err = AESDecrypt(key, string(val))
+		cipherText, err = keyring.Decrypt(string(val))
 	default:
 		//nolint:err113 //too specific to have a base error
 		return fmt.Errorf("unsupported type for SecretText: %T", dbValue)
@@ -65,16 +64,16 @@ func (secretText) Scan(ctx context.Context, field *schema.Field, dst reflect.Val
 }
 
 func (secretText) Value(ctx context.Context, _ *schema.Field, _ reflect.Value, fieldValue any) (any, error) {
-	key, ok := ctx.Value(aeadKey).(cipher.AEAD)
+	keyring, ok := ctx.Value(keyringKey).(*Keyring)
 	if !ok {
-		return nil, errNoAEAD
+		return nil, errNoKeyring
 	}
 
 	switch val := fieldValue.(type) {
 	case string:
-		return AESEncrypt(key, val)
+		return keyring.Encrypt(val)
 	case []byte:
-		return AESEncrypt(key, string(val))
+		return keyring.Encrypt(string(val))
 	default:
 		//nolint:err113 //too specific to have a base error
 		return nil, fmt.Errorf("unsupported type for SecretText: %T", fieldValue)
@@ -41,3 +41,37 @@ func TestSecretText(t *testing.T) {
 		assert.Equal(t, "sesame", check.Password)
 	})
 }
+
+func TestSecretTextReference(t *testing.T) {
+	t.Parallel()
+
+	db := newGormDB(t)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+
+	type testRefStruct struct {
+		Password string `gorm:"serializer:secret"`
+	}
+
+	require.NoError(t, db.AutoMigrate(&testRefStruct{}))
+
+	const ref = "ref+vault://secret/data/gateway#password"
+
+	obj := testRefStruct{Password: ref}
+	require.NoError(t, db.Create(&obj).Error)
+
+	t.Run("test write", func(t *testing.T) {
+		var stored string
+		row := sqlDB.QueryRow("SELECT * FROM test_ref_structs")
+		require.NoError(t, row.Scan(&stored))
+
+		assert.NotEqual(t, ref, stored, "then the reference should be encrypted like any other secret")
+	})
+
+	t.Run("test read", func(t *testing.T) {
+		var check testRefStruct
+		require.NoError(t, db.First(&check).Error)
+
+		assert.Equal(t, ref, check.Password)
+	})
+}
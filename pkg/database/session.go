@@ -18,6 +18,8 @@ import (
 type Session struct {
 	db      *DB
 	session *gorm.DB
+
+	notifications []string
 }
 
 func (s *Session) getOwner() string        { return s.db.getOwner() }
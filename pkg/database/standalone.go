@@ -29,13 +29,18 @@ func (db *DB) TransactionWithTimeout(dur time.Duration, fun TransactionFunc) err
 	defer cancel()
 	engine := db.engine.WithContext(ctx)
 
+	ses := &Session{db: db}
 	err := engine.Transaction(func(tx *gorm.DB) error {
-		ses := &Session{db: db, session: tx}
+		ses.session = tx
 		return fun(ses)
 	})
 
 	switch {
 	case err == nil:
+		for _, channel := range ses.notifications {
+			db.notifier.notify(channel)
+		}
+
 		return nil
 	case isError[*NotFoundError](err),
 		isError[*ValidationError](err),
@@ -56,11 +61,11 @@ func (db *DB) AsDB() *DB                     { return db }
 func (db *DB) GetConfig() *conf.ServerConfig { return db.Config }
 
 func (db *DB) Encrypt(plain string) (string, error) {
-	return AESEncrypt(db.AEAD, plain)
+	return db.getKeyring().Encrypt(plain)
 }
 
 func (db *DB) Decrypt(cipher string) (string, error) {
-	return AESDecrypt(db.AEAD, cipher)
+	return db.getKeyring().Decrypt(cipher)
 }
 
 // Iterate starts building a SQL 'SELECT' query to retrieve entries of the given
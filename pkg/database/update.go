@@ -9,6 +9,14 @@ type UpdateBean interface {
 	Identifier
 }
 
+// GuardedUpdateBean is the interface implemented by the models whose updates
+// must only be applied if an additional condition (the guard) is met by the
+// entry in the database. If the guard is not met, the entry is left untouched,
+// and the update fails with the error returned alongside the guard.
+type GuardedUpdateBean interface {
+	UpdateGuard() (cond string, args []any, err error)
+}
+
 // UpdateQuery is the type representing a SQL UPDATE statement for a single entry.
 type UpdateQuery struct {
 	db   Access
@@ -52,13 +60,46 @@ func (u *UpdateQuery) run(ses *Session) error {
 		query = query.Select("*") //nolint:unqueryvet // "*" is necessary here
 	}
 
-	if err := query.Updates(u.bean).Error; err != nil {
+	var (
+		guardCond string
+		guardArgs []any
+		guardErr  error
+	)
+
+	if guarded, ok := u.bean.(GuardedUpdateBean); ok {
+		if guardCond, guardArgs, guardErr = guarded.UpdateGuard(); guardCond != "" {
+			query = query.Where(guardCond, guardArgs...)
+		}
+	}
+
+	res := query.Updates(u.bean)
+	if err := res.Error; err != nil {
 		logger.Errorf("Failed to update the %s entry n°%d: %v",
 			u.bean.Appellation(), u.bean.GetID(), err)
 
 		return NewInternalError(err)
 	}
 
+	if guardCond != "" && res.RowsAffected == 0 {
+		// Some databases do not count the rows left unchanged by the update,
+		// so the guard must be checked again to know if it was met.
+		var n int64
+		if err := ses.session.Table(u.bean.TableName()).Where("id=?", u.bean.GetID()).
+			Where(guardCond, guardArgs...).Count(&n).Error; err != nil {
+			logger.Errorf("Failed to check the %s entry n°%d update guard: %v",
+				u.bean.Appellation(), u.bean.GetID(), err)
+
+			return NewInternalError(err)
+		}
+
+		if n == 0 {
+			logger.Warningf("The %s entry n°%d was not updated: %v",
+				u.bean.Appellation(), u.bean.GetID(), guardErr)
+
+			return guardErr
+		}
+	}
+
 	if callback, ok := u.bean.(UpdateCallback); ok {
 		if err := callback.AfterUpdate(ses); err != nil {
 			logger.Errorf("%s entry UPDATE callback failed: %v", u.bean.Appellation(), err)
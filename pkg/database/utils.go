@@ -151,6 +151,8 @@ func (g *gormLogger) Trace(_ context.Context, begin time.Time, fc func() (sql st
 	}
 }
 
+// AESEncrypt encrypts the given password using the given AEAD, and returns the
+// result encoded in base64, prefixed with the ID of the key (see KeyID).
 func AESEncrypt(gcm cipher.AEAD, password string) (string, error) {
 	nonce := make([]byte, gcm.NonceSize())
 	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
@@ -160,12 +162,16 @@ func AESEncrypt(gcm cipher.AEAD, password string) (string, error) {
 	cipherBytes := gcm.Seal(nonce, nonce, []byte(password), nil)
 	cipherText := base64.StdEncoding.EncodeToString(cipherBytes)
 
-	return cipherText, nil
+	return keyIDSeparator + KeyID(gcm) + keyIDSeparator + cipherText, nil
 }
 
 var errNonceTooLong = errors.New("the nonce cannot be longer than the text")
 
+// AESDecrypt decrypts the given base64 cipher text using the given AEAD. The
+// key ID prefix (if any) is ignored.
 func AESDecrypt(gcm cipher.AEAD, cipherStr string) (string, error) {
+	_, cipherStr = splitKeyID(cipherStr)
+
 	cryptPassword, err := base64.StdEncoding.DecodeString(cipherStr)
 	if err != nil {
 		return "", fmt.Errorf("failed to decode encrypted password string: %w", err)
@@ -114,7 +114,7 @@ func newGormDB(tb testing.TB) *gorm.DB {
 	db, err := gorm.Open(dialector, &gorm.Config{})
 	require.NoError(tb, err)
 
-	registerAEAD(db, testAEAD)
+	registerKeyring(db, NewKeyring(testAEAD))
 
 	return db
 }
@@ -136,7 +136,7 @@ func newGormPostgresDB(tb testing.TB) *gorm.DB {
 		require.NoError(tb, db.Exec("CREATE SCHEMA public").Error)
 	})
 
-	registerAEAD(db, testAEAD)
+	registerKeyring(db, NewKeyring(testAEAD))
 
 	return db
 }
@@ -0,0 +1,160 @@
+package expectedfile
+
+import (
+	"fmt"
+	"path"
+	"time"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/types"
+)
+
+// scope is the set of transfers with which an expected file can be received.
+type scope struct {
+	rule    *model.Rule
+	partner *model.RemoteAgent
+	server  *model.LocalAgent
+	account *model.LocalAccount
+}
+
+func getScope(db database.ReadAccess, file *model.ExpectedFile) (*scope, error) {
+	sc := &scope{rule: &model.Rule{}}
+
+	if err := db.Get(sc.rule, "id=?", file.RuleID).Run(); err != nil {
+		return nil, fmt.Errorf("failed to retrieve the expected file's rule: %w", err)
+	}
+
+	if file.RemoteAgentID.Valid {
+		sc.partner = &model.RemoteAgent{}
+		if err := db.Get(sc.partner, "id=?", file.RemoteAgentID.Int64).Run(); err != nil {
+			return nil, fmt.Errorf("failed to retrieve the expected file's partner: %w", err)
+		}
+	}
+
+	if file.LocalAccountID.Valid {
+		sc.account = &model.LocalAccount{}
+		if err := db.Get(sc.account, "id=?", file.LocalAccountID.Int64).Run(); err != nil {
+			return nil, fmt.Errorf("failed to retrieve the expected file's local account: %w", err)
+		}
+
+		sc.server = &model.LocalAgent{}
+		if err := db.Get(sc.server, "id=?", sc.account.LocalAgentID).Run(); err != nil {
+			return nil, fmt.Errorf("failed to retrieve the expected file's server: %w", err)
+		}
+	}
+
+	return sc, nil
+}
+
+// receivedFiles returns the names of the files matching the expected file's
+// pattern which were successfully received between the given dates.
+func (sc *scope) receivedFiles(db database.ReadAccess, file *model.ExpectedFile,
+	start, end time.Time,
+) ([]string, error) {
+	var transfers model.NormalizedTransfers
+
+	query := db.Select(&transfers).Owner().
+		Where("rule=? AND is_send=?", sc.rule.Name, false).
+		Where("status=?", types.StatusDone).
+		Where("stop >= ? AND stop < ?", start.UTC(), end.UTC()).
+		OrderBy("stop", true)
+
+	if sc.partner != nil {
+		query.Where("is_server=? AND agent=?", false, sc.partner.Name)
+	}
+
+	if sc.account != nil {
+		query.Where("is_server=? AND agent=? AND account=?", true, sc.server.Name,
+			sc.account.Login)
+	}
+
+	if err := query.Run(); err != nil {
+		return nil, fmt.Errorf("failed to retrieve the received transfers: %w", err)
+	}
+
+	files := []string{}
+
+	for _, trans := range transfers {
+		filename := trans.SrcFilename
+		if filename == "" {
+			filename = trans.DestFilename
+		}
+
+		filename = path.Base(filename)
+		if ok, _ := path.Match(file.Pattern, filename); ok {
+			files = append(files, filename)
+		}
+	}
+
+	return files, nil
+}
+
+// evaluate checks the windows of the given expected file which closed between
+// the given dates, and returns their outcome. The windows which have already
+// been checked, and the windows which opened before the expected file was
+// created, are ignored.
+func evaluate(db database.ReadAccess, file *model.ExpectedFile, since, now time.Time,
+) ([]*model.ExpectedFileCheck, *scope, error) {
+	sc, err := getScope(db, file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	since, now = since.Local(), now.Local()
+
+	var checks []*model.ExpectedFileCheck
+
+	// The windows can close on the day after they open, so the windows which
+	// opened on the day before "since" are also considered.
+	year, month, dayOfMonth := since.Date()
+	firstDay := time.Date(year, month, dayOfMonth-1, 0, 0, 0, 0, since.Location())
+
+	for day := firstDay; !day.After(now); day = day.AddDate(0, 0, 1) {
+		start, end, ok := file.WindowOn(day)
+		if !ok || !end.After(since) || end.After(now) || start.Before(file.Created) {
+			continue
+		}
+
+		if exists, existErr := isChecked(db, file, start); existErr != nil {
+			return nil, nil, existErr
+		} else if exists {
+			continue
+		}
+
+		files, filesErr := sc.receivedFiles(db, file, start, end)
+		if filesErr != nil {
+			return nil, nil, filesErr
+		}
+
+		check := &model.ExpectedFileCheck{
+			ExpectedFileID: file.ID,
+			WindowStart:    start,
+			WindowEnd:      end,
+			Status:         model.ExpectedFileReceived,
+			Files:          files,
+		}
+
+		switch {
+		case len(files) == 0:
+			check.Status = model.ExpectedFileMissing
+		case len(files) > 1:
+			check.Status = model.ExpectedFileDuplicate
+		}
+
+		checks = append(checks, check)
+	}
+
+	return checks, sc, nil
+}
+
+func isChecked(db database.ReadAccess, file *model.ExpectedFile, start time.Time) (bool, error) {
+	n, err := db.Count(&model.ExpectedFileCheck{}).
+		Where("expected_file_id=? AND window_start=?", file.ID, start.UTC()).
+		Run()
+	if err != nil {
+		return false, fmt.Errorf("failed to check for existing expected file checks: %w", err)
+	}
+
+	return n != 0, nil
+}
@@ -0,0 +1,145 @@
+package expectedfile
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database/dbtest"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/modeltest"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/types"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils"
+)
+
+const testProtocol = "test_proto"
+
+//nolint:gochecknoinits // init is used to ease the tests
+func init() {
+	modeltest.AddDummyProtoConfig(testProtocol)
+}
+
+//nolint:funlen //test setup is long
+func TestEvaluate(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+
+	year, month, day := time.Now().Date()
+	today := func(hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, time.Local)
+	}
+	now := today(12, 0)
+
+	rule := &model.Rule{Name: "recv", IsSend: false}
+	require.NoError(t, db.Insert(rule).Run())
+
+	partner := &model.RemoteAgent{
+		Name: "partner", Protocol: testProtocol,
+		Address: types.Addr("localhost", 443),
+	}
+	require.NoError(t, db.Insert(partner).Run())
+
+	addHistory := func(id int64, filename string, stop time.Time) {
+		require.NoError(t, db.Insert(&model.HistoryEntry{
+			Identifier:       model.Identifier{ID: id},
+			Owner:            db.Config.GatewayName,
+			RemoteTransferID: utils.FormatInt(id),
+			IsSend:           false,
+			Rule:             rule.Name,
+			Account:          "toto",
+			Agent:            partner.Name,
+			Client:           "client",
+			Protocol:         testProtocol,
+			SrcFilename:      "dir/" + filename,
+			Start:            stop.Add(-time.Minute),
+			Stop:             stop,
+			Status:           types.StatusDone,
+			Step:             types.StepNone,
+		}).Run())
+	}
+
+	addHistory(1, "a_1.csv", today(7, 0))
+	addHistory(2, "b_1.csv", today(6, 30))
+	addHistory(3, "b_2.csv", today(7, 30))
+	addHistory(4, "c_1.csv", today(9, 0)) // after the window
+
+	addFile := func(name, pattern string) *model.ExpectedFile {
+		file := &model.ExpectedFile{
+			Name:          name,
+			RuleID:        rule.ID,
+			RemoteAgentID: sql.NullInt64{Int64: partner.ID, Valid: true},
+			Pattern:       pattern,
+			WindowStart:   "06:00",
+			WindowEnd:     "08:00",
+			Days:          model.List[string]{now.Weekday().String()},
+			Created:       today(0, 0),
+		}
+		require.NoError(t, db.Insert(file).Run())
+
+		return file
+	}
+
+	received := addFile("received", "a_*.csv")
+	duplicate := addFile("duplicate", "b_*.csv")
+	missing := addFile("missing", "c_*.csv")
+
+	since := now.Add(-initialLookback)
+
+	t.Run("When the window has not closed yet", func(t *testing.T) {
+		checks, _, err := evaluate(db, received, since, today(7, 59))
+		require.NoError(t, err)
+		assert.Empty(t, checks)
+	})
+
+	t.Run("When the file was received once", func(t *testing.T) {
+		checks, _, err := evaluate(db, received, since, now)
+		require.NoError(t, err)
+		require.Len(t, checks, 1)
+
+		assert.Equal(t, model.ExpectedFileReceived, checks[0].Status)
+		assert.Equal(t, model.List[string]{"a_1.csv"}, checks[0].Files)
+		assert.True(t, today(6, 0).Equal(checks[0].WindowStart))
+		assert.True(t, today(8, 0).Equal(checks[0].WindowEnd))
+	})
+
+	t.Run("When the file was received twice", func(t *testing.T) {
+		checks, _, err := evaluate(db, duplicate, since, now)
+		require.NoError(t, err)
+		require.Len(t, checks, 1)
+
+		assert.Equal(t, model.ExpectedFileDuplicate, checks[0].Status)
+		assert.Equal(t, model.List[string]{"b_1.csv", "b_2.csv"}, checks[0].Files)
+	})
+
+	t.Run("When the file was not received during the window", func(t *testing.T) {
+		checks, _, err := evaluate(db, missing, since, now)
+		require.NoError(t, err)
+		require.Len(t, checks, 1)
+
+		assert.Equal(t, model.ExpectedFileMissing, checks[0].Status)
+		assert.Empty(t, checks[0].Files)
+
+		t.Run("Then the recorded check should not be returned again", func(t *testing.T) {
+			checks[0].Checked = now
+			require.NoError(t, db.Insert(checks[0]).Run())
+
+			again, _, err := evaluate(db, missing, since, now.Add(time.Hour))
+			require.NoError(t, err)
+			assert.Empty(t, again)
+		})
+	})
+
+	t.Run("When the expected file was created after the window opened", func(t *testing.T) {
+		late := addFile("late", "*")
+		late.Created = today(7, 0)
+		require.NoError(t, db.Update(late).Run())
+
+		checks, _, err := evaluate(db, late, since, now)
+		require.NoError(t, err)
+		assert.Empty(t, checks)
+	})
+}
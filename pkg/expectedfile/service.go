@@ -0,0 +1,181 @@
+// Package expectedfile contains the service responsible for checking that the
+// expected inbound files are received, and for alerting when one of them is
+// missing or was received more than once.
+package expectedfile
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/tasks"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils"
+)
+
+const ServiceName = "Expected files monitor"
+
+// checkInterval is the interval between 2 checks of the expected files.
+const checkInterval = time.Minute
+
+// initialLookback is how far back the service looks for closed windows on its
+// first check.
+const initialLookback = 24 * time.Hour
+
+// Service is the service responsible for checking, at the end of each of their
+// windows, whether the expected files stored in the database have been
+// received. The outcome of each window is recorded in the database, and an
+// alert (via the logs and, if configured, an email) is sent when a file is
+// missing or was received more than once.
+//
+// Since the outcomes are recorded, each window is only checked (and notified)
+// once, even after a restart of the gateway.
+type Service struct {
+	DB *database.DB
+
+	logger *log.Logger
+	state  utils.State
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	lastCheck time.Time
+}
+
+func (s *Service) Name() string { return ServiceName }
+
+func (s *Service) Start() error {
+	if s.state.IsRunning() {
+		return nil
+	}
+
+	s.logger = logging.NewLogger(ServiceName)
+
+	var ctx context.Context
+
+	ctx, s.cancel = context.WithCancel(context.Background())
+	s.done = make(chan struct{})
+
+	go s.run(ctx)
+
+	s.state.Set(utils.StateRunning, "")
+
+	return nil
+}
+
+func (s *Service) run(ctx context.Context) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		s.check(time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) Stop(ctx context.Context) error {
+	if !s.state.IsRunning() {
+		return utils.ErrNotRunning
+	}
+
+	s.cancel()
+
+	select {
+	case <-s.done:
+	case <-ctx.Done():
+		return fmt.Errorf("failed to stop the expected files monitor: %w", ctx.Err())
+	}
+
+	s.state.Set(utils.StateOffline, "")
+
+	return nil
+}
+
+func (s *Service) State() (utils.StateCode, string) { return s.state.Get() }
+
+// check checks the windows of all the expected files which closed since the
+// previous check, and records & notifies their outcome.
+func (s *Service) check(now time.Time) {
+	since := now.Add(-initialLookback)
+	if !s.lastCheck.IsZero() {
+		since = s.lastCheck
+	}
+
+	var files model.ExpectedFiles
+	if err := s.DB.Select(&files).Owner().Where("disabled=?", false).Run(); err != nil {
+		s.logger.Errorf("Failed to retrieve the expected files: %v", err)
+
+		return
+	}
+
+	for _, file := range files {
+		checks, sc, err := evaluate(s.DB, file, since, now)
+		if err != nil {
+			s.logger.Errorf("Failed to check the expected file %q: %v", file.Name, err)
+
+			continue
+		}
+
+		for _, check := range checks {
+			check.Checked = now
+
+			if err := s.DB.Insert(check).Run(); err != nil {
+				s.logger.Errorf("Failed to record the check of the expected file %q: %v",
+					file.Name, err)
+
+				continue
+			}
+
+			if check.Status != model.ExpectedFileReceived {
+				s.alert(file, sc, check)
+			}
+		}
+	}
+
+	s.lastCheck = now
+}
+
+func (s *Service) alert(file *model.ExpectedFile, sc *scope, check *model.ExpectedFileCheck) {
+	start := check.WindowStart.Local().Format(time.RFC3339)
+	end := check.WindowEnd.Local().Format(time.RFC3339)
+
+	if check.Status == model.ExpectedFileMissing {
+		s.logger.Warningf("The expected file %q was not received between %s and %s",
+			file.Name, start, end)
+	} else {
+		s.logger.Warningf("The expected file %q was received %d times between %s and %s: %s",
+			file.Name, len(check.Files), start, end, strings.Join(check.Files, ", "))
+	}
+
+	if len(file.EmailAlert) == 0 {
+		return
+	}
+
+	replacer := strings.NewReplacer(
+		"#LOCALHOST#", s.DB.Config.GatewayName,
+		"#EXPECTEDFILE#", file.Name,
+		"#RULE#", sc.rule.Name,
+		"#PATTERN#", file.Pattern,
+		"#STATUS#", string(check.Status),
+		"#WINDOWSTART#", start,
+		"#WINDOWEND#", end,
+		"#FILECOUNT#", strconv.Itoa(len(check.Files)),
+		"#FILES#", strings.Join(check.Files, ", "),
+	)
+
+	if err := tasks.SendEmail(s.DB, s.logger, file.EmailAlert, nil, replacer); err != nil {
+		s.logger.Errorf("Failed to send the alert email of the expected file %q: %v",
+			file.Name, err)
+	}
+}
@@ -0,0 +1,196 @@
+package expiry
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	pgp "github.com/ProtonMail/gopenpgp/v3/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database/dbtest"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/authentication/auth"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/modeltest"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/types"
+)
+
+const (
+	testProtocol = "test_proto"
+	day          = 24 * time.Hour
+)
+
+//nolint:gochecknoinits // init is used to ease the tests
+func init() {
+	modeltest.AddDummyProtoConfig(testProtocol)
+}
+
+// makeTestCert returns a self-signed certificate (and its private key) valid
+// for "localhost", which expires after the given duration.
+func makeTestCert(tb testing.TB, validity time.Duration) (certPEM, keyPEM string) {
+	tb.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(tb, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		DNSNames:              []string{"localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(validity),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage: []x509.ExtKeyUsage{
+			x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth,
+		},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(tb, err)
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(tb, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}))
+}
+
+func TestList(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+
+	server := &model.LocalAgent{
+		Name: "server", Protocol: testProtocol,
+		Address: types.Addr("localhost", 443),
+	}
+	require.NoError(t, db.Insert(server).Run())
+
+	account := &model.LocalAccount{LocalAgentID: server.ID, Login: "toto"}
+	require.NoError(t, db.Insert(account).Run())
+
+	partner := &model.RemoteAgent{
+		Name: "partner", Protocol: testProtocol,
+		Address: types.Addr("localhost", 443),
+	}
+	require.NoError(t, db.Insert(partner).Run())
+
+	serverCert, serverKey := makeTestCert(t, 10*day)
+	require.NoError(t, db.Insert(&model.Credential{
+		LocalAgentID: server.NullableID(),
+		Name:         "server-cert",
+		Type:         auth.TLSCertificate,
+		Value:        serverCert,
+		Value2:       serverKey,
+	}).Run())
+
+	partnerCert, _ := makeTestCert(t, 100*day)
+	require.NoError(t, db.Insert(&model.Credential{
+		RemoteAgentID: partner.NullableID(),
+		Name:          "partner-cert",
+		Type:          auth.TLSTrustedCertificate,
+		Value:         partnerCert,
+	}).Run())
+
+	require.NoError(t, db.Insert(&model.Credential{
+		LocalAccountID: account.NullableID(),
+		Name:           "password",
+		Type:           auth.Password,
+		Value:          "sesame",
+	}).Run())
+
+	authorityCert, _ := makeTestCert(t, 5*day)
+	require.NoError(t, db.Insert(&model.Authority{
+		Name:           "authority",
+		Type:           auth.AuthorityTLS,
+		PublicIdentity: authorityCert,
+	}).Run())
+
+	pgpKey, err := pgp.PGP().KeyGeneration().AddUserId("test", "test@example.com").
+		Lifetime(int32(20 * day / time.Second)).New().GenerateKey()
+	require.NoError(t, err)
+
+	armoredKey, err := pgpKey.Armor()
+	require.NoError(t, err)
+
+	require.NoError(t, db.Insert(&model.CryptoKey{
+		Name: "pgp-key",
+		Type: model.CryptoKeyTypePGPPrivate,
+		Key:  armoredKey,
+	}).Run())
+
+	t.Run("When listing the certificates expiring within 30 days", func(t *testing.T) {
+		t.Parallel()
+
+		now := time.Now()
+
+		items, err := List(db, now.Add(30*day))
+		require.NoError(t, err)
+		require.Len(t, items, 3)
+
+		assert.Equal(t, KindAuthority, items[0].Kind)
+		assert.Equal(t, "authority", items[0].Name)
+		assert.Empty(t, items[0].Owner)
+		assert.Equal(t, 4, items[0].DaysLeft(now))
+
+		assert.Equal(t, KindCredential, items[1].Kind)
+		assert.Equal(t, "server-cert", items[1].Name)
+		assert.Equal(t, `server "server"`, items[1].Owner)
+		assert.Equal(t, auth.TLSCertificate, items[1].Type)
+		assert.Equal(t, 9, items[1].DaysLeft(now))
+
+		assert.Equal(t, KindCryptoKey, items[2].Kind)
+		assert.Equal(t, "pgp-key", items[2].Name)
+		assert.Equal(t, 19, items[2].DaysLeft(now))
+	})
+
+	t.Run("When listing the certificates expiring within a year", func(t *testing.T) {
+		t.Parallel()
+
+		items, err := List(db, time.Now().Add(365*day))
+		require.NoError(t, err)
+		require.Len(t, items, 4)
+
+		assert.Equal(t, "partner-cert", items[3].Name)
+		assert.Equal(t, `partner "partner"`, items[3].Owner)
+	})
+}
+
+func TestNewAlerts(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	thresholds := []uint{1, 7, 30}
+	service := &Service{}
+
+	expiring := &Item{Kind: KindCredential, Name: "expiring", NotAfter: now.Add(20 * day)}
+	expired := &Item{Kind: KindCredential, Name: "expired", NotAfter: now.Add(-day)}
+
+	assert.Equal(t, []*Item{expiring, expired},
+		service.newAlerts([]*Item{expiring, expired}, thresholds, now),
+		"the first check should alert for all the certificates")
+
+	assert.Empty(t, service.newAlerts([]*Item{expiring, expired}, thresholds, now.Add(day)),
+		"no threshold was crossed, so no alert should be sent")
+
+	assert.Equal(t, []*Item{expiring},
+		service.newAlerts([]*Item{expiring, expired}, thresholds, now.Add(15*day)),
+		"the 7 days threshold was crossed, so an alert should be sent")
+
+	assert.Empty(t, service.newAlerts([]*Item{expired}, thresholds, now.Add(16*day)),
+		"certificates which no longer expire soon should not be alerted")
+
+	renewed := &Item{Kind: KindCredential, Name: "expiring", NotAfter: now.Add(40 * day)}
+	assert.Equal(t, []*Item{renewed},
+		service.newAlerts([]*Item{expired, renewed}, thresholds, now.Add(16*day)),
+		"a renewed certificate should be alerted again when it crosses a threshold")
+}
@@ -0,0 +1,281 @@
+// Package expiry implements the monitoring of the expiration dates of the
+// certificates and keys stored in the database (the TLS & SSH credentials, the
+// authentication authorities and the PGP keys).
+package expiry
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	pgp "github.com/ProtonMail/gopenpgp/v3/crypto"
+	"golang.org/x/crypto/ssh"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+)
+
+// The kinds of objects which can expire.
+const (
+	KindCredential = "credential"
+	KindAuthority  = "authority"
+	KindCryptoKey  = "key"
+)
+
+const hoursPerDay = 24
+
+// Item is a certificate (or key) stored in the database, along with its
+// expiration date.
+type Item struct {
+	Kind     string    // The kind of object (credential, authority or key).
+	Owner    string    // The credential's owner (empty for the other kinds).
+	Name     string    // The object's name.
+	Type     string    // The object's type.
+	NotAfter time.Time // The expiration date.
+}
+
+// DaysLeft returns the number of whole days remaining before the item expires.
+// The result is negative if the item has already expired.
+func (i *Item) DaysLeft(now time.Time) int {
+	return int(math.Floor(i.NotAfter.Sub(now).Hours() / hoursPerDay))
+}
+
+func (i *Item) String() string {
+	if i.Owner == "" {
+		return fmt.Sprintf("%s %q", i.Kind, i.Name)
+	}
+
+	return fmt.Sprintf("%s %q of %s", i.Kind, i.Name, i.Owner)
+}
+
+// List returns all the certificates & keys stored in the database which expire
+// before the given date (including those which have already expired), sorted
+// by expiration date. Objects without an expiration date (passwords, SSH public
+// keys...) are ignored.
+func List(db database.ReadAccess, before time.Time) ([]*Item, error) {
+	var items []*Item
+
+	for _, list := range []func(database.ReadAccess) ([]*Item, error){
+		listCredentials, listAuthorities, listCryptoKeys,
+	} {
+		found, err := list(db)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, found...)
+	}
+
+	items = slices.DeleteFunc(items, func(item *Item) bool {
+		return !item.NotAfter.Before(before)
+	})
+
+	slices.SortStableFunc(items, func(a, b *Item) int {
+		return a.NotAfter.Compare(b.NotAfter)
+	})
+
+	return items, nil
+}
+
+func listCredentials(db database.ReadAccess) ([]*Item, error) {
+	var items []*Item
+
+	addCredentials := func(owner model.CredOwnerTable, ownerDesc string) error {
+		creds, err := owner.GetCredentials(db)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve the credentials of %s: %w", ownerDesc, err)
+		}
+
+		for _, cred := range creds {
+			if notAfter, ok := credentialExpiry(cred); ok {
+				items = append(items, &Item{
+					Kind:     KindCredential,
+					Owner:    ownerDesc,
+					Name:     cred.Name,
+					Type:     cred.Type,
+					NotAfter: notAfter,
+				})
+			}
+		}
+
+		return nil
+	}
+
+	var servers model.LocalAgents
+	if err := db.Select(&servers).Run(); err != nil {
+		return nil, fmt.Errorf("failed to retrieve the servers: %w", err)
+	}
+
+	for _, server := range servers {
+		if err := addCredentials(server, fmt.Sprintf("server %q", server.Name)); err != nil {
+			return nil, err
+		}
+
+		var accounts model.LocalAccounts
+		if err := db.Select(&accounts).Where("local_agent_id=?", server.ID).Run(); err != nil {
+			return nil, fmt.Errorf("failed to retrieve the accounts of server %q: %w", server.Name, err)
+		}
+
+		for _, account := range accounts {
+			if err := addCredentials(account, fmt.Sprintf("local account %q of server %q",
+				account.Login, server.Name)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var partners model.RemoteAgents
+	if err := db.Select(&partners).Run(); err != nil {
+		return nil, fmt.Errorf("failed to retrieve the partners: %w", err)
+	}
+
+	for _, partner := range partners {
+		if err := addCredentials(partner, fmt.Sprintf("partner %q", partner.Name)); err != nil {
+			return nil, err
+		}
+
+		var accounts model.RemoteAccounts
+		if err := db.Select(&accounts).Where("remote_agent_id=?", partner.ID).Run(); err != nil {
+			return nil, fmt.Errorf("failed to retrieve the accounts of partner %q: %w", partner.Name, err)
+		}
+
+		for _, account := range accounts {
+			if err := addCredentials(account, fmt.Sprintf("remote account %q of partner %q",
+				account.Login, partner.Name)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return items, nil
+}
+
+func listAuthorities(db database.ReadAccess) ([]*Item, error) {
+	var authorities model.Authorities
+	if err := db.Select(&authorities).Run(); err != nil {
+		return nil, fmt.Errorf("failed to retrieve the authorities: %w", err)
+	}
+
+	var items []*Item
+
+	for _, authority := range authorities {
+		if notAfter, ok := certExpiry(authority.PublicIdentity); ok {
+			items = append(items, &Item{
+				Kind:     KindAuthority,
+				Name:     authority.Name,
+				Type:     authority.Type,
+				NotAfter: notAfter,
+			})
+		}
+	}
+
+	return items, nil
+}
+
+func listCryptoKeys(db database.ReadAccess) ([]*Item, error) {
+	var keys model.CryptoKeys
+	if err := db.Select(&keys).Where("type IN (?,?)", model.CryptoKeyTypePGPPrivate,
+		model.CryptoKeyTypePGPPublic).Run(); err != nil {
+		return nil, fmt.Errorf("failed to retrieve the cryptographic keys: %w", err)
+	}
+
+	var items []*Item
+
+	for _, key := range keys {
+		if notAfter, ok := pgpExpiry(key.Key); ok {
+			items = append(items, &Item{
+				Kind:     KindCryptoKey,
+				Name:     key.Name,
+				Type:     key.Type,
+				NotAfter: notAfter,
+			})
+		}
+	}
+
+	return items, nil
+}
+
+// credentialExpiry returns the earliest expiration date of the certificates
+// contained in the given credential's values (if any). Secret references are
+// resolved beforehand. Credentials whose references cannot be resolved are
+// ignored.
+func credentialExpiry(cred *model.Credential) (time.Time, bool) {
+	resolved := *cred
+	if err := resolved.ResolveSecrets(); err != nil {
+		return time.Time{}, false
+	}
+
+	notAfter, ok := certExpiry(resolved.Value)
+
+	if notAfter2, ok2 := certExpiry(resolved.Value2); ok2 && (!ok || notAfter2.Before(notAfter)) {
+		return notAfter2, true
+	}
+
+	return notAfter, ok
+}
+
+// certExpiry returns the expiration date of the given certificate, which can
+// either be a PEM encoded X.509 certificate chain (in which case the earliest
+// expiration date of the chain is returned), or an OpenSSH certificate.
+func certExpiry(value string) (time.Time, bool) {
+	var (
+		notAfter time.Time
+		found    bool
+		block    *pem.Block
+	)
+
+	for rest := []byte(value); ; {
+		if block, rest = pem.Decode(rest); block == nil {
+			break
+		}
+
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			continue
+		}
+
+		if !found || cert.NotAfter.Before(notAfter) {
+			notAfter, found = cert.NotAfter, true
+		}
+	}
+
+	if found {
+		return notAfter, true
+	}
+
+	pubKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(value))
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	if cert, ok := pubKey.(*ssh.Certificate); ok && cert.ValidBefore != ssh.CertTimeInfinity {
+		return time.Unix(int64(cert.ValidBefore), 0), true //nolint:gosec //overflow is not possible here
+	}
+
+	return time.Time{}, false
+}
+
+// pgpExpiry returns the expiration date of the given armored PGP key (if it
+// has one).
+func pgpExpiry(armored string) (time.Time, bool) {
+	key, err := pgp.NewKeyFromArmored(armored)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	entity := key.GetEntity()
+
+	sig, err := entity.PrimarySelfSignature(time.Now(), nil)
+	if err != nil || sig == nil || sig.KeyLifetimeSecs == nil || *sig.KeyLifetimeSecs == 0 {
+		return time.Time{}, false
+	}
+
+	return entity.PrimaryKey.CreationTime.Add(time.Duration(*sig.KeyLifetimeSecs) * time.Second), true
+}
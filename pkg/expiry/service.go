@@ -0,0 +1,229 @@
+package expiry
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/snmp"
+	"code.waarp.fr/apps/gateway/gateway/pkg/tasks"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils"
+)
+
+const ServiceName = "Certificate monitor"
+
+const (
+	defaultEmailSubject = "[#LOCALHOST#] The #CERTKIND# \"#CERTNAME#\" expires in #DAYSLEFT# days"
+	defaultEmailBody    = "The #CERTDESC# (#CERTTYPE#) expires on #EXPIRYDATE# (#DAYSLEFT# days left).\n\n" +
+		"Please renew it before it expires, as transfers using it will then fail.\n"
+)
+
+//nolint:gochecknoglobals //default values cannot be constant
+var defaultThresholds = []uint{30, 7, 1}
+
+// Service is the service responsible for periodically checking the expiration
+// dates of the certificates & keys stored in the database, and for alerting
+// (via logs, SNMP notifications and emails) when one of the configured alert
+// thresholds is reached.
+//
+// The thresholds already notified are only kept in memory, so alerts are sent
+// again after a restart of the gateway.
+type Service struct {
+	DB *database.DB
+
+	logger *log.Logger
+	state  utils.State
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	notified map[string]uint // the last threshold notified, indexed by item
+}
+
+func (s *Service) Name() string { return ServiceName }
+
+func (s *Service) Start() error {
+	if s.state.IsRunning() {
+		return nil
+	}
+
+	s.logger = logging.NewLogger(ServiceName)
+
+	interval := s.DB.Config.Certificates.CheckInterval
+	if interval <= 0 {
+		s.logger.Info("Certificate expiry monitoring is disabled.")
+
+		return nil
+	}
+
+	var ctx context.Context
+
+	ctx, s.cancel = context.WithCancel(context.Background())
+	s.done = make(chan struct{})
+
+	go s.run(ctx, interval)
+
+	s.state.Set(utils.StateRunning, "")
+
+	return nil
+}
+
+func (s *Service) run(ctx context.Context, interval time.Duration) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.check(time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) Stop(ctx context.Context) error {
+	if !s.state.IsRunning() {
+		return utils.ErrNotRunning
+	}
+
+	s.cancel()
+
+	select {
+	case <-s.done:
+	case <-ctx.Done():
+		return fmt.Errorf("failed to stop the certificate monitor: %w", ctx.Err())
+	}
+
+	s.state.Set(utils.StateOffline, "")
+
+	return nil
+}
+
+func (s *Service) State() (utils.StateCode, string) { return s.state.Get() }
+
+// thresholds returns the configured alert thresholds (in days) in ascending
+// order.
+func (s *Service) thresholds() []uint {
+	thresholds := slices.Clone(s.DB.Config.Certificates.AlertThresholds)
+	if len(thresholds) == 0 {
+		thresholds = slices.Clone(defaultThresholds)
+	}
+
+	slices.Sort(thresholds)
+
+	return slices.Compact(thresholds)
+}
+
+// check sends an alert for each certificate which reached a new threshold
+// since the last check.
+func (s *Service) check(now time.Time) {
+	thresholds := s.thresholds()
+	maxThreshold := int(thresholds[len(thresholds)-1]) //nolint:gosec //thresholds are small
+
+	items, err := List(s.DB, now.AddDate(0, 0, maxThreshold))
+	if err != nil {
+		s.logger.Errorf("Failed to check the certificates' expiration dates: %v", err)
+
+		return
+	}
+
+	for _, item := range s.newAlerts(items, thresholds, now) {
+		s.alert(item, now)
+	}
+}
+
+// newAlerts returns the items of the given list which reached a threshold
+// which has not been notified yet.
+func (s *Service) newAlerts(items []*Item, thresholds []uint, now time.Time) []*Item {
+	var alerts []*Item
+
+	notified := make(map[string]uint, len(items))
+
+	for _, item := range items {
+		// A threshold of 0 means that the certificate has already expired.
+		reached := uint(0)
+
+		if item.NotAfter.After(now) {
+			for _, threshold := range thresholds {
+				if !item.NotAfter.After(now.AddDate(0, 0, int(threshold))) { //nolint:gosec //thresholds are small
+					reached = threshold
+
+					break
+				}
+			}
+		}
+
+		key := fmt.Sprintf("%s|%s|%s|%d", item.Kind, item.Owner, item.Name, item.NotAfter.Unix())
+		notified[key] = reached
+
+		if last, ok := s.notified[key]; !ok || reached < last {
+			alerts = append(alerts, item)
+		}
+	}
+
+	// Certificates which were renewed (or deleted) are forgotten.
+	s.notified = notified
+
+	return alerts
+}
+
+func (s *Service) alert(item *Item, now time.Time) {
+	daysLeft := item.DaysLeft(now)
+
+	if item.NotAfter.After(now) {
+		s.logger.Warningf("The %s (%s) expires on %s (%d days left)", item, item.Type,
+			item.NotAfter.Local().Format(time.DateTime), daysLeft)
+	} else {
+		s.logger.Errorf("The %s (%s) has expired on %s", item, item.Type,
+			item.NotAfter.Local().Format(time.DateTime))
+	}
+
+	snmp.ReportCertificateExpiry(item.Kind, item.Owner, item.Name, item.NotAfter, daysLeft)
+
+	if err := s.sendEmail(item, daysLeft); err != nil {
+		s.logger.Errorf("Failed to send the expiration alert email: %v", err)
+	}
+}
+
+// sendEmail sends the expiration alert of the given item by email, using the
+// SMTP credential and email template defined in the configuration.
+func (s *Service) sendEmail(item *Item, daysLeft int) error {
+	config := &s.DB.Config.Certificates
+	if config.EmailSender == "" || len(config.EmailRecipients) == 0 {
+		return nil
+	}
+
+	replacer := strings.NewReplacer(
+		"#LOCALHOST#", s.DB.Config.GatewayName,
+		"#CERTKIND#", item.Kind,
+		"#CERTNAME#", item.Name,
+		"#CERTTYPE#", item.Type,
+		"#CERTOWNER#", item.Owner,
+		"#CERTDESC#", item.String(),
+		"#EXPIRYDATE#", item.NotAfter.Local().Format(time.RFC3339),
+		"#DAYSLEFT#", strconv.Itoa(daysLeft),
+	)
+
+	params := map[string]string{
+		"sender":     config.EmailSender,
+		"recipients": strings.Join(config.EmailRecipients, ","),
+		"template":   config.EmailTemplate,
+	}
+
+	//nolint:wrapcheck //wrapping adds nothing here
+	return tasks.SendEmail(s.DB, s.logger, params, &model.EmailTemplate{
+		Subject:  defaultEmailSubject,
+		Body:     defaultEmailBody,
+		MIMEType: model.DefaultEmailMimeType,
+	}, replacer)
+}
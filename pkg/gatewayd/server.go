@@ -14,11 +14,15 @@ import (
 	"syscall"
 	"time"
 
+	"code.waarp.fr/apps/gateway/gateway/pkg/acme"
 	"code.waarp.fr/apps/gateway/gateway/pkg/admin"
 	"code.waarp.fr/apps/gateway/gateway/pkg/analytics"
+	_ "code.waarp.fr/apps/gateway/gateway/pkg/brokers/backends" // import message broker backends
 	"code.waarp.fr/apps/gateway/gateway/pkg/conf"
 	"code.waarp.fr/apps/gateway/gateway/pkg/controller"
 	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/expectedfile"
+	"code.waarp.fr/apps/gateway/gateway/pkg/expiry"
 	"code.waarp.fr/apps/gateway/gateway/pkg/filewatcher"
 	"code.waarp.fr/apps/gateway/gateway/pkg/fs"
 	_ "code.waarp.fr/apps/gateway/gateway/pkg/fs/backends" // import cloud backends
@@ -27,7 +31,11 @@ import (
 	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
 	"code.waarp.fr/apps/gateway/gateway/pkg/model"
 	"code.waarp.fr/apps/gateway/gateway/pkg/protocols"
+	"code.waarp.fr/apps/gateway/gateway/pkg/revocation"
+	"code.waarp.fr/apps/gateway/gateway/pkg/secrets"
+	"code.waarp.fr/apps/gateway/gateway/pkg/sla"
 	"code.waarp.fr/apps/gateway/gateway/pkg/snmp"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils"
 	"code.waarp.fr/apps/gateway/gateway/pkg/version"
 )
 
@@ -47,6 +55,10 @@ type WG struct {
 	SnmpService  *snmp.Service
 	Controller   *controller.Controller
 	Analytics    *analytics.Service
+	ACMEService  *acme.Service
+	CertMonitor  *expiry.Service
+	SLAMonitor   *sla.Service
+	FileMonitor  *expectedfile.Service
 }
 
 // NewWG creates a new application.
@@ -113,6 +125,10 @@ func (wg *WG) initServices() {
 	wg.Analytics = &analytics.Service{DB: wg.DBService}
 	wg.SnmpService = &snmp.Service{DB: wg.DBService}
 	wg.AdminService = &admin.Server{DB: wg.DBService}
+	wg.ACMEService = &acme.Service{DB: wg.DBService}
+	wg.CertMonitor = &expiry.Service{DB: wg.DBService}
+	wg.SLAMonitor = &sla.Service{DB: wg.DBService}
+	wg.FileMonitor = &expectedfile.Service{DB: wg.DBService}
 	wg.Controller = &controller.Controller{DB: wg.DBService}
 
 	snmp.GlobalService = wg.SnmpService
@@ -120,6 +136,14 @@ func (wg *WG) initServices() {
 }
 
 func (wg *WG) startServices() error {
+	if err := secrets.Configure(&wg.Config.Secrets); err != nil {
+		return fmt.Errorf("cannot configure the secret providers: %w", err)
+	}
+
+	if err := revocation.Configure(&wg.Config.Revocation); err != nil {
+		return fmt.Errorf("cannot configure the certificate revocation checks: %w", err)
+	}
+
 	if err := wg.DBService.Start(); err != nil {
 		return fmt.Errorf("cannot start database service: %w", err)
 	}
@@ -132,6 +156,22 @@ func (wg *WG) startServices() error {
 		return fmt.Errorf("cannot start SNMP service: %w", err)
 	}
 
+	if err := wg.ACMEService.Start(); err != nil {
+		return fmt.Errorf("cannot start ACME service: %w", err)
+	}
+
+	if err := wg.CertMonitor.Start(); err != nil {
+		return fmt.Errorf("cannot start certificate monitor service: %w", err)
+	}
+
+	if err := wg.SLAMonitor.Start(); err != nil {
+		return fmt.Errorf("cannot start SLA monitor service: %w", err)
+	}
+
+	if err := wg.FileMonitor.Start(); err != nil {
+		return fmt.Errorf("cannot start expected files monitor service: %w", err)
+	}
+
 	if err := wg.AdminService.Start(); err != nil {
 		return fmt.Errorf("cannot start admin service: %w", err)
 	}
@@ -149,6 +189,10 @@ func (wg *WG) startServices() error {
 	services.Core.Add(wg.AdminService)
 	services.Core.Add(wg.SnmpService)
 	services.Core.Add(wg.Analytics)
+	services.Core.Add(wg.ACMEService)
+	services.Core.Add(wg.CertMonitor)
+	services.Core.Add(wg.SLAMonitor)
+	services.Core.Add(wg.FileMonitor)
 
 	if err := wg.startServers(); err != nil {
 		return err
@@ -246,7 +290,13 @@ func (wg *WG) startClouds() error {
 	}
 
 	for _, c := range clouds {
-		fileSys, err := fs.NewFS(c.Name, c.Type, c.Key, c.Secret, c.Options)
+		secret, resErr := c.GetSecret()
+		if resErr != nil {
+			wg.Logger.Errorf("Failed to instantiate cloud instance %q: %v", c.Name, resErr)
+			continue
+		}
+
+		fileSys, err := fs.NewFS(c.Name, c.Type, c.Key, secret, c.Options)
 		if err != nil {
 			wg.Logger.Errorf("Failed to instantiate cloud instance %q: %v", c.Name, err)
 			continue
@@ -289,6 +339,22 @@ func (wg *WG) stopServices() {
 		wg.Logger.Warningf("an error occurred while stopping the admin service: %v", err)
 	}
 
+	if err := wg.ACMEService.Stop(ctx); err != nil && !errors.Is(err, utils.ErrNotRunning) {
+		wg.Logger.Warningf("an error occurred while stopping the ACME service: %v", err)
+	}
+
+	if err := wg.CertMonitor.Stop(ctx); err != nil && !errors.Is(err, utils.ErrNotRunning) {
+		wg.Logger.Warningf("an error occurred while stopping the certificate monitor service: %v", err)
+	}
+
+	if err := wg.SLAMonitor.Stop(ctx); err != nil && !errors.Is(err, utils.ErrNotRunning) {
+		wg.Logger.Warningf("an error occurred while stopping the SLA monitor service: %v", err)
+	}
+
+	if err := wg.FileMonitor.Stop(ctx); err != nil && !errors.Is(err, utils.ErrNotRunning) {
+		wg.Logger.Warningf("an error occurred while stopping the expected files monitor service: %v", err)
+	}
+
 	if err := wg.SnmpService.Stop(ctx); err != nil {
 		wg.Logger.Warningf("an error occurred while stopping the SNMP service: %v", err)
 	}
@@ -317,8 +383,16 @@ func (wg *WG) Start() error {
 
 mainloop:
 	for {
-		switch <-c {
-		case syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT:
+		select {
+		case sig := <-c:
+			switch sig {
+			case syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT:
+				wg.stopServices()
+
+				break mainloop
+			}
+		case <-services.ShutdownRequests():
+			wg.Logger.Info("Shutdown requested, stopping the gateway")
 			wg.stopServices()
 
 			break mainloop
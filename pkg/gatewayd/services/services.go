@@ -20,8 +20,23 @@ var (
 	Core    = serviceList{}
 	Clients = NewServiceMap[Client]()
 	Servers = NewServiceMap[Server]()
+
+	shutdownRequests = make(chan struct{}, 1)
 )
 
+// RequestShutdown asks the gateway to stop all its services and exit (for
+// example once the gateway has been drained).
+func RequestShutdown() {
+	select {
+	case shutdownRequests <- struct{}{}:
+	default: // a shutdown has already been requested
+	}
+}
+
+// ShutdownRequests returns the channel receiving the shutdown requests made
+// with RequestShutdown.
+func ShutdownRequests() <-chan struct{} { return shutdownRequests }
+
 type (
 	Service = protocol.StartStopper
 	Client  = protocol.Client
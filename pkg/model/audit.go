@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+)
+
+// AuditObjectTransfer is the AuditEntry object type of the transfers.
+const AuditObjectTransfer = "transfer"
+
+// AuditEntry is a record of a modification made to an object by a user of the
+// administration interface.
+type AuditEntry struct {
+	Identifier
+	Owner string    `gorm:"column:owner"`
+	Date  time.Time `gorm:"column:date;type:timestamp;serializer:timestamp"`
+
+	Username   string `gorm:"column:username"`    // The user who made the modification.
+	ObjectType string `gorm:"column:object_type"` // The type of the modified object (see AuditObjectTransfer).
+	ObjectID   int64  `gorm:"column:object_id"`   // The ID of the modified object.
+
+	// The modifications made to the object's fields.
+	Changes List[AuditChange] `gorm:"column:changes;serializer:json"`
+}
+
+// AuditChange is the modification of a single field of an audited object.
+type AuditChange struct {
+	Field    string `json:"field"`
+	OldValue any    `json:"oldValue"`
+	NewValue any    `json:"newValue"`
+}
+
+func (*AuditEntry) TableName() string   { return TableAuditLogs }
+func (*AuditEntry) Appellation() string { return NameAuditEntry }
+
+func (a *AuditEntry) BeforeWrite(database.Access) error {
+	if a.ObjectType == "" {
+		return database.NewValidationError("the audited object's type is missing")
+	}
+
+	if a.Date.IsZero() {
+		a.Date = time.Now()
+	}
+
+	if a.Changes == nil {
+		a.Changes = List[AuditChange]{}
+	}
+
+	return nil
+}
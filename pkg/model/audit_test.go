@@ -0,0 +1,45 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database/dbtest"
+)
+
+func TestAuditEntryTableName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equalf(t, TableAuditLogs, (&AuditEntry{}).TableName(),
+		"The audit logs table name should be equal to %q", TableAuditLogs)
+}
+
+func TestAuditEntryBeforeWrite(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+
+	t.Run("Valid entry", func(t *testing.T) {
+		entry := &AuditEntry{
+			Username:   "admin",
+			ObjectType: AuditObjectTransfer,
+			ObjectID:   1,
+			Changes:    List[AuditChange]{{Field: "source file", OldValue: "old", NewValue: "new"}},
+		}
+		require.NoError(t, db.Insert(entry).Run())
+
+		var check AuditEntry
+		require.NoError(t, db.Get(&check, "id=?", entry.ID).Run())
+
+		assert.Equal(t, db.Config.GatewayName, check.Owner)
+		assert.False(t, check.Date.IsZero(), "the date should have been set")
+		assert.Equal(t, entry.Changes, check.Changes)
+	})
+
+	t.Run("No object type", func(t *testing.T) {
+		entry := &AuditEntry{Username: "admin", ObjectID: 1}
+		assert.ErrorContains(t, db.Insert(entry).Run(), "the audited object's type is missing")
+	})
+}
@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/idna"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/authentication"
+)
+
+// ACMECertificate is the type of the credentials whose TLS certificate is
+// automatically obtained (and renewed) from an ACME server. The first value is
+// the comma-separated list of the certificate's domain names, and the second
+// value contains the issued certificate chain and private key (in PEM format).
+// The second value is managed by the gateway, and is empty until the first
+// certificate has been issued.
+const ACMECertificate = "acme"
+
+var (
+	ErrACMENotServer = errors.New("ACME certificates can only be used by servers")
+	ErrACMENoDomain  = errors.New("at least one domain name is required")
+)
+
+//nolint:gochecknoinits //init is used by design
+func init() {
+	authentication.AddExternalCredentialType(ACMECertificate, ACMECertHandler{})
+}
+
+type ACMECertHandler struct{}
+
+func (ACMECertHandler) CanOnlyHaveOne() bool { return false }
+
+func (ACMECertHandler) ToDB(db database.Access, domains, plainCert string,
+) (dbDomains, encryptedCert string, err error) {
+	if plainCert == "" {
+		return domains, "", nil
+	}
+
+	if encryptedCert, err = db.Encrypt(plainCert); err != nil {
+		return "", "", fmt.Errorf("failed to encrypt the certificate: %w", err)
+	}
+
+	return domains, encryptedCert, nil
+}
+
+func (ACMECertHandler) FromDB(db database.ReadAccess, domains, encryptedCert string,
+) (dbDomains, plainCert string, err error) {
+	if encryptedCert == "" {
+		return domains, "", nil
+	}
+
+	if plainCert, err = db.Decrypt(encryptedCert); err != nil {
+		return "", "", fmt.Errorf("failed to decrypt the certificate: %w", err)
+	}
+
+	return domains, plainCert, nil
+}
+
+func (ACMECertHandler) Validate(domains, cert, _, _ string, isServer bool) error {
+	if !isServer {
+		return ErrACMENotServer
+	}
+
+	names := ParseACMEDomains(domains)
+	if len(names) == 0 {
+		return ErrACMENoDomain
+	}
+
+	for _, name := range names {
+		if _, err := idna.Registration.ToASCII(name); err != nil {
+			return fmt.Errorf("invalid domain name %q: %w", name, err)
+		}
+	}
+
+	if cert != "" {
+		if _, err := ParseACMECertificate(cert); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ParseACMEDomains returns the list of domain names contained in the given
+// comma-separated list.
+func ParseACMEDomains(domains string) []string {
+	var names []string
+
+	for _, name := range strings.Split(domains, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// ParseACMECertificate parses the certificate chain and private key (in PEM
+// format) issued by the ACME server.
+func ParseACMECertificate(certAndKey string) (*tls.Certificate, error) {
+	cert, err := tls.X509KeyPair([]byte(certAndKey), []byte(certAndKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the ACME certificate: %w", err)
+	}
+
+	return &cert, nil
+}
@@ -74,13 +74,17 @@ func (TLSTrustedCertHandler) Authenticate(db database.ReadAccess,
 	owner authentication.Owner, val any,
 ) (*authentication.Result, error) {
 	doVerify := func(chain []*x509.Certificate) (*authentication.Result, error) {
-		rootCAs, rootErr := makeRootCAs(db, owner)
+		rootCAs, authorities, rootErr := makeRootCAs(db, owner)
 		if rootErr != nil {
 			return nil, rootErr
 		}
 
-		if err := verifyCertChain(chain, rootCAs, owner.Host(),
-			owner.IsServer()); err != nil {
+		chains, err := verifyCertChain(chain, rootCAs, owner.Host(), owner.IsServer())
+		if err != nil {
+			return authentication.Failure(err.Error()), nil
+		}
+
+		if err := CheckRevocation(chains, nil, authorities); err != nil {
 			return authentication.Failure(err.Error()), nil
 		}
 
@@ -217,13 +221,16 @@ func parseTLSCertChain(cert *tls.Certificate) ([]*x509.Certificate, error) {
 	return chain, nil
 }
 
-func makeRootCAs(db database.ReadAccess, owner authentication.Owner) (*x509.CertPool, error) {
+// makeRootCAs returns the pool of the certificates trusted by the given owner,
+// along with the TLS authorities valid for the owner's host.
+func makeRootCAs(db database.ReadAccess, owner authentication.Owner,
+) (*x509.CertPool, []*model.Authority, error) {
 	rootCAs := utils.TLSCertPool()
 
 	var trustedCert model.Credentials
 	if err := db.Select(&trustedCert).Where("type=?", TLSTrustedCertificate).
 		Where(owner.GetCredCond()).Run(); err != nil {
-		return nil, fmt.Errorf("failed to retrieve the trusted certificates: %w", err)
+		return nil, nil, fmt.Errorf("failed to retrieve the trusted certificates: %w", err)
 	}
 
 	for i := range trustedCert {
@@ -232,21 +239,24 @@ func makeRootCAs(db database.ReadAccess, owner authentication.Owner) (*x509.Cert
 
 	var trustedAuthorities model.Authorities
 	if err := db.Select(&trustedAuthorities).Where("type=?", AuthorityTLS).Run(); err != nil {
-		return nil, fmt.Errorf("failed to retrieve the TLS certification authorities: %w", err)
+		return nil, nil, fmt.Errorf("failed to retrieve the TLS certification authorities: %w", err)
 	}
 
+	var authorities []*model.Authority
+
 	for _, aut := range trustedAuthorities {
 		if len(aut.ValidHosts) == 0 || slices.Contains(aut.ValidHosts, owner.Host()) {
 			rootCAs.AppendCertsFromPEM([]byte(aut.PublicIdentity))
+			authorities = append(authorities, aut)
 		}
 	}
 
-	return rootCAs, nil
+	return rootCAs, authorities, nil
 }
 
 func verifyCertChain(certChain []*x509.Certificate, rootCAs *x509.CertPool,
 	host string, isServer bool,
-) error {
+) ([][]*x509.Certificate, error) {
 	options := x509.VerifyOptions{
 		DNSName:       host,
 		Roots:         rootCAs,
@@ -263,12 +273,13 @@ func verifyCertChain(certChain []*x509.Certificate, rootCAs *x509.CertPool,
 		options.Intermediates.AddCert(certChain[i])
 	}
 
-	if _, err := certChain[0].Verify(options); err != nil {
+	chains, err := certChain[0].Verify(options)
+	if err != nil {
 		//nolint:wrapcheck //wrapping here adds nothing
-		return err
+		return nil, err
 	}
 
-	return nil
+	return chains, nil
 }
 
 //nolint:err113 //dynamic errors are needed here
@@ -3,12 +3,14 @@ package auth
 import (
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 	"slices"
 
 	"code.waarp.fr/apps/gateway/gateway/pkg/database"
 	"code.waarp.fr/apps/gateway/gateway/pkg/model"
 	"code.waarp.fr/apps/gateway/gateway/pkg/model/authentication"
+	"code.waarp.fr/apps/gateway/gateway/pkg/revocation"
 	"code.waarp.fr/apps/gateway/gateway/pkg/utils"
 )
 
@@ -58,3 +60,60 @@ func AddTLSAuthorities(db database.ReadAccess, tlsConfig *tls.Config) error {
 
 	return nil
 }
+
+// CheckRevocation checks the revocation status of the given verified
+// certificate chains (as returned by x509.Certificate.Verify), using the
+// revocation settings of the TLS authority at the root of each chain (if
+// any). An OCSP response stapled by the peer can optionally be given. The
+// check succeeds if at least one of the chains is valid.
+func CheckRevocation(chains [][]*x509.Certificate, stapled []byte,
+	authorities []*model.Authority,
+) error {
+	var err error
+
+	for _, chain := range chains {
+		if err = revocation.CheckChain(chain, stapled, revocationSettings(chain,
+			authorities)); err == nil {
+			return nil
+		}
+	}
+
+	//nolint:wrapcheck //wrapping here adds nothing
+	return err
+}
+
+// revocationSettings returns the revocation settings of the first TLS
+// authority found in the given chain, or nil if the chain does not contain
+// any authority.
+func revocationSettings(chain []*x509.Certificate, authorities []*model.Authority,
+) *revocation.Settings {
+	for _, authority := range authorities {
+		if authority.Type != AuthorityTLS {
+			continue
+		}
+
+		var block *pem.Block
+
+		for rest := []byte(authority.PublicIdentity); ; {
+			if block, rest = pem.Decode(rest); block == nil {
+				break
+			}
+
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				continue
+			}
+
+			if slices.ContainsFunc(chain, cert.Equal) {
+				return &revocation.Settings{
+					Policy:    revocation.Policy(authority.RevocationPolicy),
+					CRLURL:    authority.CRLURL,
+					OCSPURL:   authority.OCSPURL,
+					Authority: cert,
+				}
+			}
+		}
+	}
+
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"code.waarp.fr/apps/gateway/gateway/pkg/database"
 	"code.waarp.fr/apps/gateway/gateway/pkg/model/authentication"
+	"code.waarp.fr/apps/gateway/gateway/pkg/revocation"
 )
 
 // Authority is the model representing an authentication authority. An authentication
@@ -16,6 +17,13 @@ type Authority struct {
 	Type           string   `gorm:"column:type"`
 	PublicIdentity string   `gorm:"column:public_identity"`
 	ValidHosts     []string `gorm:"-"`
+
+	// The revocation settings of the certificates issued by the authority. An
+	// empty policy means that the global policy applies, and empty URLs mean
+	// that the certificates' own distribution points are used.
+	RevocationPolicy string `gorm:"column:revocation_policy"`
+	CRLURL           string `gorm:"column:crl_url"`
+	OCSPURL          string `gorm:"column:ocsp_url"`
 }
 
 func (*Authority) TableName() string   { return TableAuthorities }
@@ -44,6 +52,10 @@ func (a *Authority) BeforeWrite(db database.Access) error {
 			"could not validate the authority's public identity value: %w", err)
 	}
 
+	if _, err := revocation.ParsePolicy(a.RevocationPolicy); err != nil {
+		return database.NewValidationError(err.Error())
+	}
+
 	if n, err := db.Count(a).Where("id<>? AND name=?", a.ID, a.Name).Run(); err != nil {
 		return fmt.Errorf("failed to check for duplicate authorities: %w", err)
 	} else if n != 0 {
@@ -88,6 +88,15 @@ func TestAuthorityBeforeWrite(t *testing.T) {
 					errInvalidAuthorityVal))
 			})
 		})
+
+		Convey("Given a new authority whose revocation policy is invalid", func() {
+			newAuthority.RevocationPolicy = "strict"
+
+			Convey("Then, calling the 'BeforeWrite' hook should return an error", func() {
+				So(newAuthority.BeforeWrite(db), ShouldBeError, `invalid revocation policy "strict" `+
+					`(valid policies are "none", "soft-fail" and "hard-fail")`)
+			})
+		})
 	})
 }
 
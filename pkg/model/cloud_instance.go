@@ -5,6 +5,7 @@ import (
 
 	"code.waarp.fr/apps/gateway/gateway/pkg/database"
 	"code.waarp.fr/apps/gateway/gateway/pkg/fs"
+	"code.waarp.fr/apps/gateway/gateway/pkg/secrets"
 )
 
 type CloudInstance struct {
@@ -31,7 +32,16 @@ func (c *CloudInstance) BeforeWrite(db database.Access) error {
 		return database.NewValidationError(`the name "file" is reserved for the local filesystem`)
 	}
 
-	if err := fs.ValidateConfig(c.Name, c.Type, c.Key, c.Secret, c.Options); err != nil {
+	if err := secrets.Validate(c.Secret); err != nil {
+		return database.NewValidationErrorf("invalid cloud instance secret: %v", err)
+	}
+
+	secret, resErr := c.GetSecret()
+	if resErr != nil {
+		return database.NewValidationErrorf("invalid cloud instance secret: %v", resErr)
+	}
+
+	if err := fs.ValidateConfig(c.Name, c.Type, c.Key, secret, c.Options); err != nil {
 		return database.NewValidationErrorf("invalid cloud instance configuration: %v", err)
 	}
 
@@ -44,8 +54,24 @@ func (c *CloudInstance) BeforeWrite(db database.Access) error {
 	return nil
 }
 
+// GetSecret returns the cloud instance's secret. If the secret is a secret
+// reference, the secret it refers to is returned instead.
+func (c *CloudInstance) GetSecret() (string, error) {
+	secret, err := secrets.Resolve(c.Secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve the cloud instance's secret: %w", err)
+	}
+
+	return secret, nil
+}
+
 func (c *CloudInstance) AfterInsert(database.Access) error {
-	fileSys, err := fs.NewFS(c.Name, c.Type, c.Key, c.Secret, c.Options)
+	secret, resErr := c.GetSecret()
+	if resErr != nil {
+		return database.NewValidationErrorf("invalid cloud instance configuration: %v", resErr)
+	}
+
+	fileSys, err := fs.NewFS(c.Name, c.Type, c.Key, secret, c.Options)
 	if err != nil {
 		return database.NewValidationErrorf("invalid cloud instance configuration: %v", err)
 	}
@@ -0,0 +1,84 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+)
+
+// DefaultNodeLease is the default duration of the leases held by the nodes of
+// a cluster, used when no lease duration is given in the configuration.
+const DefaultNodeLease = 30 * time.Second
+
+const (
+	NodeStatusAlive = "ALIVE"
+	NodeStatusDead  = "DEAD"
+)
+
+var (
+	ErrClusterNodeNoName    = database.NewValidationError("the node's name cannot be empty")
+	ErrClusterNodeDuplicate = database.NewValidationError("a node with the same name already exists")
+)
+
+// ClusterNode is an entry of the cluster's node registry. Each node of the
+// cluster periodically renews its entry (heartbeat). A node whose lease has
+// expired is considered dead, and the transfers it was executing are taken
+// over by the other nodes.
+type ClusterNode struct {
+	Identifier
+	Name          string    `gorm:"column:name"`
+	Hostname      string    `gorm:"column:hostname"`
+	StartedAt     time.Time `gorm:"column:started_at;type:timestamp;serializer:timestamp"`
+	LastHeartbeat time.Time `gorm:"column:last_heartbeat;type:timestamp;serializer:timestamp"`
+	LeaseExpiry   time.Time `gorm:"column:lease_expiry;type:timestamp;serializer:timestamp"`
+
+	// Services is the state of the node's services (core services, servers,
+	// clients and file watchers) as of the node's last heartbeat, indexed by
+	// service name.
+	Services Map[ServiceState] `gorm:"column:services;serializer:json"`
+}
+
+// ServiceState is the state of one of a cluster node's services, as reported
+// by the node during its last heartbeat.
+type ServiceState struct {
+	State  string `json:"state"`
+	Reason string `json:"reason,omitempty"`
+}
+
+func (*ClusterNode) TableName() string   { return TableClusterNodes }
+func (*ClusterNode) Appellation() string { return NameClusterNode }
+
+func (n *ClusterNode) BeforeWrite(db database.Access) error {
+	if n.Name == "" {
+		return ErrClusterNodeNoName
+	}
+
+	if count, err := db.Count(n).Where("id<>? AND name=?", n.ID, n.Name).Run(); err != nil {
+		return fmt.Errorf("failed to check for duplicate nodes: %w", err)
+	} else if count != 0 {
+		return ErrClusterNodeDuplicate
+	}
+
+	return nil
+}
+
+// Status returns the health status of the node: NodeStatusAlive if its
+// lease is still valid, or NodeStatusDead otherwise.
+func (n *ClusterNode) Status() string {
+	if time.Now().Before(n.LeaseExpiry) {
+		return NodeStatusAlive
+	}
+
+	return NodeStatusDead
+}
+
+// NodeLease returns the duration of the leases held by the cluster nodes, as
+// defined in the given database's configuration.
+func NodeLease(db *database.DB) time.Duration {
+	if db.Config.Controller.NodeLease <= 0 {
+		return DefaultNodeLease
+	}
+
+	return db.Config.Controller.NodeLease
+}
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database/dbtest"
+)
+
+func TestClusterNodeTableName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equalf(t, TableClusterNodes, (&ClusterNode{}).TableName(),
+		"The cluster nodes table name should be equal to %q", TableClusterNodes)
+}
+
+func TestClusterNodeBeforeWrite(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+	existing := &ClusterNode{Name: "existing"}
+	require.NoError(t, db.Insert(existing).Run())
+
+	for _, test := range []struct {
+		name    string
+		node    *ClusterNode
+		wantErr error
+	}{
+		{
+			name:    "Valid node",
+			node:    &ClusterNode{Name: "new"},
+			wantErr: nil,
+		}, {
+			name:    "No name",
+			node:    &ClusterNode{},
+			wantErr: ErrClusterNodeNoName,
+		}, {
+			name:    "Duplicate name",
+			node:    &ClusterNode{Name: "existing"},
+			wantErr: ErrClusterNodeDuplicate,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			if test.wantErr == nil {
+				require.NoError(t, test.node.BeforeWrite(db))
+			} else {
+				require.ErrorIs(t, test.node.BeforeWrite(db), test.wantErr)
+			}
+		})
+	}
+}
+
+func TestClusterNodeStatus(t *testing.T) {
+	t.Parallel()
+
+	alive := &ClusterNode{Name: "alive", LeaseExpiry: time.Now().Add(time.Minute)}
+	assert.Equal(t, NodeStatusAlive, alive.Status())
+
+	dead := &ClusterNode{Name: "dead", LeaseExpiry: time.Now().Add(-time.Minute)}
+	assert.Equal(t, NodeStatusDead, dead.Status())
+}
+
+func TestClusterNodeServices(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+	node := &ClusterNode{
+		Name: "node",
+		Services: Map[ServiceState]{
+			"service": {State: "Error", Reason: "some reason"},
+		},
+	}
+	require.NoError(t, db.Insert(node).Run())
+
+	var check ClusterNode
+	require.NoError(t, db.Get(&check, "id=?", node.ID).Run())
+	assert.Equal(t, node.Services, check.Services)
+}
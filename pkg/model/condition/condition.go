@@ -0,0 +1,464 @@
+// Package condition implements the conditions which can be attached to the
+// tasks of a rule, in order to only execute a task when the transfer matches
+// certain criteria.
+//
+// A condition is a boolean expression made of comparisons, which can be
+// combined using the "&&", "||" and "!" operators (and parentheses). A
+// comparison is made of 2 operands and an operator. An operand can either be:
+//   - one of the transfer's attributes (see Variables)
+//   - a string literal (between single or double quotes), which can contain
+//     substitution variables (like "#TI_xxx#"), replaced at evaluation time
+//   - an integer literal, optionally followed by a size unit (K, M, G or T)
+//
+// The available operators are:
+//   - "==" and "!=": equality (numerical if both operands are numbers)
+//   - "<", "<=", ">" and ">=": numerical comparison
+//   - "=~" and "!~": matches (or not) the regular expression on the right
+//   - "matches": matches the glob pattern on the right
+//
+// Example: filename matches "*.csv" && filesize > 10M
+package condition
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// The transfer attributes which can be used in a condition.
+const (
+	VarFilename  = "filename"  // The name of the transfer's local file.
+	VarFilepath  = "filepath"  // The full path of the transfer's local file.
+	VarFilesize  = "filesize"  // The size of the file (in bytes).
+	VarRule      = "rule"      // The name of the transfer's rule.
+	VarDirection = "direction" // The transfer's direction ("send" or "receive").
+	VarPartner   = "partner"   // The remote partner's name (or the client's login for server transfers).
+	VarAccount   = "account"   // The login of the account used for the transfer.
+	VarPrevious  = "previous"  // The outcome of the previous task of the chain.
+
+	// VarFinalAttempt indicates ("true" or "false") whether the transfer will
+	// not be attempted again after its error. Only meaningful in error-tasks.
+	VarFinalAttempt = "finalAttempt"
+)
+
+// The possible outcomes of a task (as given by the "previous" variable).
+const (
+	OutcomeNone    = "none" // The task is the first of the chain.
+	OutcomeSuccess = "success"
+	OutcomeWarning = "warning"
+	OutcomeError   = "error"
+	OutcomeSkipped = "skipped"
+)
+
+// Variables is the list of the transfer attributes which can be used in a
+// condition.
+//
+//nolint:gochecknoglobals //global var is needed here for the list of variables
+var Variables = []string{
+	VarFilename, VarFilepath, VarFilesize, VarRule, VarDirection,
+	VarPartner, VarAccount, VarPrevious, VarFinalAttempt,
+}
+
+//nolint:gochecknoglobals //global var is needed here for the units
+var sizeUnits = map[byte]int64{'k': 1 << 10, 'm': 1 << 20, 'g': 1 << 30, 't': 1 << 40}
+
+var (
+	ErrSyntax     = errors.New("invalid condition syntax")
+	ErrEvaluation = errors.New("failed to evaluate the condition")
+)
+
+// Env is the environment in which a condition is evaluated.
+type Env interface {
+	// Lookup returns the value of the given variable (either a string or an
+	// int64).
+	Lookup(name string) (any, bool)
+	// Expand replaces the substitution variables (#xxx#) contained in the
+	// given string literal.
+	Expand(literal string) (string, error)
+}
+
+// Condition is a parsed condition.
+type Condition struct {
+	source string
+	root   node
+}
+
+// Parse parses the given condition. An empty condition is valid, and always
+// evaluates to true.
+func Parse(source string) (*Condition, error) {
+	cond := &Condition{source: source}
+
+	if strings.TrimSpace(source) == "" {
+		return cond, nil
+	}
+
+	toks, err := lex(source)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSyntax, err)
+	}
+
+	p := &parser{toks: toks}
+
+	if cond.root, err = p.parseOr(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSyntax, err)
+	}
+
+	if !p.done() {
+		return nil, fmt.Errorf("%w: unexpected %q", ErrSyntax, p.peek().text)
+	}
+
+	return cond, nil
+}
+
+func (c *Condition) String() string { return c.source }
+
+// Eval evaluates the condition in the given environment.
+func (c *Condition) Eval(env Env) (bool, error) {
+	if c.root == nil {
+		return true, nil
+	}
+
+	res, err := c.root.eval(env)
+	if err != nil {
+		return false, fmt.Errorf("%w %q: %w", ErrEvaluation, c.source, err)
+	}
+
+	return res, nil
+}
+
+type node interface {
+	eval(env Env) (bool, error)
+}
+
+type orNode struct{ left, right node }
+
+func (n *orNode) eval(env Env) (bool, error) {
+	if res, err := n.left.eval(env); err != nil || res {
+		return res, err
+	}
+
+	return n.right.eval(env)
+}
+
+type andNode struct{ left, right node }
+
+func (n *andNode) eval(env Env) (bool, error) {
+	if res, err := n.left.eval(env); err != nil || !res {
+		return res, err
+	}
+
+	return n.right.eval(env)
+}
+
+type notNode struct{ operand node }
+
+func (n *notNode) eval(env Env) (bool, error) {
+	res, err := n.operand.eval(env)
+
+	return !res, err
+}
+
+type operand struct {
+	kind tokenKind // tokIdent, tokString or tokNumber
+	text string
+	num  int64
+}
+
+// value returns the operand's value, either as a string or as a number.
+func (o *operand) value(env Env) (str string, num int64, isNum bool, err error) {
+	switch o.kind {
+	case tokNumber:
+		return o.text, o.num, true, nil
+	case tokString:
+		if !strings.Contains(o.text, "#") {
+			return o.text, 0, false, nil
+		}
+
+		str, err = env.Expand(o.text)
+		if err != nil {
+			return "", 0, false, fmt.Errorf("failed to expand %q: %w", o.text, err)
+		}
+
+		return str, 0, false, nil
+	default:
+		val, ok := env.Lookup(o.text)
+		if !ok {
+			return "", 0, false, fmt.Errorf("unknown variable %q", o.text) //nolint:err113 //dynamic error
+		}
+
+		switch v := val.(type) {
+		case int64:
+			return strconv.FormatInt(v, 10), v, true, nil
+		default:
+			return fmt.Sprint(v), 0, false, nil
+		}
+	}
+}
+
+// number returns the operand's value as a number.
+func (o *operand) number(env Env) (int64, error) {
+	str, num, isNum, err := o.value(env)
+	if err != nil || isNum {
+		return num, err
+	}
+
+	if num, err = parseNumber(str); err != nil {
+		return 0, fmt.Errorf("%q is not a number", str) //nolint:err113 //dynamic error
+	}
+
+	return num, nil
+}
+
+type comparison struct {
+	left, right *operand
+	op          string
+	pattern     *regexp.Regexp // precompiled regexp (if the pattern is constant)
+}
+
+//nolint:gocyclo,cyclop //splitting the function would hurt readability
+func (n *comparison) eval(env Env) (bool, error) {
+	switch n.op {
+	case "<", "<=", ">", ">=":
+		left, err := n.left.number(env)
+		if err != nil {
+			return false, err
+		}
+
+		right, err := n.right.number(env)
+		if err != nil {
+			return false, err
+		}
+
+		switch n.op {
+		case "<":
+			return left < right, nil
+		case "<=":
+			return left <= right, nil
+		case ">":
+			return left > right, nil
+		default:
+			return left >= right, nil
+		}
+	}
+
+	left, leftNum, leftIsNum, err := n.left.value(env)
+	if err != nil {
+		return false, err
+	}
+
+	right, rightNum, rightIsNum, err := n.right.value(env)
+	if err != nil {
+		return false, err
+	}
+
+	switch n.op {
+	case "==", "!=":
+		equal := left == right
+
+		if leftIsNum || rightIsNum {
+			if leftNum, err = parseNumberIf(left, leftNum, leftIsNum); err == nil {
+				if rightNum, err = parseNumberIf(right, rightNum, rightIsNum); err == nil {
+					equal = leftNum == rightNum
+				}
+			}
+		}
+
+		return equal == (n.op == "=="), nil
+	case "=~", "!~":
+		reg := n.pattern
+		if reg == nil {
+			if reg, err = regexp.Compile(right); err != nil {
+				return false, fmt.Errorf("invalid regular expression %q: %w", right, err)
+			}
+		}
+
+		return reg.MatchString(left) == (n.op == "=~"), nil
+	default: // matches
+		ok, matchErr := path.Match(right, left)
+		if matchErr != nil {
+			return false, fmt.Errorf("invalid pattern %q: %w", right, matchErr)
+		}
+
+		return ok, nil
+	}
+}
+
+func parseNumberIf(str string, num int64, isNum bool) (int64, error) {
+	if isNum {
+		return num, nil
+	}
+
+	return parseNumber(str)
+}
+
+// parseNumber parses an integer, optionally followed by a size unit (K, M, G
+// or T, which are powers of 1024).
+func parseNumber(str string) (int64, error) {
+	multiplier := int64(1)
+
+	if n := len(str); n > 1 {
+		if factor, ok := sizeUnits[str[n-1]|0x20]; ok { // lowercase
+			multiplier = factor
+			str = str[:n-1]
+		}
+	}
+
+	num, err := strconv.ParseInt(str, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number: %w", err)
+	}
+
+	return num * multiplier, nil
+}
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func (p *parser) done() bool   { return p.pos >= len(p.toks) }
+func (p *parser) peek() *token { return &p.toks[p.pos] }
+
+func (p *parser) accept(texts ...string) (string, bool) {
+	if p.done() {
+		return "", false
+	}
+
+	tok := p.peek()
+	if tok.kind == tokOperator && slices.Contains(texts, tok.text) ||
+		tok.kind == tokIdent && slices.Contains(texts, tok.text) {
+		p.pos++
+
+		return tok.text, true
+	}
+
+	return "", false
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if _, ok := p.accept("||"); !ok {
+			return left, nil
+		}
+
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = &orNode{left: left, right: right}
+	}
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if _, ok := p.accept("&&"); !ok {
+			return left, nil
+		}
+
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		left = &andNode{left: left, right: right}
+	}
+}
+
+func (p *parser) parseNot() (node, error) {
+	if _, ok := p.accept("!"); ok {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		return &notNode{operand: operand}, nil
+	}
+
+	if _, ok := p.accept("("); ok {
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := p.accept(")"); !ok {
+			return nil, errors.New(`missing ")"`) //nolint:err113 //base error
+		}
+
+		return expr, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	op, ok := p.accept("==", "!=", "<", "<=", ">", ">=", "=~", "!~", "matches")
+	if !ok {
+		return nil, fmt.Errorf("expected a comparison operator after %q", left.text) //nolint:err113 //dynamic error
+	}
+
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	cmp := &comparison{left: left, right: right, op: op}
+
+	// Constant patterns are checked (and compiled) beforehand.
+	if right.kind != tokIdent && !strings.Contains(right.text, "#") {
+		switch op {
+		case "=~", "!~":
+			if cmp.pattern, err = regexp.Compile(right.text); err != nil {
+				return nil, fmt.Errorf("invalid regular expression %q: %w", right.text, err)
+			}
+		case "matches":
+			if _, err = path.Match(right.text, ""); err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", right.text, err)
+			}
+		}
+	}
+
+	return cmp, nil
+}
+
+func (p *parser) parseOperand() (*operand, error) {
+	if p.done() {
+		return nil, errors.New("unexpected end of condition") //nolint:err113 //base error
+	}
+
+	tok := p.peek()
+
+	switch tok.kind {
+	case tokIdent:
+		if !slices.Contains(Variables, tok.text) {
+			return nil, fmt.Errorf("unknown variable %q (valid variables are: %s)", //nolint:err113 //dynamic error
+				tok.text, strings.Join(Variables, ", "))
+		}
+	case tokString, tokNumber:
+	default:
+		return nil, fmt.Errorf("unexpected %q", tok.text) //nolint:err113 //dynamic error
+	}
+
+	p.pos++
+
+	return &operand{kind: tok.kind, text: tok.text, num: tok.num}, nil
+}
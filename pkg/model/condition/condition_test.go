@@ -0,0 +1,101 @@
+package condition
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testEnv map[string]any
+
+func (e testEnv) Lookup(name string) (any, bool) {
+	val, ok := e[name]
+
+	return val, ok
+}
+
+func (e testEnv) Expand(literal string) (string, error) {
+	return strings.NewReplacer("#TI_type#", "invoice", "#TI_count#", "12").Replace(literal), nil
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	for _, valid := range []string{
+		"",
+		`filename matches "*.csv"`,
+		`filesize > 10M && (partner == 'waarp' || !(account != "toto"))`,
+		`filename =~ "^INV_[0-9]+\\.xml$" || previous == "error"`,
+		`"#TI_type#" == "invoice"`,
+	} {
+		_, err := Parse(valid)
+		require.NoErrorf(t, err, "condition %q should be valid", valid)
+	}
+
+	for _, invalid := range []string{
+		`filename`,
+		`filename ==`,
+		`unknown == "foo"`,
+		`filename == "foo`,
+		`(filename == "foo"`,
+		`filename == "foo" bar`,
+		`filename =~ "(["`,
+		`filename matches "[a-"`,
+		`filesize > 10X`,
+		`filename = "foo"`,
+	} {
+		_, err := Parse(invalid)
+		require.ErrorIsf(t, err, ErrSyntax, "condition %q should be invalid", invalid)
+	}
+}
+
+func TestEval(t *testing.T) {
+	t.Parallel()
+
+	env := testEnv{
+		VarFilename:     "INV_001.xml",
+		VarFilesize:     int64(20 * 1024 * 1024),
+		VarPartner:      "waarp",
+		VarAccount:      "toto",
+		VarPrevious:     OutcomeError,
+		VarFinalAttempt: "true",
+	}
+
+	for cond, expected := range map[string]bool{
+		"":                                        true,
+		`filename matches "*.xml"`:                true,
+		`filename matches "*.csv"`:                false,
+		`filename =~ "^INV_[0-9]+\\.xml$"`:        true,
+		`filename !~ "^INV_"`:                     false,
+		`filesize > 10M`:                          true,
+		`filesize <= 1G && filesize >= 20971520`:  true,
+		`filesize == 20M`:                         true,
+		`partner == 'waarp' && account != "toto"`: false,
+		`partner == 'waarp' || account != "toto"`: true,
+		`!(partner == "waarp")`:                   false,
+		`previous == "error"`:                     true,
+		`finalAttempt == "true"`:                  true,
+		`"#TI_type#" == "invoice"`:                true,
+		`"#TI_count#" > 10`:                       true,
+		`'it\'s' == "it's"`:                       true,
+	} {
+		parsed, err := Parse(cond)
+		require.NoError(t, err)
+
+		res, err := parsed.Eval(env)
+		require.NoError(t, err)
+		assert.Equalf(t, expected, res, "condition %q", cond)
+	}
+
+	t.Run("Given a non-numerical value compared numerically", func(t *testing.T) {
+		t.Parallel()
+
+		parsed, err := Parse(`partner > 10`)
+		require.NoError(t, err)
+
+		_, err = parsed.Eval(env)
+		require.ErrorIs(t, err, ErrEvaluation)
+	})
+}
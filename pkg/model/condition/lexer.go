@@ -0,0 +1,115 @@
+package condition
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokIdent tokenKind = iota
+	tokString
+	tokNumber
+	tokOperator
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	num  int64
+}
+
+//nolint:gochecknoglobals //global var is needed here for the operators
+var operators = []string{
+	// 2-characters operators must come first
+	"==", "!=", "<=", ">=", "=~", "!~", "&&", "||",
+	"<", ">", "!", "(", ")",
+}
+
+func lex(source string) ([]token, error) {
+	var toks []token
+
+	for rest := []rune(source); len(rest) > 0; {
+		char := rest[0]
+
+		switch {
+		case unicode.IsSpace(char):
+			rest = rest[1:]
+		case char == '"' || char == '\'':
+			str, n, err := lexString(rest)
+			if err != nil {
+				return nil, err
+			}
+
+			toks = append(toks, token{kind: tokString, text: str})
+			rest = rest[n:]
+		case unicode.IsDigit(char):
+			n := 1
+			for n < len(rest) && (unicode.IsDigit(rest[n]) || unicode.IsLetter(rest[n])) {
+				n++
+			}
+
+			num, err := parseNumber(string(rest[:n]))
+			if err != nil {
+				return nil, fmt.Errorf("%q: %w", string(rest[:n]), err)
+			}
+
+			toks = append(toks, token{kind: tokNumber, text: string(rest[:n]), num: num})
+			rest = rest[n:]
+		case unicode.IsLetter(char) || char == '_':
+			n := 1
+			for n < len(rest) && (unicode.IsLetter(rest[n]) || unicode.IsDigit(rest[n]) ||
+				rest[n] == '_') {
+				n++
+			}
+
+			toks = append(toks, token{kind: tokIdent, text: string(rest[:n])})
+			rest = rest[n:]
+		default:
+			op := lexOperator(string(rest))
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q", char) //nolint:err113 //dynamic error
+			}
+
+			toks = append(toks, token{kind: tokOperator, text: op})
+			rest = rest[len([]rune(op)):]
+		}
+	}
+
+	return toks, nil
+}
+
+func lexOperator(rest string) string {
+	for _, op := range operators {
+		if strings.HasPrefix(rest, op) {
+			return op
+		}
+	}
+
+	return ""
+}
+
+// lexString parses the quoted string at the start of the given input, and
+// returns its unquoted value along with the number of runes consumed.
+// Backslashes can be used to escape the quote character and backslashes.
+func lexString(rest []rune) (string, int, error) {
+	quote := rest[0]
+
+	var str strings.Builder
+
+	for i := 1; i < len(rest); i++ {
+		switch char := rest[i]; {
+		case char == '\\' && i+1 < len(rest) && (rest[i+1] == quote || rest[i+1] == '\\'):
+			str.WriteRune(rest[i+1])
+			i++
+		case char == quote:
+			return str.String(), i + 1, nil
+		default:
+			str.WriteRune(char)
+		}
+	}
+
+	return "", 0, fmt.Errorf("unterminated string %s", string(rest)) //nolint:err113 //dynamic error
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/tk/copybook"
+)
+
+var (
+	ErrCopybookNoName    = database.NewValidationError("the copybook's name cannot be empty")
+	ErrCopybookNoContent = database.NewValidationError("the copybook's content cannot be empty")
+	ErrCopybookInvalid   = database.NewValidationError("the copybook is invalid")
+	ErrCopybookDuplicate = database.NewValidationError("a copybook with the same name already exists")
+)
+
+// Copybook is a COBOL copybook, describing the layout of mainframe records.
+// Copybooks are used by the RECFORMAT task.
+type Copybook struct {
+	Identifier
+	Name    string `gorm:"column:name"`
+	Content string `gorm:"column:content"`
+}
+
+func (*Copybook) TableName() string   { return TableCopybooks }
+func (*Copybook) Appellation() string { return NameCopybook }
+
+func (c *Copybook) TextFields() (name, content *string) { return &c.Name, &c.Content }
+
+func (c *Copybook) BeforeWrite(db database.Access) error {
+	return checkStoredText(db, c, &storedTextErrors{
+		noName:    ErrCopybookNoName,
+		noContent: ErrCopybookNoContent,
+		invalid:   ErrCopybookInvalid,
+		duplicate: ErrCopybookDuplicate,
+	}, func(_, content string) error {
+		_, err := copybook.Parse(content)
+
+		return err //nolint:wrapcheck //wrapped by checkStoredText
+	})
+}
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database/dbtest"
+)
+
+func TestCopybookTableName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equalf(t, TableCopybooks, (&Copybook{}).TableName(),
+		"The copybooks table name should be equal to %q", TableCopybooks)
+}
+
+func TestCopybookBeforeWrite(t *testing.T) {
+	t.Parallel()
+
+	const content = "01 REC.\n   05 ID PIC 9(5).\n   05 NAME PIC X(20).\n"
+
+	db := dbtest.TestDatabase(t)
+	existing := &Copybook{Name: "existing", Content: content}
+	require.NoError(t, db.Insert(existing).Run())
+
+	for _, test := range []struct {
+		name     string
+		copybook *Copybook
+		wantErr  error
+	}{
+		{
+			name:     "Valid copybook",
+			copybook: &Copybook{Name: "new", Content: content},
+			wantErr:  nil,
+		}, {
+			name:     "No name",
+			copybook: &Copybook{Content: content},
+			wantErr:  ErrCopybookNoName,
+		}, {
+			name:     "No content",
+			copybook: &Copybook{Name: "test"},
+			wantErr:  ErrCopybookNoContent,
+		}, {
+			name:     "Invalid copybook",
+			copybook: &Copybook{Name: "test", Content: "01 REC.\n   05 ID PIC 9(0).\n"},
+			wantErr:  ErrCopybookInvalid,
+		}, {
+			name:     "Duplicate name",
+			copybook: &Copybook{Name: "existing", Content: content},
+			wantErr:  ErrCopybookDuplicate,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			if test.wantErr == nil {
+				require.NoError(t, test.copybook.BeforeWrite(db))
+			} else {
+				require.ErrorIs(t, test.copybook.BeforeWrite(db), test.wantErr)
+			}
+		})
+	}
+}
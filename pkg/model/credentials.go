@@ -6,6 +6,7 @@ import (
 
 	"code.waarp.fr/apps/gateway/gateway/pkg/database"
 	"code.waarp.fr/apps/gateway/gateway/pkg/model/authentication"
+	"code.waarp.fr/apps/gateway/gateway/pkg/secrets"
 )
 
 // CredOwnerTable is the interface implemented by all valid Credential owner table types.
@@ -161,8 +162,8 @@ func (c *Credential) validate(db database.Access) error {
 		}
 	}
 
-	if err := handler.Validate(c.Value, c.Value2, "", owner.Host(), owner.IsServer()); err != nil {
-		return database.NewValidationErrorf("failed to validate authentication value: %w", err)
+	if err := c.validateValues(handler, owner); err != nil {
+		return err
 	}
 
 	if ser, ok := handler.(authentication.Serializer); ok {
@@ -175,6 +176,62 @@ func (c *Credential) validate(db database.Access) error {
 	return nil
 }
 
+// validateValues checks the credential's values using the given handler. If the
+// values are secret references, the secrets they refer to are validated instead.
+// References are only allowed for the gateway's own credentials, since the
+// credentials used to authenticate partners must be hashed.
+func (c *Credential) validateValues(handler authentication.Handler, owner CredOwnerTable) error {
+	value, value2 := c.Value, c.Value2
+
+	if secrets.IsReference(value) || secrets.IsReference(value2) {
+		if c.RemoteAgentID.Valid || c.LocalAccountID.Valid {
+			return database.NewValidationError(
+				"secret references can only be used for the gateway's own credentials")
+		}
+
+		if err := secrets.Validate(value); err != nil {
+			return database.NewValidationErrorf("invalid authentication value: %w", err)
+		}
+
+		if err := secrets.Validate(value2); err != nil {
+			return database.NewValidationErrorf("invalid secondary authentication value: %w", err)
+		}
+
+		if err := secrets.ResolveAll(&value, &value2); err != nil {
+			return database.NewValidationErrorf("failed to resolve authentication value: %w", err)
+		}
+	}
+
+	if err := handler.Validate(value, value2, "", owner.Host(), owner.IsServer()); err != nil {
+		return database.NewValidationErrorf("failed to validate authentication value: %w", err)
+	}
+
+	return nil
+}
+
+// ResolveSecrets replaces the credential's values with the secrets they refer
+// to when they are secret references. This should only be called on credentials
+// which are about to be used, never on credentials which are about to be
+// written or exported.
+func (c *Credential) ResolveSecrets() error {
+	if err := secrets.ResolveAll(&c.Value, &c.Value2); err != nil {
+		return fmt.Errorf("failed to resolve the %q credential: %w", c.Name, err)
+	}
+
+	return nil
+}
+
+// ResolveCredentialsSecrets calls ResolveSecrets on all the given credentials.
+func ResolveCredentialsSecrets(creds Credentials) error {
+	for _, cred := range creds {
+		if err := cred.ResolveSecrets(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (c *Credential) AfterInsert(db database.Access) error {
 	return c.AfterRead(db)
 }
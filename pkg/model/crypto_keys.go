@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/secrets"
 )
 
 type CryptoKey struct {
@@ -36,5 +37,32 @@ func (k *CryptoKey) BeforeWrite(db database.Access) error {
 		return database.NewValidationErrorf("a cryptographic key named %q already exists", k.Name)
 	}
 
+	if secrets.IsReference(k.Key) {
+		if err := secrets.Validate(k.Key); err != nil {
+			return database.NewValidationErrorf("invalid cryptographic key value: %w", err)
+		}
+
+		resolved := *k
+		if err := resolved.ResolveSecrets(); err != nil {
+			return database.NewValidationError(err.Error())
+		}
+
+		return resolved.checkKey()
+	}
+
 	return k.checkKey()
 }
+
+// ResolveSecrets replaces the key's value with the secret it refers to when it
+// is a secret reference. This should only be called on keys which are about to
+// be used, never on keys which are about to be written or exported.
+func (k *CryptoKey) ResolveSecrets() error {
+	key, err := secrets.Resolve(k.Key)
+	if err != nil {
+		return fmt.Errorf("failed to resolve the cryptographic key %q: %w", k.Name, err)
+	}
+
+	k.Key = key
+
+	return nil
+}
@@ -2,11 +2,14 @@ package model
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
 	"code.waarp.fr/apps/gateway/gateway/pkg/database/dbtest"
+	"code.waarp.fr/apps/gateway/gateway/pkg/secrets"
 	"code.waarp.fr/apps/gateway/gateway/pkg/utils/testhelpers"
 )
 
@@ -65,6 +68,36 @@ func TestCryptoKeyBeforeWrite(t *testing.T) {
 		require.NoError(t, key.BeforeWrite(db))
 	})
 
+	t.Run("AES key reference", func(t *testing.T) {
+		t.Parallel()
+
+		keyFile := filepath.Join(t.TempDir(), "aes.key")
+		require.NoError(t, os.WriteFile(keyFile, []byte("0123456789abcdefhijklABCDEFHIJKL"), 0o600))
+
+		key := &CryptoKey{
+			Name: "aes-ref-key",
+			Type: CryptoKeyTypeAES,
+			Key:  "ref+file://" + filepath.ToSlash(keyFile),
+		}
+		require.NoError(t, key.BeforeWrite(db))
+
+		require.NoError(t, os.WriteFile(keyFile, []byte("too short"), 0o600))
+		secrets.Flush()
+		require.ErrorContains(t, key.BeforeWrite(db),
+			"AES keys must be 16, 24, or 32 bytes long")
+	})
+
+	t.Run("Unknown reference provider", func(t *testing.T) {
+		t.Parallel()
+
+		key := &CryptoKey{
+			Name: "aes-ref-key",
+			Type: CryptoKeyTypeAES,
+			Key:  "ref+unknown://aes.key",
+		}
+		require.ErrorContains(t, key.BeforeWrite(db), "unknown secret provider")
+	})
+
 	t.Run("No name", func(t *testing.T) {
 		t.Parallel()
 
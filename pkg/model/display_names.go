@@ -22,6 +22,17 @@ const (
 	NameEmailTemplate          = "email template"
 	NameSMTPCredential         = "smtp credential"
 	NameFileWatcher            = "filewatcher"
+	NameMessageBroker          = "message broker"
+	NameScript                 = "script"
+	NameCopybook               = "copybook"
+	NameRunningTransfer        = "running transfer"
+	NameClusterNode            = "cluster node"
+	NameRetryPolicy            = "retry policy"
+	NameSLA                    = "SLA"
+	NameSLABreach              = "SLA breach"
+	NameExpectedFile           = "expected file"
+	NameExpectedFileCheck      = "expected file check"
+	NameAuditEntry             = "audit entry"
 )
 
 const authPassword = "password"
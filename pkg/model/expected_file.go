@@ -0,0 +1,246 @@
+package model
+
+import (
+	"database/sql"
+	"fmt"
+	"path"
+	"time"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+)
+
+// ExpectedFileWindowFormat is the format of the expected files' window bounds
+// (a time of day).
+const ExpectedFileWindowFormat = "15:04"
+
+// emailTaskType is the type of the task used to send the expected files'
+// email alerts.
+const emailTaskType = "EMAIL"
+
+var (
+	ErrExpectedFileNoName    = database.NewValidationError("the expected file's name cannot be empty")
+	ErrExpectedFileDuplicate = database.NewValidationError("an expected file with the same name already exists")
+	ErrExpectedFileNoRule    = database.NewValidationError("the expected file's rule is missing")
+	ErrExpectedFileInvalid   = database.NewValidationError("the expected file is invalid")
+)
+
+// ExpectedFileStatus is the outcome of the check of an expected file's window.
+type ExpectedFileStatus string
+
+const (
+	// ExpectedFileReceived means that the file was received exactly once
+	// during the window.
+	ExpectedFileReceived ExpectedFileStatus = "received"
+	// ExpectedFileMissing means that the file was not received during the
+	// window.
+	ExpectedFileMissing ExpectedFileStatus = "missing"
+	// ExpectedFileDuplicate means that the file was received more than once
+	// during the window.
+	ExpectedFileDuplicate ExpectedFileStatus = "duplicate"
+)
+
+// ExpectedFile declares an inbound file which is expected to be received once,
+// with a given rule, during a daily time window. The expected files are
+// checked by the expected files monitor at the end of each window, which
+// records an ExpectedFileCheck (and sends an alert if the file is missing or
+// was received more than once).
+type ExpectedFile struct {
+	Identifier
+	Owner    string `gorm:"column:owner"`
+	Name     string `gorm:"column:name"`
+	Disabled bool   `gorm:"column:disabled"`
+
+	// The receive rule with which the file is expected.
+	RuleID int64 `gorm:"column:rule_id"`
+	// The partner from which the file is retrieved (client transfers), or the
+	// local account with which the file is sent (server transfers). Both are
+	// optional, but cannot be set at the same time.
+	RemoteAgentID  sql.NullInt64 `gorm:"column:remote_agent_id"`
+	LocalAccountID sql.NullInt64 `gorm:"column:local_account_id"`
+
+	Pattern     string       `gorm:"column:pattern"`                     // The pattern which the file's name must match.
+	WindowStart string       `gorm:"column:window_start"`                // The time of day (local time, "HH:MM") at which the window opens.
+	WindowEnd   string       `gorm:"column:window_end"`                  // The time of day (local time, "HH:MM") at which the window closes.
+	Days        List[string] `gorm:"column:days;serializer:json"`        // The days of the week on which the window opens.
+	EmailAlert  Map[string]  `gorm:"column:email_alert;serializer:json"` // The parameters of the EMAIL task used to send the alerts (if any).
+
+	Created time.Time `gorm:"column:created;type:timestamp;serializer:timestamp"`
+}
+
+func (*ExpectedFile) TableName() string   { return TableExpectedFiles }
+func (*ExpectedFile) Appellation() string { return NameExpectedFile }
+
+//nolint:cyclop //splitting the function would hurt readability
+func (e *ExpectedFile) BeforeWrite(db database.Access) error {
+	if e.Name == "" {
+		return ErrExpectedFileNoName
+	}
+
+	if e.RuleID == 0 {
+		return ErrExpectedFileNoRule
+	}
+
+	if e.RemoteAgentID.Valid && e.LocalAccountID.Valid {
+		return fmt.Errorf("%w: the file cannot be expected from both a partner and a local account",
+			ErrExpectedFileInvalid)
+	}
+
+	if e.Pattern == "" {
+		e.Pattern = FilewatcherDefaultPattern
+	}
+
+	if _, err := path.Match(e.Pattern, ""); err != nil {
+		return fmt.Errorf("%w: invalid file pattern %q", ErrExpectedFileInvalid, e.Pattern)
+	}
+
+	if err := e.checkWindow(); err != nil {
+		return err
+	}
+
+	if e.Created.IsZero() {
+		e.Created = time.Now()
+	}
+
+	var rule Rule
+	if err := db.Get(&rule, "id=?", e.RuleID).Run(); database.IsNotFound(err) {
+		return database.NewValidationErrorf("no rule found with ID %d", e.RuleID)
+	} else if err != nil {
+		return fmt.Errorf("failed to check the expected file's rule: %w", err)
+	} else if rule.IsSend {
+		return fmt.Errorf("%w: the rule must be a receive rule", ErrExpectedFileInvalid)
+	}
+
+	if e.RemoteAgentID.Valid {
+		if n, err := db.Count(&RemoteAgent{}).Where("id=?", e.RemoteAgentID.Int64).Run(); err != nil {
+			return fmt.Errorf("failed to check the expected file's partner: %w", err)
+		} else if n == 0 {
+			return database.NewValidationErrorf("no partner found with ID %d", e.RemoteAgentID.Int64)
+		}
+	}
+
+	if e.LocalAccountID.Valid {
+		if n, err := db.Count(&LocalAccount{}).Where("id=?", e.LocalAccountID.Int64).Run(); err != nil {
+			return fmt.Errorf("failed to check the expected file's local account: %w", err)
+		} else if n == 0 {
+			return database.NewValidationErrorf("no local account found with ID %d", e.LocalAccountID.Int64)
+		}
+	}
+
+	if err := e.checkEmailAlert(db); err != nil {
+		return err
+	}
+
+	if n, err := db.Count(e).Where("id<>? AND name=?", e.ID, e.Name).Run(); err != nil {
+		return fmt.Errorf("failed to check for duplicate expected files: %w", err)
+	} else if n != 0 {
+		return fmt.Errorf("%w: %q", ErrExpectedFileDuplicate, e.Name)
+	}
+
+	return nil
+}
+
+func (e *ExpectedFile) checkWindow() error {
+	if _, err := time.Parse(ExpectedFileWindowFormat, e.WindowStart); err != nil {
+		return fmt.Errorf("%w: %q is not a valid window start (expected format is HH:MM)",
+			ErrExpectedFileInvalid, e.WindowStart)
+	}
+
+	if _, err := time.Parse(ExpectedFileWindowFormat, e.WindowEnd); err != nil {
+		return fmt.Errorf("%w: %q is not a valid window end (expected format is HH:MM)",
+			ErrExpectedFileInvalid, e.WindowEnd)
+	}
+
+	if e.WindowStart == e.WindowEnd {
+		return fmt.Errorf("%w: the window cannot be empty", ErrExpectedFileInvalid)
+	}
+
+	days, err := normalizeWeekdays(e.Days)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrExpectedFileInvalid, err)
+	}
+
+	e.Days = days
+
+	return nil
+}
+
+// checkEmailAlert checks the email alert's parameters, using the validation
+// of the EMAIL task.
+func (e *ExpectedFile) checkEmailAlert(db database.ReadAccess) error {
+	if len(e.EmailAlert) == 0 {
+		e.EmailAlert = Map[string]{}
+
+		return nil
+	}
+
+	runner := GetTaskRunner(emailTaskType)
+	if runner == nil {
+		return fmt.Errorf("%w: the %s task is not available", ErrExpectedFileInvalid, emailTaskType)
+	}
+
+	if validator, ok := runner.(TaskValidatorDB); ok {
+		if err := validator.ValidateDB(db, e.EmailAlert); err != nil {
+			return fmt.Errorf("%w: invalid email alert: %w", ErrExpectedFileInvalid, err)
+		}
+	}
+
+	return nil
+}
+
+// WindowOn returns the bounds of the expected file's window which opens on the
+// day of the given date (in the date's location). If the window closes before
+// it opens, it is considered to close on the next day. If the window does not
+// open on that day, the function returns false.
+func (e *ExpectedFile) WindowOn(date time.Time) (start, end time.Time, ok bool) {
+	startTime, startErr := time.Parse(ExpectedFileWindowFormat, e.WindowStart)
+	endTime, endErr := time.Parse(ExpectedFileWindowFormat, e.WindowEnd)
+
+	if startErr != nil || endErr != nil || !hasWeekday(e.Days, date.Weekday()) {
+		return time.Time{}, time.Time{}, false
+	}
+
+	year, month, day := date.Date()
+	start = time.Date(year, month, day, startTime.Hour(), startTime.Minute(), 0, 0, date.Location())
+	end = time.Date(year, month, day, endTime.Hour(), endTime.Minute(), 0, 0, date.Location())
+
+	if !end.After(start) {
+		end = end.AddDate(0, 0, 1)
+	}
+
+	return start, end, true
+}
+
+// ExpectedFileCheck is a record of the outcome of the check of an expected
+// file's window.
+type ExpectedFileCheck struct {
+	Identifier
+	ExpectedFileID int64              `gorm:"column:expected_file_id"`
+	WindowStart    time.Time          `gorm:"column:window_start;type:timestamp;serializer:timestamp"`
+	WindowEnd      time.Time          `gorm:"column:window_end;type:timestamp;serializer:timestamp"`
+	Status         ExpectedFileStatus `gorm:"column:status"`
+	Files          List[string]       `gorm:"column:files;serializer:json"` // The names of the files received during the window.
+	Checked        time.Time          `gorm:"column:checked;type:timestamp;serializer:timestamp"`
+}
+
+func (*ExpectedFileCheck) TableName() string   { return TableExpectedFileChecks }
+func (*ExpectedFileCheck) Appellation() string { return NameExpectedFileCheck }
+
+func (c *ExpectedFileCheck) BeforeWrite(db database.Access) error {
+	switch c.Status {
+	case ExpectedFileReceived, ExpectedFileMissing, ExpectedFileDuplicate:
+	default:
+		return database.NewValidationErrorf("%q is not a valid expected file status", c.Status)
+	}
+
+	if c.Files == nil {
+		c.Files = List[string]{}
+	}
+
+	if n, err := db.Count(&ExpectedFile{}).Where("id=?", c.ExpectedFileID).Run(); err != nil {
+		return fmt.Errorf("failed to check the expected file: %w", err)
+	} else if n == 0 {
+		return database.NewValidationErrorf("no expected file found with ID %d", c.ExpectedFileID)
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package model
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database/dbtest"
+)
+
+func TestExpectedFileTableName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equalf(t, TableExpectedFiles, (&ExpectedFile{}).TableName(),
+		"The expected files table name should be equal to %q", TableExpectedFiles)
+}
+
+func TestExpectedFileBeforeWrite(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+
+	recvRule := &Rule{Name: "rule", IsSend: false}
+	require.NoError(t, db.Insert(recvRule).Run())
+
+	sendRule := &Rule{Name: "rule", IsSend: true}
+	require.NoError(t, db.Insert(sendRule).Run())
+
+	existing := &ExpectedFile{
+		Name: "existing", RuleID: recvRule.ID,
+		WindowStart: "06:00", WindowEnd: "07:00",
+	}
+	require.NoError(t, db.Insert(existing).Run())
+
+	for _, test := range []struct {
+		name    string
+		file    *ExpectedFile
+		wantErr error
+	}{
+		{
+			name: "Valid expected file",
+			file: &ExpectedFile{
+				Name: "new", RuleID: recvRule.ID, Pattern: "*.csv",
+				WindowStart: "22:00", WindowEnd: "02:00",
+				Days: List[string]{"Sunday"},
+			},
+			wantErr: nil,
+		}, {
+			name:    "No name",
+			file:    &ExpectedFile{RuleID: recvRule.ID, WindowStart: "06:00", WindowEnd: "07:00"},
+			wantErr: ErrExpectedFileNoName,
+		}, {
+			name:    "No rule",
+			file:    &ExpectedFile{Name: "new", WindowStart: "06:00", WindowEnd: "07:00"},
+			wantErr: ErrExpectedFileNoRule,
+		}, {
+			name:    "Send rule",
+			file:    &ExpectedFile{Name: "new", RuleID: sendRule.ID, WindowStart: "06:00", WindowEnd: "07:00"},
+			wantErr: ErrExpectedFileInvalid,
+		}, {
+			name: "Partner & local account",
+			file: &ExpectedFile{
+				Name: "new", RuleID: recvRule.ID, WindowStart: "06:00", WindowEnd: "07:00",
+				RemoteAgentID:  sql.NullInt64{Int64: 1, Valid: true},
+				LocalAccountID: sql.NullInt64{Int64: 1, Valid: true},
+			},
+			wantErr: ErrExpectedFileInvalid,
+		}, {
+			name:    "Invalid pattern",
+			file:    &ExpectedFile{Name: "new", RuleID: recvRule.ID, Pattern: "[", WindowStart: "06:00", WindowEnd: "07:00"},
+			wantErr: ErrExpectedFileInvalid,
+		}, {
+			name:    "Invalid window",
+			file:    &ExpectedFile{Name: "new", RuleID: recvRule.ID, WindowStart: "6h", WindowEnd: "07:00"},
+			wantErr: ErrExpectedFileInvalid,
+		}, {
+			name:    "Empty window",
+			file:    &ExpectedFile{Name: "new", RuleID: recvRule.ID, WindowStart: "07:00", WindowEnd: "07:00"},
+			wantErr: ErrExpectedFileInvalid,
+		}, {
+			name: "Invalid day",
+			file: &ExpectedFile{
+				Name: "new", RuleID: recvRule.ID, WindowStart: "06:00", WindowEnd: "07:00",
+				Days: List[string]{"someday"},
+			},
+			wantErr: ErrExpectedFileInvalid,
+		}, {
+			name:    "Duplicate name",
+			file:    &ExpectedFile{Name: "existing", RuleID: recvRule.ID, WindowStart: "06:00", WindowEnd: "07:00"},
+			wantErr: ErrExpectedFileDuplicate,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			if test.wantErr == nil {
+				require.NoError(t, test.file.BeforeWrite(db))
+			} else {
+				require.ErrorIs(t, test.file.BeforeWrite(db), test.wantErr)
+			}
+		})
+	}
+
+	t.Run("Default values", func(t *testing.T) {
+		t.Parallel()
+
+		file := &ExpectedFile{Name: "defaults", RuleID: recvRule.ID, WindowStart: "06:00", WindowEnd: "07:00"}
+		require.NoError(t, file.BeforeWrite(db))
+		assert.Equal(t, FilewatcherDefaultPattern, file.Pattern)
+		assert.Equal(t, List[string]{"monday", "tuesday", "wednesday", "thursday", "friday"},
+			file.Days)
+		assert.False(t, file.Created.IsZero())
+	})
+}
+
+func TestExpectedFileWindowOn(t *testing.T) {
+	t.Parallel()
+
+	file := &ExpectedFile{WindowStart: "22:00", WindowEnd: "02:30", Days: List[string]{"monday"}}
+
+	monday := time.Date(2026, time.October, 19, 15, 0, 0, 0, time.UTC)
+	start, end, ok := file.WindowOn(monday)
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2026, time.October, 19, 22, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2026, time.October, 20, 2, 30, 0, 0, time.UTC), end,
+		"the window should close on the next day")
+
+	_, _, ok = file.WindowOn(monday.AddDate(0, 0, 1))
+	assert.False(t, ok, "the window should not open on tuesdays")
+}
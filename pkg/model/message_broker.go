@@ -0,0 +1,74 @@
+package model
+
+import (
+	"fmt"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/brokers"
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/secrets"
+)
+
+// MessageBroker represents the connection settings of a message broker (AMQP,
+// Kafka, MQTT, NATS...) on which events can be published using the PUBLISH
+// task.
+type MessageBroker struct {
+	Identifier
+	Owner    string      `gorm:"column:owner"`
+	Name     string      `gorm:"column:name"`
+	Type     string      `gorm:"column:type"`
+	Address  string      `gorm:"column:address"`
+	Login    string      `gorm:"column:login"`
+	Password string      `gorm:"column:password;serializer:secret"`
+	Options  Map[string] `gorm:"column:options;serializer:json"`
+}
+
+func (*MessageBroker) TableName() string   { return TableMessageBrokers }
+func (*MessageBroker) Appellation() string { return NameMessageBroker }
+
+func (m *MessageBroker) BeforeWrite(db database.Access) error {
+	if m.Name == "" {
+		return database.NewValidationError("the message broker's name cannot be empty")
+	}
+
+	if m.Type == "" {
+		return database.NewValidationError("the message broker's type cannot be empty")
+	}
+
+	if err := secrets.Validate(m.Password); err != nil {
+		return database.NewValidationErrorf("invalid message broker password: %v", err)
+	}
+
+	conf, confErr := m.Config()
+	if confErr != nil {
+		return database.NewValidationErrorf("invalid message broker password: %v", confErr)
+	}
+
+	if err := brokers.ValidateConfig(m.Type, conf); err != nil {
+		return database.NewValidationErrorf("invalid message broker configuration: %v", err)
+	}
+
+	if n, err := db.Count(m).Where("id<>? AND name=?", m.ID, m.Name).Run(); err != nil {
+		return fmt.Errorf("failed to check existing message brokers: %w", err)
+	} else if n > 0 {
+		return database.NewValidationErrorf("a message broker named %q already exist", m.Name)
+	}
+
+	return nil
+}
+
+// Config returns the message broker's connection configuration. If the
+// broker's password is a secret reference, the secret it refers to is used
+// instead.
+func (m *MessageBroker) Config() (*brokers.Config, error) {
+	password, err := secrets.Resolve(m.Password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve the message broker's password: %w", err)
+	}
+
+	return &brokers.Config{
+		Address:  m.Address,
+		Login:    m.Login,
+		Password: password,
+		Options:  m.Options,
+	}, nil
+}
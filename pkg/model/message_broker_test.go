@@ -0,0 +1,104 @@
+package model
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/brokers"
+	"code.waarp.fr/apps/gateway/gateway/pkg/brokers/brokerstest"
+	"code.waarp.fr/apps/gateway/gateway/pkg/database/dbtest"
+)
+
+func TestMessageBrokerTableName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equalf(t, TableMessageBrokers, (&MessageBroker{}).TableName(),
+		"The message brokers table name should be equal to %q", TableMessageBrokers)
+}
+
+func TestMessageBrokerBeforeWrite(t *testing.T) {
+	t.Parallel()
+
+	kind, backend := brokerstest.MakeDummyBackend(t)
+	backend.Validate = func(conf *brokers.Config) error {
+		if conf.Login != "login" || conf.Password != "password" {
+			return brokerstest.ErrInvalidConfig
+		}
+
+		return nil
+	}
+
+	db := dbtest.TestDatabase(t)
+
+	validBroker := MessageBroker{
+		Name:     "broker",
+		Type:     kind,
+		Address:  "localhost:5672",
+		Login:    "login",
+		Password: "password",
+		Options:  map[string]string{"foo": "bar"},
+	}
+
+	t.Run("Given a valid message broker entry", func(t *testing.T) {
+		broker := validBroker
+
+		require.NoError(t, broker.BeforeWrite(db),
+			"Then calling 'BeforeWrite' should NOT return an error")
+	})
+
+	t.Run("Given an invalid message broker entry", func(t *testing.T) {
+		for name, test := range map[string]struct {
+			modify func(*MessageBroker)
+			err    string
+		}{
+			"Given a message broker with no name": {
+				modify: func(b *MessageBroker) { b.Name = "" },
+				err:    "the message broker's name cannot be empty",
+			},
+			"Given a message broker with no type": {
+				modify: func(b *MessageBroker) { b.Type = "" },
+				err:    "the message broker's type cannot be empty",
+			},
+			"Given a message broker with an unknown type": {
+				modify: func(b *MessageBroker) { b.Type = "unknown" },
+				err:    brokers.ErrUnknownBrokerType.Error(),
+			},
+			"Given a message broker with no address": {
+				modify: func(b *MessageBroker) { b.Address = "" },
+				err:    brokers.ErrMissingAddress.Error(),
+			},
+			"Given a message broker with an unknown option": {
+				modify: func(b *MessageBroker) { b.Options = map[string]string{"unknown": "val"} },
+				err:    brokers.ErrUnknownOption.Error(),
+			},
+			"Given a message broker with an invalid configuration": {
+				modify: func(b *MessageBroker) { b.Password = "wrong" },
+				err:    "invalid message broker configuration",
+			},
+		} {
+			t.Run(name, func(t *testing.T) {
+				broker := validBroker
+				test.modify(&broker)
+
+				require.ErrorContains(t, broker.BeforeWrite(db), test.err,
+					"Then calling 'BeforeWrite' should return an error")
+			})
+		}
+
+		t.Run("Given that the broker's name is already taken", func(t *testing.T) {
+			otherBroker := validBroker
+			require.NoError(t, db.Insert(&otherBroker).Run())
+
+			t.Cleanup(func() { require.NoError(t, db.Delete(&otherBroker).Run()) })
+
+			broker := validBroker
+
+			require.ErrorContains(t, broker.BeforeWrite(db),
+				fmt.Sprintf(`a message broker named %q already exist`, broker.Name),
+				"Then calling 'BeforeWrite' should return an error")
+		})
+	})
+}
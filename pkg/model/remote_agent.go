@@ -22,6 +22,8 @@ type RemoteAgent struct {
 
 	// The partner's protocol configuration as a map.
 	ProtoConfig Map[any] `gorm:"column:proto_config;serializer:json"`
+
+	RetryPolicy string `gorm:"column:retry_policy"` // The name of the partner's retry policy (optional).
 }
 
 func newRemoteAgent(id int64) *RemoteAgent {
@@ -68,6 +70,10 @@ func (r *RemoteAgent) BeforeWrite(db database.Access) error {
 		return database.WrapAsValidationError(err)
 	}
 
+	if err := checkRetryPolicy(db, r.RetryPolicy); err != nil {
+		return err
+	}
+
 	if n, err := db.Count(&RemoteAgent{}).Where("id<>? AND name=?",
 		r.ID, r.Name).Run(); err != nil {
 		return fmt.Errorf("failed to check for duplicate remote agents: %w", err)
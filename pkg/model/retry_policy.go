@@ -0,0 +1,267 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/types"
+)
+
+var (
+	ErrRetryPolicyNoName    = database.NewValidationError("the retry policy's name cannot be empty")
+	ErrRetryPolicyDuplicate = database.NewValidationError("a retry policy with the same name already exists")
+	ErrRetryPolicyInvalid   = database.NewValidationError("the retry policy is invalid")
+	ErrRetryPolicyInUse     = database.NewValidationError("the retry policy is still in use")
+	ErrRetryPolicyNotFound  = database.NewValidationError("the retry policy does not exist")
+)
+
+// RetryAction is the action a RetryPolicy takes when a transfer fails with a
+// given error code.
+type RetryAction string
+
+const (
+	// RetryActionDefault retries the transfer until the policy's maximum number
+	// of attempts has been reached. This is the action used for all the error
+	// codes which are not explicitly listed in the policy.
+	RetryActionDefault RetryAction = "retry"
+	// RetryActionNever never retries the transfer.
+	RetryActionNever RetryAction = "never"
+	// RetryActionAlways retries the transfer regardless of the number of
+	// attempts (the policy's maximum retry window still applies).
+	RetryActionAlways RetryAction = "always"
+)
+
+// RetryPolicy defines how (and whether) a failed transfer should be retried.
+// A policy can be attached to rules and to partners. A rule's policy takes
+// precedence over the partner's.
+type RetryPolicy struct {
+	Identifier
+	Name string `gorm:"column:name"`
+
+	MaxAttempts     int8          `gorm:"column:max_attempts"`     // The maximum number of attempts (including the first one).
+	FirstDelay      time.Duration `gorm:"column:first_delay"`      // The delay before the first retry.
+	IncrementFactor float32       `gorm:"column:increment_factor"` // The factor by which the delay is multiplied at each retry.
+	MaxDelay        time.Duration `gorm:"column:max_delay"`        // The maximum delay between 2 attempts (0 means no maximum).
+	Jitter          float32       `gorm:"column:jitter"`           // The random variation applied to the delay (as a fraction of the delay).
+	MaxWindow       time.Duration `gorm:"column:max_window"`       // The maximum time during which the transfer can be retried (0 means no maximum).
+
+	// The actions taken for specific error codes, indexed by error code.
+	ErrorCodes Map[RetryAction] `gorm:"column:error_codes;serializer:json"`
+}
+
+func (*RetryPolicy) TableName() string   { return TableRetryPolicies }
+func (*RetryPolicy) Appellation() string { return NameRetryPolicy }
+
+//nolint:cyclop //splitting the function would hurt readability
+func (r *RetryPolicy) BeforeWrite(db database.Access) error {
+	if r.Name == "" {
+		return ErrRetryPolicyNoName
+	}
+
+	if r.IncrementFactor == 0 {
+		r.IncrementFactor = 1
+	}
+
+	if r.ErrorCodes == nil {
+		r.ErrorCodes = Map[RetryAction]{}
+	}
+
+	switch {
+	case r.MaxAttempts < 0:
+		return fmt.Errorf("%w: the maximum number of attempts cannot be negative", ErrRetryPolicyInvalid)
+	case r.FirstDelay < 0, r.MaxDelay < 0, r.MaxWindow < 0:
+		return fmt.Errorf("%w: the delays cannot be negative", ErrRetryPolicyInvalid)
+	case r.IncrementFactor < 0:
+		return fmt.Errorf("%w: the increment factor cannot be negative", ErrRetryPolicyInvalid)
+	case r.Jitter < 0 || r.Jitter > 1:
+		return fmt.Errorf("%w: the jitter must be between 0 and 1", ErrRetryPolicyInvalid)
+	}
+
+	for code, action := range r.ErrorCodes {
+		if tec := types.TecFromString(code); code == "" || tec.String() != code || tec == types.TeOk {
+			return fmt.Errorf("%w: %q is not a valid error code", ErrRetryPolicyInvalid, code)
+		}
+
+		switch action {
+		case RetryActionDefault, RetryActionNever, RetryActionAlways:
+		default:
+			return fmt.Errorf("%w: %q is not a valid retry action (must be one of %q, %q or %q)",
+				ErrRetryPolicyInvalid, action, RetryActionDefault, RetryActionNever,
+				RetryActionAlways)
+		}
+	}
+
+	if n, err := db.Count(r).Where("id<>? AND name=?", r.ID, r.Name).Run(); err != nil {
+		return fmt.Errorf("failed to check for duplicate retry policies: %w", err)
+	} else if n != 0 {
+		return fmt.Errorf("%w: %q", ErrRetryPolicyDuplicate, r.Name)
+	}
+
+	return r.renameReferences(db)
+}
+
+// renameReferences updates the rules & partners referencing the policy when
+// the policy is renamed.
+func (r *RetryPolicy) renameReferences(db database.Access) error {
+	if r.ID == 0 {
+		return nil
+	}
+
+	var old RetryPolicy
+	if err := db.Get(&old, "id=?", r.ID).Run(); database.IsNotFound(err) {
+		return nil // the policy is being inserted with an explicit ID
+	} else if err != nil {
+		return fmt.Errorf("failed to retrieve the old retry policy: %w", err)
+	}
+
+	if old.Name == r.Name {
+		return nil
+	}
+
+	for _, table := range []string{TableRules, TableRemAgents} {
+		if err := db.Exec(fmt.Sprintf("UPDATE %s SET retry_policy=? WHERE retry_policy=?",
+			table), r.Name, old.Name); err != nil {
+			return fmt.Errorf("failed to update the retry policy's references: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// BeforeDelete checks that the policy is not used by any rule or partner
+// before deleting it.
+func (r *RetryPolicy) BeforeDelete(db database.Access) error {
+	if n, err := db.Count(&Rule{}).Where("retry_policy=?", r.Name).Run(); err != nil {
+		return fmt.Errorf("failed to check for rules using the retry policy: %w", err)
+	} else if n != 0 {
+		return fmt.Errorf("%w: it is used by %d rule(s)", ErrRetryPolicyInUse, n)
+	}
+
+	if n, err := db.Count(&RemoteAgent{}).Where("retry_policy=?", r.Name).Run(); err != nil {
+		return fmt.Errorf("failed to check for partners using the retry policy: %w", err)
+	} else if n != 0 {
+		return fmt.Errorf("%w: it is used by %d partner(s)", ErrRetryPolicyInUse, n)
+	}
+
+	return nil
+}
+
+// Action returns the action taken by the policy for the given error code.
+func (r *RetryPolicy) Action(code types.TransferErrorCode) RetryAction {
+	if action, ok := r.ErrorCodes[code.String()]; ok {
+		return action
+	}
+
+	return RetryActionDefault
+}
+
+// NextAttempt returns the date at which the given failed transfer should be
+// retried according to the policy. If the transfer should not be retried, the
+// function returns false.
+//
+// As with the clients' retry parameters, the transfer's RemainingTries must
+// already account for the attempt which just failed.
+func (r *RetryPolicy) NextAttempt(trans *Transfer, now time.Time) (time.Time, bool) {
+	action := r.Action(trans.ErrCode)
+
+	switch {
+	case action == RetryActionNever:
+		return time.Time{}, false
+	case action == RetryActionDefault && trans.RemainingTries <= 0:
+		return time.Time{}, false
+	}
+
+	delay := time.Duration(trans.NextRetryDelay) * time.Second
+	if r.MaxDelay > 0 && delay > r.MaxDelay {
+		delay = r.MaxDelay
+	}
+
+	if r.Jitter > 0 {
+		//nolint:gosec //no need for a cryptographic random source for the jitter
+		variation := float64(delay) * float64(r.Jitter) * (2*rand.Float64() - 1)
+		delay += time.Duration(variation)
+	}
+
+	next := now.Add(delay)
+	if r.MaxWindow > 0 && next.After(trans.Start.Add(r.MaxWindow)) {
+		return time.Time{}, false
+	}
+
+	return next, true
+}
+
+// NextDelay returns the delay (in seconds) which should be used for the retry
+// following the next one, taking the policy's maximum delay into account.
+func (r *RetryPolicy) NextDelay(trans *Transfer) int32 {
+	newDelay := float64(trans.NextRetryDelay) * float64(trans.RetryIncrementFactor)
+
+	if maxDelay := r.MaxDelay.Seconds(); r.MaxDelay > 0 && newDelay > maxDelay {
+		newDelay = maxDelay
+	}
+
+	if newDelay > math.MaxInt32 {
+		return math.MaxInt32
+	}
+
+	return int32(newDelay)
+}
+
+// delaySeconds returns the policy's first delay in seconds, as stored in the
+// transfers' NextRetryDelay.
+func (r *RetryPolicy) delaySeconds() int32 {
+	secs := r.FirstDelay.Seconds()
+
+	switch {
+	case secs > math.MaxInt32:
+		return math.MaxInt32
+	case secs > 0 && secs < 1:
+		return 1
+	default:
+		return int32(secs)
+	}
+}
+
+// GetRetryPolicy returns the retry policy applying to transfers made with the
+// given rule & partner (the partner can be nil for server transfers). The rule's
+// policy takes precedence over the partner's. If neither of them has a retry
+// policy, the function returns nil.
+func GetRetryPolicy(db database.ReadAccess, rule *Rule, partner *RemoteAgent,
+) (*RetryPolicy, error) {
+	name := rule.RetryPolicy
+	if name == "" && partner != nil {
+		name = partner.RetryPolicy
+	}
+
+	if name == "" {
+		return nil, nil //nolint:nilnil //no policy is not an error
+	}
+
+	var policy RetryPolicy
+	if err := db.Get(&policy, "name=?", name).Run(); err != nil {
+		if database.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: %q", ErrRetryPolicyNotFound, name)
+		}
+
+		return nil, fmt.Errorf("failed to retrieve the retry policy %q: %w", name, err)
+	}
+
+	return &policy, nil
+}
+
+// checkRetryPolicy checks that the given retry policy exists (if not empty).
+func checkRetryPolicy(db database.ReadAccess, name string) error {
+	if name == "" {
+		return nil
+	}
+
+	if n, err := db.Count(&RetryPolicy{}).Where("name=?", name).Run(); err != nil {
+		return fmt.Errorf("failed to check the retry policy: %w", err)
+	} else if n == 0 {
+		return fmt.Errorf("%w: %q", ErrRetryPolicyNotFound, name)
+	}
+
+	return nil
+}
@@ -0,0 +1,310 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database/dbtest"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/types"
+)
+
+func TestRetryPolicyTableName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equalf(t, TableRetryPolicies, (&RetryPolicy{}).TableName(),
+		"The retry policies table name should be equal to %q", TableRetryPolicies)
+}
+
+func TestRetryPolicyBeforeWrite(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+	existing := &RetryPolicy{Name: "existing"}
+	require.NoError(t, db.Insert(existing).Run())
+
+	for _, test := range []struct {
+		name    string
+		policy  *RetryPolicy
+		wantErr error
+	}{
+		{
+			name: "Valid policy",
+			policy: &RetryPolicy{
+				Name: "new", MaxAttempts: 5, FirstDelay: time.Minute,
+				IncrementFactor: 2, MaxDelay: time.Hour, Jitter: 0.2,
+				ErrorCodes: Map[RetryAction]{
+					types.TeConnection.String(): RetryActionAlways,
+					types.TeForbidden.String():  RetryActionNever,
+				},
+			},
+			wantErr: nil,
+		}, {
+			name:    "No name",
+			policy:  &RetryPolicy{MaxAttempts: 5},
+			wantErr: ErrRetryPolicyNoName,
+		}, {
+			name:    "Negative attempts",
+			policy:  &RetryPolicy{Name: "new", MaxAttempts: -1},
+			wantErr: ErrRetryPolicyInvalid,
+		}, {
+			name:    "Negative delay",
+			policy:  &RetryPolicy{Name: "new", FirstDelay: -time.Second},
+			wantErr: ErrRetryPolicyInvalid,
+		}, {
+			name:    "Invalid jitter",
+			policy:  &RetryPolicy{Name: "new", Jitter: 1.5},
+			wantErr: ErrRetryPolicyInvalid,
+		}, {
+			name: "Invalid error code",
+			policy: &RetryPolicy{Name: "new", ErrorCodes: Map[RetryAction]{
+				"TeUnknownCode": RetryActionNever,
+			}},
+			wantErr: ErrRetryPolicyInvalid,
+		}, {
+			name: "Invalid action",
+			policy: &RetryPolicy{Name: "new", ErrorCodes: Map[RetryAction]{
+				types.TeForbidden.String(): "sometimes",
+			}},
+			wantErr: ErrRetryPolicyInvalid,
+		}, {
+			name:    "Duplicate name",
+			policy:  &RetryPolicy{Name: "existing"},
+			wantErr: ErrRetryPolicyDuplicate,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			if test.wantErr == nil {
+				require.NoError(t, test.policy.BeforeWrite(db))
+			} else {
+				require.ErrorIs(t, test.policy.BeforeWrite(db), test.wantErr)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyBeforeDelete(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+
+	unused := &RetryPolicy{Name: "unused"}
+	require.NoError(t, db.Insert(unused).Run())
+
+	used := &RetryPolicy{Name: "used"}
+	require.NoError(t, db.Insert(used).Run())
+
+	rule := &Rule{Name: "rule", IsSend: true, RetryPolicy: used.Name}
+	require.NoError(t, db.Insert(rule).Run())
+
+	require.NoError(t, unused.BeforeDelete(db))
+	require.ErrorIs(t, used.BeforeDelete(db), ErrRetryPolicyInUse)
+}
+
+func TestRetryPolicyNextAttempt(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	policy := &RetryPolicy{
+		Name: "policy", MaxAttempts: 3, MaxDelay: 10 * time.Minute,
+		MaxWindow: time.Hour,
+		ErrorCodes: Map[RetryAction]{
+			types.TeConnection.String(): RetryActionAlways,
+			types.TeForbidden.String():  RetryActionNever,
+		},
+	}
+
+	for _, test := range []struct {
+		name      string
+		trans     *Transfer
+		wantRetry bool
+		wantNext  time.Time
+	}{
+		{
+			name: "Default action with remaining tries",
+			trans: &Transfer{
+				Start: now, ErrCode: types.TeDataTransfer,
+				RemainingTries: 1, NextRetryDelay: 30,
+			},
+			wantRetry: true,
+			wantNext:  now.Add(30 * time.Second),
+		}, {
+			name: "Default action without remaining tries",
+			trans: &Transfer{
+				Start: now, ErrCode: types.TeDataTransfer,
+				RemainingTries: 0, NextRetryDelay: 30,
+			},
+			wantRetry: false,
+		}, {
+			name: "Always retry without remaining tries",
+			trans: &Transfer{
+				Start: now, ErrCode: types.TeConnection,
+				RemainingTries: 0, NextRetryDelay: 30,
+			},
+			wantRetry: true,
+			wantNext:  now.Add(30 * time.Second),
+		}, {
+			name: "Never retry",
+			trans: &Transfer{
+				Start: now, ErrCode: types.TeForbidden,
+				RemainingTries: 2, NextRetryDelay: 30,
+			},
+			wantRetry: false,
+		}, {
+			name: "Delay capped by the max delay",
+			trans: &Transfer{
+				Start: now, ErrCode: types.TeDataTransfer,
+				RemainingTries: 1, NextRetryDelay: 3600,
+			},
+			wantRetry: true,
+			wantNext:  now.Add(10 * time.Minute),
+		}, {
+			name: "Outside the retry window",
+			trans: &Transfer{
+				Start: now.Add(-55 * time.Minute), ErrCode: types.TeConnection,
+				RemainingTries: 1, NextRetryDelay: 600,
+			},
+			wantRetry: false,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			next, retry := policy.NextAttempt(test.trans, now)
+			require.Equal(t, test.wantRetry, retry)
+
+			if test.wantRetry {
+				assert.Equal(t, test.wantNext, next)
+			}
+		})
+	}
+
+	t.Run("With jitter", func(t *testing.T) {
+		t.Parallel()
+
+		jittered := &RetryPolicy{Name: "jittered", MaxAttempts: 3, Jitter: 0.5}
+		trans := &Transfer{Start: now, RemainingTries: 1, NextRetryDelay: 60}
+
+		next, retry := jittered.NextAttempt(trans, now)
+		require.True(t, retry)
+		assert.WithinRange(t, next, now.Add(30*time.Second), now.Add(90*time.Second))
+	})
+}
+
+func TestRetryPolicyNextDelay(t *testing.T) {
+	t.Parallel()
+
+	policy := &RetryPolicy{Name: "policy", MaxDelay: 5 * time.Minute}
+
+	assert.EqualValues(t, 120, policy.NextDelay(&Transfer{
+		NextRetryDelay: 60, RetryIncrementFactor: 2,
+	}))
+	assert.EqualValues(t, 300, policy.NextDelay(&Transfer{
+		NextRetryDelay: 200, RetryIncrementFactor: 2,
+	}))
+}
+
+func TestGetRetryPolicy(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+
+	rulePolicy := &RetryPolicy{Name: "rule_policy", MaxAttempts: 2}
+	require.NoError(t, db.Insert(rulePolicy).Run())
+
+	partnerPolicy := &RetryPolicy{Name: "partner_policy", MaxAttempts: 7}
+	require.NoError(t, db.Insert(partnerPolicy).Run())
+
+	t.Run("Rule policy takes precedence", func(t *testing.T) {
+		t.Parallel()
+
+		policy, err := GetRetryPolicy(db,
+			&Rule{RetryPolicy: rulePolicy.Name},
+			&RemoteAgent{RetryPolicy: partnerPolicy.Name})
+		require.NoError(t, err)
+		require.NotNil(t, policy)
+		assert.Equal(t, rulePolicy.Name, policy.Name)
+	})
+
+	t.Run("Partner policy", func(t *testing.T) {
+		t.Parallel()
+
+		policy, err := GetRetryPolicy(db, &Rule{}, &RemoteAgent{RetryPolicy: partnerPolicy.Name})
+		require.NoError(t, err)
+		require.NotNil(t, policy)
+		assert.Equal(t, partnerPolicy.Name, policy.Name)
+	})
+
+	t.Run("No policy", func(t *testing.T) {
+		t.Parallel()
+
+		policy, err := GetRetryPolicy(db, &Rule{}, nil)
+		require.NoError(t, err)
+		assert.Nil(t, policy)
+	})
+}
+
+func TestTransferRetryPolicyParameters(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+
+	policy := &RetryPolicy{
+		Name: "policy", MaxAttempts: 4, FirstDelay: 45 * time.Second,
+		IncrementFactor: 3,
+	}
+	require.NoError(t, db.Insert(policy).Run())
+
+	rule := Rule{Name: "rule", IsSend: true, RetryPolicy: policy.Name}
+	require.NoError(t, db.Insert(&rule).Run())
+
+	client := Client{
+		Name: "client", Protocol: testProtocol,
+		NbOfAttempts: 1, FirstRetryDelay: 10, RetryIncrementFactor: 1,
+	}
+	require.NoError(t, db.Insert(&client).Run())
+
+	partner := RemoteAgent{Name: "partner", Protocol: testProtocol, Address: types.Addr("localhost", 0)}
+	require.NoError(t, db.Insert(&partner).Run())
+
+	remAccount := RemoteAccount{RemoteAgentID: partner.ID, Login: "toto"}
+	require.NoError(t, db.Insert(&remAccount).Run())
+
+	server := LocalAgent{Name: "server", Protocol: testProtocol, Address: types.Addr("localhost", 0)}
+	require.NoError(t, db.Insert(&server).Run())
+
+	locAccount := LocalAccount{LocalAgentID: server.ID, Login: "toto"}
+	require.NoError(t, db.Insert(&locAccount).Run())
+
+	t.Run("Client transfer", func(t *testing.T) {
+		trans := &Transfer{
+			RuleID:          rule.ID,
+			ClientID:        client.NullableID(),
+			RemoteAccountID: remAccount.NullableID(),
+			SrcFilename:     "file.txt",
+		}
+		require.NoError(t, db.Insert(trans).Run())
+
+		assert.EqualValues(t, policy.MaxAttempts, trans.RemainingTries)
+		assert.EqualValues(t, 45, trans.NextRetryDelay)
+		assert.InDelta(t, policy.IncrementFactor, trans.RetryIncrementFactor, 0)
+	})
+
+	t.Run("Server transfer", func(t *testing.T) {
+		trans := &Transfer{
+			RuleID:           rule.ID,
+			LocalAccountID:   locAccount.NullableID(),
+			RemoteTransferID: "1",
+			SrcFilename:      "file.txt",
+		}
+		require.NoError(t, db.Insert(trans).Run())
+
+		assert.EqualValues(t, policy.MaxAttempts, trans.RemainingTries)
+		assert.Zero(t, trans.NextRetryDelay)
+		assert.Zero(t, trans.RetryIncrementFactor)
+	})
+}
@@ -24,6 +24,8 @@ type Rule struct {
 	LocalDir       string `gorm:"column:local_dir"`             // The local directory for transfers.
 	RemoteDir      string `gorm:"column:remote_dir"`            // The remote directory for transfers.
 	TmpLocalRcvDir string `gorm:"column:tmp_local_receive_dir"` // The local temporary directory for transfers.
+
+	RetryPolicy string `gorm:"column:retry_policy"` // The name of the rule's retry policy (optional).
 }
 
 func (*Rule) TableName() string   { return TableRules }
@@ -93,6 +95,10 @@ func (r *Rule) BeforeWrite(db database.Access) error {
 		return database.NewValidationError("rule tmp directory must be local")
 	}
 
+	if err := checkRetryPolicy(db, r.RetryPolicy); err != nil {
+		return err
+	}
+
 	return r.checkPath(db)
 }
 
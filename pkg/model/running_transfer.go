@@ -0,0 +1,104 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+)
+
+// TransferCommand is a command sent to the gateway node executing a transfer,
+// via its RunningTransfer entry.
+type TransferCommand string
+
+const (
+	TransferCommandNone      TransferCommand = ""
+	TransferCommandPause     TransferCommand = "PAUSE"
+	TransferCommandInterrupt TransferCommand = "INTERRUPT"
+	TransferCommandCancel    TransferCommand = "CANCEL"
+)
+
+func (t TransferCommand) IsValid() bool {
+	switch t {
+	case TransferCommandNone, TransferCommandPause, TransferCommandInterrupt,
+		TransferCommandCancel:
+		return true
+	default:
+		return false
+	}
+}
+
+var (
+	ErrRunningTransferNoTransfer = database.NewValidationError("the running transfer's ID is missing")
+	ErrRunningTransferNoNode     = database.NewValidationError("the running transfer's node is missing")
+	ErrRunningTransferBadCommand = database.NewValidationError("invalid transfer command")
+)
+
+// RunningTransfer associates a running transfer with the gateway node executing
+// it in a clustered deployment. Since transfer pipelines only exist in the
+// memory of the node executing them, the entry is also used as a control
+// channel, allowing any node to send a command (pause, interrupt or cancel)
+// to the node executing the transfer.
+type RunningTransfer struct {
+	Identifier
+	TransferID int64           `gorm:"column:transfer_id"`
+	Node       string          `gorm:"column:node"`
+	Command    TransferCommand `gorm:"column:command"`
+	Since      time.Time       `gorm:"column:since;type:timestamp;serializer:timestamp"`
+	IsServer   bool            `gorm:"column:is_server"`
+
+	// LeaseExpiry is the date at which the node's lease on the transfer
+	// expires. The lease is periodically renewed by the node executing the
+	// transfer. Once expired, the transfer is considered orphaned, and is
+	// requeued so that another node can resume it.
+	LeaseExpiry time.Time `gorm:"column:lease_expiry;type:timestamp;serializer:timestamp"`
+}
+
+func (*RunningTransfer) TableName() string   { return TableRunningTransfers }
+func (*RunningTransfer) Appellation() string { return NameRunningTransfer }
+
+func (r *RunningTransfer) BeforeWrite(database.Access) error {
+	if r.TransferID == 0 {
+		return ErrRunningTransferNoTransfer
+	}
+
+	if r.Node == "" {
+		return ErrRunningTransferNoNode
+	}
+
+	if !r.Command.IsValid() {
+		return fmt.Errorf("%w %q", ErrRunningTransferBadCommand, r.Command)
+	}
+
+	return nil
+}
+
+// GetTransferNode returns the name of the cluster node currently executing
+// the given transfer. If the transfer is not running on any node (or if the
+// gateway is not clustered), an empty string is returned.
+func GetTransferNode(db database.ReadAccess, transferID int64) (string, error) {
+	var running RunningTransfer
+	if err := db.Get(&running, "transfer_id=?", transferID).Run(); err != nil {
+		if database.IsNotFound(err) {
+			return "", nil
+		}
+
+		return "", fmt.Errorf("failed to retrieve the transfer's node: %w", err)
+	}
+
+	return running.Node, nil
+}
+
+// CountClusterTransfers returns the number of transfers (server or client,
+// depending on the given boolean) currently running on the whole cluster.
+// Entries whose lease has expired are not counted, since the transfers they
+// refer to are about to be taken over.
+func CountClusterTransfers(db database.ReadAccess, isServer bool) (uint64, error) {
+	count, err := db.Count(&RunningTransfer{}).Where("is_server=? AND lease_expiry>=?",
+		isServer, time.Now().UTC()).Run()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count the cluster's running transfers: %w", err)
+	}
+
+	return count, nil
+}
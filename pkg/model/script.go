@@ -0,0 +1,41 @@
+package model
+
+import (
+	"go.starlark.net/syntax"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+)
+
+var (
+	ErrScriptNoName    = database.NewValidationError("the script's name cannot be empty")
+	ErrScriptNoContent = database.NewValidationError("the script's content cannot be empty")
+	ErrScriptInvalid   = database.NewValidationError("the script is invalid")
+	ErrScriptDuplicate = database.NewValidationError("a script with the same name already exists")
+)
+
+// Script is a Starlark script, which can be executed by the SCRIPT task.
+type Script struct {
+	Identifier
+	Name    string `gorm:"column:name"`
+	Content string `gorm:"column:content"`
+}
+
+func (*Script) TableName() string   { return TableScripts }
+func (*Script) Appellation() string { return NameScript }
+
+func (s *Script) TextFields() (name, content *string) { return &s.Name, &s.Content }
+
+func (s *Script) BeforeWrite(db database.Access) error {
+	return checkStoredText(db, s, &storedTextErrors{
+		noName:    ErrScriptNoName,
+		noContent: ErrScriptNoContent,
+		invalid:   ErrScriptInvalid,
+		duplicate: ErrScriptDuplicate,
+	}, func(name, content string) error {
+		// Only the syntax is checked here, the names used by the script are
+		// resolved when the script is compiled by the task.
+		_, err := (&syntax.FileOptions{}).Parse(name, content, 0)
+
+		return err //nolint:wrapcheck //wrapped by checkStoredText
+	})
+}
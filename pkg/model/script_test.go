@@ -0,0 +1,63 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database/dbtest"
+)
+
+func TestScriptTableName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equalf(t, TableScripts, (&Script{}).TableName(),
+		"The scripts table name should be equal to %q", TableScripts)
+}
+
+func TestScriptBeforeWrite(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+	existing := &Script{Name: "existing", Content: `print("hello")`}
+	require.NoError(t, db.Insert(existing).Run())
+
+	for _, test := range []struct {
+		name    string
+		script  *Script
+		wantErr error
+	}{
+		{
+			name:    "Valid script",
+			script:  &Script{Name: "new", Content: "x = 1\nif x:\n    print(x)\n"},
+			wantErr: nil,
+		}, {
+			name:    "No name",
+			script:  &Script{Content: `print("hello")`},
+			wantErr: ErrScriptNoName,
+		}, {
+			name:    "No content",
+			script:  &Script{Name: "test"},
+			wantErr: ErrScriptNoContent,
+		}, {
+			name:    "Invalid syntax",
+			script:  &Script{Name: "test", Content: "if x\n    print(x)"},
+			wantErr: ErrScriptInvalid,
+		}, {
+			name:    "Duplicate name",
+			script:  &Script{Name: "existing", Content: `print("hello")`},
+			wantErr: ErrScriptDuplicate,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			if test.wantErr == nil {
+				require.NoError(t, test.script.BeforeWrite(db))
+			} else {
+				require.ErrorIs(t, test.script.BeforeWrite(db), test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package model
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+)
+
+// SLADeadlineFormat is the format of the SLAs' deadlines (a time of day).
+const SLADeadlineFormat = "15:04"
+
+var (
+	ErrSLANoName    = database.NewValidationError("the SLA's name cannot be empty")
+	ErrSLADuplicate = database.NewValidationError("an SLA with the same name already exists")
+	ErrSLANoScope   = database.NewValidationError("the SLA must apply to a rule and/or a partner")
+	ErrSLANoTarget  = database.NewValidationError("the SLA must define a deadline, a maximum duration or a minimum throughput")
+	ErrSLAInvalid   = database.NewValidationError("the SLA is invalid")
+)
+
+// SLABreachKind is the kind of objective an SLABreach failed to meet.
+type SLABreachKind string
+
+const (
+	// SLABreachDeadline means that no transfer completed before the SLA's
+	// deadline.
+	SLABreachDeadline SLABreachKind = "deadline"
+	// SLABreachDuration means that a transfer took (or has been running for)
+	// longer than the SLA's maximum duration.
+	SLABreachDuration SLABreachKind = "duration"
+	// SLABreachThroughput means that a transfer's average throughput was below
+	// the SLA's minimum throughput.
+	SLABreachThroughput SLABreachKind = "throughput"
+)
+
+//nolint:gochecknoglobals //default values cannot be constant
+var businessDays = []string{"monday", "tuesday", "wednesday", "thursday", "friday"}
+
+// SLA (service level agreement) defines the objectives which the transfers
+// made with a given rule and/or partner must meet. The SLAs are periodically
+// evaluated by the SLA monitor, which records an SLABreach (and sends an alert)
+// every time one of these objectives is not met.
+type SLA struct {
+	Identifier
+	Owner string `gorm:"column:owner"`
+	Name  string `gorm:"column:name"`
+
+	// The scope of the SLA. At least one of them must be set.
+	RuleID        sql.NullInt64 `gorm:"column:rule_id"`
+	RemoteAgentID sql.NullInt64 `gorm:"column:remote_agent_id"`
+
+	Deadline      string        `gorm:"column:deadline"`                      // The time of day (local time, "HH:MM") by which a transfer must have completed.
+	DeadlineDays  List[string]  `gorm:"column:deadline_days;serializer:json"` // The days of the week on which the deadline applies.
+	MaxDuration   time.Duration `gorm:"column:max_duration"`                  // The maximum duration of a transfer (0 means no maximum).
+	MinThroughput int64         `gorm:"column:min_throughput"`                // The minimum average throughput of a transfer (in bytes/s, 0 means no minimum).
+}
+
+func (*SLA) TableName() string   { return TableSLAs }
+func (*SLA) Appellation() string { return NameSLA }
+
+//nolint:cyclop //splitting the function would hurt readability
+func (s *SLA) BeforeWrite(db database.Access) error {
+	if s.Name == "" {
+		return ErrSLANoName
+	}
+
+	if !s.RuleID.Valid && !s.RemoteAgentID.Valid {
+		return ErrSLANoScope
+	}
+
+	if s.Deadline == "" && s.MaxDuration == 0 && s.MinThroughput == 0 {
+		return ErrSLANoTarget
+	}
+
+	switch {
+	case s.MaxDuration < 0:
+		return fmt.Errorf("%w: the maximum duration cannot be negative", ErrSLAInvalid)
+	case s.MinThroughput < 0:
+		return fmt.Errorf("%w: the minimum throughput cannot be negative", ErrSLAInvalid)
+	}
+
+	if err := s.checkDeadline(); err != nil {
+		return err
+	}
+
+	if s.RuleID.Valid {
+		if n, err := db.Count(&Rule{}).Where("id=?", s.RuleID.Int64).Run(); err != nil {
+			return fmt.Errorf("failed to check the SLA's rule: %w", err)
+		} else if n == 0 {
+			return database.NewValidationErrorf("no rule found with ID %d", s.RuleID.Int64)
+		}
+	}
+
+	if s.RemoteAgentID.Valid {
+		if n, err := db.Count(&RemoteAgent{}).Where("id=?", s.RemoteAgentID.Int64).Run(); err != nil {
+			return fmt.Errorf("failed to check the SLA's partner: %w", err)
+		} else if n == 0 {
+			return database.NewValidationErrorf("no partner found with ID %d", s.RemoteAgentID.Int64)
+		}
+	}
+
+	if n, err := db.Count(s).Where("id<>? AND name=?", s.ID, s.Name).Run(); err != nil {
+		return fmt.Errorf("failed to check for duplicate SLAs: %w", err)
+	} else if n != 0 {
+		return fmt.Errorf("%w: %q", ErrSLADuplicate, s.Name)
+	}
+
+	return nil
+}
+
+func (s *SLA) checkDeadline() error {
+	if s.Deadline == "" {
+		s.DeadlineDays = List[string]{}
+
+		return nil
+	}
+
+	if _, err := time.Parse(SLADeadlineFormat, s.Deadline); err != nil {
+		return fmt.Errorf("%w: %q is not a valid deadline (expected format is HH:MM)",
+			ErrSLAInvalid, s.Deadline)
+	}
+
+	days, err := normalizeWeekdays(s.DeadlineDays)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrSLAInvalid, err)
+	}
+
+	s.DeadlineDays = days
+
+	return nil
+}
+
+// normalizeWeekdays checks & lowercases the given days of the week. If the
+// list is empty, it defaults to monday through friday.
+func normalizeWeekdays(days List[string]) (List[string], error) {
+	if len(days) == 0 {
+		return slices.Clone(businessDays), nil
+	}
+
+	for i, day := range days {
+		if _, ok := parseWeekday(day); !ok {
+			return nil, fmt.Errorf("%q is not a valid day of the week", day)
+		}
+
+		days[i] = strings.ToLower(day)
+	}
+
+	return days, nil
+}
+
+func parseWeekday(day string) (time.Weekday, bool) {
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		if strings.EqualFold(day, weekday.String()) {
+			return weekday, true
+		}
+	}
+
+	return 0, false
+}
+
+func hasWeekday(days List[string], weekday time.Weekday) bool {
+	return slices.ContainsFunc(days, func(day string) bool {
+		parsed, ok := parseWeekday(day)
+
+		return ok && parsed == weekday
+	})
+}
+
+// DeadlineOn returns the SLA's deadline on the day of the given date (in the
+// date's location). If the SLA has no deadline on that day, the function
+// returns false.
+func (s *SLA) DeadlineOn(date time.Time) (time.Time, bool) {
+	if s.Deadline == "" {
+		return time.Time{}, false
+	}
+
+	deadline, err := time.Parse(SLADeadlineFormat, s.Deadline)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	if !hasWeekday(s.DeadlineDays, date.Weekday()) {
+		return time.Time{}, false
+	}
+
+	year, month, day := date.Date()
+
+	return time.Date(year, month, day, deadline.Hour(), deadline.Minute(), 0, 0,
+		date.Location()), true
+}
+
+// SLABreach is a record of an SLA objective which was not met.
+type SLABreach struct {
+	Identifier
+	SLAID int64         `gorm:"column:sla_id"`
+	Kind  SLABreachKind `gorm:"column:kind"`
+
+	// The transfer which breached the SLA (invalid for missed deadlines).
+	TransferID sql.NullInt64 `gorm:"column:transfer_id"`
+	// The date of the missed deadline, or the start date of the transfer.
+	Date     time.Time `gorm:"column:date;type:timestamp;serializer:timestamp"`
+	Detected time.Time `gorm:"column:detected;type:timestamp;serializer:timestamp"`
+	Details  string    `gorm:"column:details"`
+}
+
+func (*SLABreach) TableName() string   { return TableSLABreaches }
+func (*SLABreach) Appellation() string { return NameSLABreach }
+
+func (b *SLABreach) BeforeWrite(db database.Access) error {
+	switch b.Kind {
+	case SLABreachDeadline, SLABreachDuration, SLABreachThroughput:
+	default:
+		return database.NewValidationErrorf("%q is not a valid SLA breach kind", b.Kind)
+	}
+
+	if n, err := db.Count(&SLA{}).Where("id=?", b.SLAID).Run(); err != nil {
+		return fmt.Errorf("failed to check the breach's SLA: %w", err)
+	} else if n == 0 {
+		return database.NewValidationErrorf("no SLA found with ID %d", b.SLAID)
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package model
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database/dbtest"
+)
+
+func TestSLATableName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equalf(t, TableSLAs, (&SLA{}).TableName(),
+		"The SLAs table name should be equal to %q", TableSLAs)
+}
+
+func TestSLABeforeWrite(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+
+	rule := &Rule{Name: "rule", IsSend: true}
+	require.NoError(t, db.Insert(rule).Run())
+
+	ruleID := sql.NullInt64{Int64: rule.ID, Valid: true}
+
+	existing := &SLA{Name: "existing", RuleID: ruleID, MaxDuration: time.Hour}
+	require.NoError(t, db.Insert(existing).Run())
+
+	for _, test := range []struct {
+		name    string
+		sla     *SLA
+		wantErr error
+	}{
+		{
+			name: "Valid SLA",
+			sla: &SLA{
+				Name: "new", RuleID: ruleID, Deadline: "07:00",
+				DeadlineDays: List[string]{"Monday", "saturday"},
+				MaxDuration:  30 * time.Minute, MinThroughput: 1000,
+			},
+			wantErr: nil,
+		}, {
+			name:    "No name",
+			sla:     &SLA{RuleID: ruleID, MaxDuration: time.Hour},
+			wantErr: ErrSLANoName,
+		}, {
+			name:    "No scope",
+			sla:     &SLA{Name: "new", MaxDuration: time.Hour},
+			wantErr: ErrSLANoScope,
+		}, {
+			name:    "No objective",
+			sla:     &SLA{Name: "new", RuleID: ruleID},
+			wantErr: ErrSLANoTarget,
+		}, {
+			name:    "Negative duration",
+			sla:     &SLA{Name: "new", RuleID: ruleID, MaxDuration: -time.Hour},
+			wantErr: ErrSLAInvalid,
+		}, {
+			name:    "Invalid deadline",
+			sla:     &SLA{Name: "new", RuleID: ruleID, Deadline: "7h"},
+			wantErr: ErrSLAInvalid,
+		}, {
+			name: "Invalid day",
+			sla: &SLA{
+				Name: "new", RuleID: ruleID, Deadline: "07:00",
+				DeadlineDays: List[string]{"someday"},
+			},
+			wantErr: ErrSLAInvalid,
+		}, {
+			name:    "Duplicate name",
+			sla:     &SLA{Name: "existing", RuleID: ruleID, MaxDuration: time.Hour},
+			wantErr: ErrSLADuplicate,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			if test.wantErr == nil {
+				require.NoError(t, test.sla.BeforeWrite(db))
+			} else {
+				require.ErrorIs(t, test.sla.BeforeWrite(db), test.wantErr)
+			}
+		})
+	}
+
+	t.Run("Default deadline days", func(t *testing.T) {
+		t.Parallel()
+
+		sla := &SLA{Name: "business", RuleID: ruleID, Deadline: "07:00"}
+		require.NoError(t, sla.BeforeWrite(db))
+		assert.Equal(t, List[string]{"monday", "tuesday", "wednesday", "thursday", "friday"},
+			sla.DeadlineDays)
+	})
+}
+
+func TestSLADeadlineOn(t *testing.T) {
+	t.Parallel()
+
+	sla := &SLA{Deadline: "07:30", DeadlineDays: List[string]{"monday", "friday"}}
+
+	monday := time.Date(2026, time.October, 19, 15, 0, 0, 0, time.UTC)
+	deadline, ok := sla.DeadlineOn(monday)
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2026, time.October, 19, 7, 30, 0, 0, time.UTC), deadline)
+
+	_, ok = sla.DeadlineOn(monday.AddDate(0, 0, 1))
+	assert.False(t, ok, "there should be no deadline on tuesdays")
+
+	_, ok = (&SLA{MaxDuration: time.Hour}).DeadlineOn(monday)
+	assert.False(t, ok, "there should be no deadline when the SLA has none")
+}
@@ -57,10 +57,21 @@ type (
 	Users               = Slice[*User]
 	NormalizedTransfers = Slice[*NormalizedTransferView]
 	CloudInstances      = Slice[*CloudInstance]
+	MessageBrokers      = Slice[*MessageBroker]
 	Authorities         = Slice[*Authority]
 	Hosts               = Slice[*Host]
 	CryptoKeys          = Slice[*CryptoKey]
 	EmailTemplates      = Slice[*EmailTemplate]
 	SMTPCredentials     = Slice[*SMTPCredential]
 	FileWatchers        = Slice[*FileWatcher]
+	Scripts             = Slice[*Script]
+	Copybooks           = Slice[*Copybook]
+	RunningTransfers    = Slice[*RunningTransfer]
+	ClusterNodes        = Slice[*ClusterNode]
+	RetryPolicies       = Slice[*RetryPolicy]
+	SLAs                = Slice[*SLA]
+	SLABreaches         = Slice[*SLABreach]
+	ExpectedFiles       = Slice[*ExpectedFile]
+	ExpectedFileChecks  = Slice[*ExpectedFileCheck]
+	AuditEntries        = Slice[*AuditEntry]
 )
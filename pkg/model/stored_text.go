@@ -0,0 +1,56 @@
+package model
+
+import (
+	"fmt"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+)
+
+// StoredText is the constraint satisfied by the models consisting of a unique
+// name and a text content (scripts and copybooks). These models are managed
+// the same way through the REST API and the backup files.
+type StoredText[T any] interface {
+	*T
+	storedText
+}
+
+type storedText interface {
+	database.Table
+	database.Identifier
+
+	// TextFields returns pointers to the name and the content of the entry.
+	TextFields() (name, content *string)
+}
+
+// storedTextErrors are the validation errors returned by checkStoredText.
+type storedTextErrors struct {
+	noName, noContent, invalid, duplicate error
+}
+
+// checkStoredText checks that the given entry has a unique name and a non-empty
+// content, which is then parsed with the given function.
+func checkStoredText(db database.ReadAccess, text storedText, errs *storedTextErrors,
+	parse func(name, content string) error,
+) error {
+	name, content := text.TextFields()
+
+	if *name == "" {
+		return errs.noName
+	}
+
+	if *content == "" {
+		return errs.noContent
+	}
+
+	if err := parse(*name, *content); err != nil {
+		return fmt.Errorf("%w: %w", errs.invalid, err)
+	}
+
+	if n, err := db.Count(text).Where("id<>? AND name=?", text.GetID(), *name).Run(); err != nil {
+		return fmt.Errorf("failed to check for duplicate %ss: %w", text.Appellation(), err)
+	} else if n != 0 {
+		return fmt.Errorf("%w: %q", errs.duplicate, *name)
+	}
+
+	return nil
+}
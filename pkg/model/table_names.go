@@ -5,27 +5,38 @@ import "code.waarp.fr/apps/gateway/gateway/pkg/database"
 // These are the constants defining the names of the database tables associated
 // with the models.
 const (
-	TableCredentials     = "credentials"
-	TableHistory         = "transfer_history"
-	TableLocAccounts     = "local_accounts"
-	TableLocAgents       = "local_agents"
-	TableRemAccounts     = "remote_accounts"
-	TableRemAgents       = "remote_agents"
-	TableClients         = "clients"
-	TableRules           = "rules"
-	TableRuleAccesses    = "rule_access"
-	TableTasks           = "tasks"
-	TableTransfers       = "transfers"
-	TableTransferInfo    = "transfer_info"
-	TableFileInfo        = "file_info"
-	TableUsers           = "users"
-	TableCloudInstances  = "cloud_instances"
-	TableAuthorities     = "auth_authorities"
-	TableAuthHosts       = "authority_hosts"
-	TableCryptoKeys      = "crypto_keys"
-	TableEmailTemplates  = "email_templates"
-	TableSMTPCredentials = "smtp_credentials"
-	TableFileWatchers    = "file_watchers"
+	TableCredentials        = "credentials"
+	TableHistory            = "transfer_history"
+	TableLocAccounts        = "local_accounts"
+	TableLocAgents          = "local_agents"
+	TableRemAccounts        = "remote_accounts"
+	TableRemAgents          = "remote_agents"
+	TableClients            = "clients"
+	TableRules              = "rules"
+	TableRuleAccesses       = "rule_access"
+	TableTasks              = "tasks"
+	TableTransfers          = "transfers"
+	TableTransferInfo       = "transfer_info"
+	TableFileInfo           = "file_info"
+	TableUsers              = "users"
+	TableCloudInstances     = "cloud_instances"
+	TableAuthorities        = "auth_authorities"
+	TableAuthHosts          = "authority_hosts"
+	TableCryptoKeys         = "crypto_keys"
+	TableEmailTemplates     = "email_templates"
+	TableSMTPCredentials    = "smtp_credentials"
+	TableFileWatchers       = "file_watchers"
+	TableMessageBrokers     = "message_brokers"
+	TableScripts            = "scripts"
+	TableCopybooks          = "copybooks"
+	TableRunningTransfers   = "running_transfers"
+	TableClusterNodes       = "cluster_nodes"
+	TableRetryPolicies      = "retry_policies"
+	TableSLAs               = "slas"
+	TableSLABreaches        = "sla_breaches"
+	TableExpectedFiles      = "expected_files"
+	TableExpectedFileChecks = "expected_file_checks"
+	TableAuditLogs          = "audit_logs"
 
 	ViewNormalizedTransfers    = "normalized_transfers"
 	ViewNormalizedTransferInfo = "normalized_transfer_info"
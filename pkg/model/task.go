@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"code.waarp.fr/apps/gateway/gateway/pkg/database"
 	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/condition"
 )
 
 // ValidTasks is a list of all the tasks known by the gateway.
@@ -54,6 +56,20 @@ const (
 	ChainError Chain = "ERROR" // ChainError is the chain for error transfer tasks.
 )
 
+const maxParallelGroupLen = 100
+
+// OnError represents the behavior of a task chain when one of its tasks fails.
+type OnError string
+
+const (
+	// OnErrorAbort stops the chain and fails the transfer (default).
+	OnErrorAbort OnError = "abort"
+	// OnErrorContinue ignores the error and executes the next task.
+	OnErrorContinue OnError = "continue"
+	// OnErrorSkip ignores the error and skips the remaining tasks of the chain.
+	OnErrorSkip OnError = "skip-remaining"
+)
+
 // Task represents one record of the 'tasks' table.
 type Task struct {
 	RuleID int64       `gorm:"column:rule_id"`
@@ -61,6 +77,22 @@ type Task struct {
 	Rank   int8        `gorm:"column:rank"`
 	Type   string      `gorm:"column:type"`
 	Args   Map[string] `gorm:"column:args;serializer:json"`
+
+	// Condition is an optional condition which must be met by the transfer
+	// for the task to be executed (see the condition package for the syntax).
+	Condition string  `gorm:"column:condition"`
+	OnError   OnError `gorm:"column:on_error"`
+
+	// Retries is the number of times the task is retried after a failure.
+	// The delay between 2 attempts starts at RetryDelay, and doubles after
+	// each attempt.
+	Retries    int8          `gorm:"column:retries"`
+	RetryDelay time.Duration `gorm:"column:retry_delay"`
+	// Timeout is the maximum duration of an attempt (0 means no limit).
+	Timeout time.Duration `gorm:"column:timeout"`
+	// ParallelGroup is the name of the group of the task. Consecutive tasks
+	// of a chain belonging to the same group are executed concurrently.
+	ParallelGroup string `gorm:"column:parallel_group"`
 }
 
 func (*Task) TableName() string   { return TableTasks }
@@ -80,6 +112,14 @@ func (t *Task) validateTasks(db database.ReadAccess) error {
 		return database.NewValidationErrorf("%q is not a valid task Type", t.Type)
 	}
 
+	if _, err := condition.Parse(t.Condition); err != nil {
+		return database.NewValidationErrorf("invalid task condition: %w", err)
+	}
+
+	if err := t.validateExecution(); err != nil {
+		return err
+	}
+
 	switch validator := runner.(type) {
 	case TaskValidator:
 		if err := validator.Validate(t.Args); err != nil {
@@ -95,6 +135,42 @@ func (t *Task) validateTasks(db database.ReadAccess) error {
 	return nil
 }
 
+// validateExecution checks the task's execution parameters (error behavior,
+// retries, timeout & parallel group).
+func (t *Task) validateExecution() error {
+	switch t.OnError {
+	case "", OnErrorAbort, OnErrorContinue, OnErrorSkip:
+	default:
+		return database.NewValidationErrorf("%q is not a valid task error behavior "+
+			"(valid values are %q, %q and %q)", t.OnError, OnErrorAbort, OnErrorContinue, OnErrorSkip)
+	}
+
+	if t.Retries < 0 {
+		return database.NewValidationError("the task's number of retries cannot be negative")
+	}
+
+	if t.RetryDelay < 0 {
+		return database.NewValidationError("the task's retry delay cannot be negative")
+	}
+
+	if t.Timeout < 0 {
+		return database.NewValidationError("the task's timeout cannot be negative")
+	}
+
+	if len(t.ParallelGroup) > maxParallelGroupLen {
+		return database.NewValidationErrorf("the task's parallel group name cannot be "+
+			"longer than %d characters", maxParallelGroupLen)
+	}
+
+	return nil
+}
+
+// HasExecutionOptions returns whether the task has any specific execution
+// parameters (retries, timeout or parallel group).
+func (t *Task) HasExecutionOptions() bool {
+	return t.Retries > 0 || t.Timeout > 0 || t.ParallelGroup != ""
+}
+
 // BeforeWrite checks if the new `Task` entry is valid and can be
 // inserted in the database.
 func (t *Task) BeforeWrite(db database.Access) error {
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	. "github.com/smartystreets/goconvey/convey"
 
@@ -117,6 +118,83 @@ func TestTaskBeforeInsert(t *testing.T) {
 				})
 			})
 
+			Convey("Given a task with an invalid condition", func() {
+				t2 := &Task{
+					RuleID:    r.ID,
+					Chain:     ChainPost,
+					Rank:      0,
+					Type:      "TESTSUCCESS",
+					Condition: `filename ==`,
+				}
+
+				Convey("When calling the `BeforeWrite` method", func() {
+					err := t2.BeforeWrite(db)
+
+					Convey("Then the error should say that the condition is invalid", func() {
+						So(err, ShouldBeError)
+						So(err.Error(), ShouldStartWith, "invalid task condition")
+					})
+				})
+			})
+
+			Convey("Given a task with an invalid error behavior", func() {
+				t2 := &Task{
+					RuleID:  r.ID,
+					Chain:   ChainPost,
+					Rank:    0,
+					Type:    "TESTSUCCESS",
+					OnError: "retry",
+				}
+
+				Convey("When calling the `BeforeWrite` method", func() {
+					err := t2.BeforeWrite(db)
+
+					Convey("Then the error should say that the error behavior is invalid", func() {
+						So(err, ShouldBeError, database.NewValidationErrorf(
+							"%q is not a valid task error behavior (valid values are %q, %q and %q)",
+							t2.OnError, OnErrorAbort, OnErrorContinue, OnErrorSkip))
+					})
+				})
+			})
+
+			Convey("Given a task with a negative timeout", func() {
+				t2 := &Task{
+					RuleID:  r.ID,
+					Chain:   ChainPost,
+					Rank:    0,
+					Type:    "TESTSUCCESS",
+					Timeout: -time.Second,
+				}
+
+				Convey("When calling the `BeforeWrite` method", func() {
+					err := t2.BeforeWrite(db)
+
+					Convey("Then the error should say that the timeout is invalid", func() {
+						So(err, ShouldBeError, database.NewValidationError(
+							"the task's timeout cannot be negative"))
+					})
+				})
+			})
+
+			Convey("Given a valid task with a condition", func() {
+				t2 := &Task{
+					RuleID:        r.ID,
+					Chain:         ChainPost,
+					Rank:          0,
+					Type:          "TESTSUCCESS",
+					Condition:     `filename matches "*.csv" && filesize > 1M`,
+					OnError:       OnErrorContinue,
+					Retries:       3,
+					RetryDelay:    time.Second,
+					Timeout:       time.Minute,
+					ParallelGroup: "group",
+				}
+
+				Convey("Then the `BeforeWrite` method should not return an error", func() {
+					So(t2.BeforeWrite(db), ShouldBeNil)
+				})
+			})
+
 			Convey("Given a task which would overwrite another task", func() {
 				t2 := Task{
 					RuleID: t.RuleID,
@@ -2,6 +2,7 @@ package model
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"maps"
 	"path"
@@ -16,6 +17,8 @@ import (
 	"code.waarp.fr/apps/gateway/gateway/pkg/utils"
 )
 
+var ErrTransferLeaseLost = errors.New("the node has lost its lease on the transfer")
+
 // UnknownSize defines the value given to Transfer.Filesize when the file size is
 // unknown.
 const UnknownSize int64 = -1
@@ -48,6 +51,10 @@ type Transfer struct {
 	NextRetry            time.Time               `gorm:"column:next_retry;type:timestamp;serializer:timestamp"`
 	Infos                TransferInfos           `gorm:"foreignKey:TransferID"`
 	TransferInfo         map[string]any          `gorm:"-"`
+
+	// leaseNode, if not empty, is the cluster node which must hold the lease
+	// on the transfer for the transfer's entry to be updated.
+	leaseNode string
 }
 
 func (*Transfer) TableName() string   { return TableTransfers }
@@ -58,6 +65,25 @@ func (*Transfer) Preloads() []string { return []string{"Infos"} }
 
 func (t *Transfer) IsServer() bool { return t.LocalAccountID.Valid }
 
+// RequireLease makes the updates of the transfer's entry conditional on the
+// given cluster node holding the lease on the transfer (meaning that the
+// transfer's RunningTransfer entry belongs to the node). Once the node has lost
+// its lease, the updates fail with ErrTransferLeaseLost. An empty node removes
+// the condition.
+func (t *Transfer) RequireLease(node string) { t.leaseNode = node }
+
+// UpdateGuard implements database.GuardedUpdateBean.
+func (t *Transfer) UpdateGuard() (cond string, args []any, err error) {
+	if t.leaseNode == "" {
+		return "", nil, nil
+	}
+
+	return "EXISTS (SELECT 1 FROM " + TableRunningTransfers + " WHERE " +
+			TableRunningTransfers + ".transfer_id=" + TableTransfers + ".id AND " +
+			TableRunningTransfers + ".node=?)",
+		[]any{t.leaseNode}, ErrTransferLeaseLost
+}
+
 //nolint:funlen,gocyclo,cyclop //function is fine for now
 func (t *Transfer) checkMandatoryValues(rule *Rule, protocol string) error {
 	if t.IsServer() {
@@ -158,7 +184,8 @@ func (t *Transfer) BeforeWrite(db database.Access) error {
 	case t.LocalAccountID.Valid && t.RemoteAccountID.Valid:
 		return database.NewValidationError("the transfer cannot have both a local and remote account ID")
 	case t.RemoteAccountID.Valid:
-		if err := db.Get(&RemoteAccount{}, "id=?", t.RemoteAccountID.Int64).Run(); err != nil {
+		var account RemoteAccount
+		if err := db.Get(&account, "id=?", t.RemoteAccountID.Int64).Run(); err != nil {
 			if database.IsNotFound(err) {
 				return database.NewValidationErrorf("the remote account %d does not exist",
 					t.RemoteAccountID.Int64)
@@ -181,7 +208,10 @@ func (t *Transfer) BeforeWrite(db database.Access) error {
 			return fmt.Errorf("failed to retrieve client: %w", err)
 		}
 
-		t.setRetryParameters(&client)
+		if err := t.setRetryParameters(db, &client, &rule, &account); err != nil {
+			return err
+		}
+
 		protocol = client.Protocol
 	case t.LocalAccountID.Valid:
 		acc := LocalAccount{}
@@ -194,7 +224,10 @@ func (t *Transfer) BeforeWrite(db database.Access) error {
 			return fmt.Errorf("failed to retrieve local account: %w", err)
 		}
 
-		t.clearRetryParameters()
+		if err := t.setServerRetryParameters(db, &rule); err != nil {
+			return err
+		}
+
 		protocol = acc.LocalAgent.Protocol
 	default:
 		return database.NewValidationError("the transfer is missing an account ID")
@@ -224,11 +257,15 @@ func (t *Transfer) AfterInsert(db database.Access) error {
 		return fmt.Errorf("failed to insert transfer info: %w", err)
 	}
 
+	t.notifyIfReady(db)
+
 	return nil
 }
 
 func (t *Transfer) AfterUpdate(db database.Access) error {
 	if reflect.DeepEqual(t.TransferInfo, t.Infos.asMap()) {
+		t.notifyIfReady(db)
+
 		return nil
 	}
 
@@ -246,10 +283,27 @@ func (t *Transfer) AfterUpdate(db database.Access) error {
 			return fmt.Errorf("failed to insert transfer info: %w", err)
 		}
 
+		t.notifyIfReady(db)
+
 		return nil
 	})
 }
 
+// notifyIfReady signals the transfer controller when the transfer is a planned
+// client transfer ready to be executed, so that it can be started right away
+// instead of waiting for the controller's next database poll.
+func (t *Transfer) notifyIfReady(db database.Access) {
+	if t.IsServer() || t.Status != types.StatusPlanned {
+		return
+	}
+
+	if now := time.Now(); t.Start.After(now) || t.NextRetry.After(now) {
+		return
+	}
+
+	db.Notify(database.TransfersChannel)
+}
+
 func (t *Transfer) AfterRead(database.ReadAccess) error {
 	t.TransferInfo = t.Infos.asMap()
 
@@ -387,18 +441,43 @@ func (t *Transfer) MoveToHistory(db database.Access, logger *log.Logger, end tim
 	return nil
 }
 
-func (t *Transfer) setRetryParameters(client *Client) {
+// setRetryParameters sets the default retry parameters of a new client transfer.
+// If the transfer has a retry policy (see GetRetryPolicy), the policy's
+// parameters are used instead of the client's.
+func (t *Transfer) setRetryParameters(db database.ReadAccess, client *Client, rule *Rule,
+	account *RemoteAccount,
+) error {
 	if t.ID == 0 {
+		var partner RemoteAgent
+		if err := db.Get(&partner, "id=?", account.RemoteAgentID).Run(); err != nil {
+			return fmt.Errorf("failed to retrieve partner: %w", err)
+		}
+
+		policy, err := GetRetryPolicy(db, rule, &partner)
+		if err != nil {
+			return err
+		}
+
+		attempts := client.NbOfAttempts
+		delay := client.FirstRetryDelay
+		factor := client.RetryIncrementFactor
+
+		if policy != nil {
+			attempts = policy.MaxAttempts
+			delay = policy.delaySeconds()
+			factor = policy.IncrementFactor
+		}
+
 		if t.RemainingTries == 0 {
-			t.RemainingTries = client.NbOfAttempts
+			t.RemainingTries = attempts
 		}
 
 		if t.NextRetryDelay == 0 {
-			t.NextRetryDelay = client.FirstRetryDelay
+			t.NextRetryDelay = delay
 		}
 
 		if t.RetryIncrementFactor == 0 {
-			t.RetryIncrementFactor = client.RetryIncrementFactor
+			t.RetryIncrementFactor = factor
 		}
 	}
 
@@ -413,6 +492,34 @@ func (t *Transfer) setRetryParameters(client *Client) {
 	if t.Status == types.StatusPlanned && t.NextRetry.IsZero() {
 		t.NextRetry = t.Start
 	}
+
+	return nil
+}
+
+// setServerRetryParameters sets the retry parameters of a server transfer. Server
+// transfers are never retried by the gateway itself, but when the rule has a
+// retry policy, the number of remaining attempts is kept to determine whether
+// the partner is expected to retry the transfer after an error.
+func (t *Transfer) setServerRetryParameters(db database.ReadAccess, rule *Rule) error {
+	if rule.RetryPolicy == "" {
+		t.clearRetryParameters()
+
+		return nil
+	}
+
+	if t.ID == 0 && t.RemainingTries == 0 {
+		policy, err := GetRetryPolicy(db, rule, nil)
+		if err != nil {
+			return err
+		}
+
+		t.RemainingTries = policy.MaxAttempts
+	}
+
+	t.NextRetryDelay = 0
+	t.RetryIncrementFactor = 0
+
+	return nil
 }
 
 func (t *Transfer) clearRetryParameters() {
@@ -33,6 +33,12 @@ type TransferContext struct {
 
 	Paths       *conf.PathsConfig
 	Authorities Authorities
+
+	// FinalAttempt indicates, once the transfer has failed, that no new attempt
+	// will be made for the transfer (either by the gateway or, for server
+	// transfers, by the partner) according to its retry policy. It is meant to
+	// be used by the error-tasks.
+	FinalAttempt bool
 }
 
 // GetTransferContext retrieves all the information regarding the given transfer
@@ -134,6 +140,12 @@ func makeLocalAgentContext(db *database.DB, logger *log.Logger, transCtx *Transf
 		return nil, fmt.Errorf("failed to retrieve server certificates: %w", err)
 	}
 
+	if err = ResolveCredentialsSecrets(transCtx.LocalAgentCreds); err != nil {
+		logger.Errorf("Failed to resolve server auth methods: %v", err)
+
+		return nil, fmt.Errorf("failed to resolve server credentials: %w", err)
+	}
+
 	return transCtx, nil
 }
 
@@ -165,6 +177,12 @@ func makeRemoteAgentContext(db *database.DB, logger *log.Logger, transCtx *Trans
 		return nil, fmt.Errorf("failed to retrieve remote account certificates: %w", err)
 	}
 
+	if err = ResolveCredentialsSecrets(transCtx.RemoteAccountCreds); err != nil {
+		logger.Errorf("Failed to resolve remote account auth methods: %v", err)
+
+		return nil, fmt.Errorf("failed to resolve remote account credentials: %w", err)
+	}
+
 	if transCtx.RemoteAgentCreds, err = transCtx.RemoteAgent.GetCredentials(db); err != nil {
 		logger.Errorf("Failed to retrieve partner auth methods: %v", err)
 
@@ -24,6 +24,11 @@ const (
 
 	SyncTransferID   = "__syncTransferID__"
 	SyncTransferRank = "__syncTransferRank__"
+
+	// TaskAttempts defines the name of the transfer info value containing the
+	// results of the attempts of the tasks with specific execution parameters
+	// (retries, timeout or parallel group).
+	TaskAttempts = "__taskAttempts__"
 )
 
 // TransferInfo represents the transfer_info database table, which contains all the
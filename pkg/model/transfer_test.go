@@ -1,6 +1,7 @@
 package model
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -498,3 +499,92 @@ func TestTransferResume(t *testing.T) {
 		assert.Equal(t, expected, actual)
 	})
 }
+
+func TestTransferNotify(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	notifications := db.Listen(ctx, database.TransfersChannel)
+
+	isNotified := func() bool {
+		select {
+		case <-notifications:
+			return true
+		default:
+			return false
+		}
+	}
+
+	rule := Rule{Name: "rule", IsSend: true}
+	require.NoError(t, db.Insert(&rule).Run())
+
+	client := Client{Name: "client", Protocol: testProtocol}
+	require.NoError(t, db.Insert(&client).Run())
+
+	partner := RemoteAgent{Name: "partner", Protocol: testProtocol, Address: types.Addr("localhost", 0)}
+	require.NoError(t, db.Insert(&partner).Run())
+
+	remAccount := RemoteAccount{RemoteAgentID: partner.ID, Login: "toto"}
+	require.NoError(t, db.Insert(&remAccount).Run())
+
+	server := LocalAgent{Name: "server", Protocol: testProtocol, Address: types.Addr("localhost", 0)}
+	require.NoError(t, db.Insert(&server).Run())
+
+	locAccount := LocalAccount{LocalAgentID: server.ID, Login: "toto"}
+	require.NoError(t, db.Insert(&locAccount).Run())
+
+	t.Run("New planned client transfer", func(t *testing.T) {
+		trans := &Transfer{
+			RuleID:          rule.ID,
+			ClientID:        client.NullableID(),
+			RemoteAccountID: remAccount.NullableID(),
+			SrcFilename:     "file1.txt",
+		}
+		require.NoError(t, db.Insert(trans).Run())
+
+		assert.True(t, isNotified(), "The controller should have been notified")
+
+		t.Run("When the transfer is updated while running", func(t *testing.T) {
+			trans.Status = types.StatusRunning
+			require.NoError(t, db.Update(trans).Cols("status").Run())
+
+			assert.False(t, isNotified(), "The controller should not have been notified")
+		})
+
+		t.Run("When the transfer is resumed", func(t *testing.T) {
+			trans.Status = types.StatusPaused
+			require.NoError(t, db.Update(trans).Cols("status").Run())
+			require.NoError(t, trans.Resume(db, time.Now()))
+
+			assert.True(t, isNotified(), "The controller should have been notified")
+		})
+	})
+
+	t.Run("New client transfer planned in the future", func(t *testing.T) {
+		trans := &Transfer{
+			RuleID:          rule.ID,
+			ClientID:        client.NullableID(),
+			RemoteAccountID: remAccount.NullableID(),
+			SrcFilename:     "file2.txt",
+			Start:           time.Now().Add(time.Hour),
+		}
+		require.NoError(t, db.Insert(trans).Run())
+
+		assert.False(t, isNotified(), "The controller should not have been notified")
+	})
+
+	t.Run("New server transfer", func(t *testing.T) {
+		trans := &Transfer{
+			RuleID:         rule.ID,
+			LocalAccountID: locAccount.NullableID(),
+			SrcFilename:    "file3.txt",
+		}
+		require.NoError(t, db.Insert(trans).Run())
+
+		assert.False(t, isNotified(), "The controller should not have been notified")
+	})
+}
@@ -0,0 +1,185 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/types"
+)
+
+// registerNode records, in a clustered deployment, that the pipeline's transfer
+// is executed by the current node. This allows the other nodes of the cluster
+// to know where the transfer is running, and to send it commands.
+//
+// The transfer limits being cluster-wide, the registration fails with
+// ErrLimitReached if the cluster is already running as many transfers as
+// allowed. Since a transfer which is not registered would escape both the
+// limits and the other nodes' control, any other registration failure also
+// prevents the transfer from starting.
+//
+// Once registered, the updates of the transfer's entry are conditioned on the
+// node keeping its lease on the transfer, so that a node which lost its lease
+// cannot overwrite the entry of a transfer taken over by another node.
+func (p *Pipeline) registerNode() *Error {
+	node := p.DB.Config.NodeID
+	if node == "" {
+		return nil
+	}
+
+	transID := p.TransCtx.Transfer.ID
+	isServer := p.TransCtx.Transfer.IsServer()
+	limit := List.getLimit(isServer)
+	errLimit := errors.New("cluster limit reached")
+
+	if err := p.DB.Transaction(func(ses *database.Session) error {
+		// Remove any leftover entry from a previous execution of the transfer.
+		if err := ses.DeleteAll(&model.RunningTransfer{}).Where("transfer_id=?",
+			transID).Run(); err != nil {
+			return fmt.Errorf("failed to delete the old running transfer entry: %w", err)
+		}
+
+		if limit != NoLimit {
+			// Locking the node registry serializes the registrations made by
+			// the different nodes, so that they cannot exceed the limit
+			// by counting the running transfers at the same time.
+			var nodes model.ClusterNodes
+			if err := ses.SelectForUpdate(&nodes).OrderBy("id", true).Run(); err != nil {
+				return fmt.Errorf("failed to lock the cluster's node registry: %w", err)
+			}
+
+			count, err := model.CountClusterTransfers(ses, isServer)
+			if err != nil {
+				return err //nolint:wrapcheck //already wrapped
+			}
+
+			if count >= limit {
+				return errLimit
+			}
+		}
+
+		now := time.Now()
+		if err := ses.Insert(&model.RunningTransfer{
+			TransferID:  transID,
+			Node:        node,
+			Since:       now,
+			IsServer:    isServer,
+			LeaseExpiry: now.Add(model.NodeLease(p.DB)),
+		}).Run(); err != nil {
+			return fmt.Errorf("failed to insert the running transfer entry: %w", err)
+		}
+
+		return nil
+	}); errors.Is(err, errLimit) {
+		p.Logger.Warningf("Cannot start the transfer, the cluster-wide limit of %d "+
+			"transfers has been reached", limit)
+
+		return ErrLimitReached
+	} else if err != nil {
+		p.Logger.Errorf("Cannot start the transfer, failed to register it on node %q: %v",
+			node, err)
+
+		return NewErrorWith(err, types.TeInternal, "failed to register the transfer on the cluster node")
+	}
+
+	p.registered.Store(true)
+	p.TransCtx.Transfer.RequireLease(node)
+
+	return nil
+}
+
+// unregisterNode removes the entry created by registerNode once the transfer
+// is no longer running on the current node. The lease condition on the
+// transfer's updates is lifted, unless the lease was lost.
+func (p *Pipeline) unregisterNode() {
+	node := p.DB.Config.NodeID
+	if node == "" {
+		return
+	}
+
+	if !p.fenced.Load() {
+		p.TransCtx.Transfer.RequireLease("")
+	}
+
+	if err := p.DB.DeleteAll(&model.RunningTransfer{}).Where("transfer_id=? AND node=?",
+		p.TransCtx.Transfer.ID, node).Run(); err != nil {
+		p.Logger.Warningf("Failed to unregister the transfer from node %q: %v", node, err)
+	}
+}
+
+// Fence stops the pipeline after the current node has lost its lease on the
+// transfer (because the node failed to renew it in time, and the transfer was
+// taken over by another node). Unlike Interrupt, the transfer's database entry
+// is left untouched, since it now belongs to another node.
+func (p *Pipeline) Fence(ctx context.Context) error {
+	p.fenced.Store(true)
+
+	return p.halt(ctx, errFenced, types.StatusInterrupted, p.interruption.Interrupt)
+}
+
+// LostLeases returns the pipelines running on the current node which have lost
+// their lease, meaning that their running transfer entry has been deleted, or
+// now belongs to another node of the cluster.
+func (l *list) LostLeases(db database.ReadAccess, node string) ([]*Pipeline, error) {
+	// The pipelines must be listed before the running transfer entries, so
+	// that a pipeline registered in the meantime cannot be mistaken for one
+	// which has lost its lease.
+	l.mutex.RLock()
+
+	pips := make([]*Pipeline, 0, len(l.m))
+	for _, pip := range l.m {
+		if pip.registered.Load() {
+			pips = append(pips, pip)
+		}
+	}
+
+	l.mutex.RUnlock()
+
+	if len(pips) == 0 {
+		return nil, nil
+	}
+
+	var entries model.RunningTransfers
+	if err := db.Select(&entries).Where("node=?", node).Run(); err != nil {
+		return nil, fmt.Errorf("failed to retrieve the node's running transfers: %w", err)
+	}
+
+	owned := make(map[int64]bool, len(entries))
+	for _, entry := range entries {
+		owned[entry.TransferID] = true
+	}
+
+	var lost []*Pipeline
+
+	for _, pip := range pips {
+		if !owned[pip.TransCtx.Transfer.ID] {
+			lost = append(lost, pip)
+		}
+	}
+
+	return lost, nil
+}
+
+// GetClusterAvailableOut returns the number of client transfers which can
+// still be started on the whole cluster before reaching the outgoing transfer
+// limit.
+func GetClusterAvailableOut(db database.ReadAccess) (uint64, error) {
+	limit := List.getLimit(false)
+	if limit == NoLimit {
+		return NoLimit, nil
+	}
+
+	count, err := model.CountClusterTransfers(db, false)
+	if err != nil {
+		return 0, err //nolint:wrapcheck //already wrapped
+	}
+
+	if count >= limit {
+		return 0, nil
+	}
+
+	return limit - count, nil
+}
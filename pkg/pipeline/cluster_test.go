@@ -0,0 +1,168 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/types"
+)
+
+func TestPipelineClusterRegistration(t *testing.T) {
+	root := t.TempDir()
+
+	Convey("Given a clustered gateway", t, func(c C) {
+		ctx := initTestDB(c, root)
+		So(ctx.db.Config.NodeID, ShouldNotBeBlank)
+
+		trans := mkSendTransfer(ctx, "file")
+
+		transCtx, err := model.GetTransferContext(ctx.db, ctx.logger, trans)
+		So(err, ShouldBeNil)
+
+		Convey("When initiating a new pipeline for a transfer", func(c C) {
+			pip, err := NewClientPipeline(ctx.db, ctx.logger, transCtx, nil)
+			So(err, ShouldBeNil)
+			resetPip(pip)
+
+			Convey("Then the transfer should be registered on the current node", func(c C) {
+				node, err := model.GetTransferNode(ctx.db, trans.ID)
+				So(err, ShouldBeNil)
+				So(node, ShouldEqual, ctx.db.Config.NodeID)
+			})
+
+			Convey("Then the pipeline should not have lost its lease", func(c C) {
+				lost, err := List.LostLeases(ctx.db, ctx.db.Config.NodeID)
+				So(err, ShouldBeNil)
+				So(lost, ShouldBeEmpty)
+			})
+
+			Convey("When the transfer is taken over by another node", func(c C) {
+				So(ctx.db.Exec("UPDATE running_transfers SET node=? WHERE transfer_id=?",
+					"other_node", trans.ID), ShouldBeNil)
+				So(ctx.db.Exec("UPDATE transfers SET status=?, progress=? WHERE id=?",
+					types.StatusRunning, 100, trans.ID), ShouldBeNil)
+
+				Convey("Then the pipeline should have lost its lease", func(c C) {
+					lost, err := List.LostLeases(ctx.db, ctx.db.Config.NodeID)
+					So(err, ShouldBeNil)
+					So(lost, ShouldResemble, []*Pipeline{pip})
+
+					Convey("When the pipeline updates the transfer", func(c C) {
+						pip.TransCtx.Transfer.Progress = 50
+
+						Convey("Then the update should be refused", func(c C) {
+							err := ctx.db.Update(pip.TransCtx.Transfer).Run()
+							So(errors.Is(err, model.ErrTransferLeaseLost), ShouldBeTrue)
+							So(pip.forceUpdateTrans(), ShouldEqual, errFenced)
+
+							var check model.Transfer
+							So(ctx.db.Get(&check, "id=?", trans.ID).Run(), ShouldBeNil)
+							So(check.Progress, ShouldEqual, 100)
+						})
+					})
+
+					Convey("When fencing the pipeline", func(c C) {
+						So(pip.Fence(context.Background()), ShouldBeNil)
+
+						Convey("Then the pipeline should have been stopped", func(c C) {
+							So(List.Exists(trans.ID), ShouldBeFalse)
+						})
+
+						Convey("Then the transfer should have been left untouched", func(c C) {
+							var check model.Transfer
+							So(ctx.db.Get(&check, "id=?", trans.ID).Run(), ShouldBeNil)
+							So(check.Status, ShouldEqual, types.StatusRunning)
+							So(check.Progress, ShouldEqual, 100)
+
+							node, err := model.GetTransferNode(ctx.db, trans.ID)
+							So(err, ShouldBeNil)
+							So(node, ShouldEqual, "other_node")
+						})
+					})
+				})
+			})
+
+			Convey("When the pipeline ends", func(c C) {
+				pip.doneOK()
+
+				Convey("Then the transfer should no longer be registered", func(c C) {
+					node, err := model.GetTransferNode(ctx.db, trans.ID)
+					So(err, ShouldBeNil)
+					So(node, ShouldBeBlank)
+				})
+			})
+		})
+
+		Convey("Given that the cluster reached its outgoing transfer limit", func(c C) {
+			List.SetLimits(0, 1)
+			defer List.SetLimits(0, 0)
+
+			other := mkSendTransfer(ctx, "other_file")
+			So(ctx.db.Insert(&model.RunningTransfer{
+				TransferID:  other.ID,
+				Node:        "other_node",
+				LeaseExpiry: time.Now().Add(time.Minute),
+			}).Run(), ShouldBeNil)
+
+			Convey("Then no more client transfers should be available", func(c C) {
+				available, err := GetClusterAvailableOut(ctx.db)
+				So(err, ShouldBeNil)
+				So(available, ShouldBeZeroValue)
+			})
+
+			Convey("When initiating a new pipeline for a transfer", func(c C) {
+				_, err := NewClientPipeline(ctx.db, ctx.logger, transCtx, nil)
+
+				Convey("Then it should return an error", func(c C) {
+					So(err, ShouldBeError, ErrLimitReached)
+				})
+
+				Convey("Then the transfer should not have been started", func(c C) {
+					So(List.Exists(trans.ID), ShouldBeFalse)
+
+					node, err := model.GetTransferNode(ctx.db, trans.ID)
+					So(err, ShouldBeNil)
+					So(node, ShouldBeBlank)
+				})
+			})
+
+			Convey("When the other node's lease has expired", func(c C) {
+				So(ctx.db.Exec("UPDATE running_transfers SET lease_expiry=?",
+					time.Now().Add(-time.Minute).UTC()), ShouldBeNil)
+
+				Convey("Then its transfer should no longer be counted", func(c C) {
+					available, err := GetClusterAvailableOut(ctx.db)
+					So(err, ShouldBeNil)
+					So(available, ShouldEqual, 1)
+				})
+			})
+		})
+
+		Convey("Given a leftover registration from another node", func(c C) {
+			So(ctx.db.Insert(&model.RunningTransfer{
+				TransferID: trans.ID,
+				Node:       "old_node",
+				Command:    model.TransferCommandPause,
+			}).Run(), ShouldBeNil)
+
+			Convey("When initiating a new pipeline for the transfer", func(c C) {
+				pip, err := NewClientPipeline(ctx.db, ctx.logger, transCtx, nil)
+				So(err, ShouldBeNil)
+				resetPip(pip)
+
+				Convey("Then the registration should have been replaced", func(c C) {
+					var entries model.RunningTransfers
+					So(ctx.db.Select(&entries).Run(), ShouldBeNil)
+					So(entries, ShouldHaveLength, 1)
+					So(entries[0].Node, ShouldEqual, ctx.db.Config.NodeID)
+					So(entries[0].Command, ShouldEqual, model.TransferCommandNone)
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,215 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/types"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils"
+)
+
+// drainCheckInterval is the interval at which a drain operation checks whether
+// the drained transfers are done.
+const drainCheckInterval = 100 * time.Millisecond
+
+var ErrDraining = NewError(types.TeShuttingDown,
+	"the service is being drained, no new transfers are accepted")
+
+// drains holds the drain state of the gateway, and of its servers & clients.
+// A drained service does not accept new transfers anymore, while letting its
+// running transfers finish.
+type drains struct {
+	gateway bool
+	servers map[int64]bool
+	clients map[int64]bool
+}
+
+// DrainGateway drains the whole gateway. No new transfers will be accepted by
+// any server or client until the gateway is restarted. It returns false if the
+// gateway was already being drained.
+func (l *list) DrainGateway() bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.drains.gateway {
+		return false
+	}
+
+	l.drains.gateway = true
+
+	return true
+}
+
+// DrainServer drains the given server. No new transfers will be accepted by
+// the server until EndServerDrain is called. It returns false if the server
+// was already being drained (either directly, or because the whole gateway is).
+func (l *list) DrainServer(serverID int64) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.drains.gateway || l.drains.servers[serverID] {
+		return false
+	}
+
+	if l.drains.servers == nil {
+		l.drains.servers = map[int64]bool{}
+	}
+
+	l.drains.servers[serverID] = true
+
+	return true
+}
+
+// DrainClient drains the given client. No new transfers will be started with
+// the client until EndClientDrain is called. It returns false if the client
+// was already being drained (either directly, or because the whole gateway is).
+func (l *list) DrainClient(clientID int64) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.drains.gateway || l.drains.clients[clientID] {
+		return false
+	}
+
+	if l.drains.clients == nil {
+		l.drains.clients = map[int64]bool{}
+	}
+
+	l.drains.clients[clientID] = true
+
+	return true
+}
+
+// EndServerDrain ends the drain of the given server.
+func (l *list) EndServerDrain(serverID int64) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	delete(l.drains.servers, serverID)
+}
+
+// EndClientDrain ends the drain of the given client.
+func (l *list) EndClientDrain(clientID int64) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	delete(l.drains.clients, clientID)
+}
+
+// IsGatewayDraining returns whether the whole gateway is being drained.
+func (l *list) IsGatewayDraining() bool {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	return l.drains.gateway
+}
+
+// IsServerDraining returns whether the given server is being drained (either
+// directly, or because the whole gateway is).
+func (l *list) IsServerDraining(serverID int64) bool {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	return l.drains.gateway || l.drains.servers[serverID]
+}
+
+// IsClientDraining returns whether the given client is being drained (either
+// directly, or because the whole gateway is).
+func (l *list) IsClientDraining(clientID int64) bool {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	return l.drains.gateway || l.drains.clients[clientID]
+}
+
+// DrainingClients returns the IDs of the clients currently being drained.
+func (l *list) DrainingClients() []int64 {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	ids := make([]int64, 0, len(l.drains.clients))
+	for id := range l.drains.clients {
+		ids = append(ids, id)
+	}
+
+	return ids
+}
+
+type stateGetter interface {
+	State() (utils.StateCode, string)
+}
+
+// DrainedState returns the state which should be reported for the given server
+// (or client), taking its drain status into account.
+func DrainedState(service stateGetter, draining bool) (utils.StateCode, string) {
+	code, reason := service.State()
+	if draining && code == utils.StateRunning {
+		return utils.StateDraining, "waiting for the running transfers to end"
+	}
+
+	return code, reason
+}
+
+func (l *list) isDraining(transCtx *model.TransferContext) bool {
+	if transCtx.Transfer.IsServer() {
+		return transCtx.LocalAgent != nil && l.IsServerDraining(transCtx.LocalAgent.ID)
+	}
+
+	return transCtx.Client != nil && l.IsClientDraining(transCtx.Client.ID)
+}
+
+// WaitServer waits until all the transfers of the given server are done, or
+// until the given context expires.
+func (l *list) WaitServer(ctx context.Context, serverID int64) error {
+	return l.wait(ctx, func(t *model.TransferContext) bool {
+		return t.LocalAgent != nil && t.LocalAgent.ID == serverID
+	})
+}
+
+// WaitClient waits until all the transfers of the given client are done, or
+// until the given context expires.
+func (l *list) WaitClient(ctx context.Context, clientID int64) error {
+	return l.wait(ctx, func(t *model.TransferContext) bool {
+		return t.Client != nil && t.Client.ID == clientID
+	})
+}
+
+// WaitAll waits until all the gateway's transfers are done, or until the given
+// context expires.
+func (l *list) WaitAll(ctx context.Context) error {
+	return l.wait(ctx, func(*model.TransferContext) bool { return true })
+}
+
+func (l *list) wait(ctx context.Context, cond func(*model.TransferContext) bool) error {
+	ticker := time.NewTicker(drainCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		if l.count(cond) == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%d transfers are still running: %w", l.count(cond), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+func (l *list) count(cond func(*model.TransferContext) bool) int {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	count := 0
+
+	for _, pip := range l.m {
+		if cond(pip.TransCtx) {
+			count++
+		}
+	}
+
+	return count
+}
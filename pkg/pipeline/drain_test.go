@@ -0,0 +1,87 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils"
+)
+
+type drainTestService utils.StateCode
+
+func (d drainTestService) State() (utils.StateCode, string) { return utils.StateCode(d), "" }
+
+func TestDrainedState(t *testing.T) {
+	Convey("Given a running service", t, func() {
+		service := drainTestService(utils.StateRunning)
+
+		Convey("Then it should be reported as draining while it is drained", func() {
+			code, _ := DrainedState(service, true)
+			So(code, ShouldEqual, utils.StateDraining)
+		})
+
+		Convey("Then it should be reported as running otherwise", func() {
+			code, _ := DrainedState(service, false)
+			So(code, ShouldEqual, utils.StateRunning)
+		})
+	})
+}
+
+func TestPipelineDrain(t *testing.T) {
+	root := t.TempDir()
+
+	Convey("Given a client transfer", t, func(c C) {
+		ctx := initTestDB(c, root)
+		trans := mkSendTransfer(ctx, "file")
+
+		transCtx, err := model.GetTransferContext(ctx.db, ctx.logger, trans)
+		So(err, ShouldBeNil)
+
+		Convey("Given that the transfer's client is being drained", func(c C) {
+			So(List.DrainClient(ctx.client.ID), ShouldBeTrue)
+			defer List.EndClientDrain(ctx.client.ID)
+
+			So(List.IsClientDraining(ctx.client.ID), ShouldBeTrue)
+			So(List.DrainingClients(), ShouldContain, ctx.client.ID)
+
+			Convey("Then it should not be possible to drain the client again", func(c C) {
+				So(List.DrainClient(ctx.client.ID), ShouldBeFalse)
+			})
+
+			Convey("When initiating a new pipeline for the transfer", func(c C) {
+				_, err := NewClientPipeline(ctx.db, ctx.logger, transCtx, nil)
+
+				Convey("Then it should return an error", func(c C) {
+					So(err, ShouldBeError, ErrDraining)
+					So(List.Exists(trans.ID), ShouldBeFalse)
+				})
+			})
+		})
+
+		Convey("Given a running pipeline for the transfer", func(c C) {
+			pip, err := NewClientPipeline(ctx.db, ctx.logger, transCtx, nil)
+			So(err, ShouldBeNil)
+			resetPip(pip)
+
+			Convey("When waiting for the client's transfers to end", func(c C) {
+				waitCtx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+				defer cancel()
+
+				Convey("Then it should time out if the transfer is still running", func(c C) {
+					So(List.WaitClient(waitCtx, ctx.client.ID), ShouldWrap, context.DeadlineExceeded)
+					pip.doneOK()
+				})
+
+				Convey("Then it should return once the transfer is done", func(c C) {
+					pip.doneOK()
+					So(List.WaitClient(waitCtx, ctx.client.ID), ShouldBeNil)
+				})
+			})
+
+		})
+	})
+}
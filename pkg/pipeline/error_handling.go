@@ -64,6 +64,7 @@ func (p *Pipeline) processError(code types.TransferErrorCode, msg, extMsg string
 		p.Logger.Errorf("Failed to update transfer error: %s", dbErr)
 	}
 
+	p.planRetry()
 	p.errorTasks()
 	p.doneErr(types.StatusError)
 }
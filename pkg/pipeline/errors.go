@@ -12,6 +12,7 @@ var (
 	errPause       = NewError(types.TeStopped, "transfer paused by user")
 	errInterrupted = NewError(types.TeShuttingDown, "transfer interrupted by a service shutdown")
 	errCanceled    = NewError(types.TeCanceled, "transfer canceled by user")
+	errFenced      = NewError(types.TeShuttingDown, "transfer taken over by another node")
 )
 
 // FileErrToTransferErr takes an error returned by a file operation function
@@ -6,9 +6,11 @@ package pipeline
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"code.waarp.fr/apps/gateway/gateway/pkg/analytics"
@@ -49,6 +51,9 @@ type Pipeline struct {
 	storedErr *Error
 	errOnce   sync.Once
 
+	registered atomic.Bool // whether the transfer is registered on the cluster node
+	fenced     atomic.Bool // whether the node has lost its lease on the transfer
+
 	Runner *tasks.Runner
 }
 
@@ -56,6 +61,12 @@ type Pipeline struct {
 func newPipeline(db *database.DB, logger *log.Logger, transCtx *model.TransferContext,
 	snmpService *snmp.Service,
 ) (*Pipeline, *Error) {
+	if List.isDraining(transCtx) {
+		logger.Errorf("Cannot start a new transfer: %v", ErrDraining)
+
+		return nil, ErrDraining
+	}
+
 	pipeline := &Pipeline{
 		DB:        db,
 		Logger:    logger,
@@ -122,6 +133,12 @@ func newPipeline(db *database.DB, logger *log.Logger, transCtx *model.TransferCo
 		return nil, err
 	}
 
+	if err := pipeline.registerNode(); err != nil {
+		List.remove(transCtx.Transfer.ID)
+
+		return nil, err
+	}
+
 	if analytics.GlobalService != nil {
 		analytics.GlobalService.RunningTransfers.Add(1)
 	}
@@ -144,7 +161,17 @@ func (p *Pipeline) SetProtocolAgent(agent any) {
 }
 
 func (p *Pipeline) forceUpdateTrans() *Error {
-	if dbErr := p.DB.Update(p.TransCtx.Transfer).Run(); dbErr != nil {
+	if p.fenced.Load() {
+		// The transfer is now owned by another node of the cluster, so its
+		// entry must not be modified anymore.
+		return nil
+	}
+
+	if dbErr := p.DB.Update(p.TransCtx.Transfer).Run(); errors.Is(dbErr, model.ErrTransferLeaseLost) {
+		p.fenced.Store(true)
+
+		return errFenced
+	} else if dbErr != nil {
 		return NewErrorWith(dbErr, types.TeInternal, "Failed to update transfer")
 	}
 
@@ -524,11 +551,8 @@ func (p *Pipeline) doneErr(status types.TransferStatus) {
 	p.TransCtx.Transfer.Status = status
 	p.TransCtx.Transfer.Stop = time.Now().UTC()
 
-	if status == types.StatusError && p.TransCtx.Transfer.RemainingTries > 0 {
-		p.TransCtx.Transfer.NextRetry = time.Now().UTC().
-			Add(time.Second * time.Duration(p.TransCtx.Transfer.NextRetryDelay))
-		incrementRetryDelay(p.TransCtx.Transfer)
-	} else {
+	// In case of error, the next attempt has already been planned by planRetry.
+	if status != types.StatusError {
 		p.TransCtx.Transfer.NextRetry = time.Time{}
 	}
 
@@ -571,6 +595,7 @@ func (p *Pipeline) done(state statemachine.State) {
 	}
 
 	List.remove(p.TransCtx.Transfer.ID)
+	p.unregisterNode()
 
 	if analytics.GlobalService != nil {
 		analytics.GlobalService.RunningTransfers.Add(-1)
@@ -35,6 +35,8 @@ type list struct {
 
 	limitServer, limitClient uint64
 	countServer, countClient uint64
+
+	drains drains
 }
 
 func (l *list) add(p *Pipeline) *Error {
@@ -82,6 +84,17 @@ func (l *list) SetLimits(server, client uint64) {
 	l.limitClient = client
 }
 
+func (l *list) getLimit(isServer bool) uint64 {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	if isServer {
+		return l.limitServer
+	}
+
+	return l.limitClient
+}
+
 func (l *list) GetAvailableOut() uint64 {
 	l.mutex.RLock()
 	defer l.mutex.RUnlock()
@@ -181,4 +194,5 @@ func (l *list) Reset() {
 	defer l.mutex.Unlock()
 
 	l.m = map[int64]*Pipeline{}
+	l.drains = drains{}
 }
@@ -0,0 +1,64 @@
+package pipeline
+
+import (
+	"time"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+)
+
+// planRetry determines, after an error, whether the transfer will be attempted
+// again, and schedules the next attempt if needed. Client transfers are retried
+// according to their retry policy (see model.GetRetryPolicy), or to their
+// client's retry parameters if they have none. Server transfers are never
+// retried by the gateway, but their rule's retry policy tells whether the
+// partner is expected to retry the transfer.
+//
+// The outcome is given to the error-tasks via the context's FinalAttempt field.
+func (p *Pipeline) planRetry() {
+	trans := p.TransCtx.Transfer
+	trans.NextRetry = time.Time{}
+
+	var partner *model.RemoteAgent
+	if !trans.IsServer() {
+		partner = p.TransCtx.RemoteAgent
+	}
+
+	policy, err := model.GetRetryPolicy(p.DB, p.TransCtx.Rule, partner)
+	if err != nil {
+		p.Logger.Errorf("Failed to retrieve the transfer's retry policy: %v", err)
+	}
+
+	now := time.Now().UTC()
+
+	switch {
+	case trans.IsServer() && policy == nil:
+		// Without a policy, there is no way to know whether the partner will
+		// retry the transfer, so it is considered as final.
+		p.TransCtx.FinalAttempt = true
+	case trans.IsServer():
+		if trans.RemainingTries > 0 {
+			trans.RemainingTries--
+		}
+
+		_, retry := policy.NextAttempt(trans, now)
+		p.TransCtx.FinalAttempt = !retry
+	case policy != nil:
+		if next, retry := policy.NextAttempt(trans, now); retry {
+			trans.NextRetry = next
+			trans.NextRetryDelay = policy.NextDelay(trans)
+		}
+
+		p.TransCtx.FinalAttempt = trans.NextRetry.IsZero()
+	default:
+		if trans.RemainingTries > 0 {
+			trans.NextRetry = now.Add(time.Second * time.Duration(trans.NextRetryDelay))
+			incrementRetryDelay(trans)
+		}
+
+		p.TransCtx.FinalAttempt = trans.NextRetry.IsZero()
+	}
+
+	if !trans.NextRetry.IsZero() {
+		p.Logger.Infof("The transfer will be retried at %s", trans.NextRetry.Format(time.RFC3339))
+	}
+}
@@ -0,0 +1,152 @@
+package pipeline
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/types"
+)
+
+func TestPipelinePlanRetry(t *testing.T) {
+	root := t.TempDir()
+
+	Convey("Given a client transfer", t, func(c C) {
+		ctx := initTestDB(c, root)
+
+		newPip := func(trans *model.Transfer) *Pipeline {
+			transCtx, err := model.GetTransferContext(ctx.db, ctx.logger, trans)
+			So(err, ShouldBeNil)
+
+			return &Pipeline{DB: ctx.db, Logger: ctx.logger, TransCtx: transCtx}
+		}
+
+		Convey("Given that the transfer has no retry policy", func(c C) {
+			trans := mkSendTransfer(ctx, "file")
+			trans.RemainingTries = 1
+			trans.NextRetryDelay = 10
+			trans.RetryIncrementFactor = 2
+
+			pip := newPip(trans)
+
+			Convey("When planning the transfer's next attempt", func(c C) {
+				pip.planRetry()
+
+				Convey("Then it should use the client's retry parameters", func(c C) {
+					So(trans.NextRetry, ShouldHappenWithin, time.Second,
+						time.Now().Add(10*time.Second))
+					So(trans.NextRetryDelay, ShouldEqual, 20)
+					So(pip.TransCtx.FinalAttempt, ShouldBeFalse)
+				})
+			})
+		})
+
+		Convey("Given that the transfer's partner has a retry policy", func(c C) {
+			policy := &model.RetryPolicy{
+				Name: "policy", MaxAttempts: 3, FirstDelay: time.Minute,
+				IncrementFactor: 10, MaxDelay: 5 * time.Minute,
+				ErrorCodes: model.Map[model.RetryAction]{
+					types.TeForbidden.String(): model.RetryActionNever,
+				},
+			}
+			So(ctx.db.Insert(policy).Run(), ShouldBeNil)
+
+			ctx.partner.RetryPolicy = policy.Name
+			So(ctx.db.Update(ctx.partner).Run(), ShouldBeNil)
+
+			trans := mkSendTransfer(ctx, "file")
+			So(trans.RemainingTries, ShouldEqual, policy.MaxAttempts)
+
+			Convey("When the transfer fails with a retriable error", func(c C) {
+				trans.ErrCode = types.TeConnection
+				pip := newPip(trans)
+				pip.planRetry()
+
+				Convey("Then it should plan the next attempt with the policy", func(c C) {
+					So(trans.NextRetry, ShouldHappenWithin, time.Second,
+						time.Now().Add(time.Minute))
+					So(trans.NextRetryDelay, ShouldEqual, 300)
+					So(pip.TransCtx.FinalAttempt, ShouldBeFalse)
+				})
+			})
+
+			Convey("When the transfer fails with a non-retriable error", func(c C) {
+				trans.ErrCode = types.TeForbidden
+				pip := newPip(trans)
+				pip.planRetry()
+
+				Convey("Then it should not plan another attempt", func(c C) {
+					So(trans.NextRetry.IsZero(), ShouldBeTrue)
+					So(pip.TransCtx.FinalAttempt, ShouldBeTrue)
+				})
+			})
+		})
+	})
+
+	Convey("Given a server transfer", t, func(c C) {
+		ctx := initTestDB(c, root)
+
+		mkServerTransfer := func() *model.Transfer {
+			trans := &model.Transfer{
+				RemoteTransferID: "1234",
+				LocalAccountID:   ctx.localAccount.NullableID(),
+				DestFilename:     "file",
+				RuleID:           ctx.recv.ID,
+			}
+			So(ctx.db.Insert(trans).Run(), ShouldBeNil)
+
+			return trans
+		}
+
+		newPip := func(trans *model.Transfer) *Pipeline {
+			transCtx, err := model.GetTransferContext(ctx.db, ctx.logger, trans)
+			So(err, ShouldBeNil)
+
+			return &Pipeline{DB: ctx.db, Logger: ctx.logger, TransCtx: transCtx}
+		}
+
+		Convey("Given that the rule has no retry policy", func(c C) {
+			trans := mkServerTransfer()
+			pip := newPip(trans)
+			pip.planRetry()
+
+			Convey("Then the attempt should be considered as final", func(c C) {
+				So(pip.TransCtx.FinalAttempt, ShouldBeTrue)
+				So(trans.NextRetry.IsZero(), ShouldBeTrue)
+			})
+		})
+
+		Convey("Given that the rule has a retry policy", func(c C) {
+			policy := &model.RetryPolicy{Name: "policy", MaxAttempts: 2}
+			So(ctx.db.Insert(policy).Run(), ShouldBeNil)
+
+			ctx.recv.RetryPolicy = policy.Name
+			So(ctx.db.Update(ctx.recv).Run(), ShouldBeNil)
+
+			trans := mkServerTransfer()
+			trans.ErrCode = types.TeDataTransfer
+
+			Convey("When the first attempt fails", func(c C) {
+				pip := newPip(trans)
+				pip.planRetry()
+
+				Convey("Then it should not be the final attempt", func(c C) {
+					So(trans.RemainingTries, ShouldEqual, 1)
+					So(pip.TransCtx.FinalAttempt, ShouldBeFalse)
+					So(trans.NextRetry.IsZero(), ShouldBeTrue)
+				})
+
+				Convey("When the second attempt fails", func(c C) {
+					pip.planRetry()
+
+					Convey("Then it should be the final attempt", func(c C) {
+						So(trans.RemainingTries, ShouldEqual, 0)
+						So(pip.TransCtx.FinalAttempt, ShouldBeTrue)
+					})
+				})
+			})
+		})
+	})
+}
@@ -114,6 +114,10 @@ func (s *server) start() error {
 		return fmt.Errorf("failed to start server listener: %w", listErr)
 	}
 
+	s.listener = protoutils.RejectWhen(s.listener, func() bool {
+		return pipeline.List.IsServerDraining(s.agent.ID)
+	})
+
 	s.server = as2.NewServer(
 		as2.WithLocalAS2ID(s.agent.Name),
 		as2.WithInboundPath("/"),
@@ -131,6 +135,12 @@ func (s *server) start() error {
 				return
 			}
 
+			if err := model.ResolveCredentialsSecrets(creds); err != nil {
+				s.logger.Errorf("Failed to resolve credentials: %v", err)
+
+				return
+			}
+
 			for _, cred := range creds {
 				cert, err := tls.X509KeyPair([]byte(cred.Value), []byte(cred.Value2))
 				if err != nil {
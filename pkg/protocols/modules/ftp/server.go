@@ -8,6 +8,7 @@ import (
 
 	ftplib "github.com/fclairamb/ftpserverlib"
 
+	"code.waarp.fr/apps/gateway/gateway/pkg/acme"
 	"code.waarp.fr/apps/gateway/gateway/pkg/analytics"
 	"code.waarp.fr/apps/gateway/gateway/pkg/database"
 	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
@@ -84,6 +85,12 @@ func (h *handler) ClientConnected(ftplib.ClientContext) (string, error) {
 	h.logger.Debug("Server control connection opened")
 	analytics.AddIncomingConnection()
 
+	if pipeline.List.IsServerDraining(h.dbServer.ID) {
+		h.logger.Info("Connection refused, the server is being drained")
+
+		return "the server is being drained, no new transfers are accepted", pipeline.ErrDraining
+	}
+
 	return h.getBanner(), nil
 }
 
@@ -143,23 +150,21 @@ func (h *handler) mkTLSConfig() {
 	h.tlsConfig = &tls.Config{
 		MinVersion: h.serverConf.MinTLSVersion.TLS(),
 		ClientAuth: tls.RequestClientCert,
+		GetConfigForClient: func(chi *tls.ClientHelloInfo) (*tls.Config, error) {
+			// A nil config means that the handshake is not an ACME challenge,
+			// in which case the regular config is used.
+			return acme.GetChallengeConfig(chi), nil
+		},
 		//nolint:err113 //dynamic errors are used to mask the internal errors (for security reasons)
 		GetCertificate: func(chi *tls.ClientHelloInfo) (*tls.Certificate, error) {
-			dbCerts, dbErr := h.dbServer.GetCredentials(h.db, auth.TLSCertificate)
-			if dbErr != nil {
-				h.logger.Errorf("Failed to retrieve TLS certificates: %v", dbErr)
-
+			certs, err := protoutils.GetServerCertificates(h.db, h.logger, h.dbServer)
+			if err != nil {
 				return nil, errors.New("internal database error")
 			}
 
-			for _, dbCert := range dbCerts {
-				cert, err := tls.X509KeyPair([]byte(dbCert.Value), []byte(dbCert.Value2))
-				if err != nil {
-					h.logger.Warningf("Failed to parse TLS certificate: %v", err)
-				}
-
-				if chi.SupportsCertificate(&cert) == nil {
-					return &cert, nil
+			for i := range certs {
+				if chi.SupportsCertificate(&certs[i]) == nil {
+					return &certs[i], nil
 				}
 			}
 
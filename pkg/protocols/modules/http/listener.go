@@ -9,6 +9,7 @@ import (
 	"code.waarp.fr/apps/gateway/gateway/pkg/database"
 	"code.waarp.fr/apps/gateway/gateway/pkg/model"
 	"code.waarp.fr/apps/gateway/gateway/pkg/model/authentication/auth"
+	"code.waarp.fr/apps/gateway/gateway/pkg/pipeline"
 	"code.waarp.fr/apps/gateway/gateway/pkg/protocols/protoutils"
 	"code.waarp.fr/apps/gateway/gateway/pkg/utils"
 )
@@ -36,6 +37,10 @@ func (h *httpService) listen() error {
 		return fmt.Errorf("failed to start server listener: %w", netErr)
 	}
 
+	list = protoutils.RejectWhen(list, func() bool {
+		return pipeline.List.IsServerDraining(h.agent.ID)
+	})
+
 	go func() {
 		servErr := h.serv.Serve(list)
 		if !errors.Is(servErr, http.ErrServerClosed) {
@@ -60,6 +60,10 @@ func sendInitialMessage(db database.ReadAccess, logger *log.Logger,
 		return fmt.Errorf("failed to retrieve credentials for account: %w", dbErr)
 	}
 
+	if err := model.ResolveCredentialsSecrets(accountCreds); err != nil {
+		return fmt.Errorf("failed to resolve credentials for account: %w", err)
+	}
+
 	var authorities model.Authorities
 	if err := db.Select(&authorities).Run(); err != nil {
 		return fmt.Errorf("failed to retrieve authorities: %w", err)
@@ -53,6 +53,10 @@ func (s *service) listen() (string, error) {
 		return "", fmt.Errorf("failed to open listener: %w", listErr)
 	}
 
+	list = protoutils.RejectWhen(list, func() bool {
+		return pipeline.List.IsServerDraining(s.localAgent.ID)
+	})
+
 	go func() {
 		if err := s.server.Serve(list); err != nil {
 			if !errors.Is(err, net.ErrClosed) {
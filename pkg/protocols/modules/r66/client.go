@@ -203,6 +203,10 @@ func (c *Client) GetConnection(partner *model.RemoteAgent, account *model.Remote
 		return nil, fmt.Errorf("failed to retrieve account credentials: %w", err2)
 	}
 
+	if err := model.ResolveCredentialsSecrets(remoteAccountCreds); err != nil {
+		return nil, fmt.Errorf("failed to resolve account credentials: %w", err)
+	}
+
 	fakeTransCtx := &model.TransferContext{
 		Client:             c.cli,
 		RemoteAgent:        partner,
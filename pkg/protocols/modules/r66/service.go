@@ -12,6 +12,7 @@ import (
 
 	"code.waarp.fr/lib/r66"
 
+	"code.waarp.fr/apps/gateway/gateway/pkg/acme"
 	"code.waarp.fr/apps/gateway/gateway/pkg/database"
 	"code.waarp.fr/apps/gateway/gateway/pkg/logging"
 	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
@@ -59,7 +60,7 @@ func (s *service) makeTLSConf() *tls.Config {
 
 	return &tls.Config{
 		GetConfigForClient: func(chi *tls.ClientHelloInfo) (*tls.Config, error) {
-			if !compatibility.IsLegacyR66CertificateAllowed {
+			if !compatibility.IsLegacyR66CertificateAllowed || acme.GetChallengeConfig(chi) != nil {
 				return standardConfig.GetConfigForClient(chi)
 			}
 
@@ -153,6 +154,10 @@ func (s *service) listen() error {
 		return fmt.Errorf("failed to start R66 listener: %w", listErr)
 	}
 
+	s.list = protoutils.RejectWhen(s.list, func() bool {
+		return pipeline.List.IsServerDraining(s.dbAgent.ID)
+	})
+
 	if s.dbAgent.Protocol == R66TLS {
 		s.list = tls.NewListener(s.list, s.makeTLSConf())
 	}
@@ -61,6 +61,12 @@ func (l *sshListener) makeSSHConf(server *model.LocalAgent) (*ssh.ServerConfig,
 		return nil, fmt.Errorf("failed to retrieve the server host keys: %w", err)
 	}
 
+	if err = model.ResolveCredentialsSecrets(hostKeys); err != nil {
+		l.Logger.Errorf("Failed to resolve the server host keys: %v", err)
+
+		return nil, fmt.Errorf("failed to resolve the server host keys: %w", err)
+	}
+
 	sshConf, err1 := getSSHServerConfig(l.DB, l.Logger, hostKeys, &protoConfig, server)
 	if err1 != nil {
 		l.Logger.Errorf("Failed to parse the SSH server configuration: %v", err1)
@@ -49,7 +49,9 @@ func (s *service) start() error {
 		DB:       s.db,
 		Logger:   s.logger,
 		serverID: s.server.ID,
-		listener: listener,
+		listener: protoutils.RejectWhen(listener, func() bool {
+			return pipeline.List.IsServerDraining(s.server.ID)
+		}),
 		shutdown: make(chan struct{}),
 	}
 
@@ -130,6 +130,10 @@ func (s *server) start() error {
 		return fmt.Errorf("failed to start server listener: %w", err)
 	}
 
+	listener = protoutils.RejectWhen(listener, func() bool {
+		return pipeline.List.IsServerDraining(s.agent.ID)
+	})
+
 	if s.agent.Protocol == WebdavTLS {
 		listener = s.listentTLS(listener)
 	}
@@ -58,3 +58,29 @@ func (l *listener) Accept() (net.Conn, error) {
 }
 
 func (l *listener) Close() error { return l.close() }
+
+// rejectingListener is a listener which immediately closes the new incoming
+// connections while its reject function returns true.
+type rejectingListener struct {
+	net.Listener
+	reject func() bool
+}
+
+// RejectWhen wraps the given listener so that the incoming connections are
+// closed as soon as they are accepted while the given function returns true
+// (typically while the server is being drained).
+func RejectWhen(l net.Listener, reject func() bool) net.Listener {
+	return &rejectingListener{Listener: l, reject: reject}
+}
+
+//nolint:wrapcheck //no need to wrap here
+func (r *rejectingListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := r.Listener.Accept()
+		if err != nil || !r.reject() {
+			return conn, err
+		}
+
+		_ = conn.Close() //nolint:errcheck //error is irrelevant here
+	}
+}
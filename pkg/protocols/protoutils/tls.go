@@ -11,10 +11,12 @@ import (
 	"strconv"
 	"strings"
 
+	"code.waarp.fr/apps/gateway/gateway/pkg/acme"
 	"code.waarp.fr/apps/gateway/gateway/pkg/database"
 	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
 	"code.waarp.fr/apps/gateway/gateway/pkg/model"
 	"code.waarp.fr/apps/gateway/gateway/pkg/model/authentication/auth"
+	"code.waarp.fr/apps/gateway/gateway/pkg/revocation"
 	"code.waarp.fr/apps/gateway/gateway/pkg/utils"
 	"code.waarp.fr/apps/gateway/gateway/pkg/utils/compatibility"
 )
@@ -139,16 +141,40 @@ func MakeServerTLSConfig(db database.ReadAccess, logger *log.Logger, agentID int
 
 func GetServerCertificates(db database.ReadAccess, logger *log.Logger, owner model.CredOwnerTable,
 ) ([]tls.Certificate, error) {
-	creds, dbErr := owner.GetCredentials(db, auth.TLSCertificate)
+	creds, dbErr := owner.GetCredentials(db, auth.TLSCertificate, auth.ACMECertificate)
 	if dbErr != nil {
 		logger.Errorf("Failed to retrieve server certificates: %s", dbErr)
 
 		return nil, fmt.Errorf("failed to retrieve server certificates: %w", dbErr)
 	}
 
+	if err := model.ResolveCredentialsSecrets(creds); err != nil {
+		logger.Errorf("Failed to resolve server certificates: %s", err)
+
+		return nil, fmt.Errorf("failed to resolve server certificates: %w", err)
+	}
+
 	var tlsCerts []tls.Certificate
 
 	for _, cred := range creds {
+		if cred.Type == auth.ACMECertificate {
+			if cred.Value2 == "" {
+				continue // the certificate has not been issued yet
+			}
+
+			cert, err := auth.ParseACMECertificate(cred.Value2)
+			if err != nil {
+				logger.Warningf("Failed to parse server certificate: %v", err)
+
+				continue
+			}
+
+			revocation.Staple(cert)
+			tlsCerts = append(tlsCerts, *cert)
+
+			continue
+		}
+
 		cert, err := tls.X509KeyPair([]byte(cred.Value), []byte(cred.Value2))
 		if err != nil {
 			logger.Warningf("Failed to parse server certificate: %v", err)
@@ -156,6 +182,7 @@ func GetServerCertificates(db database.ReadAccess, logger *log.Logger, owner mod
 			continue
 		}
 
+		revocation.Staple(&cert)
 		tlsCerts = append(tlsCerts, cert)
 	}
 
@@ -165,7 +192,11 @@ func GetServerCertificates(db database.ReadAccess, logger *log.Logger, owner mod
 func GetServerTLSConfig(db database.ReadAccess, logger *log.Logger, agentID int64,
 ) *tls.Config {
 	return &tls.Config{
-		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			if challengeConf := acme.GetChallengeConfig(hello); challengeConf != nil {
+				return challengeConf, nil
+			}
+
 			return MakeServerTLSConfig(db, logger, agentID)
 		},
 	}
@@ -187,10 +218,9 @@ func GetClientTLSConf(logger *log.Logger, partner *model.RemoteAgent,
 	}
 
 	config := &tls.Config{
-		ServerName:       partner.Address.Host,
-		RootCAs:          utils.TLSCertPool(),
-		VerifyConnection: compatibility.LogSha1(logger),
-		MinVersion:       minVersion,
+		ServerName: partner.Address.Host,
+		RootCAs:    utils.TLSCertPool(),
+		MinVersion: minVersion,
 	}
 
 	for _, cred := range accountCreds {
@@ -214,12 +244,31 @@ func GetClientTLSConf(logger *log.Logger, partner *model.RemoteAgent,
 		config.RootCAs.AppendCertsFromPEM([]byte(cred.Value))
 	}
 
+	var trustedAuthorities []*model.Authority
+
 	for _, authority := range authorities {
 		if len(authority.ValidHosts) != 0 && !slices.Contains(authority.ValidHosts, config.ServerName) {
 			continue
 		}
 
 		config.RootCAs.AppendCertsFromPEM([]byte(authority.PublicIdentity))
+		trustedAuthorities = append(trustedAuthorities, authority)
+	}
+
+	logSha1 := compatibility.LogSha1(logger)
+	config.VerifyConnection = func(state tls.ConnectionState) error {
+		if err := logSha1(state); err != nil {
+			return err
+		}
+
+		if err := auth.CheckRevocation(state.VerifiedChains, state.OCSPResponse,
+			trustedAuthorities); err != nil {
+			logger.Warningf("Failed to verify the partner's certificate: %v", err)
+
+			return fmt.Errorf("tls: invalid partner certificate: %w", err)
+		}
+
+		return nil
 	}
 
 	return config, nil
@@ -0,0 +1,305 @@
+package revocation
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/conf"
+)
+
+const maxResponseSize = 10 << 20 // 10 MiB, CRLs can be quite large
+
+var (
+	errNoOCSPResponder  = errors.New("no OCSP responder")
+	errNoCRL            = errors.New("no CRL distribution point")
+	errOCSPUnknown      = errors.New("the OCSP responder does not know the certificate")
+	errOutdated         = errors.New("the revocation information is outdated")
+	errUnexpectedStatus = errors.New("unexpected HTTP status")
+)
+
+type ocspEntry struct {
+	resp *ocsp.Response
+	raw  []byte
+}
+
+type cacheEntry[T any] struct {
+	value   T
+	expires time.Time
+}
+
+// cache keeps the CRLs & OCSP responses in memory until their next update (or
+// for the configured cache duration, whichever comes first).
+type cache[T any] struct {
+	mx      sync.Mutex
+	entries map[string]cacheEntry[T]
+}
+
+func newCache[T any]() *cache[T] {
+	return &cache[T]{entries: map[string]cacheEntry[T]{}}
+}
+
+func (c *cache[T]) get(key string) (T, bool) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		delete(c.entries, key)
+
+		var zero T
+
+		return zero, false
+	}
+
+	return entry.value, true
+}
+
+func (c *cache[T]) set(config *conf.RevocationConfig, key string, value T, nextUpdate time.Time) {
+	if config.CacheDuration <= 0 {
+		return
+	}
+
+	expires := time.Now().Add(config.CacheDuration)
+	if !nextUpdate.IsZero() && nextUpdate.Before(expires) {
+		expires = nextUpdate
+	}
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	c.entries[key] = cacheEntry[T]{value: value, expires: expires}
+}
+
+func (c *cache[T]) flush() {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	c.entries = map[string]cacheEntry[T]{}
+}
+
+func (c *checker) httpClient() *http.Client {
+	c.mx.RLock()
+	defer c.mx.RUnlock()
+
+	return c.client
+}
+
+// ocspStatus checks the certificate's status using the stapled OCSP response
+// (if any), or else by querying the certificate's OCSP responders.
+func (c *checker) ocspStatus(config *conf.RevocationConfig, cert, issuer *x509.Certificate,
+	stapled []byte, override string,
+) error {
+	if len(stapled) != 0 {
+		if resp, err := ocsp.ParseResponseForCert(stapled, cert, issuer); err == nil {
+			if err = ocspResponseStatus(resp); err == nil || errors.Is(err, ErrRevoked) {
+				return err
+			}
+		}
+	}
+
+	servers := cert.OCSPServer
+	if override != "" {
+		servers = []string{override}
+	}
+
+	if len(servers) == 0 {
+		return errNoOCSPResponder
+	}
+
+	var errs []error
+
+	for _, server := range servers {
+		entry, err := c.fetchOCSP(config, server, cert, issuer)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("OCSP responder %q: %w", server, err))
+
+			continue
+		}
+
+		if err = ocspResponseStatus(entry.resp); err == nil || errors.Is(err, ErrRevoked) {
+			return err
+		}
+
+		errs = append(errs, fmt.Errorf("OCSP responder %q: %w", server, err))
+	}
+
+	return errors.Join(errs...)
+}
+
+func ocspResponseStatus(resp *ocsp.Response) error {
+	if !resp.NextUpdate.IsZero() && resp.NextUpdate.Before(time.Now()) {
+		return errOutdated
+	}
+
+	switch resp.Status {
+	case ocsp.Good:
+		return nil
+	case ocsp.Revoked:
+		return ErrRevoked
+	default:
+		return errOCSPUnknown
+	}
+}
+
+func (c *checker) fetchOCSP(config *conf.RevocationConfig, server string,
+	cert, issuer *x509.Certificate,
+) (*ocspEntry, error) {
+	issuerHash := sha256.Sum256(issuer.RawSubjectPublicKeyInfo)
+	key := server + "|" + hex.EncodeToString(issuerHash[:]) + "|" + cert.SerialNumber.String()
+
+	if entry, ok := c.ocsps.get(key); ok {
+		return entry, nil
+	}
+
+	req, err := ocsp.CreateRequest(cert, issuer, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the OCSP request: %w", err)
+	}
+
+	raw, err := c.fetch(config, http.MethodPost, server, "application/ocsp-request", req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := ocsp.ParseResponseForCert(raw, cert, issuer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the OCSP response: %w", err)
+	}
+
+	entry := &ocspEntry{resp: resp, raw: raw}
+	c.ocsps.set(config, key, entry, resp.NextUpdate)
+
+	return entry, nil
+}
+
+// crlStatus checks the certificate's status against the CRLs of its issuer.
+func (c *checker) crlStatus(config *conf.RevocationConfig, cert, issuer *x509.Certificate,
+	override string,
+) error {
+	locations := cert.CRLDistributionPoints
+	if override != "" {
+		locations = []string{override}
+	}
+
+	if len(locations) == 0 {
+		return errNoCRL
+	}
+
+	var errs []error
+
+	for _, location := range locations {
+		list, err := c.fetchCRL(config, location)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("CRL %q: %w", location, err))
+
+			continue
+		}
+
+		if err = list.CheckSignatureFrom(issuer); err != nil {
+			errs = append(errs, fmt.Errorf("CRL %q: invalid signature: %w", location, err))
+
+			continue
+		}
+
+		if !list.NextUpdate.IsZero() && list.NextUpdate.Before(time.Now()) {
+			errs = append(errs, fmt.Errorf("CRL %q: %w", location, errOutdated))
+
+			continue
+		}
+
+		for _, entry := range list.RevokedCertificateEntries {
+			if entry.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+				return ErrRevoked
+			}
+		}
+
+		return nil
+	}
+
+	return errors.Join(errs...)
+}
+
+// fetchCRL retrieves the CRL at the given location, which can either be an
+// HTTP(S) URL, or a file path (optionally prefixed with "file://"). Both DER
+// and PEM encoded CRLs are accepted.
+func (c *checker) fetchCRL(config *conf.RevocationConfig, location string) (*x509.RevocationList, error) {
+	if list, ok := c.crls.get(location); ok {
+		return list, nil
+	}
+
+	var (
+		raw []byte
+		err error
+	)
+
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		raw, err = c.fetch(config, http.MethodGet, location, "", nil)
+	} else {
+		raw, err = os.ReadFile(strings.TrimPrefix(location, "file://"))
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve the CRL: %w", err)
+	}
+
+	if block, _ := pem.Decode(raw); block != nil {
+		raw = block.Bytes
+	}
+
+	list, err := x509.ParseRevocationList(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the CRL: %w", err)
+	}
+
+	c.crls.set(config, location, list, list.NextUpdate)
+
+	return list, nil
+}
+
+func (c *checker) fetch(config *conf.RevocationConfig, method, url, contentType string,
+	body []byte,
+) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to make the request: %w", err)
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	defer resp.Body.Close() //nolint:errcheck //error is irrelevant here
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w %q", errUnexpectedStatus, resp.Status)
+	}
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the response: %w", err)
+	}
+
+	return raw, nil
+}
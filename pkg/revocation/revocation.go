@@ -0,0 +1,283 @@
+// Package revocation implements the revocation checks (CRL & OCSP) performed
+// on the TLS certificates presented by the transfer partners and clients, as
+// well as the OCSP stapling of the gateway's own server certificates.
+//
+// The checks are governed by a policy, which can be set globally in the
+// configuration file, and overridden for the certificates issued by a given
+// authority:
+//   - none: no revocation check is performed
+//   - soft-fail: certificates known to be revoked are refused, but those
+//     whose status cannot be determined (unreachable responder, no CRL...)
+//     are accepted
+//   - hard-fail: certificates whose status cannot be determined are refused
+//     as well
+package revocation
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/conf"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
+)
+
+// Policy is a revocation checking policy.
+type Policy string
+
+// The revocation checking policies.
+const (
+	PolicyNone     Policy = "none"
+	PolicySoftFail Policy = "soft-fail"
+	PolicyHardFail Policy = "hard-fail"
+)
+
+const (
+	defaultCacheDuration = time.Hour
+	defaultTimeout       = 5 * time.Second
+)
+
+var (
+	ErrRevoked       = errors.New("certificate has been revoked")
+	ErrUnknownStatus = errors.New("certificate revocation status is unknown")
+	ErrInvalidPolicy = errors.New("invalid revocation policy")
+	errNoCheck       = errors.New("both the CRL & OCSP checks are disabled")
+)
+
+// ParsePolicy parses the given revocation policy. An empty string is accepted
+// and returned as is (meaning that the global policy applies).
+func ParsePolicy(str string) (Policy, error) {
+	switch policy := Policy(str); policy {
+	case "", PolicyNone, PolicySoftFail, PolicyHardFail:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("%w %q (valid policies are %q, %q and %q)", ErrInvalidPolicy,
+			str, PolicyNone, PolicySoftFail, PolicyHardFail)
+	}
+}
+
+// Settings are the revocation settings overriding the global configuration
+// for the certificates issued by a given authority.
+type Settings struct {
+	// Policy overrides the global policy (if not empty).
+	Policy Policy
+	// CRLURL overrides the CRL distribution points of the certificates issued
+	// by the authority. It can either be an HTTP(S) URL or a file path.
+	CRLURL string
+	// OCSPURL overrides the OCSP responders of the certificates issued by the
+	// authority.
+	OCSPURL string
+	// Authority is the authority's certificate. The URLs above only apply
+	// to the certificates directly issued by it.
+	Authority *x509.Certificate
+}
+
+//nolint:gochecknoglobals //global var is needed here for the configuration & caches
+var global = newChecker(&conf.RevocationConfig{
+	Policy:        string(PolicyNone),
+	CacheDuration: defaultCacheDuration,
+	Timeout:       defaultTimeout,
+}, logging.Discard())
+
+// Configure sets the global revocation configuration, and flushes the CRL &
+// OCSP caches.
+func Configure(config *conf.RevocationConfig) error {
+	policy, err := ParsePolicy(config.Policy)
+	if err != nil {
+		return err
+	}
+
+	if policy == "" {
+		config.Policy = string(PolicyNone)
+	}
+
+	global.configure(config, logging.NewLogger("Revocation"))
+
+	return nil
+}
+
+// CheckChain checks the revocation status of all the certificates of the given
+// verified chain (from the leaf to the root) according to the applicable
+// policy. An optional OCSP response stapled by the peer for the leaf
+// certificate can be given. Settings can be nil.
+func CheckChain(chain []*x509.Certificate, stapled []byte, settings *Settings) error {
+	return global.checkChain(chain, stapled, settings)
+}
+
+// Staple fetches an OCSP response for the given server certificate and
+// attaches it to the certificate, if OCSP stapling is enabled. The chain of
+// the certificate must contain its issuer. Failures are only logged, since
+// stapling is purely optional.
+func Staple(cert *tls.Certificate) {
+	global.staple(cert)
+}
+
+type checker struct {
+	mx     sync.RWMutex
+	config conf.RevocationConfig
+	logger *log.Logger
+	client *http.Client
+
+	crls  *cache[*x509.RevocationList]
+	ocsps *cache[*ocspEntry]
+}
+
+func newChecker(config *conf.RevocationConfig, logger *log.Logger) *checker {
+	c := &checker{
+		crls:  newCache[*x509.RevocationList](),
+		ocsps: newCache[*ocspEntry](),
+	}
+
+	c.configure(config, logger)
+
+	return c
+}
+
+func (c *checker) configure(config *conf.RevocationConfig, logger *log.Logger) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	c.config = *config
+	c.logger = logger
+
+	if c.config.Timeout <= 0 {
+		c.config.Timeout = defaultTimeout
+	}
+
+	c.client = &http.Client{Timeout: c.config.Timeout}
+
+	c.crls.flush()
+	c.ocsps.flush()
+}
+
+func (c *checker) getConfig() (conf.RevocationConfig, *log.Logger) {
+	c.mx.RLock()
+	defer c.mx.RUnlock()
+
+	return c.config, c.logger
+}
+
+func (c *checker) checkChain(chain []*x509.Certificate, stapled []byte, settings *Settings) error {
+	config, logger := c.getConfig()
+
+	if settings == nil {
+		settings = &Settings{}
+	}
+
+	policy := settings.Policy
+	if policy == "" {
+		policy = Policy(config.Policy)
+	}
+
+	if policy == PolicyNone || policy == "" {
+		return nil
+	}
+
+	// The root is trusted by definition, so it is not checked.
+	for i := 0; i < len(chain)-1; i++ {
+		cert, issuer := chain[i], chain[i+1]
+
+		var crlURL, ocspURL string
+		if settings.Authority != nil && issuer.Equal(settings.Authority) {
+			crlURL, ocspURL = settings.CRLURL, settings.OCSPURL
+		}
+
+		var staple []byte
+		if i == 0 {
+			staple = stapled
+		}
+
+		err := c.status(&config, cert, issuer, staple, crlURL, ocspURL)
+
+		switch {
+		case err == nil:
+		case errors.Is(err, ErrRevoked):
+			logger.Warningf("Certificate %q has been revoked", cert.Subject)
+
+			return fmt.Errorf("certificate %q: %w", cert.Subject, err)
+		case policy == PolicyHardFail:
+			logger.Warningf("Failed to check the revocation status of certificate %q: %v",
+				cert.Subject, err)
+
+			return fmt.Errorf("certificate %q: %w: %w", cert.Subject, ErrUnknownStatus, err)
+		default:
+			logger.Debugf("Could not check the revocation status of certificate %q: %v",
+				cert.Subject, err)
+		}
+	}
+
+	return nil
+}
+
+// status returns nil if the given certificate is known to be valid, an
+// ErrRevoked error if it has been revoked, or another error if its status
+// could not be determined. OCSP takes precedence over CRLs.
+func (c *checker) status(config *conf.RevocationConfig, cert, issuer *x509.Certificate,
+	stapled []byte, crlURL, ocspURL string,
+) error {
+	var errs []error
+
+	if !config.DisableOCSP {
+		err := c.ocspStatus(config, cert, issuer, stapled, ocspURL)
+		if err == nil || errors.Is(err, ErrRevoked) {
+			return err
+		}
+
+		errs = append(errs, err)
+	}
+
+	if !config.DisableCRL {
+		err := c.crlStatus(config, cert, issuer, crlURL)
+		if err == nil || errors.Is(err, ErrRevoked) {
+			return err
+		}
+
+		errs = append(errs, err)
+	}
+
+	if len(errs) == 0 {
+		return errNoCheck
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *checker) staple(cert *tls.Certificate) {
+	config, logger := c.getConfig()
+	if !config.OCSPStapling || len(cert.Certificate) < 2 { //nolint:mnd //the chain must contain the issuer
+		return
+	}
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		logger.Warningf("Failed to parse the certificate to staple: %v", err)
+
+		return
+	}
+
+	issuer, err := x509.ParseCertificate(cert.Certificate[1])
+	if err != nil {
+		logger.Warningf("Failed to parse the issuer of the certificate to staple: %v", err)
+
+		return
+	}
+
+	for _, server := range leaf.OCSPServer {
+		entry, fetchErr := c.fetchOCSP(&config, server, leaf, issuer)
+		if fetchErr != nil {
+			logger.Warningf("Failed to fetch the OCSP response of certificate %q: %v",
+				leaf.Subject, fetchErr)
+
+			continue
+		}
+
+		cert.OCSPStaple = entry.raw
+
+		return
+	}
+}
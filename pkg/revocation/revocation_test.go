@@ -0,0 +1,307 @@
+package revocation
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ocsp"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/conf"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  crypto.Signer
+}
+
+func makeTestCA(tb testing.TB) *testCA {
+	tb.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(tb, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(tb, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(tb, err)
+
+	return &testCA{cert: cert, key: key}
+}
+
+func (ca *testCA) issue(tb testing.TB, serial int64, crlURL, ocspURL string) *x509.Certificate {
+	tb.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(tb, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	if crlURL != "" {
+		template.CRLDistributionPoints = []string{crlURL}
+	}
+
+	if ocspURL != "" {
+		template.OCSPServer = []string{ocspURL}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(tb, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(tb, err)
+
+	return cert
+}
+
+func (ca *testCA) crl(tb testing.TB, revoked ...int64) []byte {
+	tb.Helper()
+
+	template := &x509.RevocationList{
+		Number:     big.NewInt(1),
+		ThisUpdate: time.Now().Add(-time.Minute),
+		NextUpdate: time.Now().Add(time.Hour),
+	}
+
+	for _, serial := range revoked {
+		template.RevokedCertificateEntries = append(template.RevokedCertificateEntries,
+			x509.RevocationListEntry{SerialNumber: big.NewInt(serial), RevocationTime: time.Now()})
+	}
+
+	der, err := x509.CreateRevocationList(rand.Reader, template, ca.cert, ca.key)
+	require.NoError(tb, err)
+
+	return der
+}
+
+// ocspResponder returns an OCSP responder answering that the certificates with
+// the given serial numbers are revoked, and that all the others are valid. The
+// returned counter holds the number of requests received.
+func (ca *testCA) ocspResponder(tb testing.TB, revoked ...int64) (*httptest.Server, *atomic.Int32) {
+	tb.Helper()
+
+	var count atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count.Add(1)
+
+		body, err := io.ReadAll(r.Body)
+		if !assert.NoError(tb, err) {
+			return
+		}
+
+		req, err := ocsp.ParseRequest(body)
+		if !assert.NoError(tb, err) {
+			return
+		}
+
+		template := ocsp.Response{
+			Status:       ocsp.Good,
+			SerialNumber: req.SerialNumber,
+			ThisUpdate:   time.Now().Add(-time.Minute),
+			NextUpdate:   time.Now().Add(time.Hour),
+		}
+
+		for _, serial := range revoked {
+			if req.SerialNumber.Int64() == serial {
+				template.Status = ocsp.Revoked
+				template.RevokedAt = time.Now()
+			}
+		}
+
+		resp, err := ocsp.CreateResponse(ca.cert, ca.cert, template, ca.key)
+		if !assert.NoError(tb, err) {
+			return
+		}
+
+		_, _ = w.Write(resp)
+	}))
+	tb.Cleanup(server.Close)
+
+	return server, &count
+}
+
+func newTestChecker(policy Policy, checkCRL, checkOCSP bool) *checker {
+	return newChecker(&conf.RevocationConfig{
+		Policy:        string(policy),
+		DisableCRL:    !checkCRL,
+		DisableOCSP:   !checkOCSP,
+		CacheDuration: time.Hour,
+		Timeout:       time.Second,
+		OCSPStapling:  true,
+	}, logging.Discard())
+}
+
+func TestParsePolicy(t *testing.T) {
+	t.Parallel()
+
+	for _, valid := range []string{"", "none", "soft-fail", "hard-fail"} {
+		policy, err := ParsePolicy(valid)
+		require.NoError(t, err)
+		assert.Equal(t, Policy(valid), policy)
+	}
+
+	_, err := ParsePolicy("strict")
+	require.ErrorIs(t, err, ErrInvalidPolicy)
+}
+
+func TestCheckChainOCSP(t *testing.T) {
+	t.Parallel()
+
+	ca := makeTestCA(t)
+	responder, count := ca.ocspResponder(t, 2)
+
+	valid := ca.issue(t, 1, "", responder.URL)
+	revoked := ca.issue(t, 2, "", responder.URL)
+	noResponder := ca.issue(t, 3, "", "")
+
+	t.Run("Given the 'none' policy", func(t *testing.T) {
+		t.Parallel()
+
+		c := newTestChecker(PolicyNone, true, true)
+
+		assert.NoError(t, c.checkChain([]*x509.Certificate{revoked, ca.cert}, nil, nil),
+			"no check should be performed")
+	})
+
+	t.Run("Given the 'soft-fail' policy", func(t *testing.T) {
+		t.Parallel()
+
+		c := newTestChecker(PolicySoftFail, true, true)
+
+		require.NoError(t, c.checkChain([]*x509.Certificate{valid, ca.cert}, nil, nil))
+		require.ErrorIs(t, c.checkChain([]*x509.Certificate{revoked, ca.cert}, nil, nil), ErrRevoked)
+		require.NoError(t, c.checkChain([]*x509.Certificate{noResponder, ca.cert}, nil, nil),
+			"an unknown status should be accepted")
+	})
+
+	t.Run("Given the 'hard-fail' policy", func(t *testing.T) {
+		t.Parallel()
+
+		c := newTestChecker(PolicyHardFail, true, true)
+
+		require.NoError(t, c.checkChain([]*x509.Certificate{valid, ca.cert}, nil, nil))
+		require.ErrorIs(t, c.checkChain([]*x509.Certificate{revoked, ca.cert}, nil, nil), ErrRevoked)
+		require.ErrorIs(t, c.checkChain([]*x509.Certificate{noResponder, ca.cert}, nil, nil),
+			ErrUnknownStatus, "an unknown status should be refused")
+	})
+
+	t.Run("Given an authority overriding the policy & OCSP responder", func(t *testing.T) {
+		t.Parallel()
+
+		c := newTestChecker(PolicyNone, false, true)
+		settings := &Settings{Policy: PolicyHardFail, OCSPURL: responder.URL, Authority: ca.cert}
+
+		require.NoError(t, c.checkChain([]*x509.Certificate{noResponder, ca.cert}, nil, settings))
+		require.ErrorIs(t, c.checkChain([]*x509.Certificate{ca.issue(t, 2, "", ""), ca.cert},
+			nil, settings), ErrRevoked)
+	})
+
+	t.Run("Given a stapled OCSP response", func(t *testing.T) {
+		t.Parallel()
+
+		stapler := newTestChecker(PolicyHardFail, false, true)
+		tlsCert := &tls.Certificate{Certificate: [][]byte{revoked.Raw, ca.cert.Raw}}
+		stapler.staple(tlsCert)
+		require.NotEmpty(t, tlsCert.OCSPStaple)
+
+		c := newTestChecker(PolicyHardFail, false, true)
+		before := count.Load()
+
+		require.ErrorIs(t, c.checkChain([]*x509.Certificate{revoked, ca.cert},
+			tlsCert.OCSPStaple, nil), ErrRevoked)
+		assert.Equal(t, before, count.Load(), "the responder should not have been queried")
+	})
+}
+
+func TestCheckChainCRL(t *testing.T) {
+	t.Parallel()
+
+	ca := makeTestCA(t)
+
+	var crlCount atomic.Int32
+
+	crl := ca.crl(t, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		crlCount.Add(1)
+
+		_, _ = w.Write(crl)
+	}))
+	t.Cleanup(server.Close)
+
+	t.Run("Given a CRL distribution point", func(t *testing.T) {
+		t.Parallel()
+
+		c := newTestChecker(PolicyHardFail, true, true)
+
+		require.NoError(t, c.checkChain([]*x509.Certificate{ca.issue(t, 1, server.URL, ""), ca.cert},
+			nil, nil))
+		require.ErrorIs(t, c.checkChain([]*x509.Certificate{ca.issue(t, 2, server.URL, ""), ca.cert},
+			nil, nil), ErrRevoked)
+		assert.Equal(t, int32(1), crlCount.Load(), "the CRL should have been cached")
+	})
+
+	t.Run("Given a CRL file set on the authority", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "ca.crl")
+		require.NoError(t, os.WriteFile(path, crl, 0o600))
+
+		c := newTestChecker(PolicySoftFail, true, false)
+		settings := &Settings{CRLURL: path, Authority: ca.cert}
+
+		require.NoError(t, c.checkChain([]*x509.Certificate{ca.issue(t, 1, "", ""), ca.cert},
+			nil, settings))
+		require.ErrorIs(t, c.checkChain([]*x509.Certificate{ca.issue(t, 2, "", ""), ca.cert},
+			nil, settings), ErrRevoked)
+	})
+
+	t.Run("Given a CRL signed by another CA", func(t *testing.T) {
+		t.Parallel()
+
+		other := makeTestCA(t)
+		path := filepath.Join(t.TempDir(), "other.crl")
+		require.NoError(t, os.WriteFile(path, other.crl(t), 0o600))
+
+		c := newTestChecker(PolicyHardFail, true, false)
+		settings := &Settings{CRLURL: path, Authority: ca.cert}
+
+		require.ErrorIs(t, c.checkChain([]*x509.Certificate{ca.issue(t, 1, "", ""), ca.cert},
+			nil, settings), ErrUnknownStatus)
+	})
+}
@@ -0,0 +1,67 @@
+package secrets
+
+import (
+	"sync"
+	"time"
+)
+
+const defaultCacheTTL = 5 * time.Minute
+
+//nolint:gochecknoglobals //global var is needed here for the cache
+var globalCache = &cache{ttl: defaultCacheTTL, entries: map[string]cacheEntry{}}
+
+type cacheEntry struct {
+	secret  string
+	expires time.Time
+}
+
+// cache keeps the resolved secrets in memory for a limited amount of time to
+// avoid querying the secret stores every time a secret is needed.
+type cache struct {
+	mx      sync.Mutex
+	ttl     time.Duration
+	entries map[string]cacheEntry
+}
+
+func (c *cache) setTTL(ttl time.Duration) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	c.ttl = ttl
+}
+
+func (c *cache) get(ref string) (string, bool) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	entry, ok := c.entries[ref]
+	if !ok {
+		return "", false
+	}
+
+	if time.Now().After(entry.expires) {
+		delete(c.entries, ref)
+
+		return "", false
+	}
+
+	return entry.secret, true
+}
+
+func (c *cache) set(ref, secret string) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.entries[ref] = cacheEntry{secret: secret, expires: time.Now().Add(c.ttl)}
+}
+
+func (c *cache) flush() {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	c.entries = map[string]cacheEntry{}
+}
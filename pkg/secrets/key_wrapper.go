@@ -0,0 +1,99 @@
+package secrets
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+)
+
+const vaultTransitScheme = "vault-transit"
+
+var (
+	ErrUnknownKeyWrapper = errors.New("unknown key wrapper")
+	ErrInvalidKeyWrapper = errors.New("invalid key wrapper")
+)
+
+// KeyWrapper is the interface implemented by the external key management
+// services (KMS) which can be used to wrap (encrypt) and unwrap (decrypt) the
+// data encryption keys, a technique known as envelope encryption.
+type KeyWrapper interface {
+	// WrapKey returns the given key encrypted by the KMS.
+	WrapKey(key []byte) ([]byte, error)
+	// UnwrapKey returns the given wrapped key decrypted by the KMS.
+	UnwrapKey(wrapped []byte) ([]byte, error)
+}
+
+// NewKeyWrapper returns the KeyWrapper designated by the given URI. The only
+// supported KMS is currently the HashiCorp Vault transit secret engine, with
+// URIs of the form "vault-transit://<mount>/<key name>". The Vault server is
+// the one configured for the secret references.
+func NewKeyWrapper(uri string) (KeyWrapper, error) {
+	scheme, keyPath, ok := strings.Cut(uri, refSeparator)
+	if !ok {
+		return nil, fmt.Errorf("%w %q: missing %q separator", ErrInvalidKeyWrapper, uri, refSeparator)
+	}
+
+	switch scheme {
+	case vaultTransitScheme:
+		mount, key := path.Split(strings.Trim(keyPath, "/"))
+		if mount == "" || key == "" {
+			return nil, fmt.Errorf(`%w %q: expected "%s://<mount>/<key name>"`,
+				ErrInvalidKeyWrapper, uri, vaultTransitScheme)
+		}
+
+		vault, _ := getProvider("vault")
+
+		return &vaultTransit{
+			vault: vault.(*vaultProvider), //nolint:forcetypeassert //the vault provider always has this type
+			mount: strings.Trim(mount, "/"),
+			key:   key,
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnknownKeyWrapper, scheme)
+	}
+}
+
+// vaultTransit wraps keys using the HashiCorp Vault transit secret engine.
+type vaultTransit struct {
+	vault      *vaultProvider
+	mount, key string
+}
+
+type vaultTransitResponse struct {
+	Data struct {
+		Plaintext  string `json:"plaintext"`
+		Ciphertext string `json:"ciphertext"`
+	} `json:"data"`
+}
+
+func (v *vaultTransit) WrapKey(key []byte) ([]byte, error) {
+	var resp vaultTransitResponse
+
+	if err := v.vault.request(http.MethodPost, path.Join(v.mount, "encrypt", v.key),
+		map[string]string{"plaintext": base64.StdEncoding.EncodeToString(key)},
+		&resp); err != nil {
+		return nil, fmt.Errorf("failed to wrap the key: %w", err)
+	}
+
+	return []byte(resp.Data.Ciphertext), nil
+}
+
+func (v *vaultTransit) UnwrapKey(wrapped []byte) ([]byte, error) {
+	var resp vaultTransitResponse
+
+	if err := v.vault.request(http.MethodPost, path.Join(v.mount, "decrypt", v.key),
+		map[string]string{"ciphertext": strings.TrimSpace(string(wrapped))},
+		&resp); err != nil {
+		return nil, fmt.Errorf("failed to unwrap the key: %w", err)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(resp.Data.Plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the unwrapped key: %w", err)
+	}
+
+	return key, nil
+}
@@ -0,0 +1,65 @@
+package secrets
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/conf"
+)
+
+//nolint:paralleltest //the test modifies the global provider registry
+func TestVaultTransitKeyWrapper(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		// This fake transit engine simply prefixes the plain text.
+		switch r.URL.Path {
+		case "/v1/transit/encrypt/gateway":
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]string{
+				"ciphertext": "vault:v1:" + body["plaintext"],
+			}})
+		case "/v1/transit/decrypt/gateway":
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]string{
+				"plaintext": strings.TrimPrefix(body["ciphertext"], "vault:v1:"),
+			}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	require.NoError(t, Configure(&conf.SecretsConfig{VaultAddress: server.URL, VaultToken: "token"}))
+	t.Cleanup(func() { Register("vault", &vaultProvider{}) })
+
+	t.Run("Given a valid transit key", func(t *testing.T) {
+		wrapper, err := NewKeyWrapper("vault-transit://transit/gateway")
+		require.NoError(t, err)
+
+		wrapped, err := wrapper.WrapKey([]byte("data key"))
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(wrapped), "vault:v1:"))
+
+		key, err := wrapper.UnwrapKey(wrapped)
+		require.NoError(t, err)
+		assert.Equal(t, "data key", string(key))
+	})
+
+	t.Run("Given an invalid key wrapper", func(t *testing.T) {
+		_, err := NewKeyWrapper("vault-transit://gateway")
+		require.ErrorIs(t, err, ErrInvalidKeyWrapper)
+
+		_, err = NewKeyWrapper("aws-kms://gateway")
+		require.ErrorIs(t, err, ErrUnknownKeyWrapper)
+	})
+}
@@ -0,0 +1,36 @@
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// envProvider reads secrets from environment variables.
+type envProvider struct{}
+
+func (envProvider) Fetch(ref *Reference) (string, error) {
+	val, ok := os.LookupEnv(ref.Path)
+	if !ok {
+		return "", fmt.Errorf("%w: environment variable %q is not set", ErrSecretNotFound, ref.Path)
+	}
+
+	return extractKey([]byte(val), ref)
+}
+
+// fileProvider reads secrets from files. Trailing newlines are removed.
+type fileProvider struct{}
+
+func (fileProvider) Fetch(ref *Reference) (string, error) {
+	content, err := os.ReadFile(ref.Path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+
+	secret, err := extractKey(content, ref)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(secret, "\r\n"), nil
+}
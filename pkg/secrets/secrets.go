@@ -0,0 +1,235 @@
+// Package secrets contains the code for resolving the secret references which
+// can be given in place of secrets (passwords, private keys, passphrases...).
+//
+// A secret reference is a string of the form "ref+<provider>://<path>[#<key>]".
+// When a reference is given instead of a secret, only the reference is stored
+// in the database, and the actual secret is fetched from the designated
+// provider when it is needed. The following providers are available:
+//   - env: the secret is read from the environment variable <path>
+//   - file: the secret is read from the file at <path>
+//   - vault: the secret is read from the HashiCorp Vault KV secret at <path>
+//
+// If a key is given, the secret's content is parsed as a JSON object, and the
+// value of the given key is returned (for Vault, the key is mandatory and
+// designates a field of the KV secret).
+package secrets
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/conf"
+)
+
+const (
+	refPrefix    = "ref+"
+	refSeparator = "://"
+	keySeparator = "#"
+)
+
+var (
+	ErrInvalidReference = errors.New("invalid secret reference")
+	ErrUnknownProvider  = errors.New("unknown secret provider")
+	ErrSecretNotFound   = errors.New("secret not found")
+)
+
+// Reference represents a parsed secret reference.
+type Reference struct {
+	Provider string
+	Path     string
+	Key      string
+}
+
+func (r *Reference) String() string {
+	ref := refPrefix + r.Provider + refSeparator + r.Path
+	if r.Key != "" {
+		ref += keySeparator + r.Key
+	}
+
+	return ref
+}
+
+// Provider is the interface which must be implemented by the secret stores.
+type Provider interface {
+	// Fetch returns the secret designated by the given reference.
+	Fetch(ref *Reference) (string, error)
+}
+
+//nolint:gochecknoglobals //global vars are needed here for the provider registry
+var (
+	providersMx sync.RWMutex
+	providers   = map[string]Provider{
+		"env":   envProvider{},
+		"file":  fileProvider{},
+		"vault": &vaultProvider{},
+	}
+)
+
+// Register adds the given provider to the list of known secret providers.
+// If a provider with the same name already exists, it is replaced.
+func Register(name string, provider Provider) {
+	providersMx.Lock()
+	defer providersMx.Unlock()
+
+	providers[name] = provider
+}
+
+func getProvider(name string) (Provider, bool) {
+	providersMx.RLock()
+	defer providersMx.RUnlock()
+
+	provider, ok := providers[name]
+
+	return provider, ok
+}
+
+// Configure configures the secret providers and the resolution cache using the
+// given configuration.
+func Configure(config *conf.SecretsConfig) error {
+	vault, err := newVaultProvider(config)
+	if err != nil {
+		return err
+	}
+
+	Register("vault", vault)
+	globalCache.setTTL(config.CacheTTL)
+	globalCache.flush()
+
+	return nil
+}
+
+// IsReference returns whether the given value is a secret reference.
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, refPrefix) && strings.Contains(value, refSeparator)
+}
+
+// ParseReference parses the given secret reference.
+func ParseReference(value string) (*Reference, error) {
+	if !strings.HasPrefix(value, refPrefix) {
+		return nil, fmt.Errorf("%w: missing %q prefix", ErrInvalidReference, refPrefix)
+	}
+
+	provider, path, ok := strings.Cut(strings.TrimPrefix(value, refPrefix), refSeparator)
+	if !ok {
+		return nil, fmt.Errorf("%w: missing %q separator", ErrInvalidReference, refSeparator)
+	}
+
+	ref := &Reference{Provider: provider, Path: path}
+
+	if idx := strings.LastIndex(path, keySeparator); idx >= 0 {
+		ref.Path, ref.Key = path[:idx], path[idx+1:]
+	}
+
+	if ref.Provider == "" {
+		return nil, fmt.Errorf("%w: missing provider", ErrInvalidReference)
+	}
+
+	if ref.Path == "" {
+		return nil, fmt.Errorf("%w: missing path", ErrInvalidReference)
+	}
+
+	return ref, nil
+}
+
+// Validate checks that the given value, if it is a secret reference, is
+// syntactically valid and designates a known provider. Values which are not
+// secret references are always valid.
+func Validate(value string) error {
+	if !IsReference(value) {
+		return nil
+	}
+
+	ref, err := ParseReference(value)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := getProvider(ref.Provider); !ok {
+		return fmt.Errorf("%w %q", ErrUnknownProvider, ref.Provider)
+	}
+
+	return nil
+}
+
+// Resolve returns the secret designated by the given value if it is a secret
+// reference. Otherwise, the value is returned unchanged.
+func Resolve(value string) (string, error) {
+	if !IsReference(value) {
+		return value, nil
+	}
+
+	if secret, ok := globalCache.get(value); ok {
+		return secret, nil
+	}
+
+	ref, err := ParseReference(value)
+	if err != nil {
+		return "", err
+	}
+
+	provider, ok := getProvider(ref.Provider)
+	if !ok {
+		return "", fmt.Errorf("%w %q", ErrUnknownProvider, ref.Provider)
+	}
+
+	secret, err := provider.Fetch(ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve secret reference %q: %w", value, err)
+	}
+
+	globalCache.set(value, secret)
+
+	return secret, nil
+}
+
+// ResolveAll resolves all the given values in place.
+func ResolveAll(values ...*string) error {
+	for _, value := range values {
+		secret, err := Resolve(*value)
+		if err != nil {
+			return err
+		}
+
+		*value = secret
+	}
+
+	return nil
+}
+
+// Flush empties the secret cache, forcing all references to be fetched again
+// the next time they are resolved.
+func Flush() {
+	globalCache.flush()
+}
+
+func extractKey(content []byte, ref *Reference) (string, error) {
+	if ref.Key == "" {
+		return string(content), nil
+	}
+
+	var object map[string]any
+	if err := json.Unmarshal(content, &object); err != nil {
+		return "", fmt.Errorf("failed to parse the secret as a JSON object: %w", err)
+	}
+
+	return getKey(object, ref.Key)
+}
+
+func getKey(object map[string]any, key string) (string, error) {
+	val, ok := object[key]
+	if !ok {
+		return "", fmt.Errorf("%w: no key %q", ErrSecretNotFound, key)
+	}
+
+	switch secret := val.(type) {
+	case string:
+		return secret, nil
+	case nil:
+		return "", nil
+	default:
+		return fmt.Sprint(secret), nil
+	}
+}
@@ -0,0 +1,177 @@
+package secrets
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/conf"
+)
+
+func TestParseReference(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Given a reference with a key", func(t *testing.T) {
+		t.Parallel()
+
+		ref, err := ParseReference("ref+vault://secret/data/gateway#password")
+		require.NoError(t, err)
+
+		assert.Equal(t, "vault", ref.Provider)
+		assert.Equal(t, "secret/data/gateway", ref.Path)
+		assert.Equal(t, "password", ref.Key)
+		assert.Equal(t, "ref+vault://secret/data/gateway#password", ref.String())
+	})
+
+	t.Run("Given a reference without a key", func(t *testing.T) {
+		t.Parallel()
+
+		ref, err := ParseReference("ref+file:///run/secrets/password")
+		require.NoError(t, err)
+
+		assert.Equal(t, "file", ref.Provider)
+		assert.Equal(t, "/run/secrets/password", ref.Path)
+		assert.Empty(t, ref.Key)
+	})
+
+	t.Run("Given an invalid reference", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := ParseReference("ref+://path")
+		require.ErrorIs(t, err, ErrInvalidReference)
+
+		_, err = ParseReference("ref+env://")
+		require.ErrorIs(t, err, ErrInvalidReference)
+	})
+
+	t.Run("Given values which are not references", func(t *testing.T) {
+		t.Parallel()
+
+		assert.False(t, IsReference("sesame"))
+		assert.False(t, IsReference("ref+sesame"))
+		assert.False(t, IsReference("dGVzdA=="))
+		assert.True(t, IsReference("ref+env://PASSWORD"))
+	})
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, Validate("sesame"))
+	require.NoError(t, Validate("ref+env://PASSWORD"))
+	require.ErrorIs(t, Validate("ref+unknown://PASSWORD"), ErrUnknownProvider)
+}
+
+//nolint:paralleltest //cannot use t.Setenv in parallel tests
+func TestResolveEnv(t *testing.T) {
+	Flush()
+	t.Setenv("WAARP_TEST_SECRET", "sesame")
+	t.Setenv("WAARP_TEST_JSON_SECRET", `{"user":"toto","password":"sesame"}`)
+
+	t.Run("Given a plain value", func(t *testing.T) {
+		secret, err := Resolve("sesame")
+		require.NoError(t, err)
+		assert.Equal(t, "sesame", secret)
+	})
+
+	t.Run("Given an environment variable reference", func(t *testing.T) {
+		secret, err := Resolve("ref+env://WAARP_TEST_SECRET")
+		require.NoError(t, err)
+		assert.Equal(t, "sesame", secret)
+	})
+
+	t.Run("Given an environment variable reference with a key", func(t *testing.T) {
+		secret, err := Resolve("ref+env://WAARP_TEST_JSON_SECRET#password")
+		require.NoError(t, err)
+		assert.Equal(t, "sesame", secret)
+	})
+
+	t.Run("Given a reference to an unknown variable", func(t *testing.T) {
+		_, err := Resolve("ref+env://WAARP_TEST_UNKNOWN_SECRET")
+		require.ErrorIs(t, err, ErrSecretNotFound)
+	})
+}
+
+func TestResolveFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "secret")
+	require.NoError(t, os.WriteFile(path, []byte("sesame\n"), 0o600))
+
+	secret, err := Resolve("ref+file://" + filepath.ToSlash(path))
+	require.NoError(t, err)
+	assert.Equal(t, "sesame", secret, "then the trailing newline should be removed")
+}
+
+//nolint:paralleltest //the test modifies the global provider registry
+func TestResolveVault(t *testing.T) {
+	Flush()
+
+	const token = "test-token"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != token {
+			w.WriteHeader(http.StatusForbidden)
+
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v1/secret/data/gateway":
+			_, _ = w.Write([]byte(`{"data":{"data":{"password":"sesame"},"metadata":{"version":1}}}`))
+		case "/v1/kv/gateway":
+			_, _ = w.Write([]byte(`{"data":{"password":"open"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	require.NoError(t, Configure(&conf.SecretsConfig{
+		CacheTTL:     time.Minute,
+		VaultAddress: server.URL,
+		VaultToken:   token,
+	}))
+	t.Cleanup(func() { Register("vault", &vaultProvider{}) })
+
+	t.Run("Given a KV v2 secret", func(t *testing.T) {
+		secret, err := Resolve("ref+vault://secret/data/gateway#password")
+		require.NoError(t, err)
+		assert.Equal(t, "sesame", secret)
+	})
+
+	t.Run("Given a KV v1 secret", func(t *testing.T) {
+		secret, err := Resolve("ref+vault://kv/gateway#password")
+		require.NoError(t, err)
+		assert.Equal(t, "open", secret)
+	})
+
+	t.Run("Given an unknown secret", func(t *testing.T) {
+		_, err := Resolve("ref+vault://secret/data/unknown#password")
+		require.ErrorIs(t, err, ErrSecretNotFound)
+	})
+
+	t.Run("Given an unknown key", func(t *testing.T) {
+		_, err := Resolve("ref+vault://secret/data/gateway#login")
+		require.ErrorIs(t, err, ErrSecretNotFound)
+	})
+
+	t.Run("Given a reference without a key", func(t *testing.T) {
+		_, err := Resolve("ref+vault://secret/data/gateway")
+		require.ErrorIs(t, err, ErrVaultNoKey)
+	})
+
+	t.Run("Given a cached secret", func(t *testing.T) {
+		server.Close()
+
+		secret, err := Resolve("ref+vault://secret/data/gateway#password")
+		require.NoError(t, err)
+		assert.Equal(t, "sesame", secret, "then the cached secret should be returned")
+	})
+}
@@ -0,0 +1,199 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/conf"
+)
+
+const (
+	vaultAddrEnvVar  = "VAULT_ADDR"
+	vaultTokenEnvVar = "VAULT_TOKEN"
+	vaultTimeout     = 10 * time.Second
+)
+
+var (
+	ErrVaultNoAddress = errors.New("no Vault address configured")
+	ErrVaultNoToken   = errors.New("no Vault token configured")
+	ErrVaultNoKey     = errors.New("vault secret references must specify a key")
+	errVaultBadCACert = errors.New("failed to parse the Vault CA certificate")
+)
+
+// vaultProvider reads secrets from a HashiCorp Vault KV secret engine (both
+// versions 1 and 2 are supported). For KV v2, the path must include the "data"
+// segment (ex: "secret/data/gateway").
+type vaultProvider struct {
+	address   string
+	token     string
+	namespace string
+	client    *http.Client
+}
+
+func newVaultProvider(config *conf.SecretsConfig) (*vaultProvider, error) {
+	vault := &vaultProvider{
+		address:   config.VaultAddress,
+		token:     config.VaultToken,
+		namespace: config.VaultNamespace,
+	}
+
+	if config.VaultTokenFile != "" {
+		token, err := os.ReadFile(config.VaultTokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the Vault token file: %w", err)
+		}
+
+		vault.token = strings.TrimSpace(string(token))
+	}
+
+	if config.VaultCACert != "" {
+		pem, err := os.ReadFile(config.VaultCACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the Vault CA certificate: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errVaultBadCACert
+		}
+
+		vault.client = &http.Client{
+			Timeout: vaultTimeout,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12},
+			},
+		}
+	}
+
+	return vault, nil
+}
+
+func (v *vaultProvider) getClient() *http.Client {
+	if v.client != nil {
+		return v.client
+	}
+
+	return &http.Client{Timeout: vaultTimeout}
+}
+
+func (v *vaultProvider) getAddress() string {
+	if v.address != "" {
+		return v.address
+	}
+
+	return os.Getenv(vaultAddrEnvVar)
+}
+
+func (v *vaultProvider) getToken() string {
+	if v.token != "" {
+		return v.token
+	}
+
+	return os.Getenv(vaultTokenEnvVar)
+}
+
+func (v *vaultProvider) Fetch(ref *Reference) (string, error) {
+	if ref.Key == "" {
+		return "", ErrVaultNoKey
+	}
+
+	var secret struct {
+		Data map[string]any `json:"data"`
+	}
+
+	if err := v.request(http.MethodGet, ref.Path, nil, &secret); err != nil {
+		return "", err
+	}
+
+	// KV v2 engines wrap the secret's fields in a "data" object, alongside
+	// a "metadata" object.
+	if data, isMap := secret.Data["data"].(map[string]any); isMap {
+		if _, hasMeta := secret.Data["metadata"]; hasMeta {
+			return getKey(data, ref.Key)
+		}
+	}
+
+	return getKey(secret.Data, ref.Key)
+}
+
+// request sends a request to the Vault API at the given path, with the given
+// body (if any) encoded in JSON, and decodes the JSON response in out.
+func (v *vaultProvider) request(method, path string, body, out any) error {
+	addr := v.getAddress()
+	if addr == "" {
+		return ErrVaultNoAddress
+	}
+
+	token := v.getToken()
+	if token == "" {
+		return ErrVaultNoToken
+	}
+
+	reqURL, err := url.JoinPath(addr, "v1", path)
+	if err != nil {
+		return fmt.Errorf("failed to build the Vault URL: %w", err)
+	}
+
+	var reqBody io.Reader
+
+	if body != nil {
+		content, jsonErr := json.Marshal(body)
+		if jsonErr != nil {
+			return fmt.Errorf("failed to encode the Vault request: %w", jsonErr)
+		}
+
+		reqBody = bytes.NewReader(content)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), vaultTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to build the Vault request: %w", err)
+	}
+
+	req.Header.Set("X-Vault-Token", token)
+
+	if v.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.namespace)
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := v.getClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to query Vault: %w", err)
+	}
+
+	defer resp.Body.Close() //nolint:errcheck //error is irrelevant here
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("%w: no Vault secret at %q", ErrSecretNotFound, path)
+	case resp.StatusCode != http.StatusOK:
+		content, _ := io.ReadAll(resp.Body) //nolint:errcheck //best effort, only used for the error message
+
+		//nolint:err113 //too specific to have a base error
+		return fmt.Errorf("vault responded with %s: %s", resp.Status, strings.TrimSpace(string(content)))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to parse the Vault response: %w", err)
+	}
+
+	return nil
+}
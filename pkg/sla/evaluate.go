@@ -0,0 +1,242 @@
+package sla
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/types"
+)
+
+// scope is the set of transfers to which an SLA applies.
+type scope struct {
+	rule    *model.Rule
+	partner *model.RemoteAgent
+}
+
+func getScope(db database.ReadAccess, sla *model.SLA) (*scope, error) {
+	var sc scope
+
+	if sla.RuleID.Valid {
+		sc.rule = &model.Rule{}
+		if err := db.Get(sc.rule, "id=?", sla.RuleID.Int64).Run(); err != nil {
+			return nil, fmt.Errorf("failed to retrieve the SLA's rule: %w", err)
+		}
+	}
+
+	if sla.RemoteAgentID.Valid {
+		sc.partner = &model.RemoteAgent{}
+		if err := db.Get(sc.partner, "id=?", sla.RemoteAgentID.Int64).Run(); err != nil {
+			return nil, fmt.Errorf("failed to retrieve the SLA's partner: %w", err)
+		}
+	}
+
+	return &sc, nil
+}
+
+// selectTransfers returns a query selecting the transfers (both ongoing and
+// finished) to which the SLA applies.
+func (sc *scope) selectTransfers(db database.ReadAccess, transfers *model.NormalizedTransfers,
+) *database.SelectQuery {
+	query := db.Select(transfers).Owner()
+
+	if sc.rule != nil {
+		query.Where("rule=? AND is_send=?", sc.rule.Name, sc.rule.IsSend)
+	}
+
+	if sc.partner != nil {
+		query.Where("is_server=? AND agent=?", false, sc.partner.Name)
+	}
+
+	return query
+}
+
+// evaluate returns the new breaches of the given SLA, that is, the breaches
+// which have not already been recorded in the database. Only the transfers
+// which ended after the given date are checked against the SLA's duration &
+// throughput objectives (ongoing transfers are always checked).
+func evaluate(db database.ReadAccess, sla *model.SLA, since, now time.Time,
+) ([]*model.SLABreach, error) {
+	sc, err := getScope(db, sla)
+	if err != nil {
+		return nil, err
+	}
+
+	var breaches []*model.SLABreach
+
+	for _, check := range []func(database.ReadAccess, *model.SLA, *scope, time.Time,
+		time.Time) ([]*model.SLABreach, error){
+		checkDeadline, checkDuration, checkThroughput,
+	} {
+		newBreaches, checkErr := check(db, sla, sc, since, now)
+		if checkErr != nil {
+			return nil, checkErr
+		}
+
+		for _, breach := range newBreaches {
+			if exists, existErr := isRecorded(db, breach); existErr != nil {
+				return nil, existErr
+			} else if !exists {
+				breaches = append(breaches, breach)
+			}
+		}
+	}
+
+	return breaches, nil
+}
+
+func isRecorded(db database.ReadAccess, breach *model.SLABreach) (bool, error) {
+	query := db.Count(&model.SLABreach{}).Where("sla_id=? AND kind=?", breach.SLAID, breach.Kind)
+
+	if breach.TransferID.Valid {
+		query.Where("transfer_id=?", breach.TransferID.Int64)
+	} else {
+		query.Where("date=?", breach.Date.UTC())
+	}
+
+	n, err := query.Run()
+	if err != nil {
+		return false, fmt.Errorf("failed to check for existing SLA breaches: %w", err)
+	}
+
+	return n != 0, nil
+}
+
+// checkDeadline checks whether a transfer completed on the current day before
+// the SLA's deadline (if the deadline has passed).
+func checkDeadline(db database.ReadAccess, sla *model.SLA, sc *scope, _, now time.Time,
+) ([]*model.SLABreach, error) {
+	now = now.Local()
+
+	deadline, ok := sla.DeadlineOn(now)
+	if !ok || now.Before(deadline) {
+		return nil, nil
+	}
+
+	year, month, day := now.Date()
+	dayStart := time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+
+	var transfers model.NormalizedTransfers
+
+	n, err := sc.selectTransfers(db, &transfers).
+		Where("status=?", types.StatusDone).
+		Where("stop >= ? AND stop <= ?", dayStart.UTC(), deadline.UTC()).
+		Count()
+	if err != nil {
+		return nil, fmt.Errorf("failed to count the transfers completed before the deadline: %w", err)
+	}
+
+	if n != 0 {
+		return nil, nil
+	}
+
+	return []*model.SLABreach{{
+		SLAID: sla.ID,
+		Kind:  model.SLABreachDeadline,
+		Date:  deadline,
+		Details: fmt.Sprintf("no transfer completed before %s on %s", sla.Deadline,
+			deadline.Format(time.DateOnly)),
+	}}, nil
+}
+
+// checkDuration checks whether the ongoing transfers have been running, and
+// the finished transfers ran, for longer than the SLA's maximum duration.
+func checkDuration(db database.ReadAccess, sla *model.SLA, sc *scope, since, now time.Time,
+) ([]*model.SLABreach, error) {
+	if sla.MaxDuration <= 0 {
+		return nil, nil
+	}
+
+	var ongoing model.NormalizedTransfers
+	if err := sc.selectTransfers(db, &ongoing).
+		Where("is_transfer=?", true).
+		In("status", types.StatusRunning, types.StatusPaused, types.StatusInterrupted,
+			types.StatusError).
+		Where("start <= ?", now.Add(-sla.MaxDuration).UTC()).
+		Run(); err != nil {
+		return nil, fmt.Errorf("failed to retrieve the ongoing transfers: %w", err)
+	}
+
+	breaches := make([]*model.SLABreach, 0, len(ongoing))
+
+	for _, trans := range ongoing {
+		breaches = append(breaches, newTransferBreach(sla, trans, model.SLABreachDuration,
+			fmt.Sprintf("transfer n°%d has been running for %s (maximum: %s)", trans.ID,
+				now.Sub(trans.Start).Round(time.Second), sla.MaxDuration)))
+	}
+
+	finished, err := finishedTransfers(db, sc, since)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, trans := range finished {
+		if duration := trans.Stop.Sub(trans.Start); duration > sla.MaxDuration {
+			breaches = append(breaches, newTransferBreach(sla, trans, model.SLABreachDuration,
+				fmt.Sprintf("transfer n°%d took %s to complete (maximum: %s)", trans.ID,
+					duration.Round(time.Second), sla.MaxDuration)))
+		}
+	}
+
+	return breaches, nil
+}
+
+// checkThroughput checks whether the average throughput of the finished
+// transfers was above the SLA's minimum throughput.
+func checkThroughput(db database.ReadAccess, sla *model.SLA, sc *scope, since, _ time.Time,
+) ([]*model.SLABreach, error) {
+	if sla.MinThroughput <= 0 {
+		return nil, nil
+	}
+
+	finished, err := finishedTransfers(db, sc, since)
+	if err != nil {
+		return nil, err
+	}
+
+	var breaches []*model.SLABreach
+
+	for _, trans := range finished {
+		duration := trans.Stop.Sub(trans.Start)
+		if trans.Filesize <= 0 || duration <= 0 {
+			continue
+		}
+
+		throughput := int64(float64(trans.Filesize) / duration.Seconds())
+		if throughput < sla.MinThroughput {
+			breaches = append(breaches, newTransferBreach(sla, trans, model.SLABreachThroughput,
+				fmt.Sprintf("transfer n°%d had an average throughput of %d B/s (minimum: %d B/s)",
+					trans.ID, throughput, sla.MinThroughput)))
+		}
+	}
+
+	return breaches, nil
+}
+
+func finishedTransfers(db database.ReadAccess, sc *scope, since time.Time,
+) (model.NormalizedTransfers, error) {
+	var finished model.NormalizedTransfers
+	if err := sc.selectTransfers(db, &finished).
+		Where("is_transfer=?", false).
+		Where("status=?", types.StatusDone).
+		Where("stop >= ?", since.UTC()).
+		Run(); err != nil {
+		return nil, fmt.Errorf("failed to retrieve the finished transfers: %w", err)
+	}
+
+	return finished, nil
+}
+
+func newTransferBreach(sla *model.SLA, trans *model.NormalizedTransferView,
+	kind model.SLABreachKind, details string,
+) *model.SLABreach {
+	return &model.SLABreach{
+		SLAID:      sla.ID,
+		Kind:       kind,
+		TransferID: sql.NullInt64{Int64: trans.ID, Valid: true},
+		Date:       trans.Start,
+		Details:    details,
+	}
+}
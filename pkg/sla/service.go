@@ -0,0 +1,205 @@
+// Package sla contains the service responsible for evaluating the transfers'
+// SLAs, and for alerting when one of them is breached.
+package sla
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/snmp"
+	"code.waarp.fr/apps/gateway/gateway/pkg/tasks"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils"
+)
+
+const ServiceName = "SLA monitor"
+
+const (
+	defaultEmailSubject = "[#LOCALHOST#] The SLA \"#SLANAME#\" has been breached (#SLAKIND#)"
+	defaultEmailBody    = "The SLA \"#SLANAME#\" has been breached on #SLADATE#: #SLADETAILS#.\n"
+)
+
+// initialLookback is how far back in the transfer history the service looks
+// for finished transfers on its first evaluation.
+const initialLookback = 24 * time.Hour
+
+// Service is the service responsible for periodically evaluating the SLAs
+// stored in the database against the transfers (both ongoing and finished),
+// and for alerting (via logs, SNMP notifications and emails) when one of them
+// is breached.
+//
+// The breaches are recorded in the database, so each breach is only notified
+// once, even after a restart of the gateway.
+type Service struct {
+	DB *database.DB
+
+	logger *log.Logger
+	state  utils.State
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	lastCheck time.Time
+}
+
+func (s *Service) Name() string { return ServiceName }
+
+func (s *Service) Start() error {
+	if s.state.IsRunning() {
+		return nil
+	}
+
+	s.logger = logging.NewLogger(ServiceName)
+
+	interval := s.DB.Config.SLA.CheckInterval
+	if interval <= 0 {
+		s.logger.Info("SLA monitoring is disabled.")
+
+		return nil
+	}
+
+	var ctx context.Context
+
+	ctx, s.cancel = context.WithCancel(context.Background())
+	s.done = make(chan struct{})
+
+	go s.run(ctx, interval)
+
+	s.state.Set(utils.StateRunning, "")
+
+	return nil
+}
+
+func (s *Service) run(ctx context.Context, interval time.Duration) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.check(time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) Stop(ctx context.Context) error {
+	if !s.state.IsRunning() {
+		return utils.ErrNotRunning
+	}
+
+	s.cancel()
+
+	select {
+	case <-s.done:
+	case <-ctx.Done():
+		return fmt.Errorf("failed to stop the SLA monitor: %w", ctx.Err())
+	}
+
+	s.state.Set(utils.StateOffline, "")
+
+	return nil
+}
+
+func (s *Service) State() (utils.StateCode, string) { return s.state.Get() }
+
+// check evaluates all the SLAs, and records & notifies their new breaches.
+func (s *Service) check(now time.Time) {
+	// The transfers which ended since the previous check (with a margin for
+	// the transfers which were being archived at that time) are evaluated.
+	since := now.Add(-initialLookback)
+	if !s.lastCheck.IsZero() {
+		since = s.lastCheck.Add(-s.DB.Config.SLA.CheckInterval)
+	}
+
+	var slas model.SLAs
+	if err := s.DB.Select(&slas).Owner().Run(); err != nil {
+		s.logger.Errorf("Failed to retrieve the SLAs: %v", err)
+
+		return
+	}
+
+	for _, sla := range slas {
+		breaches, err := evaluate(s.DB, sla, since, now)
+		if err != nil {
+			s.logger.Errorf("Failed to evaluate the SLA %q: %v", sla.Name, err)
+
+			continue
+		}
+
+		for _, breach := range breaches {
+			breach.Detected = now
+
+			if err := s.DB.Insert(breach).Run(); err != nil {
+				// If the breach violates the table's unique constraint, then it was
+				// already recorded (and notified) by another gateway instance.
+				if recorded, _ := isRecorded(s.DB, breach); recorded {
+					s.logger.Debugf("The breach of the SLA %q was already recorded", sla.Name)
+				} else {
+					s.logger.Errorf("Failed to record the breach of the SLA %q: %v", sla.Name, err)
+				}
+
+				continue
+			}
+
+			s.alert(sla, breach)
+		}
+	}
+
+	s.lastCheck = now
+}
+
+func (s *Service) alert(sla *model.SLA, breach *model.SLABreach) {
+	s.logger.Warningf("The SLA %q has been breached (%s): %s", sla.Name, breach.Kind,
+		breach.Details)
+
+	transferID := ""
+	if breach.TransferID.Valid {
+		transferID = utils.FormatInt(breach.TransferID.Int64)
+	}
+
+	snmp.ReportSLABreach(sla.Name, string(breach.Kind), breach.Date, transferID, breach.Details)
+
+	if err := s.sendEmail(sla, breach, transferID); err != nil {
+		s.logger.Errorf("Failed to send the SLA breach alert email: %v", err)
+	}
+}
+
+// sendEmail sends the alert of the given breach by email, using the SMTP
+// credential and email template defined in the configuration.
+func (s *Service) sendEmail(sla *model.SLA, breach *model.SLABreach, transferID string) error {
+	config := &s.DB.Config.SLA
+	if config.EmailSender == "" || len(config.EmailRecipients) == 0 {
+		return nil
+	}
+
+	replacer := strings.NewReplacer(
+		"#LOCALHOST#", s.DB.Config.GatewayName,
+		"#SLANAME#", sla.Name,
+		"#SLAKIND#", string(breach.Kind),
+		"#SLADATE#", breach.Date.Local().Format(time.RFC3339),
+		"#SLADETAILS#", breach.Details,
+		"#TRANSFERID#", transferID,
+	)
+
+	params := map[string]string{
+		"sender":     config.EmailSender,
+		"recipients": strings.Join(config.EmailRecipients, ","),
+		"template":   config.EmailTemplate,
+	}
+
+	//nolint:wrapcheck //wrapping adds nothing here
+	return tasks.SendEmail(s.DB, s.logger, params, &model.EmailTemplate{
+		Subject:  defaultEmailSubject,
+		Body:     defaultEmailBody,
+		MIMEType: model.DefaultEmailMimeType,
+	}, replacer)
+}
@@ -0,0 +1,180 @@
+package sla
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database/dbtest"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/modeltest"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/types"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils"
+)
+
+const testProtocol = "test_proto"
+
+//nolint:gochecknoinits // init is used to ease the tests
+func init() {
+	modeltest.AddDummyProtoConfig(testProtocol)
+}
+
+//nolint:funlen //test setup is long
+func TestEvaluate(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+
+	year, month, day := time.Now().Date()
+	today := func(hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, time.Local)
+	}
+	now := today(12, 0)
+
+	rule := &model.Rule{Name: "send", IsSend: true}
+	require.NoError(t, db.Insert(rule).Run())
+
+	client := &model.Client{Name: "client", Protocol: testProtocol}
+	require.NoError(t, db.Insert(client).Run())
+
+	partner := &model.RemoteAgent{
+		Name: "partner", Protocol: testProtocol,
+		Address: types.Addr("localhost", 443),
+	}
+	require.NoError(t, db.Insert(partner).Run())
+
+	other := &model.RemoteAgent{
+		Name: "other", Protocol: testProtocol,
+		Address: types.Addr("localhost", 444),
+	}
+	require.NoError(t, db.Insert(other).Run())
+
+	account := &model.RemoteAccount{RemoteAgentID: partner.ID, Login: "toto"}
+	require.NoError(t, db.Insert(account).Run())
+
+	addHistory := func(id int64, start, stop time.Time) {
+		require.NoError(t, db.Insert(&model.HistoryEntry{
+			Identifier:       model.Identifier{ID: id},
+			Owner:            db.Config.GatewayName,
+			RemoteTransferID: utils.FormatInt(id),
+			IsSend:           true,
+			Rule:             rule.Name,
+			Account:          account.Login,
+			Agent:            partner.Name,
+			Client:           client.Name,
+			Protocol:         testProtocol,
+			SrcFilename:      "file",
+			Filesize:         1_000_000,
+			Start:            start,
+			Stop:             stop,
+			Status:           types.StatusDone,
+			Step:             types.StepNone,
+		}).Run())
+	}
+
+	addHistory(1, today(6, 0), today(6, 1))  // on time & fast enough
+	addHistory(2, today(9, 0), today(10, 0)) // too long & too slow
+
+	ongoing := &model.Transfer{
+		RuleID:          rule.ID,
+		ClientID:        client.NullableID(),
+		RemoteAccountID: account.NullableID(),
+		SrcFilename:     "file",
+		Start:           today(11, 0),
+		Status:          types.StatusRunning,
+	}
+	require.NoError(t, db.Insert(ongoing).Run())
+
+	ruleSLA := &model.SLA{
+		Name:          "rule-sla",
+		RuleID:        sql.NullInt64{Int64: rule.ID, Valid: true},
+		Deadline:      "07:00",
+		DeadlineDays:  model.List[string]{now.Weekday().String()},
+		MaxDuration:   30 * time.Minute,
+		MinThroughput: 1000,
+	}
+	require.NoError(t, db.Insert(ruleSLA).Run())
+
+	partnerSLA := &model.SLA{
+		Name:          "partner-sla",
+		RemoteAgentID: sql.NullInt64{Int64: other.ID, Valid: true},
+		Deadline:      "07:00",
+		DeadlineDays:  model.List[string]{now.Weekday().String()},
+	}
+	require.NoError(t, db.Insert(partnerSLA).Run())
+
+	since := now.Add(-initialLookback)
+
+	t.Run("When evaluating an SLA met by some transfers but not others", func(t *testing.T) {
+		breaches, err := evaluate(db, ruleSLA, since, now)
+		require.NoError(t, err)
+		require.Len(t, breaches, 3)
+
+		assert.Equal(t, model.SLABreachDuration, breaches[0].Kind)
+		assert.Equal(t, ongoing.ID, breaches[0].TransferID.Int64)
+		assert.Equal(t, model.SLABreachDuration, breaches[1].Kind)
+		assert.Equal(t, int64(2), breaches[1].TransferID.Int64)
+		assert.Equal(t, model.SLABreachThroughput, breaches[2].Kind)
+		assert.Equal(t, int64(2), breaches[2].TransferID.Int64)
+
+		t.Run("Then the recorded breaches should not be returned again", func(t *testing.T) {
+			for _, breach := range breaches {
+				breach.Detected = now
+				require.NoError(t, db.Insert(breach).Run())
+			}
+
+			again, err := evaluate(db, ruleSLA, since, now)
+			require.NoError(t, err)
+			assert.Empty(t, again)
+		})
+
+		t.Run("Then the same breach cannot be recorded twice", func(t *testing.T) {
+			duplicate := *breaches[1]
+			duplicate.ID = 0
+
+			require.Error(t, db.Insert(&duplicate).Run(),
+				"the breach should violate the unique constraint")
+
+			recorded, err := isRecorded(db, &duplicate)
+			require.NoError(t, err)
+			assert.True(t, recorded)
+		})
+
+		t.Run("Then the SLA's status should be breached", func(t *testing.T) {
+			status, last, err := Status(db, ruleSLA, now)
+			require.NoError(t, err)
+			assert.Equal(t, StatusBreached, status)
+			require.NotNil(t, last)
+
+			status, _, err = Status(db, ruleSLA, now.Add(24*time.Hour))
+			require.NoError(t, err)
+			assert.Equal(t, StatusOK, status, "the status should be reset the next day")
+		})
+	})
+
+	t.Run("When evaluating an SLA whose deadline was missed", func(t *testing.T) {
+		breaches, err := evaluate(db, partnerSLA, since, today(6, 59))
+		require.NoError(t, err)
+		assert.Empty(t, breaches, "the deadline has not passed yet")
+
+		breaches, err = evaluate(db, partnerSLA, since, now)
+		require.NoError(t, err)
+		require.Len(t, breaches, 1)
+
+		assert.Equal(t, model.SLABreachDeadline, breaches[0].Kind)
+		assert.False(t, breaches[0].TransferID.Valid)
+		assert.True(t, today(7, 0).Equal(breaches[0].Date))
+
+		t.Run("Then the recorded breach should not be returned again", func(t *testing.T) {
+			breaches[0].Detected = now
+			require.NoError(t, db.Insert(breaches[0]).Run())
+
+			again, err := evaluate(db, partnerSLA, since, now.Add(time.Hour))
+			require.NoError(t, err)
+			assert.Empty(t, again)
+		})
+	})
+}
@@ -0,0 +1,41 @@
+package sla
+
+import (
+	"fmt"
+	"time"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+)
+
+// The possible statuses of an SLA.
+const (
+	StatusOK       = "OK"
+	StatusBreached = "BREACHED"
+)
+
+// Status returns the status of the given SLA for the current (local) day: the
+// SLA is breached if at least one breach has been detected since the start of
+// the day. The function also returns the SLA's most recent breach (if any).
+func Status(db database.ReadAccess, sla *model.SLA, now time.Time,
+) (string, *model.SLABreach, error) {
+	var breaches model.SLABreaches
+	if err := db.Select(&breaches).Where("sla_id=?", sla.ID).
+		OrderBy("detected", false).Limit(1, 0).Run(); err != nil {
+		return "", nil, fmt.Errorf("failed to retrieve the SLA's last breach: %w", err)
+	}
+
+	if len(breaches) == 0 {
+		return StatusOK, nil, nil
+	}
+
+	now = now.Local()
+	year, month, day := now.Date()
+	dayStart := time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+
+	if last := breaches[0]; !last.Detected.Before(dayStart) {
+		return StatusBreached, last, nil
+	}
+
+	return StatusOK, breaches[0], nil
+}
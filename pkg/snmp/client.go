@@ -11,6 +11,7 @@ import (
 
 	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
 	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/secrets"
 	"code.waarp.fr/apps/gateway/gateway/pkg/utils"
 )
 
@@ -44,6 +45,11 @@ func connect(logger *log.Logger, config *MonitorConfig) (*gosnmp.GoSNMP, error)
 	}
 
 	if version == gosnmp.Version3 {
+		authPassphrase, privPassphrase := config.AuthPassphrase, config.PrivPassphrase
+		if err := secrets.ResolveAll(&authPassphrase, &privPassphrase); err != nil {
+			return nil, fmt.Errorf("failed to resolve the SNMPv3 passphrases: %w", err)
+		}
+
 		if config.AuthEngineID != "" {
 			var authEngineIDInt big.Int
 			if _, ok := authEngineIDInt.SetString(config.AuthEngineID, 0); ok {
@@ -60,8 +66,8 @@ func connect(logger *log.Logger, config *MonitorConfig) (*gosnmp.GoSNMP, error)
 			UserName:                 config.AuthUsername,
 			AuthenticationProtocol:   getAuthProtocol(config.AuthProtocol),
 			PrivacyProtocol:          getPrivProtocol(config.PrivProtocol),
-			AuthenticationPassphrase: config.AuthPassphrase,
-			PrivacyPassphrase:        config.PrivPassphrase,
+			AuthenticationPassphrase: authPassphrase,
+			PrivacyPassphrase:        privPassphrase,
 			Logger:                   snmpLogger,
 		}
 	}
@@ -210,3 +216,92 @@ func (s *Service) sendServiceError(service string, sErr error) error {
 
 	return s.sendTrap(&trap)
 }
+
+func (s *Service) sendCertificateExpiry(kind, owner, name string, notAfter time.Time,
+	daysLeft int,
+) error {
+	trap := gosnmp.SnmpTrap{
+		Variables: []gosnmp.SnmpPDU{
+			{
+				Value: s.sysUpTime(),
+				Name:  SNMPSysUpTimeOID,
+				Type:  gosnmp.TimeTicks,
+			}, {
+				Value: CertExpiryNotifOID,
+				Name:  SNMPTrapOID,
+				Type:  gosnmp.ObjectIdentifier,
+			}, {
+				Value: s.DB.Config.GatewayName,
+				Name:  InstanceNameOID,
+				Type:  gosnmp.OctetString,
+			}, {
+				Value: kind,
+				Name:  CeObjectKindOID,
+				Type:  gosnmp.OctetString,
+			}, {
+				Value: owner,
+				Name:  CeObjectOwnerOID,
+				Type:  gosnmp.OctetString,
+			}, {
+				Value: name,
+				Name:  CeObjectNameOID,
+				Type:  gosnmp.OctetString,
+			}, {
+				Value: notAfter.Local().Format(time.RFC3339),
+				Name:  CeObjectDateOID,
+				Type:  gosnmp.OctetString,
+			}, {
+				Value: daysLeft,
+				Name:  CeObjectDaysLeftOID,
+				Type:  gosnmp.Integer,
+			},
+		},
+		IsInform: false,
+	}
+
+	return s.sendTrap(&trap)
+}
+
+func (s *Service) sendSLABreach(name, kind string, date time.Time, transferID, details string,
+) error {
+	trap := gosnmp.SnmpTrap{
+		Variables: []gosnmp.SnmpPDU{
+			{
+				Value: s.sysUpTime(),
+				Name:  SNMPSysUpTimeOID,
+				Type:  gosnmp.TimeTicks,
+			}, {
+				Value: SLABreachNotifOID,
+				Name:  SNMPTrapOID,
+				Type:  gosnmp.ObjectIdentifier,
+			}, {
+				Value: s.DB.Config.GatewayName,
+				Name:  InstanceNameOID,
+				Type:  gosnmp.OctetString,
+			}, {
+				Value: name,
+				Name:  SbObjectNameOID,
+				Type:  gosnmp.OctetString,
+			}, {
+				Value: kind,
+				Name:  SbObjectKindOID,
+				Type:  gosnmp.OctetString,
+			}, {
+				Value: date.Local().Format(time.RFC3339),
+				Name:  SbObjectDateOID,
+				Type:  gosnmp.OctetString,
+			}, {
+				Value: transferID,
+				Name:  SbObjectTransferIDOID,
+				Type:  gosnmp.OctetString,
+			}, {
+				Value: details,
+				Name:  SbObjectDetailsOID,
+				Type:  gosnmp.OctetString,
+			},
+		},
+		IsInform: false,
+	}
+
+	return s.sendTrap(&trap)
+}
@@ -26,6 +26,8 @@ var (
 
 	ServiceErrorObjectsOID = NotifObjectsOID + ".1"
 	TransErrorObjectsOID   = NotifObjectsOID + ".2"
+	CertExpiryObjectsOID   = NotifObjectsOID + ".3"
+	SLABreachObjectsOID    = NotifObjectsOID + ".4"
 )
 
 // Application info OIDs.
@@ -107,3 +109,25 @@ var (
 	SeObjectNameOID  = ServiceErrorObjectsOID + ".1"
 	SeObjectErrorOID = ServiceErrorObjectsOID + ".2"
 )
+
+// Certificate expiry OIDs.
+var (
+	CertExpiryNotifOID = NotifsOID + ".3"
+
+	CeObjectKindOID     = CertExpiryObjectsOID + ".1"
+	CeObjectOwnerOID    = CertExpiryObjectsOID + ".2"
+	CeObjectNameOID     = CertExpiryObjectsOID + ".3"
+	CeObjectDateOID     = CertExpiryObjectsOID + ".4"
+	CeObjectDaysLeftOID = CertExpiryObjectsOID + ".5"
+)
+
+// SLA breach OIDs.
+var (
+	SLABreachNotifOID = NotifsOID + ".4"
+
+	SbObjectNameOID       = SLABreachObjectsOID + ".1"
+	SbObjectKindOID       = SLABreachObjectsOID + ".2"
+	SbObjectDateOID       = SLABreachObjectsOID + ".3"
+	SbObjectTransferIDOID = SLABreachObjectsOID + ".4"
+	SbObjectDetailsOID    = SLABreachObjectsOID + ".5"
+)
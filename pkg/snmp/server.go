@@ -7,12 +7,18 @@ import (
 	"github.com/gosnmp/gosnmp"
 	snmplib "github.com/slayercat/GoSNMPServer"
 
+	"code.waarp.fr/apps/gateway/gateway/pkg/secrets"
 	"code.waarp.fr/apps/gateway/gateway/pkg/utils"
 )
 
 var ErrNoAnalytics = errors.New("analytics service is not running")
 
 func (s *Service) listen(conf *ServerConfig) error {
+	authPassphrase, privPassphrase := conf.SNMPv3AuthPassphrase, conf.SNMPv3PrivPassphrase
+	if err := secrets.ResolveAll(&authPassphrase, &privPassphrase); err != nil {
+		return fmt.Errorf("failed to resolve the SNMPv3 passphrases: %w", err)
+	}
+
 	handler := snmplib.MasterAgent{
 		SecurityConfig: snmplib.SecurityConfig{
 			SnmpV3Only:               conf.SNMPv3Only,
@@ -21,8 +27,8 @@ func (s *Service) listen(conf *ServerConfig) error {
 				UserName:                 conf.SNMPv3Username,
 				AuthenticationProtocol:   getAuthProtocol(conf.SNMPv3AuthProtocol),
 				PrivacyProtocol:          getPrivProtocol(conf.SNMPv3PrivProtocol),
-				AuthenticationPassphrase: string(conf.SNMPv3AuthPassphrase),
-				PrivacyPassphrase:        string(conf.SNMPv3PrivPassphrase),
+				AuthenticationPassphrase: authPassphrase,
+				PrivacyPassphrase:        privPassphrase,
 			}},
 		},
 		SubAgents: []*snmplib.SubAgent{{
@@ -186,6 +186,30 @@ func ReportServiceFailure(service string, sErr error) {
 	}
 }
 
+// ReportCertificateExpiry sends an SNMP notification warning that the given
+// certificate (or key) will expire (or has expired) at the given date.
+func ReportCertificateExpiry(kind, owner, name string, notAfter time.Time, daysLeft int) {
+	if GlobalService == nil {
+		return
+	}
+
+	if err := GlobalService.sendCertificateExpiry(kind, owner, name, notAfter, daysLeft); err != nil {
+		GlobalService.Logger.Errorf("Failed to send certificate expiry notification: %v", err)
+	}
+}
+
+// ReportSLABreach sends an SNMP notification warning that the given SLA has
+// been breached. The transfer ID is empty for missed deadlines.
+func ReportSLABreach(name, kind string, date time.Time, transferID, details string) {
+	if GlobalService == nil {
+		return
+	}
+
+	if err := GlobalService.sendSLABreach(name, kind, date, transferID, details); err != nil {
+		GlobalService.Logger.Errorf("Failed to send SLA breach notification: %v", err)
+	}
+}
+
 // sysUpTime returns the elapsed time since the service started (in hundredths
 // of a second).
 func (s *Service) sysUpTime() uint32 {
@@ -59,6 +59,16 @@ func TestSNMPClient(t *testing.T) {
 			require.NoError(t, service.sendServiceError("test-service", testError))
 		})
 
+		t.Run("Reporting a certificate expiry", func(t *testing.T) {
+			require.NoError(t, service.sendCertificateExpiry("credential",
+				`server "sftp"`, "sftp-cert", time.Now().Add(72*time.Hour), 3))
+		})
+
+		t.Run("Reporting an SLA breach", func(t *testing.T) {
+			require.NoError(t, service.sendSLABreach("daily-report", "deadline",
+				time.Now(), "", "no transfer completed before 07:00"))
+		})
+
 		t.Run("Reporting a transfer error", func(t *testing.T) {
 			t.SkipNow()
 			transfer := &model.NormalizedTransferView{
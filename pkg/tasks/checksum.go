@@ -0,0 +1,168 @@
+package tasks
+
+import (
+	"context"
+	"crypto/md5"  //nolint:gosec //MD5 is only offered for compatibility, not for security
+	"crypto/sha1" //nolint:gosec //SHA-1 is only offered for compatibility, not for security
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/fs"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils"
+)
+
+const (
+	ChecksumMD5    = "MD5"
+	ChecksumSHA1   = "SHA-1"
+	ChecksumSHA256 = "SHA-256"
+	ChecksumSHA384 = "SHA-384"
+	ChecksumSHA512 = "SHA-512"
+	ChecksumCRC32  = "CRC32"
+
+	checksumDefaultAlgo = ChecksumSHA256
+	checksumDefaultInfo = "checksum"
+)
+
+var (
+	ErrChecksumUnknownAlgo = errors.New("unknown checksum algorithm")
+	ErrChecksumNoOutput    = errors.New(`at least one of "transferInfo" or "sidecar" must be set`)
+)
+
+type checksumAlgo struct {
+	new       func() hash.Hash
+	extension string
+}
+
+//nolint:gochecknoglobals //global var is needed here for the list of algorithms
+var checksumAlgos = map[string]checksumAlgo{
+	ChecksumMD5:    {md5.New, ".md5"},
+	ChecksumSHA1:   {sha1.New, ".sha1"},
+	ChecksumSHA256: {sha256.New, ".sha256"},
+	ChecksumSHA384: {sha512.New384, ".sha384"},
+	ChecksumSHA512: {sha512.New, ".sha512"},
+	ChecksumCRC32:  {func() hash.Hash { return crc32.NewIEEE() }, ".crc32"},
+}
+
+func getChecksumAlgo(name string) (checksumAlgo, error) {
+	if name == "" {
+		name = checksumDefaultAlgo
+	}
+
+	algo, ok := checksumAlgos[strings.ToUpper(name)]
+	if !ok {
+		return checksumAlgo{}, fmt.Errorf("%w %q", ErrChecksumUnknownAlgo, name)
+	}
+
+	return algo, nil
+}
+
+// hashFile returns the hex-encoded checksum of the given file's content.
+func hashFile(ctx context.Context, path string, algo checksumAlgo) (string, error) {
+	file, err := fs.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+
+	defer file.Close() //nolint:errcheck //error is irrelevant here
+
+	hasher := algo.new()
+	if err := utils.RunWithCtx(ctx, func() error {
+		_, cpErr := io.Copy(hasher, file)
+
+		return cpErr //nolint:wrapcheck //wrapped below
+	}); err != nil {
+		return "", fmt.Errorf("failed to hash file: %w", err)
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// formatSidecar returns the content of a checksum sidecar file, in the format
+// used by the "sha256sum" family of tools.
+func formatSidecar(sum, path string) string {
+	return fmt.Sprintf("%s  %s\n", sum, filepath.Base(path))
+}
+
+// checksumTask is a task which computes the checksum of the transfer file, and
+// stores it in the transfer info and/or in a sidecar file.
+type checksumTask struct {
+	Algorithm    string   `json:"algorithm"`
+	TransferInfo *string  `json:"transferInfo"`
+	Sidecar      jsonBool `json:"sidecar"`
+	SidecarPath  string   `json:"sidecarPath"`
+
+	algo checksumAlgo
+}
+
+func (c *checksumTask) Validate(args map[string]string) error {
+	*c = checksumTask{}
+
+	if err := utils.JSONConvert(args, c); err != nil {
+		return fmt.Errorf("failed to parse the checksum arguments: %w", err)
+	}
+
+	if c.TransferInfo == nil {
+		defaultInfo := checksumDefaultInfo
+		c.TransferInfo = &defaultInfo
+	}
+
+	if *c.TransferInfo == "" && !bool(c.Sidecar) && c.SidecarPath == "" {
+		return ErrChecksumNoOutput
+	}
+
+	var err error
+	c.algo, err = getChecksumAlgo(c.Algorithm)
+
+	return err
+}
+
+func (c *checksumTask) Run(ctx context.Context, args map[string]string, _ *database.DB,
+	logger *log.Logger, transCtx *model.TransferContext, _ any,
+) error {
+	if err := c.Validate(args); err != nil {
+		logger.Error(err.Error())
+
+		return err
+	}
+
+	filePath := transCtx.Transfer.LocalPath
+
+	sum, err := hashFile(ctx, filePath, c.algo)
+	if err != nil {
+		logger.Errorf("Failed to compute the file checksum: %v", err)
+
+		return err
+	}
+
+	if *c.TransferInfo != "" {
+		setTransferInfo(logger, transCtx, Checksum, *c.TransferInfo, sum)
+	}
+
+	if c.Sidecar || c.SidecarPath != "" {
+		sidecar := c.SidecarPath
+		if sidecar == "" {
+			sidecar = filePath + c.algo.extension
+		}
+
+		if err := fs.WriteFullFile(sidecar, []byte(formatSidecar(sum, filePath))); err != nil {
+			logger.Errorf("Failed to write the checksum file: %v", err)
+
+			return fmt.Errorf("failed to write the checksum file: %w", err)
+		}
+	}
+
+	logger.Debugf("File checksum computed: %s", sum)
+
+	return nil
+}
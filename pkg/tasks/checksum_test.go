@@ -0,0 +1,173 @@
+package tasks
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash/crc32"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/fs"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/types"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils/testhelpers"
+)
+
+const checksumTestContent = "checksum task test file"
+
+func makeChecksumTestFile(t *testing.T) (*model.TransferContext, string) {
+	t.Helper()
+
+	filePath := filepath.Join(t.TempDir(), "file.txt")
+	require.NoError(t, fs.WriteFullFile(filePath, []byte(checksumTestContent)))
+
+	sum := sha256.Sum256([]byte(checksumTestContent))
+
+	return &model.TransferContext{Transfer: &model.Transfer{LocalPath: filePath}},
+		hex.EncodeToString(sum[:])
+}
+
+func TestChecksumRun(t *testing.T) {
+	t.Parallel()
+
+	logger := testhelpers.GetTestLogger(t)
+
+	t.Run("Given the default arguments", func(t *testing.T) {
+		t.Parallel()
+
+		transCtx, sum := makeChecksumTestFile(t)
+
+		require.NoError(t, (&checksumTask{}).Run(t.Context(), map[string]string{},
+			nil, logger, transCtx, nil))
+		assert.Equal(t, sum, transCtx.Transfer.TransferInfo["checksum"],
+			"Then the checksum should have been stored in the transfer info")
+		assert.NoFileExists(t, transCtx.Transfer.LocalPath+".sha256",
+			"Then no sidecar file should have been written")
+	})
+
+	t.Run("Given a sidecar file", func(t *testing.T) {
+		t.Parallel()
+
+		transCtx, _ := makeChecksumTestFile(t)
+		args := map[string]string{"algorithm": "crc32", "transferInfo": "", "sidecar": "true"}
+
+		require.NoError(t, (&checksumTask{}).Run(t.Context(), args, nil, logger, transCtx, nil))
+
+		content, err := fs.ReadFullFile(transCtx.Transfer.LocalPath + ".crc32")
+		require.NoError(t, err)
+
+		crc := crc32.NewIEEE()
+		crc.Write([]byte(checksumTestContent))
+
+		assert.Equal(t, hex.EncodeToString(crc.Sum(nil))+"  file.txt\n", string(content),
+			"Then the sidecar file should contain the checksum")
+		assert.Empty(t, transCtx.Transfer.TransferInfo,
+			"Then the checksum should not have been stored in the transfer info")
+	})
+
+	t.Run("Given no output", func(t *testing.T) {
+		t.Parallel()
+
+		transCtx, _ := makeChecksumTestFile(t)
+
+		require.ErrorIs(t, (&checksumTask{}).Run(t.Context(), map[string]string{"transferInfo": ""},
+			nil, logger, transCtx, nil), ErrChecksumNoOutput)
+	})
+
+	t.Run("Given an unknown algorithm", func(t *testing.T) {
+		t.Parallel()
+
+		transCtx, _ := makeChecksumTestFile(t)
+
+		require.ErrorIs(t, (&checksumTask{}).Run(t.Context(), map[string]string{"algorithm": "MD4"},
+			nil, logger, transCtx, nil), ErrChecksumUnknownAlgo)
+	})
+}
+
+func TestVerifyChecksumRun(t *testing.T) {
+	t.Parallel()
+
+	logger := testhelpers.GetTestLogger(t)
+
+	t.Run("Given an expected value", func(t *testing.T) {
+		t.Parallel()
+
+		transCtx, sum := makeChecksumTestFile(t)
+
+		require.NoError(t, (&verifyChecksumTask{}).Run(t.Context(),
+			map[string]string{"expected": sum}, nil, logger, transCtx, nil))
+	})
+
+	t.Run("Given a transfer info", func(t *testing.T) {
+		t.Parallel()
+
+		transCtx, sum := makeChecksumTestFile(t)
+		transCtx.Transfer.TransferInfo = map[string]any{"hash": sum}
+
+		require.NoError(t, (&verifyChecksumTask{}).Run(t.Context(),
+			map[string]string{"transferInfo": "hash"}, nil, logger, transCtx, nil))
+
+		require.ErrorIs(t, (&verifyChecksumTask{}).Run(t.Context(),
+			map[string]string{"transferInfo": "missing"}, nil, logger, transCtx, nil),
+			ErrVerifyChecksumNoInfo)
+	})
+
+	t.Run("Given a sidecar file", func(t *testing.T) {
+		t.Parallel()
+
+		transCtx, sum := makeChecksumTestFile(t)
+		require.NoError(t, fs.WriteFullFile(transCtx.Transfer.LocalPath+".sha256",
+			[]byte(formatSidecar(sum, transCtx.Transfer.LocalPath))))
+
+		require.NoError(t, (&verifyChecksumTask{}).Run(t.Context(),
+			map[string]string{"sidecar": "true"}, nil, logger, transCtx, nil))
+	})
+
+	t.Run("Given a manifest", func(t *testing.T) {
+		t.Parallel()
+
+		transCtx, sum := makeChecksumTestFile(t)
+		manifest := filepath.Join(t.TempDir(), "SHA256SUMS")
+
+		for name, content := range map[string]string{
+			"GNU format": "0123  other.txt\n" + sum + " *dir/file.txt\n",
+			"BSD format": "SHA256 (other.txt) = 0123\nSHA256 (file.txt) = " + sum + "\n",
+		} {
+			require.NoError(t, fs.WriteFullFile(manifest, []byte(content)))
+			require.NoError(t, (&verifyChecksumTask{}).Run(t.Context(),
+				map[string]string{"manifest": manifest}, nil, logger, transCtx, nil), name)
+		}
+
+		require.NoError(t, fs.WriteFullFile(manifest, []byte("0123  other.txt\n")))
+		require.ErrorIs(t, (&verifyChecksumTask{}).Run(t.Context(),
+			map[string]string{"manifest": manifest}, nil, logger, transCtx, nil),
+			ErrVerifyChecksumNotInManifest)
+	})
+
+	t.Run("Given a mismatching checksum", func(t *testing.T) {
+		t.Parallel()
+
+		transCtx, _ := makeChecksumTestFile(t)
+
+		err := (&verifyChecksumTask{}).Run(t.Context(),
+			map[string]string{"expected": "0123456789abcdef"}, nil, logger, transCtx, nil)
+
+		var taskErr *Error
+		require.ErrorAs(t, err, &taskErr)
+		assert.Equal(t, types.TeIntegrity, taskErr.Code,
+			"Then the error should be an integrity error")
+	})
+
+	t.Run("Given multiple sources", func(t *testing.T) {
+		t.Parallel()
+
+		transCtx, sum := makeChecksumTestFile(t)
+
+		require.ErrorIs(t, (&verifyChecksumTask{}).Run(t.Context(),
+			map[string]string{"expected": sum, "sidecar": "true"}, nil, logger, transCtx, nil),
+			ErrVerifyChecksumManySources)
+	})
+}
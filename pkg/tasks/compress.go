@@ -0,0 +1,168 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/fs"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils"
+)
+
+var ErrCompressUnknownFormat = errors.New("unknown compression format")
+
+// compressTask is a task which compresses the transfer file (as a single file,
+// unlike ARCHIVE). The compressed file replaces the original one as the
+// transfer's file, and its name is suffixed with the format's extension.
+type compressTask struct {
+	Format           string   `json:"format"`
+	CompressionLevel string   `json:"compressionLevel"`
+	KeepOriginal     jsonBool `json:"keepOriginal"`
+
+	format *compressionFormat
+	level  int
+}
+
+func (c *compressTask) parseParams(params map[string]string) error {
+	*c = compressTask{}
+	if err := utils.JSONConvert(params, c); err != nil {
+		return fmt.Errorf("failed to parse the compress task parameters: %w", err)
+	}
+
+	if c.Format == "" {
+		c.Format = CompressGzip
+	}
+
+	var ok bool
+	if c.format, ok = CompressionFormats.Get(normalizeCompressionFormat(c.Format)); !ok {
+		return fmt.Errorf("%w %q", ErrCompressUnknownFormat, c.Format)
+	}
+
+	if c.CompressionLevel == "" {
+		c.level = defaultCompressionLevel
+	} else {
+		var err error
+		if c.level, err = strconv.Atoi(c.CompressionLevel); err != nil {
+			return fmt.Errorf("failed to parse the compression level: %w", err)
+		}
+
+		if !isValidCompressionLevel(c.level) {
+			return fmt.Errorf("%w %d", ErrInvalidCompressionLevel, c.level)
+		}
+	}
+
+	return nil
+}
+
+func (c *compressTask) Validate(params map[string]string) error {
+	return c.parseParams(params)
+}
+
+func (c *compressTask) Run(_ context.Context, params map[string]string, _ *database.DB,
+	logger *log.Logger, transCtx *model.TransferContext, _ any,
+) error {
+	if err := c.parseParams(params); err != nil {
+		logger.Error(err.Error())
+
+		return err
+	}
+
+	srcPath := transCtx.Transfer.LocalPath
+	dstPath := srcPath + c.format.extension
+
+	size, err := c.compress(srcPath, dstPath)
+	if err != nil {
+		logger.Errorf("Failed to compress file: %v", err)
+
+		return err
+	}
+
+	if !c.KeepOriginal {
+		if err := fs.Remove(srcPath); err != nil {
+			logger.Errorf("Failed to delete the original file: %v", err)
+
+			return fmt.Errorf("failed to delete the original file: %w", err)
+		}
+	}
+
+	updateCompressedTransfer(transCtx, dstPath, size, func(remotePath string) string {
+		return remotePath + c.format.extension
+	})
+
+	logger.Debugf("Compressed file %q to %q", srcPath, dstPath)
+
+	return nil
+}
+
+func (c *compressTask) compress(srcPath, dstPath string) (int64, error) {
+	srcFile, opErr := fs.Open(srcPath)
+	if opErr != nil {
+		return 0, fmt.Errorf("failed to open source file: %w", opErr)
+	}
+	defer srcFile.Close() //nolint:errcheck //close never returns an error on read-only files
+
+	return writeCompressionOutput(dstPath, func(dstFile fs.File) error {
+		compressor, err := c.format.compressor(dstFile, c.level)
+		if err != nil {
+			return fmt.Errorf("failed to initialize the compressor: %w", err)
+		}
+
+		if _, err := io.Copy(compressor, srcFile); err != nil {
+			return fmt.Errorf("failed to compress the file: %w", err)
+		}
+
+		if err := compressor.Close(); err != nil {
+			return fmt.Errorf("failed to finalize the compression: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// writeCompressionOutput creates the given output file, and fills it using the
+// given function. If an error occurs, the partial output file is removed. It
+// returns the size of the output file.
+func writeCompressionOutput(dstPath string, write func(fs.File) error) (int64, error) {
+	dstFile, opErr := fs.Create(dstPath)
+	if opErr != nil {
+		return 0, fmt.Errorf("failed to create destination file: %w", opErr)
+	}
+
+	if err := write(dstFile); err != nil {
+		_ = dstFile.Close()    //nolint:errcheck //the write error takes precedence
+		_ = fs.Remove(dstPath) //nolint:errcheck //the write error takes precedence
+
+		return 0, err
+	}
+
+	if err := dstFile.Close(); err != nil {
+		_ = fs.Remove(dstPath) //nolint:errcheck //the close error takes precedence
+
+		return 0, fmt.Errorf("failed to close destination file: %w", err)
+	}
+
+	info, statErr := fs.Stat(dstPath)
+	if statErr != nil {
+		return 0, fmt.Errorf("failed to retrieve the destination file's size: %w", statErr)
+	}
+
+	return info.Size(), nil
+}
+
+// updateCompressedTransfer changes the transfer's file to the given one,
+// updates its size, and renames its remote path using the given function.
+func updateCompressedTransfer(transCtx *model.TransferContext, newPath string, size int64,
+	rename func(remotePath string) string,
+) {
+	transCtx.Transfer.LocalPath = newPath
+	transCtx.Transfer.Filesize = size
+
+	if transCtx.Transfer.RemotePath != "" {
+		transCtx.Transfer.RemotePath = rename(transCtx.Transfer.RemotePath)
+	}
+}
@@ -0,0 +1,85 @@
+package tasks
+
+import (
+	"bytes"
+	"strings"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils/ordered"
+)
+
+const (
+	CompressGzip  = "gzip"
+	CompressZstd  = "zstd"
+	CompressBzip2 = "bzip2"
+	CompressXz    = "xz"
+)
+
+// compressionFormat describes a single-file compression format, as used by the
+// COMPRESS and DECOMPRESS tasks.
+type compressionFormat struct {
+	extension    string
+	magic        []byte
+	compressor   compressorFunc
+	decompressor decompressorFunc
+}
+
+//nolint:gochecknoglobals //global var is needed here for future-proofing
+var CompressionFormats = ordered.Map[string, *compressionFormat]{}
+
+//nolint:gochecknoinits //init is needed here to populate CompressionFormats
+func init() {
+	CompressionFormats.Add(CompressGzip, &compressionFormat{
+		extension:    ".gz",
+		magic:        []byte{0x1f, 0x8b},
+		compressor:   gzipCompressor,
+		decompressor: gzipDecompressor,
+	})
+	CompressionFormats.Add(CompressZstd, &compressionFormat{
+		extension:    ".zst",
+		magic:        []byte{0x28, 0xb5, 0x2f, 0xfd},
+		compressor:   zstdCompressor,
+		decompressor: zstdDecompressor,
+	})
+	CompressionFormats.Add(CompressBzip2, &compressionFormat{
+		extension:    ".bz2",
+		magic:        []byte("BZh"),
+		compressor:   bzip2Compressor,
+		decompressor: bzip2Decompressor,
+	})
+	CompressionFormats.Add(CompressXz, &compressionFormat{
+		extension:    ".xz",
+		magic:        []byte{0xfd, '7', 'z', 'X', 'Z', 0x00},
+		compressor:   xzCompressor,
+		decompressor: xzDecompressor,
+	})
+}
+
+// compressionMagicLen is the number of bytes needed to identify any of the
+// compression formats.
+const compressionMagicLen = 6
+
+// detectCompressionFormat returns the name of the compression format matching
+// the given file header, or an empty string if no format matches.
+func detectCompressionFormat(header []byte) string {
+	for name, format := range CompressionFormats.Iter() {
+		if bytes.HasPrefix(header, format.magic) {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// trimCompressionExtension removes the given compression extension from the
+// given path. It returns false if the path does not have the extension.
+func trimCompressionExtension(path, extension string) (string, bool) {
+	if !hasExtension(path, extension) || len(path) == len(extension) {
+		return path, false
+	}
+
+	return path[:len(path)-len(extension)], true
+}
+
+func normalizeCompressionFormat(format string) string {
+	return strings.ToLower(strings.TrimSpace(format))
+}
@@ -0,0 +1,174 @@
+package tasks
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/fs"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils/testhelpers"
+)
+
+func makeCompressTestContext(t *testing.T, content string) *model.TransferContext {
+	t.Helper()
+
+	filePath := filepath.Join(t.TempDir(), "file.txt")
+	require.NoError(t, fs.WriteFullFile(filePath, []byte(content)))
+
+	return &model.TransferContext{
+		Rule: &model.Rule{IsSend: true},
+		Transfer: &model.Transfer{
+			LocalPath:  filePath,
+			RemotePath: "/remote/file.txt",
+			Filesize:   int64(len(content)),
+		},
+	}
+}
+
+func TestCompressValidate(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		name    string
+		args    map[string]string
+		wantErr error
+	}{
+		{"Given no arguments", map[string]string{}, nil},
+		{"Given a valid format", map[string]string{"format": "ZSTD", "compressionLevel": "9"}, nil},
+		{"Given an unknown format", map[string]string{"format": "rar"}, ErrCompressUnknownFormat},
+		{"Given an invalid level", map[string]string{"compressionLevel": "10"}, ErrInvalidCompressionLevel},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := (&compressTask{}).Validate(test.args)
+			if test.wantErr == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestCompressDecompress(t *testing.T) {
+	t.Parallel()
+
+	logger := testhelpers.GetTestLogger(t)
+	content := strings.Repeat("Lorem ipsum dolor sit amet, consectetur adipiscing elit.\n", 100)
+
+	for name, format := range CompressionFormats.Iter() {
+		t.Run("Given the "+name+" format", func(t *testing.T) {
+			t.Parallel()
+
+			transCtx := makeCompressTestContext(t, content)
+			origPath := transCtx.Transfer.LocalPath
+
+			require.NoError(t, (&compressTask{}).Run(t.Context(),
+				map[string]string{"format": name}, nil, logger, transCtx, nil))
+
+			compPath := origPath + format.extension
+
+			t.Run("Then the file should have been compressed", func(t *testing.T) {
+				assert.Equal(t, compPath, transCtx.Transfer.LocalPath)
+				assert.Equal(t, "/remote/file.txt"+format.extension, transCtx.Transfer.RemotePath)
+				assert.NoFileExists(t, origPath, "Then the original file should have been deleted")
+
+				info, err := fs.Stat(compPath)
+				require.NoError(t, err)
+				assert.Equal(t, info.Size(), transCtx.Transfer.Filesize)
+				assert.Less(t, transCtx.Transfer.Filesize, int64(len(content)))
+			})
+
+			// The format is detected from the file's content.
+			require.NoError(t, (&decompressTask{}).Run(t.Context(),
+				map[string]string{}, nil, logger, transCtx, nil))
+
+			t.Run("Then the file should have been decompressed", func(t *testing.T) {
+				assert.Equal(t, origPath, transCtx.Transfer.LocalPath)
+				assert.Equal(t, "/remote/file.txt", transCtx.Transfer.RemotePath)
+				assert.Equal(t, int64(len(content)), transCtx.Transfer.Filesize)
+				assert.NoFileExists(t, compPath, "Then the compressed file should have been deleted")
+
+				res, err := fs.ReadFullFile(origPath)
+				require.NoError(t, err)
+				assert.Equal(t, content, string(res))
+			})
+		})
+	}
+}
+
+func TestCompressKeepOriginal(t *testing.T) {
+	t.Parallel()
+
+	logger := testhelpers.GetTestLogger(t)
+	transCtx := makeCompressTestContext(t, "hello world")
+	origPath := transCtx.Transfer.LocalPath
+
+	require.NoError(t, (&compressTask{}).Run(t.Context(), map[string]string{
+		"format": "xz", "keepOriginal": "true",
+	}, nil, logger, transCtx, nil))
+
+	assert.Equal(t, origPath+".xz", transCtx.Transfer.LocalPath)
+	assert.FileExists(t, origPath, "Then the original file should have been kept")
+}
+
+func TestDecompressRun(t *testing.T) {
+	t.Parallel()
+
+	logger := testhelpers.GetTestLogger(t)
+
+	t.Run("Given a file without extension", func(t *testing.T) {
+		t.Parallel()
+
+		transCtx := makeCompressTestContext(t, "hello world")
+		origPath := transCtx.Transfer.LocalPath
+
+		require.NoError(t, (&compressTask{}).Run(t.Context(), map[string]string{"format": "bzip2"},
+			nil, logger, transCtx, nil))
+
+		noExtPath := filepath.Join(filepath.Dir(origPath), "compressed")
+		require.NoError(t, fs.MoveFile(transCtx.Transfer.LocalPath, noExtPath))
+
+		transCtx.Transfer.LocalPath = noExtPath
+
+		require.NoError(t, (&decompressTask{}).Run(t.Context(), map[string]string{},
+			nil, logger, transCtx, nil))
+
+		assert.Equal(t, noExtPath, transCtx.Transfer.LocalPath,
+			"Then the file should have been decompressed in place")
+
+		res, err := fs.ReadFullFile(noExtPath)
+		require.NoError(t, err)
+		assert.Equal(t, "hello world", string(res))
+	})
+
+	t.Run("Given an uncompressed file", func(t *testing.T) {
+		t.Parallel()
+
+		transCtx := makeCompressTestContext(t, "hello world")
+
+		require.ErrorIs(t, (&decompressTask{}).Run(t.Context(), map[string]string{},
+			nil, logger, transCtx, nil), ErrDecompressUnknownFormat)
+	})
+
+	t.Run("Given a wrong format", func(t *testing.T) {
+		t.Parallel()
+
+		transCtx := makeCompressTestContext(t, "hello world")
+		origPath := transCtx.Transfer.LocalPath
+
+		require.NoError(t, (&compressTask{}).Run(t.Context(), map[string]string{"format": "gzip"},
+			nil, logger, transCtx, nil))
+
+		require.Error(t, (&decompressTask{}).Run(t.Context(), map[string]string{"format": "zstd"},
+			nil, logger, transCtx, nil))
+		assert.Equal(t, origPath+".gz", transCtx.Transfer.LocalPath,
+			"Then the transfer should not have been changed")
+		assert.NoFileExists(t, origPath+".gz.tmp", "Then the partial file should have been removed")
+	})
+}
@@ -0,0 +1,73 @@
+package tasks
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/condition"
+)
+
+// conditionEnv is the environment in which the tasks' conditions are evaluated.
+type conditionEnv struct {
+	transCtx *model.TransferContext
+	previous string
+}
+
+func (c *conditionEnv) Lookup(name string) (any, bool) {
+	trans := c.transCtx.Transfer
+
+	switch name {
+	case condition.VarFilename:
+		return path.Base(trans.LocalPath), true
+	case condition.VarFilepath:
+		return trans.LocalPath, true
+	case condition.VarFilesize:
+		return trans.Filesize, true
+	case condition.VarRule:
+		return c.transCtx.Rule.Name, true
+	case condition.VarDirection:
+		if c.transCtx.Rule.IsSend {
+			return "send", true
+		}
+
+		return "receive", true
+	case condition.VarPartner:
+		partner, _ := getRemote(c.transCtx, "")
+
+		return partner, true
+	case condition.VarAccount:
+		account, _ := getClient(c.transCtx, "")
+
+		return account, true
+	case condition.VarPrevious:
+		return c.previous, true
+	case condition.VarFinalAttempt:
+		return strconv.FormatBool(c.transCtx.FinalAttempt), true
+	default:
+		return nil, false
+	}
+}
+
+// Expand replaces the substitution variables contained in the given literal,
+// the same way they are replaced in the tasks' arguments.
+func (c *conditionEnv) Expand(literal string) (string, error) {
+	raw, err := json.Marshal(literal)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize the literal: %w", err)
+	}
+
+	replaced, err := replaceVars(string(raw), c.transCtx)
+	if err != nil {
+		return "", err
+	}
+
+	var res string
+	if err := json.Unmarshal([]byte(replaced), &res); err != nil {
+		return "", fmt.Errorf("failed to deserialize the literal: %w", err)
+	}
+
+	return res, nil
+}
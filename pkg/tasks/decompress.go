@@ -0,0 +1,159 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/fs"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils"
+)
+
+var ErrDecompressUnknownFormat = errors.New("the file's compression format could not be detected")
+
+// decompressTask is a task which decompresses the transfer file (compressed
+// with COMPRESS, or any other tool using the same formats). If no format is
+// given, it is detected from the file's content. The decompressed file
+// replaces the compressed one as the transfer's file.
+type decompressTask struct {
+	Format       string   `json:"format"`
+	KeepOriginal jsonBool `json:"keepOriginal"`
+
+	format *compressionFormat
+}
+
+func (d *decompressTask) parseParams(params map[string]string) error {
+	*d = decompressTask{}
+	if err := utils.JSONConvert(params, d); err != nil {
+		return fmt.Errorf("failed to parse the decompress task parameters: %w", err)
+	}
+
+	if d.Format == "" {
+		return nil
+	}
+
+	var ok bool
+	if d.format, ok = CompressionFormats.Get(normalizeCompressionFormat(d.Format)); !ok {
+		return fmt.Errorf("%w %q", ErrCompressUnknownFormat, d.Format)
+	}
+
+	return nil
+}
+
+func (d *decompressTask) Validate(params map[string]string) error {
+	return d.parseParams(params)
+}
+
+func (d *decompressTask) Run(_ context.Context, params map[string]string, _ *database.DB,
+	logger *log.Logger, transCtx *model.TransferContext, _ any,
+) error {
+	if err := d.parseParams(params); err != nil {
+		logger.Error(err.Error())
+
+		return err
+	}
+
+	srcPath := transCtx.Transfer.LocalPath
+
+	if d.format == nil {
+		if err := d.detectFormat(srcPath); err != nil {
+			logger.Error(err.Error())
+
+			return err
+		}
+	}
+
+	dstPath, hasExt := trimCompressionExtension(srcPath, d.format.extension)
+	if !hasExt {
+		// The file has no recognizable extension, so the decompressed file
+		// replaces the compressed one.
+		dstPath = srcPath + ".tmp"
+	}
+
+	size, err := d.decompress(srcPath, dstPath)
+	if err != nil {
+		logger.Errorf("Failed to decompress file: %v", err)
+
+		return err
+	}
+
+	if !hasExt {
+		if err := fs.MoveFile(dstPath, srcPath); err != nil {
+			logger.Errorf("Failed to replace the compressed file: %v", err)
+
+			return fmt.Errorf("failed to replace the compressed file: %w", err)
+		}
+
+		dstPath = srcPath
+	} else if !d.KeepOriginal {
+		if err := fs.Remove(srcPath); err != nil {
+			logger.Errorf("Failed to delete the compressed file: %v", err)
+
+			return fmt.Errorf("failed to delete the compressed file: %w", err)
+		}
+	}
+
+	updateCompressedTransfer(transCtx, dstPath, size, func(remotePath string) string {
+		newPath, _ := trimCompressionExtension(remotePath, d.format.extension)
+
+		return newPath
+	})
+
+	logger.Debugf("Decompressed file %q to %q", srcPath, dstPath)
+
+	return nil
+}
+
+func (d *decompressTask) detectFormat(srcPath string) error {
+	srcFile, opErr := fs.Open(srcPath)
+	if opErr != nil {
+		return fmt.Errorf("failed to open source file: %w", opErr)
+	}
+	defer srcFile.Close() //nolint:errcheck //close never returns an error on read-only files
+
+	header := make([]byte, compressionMagicLen)
+
+	n, err := io.ReadFull(srcFile, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to read the file header: %w", err)
+	}
+
+	name := detectCompressionFormat(header[:n])
+	if name == "" {
+		return ErrDecompressUnknownFormat
+	}
+
+	d.format, _ = CompressionFormats.Get(name)
+
+	return nil
+}
+
+func (d *decompressTask) decompress(srcPath, dstPath string) (int64, error) {
+	srcFile, opErr := fs.Open(srcPath)
+	if opErr != nil {
+		return 0, fmt.Errorf("failed to open source file: %w", opErr)
+	}
+	defer srcFile.Close() //nolint:errcheck //close never returns an error on read-only files
+
+	decompressor, decErr := d.format.decompressor(srcFile)
+	if decErr != nil {
+		return 0, fmt.Errorf("failed to initialize the decompressor: %w", decErr)
+	}
+
+	// Some decompressors (like zstd's) hold resources which must be released.
+	if closer, ok := decompressor.(interface{ Close() }); ok {
+		defer closer.Close()
+	}
+
+	return writeCompressionOutput(dstPath, func(dstFile fs.File) error {
+		if _, err := io.Copy(dstFile, decompressor); err != nil {
+			return fmt.Errorf("failed to decompress the file: %w", err)
+		}
+
+		return nil
+	})
+}
@@ -81,6 +81,10 @@ func (d *decrypt) ValidateDB(db database.ReadAccess, params map[string]string) e
 		return fmt.Errorf("failed to retrieve decryption key from database: %w", err)
 	}
 
+	if err := cryptoKey.ResolveSecrets(); err != nil {
+		return fmt.Errorf("failed to resolve decryption key: %w", err)
+	}
+
 	method, ok := DecryptMethods.Get(d.Method)
 	if !ok {
 		return fmt.Errorf("%w %q", ErrDecryptUnknownMethod, d.Method)
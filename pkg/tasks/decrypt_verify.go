@@ -78,6 +78,10 @@ func (d *decryptVerify) ValidateDB(db database.ReadAccess, params map[string]str
 		return fmt.Errorf("failed to retrieve decryption key from database: %w", err)
 	}
 
+	if err := dCryptoKey.ResolveSecrets(); err != nil {
+		return fmt.Errorf("failed to resolve decryption key: %w", err)
+	}
+
 	var vCryptoKey model.CryptoKey
 	if err := db.Get(&vCryptoKey, "name=?", d.VerifyKeyName).Owner().Run(); database.IsNotFound(err) {
 		return fmt.Errorf("%w %q", ErrDecryptVerifyKeyNotFound, d.VerifyKeyName)
@@ -85,6 +89,10 @@ func (d *decryptVerify) ValidateDB(db database.ReadAccess, params map[string]str
 		return fmt.Errorf("failed to retrieve verification key from database: %w", err)
 	}
 
+	if err := vCryptoKey.ResolveSecrets(); err != nil {
+		return fmt.Errorf("failed to resolve verification key: %w", err)
+	}
+
 	method, ok := DecryptVerifyMethods.Get(d.Method)
 	if !ok {
 		return fmt.Errorf("%w %q", ErrEncryptSignUnknownMethod, d.Method)
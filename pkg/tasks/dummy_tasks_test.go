@@ -80,12 +80,15 @@ func (t *testTaskLong) Validate(map[string]string) error {
 	return nil
 }
 
-func (t *testTaskLong) Run(context.Context, map[string]string, *database.DB,
-	*log.Logger, *model.TransferContext, any,
+func (t *testTaskLong) Run(ctx context.Context, _ map[string]string, _ *database.DB,
+	_ *log.Logger, _ *model.TransferContext, _ any,
 ) error {
 	dummyTaskCheck <- "LONG"
 
-	time.Sleep(time.Minute)
-
-	return errTaskFailed
+	select {
+	case <-ctx.Done():
+		return ctx.Err() //nolint:wrapcheck //no need to wrap here
+	case <-time.After(time.Minute):
+		return errTaskFailed
+	}
 }
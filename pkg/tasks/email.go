@@ -8,6 +8,7 @@ import (
 	"io"
 	"net/mail"
 	"path"
+	"strings"
 
 	"gopkg.in/gomail.v2"
 
@@ -33,6 +34,10 @@ type emailTask struct {
 
 	connInfo model.SMTPCredential
 	template model.EmailTemplate
+
+	// defaultTemplate is the template used when no template name is given.
+	// If nil, the template name is mandatory.
+	defaultTemplate *model.EmailTemplate
 }
 
 func (m *emailTask) parseParams(db database.ReadAccess, params map[string]string) error {
@@ -54,7 +59,7 @@ func (m *emailTask) parseParams(db database.ReadAccess, params map[string]string
 		}
 	}
 
-	if m.Template == "" {
+	if m.Template == "" && m.defaultTemplate == nil {
 		return ErrMailNoTemplate
 	}
 
@@ -62,6 +67,12 @@ func (m *emailTask) parseParams(db database.ReadAccess, params map[string]string
 		return fmt.Errorf("failed to retrieve SMTP credential: %w", err)
 	}
 
+	if m.Template == "" {
+		m.template = *m.defaultTemplate
+
+		return nil
+	}
+
 	if err := db.Get(&m.template, "name=?", m.Template).Run(); err != nil {
 		return fmt.Errorf("failed to retrieve email template: %w", err)
 	}
@@ -88,11 +99,43 @@ func (m *emailTask) Run(_ context.Context, params map[string]string, db *databas
 		return fmt.Errorf("failed to replace email template variables: %w", err)
 	}
 
+	return m.send(db, logger)
+}
+
+// SendEmail sends an email outside of any transfer (for alerts & such), using
+// the same parameters (sender, recipients & template) as the EMAIL task. If
+// the parameters do not name a template, the given default template is used
+// instead (if it is not nil). Since there is no transfer, the template's
+// variables are substituted using the given replacer.
+func SendEmail(db *database.DB, logger *log.Logger, params map[string]string,
+	defaultTemplate *model.EmailTemplate, replacer *strings.Replacer,
+) error {
+	m := emailTask{defaultTemplate: defaultTemplate}
+	if err := m.parseParams(db, params); err != nil {
+		return err
+	}
+
+	m.template.Subject = replacer.Replace(m.template.Subject)
+	m.template.Body = replacer.Replace(m.template.Body)
+
+	for i, attachment := range m.template.Attachments {
+		m.template.Attachments[i] = replacer.Replace(attachment)
+	}
+
+	return m.send(db, logger)
+}
+
+func (m *emailTask) send(db *database.DB, logger *log.Logger) error {
 	email := gomail.NewMessage()
 	email.SetHeader("From", m.connInfo.EmailAddress)
 	email.SetHeader("To", m.Recipients...)
 	email.SetHeader("Subject", m.template.Subject)
-	email.SetBody("text/plain", m.template.Body)
+	mimeType := m.template.MIMEType
+	if mimeType == "" {
+		mimeType = model.DefaultEmailMimeType
+	}
+
+	email.SetBody(mimeType, m.template.Body)
 
 	for _, attachement := range m.template.Attachments {
 		email.Attach(path.Base(attachement), m.copyFileFunc(attachement))
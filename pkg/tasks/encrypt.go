@@ -81,6 +81,10 @@ func (e *encrypt) ValidateDB(db database.ReadAccess, params map[string]string) e
 		return fmt.Errorf("failed to retrieve encryption key from database: %w", err)
 	}
 
+	if err := cryptoKey.ResolveSecrets(); err != nil {
+		return fmt.Errorf("failed to resolve encryption key: %w", err)
+	}
+
 	method, ok := EncryptMethods.Get(e.Method)
 	if !ok {
 		return fmt.Errorf("%w %q", ErrEncryptUnknownMethod, e.Method)
@@ -78,6 +78,10 @@ func (e *encryptSign) ValidateDB(db database.ReadAccess, params map[string]strin
 		return fmt.Errorf("failed to retrieve encryption key from database: %w", err)
 	}
 
+	if err := eCryptoKey.ResolveSecrets(); err != nil {
+		return fmt.Errorf("failed to resolve encryption key: %w", err)
+	}
+
 	var sCryptoKey model.CryptoKey
 	if err := db.Get(&sCryptoKey, "name=?", e.SignKeyName).Owner().Run(); database.IsNotFound(err) {
 		return fmt.Errorf("%w %q", ErrEncryptSignKeyNotFound, e.SignKeyName)
@@ -85,6 +89,10 @@ func (e *encryptSign) ValidateDB(db database.ReadAccess, params map[string]strin
 		return fmt.Errorf("failed to retrieve signature key from database: %w", err)
 	}
 
+	if err := sCryptoKey.ResolveSecrets(); err != nil {
+		return fmt.Errorf("failed to resolve signature key: %w", err)
+	}
+
 	method, ok := EncryptSignMethods.Get(e.Method)
 	if !ok {
 		return fmt.Errorf("%w %q", ErrEncryptSignUnknownMethod, e.Method)
@@ -10,6 +10,7 @@ import (
 var (
 	ErrTransferInterrupted = newError(types.TeStopped, "transfer interrupted")
 	ErrBadTaskArguments    = errors.New("bad arguments for tasks")
+	ErrTaskTimeout         = errors.New("task timed out")
 )
 
 type Error struct {
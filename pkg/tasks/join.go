@@ -0,0 +1,329 @@
+package tasks
+
+import (
+	"cmp"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/fs"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/types"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils"
+)
+
+const joinDefaultInterval = 5 * time.Second
+
+// joinPartNumberRegex matches the last number in a part's file name, which is
+// used to order the parts identified by a naming pattern.
+var joinPartNumberRegex = regexp.MustCompile(`(\d+)\D*$`)
+
+var (
+	ErrJoinNoSource      = errors.New(`one of "manifest" or "pattern" must be given`)
+	ErrJoinManySources   = errors.New(`"manifest" and "pattern" cannot be given together`)
+	ErrJoinNoCount       = errors.New(`the number of parts must be given with "pattern"`)
+	ErrJoinNoOutput      = errors.New("no output path specified")
+	ErrJoinEmptyManifest = errors.New("the manifest does not list any part")
+	ErrJoinMissingParts  = errors.New("some parts are missing")
+	ErrJoinTooManyParts  = errors.New("more parts than expected were found")
+	ErrJoinNoPartNumber  = errors.New("the part's name does not contain a part number")
+	ErrJoinDuplicatePart = errors.New("several parts have the same part number")
+	ErrJoinPartsGap      = errors.New("the part numbers are not contiguous")
+)
+
+// joinTask is a task which reassembles a file split into several parts. The
+// parts are either listed in a manifest (with their checksums), or identified
+// by a naming pattern. The task waits (up to a timeout) for all the parts to
+// be present, then concatenates them, in order, into the output file.
+type joinTask struct {
+	Manifest    string       `json:"manifest"`
+	Pattern     string       `json:"pattern"`
+	Count       jsonInt      `json:"count"`
+	Output      string       `json:"output"`
+	Algorithm   string       `json:"algorithm"`
+	Timeout     jsonDuration `json:"timeout"`
+	Interval    jsonDuration `json:"interval"`
+	DeleteParts jsonBool     `json:"deleteParts"`
+
+	algo checksumAlgo
+}
+
+func (j *joinTask) Validate(args map[string]string) error {
+	*j = joinTask{}
+
+	if err := utils.JSONConvert(args, j); err != nil {
+		return fmt.Errorf("failed to parse the join arguments: %w", err)
+	}
+
+	switch {
+	case j.Manifest == "" && j.Pattern == "":
+		return ErrJoinNoSource
+	case j.Manifest != "" && j.Pattern != "":
+		return ErrJoinManySources
+	case j.Pattern != "" && j.Count <= 0:
+		return ErrJoinNoCount
+	}
+
+	if j.Output == "" {
+		return ErrJoinNoOutput
+	}
+
+	if j.Interval.IsZero() {
+		j.Interval.Duration = joinDefaultInterval
+	}
+
+	var err error
+	j.algo, err = getChecksumAlgo(j.Algorithm)
+
+	return err
+}
+
+func (j *joinTask) Run(ctx context.Context, args map[string]string, _ *database.DB,
+	logger *log.Logger, transCtx *model.TransferContext, _ any,
+) error {
+	if err := j.Validate(args); err != nil {
+		logger.Error(err.Error())
+
+		return err
+	}
+
+	parts, err := j.waitParts(ctx, logger)
+	if err != nil {
+		logger.Errorf("Failed to retrieve the parts: %v", err)
+
+		return err
+	}
+
+	if err := j.join(ctx, parts); err != nil {
+		logger.Errorf("Failed to join the parts: %v", err)
+
+		if rmErr := fs.Remove(j.Output); rmErr != nil && !errors.Is(rmErr, fs.ErrNotExist) {
+			logger.Warningf("Failed to delete the incomplete output file: %v", rmErr)
+		}
+
+		return err
+	}
+
+	logger.Debugf("Joined %d parts into %q", len(parts), j.Output)
+
+	if j.DeleteParts {
+		j.deleteParts(logger, parts)
+	}
+
+	transCtx.Transfer.LocalPath = j.Output
+
+	return nil
+}
+
+// waitParts waits until all the parts are present, and returns them (with the
+// expected checksum, if known) in order.
+func (j *joinTask) waitParts(ctx context.Context, logger *log.Logger) ([]manifestEntry, error) {
+	var deadline time.Time
+	if !j.Timeout.IsZero() {
+		deadline = time.Now().Add(j.Timeout.Duration)
+	}
+
+	for {
+		parts, missing, err := j.listParts()
+		if err != nil {
+			return nil, err
+		}
+
+		if missing == 0 {
+			return parts, nil
+		}
+
+		if deadline.IsZero() || time.Now().Add(j.Interval.Duration).After(deadline) {
+			return nil, fmt.Errorf("%w: %d part(s) not found", ErrJoinMissingParts, missing)
+		}
+
+		logger.Debugf("Waiting for %d missing part(s)", missing)
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("join interrupted: %w", ctx.Err())
+		case <-time.After(j.Interval.Duration):
+		}
+	}
+}
+
+// listParts returns the parts, along with the number of parts which are still
+// missing.
+func (j *joinTask) listParts() ([]manifestEntry, int, error) {
+	if j.Pattern != "" {
+		return j.listPatternParts()
+	}
+
+	content, err := fs.ReadFullFile(j.Manifest)
+	if errors.Is(err, fs.ErrNotExist) {
+		// The manifest itself has not arrived yet.
+		return nil, 1, nil
+	} else if err != nil {
+		return nil, 0, fmt.Errorf("failed to read the manifest: %w", err)
+	}
+
+	parts := parseManifest(content)
+	if len(parts) == 0 {
+		return nil, 0, fmt.Errorf("%w %q", ErrJoinEmptyManifest, j.Manifest)
+	}
+
+	missing := 0
+
+	for i := range parts {
+		if !strings.Contains(parts[i].name, "/") {
+			parts[i].name = path.Join(path.Dir(j.Manifest), parts[i].name)
+		}
+
+		if _, err := fs.Stat(parts[i].name); errors.Is(err, fs.ErrNotExist) {
+			missing++
+		} else if err != nil {
+			return nil, 0, fmt.Errorf("failed to retrieve part %q: %w", parts[i].name, err)
+		}
+	}
+
+	return parts, missing, nil
+}
+
+// listPatternParts returns the parts matching the naming pattern, ordered by
+// their part number (the last number in their name), along with the number of
+// parts which are still missing. The output file is ignored if it matches the
+// pattern. Since the parts must be numbered contiguously, duplicate part
+// numbers, or parts too far apart to fit in the expected count, are errors.
+func (j *joinTask) listPatternParts() ([]manifestEntry, int, error) {
+	files, err := fs.Glob(j.Pattern)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid parts pattern %q: %w", j.Pattern, err)
+	}
+
+	type numberedPart struct {
+		name   string
+		number int
+	}
+
+	numbered := make([]numberedPart, 0, len(files))
+
+	for _, file := range files {
+		if path.Clean(file) == path.Clean(j.Output) {
+			continue
+		}
+
+		match := joinPartNumberRegex.FindStringSubmatch(path.Base(file))
+		if match == nil {
+			return nil, 0, fmt.Errorf("%w: %q", ErrJoinNoPartNumber, file)
+		}
+
+		number, convErr := strconv.Atoi(match[1])
+		if convErr != nil {
+			return nil, 0, fmt.Errorf("%w: %q", ErrJoinNoPartNumber, file)
+		}
+
+		numbered = append(numbered, numberedPart{name: file, number: number})
+	}
+
+	if len(numbered) > int(j.Count) {
+		return nil, 0, fmt.Errorf("%w: expected %d, found %d", ErrJoinTooManyParts,
+			j.Count, len(numbered))
+	}
+
+	slices.SortFunc(numbered, func(a, b numberedPart) int {
+		return cmp.Or(cmp.Compare(a.number, b.number), strings.Compare(a.name, b.name))
+	})
+
+	parts := make([]manifestEntry, len(numbered))
+	for i, part := range numbered {
+		if i > 0 && part.number == numbered[i-1].number {
+			return nil, 0, fmt.Errorf("%w: %q and %q", ErrJoinDuplicatePart,
+				numbered[i-1].name, part.name)
+		}
+
+		parts[i] = manifestEntry{name: part.name}
+	}
+
+	// The parts still missing can only fill the gaps between the parts found,
+	// so the parts found must not span more numbers than the expected count.
+	if len(numbered) > 0 {
+		first, last := numbered[0], numbered[len(numbered)-1]
+		if last.number-first.number >= int(j.Count) {
+			return nil, 0, fmt.Errorf("%w: %d parts expected, but found parts %d to %d",
+				ErrJoinPartsGap, j.Count, first.number, last.number)
+		}
+	}
+
+	return parts, int(j.Count) - len(parts), nil
+}
+
+// join concatenates the given parts into the output file, checking the
+// checksum of each part along the way.
+func (j *joinTask) join(ctx context.Context, parts []manifestEntry) error {
+	out, err := fs.Create(j.Output)
+	if err != nil {
+		return fmt.Errorf("failed to create the output file: %w", err)
+	}
+
+	defer out.Close() //nolint:errcheck //error is checked below
+
+	for _, part := range parts {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("join interrupted: %w", err)
+		}
+
+		if err := j.appendPart(out, part); err != nil {
+			return err
+		}
+	}
+
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to close the output file: %w", err)
+	}
+
+	return nil
+}
+
+func (j *joinTask) appendPart(out io.Writer, part manifestEntry) error {
+	file, err := fs.Open(part.name)
+	if err != nil {
+		return fmt.Errorf("failed to open part %q: %w", part.name, err)
+	}
+
+	defer file.Close() //nolint:errcheck //error is irrelevant here
+
+	hasher := j.algo.new()
+
+	if _, err := io.Copy(io.MultiWriter(out, hasher), file); err != nil {
+		return fmt.Errorf("failed to copy part %q: %w", part.name, err)
+	}
+
+	if sum := hex.EncodeToString(hasher.Sum(nil)); part.sum != "" && !strings.EqualFold(part.sum, sum) {
+		return newError(types.TeIntegrity, "checksum mismatch for part %q: expected %s, got %s",
+			part.name, part.sum, sum)
+	}
+
+	return nil
+}
+
+func (j *joinTask) deleteParts(logger *log.Logger, parts []manifestEntry) {
+	files := make([]string, 0, len(parts)+1)
+	for _, part := range parts {
+		files = append(files, part.name)
+	}
+
+	if j.Manifest != "" {
+		files = append(files, j.Manifest)
+	}
+
+	for _, file := range files {
+		if err := fs.Remove(file); err != nil {
+			logger.Warningf("Failed to delete %q: %v", file, err)
+		}
+	}
+}
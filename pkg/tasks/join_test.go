@@ -0,0 +1,234 @@
+package tasks
+
+import (
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/fs"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/types"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils/testhelpers"
+)
+
+func TestJoinRun(t *testing.T) {
+	t.Parallel()
+
+	logger := testhelpers.GetTestLogger(t)
+
+	writeParts := func(t *testing.T, dir string, parts ...string) {
+		t.Helper()
+
+		for i, part := range parts {
+			require.NoError(t, fs.WriteFullFile(
+				filepath.Join(dir, "part_"+string(rune('1'+i))), []byte(part)))
+		}
+	}
+
+	t.Run("Given a manifest", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		writeParts(t, dir, "hello ", "world")
+
+		manifest := filepath.Join(dir, "parts.sha256")
+		require.NoError(t, fs.WriteFullFile(manifest, []byte(
+			formatSidecar(sha256Hex("hello "), "part_1")+
+				"SHA256 (part_2) = "+sha256Hex("world")+"\n")))
+
+		output := filepath.Join(dir, "joined.txt")
+		transCtx := &model.TransferContext{Transfer: &model.Transfer{LocalPath: manifest}}
+		args := map[string]string{"manifest": manifest, "output": output, "deleteParts": "true"}
+
+		require.NoError(t, (&joinTask{}).Run(t.Context(), args, nil, logger, transCtx, nil))
+
+		assert.Equal(t, "hello world", readTestFile(t, output))
+		assert.Equal(t, output, transCtx.Transfer.LocalPath,
+			"Then the transfer file should be the joined file")
+		assert.NoFileExists(t, filepath.Join(dir, "part_1"))
+		assert.NoFileExists(t, filepath.Join(dir, "part_2"))
+		assert.NoFileExists(t, manifest)
+	})
+
+	t.Run("Given a corrupted part", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		writeParts(t, dir, "hello ", "world")
+
+		manifest := filepath.Join(dir, "parts.sha256")
+		require.NoError(t, fs.WriteFullFile(manifest, []byte(
+			formatSidecar(sha256Hex("hello "), "part_1")+
+				formatSidecar(sha256Hex("World"), "part_2"))))
+
+		output := filepath.Join(dir, "joined.txt")
+		transCtx := &model.TransferContext{Transfer: &model.Transfer{LocalPath: manifest}}
+		args := map[string]string{"manifest": manifest, "output": output}
+
+		err := (&joinTask{}).Run(t.Context(), args, nil, logger, transCtx, nil)
+
+		var taskErr *Error
+		require.ErrorAs(t, err, &taskErr)
+		assert.Equal(t, types.TeIntegrity, taskErr.Code)
+		assert.NoFileExists(t, output, "Then the incomplete output file should have been deleted")
+	})
+
+	t.Run("Given a naming pattern", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		writeParts(t, dir, "foo", "bar", "baz")
+
+		output := filepath.Join(dir, "joined.txt")
+		transCtx := &model.TransferContext{Transfer: &model.Transfer{}}
+		args := map[string]string{
+			"pattern": filepath.Join(dir, "part_*"),
+			"count":   "3",
+			"output":  output,
+		}
+
+		require.NoError(t, (&joinTask{}).Run(t.Context(), args, nil, logger, transCtx, nil))
+		assert.Equal(t, "foobarbaz", readTestFile(t, output))
+		assert.FileExists(t, filepath.Join(dir, "part_1"), "Then the parts should have been kept")
+	})
+
+	t.Run("Given parts numbered past 9", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+
+		for i := 1; i <= 10; i++ {
+			require.NoError(t, fs.WriteFullFile(
+				filepath.Join(dir, "part_"+strconv.Itoa(i)), []byte(strconv.Itoa(i)+",")))
+		}
+
+		output := filepath.Join(dir, "joined.txt")
+		transCtx := &model.TransferContext{Transfer: &model.Transfer{}}
+		args := map[string]string{
+			"pattern": filepath.Join(dir, "part_*"),
+			"count":   "10",
+			"output":  output,
+		}
+
+		require.NoError(t, (&joinTask{}).Run(t.Context(), args, nil, logger, transCtx, nil))
+		assert.Equal(t, "1,2,3,4,5,6,7,8,9,10,", readTestFile(t, output),
+			"Then the parts should have been joined in numeric order")
+	})
+
+	t.Run("Given an output file matching the pattern", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		writeParts(t, dir, "foo", "bar")
+
+		output := filepath.Join(dir, "part_0")
+		require.NoError(t, fs.WriteFullFile(output, []byte("old")))
+
+		transCtx := &model.TransferContext{Transfer: &model.Transfer{}}
+		args := map[string]string{
+			"pattern": filepath.Join(dir, "part_*"),
+			"count":   "2",
+			"output":  output,
+		}
+
+		require.NoError(t, (&joinTask{}).Run(t.Context(), args, nil, logger, transCtx, nil))
+		assert.Equal(t, "foobar", readTestFile(t, output),
+			"Then the output file should not have been counted as a part")
+	})
+
+	t.Run("Given too many parts", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		writeParts(t, dir, "foo", "bar", "baz")
+
+		transCtx := &model.TransferContext{Transfer: &model.Transfer{}}
+		args := map[string]string{
+			"pattern": filepath.Join(dir, "part_*"),
+			"count":   "2",
+			"output":  filepath.Join(dir, "joined.txt"),
+		}
+
+		require.ErrorIs(t, (&joinTask{}).Run(t.Context(), args, nil, logger, transCtx, nil),
+			ErrJoinTooManyParts)
+		assert.NoFileExists(t, filepath.Join(dir, "joined.txt"))
+	})
+
+	t.Run("Given a part without a number", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		writeParts(t, dir, "foo")
+		require.NoError(t, fs.WriteFullFile(filepath.Join(dir, "part_x"), []byte("bar")))
+
+		transCtx := &model.TransferContext{Transfer: &model.Transfer{}}
+		args := map[string]string{
+			"pattern": filepath.Join(dir, "part_*"),
+			"count":   "2",
+			"output":  filepath.Join(dir, "joined.txt"),
+		}
+
+		require.ErrorIs(t, (&joinTask{}).Run(t.Context(), args, nil, logger, transCtx, nil),
+			ErrJoinNoPartNumber)
+	})
+
+	t.Run("Given missing parts", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		writeParts(t, dir, "foo", "bar")
+
+		transCtx := &model.TransferContext{Transfer: &model.Transfer{}}
+		args := map[string]string{
+			"pattern":  filepath.Join(dir, "part_*"),
+			"count":    "3",
+			"output":   filepath.Join(dir, "joined.txt"),
+			"timeout":  "100ms",
+			"interval": "10ms",
+		}
+
+		require.ErrorIs(t, (&joinTask{}).Run(t.Context(), args, nil, logger, transCtx, nil),
+			ErrJoinMissingParts)
+	})
+
+	t.Run("Given duplicate part numbers", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		writeParts(t, dir, "foo")
+		require.NoError(t, fs.WriteFullFile(filepath.Join(dir, "part_01"), []byte("bar")))
+
+		transCtx := &model.TransferContext{Transfer: &model.Transfer{}}
+		args := map[string]string{
+			"pattern": filepath.Join(dir, "part_*"),
+			"count":   "2",
+			"output":  filepath.Join(dir, "joined.txt"),
+		}
+
+		require.ErrorIs(t, (&joinTask{}).Run(t.Context(), args, nil, logger, transCtx, nil),
+			ErrJoinDuplicatePart)
+		assert.NoFileExists(t, filepath.Join(dir, "joined.txt"))
+	})
+
+	t.Run("Given a gap in the part numbers", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		writeParts(t, dir, "foo", "bar")
+		require.NoError(t, fs.WriteFullFile(filepath.Join(dir, "part_4"), []byte("baz")))
+
+		transCtx := &model.TransferContext{Transfer: &model.Transfer{}}
+		args := map[string]string{
+			"pattern": filepath.Join(dir, "part_*"),
+			"count":   "3",
+			"output":  filepath.Join(dir, "joined.txt"),
+		}
+
+		require.ErrorIs(t, (&joinTask{}).Run(t.Context(), args, nil, logger, transCtx, nil),
+			ErrJoinPartsGap)
+		assert.NoFileExists(t, filepath.Join(dir, "joined.txt"))
+	})
+}
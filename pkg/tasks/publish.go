@@ -0,0 +1,186 @@
+package tasks
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/brokers"
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/authentication/auth"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils"
+)
+
+const (
+	publishDefaultContentType = "application/json"
+	publishDefaultHash        = ChecksumSHA256
+	publishNoHash             = "none"
+)
+
+var (
+	ErrPublishNoBroker       = errors.New("missing message broker name")
+	ErrPublishNoTopic        = errors.New("missing message topic")
+	ErrPublishBrokerNotFound = errors.New("message broker not found")
+)
+
+// publishTask is a task which publishes a message about the transfer on a
+// message broker. If no message is given, a JSON event describing the transfer
+// is sent instead.
+type publishTask struct {
+	Broker        string `json:"broker"`
+	Topic         string `json:"topic"`
+	Key           string `json:"key"`
+	Message       string `json:"message"`
+	ContentType   string `json:"contentType"`
+	HashAlgorithm string `json:"hashAlgorithm"`
+
+	broker model.MessageBroker
+	hash   checksumAlgo
+}
+
+// publishEvent is the default message sent by the PUBLISH task.
+type publishEvent struct {
+	TransferID       int64          `json:"transferId"`
+	RemoteTransferID string         `json:"remoteTransferId"`
+	Rule             string         `json:"rule"`
+	Direction        string         `json:"direction"`
+	File             string         `json:"file"`
+	Path             string         `json:"path"`
+	Size             int64          `json:"size"`
+	Hash             string         `json:"hash,omitempty"`
+	HashAlgorithm    string         `json:"hashAlgorithm,omitempty"`
+	TransferInfo     map[string]any `json:"transferInfo,omitempty"`
+}
+
+func (p *publishTask) ValidateDB(db database.ReadAccess, args map[string]string) error {
+	*p = publishTask{}
+
+	if err := utils.JSONConvert(args, p); err != nil {
+		return fmt.Errorf("failed to parse the publish arguments: %w", err)
+	}
+
+	if p.Broker == "" {
+		return ErrPublishNoBroker
+	}
+
+	if p.Topic == "" {
+		return ErrPublishNoTopic
+	}
+
+	if p.ContentType == "" {
+		p.ContentType = publishDefaultContentType
+	}
+
+	if p.HashAlgorithm == "" {
+		p.HashAlgorithm = publishDefaultHash
+	}
+
+	if p.HashAlgorithm != publishNoHash {
+		var err error
+		if p.hash, err = getChecksumAlgo(p.HashAlgorithm); err != nil {
+			return err
+		}
+	}
+
+	if err := db.Get(&p.broker, "name=?", p.Broker).Owner().Run(); database.IsNotFound(err) {
+		return fmt.Errorf("%w: %q", ErrPublishBrokerNotFound, p.Broker)
+	} else if err != nil {
+		return fmt.Errorf("failed to retrieve message broker from database: %w", err)
+	}
+
+	return nil
+}
+
+func (p *publishTask) Run(ctx context.Context, args map[string]string, db *database.DB,
+	logger *log.Logger, transCtx *model.TransferContext, _ any,
+) error {
+	if err := p.ValidateDB(db, args); err != nil {
+		logger.Error(err.Error())
+
+		return err
+	}
+
+	conf, confErr := p.broker.Config()
+	if confErr != nil {
+		logger.Errorf("Failed to load the message broker configuration: %v", confErr)
+
+		return fmt.Errorf("failed to load the message broker configuration: %w", confErr)
+	}
+
+	conf.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	if err := auth.AddTLSAuthorities(db, conf.TLSConfig); err != nil {
+		logger.Warningf("Failed to add TLS authorities: %v", err)
+	}
+
+	payload := []byte(p.Message)
+
+	if p.Message == "" {
+		var err error
+		if payload, err = p.makeEvent(ctx, transCtx); err != nil {
+			logger.Error(err.Error())
+
+			return err
+		}
+	}
+
+	msg := &brokers.Message{
+		Topic:       p.Topic,
+		Key:         p.Key,
+		ContentType: p.ContentType,
+		Payload:     payload,
+	}
+
+	if err := brokers.Publish(ctx, p.broker.Type, conf, msg); err != nil {
+		logger.Errorf("Failed to publish the message on broker %q: %v", p.Broker, err)
+
+		return fmt.Errorf("failed to publish the message on broker %q: %w", p.Broker, err)
+	}
+
+	logger.Debugf("Message published on broker %q (topic %q)", p.Broker, p.Topic)
+
+	return nil
+}
+
+func (p *publishTask) makeEvent(ctx context.Context, transCtx *model.TransferContext,
+) ([]byte, error) {
+	trans := transCtx.Transfer
+	event := &publishEvent{
+		TransferID:       trans.ID,
+		RemoteTransferID: trans.RemoteTransferID,
+		Direction:        "receive",
+		File:             filepath.Base(trans.LocalPath),
+		Path:             trans.LocalPath,
+		Size:             trans.Filesize,
+		TransferInfo:     trans.TransferInfo,
+	}
+
+	if transCtx.Rule != nil {
+		event.Rule = transCtx.Rule.Name
+
+		if transCtx.Rule.IsSend {
+			event.Direction = "send"
+		}
+	}
+
+	if p.HashAlgorithm != publishNoHash {
+		sum, err := hashFile(ctx, trans.LocalPath, p.hash)
+		if err != nil {
+			return nil, err
+		}
+
+		event.Hash = sum
+		event.HashAlgorithm = p.HashAlgorithm
+	}
+
+	content, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize the transfer event: %w", err)
+	}
+
+	return content, nil
+}
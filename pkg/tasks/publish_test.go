@@ -0,0 +1,132 @@
+package tasks
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/brokers/brokerstest"
+	"code.waarp.fr/apps/gateway/gateway/pkg/database/dbtest"
+	"code.waarp.fr/apps/gateway/gateway/pkg/fs"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils/testhelpers"
+)
+
+func TestPublishValidate(t *testing.T) {
+	t.Parallel()
+
+	brokerType, _ := brokerstest.MakeDummyBackend(t)
+	db := dbtest.TestDatabase(t)
+
+	broker := model.MessageBroker{Name: "broker", Type: brokerType, Address: "localhost:4222"}
+	require.NoError(t, db.Insert(&broker).Run())
+
+	for name, test := range map[string]struct {
+		args map[string]string
+		err  error
+	}{
+		"Missing broker": {
+			args: map[string]string{"topic": "transfers"},
+			err:  ErrPublishNoBroker,
+		},
+		"Missing topic": {
+			args: map[string]string{"broker": broker.Name},
+			err:  ErrPublishNoTopic,
+		},
+		"Unknown broker": {
+			args: map[string]string{"broker": "unknown", "topic": "transfers"},
+			err:  ErrPublishBrokerNotFound,
+		},
+		"Unknown hash algorithm": {
+			args: map[string]string{"broker": broker.Name, "topic": "transfers", "hashAlgorithm": "CRC64"},
+			err:  ErrChecksumUnknownAlgo,
+		},
+		"Valid": {
+			args: map[string]string{"broker": broker.Name, "topic": "transfers", "hashAlgorithm": "none"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := (&publishTask{}).ValidateDB(db, test.args)
+			if test.err == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestPublishRun(t *testing.T) {
+	t.Parallel()
+
+	const content = "publish task test file"
+
+	brokerType, backend := brokerstest.MakeDummyBackend(t)
+	logger := testhelpers.GetTestLogger(t)
+	db := dbtest.TestDatabase(t)
+
+	broker := model.MessageBroker{Name: "broker", Type: brokerType, Address: "localhost:4222"}
+	require.NoError(t, db.Insert(&broker).Run())
+
+	filePath := filepath.Join(t.TempDir(), "file.txt")
+	require.NoError(t, fs.WriteFullFile(filePath, []byte(content)))
+
+	sum := sha256.Sum256([]byte(content))
+	transCtx := &model.TransferContext{
+		Rule: &model.Rule{Name: "rule", IsSend: true},
+		Transfer: &model.Transfer{
+			ID:               1,
+			RemoteTransferID: "12345",
+			LocalPath:        filePath,
+			Filesize:         int64(len(content)),
+			TransferInfo:     map[string]any{"foo": "bar"},
+		},
+	}
+
+	t.Run("Given the default event", func(t *testing.T) {
+		args := map[string]string{"broker": broker.Name, "topic": "transfers", "key": "12345"}
+
+		require.NoError(t, (&publishTask{}).Run(t.Context(), args, db, logger, transCtx, nil))
+
+		msgs := backend.Messages()
+		require.Len(t, msgs, 1)
+		assert.Equal(t, "transfers", msgs[0].Topic)
+		assert.Equal(t, "12345", msgs[0].Key)
+		assert.Equal(t, "application/json", msgs[0].ContentType)
+		expected, err := json.Marshal(map[string]any{
+			"transferId":       1,
+			"remoteTransferId": "12345",
+			"rule":             "rule",
+			"direction":        "send",
+			"file":             "file.txt",
+			"path":             filePath,
+			"size":             len(content),
+			"hash":             hex.EncodeToString(sum[:]),
+			"hashAlgorithm":    "SHA-256",
+			"transferInfo":     map[string]any{"foo": "bar"},
+		})
+		require.NoError(t, err)
+		assert.JSONEq(t, string(expected), string(msgs[0].Payload))
+	})
+
+	t.Run("Given a custom message", func(t *testing.T) {
+		args := map[string]string{
+			"broker": broker.Name, "topic": "transfers",
+			"message": "file received", "contentType": "text/plain",
+		}
+
+		require.NoError(t, (&publishTask{}).Run(t.Context(), args, db, logger, transCtx, nil))
+
+		msgs := backend.Messages()
+		require.Len(t, msgs, 2)
+		assert.Equal(t, "text/plain", msgs[1].ContentType)
+		assert.Equal(t, "file received", string(msgs[1].Payload))
+	})
+}
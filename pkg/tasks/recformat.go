@@ -0,0 +1,289 @@
+package tasks
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/text/encoding"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/fs"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/tk/copybook"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils"
+)
+
+const (
+	RecFormatText     = "text"
+	RecFormatFixed    = "fixed"
+	RecFormatVariable = "variable"
+	RecFormatCSV      = "csv"
+
+	recFormatMaxVariableLength = 32760
+)
+
+var (
+	ErrRecFormatInvalidFrom       = errors.New(`invalid "from" record format`)
+	ErrRecFormatInvalidTo         = errors.New(`invalid "to" record format`)
+	ErrRecFormatSameFormats       = errors.New("the source and destination record formats are identical")
+	ErrRecFormatNoRecordLength    = errors.New(`fixed-length records require a "recordLength" or a "copybook"`)
+	ErrRecFormatNoCopybook        = errors.New(`the CSV conversion requires a "copybook"`)
+	ErrRecFormatCopybookNotFound  = errors.New("copybook not found")
+	ErrRecFormatInvalidPadding    = errors.New("the padding must be a single character")
+	ErrRecFormatInvalidNewline    = errors.New(`the newline must be either "LF" or "CRLF"`)
+	ErrRecFormatInvalidSeparator  = errors.New("the separator must be a single character")
+	ErrRecFormatInvalidBlockSize  = errors.New("invalid block size")
+	ErrRecFormatInvalidRecord     = errors.New("invalid record")
+	ErrRecFormatTruncatedRecord   = errors.New("the last record is truncated")
+	ErrRecFormatRecordTooLong     = errors.New("the record is too long")
+	ErrRecFormatInvalidDescriptor = errors.New("invalid record or block descriptor word")
+)
+
+// recFormatTask is a task which converts the transfer file between record
+// formats: line-based text, fixed-length records (FB), variable-length records
+// (VB, with record descriptor words) and CSV (using a COBOL copybook to
+// decode the records' fields).
+type recFormatTask struct {
+	From         string   `json:"from"`
+	To           string   `json:"to"`
+	RecordLength jsonInt  `json:"recordLength"`
+	Copybook     string   `json:"copybook"`
+	Charset      string   `json:"charset"`
+	Blocked      jsonBool `json:"blocked"`
+	BlockSize    jsonInt  `json:"blockSize"`
+	Padding      string   `json:"padding"`
+	Trim         jsonBool `json:"trim"`
+	Newline      string   `json:"newline"`
+	Separator    string   `json:"separator"`
+	Header       jsonBool `json:"header"`
+
+	layout    *copybook.Layout
+	encoding  encoding.Encoding
+	padByte   byte
+	lineFeed  byte
+	carReturn byte
+	separator rune
+}
+
+//nolint:funlen,gocyclo,cyclop //splitting the arguments validation would be less readable
+func (r *recFormatTask) ValidateDB(db database.ReadAccess, params map[string]string) error {
+	*r = recFormatTask{}
+	if err := utils.JSONConvert(params, r); err != nil {
+		return fmt.Errorf("failed to parse the RECFORMAT task parameters: %w", err)
+	}
+
+	r.From, r.To = strings.ToLower(r.From), strings.ToLower(r.To)
+
+	switch r.From {
+	case RecFormatText, RecFormatFixed, RecFormatVariable:
+	default:
+		return fmt.Errorf("%w %q", ErrRecFormatInvalidFrom, r.From)
+	}
+
+	switch r.To {
+	case RecFormatText, RecFormatFixed, RecFormatVariable, RecFormatCSV:
+	default:
+		return fmt.Errorf("%w %q", ErrRecFormatInvalidTo, r.To)
+	}
+
+	if r.From == r.To {
+		return ErrRecFormatSameFormats
+	}
+
+	if r.Copybook != "" {
+		var dbCopybook model.Copybook
+		if err := db.Get(&dbCopybook, "name=?", r.Copybook).Run(); err != nil {
+			if database.IsNotFound(err) {
+				return fmt.Errorf("%w: %q", ErrRecFormatCopybookNotFound, r.Copybook)
+			}
+
+			return fmt.Errorf("failed to retrieve copybook %q: %w", r.Copybook, err)
+		}
+
+		var err error
+		if r.layout, err = copybook.Parse(dbCopybook.Content); err != nil {
+			return fmt.Errorf("invalid copybook %q: %w", r.Copybook, err)
+		}
+
+		if r.RecordLength == 0 {
+			r.RecordLength = jsonInt(r.layout.Length)
+		}
+	}
+
+	if r.To == RecFormatCSV && r.layout == nil {
+		return ErrRecFormatNoCopybook
+	}
+
+	if (r.From == RecFormatFixed || r.To == RecFormatFixed) && r.RecordLength <= 0 {
+		return ErrRecFormatNoRecordLength
+	}
+
+	if r.Blocked {
+		if r.BlockSize == 0 {
+			r.BlockSize = recFormatMaxVariableLength
+		}
+
+		if r.BlockSize < 8 || r.BlockSize > recFormatMaxVariableLength {
+			return fmt.Errorf("%w %d", ErrRecFormatInvalidBlockSize, r.BlockSize)
+		}
+	}
+
+	if r.Charset != "" {
+		var err error
+		if r.encoding, err = getEncoding(r.Charset); err != nil {
+			return err
+		}
+	}
+
+	if err := r.parseControlChars(); err != nil {
+		return err
+	}
+
+	switch strings.ToUpper(r.Newline) {
+	case "", "LF":
+		r.Newline = "\n"
+	case "CRLF":
+		r.Newline = "\r\n"
+	default:
+		return fmt.Errorf("%w, got %q", ErrRecFormatInvalidNewline, r.Newline)
+	}
+
+	r.separator = ','
+
+	if r.Separator != "" {
+		runes := []rune(r.Separator)
+		if len(runes) != 1 {
+			return fmt.Errorf("%w, got %q", ErrRecFormatInvalidSeparator, r.Separator)
+		}
+
+		r.separator = runes[0]
+	}
+
+	return nil
+}
+
+// parseControlChars computes the padding byte of the fixed-length records, and
+// the line separator bytes of the text lines, encoded with the file's charset.
+func (r *recFormatTask) parseControlChars() error {
+	if r.Padding == "" {
+		r.Padding = " "
+	}
+
+	encode := func(str string) ([]byte, error) {
+		if r.encoding == nil {
+			return []byte(str), nil
+		}
+
+		//nolint:wrapcheck //wrapped by the caller
+		return r.encoding.NewEncoder().Bytes([]byte(str))
+	}
+
+	padding, err := encode(r.Padding)
+	if err != nil || len(padding) != 1 {
+		return fmt.Errorf("%w, got %q", ErrRecFormatInvalidPadding, r.Padding)
+	}
+
+	controls, err := encode("\n\r")
+	if err != nil || len(controls) != 2 { //nolint:mnd //LF + CR
+		return fmt.Errorf("%w: the charset %q cannot encode line separators",
+			ErrTranscodeInvalidEncoding, r.Charset)
+	}
+
+	r.padByte = padding[0]
+	r.lineFeed, r.carReturn = controls[0], controls[1]
+
+	return nil
+}
+
+func (r *recFormatTask) Run(_ context.Context, params map[string]string, db *database.DB,
+	logger *log.Logger, transCtx *model.TransferContext, _ any,
+) error {
+	if err := r.ValidateDB(db, params); err != nil {
+		logger.Error(err.Error())
+
+		return err
+	}
+
+	srcPath := transCtx.Transfer.LocalPath
+	tmpPath := srcPath + ".tmp"
+
+	count, size, err := r.convertFile(srcPath, tmpPath)
+	if err != nil {
+		logger.Errorf("Failed to convert the file's records: %v", err)
+
+		return err
+	}
+
+	if err := fs.Remove(srcPath); err != nil {
+		logger.Errorf("Failed to delete source file: %v", err)
+
+		return fmt.Errorf("failed to delete source file: %w", err)
+	}
+
+	if err := fs.MoveFile(tmpPath, srcPath); err != nil {
+		logger.Errorf("Failed to rename temporary file: %v", err)
+
+		return fmt.Errorf("failed to rename temporary file: %w", err)
+	}
+
+	transCtx.Transfer.Filesize = size
+
+	logger.Debugf("Converted %d records from %s to %s", count, r.From, r.To)
+
+	return nil
+}
+
+func (r *recFormatTask) convertFile(srcPath, dstPath string) (count, size int64, err error) {
+	srcFile, opErr := fs.Open(srcPath)
+	if opErr != nil {
+		return 0, 0, fmt.Errorf("failed to open source file: %w", opErr)
+	}
+	defer srcFile.Close() //nolint:errcheck //close never returns an error on read-only files
+
+	size, err = writeCompressionOutput(dstPath, func(dstFile fs.File) error {
+		var convErr error
+		count, convErr = r.convert(bufio.NewReader(srcFile), dstFile)
+
+		return convErr
+	})
+
+	return count, size, err
+}
+
+func (r *recFormatTask) convert(src *bufio.Reader, dst io.Writer) (int64, error) {
+	buf := bufio.NewWriter(dst)
+	reader := r.newReader(src)
+	writer := r.newWriter(buf)
+
+	var count int64
+
+	for {
+		record, err := reader.next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return count, fmt.Errorf("record %d: %w", count+1, err)
+		}
+
+		count++
+
+		if err := writer.write(record); err != nil {
+			return count, fmt.Errorf("record %d: %w", count, err)
+		}
+	}
+
+	if err := writer.close(); err != nil {
+		return count, err
+	}
+
+	if err := buf.Flush(); err != nil {
+		return count, fmt.Errorf("failed to write the converted file: %w", err)
+	}
+
+	return count, nil
+}
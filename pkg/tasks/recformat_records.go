@@ -0,0 +1,359 @@
+package tasks
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const recFormatDescriptorLen = 4
+
+type recordReader interface {
+	next() ([]byte, error)
+}
+
+type recordWriter interface {
+	write(record []byte) error
+	close() error
+}
+
+func (r *recFormatTask) newReader(src *bufio.Reader) recordReader {
+	switch r.From {
+	case RecFormatFixed:
+		return &fixedRecordReader{src: src, buf: make([]byte, r.RecordLength)}
+	case RecFormatVariable:
+		return &variableRecordReader{src: src, blocked: bool(r.Blocked)}
+	default:
+		return &textRecordReader{src: src, lineFeed: r.lineFeed, carReturn: r.carReturn}
+	}
+}
+
+func (r *recFormatTask) newWriter(dst *bufio.Writer) recordWriter {
+	switch r.To {
+	case RecFormatFixed:
+		return &fixedRecordWriter{dst: dst, length: int(r.RecordLength), padding: r.padByte}
+	case RecFormatVariable:
+		writer := &variableRecordWriter{dst: dst}
+		if r.Blocked {
+			writer.blockSize = int(r.BlockSize)
+		}
+
+		return writer
+	case RecFormatCSV:
+		csvWriter := csv.NewWriter(dst)
+		csvWriter.Comma = r.separator
+		csvWriter.UseCRLF = r.Newline == "\r\n"
+
+		return &csvRecordWriter{task: r, dst: csvWriter, header: bool(r.Header)}
+	default:
+		newline := []byte{r.lineFeed}
+		if r.Newline == "\r\n" {
+			newline = []byte{r.carReturn, r.lineFeed}
+		}
+
+		writer := &textRecordWriter{dst: dst, newline: newline}
+		if r.Trim {
+			writer.trim = []byte{r.padByte}
+		}
+
+		return writer
+	}
+}
+
+// textRecordReader reads records separated by line feeds. A carriage return
+// preceding the line feed is removed from the record.
+type textRecordReader struct {
+	src                 *bufio.Reader
+	lineFeed, carReturn byte
+}
+
+func (t *textRecordReader) next() ([]byte, error) {
+	line, err := t.src.ReadBytes(t.lineFeed)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to read the source file: %w", err)
+	}
+
+	if len(line) == 0 {
+		return nil, io.EOF
+	}
+
+	line = bytes.TrimSuffix(line, []byte{t.lineFeed})
+	line = bytes.TrimSuffix(line, []byte{t.carReturn})
+
+	return line, nil
+}
+
+// fixedRecordReader reads fixed-length records.
+type fixedRecordReader struct {
+	src *bufio.Reader
+	buf []byte
+}
+
+func (f *fixedRecordReader) next() ([]byte, error) {
+	n, err := io.ReadFull(f.src, f.buf)
+
+	switch {
+	case errors.Is(err, io.EOF):
+		return nil, io.EOF
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return nil, fmt.Errorf("%w (%d bytes instead of %d)", ErrRecFormatTruncatedRecord,
+			n, len(f.buf))
+	case err != nil:
+		return nil, fmt.Errorf("failed to read the source file: %w", err)
+	}
+
+	return f.buf, nil
+}
+
+// variableRecordReader reads variable-length records, each prefixed by a
+// record descriptor word (RDW). If the records are blocked, each block is also
+// prefixed by a block descriptor word (BDW).
+type variableRecordReader struct {
+	src       *bufio.Reader
+	blocked   bool
+	remaining int // the number of bytes left in the current block
+}
+
+func (v *variableRecordReader) next() ([]byte, error) {
+	if v.blocked && v.remaining == 0 {
+		length, err := v.readDescriptor()
+		if err != nil {
+			return nil, err
+		}
+
+		v.remaining = length - recFormatDescriptorLen
+	}
+
+	length, err := v.readDescriptor()
+	if errors.Is(err, io.EOF) && v.blocked {
+		return nil, fmt.Errorf("%w: the block is truncated", ErrRecFormatInvalidDescriptor)
+	} else if err != nil {
+		return nil, err
+	}
+
+	if v.blocked {
+		if length > v.remaining {
+			return nil, fmt.Errorf("%w: the record overflows its block", ErrRecFormatInvalidDescriptor)
+		}
+
+		v.remaining -= length
+	}
+
+	record := make([]byte, length-recFormatDescriptorLen)
+	if _, err := io.ReadFull(v.src, record); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRecFormatTruncatedRecord, err)
+	}
+
+	return record, nil
+}
+
+// readDescriptor reads a descriptor word, and returns the length it contains
+// (which includes the descriptor itself).
+func (v *variableRecordReader) readDescriptor() (int, error) {
+	var descriptor [recFormatDescriptorLen]byte
+
+	if _, err := io.ReadFull(v.src, descriptor[:]); errors.Is(err, io.EOF) {
+		return 0, io.EOF
+	} else if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrRecFormatInvalidDescriptor, err)
+	}
+
+	length := int(binary.BigEndian.Uint16(descriptor[:2]))
+	if length < recFormatDescriptorLen {
+		return 0, fmt.Errorf("%w: invalid length %d", ErrRecFormatInvalidDescriptor, length)
+	}
+
+	return length, nil
+}
+
+// textRecordWriter writes records as lines of text, optionally removing the
+// trailing padding of the records.
+type textRecordWriter struct {
+	dst     *bufio.Writer
+	newline []byte
+	trim    []byte
+}
+
+func (t *textRecordWriter) write(record []byte) error {
+	if len(t.trim) != 0 {
+		record = bytes.TrimRight(record, string(t.trim))
+	}
+
+	if bytes.Contains(record, t.newline[len(t.newline)-1:]) {
+		return fmt.Errorf("%w: the record contains a line separator", ErrRecFormatInvalidRecord)
+	}
+
+	if _, err := t.dst.Write(record); err != nil {
+		return fmt.Errorf("failed to write the converted file: %w", err)
+	}
+
+	if _, err := t.dst.Write(t.newline); err != nil {
+		return fmt.Errorf("failed to write the converted file: %w", err)
+	}
+
+	return nil
+}
+
+func (*textRecordWriter) close() error { return nil }
+
+// fixedRecordWriter writes fixed-length records, padding the shorter ones.
+type fixedRecordWriter struct {
+	dst     *bufio.Writer
+	length  int
+	padding byte
+}
+
+func (f *fixedRecordWriter) write(record []byte) error {
+	if len(record) > f.length {
+		return fmt.Errorf("%w (%d bytes, maximum is %d)", ErrRecFormatRecordTooLong,
+			len(record), f.length)
+	}
+
+	if _, err := f.dst.Write(record); err != nil {
+		return fmt.Errorf("failed to write the converted file: %w", err)
+	}
+
+	for range f.length - len(record) {
+		if err := f.dst.WriteByte(f.padding); err != nil {
+			return fmt.Errorf("failed to write the converted file: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (*fixedRecordWriter) close() error { return nil }
+
+// variableRecordWriter writes variable-length records, prefixed by their
+// record descriptor word. If blockSize is not 0, the records are grouped in
+// blocks of at most blockSize bytes, prefixed by a block descriptor word.
+type variableRecordWriter struct {
+	dst       *bufio.Writer
+	blockSize int
+	block     []byte
+}
+
+func (v *variableRecordWriter) write(record []byte) error {
+	length := len(record) + recFormatDescriptorLen
+
+	maxLength := recFormatMaxVariableLength
+	if v.blockSize != 0 {
+		maxLength = v.blockSize - recFormatDescriptorLen
+	}
+
+	if length > maxLength {
+		return fmt.Errorf("%w (%d bytes, maximum is %d)", ErrRecFormatRecordTooLong,
+			len(record), maxLength-recFormatDescriptorLen)
+	}
+
+	if v.blockSize == 0 {
+		return writeVariableRecord(v.dst, record)
+	}
+
+	if recFormatDescriptorLen+len(v.block)+length > v.blockSize {
+		if err := v.flushBlock(); err != nil {
+			return err
+		}
+	}
+
+	buf := bytes.NewBuffer(v.block)
+	_ = writeVariableRecord(buf, record) //nolint:errcheck //writing to a buffer cannot fail
+	v.block = buf.Bytes()
+
+	return nil
+}
+
+func (v *variableRecordWriter) flushBlock() error {
+	if len(v.block) == 0 {
+		return nil
+	}
+
+	if err := writeVariableRecord(v.dst, v.block); err != nil {
+		return err
+	}
+
+	v.block = v.block[:0]
+
+	return nil
+}
+
+func (v *variableRecordWriter) close() error {
+	return v.flushBlock()
+}
+
+// writeVariableRecord writes the given data prefixed by a descriptor word.
+func writeVariableRecord(dst io.Writer, data []byte) error {
+	var descriptor [recFormatDescriptorLen]byte
+
+	//nolint:gosec //the length was checked by the caller
+	binary.BigEndian.PutUint16(descriptor[:2], uint16(len(data)+recFormatDescriptorLen))
+
+	if _, err := dst.Write(descriptor[:]); err != nil {
+		return fmt.Errorf("failed to write the converted file: %w", err)
+	}
+
+	if _, err := dst.Write(data); err != nil {
+		return fmt.Errorf("failed to write the converted file: %w", err)
+	}
+
+	return nil
+}
+
+// csvRecordWriter writes the records as CSV lines, using the task's copybook
+// to decode the records' fields.
+type csvRecordWriter struct {
+	task   *recFormatTask
+	dst    *csv.Writer
+	header bool
+	values []string
+}
+
+func (c *csvRecordWriter) write(record []byte) error {
+	fields := c.task.layout.Fields
+
+	if c.header {
+		c.header = false
+		c.values = make([]string, len(fields))
+
+		for i, field := range fields {
+			c.values[i] = field.Name
+		}
+
+		if err := c.dst.Write(c.values); err != nil {
+			return fmt.Errorf("failed to write the converted file: %w", err)
+		}
+	}
+
+	if c.values == nil {
+		c.values = make([]string, len(fields))
+	}
+
+	for i, field := range fields {
+		value, err := field.Decode(record, c.task.encoding)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrRecFormatInvalidRecord, err)
+		}
+
+		c.values[i] = value
+	}
+
+	if err := c.dst.Write(c.values); err != nil {
+		return fmt.Errorf("failed to write the converted file: %w", err)
+	}
+
+	return nil
+}
+
+func (c *csvRecordWriter) close() error {
+	c.dst.Flush()
+
+	if err := c.dst.Error(); err != nil {
+		return fmt.Errorf("failed to write the converted file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,260 @@
+package tasks
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database/dbtest"
+	"code.waarp.fr/apps/gateway/gateway/pkg/fs"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils/testhelpers"
+)
+
+const recFormatTestCopybook = `
+       01  CUSTOMER-RECORD.
+           05  CUST-NAME      PIC X(5).
+           05  CUST-BALANCE   PIC S9(5)V99 COMP-3.
+           05  CUST-ORDERS    PIC 9(3).
+`
+
+func makeRecFormatTestContext(t *testing.T, content []byte) *model.TransferContext {
+	t.Helper()
+
+	filePath := filepath.Join(t.TempDir(), "file.dat")
+	require.NoError(t, fs.WriteFullFile(filePath, content))
+
+	return &model.TransferContext{
+		Rule: &model.Rule{IsSend: true},
+		Transfer: &model.Transfer{
+			LocalPath: filePath,
+			Filesize:  int64(len(content)),
+		},
+	}
+}
+
+func TestRecFormatValidateDB(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+	require.NoError(t, db.Insert(&model.Copybook{
+		Name: "customers", Content: recFormatTestCopybook,
+	}).Run())
+
+	for _, test := range []struct {
+		name    string
+		args    map[string]string
+		wantErr error
+	}{
+		{
+			"Given a record length", map[string]string{
+				"from": "fixed", "to": "text", "recordLength": "80",
+			}, nil,
+		},
+		{
+			"Given a copybook", map[string]string{
+				"from": "variable", "to": "csv", "copybook": "customers",
+			}, nil,
+		},
+		{
+			"Given an invalid source format", map[string]string{
+				"from": "csv", "to": "text",
+			}, ErrRecFormatInvalidFrom,
+		},
+		{
+			"Given an invalid destination format", map[string]string{
+				"from": "text", "to": "vsam",
+			}, ErrRecFormatInvalidTo,
+		},
+		{
+			"Given identical formats", map[string]string{
+				"from": "text", "to": "text",
+			}, ErrRecFormatSameFormats,
+		},
+		{
+			"Given no record length", map[string]string{
+				"from": "text", "to": "fixed",
+			}, ErrRecFormatNoRecordLength,
+		},
+		{
+			"Given a CSV conversion without copybook", map[string]string{
+				"from": "fixed", "to": "csv", "recordLength": "12",
+			}, ErrRecFormatNoCopybook,
+		},
+		{
+			"Given an unknown copybook", map[string]string{
+				"from": "fixed", "to": "csv", "copybook": "unknown",
+			}, ErrRecFormatCopybookNotFound,
+		},
+		{
+			"Given an invalid padding", map[string]string{
+				"from": "text", "to": "fixed", "recordLength": "80", "padding": "ab",
+			}, ErrRecFormatInvalidPadding,
+		},
+		{
+			"Given an invalid block size", map[string]string{
+				"from": "text", "to": "variable", "blocked": "true", "blockSize": "65536",
+			}, ErrRecFormatInvalidBlockSize,
+		},
+		{
+			"Given an invalid charset", map[string]string{
+				"from": "fixed", "to": "text", "recordLength": "80", "charset": "foo",
+			}, ErrTranscodeInvalidEncoding,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := (&recFormatTask{}).ValidateDB(db, test.args)
+			if test.wantErr == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestRecFormatRun(t *testing.T) {
+	t.Parallel()
+
+	logger := testhelpers.GetTestLogger(t)
+	db := dbtest.TestDatabase(t)
+	require.NoError(t, db.Insert(&model.Copybook{
+		Name: "customers", Content: recFormatTestCopybook,
+	}).Run())
+
+	for _, test := range []struct {
+		name     string
+		args     map[string]string
+		input    string
+		expected string
+	}{
+		{
+			"Given a text to fixed conversion",
+			map[string]string{"from": "text", "to": "fixed", "recordLength": "5", "padding": "."},
+			"abc\r\nde\n\nfghij",
+			"abc..de........fghij",
+		},
+		{
+			"Given a fixed to text conversion",
+			map[string]string{"from": "fixed", "to": "text", "recordLength": "5", "trim": "true"},
+			"abc  de        fghij",
+			"abc\nde\n\nfghij\n",
+		},
+		{
+			"Given a text to variable conversion",
+			map[string]string{"from": "text", "to": "variable"},
+			"abc\nde\n",
+			"\x00\x07\x00\x00abc\x00\x06\x00\x00de",
+		},
+		{
+			"Given a blocked variable to text conversion",
+			map[string]string{"from": "variable", "to": "text", "blocked": "true", "newline": "CRLF"},
+			"\x00\x0F\x00\x00\x00\x07\x00\x00abc\x00\x04\x00\x00\x00\x0A\x00\x00\x00\x06\x00\x00de",
+			"abc\r\n\r\nde\r\n",
+		},
+		{
+			"Given a text to blocked variable conversion",
+			map[string]string{"from": "text", "to": "variable", "blocked": "true", "blockSize": "16"},
+			"abc\n\nde",
+			"\x00\x0F\x00\x00\x00\x07\x00\x00abc\x00\x04\x00\x00\x00\x0A\x00\x00\x00\x06\x00\x00de",
+		},
+		{
+			"Given a fixed to CSV conversion",
+			map[string]string{
+				"from": "fixed", "to": "csv", "copybook": "customers",
+				"header": "true", "separator": ";",
+			},
+			"Alice\x00\x12\x34\x5D042Bob  \x00\x00\x10\x0C   ",
+			"CUST-NAME;CUST-BALANCE;CUST-ORDERS\nAlice;-123.45;42\nBob;1.00;\n",
+		},
+		{
+			"Given an EBCDIC variable to CSV conversion",
+			map[string]string{
+				"from": "variable", "to": "csv", "copybook": "customers",
+				"charset": "IBM Code Page 500",
+			},
+			"\x00\x10\x00\x00\xC1\x93\x89\x83\x85\x00\x12\x34\x5D\xF0\xF4\xF2",
+			"Alice,-123.45,42\n",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			transCtx := makeRecFormatTestContext(t, []byte(test.input))
+			filePath := transCtx.Transfer.LocalPath
+
+			require.NoError(t, (&recFormatTask{}).Run(t.Context(), test.args, db,
+				logger, transCtx, nil))
+
+			t.Run("Then the file should have been converted", func(t *testing.T) {
+				content, err := fs.ReadFullFile(filePath)
+				require.NoError(t, err)
+
+				assert.Equal(t, test.expected, string(content))
+				assert.Equal(t, int64(len(test.expected)), transCtx.Transfer.Filesize)
+				assert.Equal(t, filePath, transCtx.Transfer.LocalPath)
+				assert.NoFileExists(t, filePath+".tmp")
+			})
+		})
+	}
+}
+
+func TestRecFormatRunErrors(t *testing.T) {
+	t.Parallel()
+
+	logger := testhelpers.GetTestLogger(t)
+
+	for _, test := range []struct {
+		name    string
+		args    map[string]string
+		input   string
+		wantErr error
+	}{
+		{
+			"Given a truncated fixed record",
+			map[string]string{"from": "fixed", "to": "text", "recordLength": "5"},
+			"abcdefg",
+			ErrRecFormatTruncatedRecord,
+		},
+		{
+			"Given a record too long for the fixed length",
+			map[string]string{"from": "text", "to": "fixed", "recordLength": "3"},
+			"abc\nabcd\n",
+			ErrRecFormatRecordTooLong,
+		},
+		{
+			"Given an invalid record descriptor",
+			map[string]string{"from": "variable", "to": "text"},
+			"\x00\x02\x00\x00",
+			ErrRecFormatInvalidDescriptor,
+		},
+		{
+			"Given a truncated variable record",
+			map[string]string{"from": "variable", "to": "text"},
+			"\x00\x10\x00\x00abc",
+			ErrRecFormatTruncatedRecord,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			transCtx := makeRecFormatTestContext(t, []byte(test.input))
+			filePath := transCtx.Transfer.LocalPath
+
+			err := (&recFormatTask{}).Run(t.Context(), test.args, nil, logger, transCtx, nil)
+			require.ErrorIs(t, err, test.wantErr)
+
+			t.Run("Then the original file should be left untouched", func(t *testing.T) {
+				content, err := fs.ReadFullFile(filePath)
+				require.NoError(t, err)
+
+				assert.Equal(t, test.input, string(content))
+				assert.NoFileExists(t, filePath+".tmp")
+			})
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -116,6 +117,9 @@ func getReplacers() replacersMap {
 		"#ERRORSTRCODE#": func(ctx *model.TransferContext, _ string) (string, error) {
 			return ctx.Transfer.ErrDetails, nil
 		},
+		"#FINALATTEMPT#": func(ctx *model.TransferContext, _ string) (string, error) {
+			return strconv.FormatBool(ctx.FinalAttempt), nil
+		},
 		"#NOWAIT#":    notImplemented("#NOWAIT#"),
 		"#LOCALEXEC#": notImplemented("#LOCALEXEC#"),
 	}
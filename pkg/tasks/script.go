@@ -0,0 +1,182 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/fs"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils"
+)
+
+var (
+	ErrScriptNoSource    = errors.New(`either a "script" name or a "file" must be given`)
+	ErrScriptManySources = errors.New(`"script" and "file" cannot be used together`)
+	ErrScriptNotFound    = errors.New("script not found")
+	ErrScriptCompile     = errors.New("failed to compile the script")
+	ErrScriptFailed      = errors.New("script failed")
+)
+
+// scriptFileOptions are the Starlark dialect options used for the scripts. They
+// lift the restrictions of the Bazel dialect (top-level loops, recursion...),
+// which are pointless here.
+//
+//nolint:gochecknoglobals //global var is needed here for the script options
+var scriptFileOptions = &syntax.FileOptions{
+	Set:             true,
+	While:           true,
+	TopLevelControl: true,
+	GlobalReassign:  true,
+	Recursion:       true,
+}
+
+// scriptTask is a task which executes a Starlark script inside the gateway,
+// without spawning a process. The script can read and modify the transfer
+// (its paths and transfer info), perform file operations, and set the outcome
+// of the task. The script can either be stored in the database, or be read
+// from a file.
+type scriptTask struct {
+	Script   string     `json:"script"`
+	File     string     `json:"file"`
+	Params   jsonObject `json:"params"`
+	MaxSteps jsonInt    `json:"maxSteps"`
+
+	source string
+}
+
+func (s *scriptTask) ValidateDB(db database.ReadAccess, args map[string]string) error {
+	*s = scriptTask{}
+
+	if err := utils.JSONConvert(args, s); err != nil {
+		return fmt.Errorf("failed to parse the script arguments: %w", err)
+	}
+
+	switch {
+	case s.Script == "" && s.File == "":
+		return ErrScriptNoSource
+	case s.Script != "" && s.File != "":
+		return ErrScriptManySources
+	case s.File != "":
+		// The file is only read when the task is executed, since its path
+		// may contain substitution markers.
+		return nil
+	}
+
+	var script model.Script
+	if err := db.Get(&script, "name=?", s.Script).Run(); err != nil {
+		if database.IsNotFound(err) {
+			return fmt.Errorf("%w: %q", ErrScriptNotFound, s.Script)
+		}
+
+		return fmt.Errorf("failed to retrieve script %q: %w", s.Script, err)
+	}
+
+	s.source = script.Content
+
+	if _, _, err := starlark.SourceProgramOptions(scriptFileOptions, s.Script,
+		s.source, isScriptPredeclared); err != nil {
+		return fmt.Errorf("%w %q: %w", ErrScriptCompile, s.Script, err)
+	}
+
+	return nil
+}
+
+func (s *scriptTask) Run(ctx context.Context, args map[string]string, db *database.DB,
+	logger *log.Logger, transCtx *model.TransferContext, _ any,
+) error {
+	if err := s.ValidateDB(db, args); err != nil {
+		logger.Error(err.Error())
+
+		return err
+	}
+
+	name := s.Script
+
+	if s.File != "" {
+		content, err := fs.ReadFullFile(s.File)
+		if err != nil {
+			logger.Errorf("Failed to read the script file: %v", err)
+
+			return fmt.Errorf("failed to read the script file: %w", err)
+		}
+
+		name, s.source = s.File, string(content)
+	}
+
+	env, envErr := newScriptEnv(logger, transCtx, s.Params)
+	if envErr != nil {
+		logger.Errorf("Failed to prepare the script environment: %v", envErr)
+
+		return envErr
+	}
+
+	thread := &starlark.Thread{
+		Name:  name,
+		Print: func(_ *starlark.Thread, msg string) { logger.Info(msg) },
+	}
+
+	if s.MaxSteps > 0 {
+		thread.SetMaxExecutionSteps(uint64(s.MaxSteps))
+	}
+
+	stop := context.AfterFunc(ctx, func() { thread.Cancel(context.Cause(ctx).Error()) })
+	defer stop()
+
+	_, execErr := starlark.ExecFileOptions(scriptFileOptions, thread, name, s.source, env.globals())
+	if execErr != nil {
+		return scriptError(logger, name, execErr)
+	}
+
+	if err := env.commit(); err != nil {
+		logger.Errorf("Script %q failed: %v", name, err)
+
+		return fmt.Errorf("%w: %w", ErrScriptFailed, err)
+	}
+
+	if env.warning != "" {
+		logger.Warningf("Script %q ended with a warning: %s", name, env.warning)
+
+		return &WarningError{env.warning}
+	}
+
+	logger.Debugf("Script %q executed successfully", name)
+
+	return nil
+}
+
+// scriptError converts the given script execution error to a task error. The
+// changes made by the script to the transfer are discarded.
+func scriptError(logger *log.Logger, name string, execErr error) error {
+	var taskErr *Error
+	if errors.As(execErr, &taskErr) {
+		// The script explicitly failed using the "error" function.
+		logger.Errorf("Script %q failed: %v", name, taskErr)
+
+		return taskErr
+	}
+
+	var evalErr *starlark.EvalError
+	if !errors.As(execErr, &evalErr) {
+		logger.Errorf("Script %q failed: %v", name, execErr)
+
+		return fmt.Errorf("%w: %w", ErrScriptFailed, execErr)
+	}
+
+	logger.Errorf("Script %q failed: %s", name, evalErr.Backtrace())
+
+	// The error is located at the innermost frame of the script itself
+	// (ignoring the builtins' frames).
+	for i := range evalErr.CallStack {
+		if pos := evalErr.CallStack.At(i).Pos; pos.Filename() == name {
+			return fmt.Errorf("%w at line %d: %s", ErrScriptFailed, pos.Line, evalErr.Msg)
+		}
+	}
+
+	return fmt.Errorf("%w: %s", ErrScriptFailed, evalErr.Msg)
+}
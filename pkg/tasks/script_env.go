@@ -0,0 +1,490 @@
+package tasks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/fs"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/types"
+)
+
+var (
+	errScriptUnsupportedType = errors.New("unsupported value type")
+	errScriptInfoKey         = errors.New("transfer info keys must be strings")
+	errScriptReadOnly        = errors.New("read-only attribute")
+	errScriptNotString       = errors.New("value must be a string")
+	errScriptUnknownCode     = errors.New("unknown error code")
+)
+
+// scriptPredeclared lists the names predeclared in the scripts' environment
+// (in addition to the Starlark built-ins).
+//
+//nolint:gochecknoglobals //global var is needed here for the list of names
+var scriptPredeclared = []string{"transfer", "params", "fs", "log", "warn", "error"}
+
+func isScriptPredeclared(name string) bool { return slices.Contains(scriptPredeclared, name) }
+
+// scriptEnv is the environment in which a script is executed. The changes made
+// by the script to the transfer are buffered, and are only applied to the
+// transfer if the script succeeds.
+type scriptEnv struct {
+	logger   *log.Logger
+	transfer *scriptTransfer
+	params   starlark.Value
+	warning  string
+}
+
+func newScriptEnv(logger *log.Logger, transCtx *model.TransferContext, params jsonObject,
+) (*scriptEnv, error) {
+	starParams, paramsErr := toStarlark(map[string]any(params))
+	if paramsErr != nil {
+		return nil, fmt.Errorf("invalid script parameters: %w", paramsErr)
+	}
+
+	starParams.Freeze()
+
+	info := starlark.NewDict(len(transCtx.Transfer.TransferInfo))
+	origInfo := make(map[string]starlark.Value, len(transCtx.Transfer.TransferInfo))
+
+	for key, val := range transCtx.Transfer.TransferInfo {
+		starVal, err := toStarlark(val)
+		if err != nil {
+			return nil, fmt.Errorf("invalid transfer info %q: %w", key, err)
+		}
+
+		origInfo[key] = starVal
+
+		if err := info.SetKey(starlark.String(key), starVal); err != nil {
+			return nil, fmt.Errorf("failed to set transfer info %q: %w", key, err)
+		}
+	}
+
+	return &scriptEnv{
+		logger: logger,
+		params: starParams,
+		transfer: &scriptTransfer{
+			transCtx:   transCtx,
+			localPath:  transCtx.Transfer.LocalPath,
+			remotePath: transCtx.Transfer.RemotePath,
+			info:       info,
+			origInfo:   origInfo,
+		},
+	}, nil
+}
+
+func (e *scriptEnv) globals() starlark.StringDict {
+	return starlark.StringDict{
+		"transfer": e.transfer,
+		"params":   e.params,
+		"fs":       scriptFSModule(),
+		"log":      e.logModule(),
+		"warn":     starlark.NewBuiltin("warn", e.warn),
+		"error":    starlark.NewBuiltin("error", scriptFail),
+	}
+}
+
+// warn sets the outcome of the task to a warning. The script continues its
+// execution.
+func (e *scriptEnv) warn(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple,
+	kwargs []starlark.Tuple,
+) (starlark.Value, error) {
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &e.warning); err != nil {
+		return nil, err //nolint:wrapcheck //error is returned to the script
+	}
+
+	return starlark.None, nil
+}
+
+// scriptFail stops the script with the given error message and transfer error
+// code.
+func scriptFail(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple,
+	kwargs []starlark.Tuple,
+) (starlark.Value, error) {
+	var msg string
+
+	code := types.TeExternalOperation.String()
+
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "msg", &msg, "code?", &code); err != nil {
+		return nil, err //nolint:wrapcheck //error is returned to the script
+	}
+
+	tec := types.TecFromString(code)
+	if !tec.IsValid() || tec.String() != code {
+		return nil, fmt.Errorf("%s: %w %q", b.Name(), errScriptUnknownCode, code)
+	}
+
+	return nil, newError(tec, "%s", msg)
+}
+
+// commit applies the changes made by the script to the transfer.
+func (e *scriptEnv) commit() error {
+	trans := e.transfer.transCtx.Transfer
+	newInfo := make(map[string]any, e.transfer.info.Len())
+
+	for _, item := range e.transfer.info.Items() {
+		key, ok := starlark.AsString(item[0])
+		if !ok {
+			return fmt.Errorf("%w, got %s", errScriptInfoKey, item[0].Type())
+		}
+
+		if orig, exists := e.transfer.origInfo[key]; exists {
+			if eq, err := starlark.Equal(orig, item[1]); err == nil && eq {
+				continue // unchanged
+			}
+		}
+
+		val, err := fromStarlark(item[1])
+		if err != nil {
+			return fmt.Errorf("invalid transfer info %q: %w", key, err)
+		}
+
+		newInfo[key] = val
+	}
+
+	if trans.LocalPath != e.transfer.localPath {
+		e.logger.Debugf("Changed the transfer's local path to %q", e.transfer.localPath)
+		trans.LocalPath = e.transfer.localPath
+	}
+
+	if trans.RemotePath != e.transfer.remotePath {
+		e.logger.Debugf("Changed the transfer's remote path to %q", e.transfer.remotePath)
+		trans.RemotePath = e.transfer.remotePath
+	}
+
+	if trans.TransferInfo == nil {
+		trans.TransferInfo = map[string]any{}
+	}
+
+	for key := range e.transfer.origInfo {
+		if _, exists, _ := e.transfer.info.Get(starlark.String(key)); !exists {
+			delete(trans.TransferInfo, key)
+			e.logger.Debugf("Deleted transfer info %q", key)
+		}
+	}
+
+	for key, val := range newInfo {
+		trans.TransferInfo[key] = val
+		e.logger.Debugf("Set transfer info %q = %v", key, val)
+	}
+
+	return nil
+}
+
+func (e *scriptEnv) logModule() *starlarkstruct.Module {
+	logFunc := func(name string, fn func(string)) *starlark.Builtin {
+		return starlark.NewBuiltin("log."+name, func(_ *starlark.Thread, b *starlark.Builtin,
+			args starlark.Tuple, kwargs []starlark.Tuple,
+		) (starlark.Value, error) {
+			var msg string
+			if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &msg); err != nil {
+				return nil, err //nolint:wrapcheck //error is returned to the script
+			}
+
+			fn(msg)
+
+			return starlark.None, nil
+		})
+	}
+
+	return &starlarkstruct.Module{Name: "log", Members: starlark.StringDict{
+		"debug":   logFunc("debug", func(msg string) { e.logger.Debug(msg) }),
+		"info":    logFunc("info", func(msg string) { e.logger.Info(msg) }),
+		"warning": logFunc("warning", func(msg string) { e.logger.Warning(msg) }),
+		"error":   logFunc("error", func(msg string) { e.logger.Error(msg) }),
+	}}
+}
+
+// scriptFSFunc returns a builtin function of the "fs" module, which takes the
+// given number of string arguments.
+func scriptFSFunc(name string, nbArgs int, fn func(args []string) (starlark.Value, error),
+) *starlark.Builtin {
+	return starlark.NewBuiltin("fs."+name, func(_ *starlark.Thread, b *starlark.Builtin,
+		args starlark.Tuple, kwargs []starlark.Tuple,
+	) (starlark.Value, error) {
+		strArgs := make([]string, nbArgs)
+		ptrs := make([]any, nbArgs)
+
+		for i := range strArgs {
+			ptrs[i] = &strArgs[i]
+		}
+
+		if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, nbArgs, ptrs...); err != nil {
+			return nil, err //nolint:wrapcheck //error is returned to the script
+		}
+
+		res, err := fn(strArgs)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", b.Name(), err)
+		}
+
+		return res, nil
+	})
+}
+
+func scriptFSModule() *starlarkstruct.Module {
+	none := func(err error) (starlark.Value, error) { return starlark.None, err }
+
+	return &starlarkstruct.Module{Name: "fs", Members: starlark.StringDict{
+		"read": scriptFSFunc("read", 1, func(args []string) (starlark.Value, error) {
+			content, err := fs.ReadFullFile(args[0])
+
+			return starlark.String(content), err //nolint:wrapcheck //wrapped by scriptFSFunc
+		}),
+		"write": scriptFSFunc("write", 2, func(args []string) (starlark.Value, error) {
+			return none(fs.WriteFullFile(args[0], []byte(args[1])))
+		}),
+		"exists": scriptFSFunc("exists", 1, func(args []string) (starlark.Value, error) {
+			_, err := fs.Stat(args[0])
+			if errors.Is(err, fs.ErrNotExist) {
+				return starlark.False, nil
+			}
+
+			return starlark.Bool(err == nil), err //nolint:wrapcheck //wrapped by scriptFSFunc
+		}),
+		"size": scriptFSFunc("size", 1, func(args []string) (starlark.Value, error) {
+			info, err := fs.Stat(args[0])
+			if err != nil {
+				return nil, err //nolint:wrapcheck //wrapped by scriptFSFunc
+			}
+
+			return starlark.MakeInt64(info.Size()), nil
+		}),
+		"list": scriptFSFunc("list", 1, func(args []string) (starlark.Value, error) {
+			matches, err := fs.Glob(args[0])
+			if err != nil {
+				return nil, err //nolint:wrapcheck //wrapped by scriptFSFunc
+			}
+
+			list := make([]starlark.Value, len(matches))
+			for i, match := range matches {
+				list[i] = starlark.String(match)
+			}
+
+			return starlark.NewList(list), nil
+		}),
+		"mkdir": scriptFSFunc("mkdir", 1, func(args []string) (starlark.Value, error) {
+			return none(fs.MkdirAll(args[0]))
+		}),
+		"copy": scriptFSFunc("copy", 2, func(args []string) (starlark.Value, error) {
+			return none(fs.CopyFile(args[0], args[1]))
+		}),
+		"move": scriptFSFunc("move", 2, func(args []string) (starlark.Value, error) {
+			return none(fs.MoveFile(args[0], args[1]))
+		}),
+		"remove": scriptFSFunc("remove", 1, func(args []string) (starlark.Value, error) {
+			return none(fs.Remove(args[0]))
+		}),
+	}}
+}
+
+// scriptTransfer is the "transfer" object of the scripts. Its paths and its
+// transfer info can be modified by the script.
+type scriptTransfer struct {
+	transCtx   *model.TransferContext
+	localPath  string
+	remotePath string
+	info       *starlark.Dict
+	origInfo   map[string]starlark.Value
+}
+
+//nolint:gochecknoglobals //global var is needed here for the list of attributes
+var scriptTransferAttrs = []string{
+	"dest_filename", "filesize", "id", "info", "is_send", "is_server", "local_path",
+	"remote_id", "remote_path", "requested", "requester", "rule", "src_filename",
+}
+
+func (t *scriptTransfer) String() string        { return fmt.Sprintf("<transfer %d>", t.transCtx.Transfer.ID) }
+func (t *scriptTransfer) Type() string          { return "transfer" }
+func (t *scriptTransfer) Freeze()               { t.info.Freeze() }
+func (t *scriptTransfer) Truth() starlark.Bool  { return starlark.True }
+func (t *scriptTransfer) AttrNames() []string   { return scriptTransferAttrs }
+func (t *scriptTransfer) Hash() (uint32, error) { return 0, fmt.Errorf("unhashable: %s", t.Type()) }
+
+//nolint:cyclop //the attributes are simple, splitting this would not help
+func (t *scriptTransfer) Attr(name string) (starlark.Value, error) {
+	trans := t.transCtx.Transfer
+
+	switch name {
+	case "id":
+		return starlark.MakeInt64(trans.ID), nil
+	case "remote_id":
+		return starlark.String(trans.RemoteTransferID), nil
+	case "rule":
+		return starlark.String(t.transCtx.Rule.Name), nil
+	case "is_send":
+		return starlark.Bool(t.transCtx.Rule.IsSend), nil
+	case "is_server":
+		return starlark.Bool(trans.IsServer()), nil
+	case "requester":
+		requester, _ := getClient(t.transCtx, "")
+
+		return starlark.String(requester), nil
+	case "requested":
+		requested, _ := getServer(t.transCtx, "")
+
+		return starlark.String(requested), nil
+	case "src_filename":
+		return starlark.String(trans.SrcFilename), nil
+	case "dest_filename":
+		return starlark.String(trans.DestFilename), nil
+	case "filesize":
+		return starlark.MakeInt64(trans.Filesize), nil
+	case "local_path":
+		return starlark.String(t.localPath), nil
+	case "remote_path":
+		return starlark.String(t.remotePath), nil
+	case "info":
+		return t.info, nil
+	default:
+		return nil, nil //nolint:nilnil //this is how starlark signals a missing attribute
+	}
+}
+
+func (t *scriptTransfer) SetField(name string, val starlark.Value) error {
+	var field *string
+
+	switch name {
+	case "local_path":
+		field = &t.localPath
+	case "remote_path":
+		field = &t.remotePath
+	default:
+		if slices.Contains(scriptTransferAttrs, name) {
+			return fmt.Errorf("transfer.%s: %w", name, errScriptReadOnly)
+		}
+
+		return starlark.NoSuchAttrError(fmt.Sprintf("transfer has no .%s attribute", name))
+	}
+
+	str, ok := starlark.AsString(val)
+	if !ok {
+		return fmt.Errorf("transfer.%s: %w, got %s", name, errScriptNotString, val.Type())
+	}
+
+	*field = str
+
+	return nil
+}
+
+// toStarlark converts the given transfer info (or parameter) value to a
+// Starlark value.
+func toStarlark(val any) (starlark.Value, error) {
+	switch val := val.(type) {
+	case nil:
+		return starlark.None, nil
+	case bool:
+		return starlark.Bool(val), nil
+	case string:
+		return starlark.String(val), nil
+	case int:
+		return starlark.MakeInt(val), nil
+	case int64:
+		return starlark.MakeInt64(val), nil
+	case float64:
+		return starlark.Float(val), nil
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return starlark.MakeInt64(i), nil
+		}
+
+		f, err := val.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q: %w", val, err)
+		}
+
+		return starlark.Float(f), nil
+	case []any:
+		list := make([]starlark.Value, len(val))
+
+		for i, elem := range val {
+			var err error
+			if list[i], err = toStarlark(elem); err != nil {
+				return nil, err
+			}
+		}
+
+		return starlark.NewList(list), nil
+	case map[string]any:
+		dict := starlark.NewDict(len(val))
+
+		for key, elem := range val {
+			starElem, err := toStarlark(elem)
+			if err != nil {
+				return nil, err
+			}
+
+			if err := dict.SetKey(starlark.String(key), starElem); err != nil {
+				return nil, fmt.Errorf("failed to set key %q: %w", key, err)
+			}
+		}
+
+		return dict, nil
+	default:
+		return nil, fmt.Errorf("%w %T", errScriptUnsupportedType, val)
+	}
+}
+
+// fromStarlark converts the given Starlark value to a value which can be stored
+// in the transfer info.
+func fromStarlark(val starlark.Value) (any, error) {
+	switch val := val.(type) {
+	case starlark.NoneType:
+		return nil, nil //nolint:nilnil //nil is a valid value here
+	case starlark.Bool:
+		return bool(val), nil
+	case starlark.String:
+		return string(val), nil
+	case starlark.Int:
+		if i, ok := val.Int64(); ok {
+			return i, nil
+		}
+
+		return val.String(), nil
+	case starlark.Float:
+		return float64(val), nil
+	case *starlark.List, starlark.Tuple:
+		iter := starlark.Iterate(val)
+		defer iter.Done()
+
+		list := []any{}
+
+		var elem starlark.Value
+		for iter.Next(&elem) {
+			goElem, err := fromStarlark(elem)
+			if err != nil {
+				return nil, err
+			}
+
+			list = append(list, goElem)
+		}
+
+		return list, nil
+	case *starlark.Dict:
+		dict := make(map[string]any, val.Len())
+
+		for _, item := range val.Items() {
+			key, ok := starlark.AsString(item[0])
+			if !ok {
+				return nil, fmt.Errorf("%w: dict keys must be strings", errScriptUnsupportedType)
+			}
+
+			goElem, err := fromStarlark(item[1])
+			if err != nil {
+				return nil, err
+			}
+
+			dict[key] = goElem
+		}
+
+		return dict, nil
+	default:
+		return nil, fmt.Errorf("%w %s", errScriptUnsupportedType, val.Type())
+	}
+}
@@ -0,0 +1,190 @@
+package tasks
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database/dbtest"
+	"code.waarp.fr/apps/gateway/gateway/pkg/fs"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/types"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils/testhelpers"
+)
+
+const scriptTestContent = `
+content = fs.read(transfer.local_path)
+if "forbidden" in content:
+    error("the file contains forbidden content", code = "TeForbidden")
+
+new_path = transfer.local_path + ".checked"
+fs.move(transfer.local_path, new_path)
+transfer.local_path = new_path
+
+transfer.info["lines"] = len(content.splitlines())
+transfer.info["checkedBy"] = params["user"]
+transfer.info.pop("toDelete", None)
+
+if transfer.info["lines"] > params["maxLines"]:
+    warn("the file has too many lines")
+`
+
+func makeScriptTestContext(t *testing.T, content string) *model.TransferContext {
+	t.Helper()
+
+	filePath := filepath.Join(t.TempDir(), "file.txt")
+	require.NoError(t, fs.WriteFullFile(filePath, []byte(content)))
+
+	return &model.TransferContext{
+		Rule: &model.Rule{Name: "rule", IsSend: true},
+		Transfer: &model.Transfer{
+			Identifier: model.ID(1234),
+			LocalPath:  filePath,
+			RemotePath: "/remote/file.txt",
+			TransferInfo: map[string]any{
+				"toDelete": "foo",
+				"kept":     map[string]any{"bar": []any{1, "2", true}},
+			},
+		},
+		RemoteAgent:   &model.RemoteAgent{Name: "partner"},
+		RemoteAccount: &model.RemoteAccount{Login: "login"},
+	}
+}
+
+func TestScriptValidateDB(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+	require.NoError(t, db.Insert(&model.Script{Name: "valid", Content: scriptTestContent}).Run())
+	require.NoError(t, db.Insert(&model.Script{Name: "unknown_name", Content: "foo(1)"}).Run())
+
+	for _, test := range []struct {
+		name    string
+		args    map[string]string
+		wantErr error
+	}{
+		{"Given a valid stored script", map[string]string{"script": "valid"}, nil},
+		{"Given a script file", map[string]string{"file": "/#INPATH#/script.star"}, nil},
+		{"Given no script", map[string]string{}, ErrScriptNoSource},
+		{"Given both sources", map[string]string{"script": "valid", "file": "foo"}, ErrScriptManySources},
+		{"Given an unknown script", map[string]string{"script": "unknown"}, ErrScriptNotFound},
+		{"Given an invalid script", map[string]string{"script": "unknown_name"}, ErrScriptCompile},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := (&scriptTask{}).ValidateDB(db, test.args)
+			if test.wantErr == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestScriptRun(t *testing.T) {
+	t.Parallel()
+
+	logger := testhelpers.GetTestLogger(t)
+	db := dbtest.TestDatabase(t)
+	require.NoError(t, db.Insert(&model.Script{Name: "check", Content: scriptTestContent}).Run())
+
+	t.Run("Given a successful script", func(t *testing.T) {
+		t.Parallel()
+
+		transCtx := makeScriptTestContext(t, "line 1\nline 2\n")
+		oldPath := transCtx.Transfer.LocalPath
+		args := map[string]string{"script": "check", "params": `{"user": "bob", "maxLines": 10}`}
+
+		require.NoError(t, (&scriptTask{}).Run(t.Context(), args, db, logger, transCtx, nil))
+
+		assert.Equal(t, oldPath+".checked", transCtx.Transfer.LocalPath,
+			"Then the transfer's local path should have been changed")
+		assert.FileExists(t, oldPath+".checked", "Then the file should have been moved")
+		assert.Equal(t, map[string]any{
+			"lines":     int64(2),
+			"checkedBy": "bob",
+			"kept":      map[string]any{"bar": []any{1, "2", true}},
+		}, transCtx.Transfer.TransferInfo, "Then the transfer info should have been updated")
+	})
+
+	t.Run("Given a script ending with a warning", func(t *testing.T) {
+		t.Parallel()
+
+		transCtx := makeScriptTestContext(t, "line 1\nline 2\n")
+		args := map[string]string{"script": "check", "params": `{"user": "bob", "maxLines": 1}`}
+
+		err := (&scriptTask{}).Run(t.Context(), args, db, logger, transCtx, nil)
+
+		var warning *WarningError
+		require.ErrorAs(t, err, &warning)
+		assert.Equal(t, "the file has too many lines", warning.Error())
+		assert.Equal(t, "bob", transCtx.Transfer.TransferInfo["checkedBy"],
+			"Then the transfer info should have been updated")
+	})
+
+	t.Run("Given a script failing with an error code", func(t *testing.T) {
+		t.Parallel()
+
+		transCtx := makeScriptTestContext(t, "forbidden content")
+		oldPath := transCtx.Transfer.LocalPath
+		args := map[string]string{"script": "check", "params": `{"user": "bob", "maxLines": 1}`}
+
+		err := (&scriptTask{}).Run(t.Context(), args, db, logger, transCtx, nil)
+
+		var taskErr *Error
+		require.ErrorAs(t, err, &taskErr)
+		assert.Equal(t, types.TeForbidden, taskErr.Code)
+		assert.Equal(t, "the file contains forbidden content", taskErr.Details)
+		assert.Equal(t, oldPath, transCtx.Transfer.LocalPath,
+			"Then the transfer should not have been changed")
+	})
+
+	t.Run("Given a script file with a runtime error", func(t *testing.T) {
+		t.Parallel()
+
+		transCtx := makeScriptTestContext(t, "")
+		script := filepath.Join(t.TempDir(), "script.star")
+		require.NoError(t, fs.WriteFullFile(script, []byte(
+			"transfer.info[\"foo\"] = \"bar\"\n"+
+				"x = {}\n"+
+				"print(x[\"missing\"])\n")))
+
+		err := (&scriptTask{}).Run(t.Context(), map[string]string{"file": script},
+			db, logger, transCtx, nil)
+		require.ErrorIs(t, err, ErrScriptFailed)
+		assert.ErrorContains(t, err, "at line 3")
+		assert.NotContains(t, transCtx.Transfer.TransferInfo, "foo",
+			"Then the transfer info should not have been changed")
+	})
+
+	t.Run("Given a read-only attribute modification", func(t *testing.T) {
+		t.Parallel()
+
+		transCtx := makeScriptTestContext(t, "")
+		script := filepath.Join(t.TempDir(), "script.star")
+		require.NoError(t, fs.WriteFullFile(script, []byte(`transfer.rule = "other"`)))
+
+		err := (&scriptTask{}).Run(t.Context(), map[string]string{"file": script},
+			db, logger, transCtx, nil)
+		require.ErrorIs(t, err, ErrScriptFailed)
+		assert.ErrorContains(t, err, "read-only")
+	})
+
+	t.Run("Given an infinite loop", func(t *testing.T) {
+		t.Parallel()
+
+		transCtx := makeScriptTestContext(t, "")
+		script := filepath.Join(t.TempDir(), "script.star")
+		require.NoError(t, fs.WriteFullFile(script, []byte("while True:\n    pass\n")))
+
+		err := (&scriptTask{}).Run(t.Context(), map[string]string{
+			"file": script, "maxSteps": "10000",
+		}, db, logger, transCtx, nil)
+		require.ErrorIs(t, err, ErrScriptFailed)
+		assert.ErrorContains(t, err, "too many steps")
+	})
+}
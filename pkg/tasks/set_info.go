@@ -39,30 +39,36 @@ func (t *setInfoTask) Run(_ context.Context, args map[string]string, _ *database
 		return err
 	}
 
+	setTransferInfo(logger, transCtx, "SETINFO", t.Key, t.Value)
+
+	return nil
+}
+
+// setTransferInfo sets the given transfer info key to the given value. An empty
+// value deletes the key.
+func setTransferInfo(logger *log.Logger, transCtx *model.TransferContext, task, key, value string) {
 	if transCtx.Transfer.TransferInfo == nil {
 		transCtx.Transfer.TransferInfo = map[string]any{}
 	}
 
-	old, existed := transCtx.Transfer.TransferInfo[t.Key]
+	old, existed := transCtx.Transfer.TransferInfo[key]
 
-	if t.Value == "" {
+	if value == "" {
 		// Empty value = delete the key.
-		delete(transCtx.Transfer.TransferInfo, t.Key)
+		delete(transCtx.Transfer.TransferInfo, key)
 
 		if existed {
-			logger.Debugf("SETINFO: deleted key %q (was %v)", t.Key, old)
+			logger.Debugf("%s: deleted key %q (was %v)", task, key, old)
 		}
 
-		return nil
+		return
 	}
 
-	transCtx.Transfer.TransferInfo[t.Key] = t.Value
+	transCtx.Transfer.TransferInfo[key] = value
 
 	if existed {
-		logger.Debugf("SETINFO: updated key %q: %v -> %v", t.Key, old, t.Value)
+		logger.Debugf("%s: updated key %q: %v -> %v", task, key, old, value)
 	} else {
-		logger.Debugf("SETINFO: added key %q = %v", t.Key, t.Value)
+		logger.Debugf("%s: added key %q = %v", task, key, value)
 	}
-
-	return nil
 }
@@ -85,6 +85,10 @@ func (s *sign) ValidateDB(db database.ReadAccess, params map[string]string) erro
 		return fmt.Errorf("failed to retrieve signature key from database: %w", err)
 	}
 
+	if err := cryptoKey.ResolveSecrets(); err != nil {
+		return fmt.Errorf("failed to resolve signature key: %w", err)
+	}
+
 	method, ok := SignMethods.Get(s.Method)
 	if !ok {
 		return fmt.Errorf("%w %q", ErrSignUnknownMethod, s.Method)
@@ -0,0 +1,364 @@
+package tasks
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"path"
+	"strings"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/fs"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils"
+)
+
+const (
+	// SplitPartNumber is the marker replaced with the part number in the
+	// SPLIT task's output template.
+	SplitPartNumber = "#PARTNUMBER#"
+
+	splitDefaultSeparator = "\n"
+
+	// SplitInfoPart and SplitInfoCount are the names of the transfer info set
+	// by the SPLIT task on the transfers of the parts.
+	SplitInfoPart  = "splitPart"
+	SplitInfoCount = "splitCount"
+)
+
+var (
+	ErrSplitNoMode       = errors.New(`one of "size" or "lines" must be given`)
+	ErrSplitManyModes    = errors.New(`"size" and "lines" cannot be given together`)
+	ErrSplitInvalidSize  = errors.New("the part size must be strictly positive")
+	ErrSplitNoPartNumber = errors.New(`the output template must contain "` + SplitPartNumber + `"`)
+	ErrSplitFromPartner  = errors.New(`the parts can only be sent, the "from" argument is not allowed`)
+)
+
+// splitTransferArgs lists the SPLIT arguments which are passed on to the
+// TRANSFER task when sending the parts.
+//
+//nolint:gochecknoglobals //global var is needed here for the list of arguments
+var splitTransferArgs = []string{
+	"to", "as", "using", "rule", "info", "copyInfo",
+	"nbOfAttempts", "firstRetryDelay", "retryIncrementFactor",
+}
+
+// splitTask is a task which splits the transfer file into several parts,
+// either by size or by number of lines (or records). The task can optionally
+// write a manifest listing the parts with their checksums, and schedule one
+// new transfer per part (and one for the manifest).
+type splitTask struct {
+	Size      jsonInt `json:"size"`
+	Lines     jsonInt `json:"lines"`
+	Separator string  `json:"separator"`
+	Output    string  `json:"output"`
+	Manifest  string  `json:"manifest"`
+	Algorithm string  `json:"algorithm"`
+	To        string  `json:"to"`
+	From      string  `json:"from"`
+
+	algo     checksumAlgo
+	transfer map[string]string
+}
+
+// splitPart is a file produced by the SPLIT task.
+type splitPart struct {
+	path, sum string
+}
+
+func (s *splitTask) parseArgs(args map[string]string) error {
+	*s = splitTask{}
+
+	if err := utils.JSONConvert(args, s); err != nil {
+		return fmt.Errorf("failed to parse the split arguments: %w", err)
+	}
+
+	switch {
+	case s.Size == 0 && s.Lines == 0:
+		return ErrSplitNoMode
+	case s.Size != 0 && s.Lines != 0:
+		return ErrSplitManyModes
+	case s.Size < 0 || s.Lines < 0:
+		return ErrSplitInvalidSize
+	}
+
+	if s.Separator == "" {
+		s.Separator = splitDefaultSeparator
+	}
+
+	if s.Output != "" && !strings.Contains(s.Output, SplitPartNumber) {
+		return ErrSplitNoPartNumber
+	}
+
+	if s.From != "" {
+		return ErrSplitFromPartner
+	}
+
+	var err error
+	if s.algo, err = getChecksumAlgo(s.Algorithm); err != nil {
+		return err
+	}
+
+	if s.To != "" {
+		s.transfer = map[string]string{}
+
+		for _, key := range splitTransferArgs {
+			if val, ok := args[key]; ok {
+				s.transfer[key] = val
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *splitTask) ValidateDB(db database.ReadAccess, args map[string]string) error {
+	if err := s.parseArgs(args); err != nil {
+		return err
+	}
+
+	if s.transfer == nil {
+		return nil
+	}
+
+	transArgs := map[string]string{"file": SplitPartNumber}
+	maps.Copy(transArgs, s.transfer)
+
+	return (&TransferTask{}).ValidateDB(db, transArgs)
+}
+
+func (s *splitTask) Run(ctx context.Context, args map[string]string, db *database.DB,
+	logger *log.Logger, transCtx *model.TransferContext, _ any,
+) error {
+	if err := s.parseArgs(args); err != nil {
+		logger.Error(err.Error())
+
+		return err
+	}
+
+	parts, err := s.split(ctx, transCtx.Transfer.LocalPath)
+	if err != nil {
+		logger.Errorf("Failed to split the file: %v", err)
+
+		return err
+	}
+
+	logger.Debugf("File split into %d parts", len(parts))
+	setTransferInfo(logger, transCtx, Split, SplitInfoCount, utils.FormatInt(len(parts)))
+
+	if s.Manifest != "" {
+		if err := s.writeManifest(parts); err != nil {
+			logger.Errorf("Failed to write the split manifest: %v", err)
+
+			return err
+		}
+	}
+
+	if s.transfer == nil {
+		return nil
+	}
+
+	return s.sendParts(db, logger, transCtx, parts)
+}
+
+// partPath returns the path of the part with the given number. By default, the
+// part is named after the original file, with the part number as extension. If
+// the output template is a bare file name, the part is placed next to the
+// original file.
+func (s *splitTask) partPath(filePath string, number int) string {
+	template := s.Output
+	if template == "" {
+		template = path.Base(filePath) + "." + SplitPartNumber
+	}
+
+	name := strings.ReplaceAll(template, SplitPartNumber, fmt.Sprintf("%03d", number))
+	if !strings.Contains(name, "/") {
+		name = path.Join(path.Dir(filePath), name)
+	}
+
+	return name
+}
+
+func (s *splitTask) split(ctx context.Context, filePath string) ([]splitPart, error) {
+	file, err := fs.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	defer file.Close() //nolint:errcheck //error is irrelevant here
+
+	reader := bufio.NewReader(file)
+
+	var parts []splitPart
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("split interrupted: %w", err)
+		}
+
+		part, eof, partErr := s.writePart(reader, filePath, len(parts)+1)
+		if partErr != nil {
+			return nil, partErr
+		}
+
+		if part != nil {
+			parts = append(parts, *part)
+		}
+
+		if eof {
+			return parts, nil
+		}
+	}
+}
+
+// writePart copies the next part of the given reader to the part file with the
+// given number. It returns whether the end of the reader was reached. If the
+// part would be empty (and is not the first one), no file is written, and nil
+// is returned.
+func (s *splitTask) writePart(reader *bufio.Reader, filePath string, number int,
+) (*splitPart, bool, error) {
+	if _, err := reader.Peek(1); errors.Is(err, io.EOF) && number > 1 {
+		return nil, true, nil
+	}
+
+	partPath := s.partPath(filePath, number)
+
+	out, err := fs.Create(partPath)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create part %q: %w", partPath, err)
+	}
+
+	defer out.Close() //nolint:errcheck //error is checked below
+
+	hasher := s.algo.new()
+	writer := io.MultiWriter(out, hasher)
+
+	var eof bool
+
+	if s.Size > 0 {
+		_, err = io.CopyN(writer, reader, int64(s.Size))
+	} else {
+		err = copyRecords(writer, reader, []byte(s.Separator), int64(s.Lines))
+	}
+
+	if errors.Is(err, io.EOF) {
+		eof, err = true, nil
+	} else if err == nil {
+		_, peekErr := reader.Peek(1)
+		eof = errors.Is(peekErr, io.EOF)
+	}
+
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to write part %q: %w", partPath, err)
+	}
+
+	if err := out.Close(); err != nil {
+		return nil, false, fmt.Errorf("failed to close part %q: %w", partPath, err)
+	}
+
+	return &splitPart{path: partPath, sum: hex.EncodeToString(hasher.Sum(nil))}, eof, nil
+}
+
+// copyRecords copies the given number of records (ending with the given
+// separator) from the reader to the writer. It returns io.EOF if the reader
+// ends before.
+func copyRecords(writer io.Writer, reader *bufio.Reader, sep []byte, count int64) error {
+	last := sep[len(sep)-1]
+
+	for range count {
+		var record []byte
+
+		for {
+			chunk, err := reader.ReadBytes(last)
+			record = append(record, chunk...)
+
+			if errors.Is(err, io.EOF) {
+				if _, wErr := writer.Write(record); wErr != nil {
+					return wErr //nolint:wrapcheck //wrapped by the caller
+				}
+
+				return io.EOF
+			} else if err != nil {
+				return err //nolint:wrapcheck //wrapped by the caller
+			}
+
+			if bytes.HasSuffix(record, sep) {
+				break
+			}
+		}
+
+		if _, err := writer.Write(record); err != nil {
+			return err //nolint:wrapcheck //wrapped by the caller
+		}
+	}
+
+	return nil
+}
+
+func (s *splitTask) writeManifest(parts []splitPart) error {
+	var content strings.Builder
+
+	for _, part := range parts {
+		content.WriteString(formatSidecar(part.sum, part.path))
+	}
+
+	if err := fs.WriteFullFile(s.Manifest, []byte(content.String())); err != nil {
+		return fmt.Errorf("failed to write the manifest: %w", err)
+	}
+
+	return nil
+}
+
+// sendParts schedules a new transfer for each part (and for the manifest, if
+// there is one), in the same way the TRANSFER task does.
+func (s *splitTask) sendParts(db *database.DB, logger *log.Logger,
+	transCtx *model.TransferContext, parts []splitPart,
+) error {
+	files := make([]string, 0, len(parts)+1)
+	for _, part := range parts {
+		files = append(files, part.path)
+	}
+
+	if s.Manifest != "" {
+		files = append(files, s.Manifest)
+	}
+
+	for i, file := range files {
+		transArgs := map[string]string{"file": file}
+		maps.Copy(transArgs, s.transfer)
+
+		transTask := &TransferTask{}
+		if err := transTask.parseArgs(db, transArgs); err != nil {
+			logger.Error(err.Error())
+
+			return err
+		}
+
+		if transTask.Info == nil {
+			transTask.Info = jsonObject{}
+		}
+
+		if i < len(parts) {
+			transTask.Info[SplitInfoPart] = i + 1
+		}
+
+		transTask.Info[SplitInfoCount] = len(parts)
+
+		trans, err := transTask.makeTransfer(db, transCtx)
+		if err != nil {
+			logger.Errorf("Failed to create transfer: %v", err)
+
+			return err
+		}
+
+		logger.Debugf("Programmed new transfer n°%d of file %q", trans.ID, file)
+	}
+
+	return nil
+}
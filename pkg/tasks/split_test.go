@@ -0,0 +1,185 @@
+package tasks
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database/dbtest"
+	"code.waarp.fr/apps/gateway/gateway/pkg/fs"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/types"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils/testhelpers"
+)
+
+const splitTestContent = "line 1\nline 2\nline 3\nline 4\nline 5\n"
+
+func makeSplitTestFile(t *testing.T) *model.TransferContext {
+	t.Helper()
+
+	filePath := filepath.Join(t.TempDir(), "file.txt")
+	require.NoError(t, fs.WriteFullFile(filePath, []byte(splitTestContent)))
+
+	return &model.TransferContext{Transfer: &model.Transfer{LocalPath: filePath}}
+}
+
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+
+	return hex.EncodeToString(sum[:])
+}
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+
+	content, err := fs.ReadFullFile(path)
+	require.NoError(t, err)
+
+	return string(content)
+}
+
+func TestSplitRun(t *testing.T) {
+	t.Parallel()
+
+	logger := testhelpers.GetTestLogger(t)
+
+	t.Run("Given a split by size", func(t *testing.T) {
+		t.Parallel()
+
+		transCtx := makeSplitTestFile(t)
+		dir := filepath.Dir(transCtx.Transfer.LocalPath)
+
+		require.NoError(t, (&splitTask{}).Run(t.Context(), map[string]string{"size": "16"},
+			nil, logger, transCtx, nil))
+
+		assert.Equal(t, "line 1\nline 2\nli", readTestFile(t, filepath.Join(dir, "file.txt.001")))
+		assert.Equal(t, "ne 3\nline 4\nline", readTestFile(t, filepath.Join(dir, "file.txt.002")))
+		assert.Equal(t, " 5\n", readTestFile(t, filepath.Join(dir, "file.txt.003")))
+		assert.NoFileExists(t, filepath.Join(dir, "file.txt.004"))
+		assert.Equal(t, "3", transCtx.Transfer.TransferInfo[SplitInfoCount],
+			"Then the number of parts should have been stored in the transfer info")
+	})
+
+	t.Run("Given a split by lines with a manifest", func(t *testing.T) {
+		t.Parallel()
+
+		transCtx := makeSplitTestFile(t)
+		dir := filepath.Dir(transCtx.Transfer.LocalPath)
+		manifest := filepath.Join(dir, "file.sha256")
+		args := map[string]string{
+			"lines":    "2",
+			"output":   "part_" + SplitPartNumber + ".txt",
+			"manifest": manifest,
+		}
+
+		require.NoError(t, (&splitTask{}).Run(t.Context(), args, nil, logger, transCtx, nil))
+
+		assert.Equal(t, "line 1\nline 2\n", readTestFile(t, filepath.Join(dir, "part_001.txt")))
+		assert.Equal(t, "line 3\nline 4\n", readTestFile(t, filepath.Join(dir, "part_002.txt")))
+		assert.Equal(t, "line 5\n", readTestFile(t, filepath.Join(dir, "part_003.txt")))
+		assert.NoFileExists(t, filepath.Join(dir, "part_004.txt"))
+
+		assert.Equal(t,
+			formatSidecar(sha256Hex("line 1\nline 2\n"), "part_001.txt")+
+				formatSidecar(sha256Hex("line 3\nline 4\n"), "part_002.txt")+
+				formatSidecar(sha256Hex("line 5\n"), "part_003.txt"),
+			readTestFile(t, manifest), "Then the manifest should list the parts")
+	})
+
+	t.Run("Given a custom record separator", func(t *testing.T) {
+		t.Parallel()
+
+		transCtx := makeSplitTestFile(t)
+		dir := filepath.Dir(transCtx.Transfer.LocalPath)
+		args := map[string]string{"lines": "1", "separator": "2\nline 3"}
+
+		require.NoError(t, (&splitTask{}).Run(t.Context(), args, nil, logger, transCtx, nil))
+
+		assert.Equal(t, "line 1\nline 2\nline 3", readTestFile(t, filepath.Join(dir, "file.txt.001")))
+		assert.Equal(t, "\nline 4\nline 5\n", readTestFile(t, filepath.Join(dir, "file.txt.002")))
+	})
+
+	t.Run("Given invalid arguments", func(t *testing.T) {
+		t.Parallel()
+
+		for _, test := range []struct {
+			args     map[string]string
+			expected error
+		}{
+			{map[string]string{}, ErrSplitNoMode},
+			{map[string]string{"size": "10", "lines": "1"}, ErrSplitManyModes},
+			{map[string]string{"size": "-1"}, ErrSplitInvalidSize},
+			{map[string]string{"size": "10", "output": "part.txt"}, ErrSplitNoPartNumber},
+			{map[string]string{"size": "10", "from": "partner"}, ErrSplitFromPartner},
+			{map[string]string{"size": "10", "algorithm": "unknown"}, ErrChecksumUnknownAlgo},
+		} {
+			require.ErrorIs(t, (&splitTask{}).ValidateDB(nil, test.args), test.expected)
+		}
+	})
+
+	t.Run("Given a partner to send the parts to", func(t *testing.T) {
+		t.Parallel()
+
+		db := dbtest.TestDatabase(t)
+
+		rule := &model.Rule{Name: "push", IsSend: true, Path: "push"}
+		require.NoError(t, db.Insert(rule).Run())
+
+		client := &model.Client{Name: "cli", Protocol: testProtocol}
+		require.NoError(t, db.Insert(client).Run())
+
+		partner := &model.RemoteAgent{
+			Name: "partner", Protocol: testProtocol,
+			Address: types.Addr("localhost", 1111),
+		}
+		require.NoError(t, db.Insert(partner).Run())
+
+		account := &model.RemoteAccount{RemoteAgentID: partner.ID, Login: "toto"}
+		require.NoError(t, db.Insert(account).Run())
+
+		transCtx := makeSplitTestFile(t)
+		dir := filepath.Dir(transCtx.Transfer.LocalPath)
+		args := map[string]string{
+			"lines":    "3",
+			"manifest": filepath.Join(dir, "file.sha256"),
+			"to":       partner.Name,
+			"as":       account.Login,
+			"using":    client.Name,
+			"rule":     rule.Name,
+			"info":     `{"foo":"bar"}`,
+		}
+
+		require.NoError(t, (&splitTask{}).ValidateDB(db, args))
+		require.NoError(t, (&splitTask{}).Run(t.Context(), args, db, logger, transCtx, nil))
+
+		var transfers model.Transfers
+		require.NoError(t, db.Select(&transfers).Run())
+		require.Len(t, transfers, 3,
+			"Then a transfer should have been created for each part and the manifest")
+
+		nbParts := json.Number("2")
+
+		for _, expected := range []struct {
+			file string
+			info map[string]any
+		}{
+			{"file.txt.001", map[string]any{"foo": "bar", SplitInfoPart: json.Number("1"), SplitInfoCount: nbParts}},
+			{"file.txt.002", map[string]any{"foo": "bar", SplitInfoPart: json.Number("2"), SplitInfoCount: nbParts}},
+			{"file.sha256", map[string]any{"foo": "bar", SplitInfoCount: nbParts}},
+		} {
+			var transfer model.Transfer
+			require.NoError(t, db.Get(&transfer, "src_filename=?",
+				filepath.Join(dir, expected.file)).Eager().Run())
+
+			assert.Equal(t, rule.ID, transfer.RuleID)
+			assert.Equal(t, account.ID, transfer.RemoteAccountID.Int64)
+			delete(transfer.TransferInfo, model.FollowID)
+			assert.Equal(t, expected.info, transfer.TransferInfo)
+		}
+	})
+}
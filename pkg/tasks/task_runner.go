@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
+	"reflect"
 	"sync"
 	"time"
 
@@ -13,6 +15,7 @@ import (
 	"code.waarp.fr/apps/gateway/gateway/pkg/fs"
 	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
 	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/condition"
 	"code.waarp.fr/apps/gateway/gateway/pkg/model/types"
 )
 
@@ -23,6 +26,9 @@ type Runner struct {
 	transCtx *model.TransferContext
 	Remote   any
 
+	previous      string // the outcome of the previous task of the chain
+	skipRemaining bool   // whether the rest of the chain should be skipped
+
 	Ctx  context.Context
 	Stop context.CancelFunc
 	lock sync.WaitGroup
@@ -70,49 +76,389 @@ func (r *Runner) ErrorTasks(trace func(rank int)) *Error {
 func (r *Runner) runTask(updTicker *time.Ticker, task *model.Task, taskInfo string,
 	isErrTasks bool,
 ) *Error {
+	prep, err := r.prepareTask(task, taskInfo)
+	if err != nil {
+		return err
+	}
+
+	if prep.runner == nil {
+		return r.notExecuted(updTicker, task, taskInfo, isErrTasks, prep)
+	}
+
+	attempts, runErr := r.execTask(r.transCtx, prep.runner, prep.args, task, taskInfo, isErrTasks)
+	r.saveAttempts(attempts)
+
+	return r.finishTask(updTicker, task, taskInfo, isErrTasks, runErr)
+}
+
+// preparedTask is a task ready to be executed. If the task should not be
+// executed (because its condition is not met, or because its preparation
+// failed), the runner is nil.
+type preparedTask struct {
+	runner  model.TaskRunner
+	args    map[string]string
+	failure *Error // the preparation error, if the preparation failed
+}
+
+// prepareTask checks the task's condition, and contextualizes its arguments.
+// It has no effect on the runner's state, the outcome of a task which should
+// not be executed is only handled later on by notExecuted. An error is only
+// returned if the task cannot be run at all.
+func (r *Runner) prepareTask(task *model.Task, taskInfo string) (*preparedTask, *Error) {
 	runner := model.GetTaskRunner(task.Type)
 	if runner == nil {
-		return newError(types.TeExternalOperation, "unknown task type: %s", task.Type)
+		return nil, newError(types.TeExternalOperation, "unknown task type: %s", task.Type)
+	}
+
+	execute, condErr := r.checkCondition(task)
+	if condErr != nil {
+		r.Logger.Errorf("%s: %v", taskInfo, condErr)
+
+		return &preparedTask{failure: newErrorWith(types.TeExternalOperation,
+			fmt.Sprintf("%s: invalid condition", taskInfo), condErr)}, nil
+	}
+
+	if !execute {
+		r.Logger.Debugf("%s: condition %q not met, skipping the task", taskInfo, task.Condition)
+
+		return &preparedTask{}, nil
 	}
 
 	args, setupErr := r.setup(task)
 	if setupErr != nil {
 		r.Logger.Errorf("%s: setup failed: %v", taskInfo, setupErr)
 
-		return newErrorWith(types.TeExternalOperation,
-			fmt.Sprintf("%s: setup failed", taskInfo), setupErr)
+		return &preparedTask{failure: newErrorWith(types.TeExternalOperation,
+			fmt.Sprintf("%s: setup failed", taskInfo), setupErr)}, nil
 	}
 
-	r.Logger.Debugf("Executing task %s with args %s", taskInfo, mapToStr(args))
-
-	var runErr error
+	return &preparedTask{runner: runner, args: args}, nil
+}
 
-	if isErrTasks {
-		runErr = runner.Run(context.Background(), args, r.db, r.Logger, r.transCtx, r.Remote)
-	} else {
-		runErr = runner.Run(r.Ctx, args, r.db, r.Logger, r.transCtx, r.Remote)
+// notExecuted handles the outcome of a task which was not executed, either
+// because its preparation failed, or because its condition was not met.
+func (r *Runner) notExecuted(updTicker *time.Ticker, task *model.Task, taskInfo string,
+	isErrTasks bool, prep *preparedTask,
+) *Error {
+	if prep.failure != nil {
+		return r.taskFailed(updTicker, task, taskInfo, isErrTasks, prep.failure)
 	}
 
+	r.previous = condition.OutcomeSkipped
+
+	return r.updateProgress(updTicker, isErrTasks)
+}
+
+// finishTask handles the result of the task's execution.
+func (r *Runner) finishTask(updTicker *time.Ticker, task *model.Task, taskInfo string,
+	isErrTasks bool, runErr error,
+) *Error {
 	if runErr != nil {
 		var warningError *WarningError
 		if !errors.As(runErr, &warningError) {
+			// Tasks can return a specific error code (like TeIntegrity),
+			// otherwise the error is considered an external operation error.
+			code := types.TeExternalOperation
+
+			var taskErr *Error
+			if errors.As(runErr, &taskErr) {
+				code = taskErr.Code
+			}
+
 			r.Logger.Errorf("%s: %v", taskInfo, runErr)
-			r.transCtx.Transfer.ErrCode = types.TeExternalOperation
+			r.transCtx.Transfer.ErrCode = code
 			r.transCtx.Transfer.ErrDetails = fmt.Sprintf("%s: %v", taskInfo, runErr)
 
-			return newErrorWith(types.TeExternalOperation, taskInfo, runErr)
+			return r.taskFailed(updTicker, task, taskInfo, isErrTasks,
+				newErrorWith(code, taskInfo, runErr))
 		}
 
 		r.Logger.Warningf("%s: %v", taskInfo, runErr)
 		r.transCtx.Transfer.ErrCode = types.TeWarning
 		r.transCtx.Transfer.ErrDetails = fmt.Sprintf("%s: %v", taskInfo, runErr)
+		r.previous = condition.OutcomeWarning
 	} else {
 		r.Logger.Debug(taskInfo)
+		r.previous = condition.OutcomeSuccess
 	}
 
 	return r.updateProgress(updTicker, isErrTasks)
 }
 
+// runTaskGroup executes the given tasks concurrently. The tasks' conditions
+// are all evaluated beforehand, and the results of the tasks (including the
+// skipped ones) are handled in order once all of them are done, stopping at the
+// first error. Each task works on its own copy of the transfer context, and the
+// changes made to these copies are merged back (in the tasks' order) once the
+// whole group is done.
+func (r *Runner) runTaskGroup(updTicker *time.Ticker, group []*model.Task, infos []string,
+	isErrTasks bool,
+) *Error {
+	type job struct {
+		*preparedTask
+
+		transCtx *model.TransferContext
+		attempts []any
+		err      error
+	}
+
+	jobs := make([]*job, len(group))
+
+	for i, task := range group {
+		prep, err := r.prepareTask(task, infos[i])
+		if err != nil {
+			return err
+		}
+
+		jobs[i] = &job{preparedTask: prep}
+		if prep.runner != nil {
+			jobs[i].transCtx = r.isolatedContext()
+		}
+	}
+
+	r.Logger.Debugf("Executing %d tasks of parallel group %q", len(group), group[0].ParallelGroup)
+
+	var wg sync.WaitGroup
+
+	for i, j := range jobs {
+		if j.runner == nil {
+			continue
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			j.attempts, j.err = r.execTask(j.transCtx, j.runner, j.args, group[i], infos[i],
+				isErrTasks)
+		}()
+	}
+
+	wg.Wait()
+
+	base := r.isolatedContext().Transfer
+
+	for i, j := range jobs {
+		if j.runner == nil {
+			if err := r.notExecuted(updTicker, group[i], infos[i], isErrTasks,
+				j.preparedTask); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		r.mergeTransfer(base, j.transCtx.Transfer)
+		r.saveAttempts(j.attempts)
+
+		if err := r.finishTask(updTicker, group[i], infos[i], isErrTasks, j.err); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isolatedContext returns a copy of the runner's transfer context which can be
+// modified by a task without affecting the runner's context.
+func (r *Runner) isolatedContext() *model.TransferContext {
+	transCtx := *r.transCtx
+	trans := *r.transCtx.Transfer
+	trans.TransferInfo = maps.Clone(trans.TransferInfo)
+	transCtx.Transfer = &trans
+
+	return &transCtx
+}
+
+// mergeTransfer applies to the runner's transfer the changes made to the given
+// copy of the transfer by a task of a parallel group. The base is the state of
+// the transfer before the group was executed.
+func (r *Runner) mergeTransfer(base, changed *model.Transfer) {
+	trans := r.transCtx.Transfer
+
+	if changed.LocalPath != base.LocalPath {
+		trans.LocalPath = changed.LocalPath
+	}
+
+	if changed.RemotePath != base.RemotePath {
+		trans.RemotePath = changed.RemotePath
+	}
+
+	if changed.Filesize != base.Filesize {
+		trans.Filesize = changed.Filesize
+	}
+
+	for key, val := range changed.TransferInfo {
+		if old, ok := base.TransferInfo[key]; ok && reflect.DeepEqual(old, val) {
+			continue
+		}
+
+		if trans.TransferInfo == nil {
+			trans.TransferInfo = map[string]any{}
+		}
+
+		trans.TransferInfo[key] = val
+	}
+
+	for key := range base.TransferInfo {
+		if _, ok := changed.TransferInfo[key]; !ok {
+			delete(trans.TransferInfo, key)
+		}
+	}
+}
+
+// execTask runs the given task, retrying it on failure if the task allows it.
+// If the task has specific execution parameters, the results of the attempts
+// are returned, so that they can be recorded in the transfer info.
+func (r *Runner) execTask(transCtx *model.TransferContext, runner model.TaskRunner,
+	args map[string]string, task *model.Task, taskInfo string, isErrTasks bool,
+) ([]any, error) {
+	ctx := r.Ctx
+	if isErrTasks {
+		ctx = context.Background()
+	}
+
+	r.Logger.Debugf("Executing task %s with args %s", taskInfo, mapToStr(args))
+
+	var attempts []any
+
+	delay := task.RetryDelay
+
+	for attempt := 1; ; attempt++ {
+		start := time.Now()
+		err := r.attemptTask(ctx, transCtx, runner, args, task)
+
+		if task.HasExecutionOptions() {
+			attempts = append(attempts, makeAttemptRecord(task, attempt, start, err))
+		}
+
+		var warningError *WarningError
+		if err == nil || errors.As(err, &warningError) || attempt > int(task.Retries) {
+			return attempts, err
+		}
+
+		r.Logger.Warningf("%s: attempt %d/%d failed: %v (retrying in %s)", taskInfo,
+			attempt, int(task.Retries)+1, err, delay)
+
+		select {
+		case <-ctx.Done():
+			return attempts, err
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+	}
+}
+
+// attemptTask runs the given task once, within the limit of the task's timeout
+// (if it has one). When the timeout expires, the task's context is cancelled,
+// and the attempt fails once the task has returned. The task is never left
+// running in the background, since it could otherwise keep modifying the
+// transfer context after the attempt is over.
+func (r *Runner) attemptTask(ctx context.Context, transCtx *model.TransferContext,
+	runner model.TaskRunner, args map[string]string, task *model.Task,
+) error {
+	if task.Timeout <= 0 {
+		return runner.Run(ctx, args, r.db, r.Logger, transCtx, r.Remote)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, task.Timeout)
+	defer cancel()
+
+	err := runner.Run(ctx, args, r.db, r.Logger, transCtx, r.Remote)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w after %s: %w", ErrTaskTimeout, task.Timeout, err)
+	}
+
+	return err
+}
+
+// makeAttemptRecord returns the transfer info record of the given task attempt.
+func makeAttemptRecord(task *model.Task, attempt int, start time.Time, err error) map[string]any {
+	record := map[string]any{
+		"chain":    string(task.Chain),
+		"rank":     task.Rank,
+		"type":     task.Type,
+		"attempt":  attempt,
+		"start":    start.UTC().Format(time.RFC3339Nano),
+		"duration": time.Since(start).String(),
+		"result":   condition.OutcomeSuccess,
+	}
+
+	if err != nil {
+		record["result"] = condition.OutcomeError
+		record["error"] = err.Error()
+
+		var warningError *WarningError
+		if errors.As(err, &warningError) {
+			record["result"] = condition.OutcomeWarning
+		}
+	}
+
+	return record
+}
+
+// saveAttempts adds the given task attempts records to the transfer's info.
+func (r *Runner) saveAttempts(records []any) {
+	if len(records) == 0 {
+		return
+	}
+
+	trans := r.transCtx.Transfer
+	if trans.TransferInfo == nil {
+		trans.TransferInfo = map[string]any{}
+	}
+
+	attempts, _ := trans.TransferInfo[model.TaskAttempts].([]any)
+	trans.TransferInfo[model.TaskAttempts] = append(attempts, records...)
+}
+
+// taskFailed handles the failure of the given task according to its error
+// behavior. If the task was set to abort the chain on error (the default), the
+// given error is returned. Otherwise, the error is downgraded to a warning, and
+// the chain resumes (or stops, if the remaining tasks must be skipped).
+func (r *Runner) taskFailed(updTicker *time.Ticker, task *model.Task, taskInfo string,
+	isErrTasks bool, taskErr *Error,
+) *Error {
+	r.previous = condition.OutcomeError
+
+	switch task.OnError {
+	case model.OnErrorContinue:
+		r.Logger.Warningf("%s: error ignored, continuing with the next task", taskInfo)
+	case model.OnErrorSkip:
+		r.Logger.Warningf("%s: error ignored, skipping the remaining tasks", taskInfo)
+		r.skipRemaining = true
+	default:
+		return taskErr
+	}
+
+	r.transCtx.Transfer.ErrCode = types.TeWarning
+	r.transCtx.Transfer.ErrDetails = taskErr.Error()
+
+	return r.updateProgress(updTicker, isErrTasks)
+}
+
+// checkCondition returns whether the given task's condition is met by the
+// transfer.
+func (r *Runner) checkCondition(task *model.Task) (bool, error) {
+	if task.Condition == "" {
+		return true, nil
+	}
+
+	cond, err := condition.Parse(task.Condition)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse the task condition: %w", err)
+	}
+
+	ok, err := cond.Eval(&conditionEnv{transCtx: r.transCtx, previous: r.previous})
+	if err != nil {
+		return false, fmt.Errorf("failed to evaluate the task condition: %w", err)
+	}
+
+	return ok, nil
+}
+
 func (r *Runner) updateProgress(updTicker *time.Ticker, isErrTasks bool) *Error {
 	r.transCtx.Transfer.TaskNumber++
 
@@ -146,10 +492,17 @@ func (r *Runner) runTasks(tasks []*model.Task, isErrTasks bool, trace func(rank
 	r.lock.Add(1)
 	defer r.lock.Done()
 
-	for i := int(r.transCtx.Transfer.TaskNumber); i < len(tasks); i++ {
-		task := tasks[i]
-		taskInfo := fmt.Sprintf("Task %s @ %s %s[%v]", task.Type, r.transCtx.Rule.Name,
-			task.Chain, task.Rank)
+	r.previous = condition.OutcomeNone
+	r.skipRemaining = false
+
+	for i := int(r.transCtx.Transfer.TaskNumber); i < len(tasks); {
+		group := taskGroup(tasks, i)
+		infos := make([]string, len(group))
+
+		for j, task := range group {
+			infos[j] = fmt.Sprintf("Task %s @ %s %s[%v]", task.Type, r.transCtx.Rule.Name,
+				task.Chain, task.Rank)
+		}
 
 		if !isErrTasks {
 			select {
@@ -160,22 +513,70 @@ func (r *Runner) runTasks(tasks []*model.Task, isErrTasks bool, trace func(rank
 		}
 
 		updTicker := time.NewTicker(time.Second)
-		if err := r.runTask(updTicker, task, taskInfo, isErrTasks); err != nil {
+
+		var err *Error
+		if len(group) == 1 {
+			err = r.runTask(updTicker, group[0], infos[0], isErrTasks)
+		} else {
+			err = r.runTaskGroup(updTicker, group, infos, isErrTasks)
+		}
+
+		updTicker.Stop()
+
+		if err != nil {
 			return err
 		}
 
-		if trace == nil {
-			continue
+		for range group {
+			if trace != nil {
+				if err := trace(i); err != nil {
+					return newErrorWith(types.TeInternal, "task trace error", err)
+				}
+			}
+
+			i++
 		}
 
-		if err := trace(i); err != nil {
-			return newErrorWith(types.TeInternal, "task trace error", err)
+		if r.skipRemaining {
+			return r.skipTasks(tasks, isErrTasks)
 		}
 	}
 
 	return nil
 }
 
+// skipTasks marks all the given tasks as done, so that the skipped tasks are
+// not executed if the transfer is resumed later on.
+func (r *Runner) skipTasks(tasks []*model.Task, isErrTasks bool) *Error {
+	r.transCtx.Transfer.TaskNumber = int8(len(tasks))
+
+	if dbErr := r.db.Update(r.transCtx.Transfer).Cols("task_number", "error_code",
+		"error_details").Run(); dbErr != nil {
+		r.Logger.Errorf("Failed to update transfer after skipping tasks: %v", dbErr)
+
+		if !isErrTasks {
+			return newErrorWith(types.TeInternal, "failed to update transfer", dbErr)
+		}
+	}
+
+	return nil
+}
+
+// taskGroup returns the tasks starting at the given index which belong to the
+// same parallel group (or just the task at the given index if it does not
+// belong to any group).
+func taskGroup(tasks []*model.Task, start int) []*model.Task {
+	end := start + 1
+
+	if group := tasks[start].ParallelGroup; group != "" {
+		for end < len(tasks) && tasks[end].ParallelGroup == group {
+			end++
+		}
+	}
+
+	return tasks[start:end]
+}
+
 // setup contextualizes and unmarshalls the tasks arguments.
 // It returns a json object exploitable by the task.
 func (r *Runner) setup(t *model.Task) (map[string]string, error) {
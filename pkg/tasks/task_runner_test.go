@@ -1,6 +1,7 @@
 package tasks
 
 import (
+	"errors"
 	"fmt"
 	"path"
 	"path/filepath"
@@ -43,7 +44,7 @@ func TestSetup(t *testing.T) {
 				"requestedHost": "#REQUESTEDHOST#", "fullTransferID": "#FULLTRANSFERID#",
 				"errCode": "#ERRORCODE#", "errMsg": "#ERRORMSG#", "errStrCode": "#ERRORSTRCODE#",
 				"inPath": "#INPATH#", "outPath": "#OUTPATH#", "workPath": "#WORKPATH#",
-				"homePath": "#HOMEPATH#", "transferInfo": "#TI_foo#/#TI_id#",
+				"homePath": "#HOMEPATH#", "transferInfo": "#TI_foo#/#TI_id#", "finalAttempt": "#FINALATTEMPT#",
 				"baseFileName": "#BASEFILENAME#", "fileExtension": "#FILEEXTENSION#",
 				"timestamp":        fmt.Sprintf("#TIMESTAMP(%s)#", timestampTsFormat),
 				"defaultTimestamp": `#TIMESTAMP#`,
@@ -267,6 +268,15 @@ func TestSetup(t *testing.T) {
 						})
 					})
 
+					Convey("Then res should contain an entry `finalAttempt`", func() {
+						val, ok := res["finalAttempt"]
+						So(ok, ShouldBeTrue)
+
+						Convey("Then res[finalAttempt] should contain the resolved variable", func() {
+							So(val, ShouldEqual, "false")
+						})
+					})
+
 					Convey("Then res should contain an entry `homePath`", func() {
 						val, ok := res["homePath"]
 						So(ok, ShouldBeTrue)
@@ -396,8 +406,11 @@ func TestRunTasks(t *testing.T) {
 			db:     db,
 			Logger: logger,
 			transCtx: &model.TransferContext{
-				Rule:     rule,
-				Transfer: trans,
+				Rule:          rule,
+				Transfer:      trans,
+				Client:        client,
+				RemoteAgent:   agent,
+				RemoteAccount: account,
 			},
 			Ctx: t.Context(),
 		}
@@ -510,6 +523,315 @@ func TestRunTasks(t *testing.T) {
 				})
 			})
 
+			Convey("Given a task failing with a specific error code", func() {
+				trans.LocalPath = filepath.Join(t.TempDir(), "file")
+				So(fs.WriteFullFile(trans.LocalPath, []byte("content")), ShouldBeNil)
+
+				tasks := []*model.Task{{
+					RuleID: rule.ID,
+					Chain:  model.ChainPre,
+					Rank:   0,
+					Type:   VerifyChecksum,
+					Args:   map[string]string{"expected": "0123456789abcdef"},
+				}}
+
+				Convey("Then the error should keep the task's error code", func() {
+					err := proc.runTasks(tasks, false, nil)
+					So(err, ShouldNotBeNil)
+					So(err.Code, ShouldEqual, types.TeIntegrity)
+					So(trans.ErrCode, ShouldEqual, types.TeIntegrity)
+				})
+			})
+
+			Convey("Given some tasks with conditions", func() {
+				dummyTaskCheck = make(chan string, 4)
+
+				tasks := []*model.Task{
+					{
+						RuleID:    rule.ID,
+						Chain:     model.ChainPre,
+						Rank:      0,
+						Type:      taskFail,
+						Args:      map[string]string{},
+						Condition: `filename matches "*.csv"`,
+					}, {
+						RuleID:    rule.ID,
+						Chain:     model.ChainPre,
+						Rank:      1,
+						Type:      taskSuccess,
+						Args:      map[string]string{},
+						Condition: `rule == "rule" && previous == "skipped"`,
+					}, {
+						RuleID:    rule.ID,
+						Chain:     model.ChainPre,
+						Rank:      2,
+						Type:      taskFail,
+						Args:      map[string]string{},
+						Condition: `partner == "agent" && account != "toto"`,
+					},
+				}
+
+				Convey("Then it should only run the tasks whose condition is met", func() {
+					So(proc.runTasks(tasks, false, nil), ShouldBeNil)
+					dummyTaskCheck <- "DONE"
+
+					So(<-dummyTaskCheck, ShouldEqual, "SUCCESS")
+					So(<-dummyTaskCheck, ShouldEqual, "DONE")
+					So(trans.TaskNumber, ShouldEqual, 3)
+				})
+			})
+
+			Convey("Given a failing task set to continue on error", func() {
+				dummyTaskCheck = make(chan string, 4)
+
+				tasks := []*model.Task{
+					{
+						RuleID:  rule.ID,
+						Chain:   model.ChainPre,
+						Rank:    0,
+						Type:    taskFail,
+						Args:    map[string]string{},
+						OnError: model.OnErrorContinue,
+					}, {
+						RuleID:    rule.ID,
+						Chain:     model.ChainPre,
+						Rank:      1,
+						Type:      taskSuccess,
+						Args:      map[string]string{},
+						Condition: `previous == "error"`,
+					},
+				}
+
+				Convey("Then it should run the tasks without error", func() {
+					So(proc.runTasks(tasks, false, nil), ShouldBeNil)
+					dummyTaskCheck <- "DONE"
+
+					Convey("Then it should have executed all tasks", func() {
+						So(<-dummyTaskCheck, ShouldEqual, "FAILURE")
+						So(<-dummyTaskCheck, ShouldEqual, "SUCCESS")
+						So(<-dummyTaskCheck, ShouldEqual, "DONE")
+					})
+
+					Convey("Then the transfer should have a warning", func() {
+						So(trans.ErrCode, ShouldEqual, types.TeWarning)
+						So(trans.ErrDetails, ShouldStartWith, "Task TESTFAIL @ rule PRE[0]")
+					})
+				})
+			})
+
+			Convey("Given a failing task set to skip the remaining tasks on error", func() {
+				dummyTaskCheck = make(chan string, 4)
+
+				tasks := []*model.Task{
+					{
+						RuleID:  rule.ID,
+						Chain:   model.ChainPre,
+						Rank:    0,
+						Type:    taskFail,
+						Args:    map[string]string{},
+						OnError: model.OnErrorSkip,
+					}, {
+						RuleID: rule.ID,
+						Chain:  model.ChainPre,
+						Rank:   1,
+						Type:   taskSuccess,
+						Args:   map[string]string{},
+					},
+				}
+
+				Convey("Then it should stop the chain without error", func() {
+					So(proc.runTasks(tasks, false, nil), ShouldBeNil)
+					dummyTaskCheck <- "DONE"
+
+					So(<-dummyTaskCheck, ShouldEqual, "FAILURE")
+					So(<-dummyTaskCheck, ShouldEqual, "DONE")
+					So(trans.ErrCode, ShouldEqual, types.TeWarning)
+
+					Convey("Then the skipped tasks should be marked as done", func() {
+						So(trans.TaskNumber, ShouldEqual, 2)
+
+						var check model.Transfer
+						So(db.Get(&check, "id=?", trans.ID).Run(), ShouldBeNil)
+						So(check.TaskNumber, ShouldEqual, 2)
+					})
+
+					Convey("When resuming the tasks", func() {
+						So(proc.runTasks(tasks, false, nil), ShouldBeNil)
+						dummyTaskCheck <- "DONE"
+
+						Convey("Then the skipped tasks should not be executed", func() {
+							So(<-dummyTaskCheck, ShouldEqual, "DONE")
+						})
+					})
+				})
+			})
+
+			Convey("Given a failing task with retries", func() {
+				dummyTaskCheck = make(chan string, 4)
+
+				tasks := []*model.Task{{
+					RuleID:     rule.ID,
+					Chain:      model.ChainPre,
+					Rank:       0,
+					Type:       taskFail,
+					Args:       map[string]string{},
+					Retries:    2,
+					RetryDelay: time.Millisecond,
+				}}
+
+				Convey("Then running the tasks should return an error", func() {
+					So(proc.runTasks(tasks, false, nil), ShouldBeError)
+					dummyTaskCheck <- "DONE"
+
+					Convey("Then it should have retried the task", func() {
+						So(<-dummyTaskCheck, ShouldEqual, "FAILURE")
+						So(<-dummyTaskCheck, ShouldEqual, "FAILURE")
+						So(<-dummyTaskCheck, ShouldEqual, "FAILURE")
+						So(<-dummyTaskCheck, ShouldEqual, "DONE")
+					})
+
+					Convey("Then the attempts should have been recorded", func() {
+						attempts, ok := trans.TransferInfo[model.TaskAttempts].([]any)
+						So(ok, ShouldBeTrue)
+						So(attempts, ShouldHaveLength, 3)
+
+						last, ok := attempts[2].(map[string]any)
+						So(ok, ShouldBeTrue)
+						So(last["attempt"], ShouldEqual, 3)
+						So(last["result"], ShouldEqual, "error")
+						So(last["error"], ShouldEqual, errTaskFailed.Error())
+					})
+				})
+			})
+
+			Convey("Given a task exceeding its timeout", func() {
+				dummyTaskCheck = make(chan string, 2)
+
+				tasks := []*model.Task{{
+					RuleID:  rule.ID,
+					Chain:   model.ChainPre,
+					Rank:    0,
+					Type:    taskLong,
+					Args:    map[string]string{},
+					Timeout: 10 * time.Millisecond,
+				}}
+
+				Convey("Then running the tasks should return a timeout error", func() {
+					err := proc.runTasks(tasks, false, nil)
+					So(err, ShouldNotBeNil)
+					So(errors.Is(err, ErrTaskTimeout), ShouldBeTrue)
+				})
+			})
+
+			Convey("Given a group of parallel tasks", func() {
+				const timeout = 200 * time.Millisecond
+
+				dummyTaskCheck = make(chan string, 4)
+
+				tasks := make([]*model.Task, 3)
+				for i := range tasks {
+					tasks[i] = &model.Task{
+						RuleID:        rule.ID,
+						Chain:         model.ChainPre,
+						Rank:          int8(i),
+						Type:          taskLong,
+						Args:          map[string]string{},
+						OnError:       model.OnErrorContinue,
+						Timeout:       timeout,
+						ParallelGroup: "group",
+					}
+				}
+
+				Convey("Then it should run the tasks concurrently", func() {
+					start := time.Now()
+					So(proc.runTasks(tasks, false, nil), ShouldBeNil)
+					So(time.Since(start), ShouldBeLessThan, 3*timeout)
+
+					So(<-dummyTaskCheck, ShouldEqual, "LONG")
+					So(<-dummyTaskCheck, ShouldEqual, "LONG")
+					So(<-dummyTaskCheck, ShouldEqual, "LONG")
+					So(trans.TaskNumber, ShouldEqual, 3)
+				})
+			})
+
+			Convey("Given a parallel group with a failing task before a skipped one", func() {
+				dummyTaskCheck = make(chan string, 2)
+
+				tasks := []*model.Task{
+					{
+						RuleID:        rule.ID,
+						Chain:         model.ChainPre,
+						Rank:          0,
+						Type:          taskFail,
+						Args:          map[string]string{},
+						ParallelGroup: "group",
+					}, {
+						RuleID:        rule.ID,
+						Chain:         model.ChainPre,
+						Rank:          1,
+						Type:          taskSuccess,
+						Args:          map[string]string{},
+						Condition:     `filename matches "*.csv"`,
+						ParallelGroup: "group",
+					},
+				}
+
+				Convey("Then running the tasks should return an error", func() {
+					So(proc.runTasks(tasks, false, nil), ShouldNotBeNil)
+					dummyTaskCheck <- "DONE"
+
+					So(<-dummyTaskCheck, ShouldEqual, "FAILURE")
+					So(<-dummyTaskCheck, ShouldEqual, "DONE")
+
+					Convey("Then the failed task should not be counted as done", func() {
+						So(trans.TaskNumber, ShouldEqual, 0)
+
+						var check model.Transfer
+						So(db.Get(&check, "id=?", trans.ID).Run(), ShouldBeNil)
+						So(check.TaskNumber, ShouldEqual, 0)
+					})
+				})
+			})
+
+			Convey("Given a group of parallel SETINFO tasks", func() {
+				trans.TransferInfo = map[string]any{"old": "value", "deleted": "value"}
+
+				tasks := []*model.Task{
+					{
+						RuleID:        rule.ID,
+						Chain:         model.ChainPre,
+						Rank:          0,
+						Type:          SetInfo,
+						Args:          map[string]string{"key": "key1", "value": "val1"},
+						ParallelGroup: "group",
+					}, {
+						RuleID:        rule.ID,
+						Chain:         model.ChainPre,
+						Rank:          1,
+						Type:          SetInfo,
+						Args:          map[string]string{"key": "deleted", "value": ""},
+						ParallelGroup: "group",
+					}, {
+						RuleID:        rule.ID,
+						Chain:         model.ChainPre,
+						Rank:          2,
+						Type:          SetInfo,
+						Args:          map[string]string{"key": "key2", "value": "val2"},
+						ParallelGroup: "group",
+					},
+				}
+
+				Convey("Then it should merge the changes made by all the tasks", func() {
+					So(proc.runTasks(tasks, false, nil), ShouldBeNil)
+					So(trans.TaskNumber, ShouldEqual, 3)
+					So(trans.TransferInfo, ShouldContainKey, model.TaskAttempts)
+					So(trans.TransferInfo["old"], ShouldEqual, "value")
+					So(trans.TransferInfo["key1"], ShouldEqual, "val1")
+					So(trans.TransferInfo["key2"], ShouldEqual, "val2")
+					So(trans.TransferInfo, ShouldNotContainKey, "deleted")
+				})
+			})
+
 			Convey("Given an unknown type of task", func() {
 				dummyTaskCheck = make(chan string, 1)
 
@@ -17,6 +17,7 @@ const (
 	ExecMove   = "EXECMOVE"
 	ExecOutput = "EXECOUTPUT"
 	UpdateConf = "UPDATECONF"
+	Script     = "SCRIPT"
 
 	Rename      = "RENAME"
 	Transfer    = "TRANSFER"
@@ -30,16 +31,28 @@ const (
 	EncryptAndSign   = "ENCRYPT&SIGN"
 	DecryptAndVerify = "DECRYPT&VERIFY"
 
-	Archive = "ARCHIVE"
-	Extract = "EXTRACT"
+	Archive    = "ARCHIVE"
+	Extract    = "EXTRACT"
+	Compress   = "COMPRESS"
+	Decompress = "DECOMPRESS"
+
+	Split = "SPLIT"
+	Join  = "JOIN"
 
 	Transcode     = "TRANSCODE"
 	ChangeNewline = "CHNEWLINE"
+	RecFormat     = "RECFORMAT"
 
 	Icap         = "ICAP"
 	Email        = "EMAIL"
 	RemoteDelete = "REMOTEDELETE"
 	SendMessage  = "SENDMESSAGE"
+	Webhook      = "WEBHOOK"
+	Publish      = "PUBLISH"
+
+	Checksum       = "CHECKSUM"
+	VerifyChecksum = "VERIFYCHECKSUM"
+	Validate       = "VALIDATE"
 )
 
 //nolint:gochecknoinits //init is required here
@@ -56,6 +69,7 @@ func init() {
 	model.ValidTasks[ExecMove] = newRunner[*execMoveTask]
 	model.ValidTasks[ExecOutput] = newRunner[*execOutputTask]
 	model.ValidTasks[UpdateConf] = newRunner[*updateconfTask]
+	model.ValidTasks[Script] = newRunner[*scriptTask]
 
 	// Transfer tasks
 	model.ValidTasks[Rename] = newRunner[*renameTask] // "RENAME" is in fact a "change target" task
@@ -71,18 +85,32 @@ func init() {
 	model.ValidTasks[EncryptAndSign] = newRunner[*encryptSign]
 	model.ValidTasks[DecryptAndVerify] = newRunner[*decryptVerify]
 
+	// File integrity
+	model.ValidTasks[Checksum] = newRunner[*checksumTask]
+	model.ValidTasks[VerifyChecksum] = newRunner[*verifyChecksumTask]
+	model.ValidTasks[Validate] = newRunner[*validateTask]
+
 	// Archiving & compression
 	model.ValidTasks[Archive] = newRunner[*archiveTask]
 	model.ValidTasks[Extract] = newRunner[*extractTask]
+	model.ValidTasks[Compress] = newRunner[*compressTask]
+	model.ValidTasks[Decompress] = newRunner[*decompressTask]
+
+	// File splitting
+	model.ValidTasks[Split] = newRunner[*splitTask]
+	model.ValidTasks[Join] = newRunner[*joinTask]
 
 	// Content manipulation
 	model.ValidTasks[Transcode] = newRunner[*transcodeTask]
 	model.ValidTasks[ChangeNewline] = newRunner[*chNewlineTask]
+	model.ValidTasks[RecFormat] = newRunner[*recFormatTask]
 
 	// Network
 	model.ValidTasks[Icap] = newRunner[*icapTask]
 	model.ValidTasks[Email] = newRunner[*emailTask]
 	model.ValidTasks[RemoteDelete] = newRunner[*remoteDelete]
+	model.ValidTasks[Webhook] = newRunner[*webhookTask]
+	model.ValidTasks[Publish] = newRunner[*publishTask]
 
 	// PeSIT messaging
 	model.ValidTasks[SendMessage] = newRunner[*sendMessageTask]
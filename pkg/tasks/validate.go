@@ -0,0 +1,221 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/fs"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils"
+)
+
+const (
+	ValidateXML  = "XML"
+	ValidateJSON = "JSON"
+	ValidateCSV  = "CSV"
+
+	validateDefaultMaxErrors = 100
+	validateErrorsInDetails  = 3
+)
+
+var (
+	ErrValidateNoSchema      = errors.New("no validation schema specified")
+	ErrValidateUnknownFormat = errors.New("unknown file format")
+	ErrValidateInvalidFile   = errors.New("the file is invalid")
+)
+
+// contentValidator validates the content of a file against a schema, and adds
+// the problems it finds to the given report. The returned error is reserved for
+// problems which prevent the validation itself (I/O errors...).
+type contentValidator interface {
+	validate(reader io.Reader, report *validationReport) error
+}
+
+// validationFormats lists the supported file formats, along with the function
+// loading their schema.
+//
+//nolint:gochecknoglobals //global var is needed here for the list of formats
+var validationFormats = map[string]func(*validateTask) (contentValidator, error){
+	ValidateXML:  newXSDValidator,
+	ValidateJSON: newJSONValidator,
+	ValidateCSV:  newCSVValidator,
+}
+
+// validationExtensions maps the file extensions to their format, for when
+// no format is given.
+//
+//nolint:gochecknoglobals //global var is needed here for the list of extensions
+var validationExtensions = map[string]string{
+	".xml":    ValidateXML,
+	".json":   ValidateJSON,
+	".jsonl":  ValidateJSON,
+	".ndjson": ValidateJSON,
+	".csv":    ValidateCSV,
+}
+
+// validationReport holds the problems found during the validation of a file.
+type validationReport struct {
+	errors    []string
+	maxErrors int
+}
+
+// addf adds a new problem to the report. If the line number is not known, it
+// should be 0.
+func (r *validationReport) addf(line int, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	if line > 0 {
+		msg = fmt.Sprintf("line %d: %s", line, msg)
+	}
+
+	r.errors = append(r.errors, msg)
+}
+
+// full returns whether the maximum number of errors has been reached, in which
+// case the validation should stop.
+func (r *validationReport) full() bool {
+	return r.maxErrors > 0 && len(r.errors) >= r.maxErrors
+}
+
+func (r *validationReport) String() string {
+	var builder strings.Builder
+
+	for _, msg := range r.errors {
+		builder.WriteString(msg)
+		builder.WriteByte('\n')
+	}
+
+	if r.full() {
+		fmt.Fprintf(&builder, "validation stopped after %d errors\n", r.maxErrors)
+	}
+
+	return builder.String()
+}
+
+// validateTask is a task which checks that the transfer file is a valid XML
+// (against an XSD), JSON (against a JSON Schema) or CSV (against a column
+// specification) file. The file is read as a stream, so that large files can
+// be validated without being loaded in memory.
+type validateTask struct {
+	Format    string   `json:"format"`
+	Schema    string   `json:"schema"`
+	Report    string   `json:"report"`
+	MaxErrors *jsonInt `json:"maxErrors"`
+	JSONLines jsonBool `json:"jsonLines"`
+}
+
+func (v *validateTask) Validate(args map[string]string) error {
+	*v = validateTask{}
+
+	if err := utils.JSONConvert(args, v); err != nil {
+		return fmt.Errorf("failed to parse the validation arguments: %w", err)
+	}
+
+	if v.Schema == "" {
+		return ErrValidateNoSchema
+	}
+
+	if v.Format != "" {
+		v.Format = strings.ToUpper(v.Format)
+
+		if _, ok := validationFormats[v.Format]; !ok {
+			return fmt.Errorf("%w %q", ErrValidateUnknownFormat, v.Format)
+		}
+	}
+
+	if v.MaxErrors == nil {
+		maxErrors := jsonInt(validateDefaultMaxErrors)
+		v.MaxErrors = &maxErrors
+	}
+
+	return nil
+}
+
+func (v *validateTask) Run(ctx context.Context, args map[string]string, _ *database.DB,
+	logger *log.Logger, transCtx *model.TransferContext, _ any,
+) error {
+	if err := v.Validate(args); err != nil {
+		logger.Error(err.Error())
+
+		return err
+	}
+
+	filePath := transCtx.Transfer.LocalPath
+
+	validator, err := v.getValidator(filePath)
+	if err != nil {
+		logger.Errorf("Failed to load the validation schema: %v", err)
+
+		return err
+	}
+
+	report := &validationReport{maxErrors: int(*v.MaxErrors)}
+
+	if err := utils.RunWithCtx(ctx, func() error {
+		return validateFile(filePath, validator, report)
+	}); err != nil {
+		logger.Errorf("Failed to validate the file: %v", err)
+
+		return fmt.Errorf("failed to validate the file: %w", err)
+	}
+
+	if len(report.errors) == 0 {
+		logger.Debugf("File %q is a valid %s file", filePath, v.Format)
+
+		return nil
+	}
+
+	if v.Report != "" {
+		if err := fs.WriteFullFile(v.Report, []byte(report.String())); err != nil {
+			logger.Errorf("Failed to write the validation report: %v", err)
+		}
+	}
+
+	details := slices.Clone(report.errors[:min(len(report.errors), validateErrorsInDetails)])
+	if len(report.errors) > validateErrorsInDetails {
+		details = append(details, "...")
+	}
+
+	logger.Errorf("File %q is not a valid %s file:\n%s", filePath, v.Format, report)
+
+	return fmt.Errorf("%w: %d error(s): %s", ErrValidateInvalidFile,
+		len(report.errors), strings.Join(details, "; "))
+}
+
+func (v *validateTask) getValidator(filePath string) (contentValidator, error) {
+	if v.Format == "" {
+		ext := strings.ToLower(path.Ext(filePath))
+
+		format, ok := validationExtensions[ext]
+		if !ok {
+			return nil, fmt.Errorf("%w: cannot deduce the format from the file extension %q",
+				ErrValidateUnknownFormat, ext)
+		}
+
+		v.Format = format
+
+		// ".jsonl" & ".ndjson" files contain one JSON document per line.
+		if format == ValidateJSON && ext != ".json" {
+			v.JSONLines = true
+		}
+	}
+
+	return validationFormats[v.Format](v)
+}
+
+func validateFile(filePath string, validator contentValidator, report *validationReport) error {
+	file, err := fs.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+
+	defer file.Close() //nolint:errcheck //error is irrelevant here
+
+	return validator.validate(file, report)
+}
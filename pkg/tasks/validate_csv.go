@@ -0,0 +1,325 @@
+package tasks
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/fs"
+)
+
+const (
+	csvTypeString   = "string"
+	csvTypeInteger  = "integer"
+	csvTypeDecimal  = "decimal"
+	csvTypeBoolean  = "boolean"
+	csvTypeDate     = "date"
+	csvTypeDateTime = "datetime"
+)
+
+var (
+	ErrCSVNoColumns      = errors.New("the CSV specification does not define any column")
+	ErrCSVInvalidSep     = errors.New("the CSV separator must be a single character")
+	ErrCSVUnknownType    = errors.New("unknown CSV column type")
+	ErrCSVInvalidBound   = errors.New("invalid CSV column bound")
+	ErrCSVBoundForString = errors.New(`"min" & "max" cannot be used on a string column`)
+
+	errCSVNotInteger = errors.New("not an integer")
+	errCSVNotDecimal = errors.New("not a decimal number")
+)
+
+// csvBound is a "min" or "max" bound of a CSV column specification. It can be
+// given either as a JSON string or as a JSON number.
+type csvBound string
+
+func (c *csvBound) UnmarshalJSON(data []byte) error {
+	if str, err := strconv.Unquote(string(data)); err == nil {
+		*c = csvBound(str)
+	} else {
+		*c = csvBound(data)
+	}
+
+	return nil
+}
+
+// csvColumn is the specification of a CSV column.
+type csvColumn struct {
+	Name      string   `json:"name"`
+	Type      string   `json:"type"`
+	Required  bool     `json:"required"`
+	Pattern   string   `json:"pattern"`
+	Enum      []string `json:"enum"`
+	MinLength *int     `json:"minLength"`
+	MaxLength *int     `json:"maxLength"`
+	Min       csvBound `json:"min"`
+	Max       csvBound `json:"max"`
+	Format    string   `json:"format"`
+
+	pattern  *regexp.Regexp
+	layout   string
+	min, max any
+}
+
+// csvValidator validates CSV files against a specification of their columns.
+// The file is validated one record at a time.
+type csvValidator struct {
+	Separator         string      `json:"separator"`
+	Comment           string      `json:"comment"`
+	Header            bool        `json:"header"`
+	AllowExtraColumns bool        `json:"allowExtraColumns"`
+	Columns           []csvColumn `json:"columns"`
+}
+
+func newCSVValidator(task *validateTask) (contentValidator, error) {
+	content, err := fs.ReadFullFile(task.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the CSV specification: %w", err)
+	}
+
+	var validator csvValidator
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&validator); err != nil {
+		return nil, fmt.Errorf("failed to parse the CSV specification: %w", err)
+	}
+
+	if err := validator.init(); err != nil {
+		return nil, err
+	}
+
+	return &validator, nil
+}
+
+func (c *csvValidator) init() error {
+	if len(c.Columns) == 0 {
+		return ErrCSVNoColumns
+	}
+
+	if c.Separator == "" {
+		c.Separator = ","
+	}
+
+	if utf8.RuneCountInString(c.Separator) != 1 || utf8.RuneCountInString(c.Comment) > 1 {
+		return ErrCSVInvalidSep
+	}
+
+	for i := range c.Columns {
+		if err := c.Columns[i].init(); err != nil {
+			return fmt.Errorf("column %d (%q): %w", i+1, c.Columns[i].Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (c *csvColumn) init() error {
+	if c.Type == "" {
+		c.Type = csvTypeString
+	}
+
+	c.Type = strings.ToLower(c.Type)
+
+	switch c.Type {
+	case csvTypeString, csvTypeInteger, csvTypeDecimal, csvTypeBoolean:
+	case csvTypeDate:
+		c.layout = time.DateOnly
+		if c.Format != "" {
+			c.layout = toGoTimeLayout(c.Format)
+		}
+	case csvTypeDateTime:
+		c.layout = time.RFC3339
+		if c.Format != "" {
+			c.layout = toGoTimeLayout(c.Format)
+		}
+	default:
+		return fmt.Errorf("%w %q", ErrCSVUnknownType, c.Type)
+	}
+
+	if c.Pattern != "" {
+		var err error
+		if c.pattern, err = regexp.Compile("^(?:" + c.Pattern + ")$"); err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+
+	for _, bound := range []struct {
+		str csvBound
+		val *any
+	}{{c.Min, &c.min}, {c.Max, &c.max}} {
+		if bound.str == "" {
+			continue
+		}
+
+		if c.Type == csvTypeString || c.Type == csvTypeBoolean {
+			return ErrCSVBoundForString
+		}
+
+		val, err := c.parse(string(bound.str))
+		if err != nil {
+			return fmt.Errorf("%w %q: %w", ErrCSVInvalidBound, bound.str, err)
+		}
+
+		*bound.val = val
+	}
+
+	return nil
+}
+
+// parse parses the given value according to the column type. The returned
+// value is either a string, a bool, a *big.Rat or a time.Time.
+func (c *csvColumn) parse(value string) (any, error) {
+	switch c.Type {
+	case csvTypeInteger:
+		if _, ok := new(big.Int).SetString(value, 10); !ok {
+			return nil, errCSVNotInteger
+		}
+
+		rat, _ := new(big.Rat).SetString(value)
+
+		return rat, nil
+	case csvTypeDecimal:
+		rat, ok := new(big.Rat).SetString(value)
+		if !ok || strings.ContainsAny(value, "/eE") {
+			return nil, errCSVNotDecimal
+		}
+
+		return rat, nil
+	case csvTypeBoolean:
+		return strconv.ParseBool(value) //nolint:wrapcheck //wrapped by the caller
+	case csvTypeDate, csvTypeDateTime:
+		return time.Parse(c.layout, value) //nolint:wrapcheck //wrapped by the caller
+	default:
+		return value, nil
+	}
+}
+
+// compareCSVValues compares 2 values returned by parse.
+func compareCSVValues(a, b any) int {
+	switch a := a.(type) {
+	case *big.Rat:
+		return a.Cmp(b.(*big.Rat)) //nolint:forcetypeassert //both values have the same type
+	case time.Time:
+		return a.Compare(b.(time.Time)) //nolint:forcetypeassert //both values have the same type
+	default:
+		return 0
+	}
+}
+
+// check returns the problem with the given value, or an empty string if the
+// value is valid.
+func (c *csvColumn) check(value string) string {
+	if value == "" {
+		if c.Required {
+			return "missing required value"
+		}
+
+		return ""
+	}
+
+	parsed, err := c.parse(value)
+	if err != nil {
+		return fmt.Sprintf("invalid %s %q", c.Type, value)
+	}
+
+	length := utf8.RuneCountInString(value)
+
+	switch {
+	case c.MinLength != nil && length < *c.MinLength:
+		return fmt.Sprintf("value %q is shorter than %d characters", value, *c.MinLength)
+	case c.MaxLength != nil && length > *c.MaxLength:
+		return fmt.Sprintf("value %q is longer than %d characters", value, *c.MaxLength)
+	case c.pattern != nil && !c.pattern.MatchString(value):
+		return fmt.Sprintf("value %q does not match the pattern %q", value, c.Pattern)
+	case len(c.Enum) != 0 && !slices.Contains(c.Enum, value):
+		return fmt.Sprintf("value %q is not one of %q", value, c.Enum)
+	case c.min != nil && compareCSVValues(parsed, c.min) < 0:
+		return fmt.Sprintf("value %q is lower than %s", value, c.Min)
+	case c.max != nil && compareCSVValues(parsed, c.max) > 0:
+		return fmt.Sprintf("value %q is greater than %s", value, c.Max)
+	default:
+		return ""
+	}
+}
+
+func (c *csvValidator) validate(reader io.Reader, report *validationReport) error {
+	csvReader := csv.NewReader(reader)
+	csvReader.Comma, _ = utf8.DecodeRuneInString(c.Separator)
+	if c.Comment != "" {
+		csvReader.Comment, _ = utf8.DecodeRuneInString(c.Comment)
+	}
+
+	csvReader.FieldsPerRecord = -1
+	csvReader.ReuseRecord = true
+
+	for first := true; !report.full(); first = false {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if parseErr := (*csv.ParseError)(nil); errors.As(err, &parseErr) {
+			report.addf(parseErr.Line, "malformed CSV: %v", parseErr.Err)
+
+			continue
+		} else if err != nil {
+			return fmt.Errorf("failed to read the file: %w", err)
+		}
+
+		line, _ := csvReader.FieldPos(0)
+
+		if first && c.Header {
+			c.checkHeader(record, line, report)
+
+			continue
+		}
+
+		c.checkRecord(record, line, report)
+	}
+
+	return nil
+}
+
+func (c *csvValidator) checkHeader(record []string, line int, report *validationReport) {
+	for i, col := range c.Columns {
+		if i >= len(record) || record[i] != col.Name {
+			report.addf(line, "invalid header: expected %q, got %q", c.columnNames(), record)
+
+			return
+		}
+	}
+}
+
+func (c *csvValidator) columnNames() []string {
+	names := make([]string, len(c.Columns))
+	for i := range c.Columns {
+		names[i] = c.Columns[i].Name
+	}
+
+	return names
+}
+
+func (c *csvValidator) checkRecord(record []string, line int, report *validationReport) {
+	if len(record) < len(c.Columns) || (len(record) > len(c.Columns) && !c.AllowExtraColumns) {
+		report.addf(line, "expected %d columns, got %d", len(c.Columns), len(record))
+
+		return
+	}
+
+	for i := range c.Columns {
+		if problem := c.Columns[i].check(record[i]); problem != "" && !report.full() {
+			report.addf(line, "column %d (%q): %s", i+1, c.Columns[i].Name, problem)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package tasks
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/fs"
+)
+
+// jsonValidator validates JSON files against a JSON Schema. In "JSON lines"
+// mode, each line of the file is validated as a separate document, which
+// allows large files to be validated as a stream.
+type jsonValidator struct {
+	schema *jsonschema.Schema
+	lines  bool
+}
+
+func newJSONValidator(task *validateTask) (contentValidator, error) {
+	content, err := fs.ReadFullFile(task.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the JSON schema: %w", err)
+	}
+
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the JSON schema: %w", err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(task.Schema, doc); err != nil {
+		return nil, fmt.Errorf("failed to load the JSON schema: %w", err)
+	}
+
+	schema, err := compiler.Compile(task.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile the JSON schema: %w", err)
+	}
+
+	return &jsonValidator{schema: schema, lines: bool(task.JSONLines)}, nil
+}
+
+func (j *jsonValidator) validate(reader io.Reader, report *validationReport) error {
+	if !j.lines {
+		j.validateDocument(reader, 0, report)
+
+		return nil
+	}
+
+	bufReader := bufio.NewReader(reader)
+
+	for line := 1; !report.full(); line++ {
+		content, err := bufReader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("failed to read the file: %w", err)
+		}
+
+		if len(bytes.TrimSpace(content)) != 0 {
+			j.validateDocument(bytes.NewReader(content), line, report)
+		}
+
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func (j *jsonValidator) validateDocument(reader io.Reader, line int, report *validationReport) {
+	doc, err := jsonschema.UnmarshalJSON(reader)
+	if err != nil {
+		report.addf(line, "malformed JSON: %v", err)
+
+		return
+	}
+
+	var valErr *jsonschema.ValidationError
+
+	if err := j.schema.Validate(doc); errors.As(err, &valErr) {
+		addJSONErrors(valErr.BasicOutput(), line, report)
+	} else if err != nil {
+		report.addf(line, "%v", err)
+	}
+}
+
+// addJSONErrors adds the leaf errors of the given JSON Schema validation output
+// to the report.
+func addJSONErrors(unit *jsonschema.OutputUnit, line int, report *validationReport) {
+	if len(unit.Errors) == 0 {
+		if unit.Error != nil && !report.full() {
+			location := unit.InstanceLocation
+			if location == "" {
+				location = "/"
+			}
+
+			report.addf(line, "at %q: %s", location, unit.Error)
+		}
+
+		return
+	}
+
+	for i := range unit.Errors {
+		addJSONErrors(&unit.Errors[i], line, report)
+	}
+}
@@ -0,0 +1,338 @@
+package tasks
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/fs"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils/testhelpers"
+)
+
+const validateTestXSD = `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="order">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="id" type="xs:positiveInteger"/>
+        <xs:element name="date" type="xs:date"/>
+        <xs:element name="line" type="lineType" maxOccurs="unbounded"/>
+        <xs:element name="comment" type="xs:string" minOccurs="0"/>
+      </xs:sequence>
+      <xs:attribute name="currency" type="currencyType" use="required"/>
+    </xs:complexType>
+  </xs:element>
+  <xs:complexType name="lineType">
+    <xs:sequence>
+      <xs:element name="product" type="xs:string"/>
+      <xs:element name="quantity">
+        <xs:simpleType>
+          <xs:restriction base="xs:int">
+            <xs:minInclusive value="1"/>
+            <xs:maxInclusive value="99"/>
+          </xs:restriction>
+        </xs:simpleType>
+      </xs:element>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:simpleType name="currencyType">
+    <xs:restriction base="xs:string">
+      <xs:pattern value="[A-Z]{3}"/>
+    </xs:restriction>
+  </xs:simpleType>
+</xs:schema>`
+
+func validateTestOccursXSD(minOccurs, maxOccurs string) string {
+	return `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="list">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="item" type="xs:string" minOccurs="` + minOccurs +
+		`" maxOccurs="` + maxOccurs + `"/>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`
+}
+
+const validateTestJSONSchema = `{
+  "type": "object",
+  "required": ["id", "amount"],
+  "properties": {
+    "id": {"type": "integer"},
+    "amount": {"type": "number", "minimum": 0}
+  }
+}`
+
+const validateTestCSVSpec = `{
+  "separator": ";",
+  "header": true,
+  "columns": [
+    {"name": "id", "type": "integer", "required": true, "min": 1},
+    {"name": "name", "maxLength": 5},
+    {"name": "birth", "type": "date", "format": "DD/MM/YYYY"},
+    {"name": "active", "type": "boolean"}
+  ]
+}`
+
+func makeValidateTestFiles(t *testing.T, schema, fileName, content string,
+) (*model.TransferContext, string) {
+	t.Helper()
+
+	root := t.TempDir()
+	schemaPath := filepath.Join(root, "schema")
+	filePath := filepath.Join(root, fileName)
+
+	require.NoError(t, fs.WriteFullFile(schemaPath, []byte(schema)))
+	require.NoError(t, fs.WriteFullFile(filePath, []byte(content)))
+
+	return &model.TransferContext{Transfer: &model.Transfer{LocalPath: filePath}}, schemaPath
+}
+
+func TestValidateValidate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Given valid arguments", func(t *testing.T) {
+		t.Parallel()
+
+		task := &validateTask{}
+		require.NoError(t, task.Validate(map[string]string{"format": "xml", "schema": "foo.xsd"}))
+		assert.Equal(t, ValidateXML, task.Format, "Then the format should have been normalized")
+		assert.EqualValues(t, validateDefaultMaxErrors, *task.MaxErrors,
+			"Then the default maximum number of errors should have been set")
+	})
+
+	t.Run("Given no schema", func(t *testing.T) {
+		t.Parallel()
+
+		require.ErrorIs(t, (&validateTask{}).Validate(map[string]string{"format": "xml"}),
+			ErrValidateNoSchema)
+	})
+
+	t.Run("Given an unknown format", func(t *testing.T) {
+		t.Parallel()
+
+		require.ErrorIs(t, (&validateTask{}).Validate(map[string]string{
+			"format": "yaml", "schema": "foo",
+		}), ErrValidateUnknownFormat)
+	})
+}
+
+func TestValidateRunXML(t *testing.T) {
+	t.Parallel()
+
+	logger := testhelpers.GetTestLogger(t)
+
+	t.Run("Given a valid XML file", func(t *testing.T) {
+		t.Parallel()
+
+		transCtx, schema := makeValidateTestFiles(t, validateTestXSD, "order.xml", `<?xml version="1.0"?>
+<order currency="EUR">
+  <id>12</id>
+  <date>2024-05-01</date>
+  <line><product>foo</product><quantity>3</quantity></line>
+  <line><product>bar</product><quantity>99</quantity></line>
+</order>`)
+
+		require.NoError(t, (&validateTask{}).Run(t.Context(), map[string]string{"schema": schema},
+			nil, logger, transCtx, nil))
+	})
+
+	t.Run("Given an invalid XML file", func(t *testing.T) {
+		t.Parallel()
+
+		transCtx, schema := makeValidateTestFiles(t, validateTestXSD, "order.xml", `<?xml version="1.0"?>
+<order currency="euro">
+  <id>0</id>
+  <date>2024-05-01</date>
+  <line><product>foo</product><quantity>100</quantity></line>
+  <unknown/>
+</order>`)
+		report := filepath.Join(t.TempDir(), "report.txt")
+
+		err := (&validateTask{}).Run(t.Context(), map[string]string{
+			"schema": schema, "report": report,
+		}, nil, logger, transCtx, nil)
+		require.ErrorIs(t, err, ErrValidateInvalidFile)
+		assert.ErrorContains(t, err, "4 error(s)",
+			"Then the error should contain the number of problems")
+
+		content, readErr := fs.ReadFullFile(report)
+		require.NoError(t, readErr)
+
+		assert.Contains(t, string(content), "line 2:",
+			"Then the report should contain the invalid attribute's line")
+		assert.Contains(t, string(content), "line 3:",
+			"Then the report should contain the invalid ID's line")
+		assert.Contains(t, string(content), "line 5:",
+			"Then the report should contain the invalid quantity's line")
+		assert.Contains(t, string(content), "line 6:",
+			"Then the report should contain the unexpected element's line")
+	})
+
+	t.Run("Given a malformed XML file", func(t *testing.T) {
+		t.Parallel()
+
+		transCtx, schema := makeValidateTestFiles(t, validateTestXSD, "order.xml",
+			`<order currency="EUR"><id>1</id>`)
+
+		require.ErrorIs(t, (&validateTask{}).Run(t.Context(), map[string]string{"schema": schema},
+			nil, logger, transCtx, nil), ErrValidateInvalidFile)
+	})
+
+	t.Run("Given a schema with a maxOccurs above the limit", func(t *testing.T) {
+		t.Parallel()
+
+		transCtx, schema := makeValidateTestFiles(t, validateTestOccursXSD("1", "101"),
+			"list.xml", `<list><item>foo</item></list>`)
+
+		err := (&validateTask{}).Run(t.Context(), map[string]string{"schema": schema},
+			nil, logger, transCtx, nil)
+		require.ErrorIs(t, err, ErrXSDUnsupported)
+		assert.ErrorContains(t, err, "maxOccurs 101 is above the limit of 100")
+	})
+
+	t.Run("Given a schema with a minOccurs above the limit", func(t *testing.T) {
+		t.Parallel()
+
+		transCtx, schema := makeValidateTestFiles(t, validateTestOccursXSD("101", "unbounded"),
+			"list.xml", `<list><item>foo</item></list>`)
+
+		err := (&validateTask{}).Run(t.Context(), map[string]string{"schema": schema},
+			nil, logger, transCtx, nil)
+		require.ErrorIs(t, err, ErrXSDUnsupported)
+		assert.ErrorContains(t, err, "minOccurs 101 is above the limit of 100")
+	})
+
+	t.Run("Given a schema with occurrences at the limit", func(t *testing.T) {
+		t.Parallel()
+
+		transCtx, schema := makeValidateTestFiles(t, validateTestOccursXSD("2", "100"),
+			"list.xml", `<list><item>foo</item></list>`)
+
+		err := (&validateTask{}).Run(t.Context(), map[string]string{"schema": schema},
+			nil, logger, transCtx, nil)
+		require.Error(t, err, "the file should be rejected since it has less than 2 items")
+		assert.NotErrorIs(t, err, ErrXSDUnsupported)
+	})
+
+	t.Run("Given a maximum number of errors", func(t *testing.T) {
+		t.Parallel()
+
+		transCtx, schema := makeValidateTestFiles(t, validateTestXSD, "order.xml", `<?xml version="1.0"?>
+<order currency="euro">
+  <id>0</id>
+  <date>yesterday</date>
+  <line><product>foo</product><quantity>100</quantity></line>
+</order>`)
+
+		err := (&validateTask{}).Run(t.Context(), map[string]string{
+			"schema": schema, "maxErrors": "2",
+		}, nil, logger, transCtx, nil)
+		require.ErrorIs(t, err, ErrValidateInvalidFile)
+		assert.ErrorContains(t, err, "2 error(s)",
+			"Then the validation should have stopped after 2 errors")
+	})
+}
+
+func TestValidateRunJSON(t *testing.T) {
+	t.Parallel()
+
+	logger := testhelpers.GetTestLogger(t)
+
+	t.Run("Given a valid JSON file", func(t *testing.T) {
+		t.Parallel()
+
+		transCtx, schema := makeValidateTestFiles(t, validateTestJSONSchema, "file.json",
+			`{"id": 1, "amount": 12.5}`)
+
+		require.NoError(t, (&validateTask{}).Run(t.Context(), map[string]string{"schema": schema},
+			nil, logger, transCtx, nil))
+	})
+
+	t.Run("Given an invalid JSON file", func(t *testing.T) {
+		t.Parallel()
+
+		transCtx, schema := makeValidateTestFiles(t, validateTestJSONSchema, "file.json",
+			`{"id": "abc", "amount": -1}`)
+
+		err := (&validateTask{}).Run(t.Context(), map[string]string{"schema": schema},
+			nil, logger, transCtx, nil)
+		require.ErrorIs(t, err, ErrValidateInvalidFile)
+		assert.ErrorContains(t, err, `at "/id"`, "Then the error should locate the invalid value")
+		assert.ErrorContains(t, err, `at "/amount"`, "Then the error should locate the invalid value")
+	})
+
+	t.Run("Given an invalid JSON lines file", func(t *testing.T) {
+		t.Parallel()
+
+		transCtx, schema := makeValidateTestFiles(t, validateTestJSONSchema, "file.jsonl",
+			"{\"id\": 1, \"amount\": 1}\n\n{\"id\": 2}\n{\"id\": 3, \"amount\": 3\n")
+
+		err := (&validateTask{}).Run(t.Context(), map[string]string{"schema": schema},
+			nil, logger, transCtx, nil)
+		require.ErrorIs(t, err, ErrValidateInvalidFile)
+		assert.ErrorContains(t, err, "2 error(s)", "Then the error should contain the number of problems")
+		assert.ErrorContains(t, err, "line 3:", "Then the error should contain the invalid line")
+		assert.ErrorContains(t, err, "line 4: malformed JSON",
+			"Then the error should contain the malformed line")
+	})
+}
+
+func TestValidateRunCSV(t *testing.T) {
+	t.Parallel()
+
+	logger := testhelpers.GetTestLogger(t)
+
+	t.Run("Given a valid CSV file", func(t *testing.T) {
+		t.Parallel()
+
+		transCtx, schema := makeValidateTestFiles(t, validateTestCSVSpec, "file.csv",
+			"id;name;birth;active\n1;bob;01/02/1990;true\n2;alice;;false\n")
+
+		require.NoError(t, (&validateTask{}).Run(t.Context(), map[string]string{"schema": schema},
+			nil, logger, transCtx, nil))
+	})
+
+	t.Run("Given an invalid CSV file", func(t *testing.T) {
+		t.Parallel()
+
+		transCtx, schema := makeValidateTestFiles(t, validateTestCSVSpec, "file.txt",
+			"id;name;birth;active\n0;bob;01/02/1990;true\n2;roberta;1990-02-01;maybe\n3;bob\n")
+
+		err := (&validateTask{}).Run(t.Context(), map[string]string{
+			"schema": schema, "format": "csv", "maxErrors": "0",
+		}, nil, logger, transCtx, nil)
+		require.ErrorIs(t, err, ErrValidateInvalidFile)
+		assert.ErrorContains(t, err, "5 error(s)", "Then the error should contain the number of problems")
+		assert.ErrorContains(t, err, `line 2: column 1 ("id"): value "0" is lower than 1`,
+			"Then the error should contain the invalid value")
+		assert.ErrorContains(t, err, `line 3: column 2 ("name")`,
+			"Then the error should contain the invalid value")
+	})
+
+	t.Run("Given an invalid header", func(t *testing.T) {
+		t.Parallel()
+
+		transCtx, schema := makeValidateTestFiles(t, validateTestCSVSpec, "file.csv",
+			"id;nom;birth;active\n1;bob;01/02/1990;true\n")
+
+		err := (&validateTask{}).Run(t.Context(), map[string]string{"schema": schema},
+			nil, logger, transCtx, nil)
+		require.ErrorIs(t, err, ErrValidateInvalidFile)
+		assert.ErrorContains(t, err, "line 1: invalid header")
+	})
+
+	t.Run("Given an unknown file extension", func(t *testing.T) {
+		t.Parallel()
+
+		transCtx, schema := makeValidateTestFiles(t, validateTestCSVSpec, "file.txt", "")
+
+		require.ErrorIs(t, (&validateTask{}).Run(t.Context(), map[string]string{"schema": schema},
+			nil, logger, transCtx, nil), ErrValidateUnknownFormat)
+	})
+}
@@ -0,0 +1,1031 @@
+package tasks
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/fs"
+)
+
+const (
+	xsdNamespace = "http://www.w3.org/2001/XMLSchema"
+	xsiNamespace = "http://www.w3.org/2001/XMLSchema-instance"
+	xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+
+	// xsdMaxOccurs is the maximum value allowed for "minOccurs" and (unless
+	// unbounded) "maxOccurs". Since the particles are expanded once per
+	// occurrence in the content model automaton, larger values are rejected
+	// rather than approximated.
+	xsdMaxOccurs = 100
+)
+
+var (
+	ErrXSDInvalidSchema = errors.New("invalid XSD schema")
+	ErrXSDUnsupported   = errors.New("unsupported XSD feature")
+	ErrXSDUnknownRef    = errors.New("unknown XSD reference")
+)
+
+// xmlNode is a generic XML element, used to parse the XSD schemas.
+type xmlNode struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	Children []xmlNode  `xml:",any"`
+}
+
+func (n *xmlNode) attr(name string) string {
+	for _, attr := range n.Attrs {
+		if attr.Name.Space == "" && attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+
+	return ""
+}
+
+func (n *xmlNode) is(local string) bool {
+	return n.XMLName.Space == xsdNamespace && n.XMLName.Local == local
+}
+
+// xsdType is a compiled XSD type. Simple types are represented by an xsdType
+// with a text type and no content.
+type xsdType struct {
+	text    *xsdSimpleType
+	attrs   []*xsdAttribute
+	anyAttr bool
+	content *xsdParticle
+	mixed   bool
+	anyType bool
+
+	nfa *xsdNFA
+}
+
+func (t *xsdType) attribute(name string) *xsdAttribute {
+	for _, attr := range t.attrs {
+		if attr.name == name {
+			return attr
+		}
+	}
+
+	return nil
+}
+
+func (t *xsdType) getNFA() *xsdNFA {
+	if t.nfa == nil {
+		t.nfa = newXSDNFA(t.content)
+	}
+
+	return t.nfa
+}
+
+type xsdAttribute struct {
+	name     string
+	typ      *xsdSimpleType
+	required bool
+	fixed    *string
+}
+
+type xsdElement struct {
+	name     string
+	typ      *xsdType
+	nillable bool
+	fixed    *string
+}
+
+type xsdParticleKind uint8
+
+const (
+	xsdParticleElement xsdParticleKind = iota
+	xsdParticleAny
+	xsdParticleSequence
+	xsdParticleChoice
+	xsdParticleAll
+)
+
+type xsdParticle struct {
+	kind     xsdParticleKind
+	min, max int // max is -1 when unbounded
+	elem     *xsdElement
+	children []*xsdParticle
+}
+
+// xsdSchema is a compiled XSD schema. Named components are compiled lazily,
+// so that they can reference each other in any order (and recursively).
+type xsdSchema struct {
+	prefixes map[string]string
+
+	rawElements   map[string]*xmlNode
+	rawTypes      map[string]*xmlNode
+	rawGroups     map[string]*xmlNode
+	rawAttrGroups map[string]*xmlNode
+	rawAttributes map[string]*xmlNode
+
+	elements map[string]*xsdElement
+	types    map[string]*xsdType
+	simples  map[string]*xsdSimpleType
+}
+
+// xsdValidator validates XML files against an XSD schema. The validation is
+// done as the file is read, without loading the whole document in memory.
+//
+// The validator is implemented here because the gateway is built without cgo,
+// which rules out the libxml2-based validators, and there is no maintained
+// pure Go XSD validation library.
+//
+// Only a subset of XSD 1.0 is supported: namespaces are ignored (elements and
+// attributes are matched by their local name), and "include", "import",
+// "redefine", identity constraints and substitution groups are not supported.
+type xsdValidator struct {
+	schema *xsdSchema
+}
+
+func newXSDValidator(task *validateTask) (contentValidator, error) {
+	content, err := fs.ReadFullFile(task.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the XSD schema: %w", err)
+	}
+
+	schema, err := parseXSD(content)
+	if err != nil {
+		return nil, err
+	}
+
+	return &xsdValidator{schema: schema}, nil
+}
+
+func parseXSD(content []byte) (*xsdSchema, error) {
+	var root xmlNode
+	if err := xml.Unmarshal(content, &root); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrXSDInvalidSchema, err)
+	}
+
+	if !root.is("schema") {
+		return nil, fmt.Errorf("%w: the root element is not an XSD schema", ErrXSDInvalidSchema)
+	}
+
+	schema := &xsdSchema{
+		prefixes:      map[string]string{},
+		rawElements:   map[string]*xmlNode{},
+		rawTypes:      map[string]*xmlNode{},
+		rawGroups:     map[string]*xmlNode{},
+		rawAttrGroups: map[string]*xmlNode{},
+		rawAttributes: map[string]*xmlNode{},
+		elements:      map[string]*xsdElement{},
+		types:         map[string]*xsdType{},
+		simples:       map[string]*xsdSimpleType{},
+	}
+
+	for _, attr := range root.Attrs {
+		switch {
+		case attr.Name.Space == "xmlns":
+			schema.prefixes[attr.Name.Local] = attr.Value
+		case attr.Name.Space == "" && attr.Name.Local == "xmlns":
+			schema.prefixes[""] = attr.Value
+		}
+	}
+
+	for i := range root.Children {
+		child := &root.Children[i]
+		name := child.attr("name")
+
+		switch {
+		case child.is("element"):
+			schema.rawElements[name] = child
+		case child.is("complexType"), child.is("simpleType"):
+			schema.rawTypes[name] = child
+		case child.is("group"):
+			schema.rawGroups[name] = child
+		case child.is("attributeGroup"):
+			schema.rawAttrGroups[name] = child
+		case child.is("attribute"):
+			schema.rawAttributes[name] = child
+		case child.is("include"), child.is("import"), child.is("redefine"):
+			return nil, fmt.Errorf("%w: %q", ErrXSDUnsupported, child.XMLName.Local)
+		}
+	}
+
+	// Compile all the global elements now, so that errors in the schema are
+	// reported before the validation starts.
+	for name := range schema.rawElements {
+		if _, err := schema.getElement(name); err != nil {
+			return nil, err
+		}
+	}
+
+	return schema, nil
+}
+
+// resolve splits the given qualified name, and returns whether it refers to
+// the XSD namespace (in which case it is a built-in type).
+func (s *xsdSchema) resolve(qname string) (local string, builtin bool) {
+	prefix, local, ok := strings.Cut(qname, ":")
+	if !ok {
+		prefix, local = "", qname
+	}
+
+	return local, s.prefixes[prefix] == xsdNamespace
+}
+
+func (s *xsdSchema) getElement(qname string) (*xsdElement, error) {
+	name, _ := s.resolve(qname)
+
+	if elem, ok := s.elements[name]; ok {
+		return elem, nil
+	}
+
+	node, ok := s.rawElements[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: element %q", ErrXSDUnknownRef, qname)
+	}
+
+	elem := &xsdElement{}
+	s.elements[name] = elem
+
+	return elem, s.compileElement(node, elem)
+}
+
+func (s *xsdSchema) getType(qname string) (*xsdType, error) {
+	name, builtin := s.resolve(qname)
+
+	if builtin {
+		if name == "anyType" {
+			return &xsdType{anyType: true, mixed: true, anyAttr: true}, nil
+		}
+
+		simple, err := builtinSimpleType(name)
+		if err != nil {
+			return nil, err
+		}
+
+		return &xsdType{text: simple}, nil
+	}
+
+	if typ, ok := s.types[name]; ok {
+		return typ, nil
+	}
+
+	node, ok := s.rawTypes[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: type %q", ErrXSDUnknownRef, qname)
+	}
+
+	typ := &xsdType{}
+	s.types[name] = typ
+
+	if node.is("simpleType") {
+		simple, err := s.getSimpleType(qname)
+		typ.text = simple
+
+		return typ, err
+	}
+
+	return typ, s.compileComplexType(node, typ)
+}
+
+func (s *xsdSchema) getSimpleType(qname string) (*xsdSimpleType, error) {
+	name, builtin := s.resolve(qname)
+
+	if builtin {
+		return builtinSimpleType(name)
+	}
+
+	if simple, ok := s.simples[name]; ok {
+		return simple, nil
+	}
+
+	node, ok := s.rawTypes[name]
+	if !ok || !node.is("simpleType") {
+		return nil, fmt.Errorf("%w: simple type %q", ErrXSDUnknownRef, qname)
+	}
+
+	simple := &xsdSimpleType{}
+	s.simples[name] = simple
+
+	return simple, s.compileSimpleType(node, simple)
+}
+
+func (s *xsdSchema) compileElement(node *xmlNode, elem *xsdElement) error {
+	elem.name = node.attr("name")
+	elem.nillable = node.attr("nillable") == "true"
+
+	if fixed := node.attr("fixed"); fixed != "" {
+		elem.fixed = &fixed
+	}
+
+	var err error
+
+	if typeName := node.attr("type"); typeName != "" {
+		elem.typ, err = s.getType(typeName)
+
+		return err
+	}
+
+	for i := range node.Children {
+		switch child := &node.Children[i]; {
+		case child.is("complexType"):
+			elem.typ = &xsdType{}
+
+			return s.compileComplexType(child, elem.typ)
+		case child.is("simpleType"):
+			elem.typ = &xsdType{text: &xsdSimpleType{}}
+
+			return s.compileSimpleType(child, elem.typ.text)
+		}
+	}
+
+	// An element without type is of type "anyType".
+	elem.typ = &xsdType{anyType: true, mixed: true, anyAttr: true}
+
+	return nil
+}
+
+//nolint:funlen //splitting the function would not make it more readable
+func (s *xsdSchema) compileComplexType(node *xmlNode, typ *xsdType) error {
+	if node.attr("mixed") == "true" {
+		typ.mixed = true
+	}
+
+	for i := range node.Children {
+		child := &node.Children[i]
+
+		switch {
+		case child.is("sequence"), child.is("choice"), child.is("all"), child.is("group"):
+			var err error
+			if typ.content, err = s.compileParticle(child); err != nil {
+				return err
+			}
+		case child.is("attribute"), child.is("attributeGroup"), child.is("anyAttribute"):
+			if err := s.compileAttribute(child, typ); err != nil {
+				return err
+			}
+		case child.is("simpleContent"), child.is("complexContent"):
+			if err := s.compileDerivation(child, typ); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// compileDerivation compiles the extension or restriction of the given simple
+// or complex content.
+func (s *xsdSchema) compileDerivation(content *xmlNode, typ *xsdType) error {
+	for i := range content.Children {
+		deriv := &content.Children[i]
+		if !deriv.is("extension") && !deriv.is("restriction") {
+			continue
+		}
+
+		base, err := s.getType(deriv.attr("base"))
+		if err != nil {
+			return err
+		}
+
+		typ.attrs = append(typ.attrs, base.attrs...)
+		typ.anyAttr = base.anyAttr
+
+		if content.is("simpleContent") {
+			typ.text = base.text
+
+			if deriv.is("restriction") {
+				typ.text = &xsdSimpleType{base: base.text}
+				if err := s.compileFacets(deriv, typ.text); err != nil {
+					return err
+				}
+			}
+		} else if deriv.is("extension") {
+			typ.content = base.content
+			typ.mixed = typ.mixed || base.mixed
+		}
+
+		if err := s.compileComplexType(deriv, typ); err != nil {
+			return err
+		}
+
+		// For an extension, the content of the base type comes first.
+		if deriv.is("extension") && base.content != nil && typ.content != base.content {
+			typ.content = &xsdParticle{
+				kind: xsdParticleSequence, min: 1, max: 1,
+				children: []*xsdParticle{base.content, typ.content},
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *xsdSchema) compileAttribute(node *xmlNode, typ *xsdType) error {
+	switch {
+	case node.is("anyAttribute"):
+		typ.anyAttr = true
+
+		return nil
+	case node.is("attributeGroup"):
+		name, _ := s.resolve(node.attr("ref"))
+
+		group, ok := s.rawAttrGroups[name]
+		if !ok {
+			return fmt.Errorf("%w: attribute group %q", ErrXSDUnknownRef, node.attr("ref"))
+		}
+
+		for i := range group.Children {
+			if err := s.compileAttribute(&group.Children[i], typ); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case !node.is("attribute"):
+		return nil
+	}
+
+	if node.attr("use") == "prohibited" {
+		return nil
+	}
+
+	decl := node
+
+	if ref := node.attr("ref"); ref != "" {
+		name, _ := s.resolve(ref)
+
+		var ok bool
+		if decl, ok = s.rawAttributes[name]; !ok {
+			return fmt.Errorf("%w: attribute %q", ErrXSDUnknownRef, ref)
+		}
+	}
+
+	attr := &xsdAttribute{
+		name:     decl.attr("name"),
+		required: node.attr("use") == "required",
+	}
+
+	for _, n := range []*xmlNode{node, decl} {
+		if fixed := n.attr("fixed"); fixed != "" && attr.fixed == nil {
+			attr.fixed = &fixed
+		}
+	}
+
+	var err error
+
+	inline := slices.IndexFunc(decl.Children, func(n xmlNode) bool { return n.is("simpleType") })
+
+	switch typeName := decl.attr("type"); {
+	case typeName != "":
+		attr.typ, err = s.getSimpleType(typeName)
+	case inline >= 0:
+		attr.typ = &xsdSimpleType{}
+		err = s.compileSimpleType(&decl.Children[inline], attr.typ)
+	default:
+		attr.typ, err = builtinSimpleType("anySimpleType")
+	}
+
+	// A redefined attribute replaces the inherited one.
+	typ.attrs = slices.DeleteFunc(typ.attrs, func(a *xsdAttribute) bool { return a.name == attr.name })
+	typ.attrs = append(typ.attrs, attr)
+
+	return err
+}
+
+func parseOccurs(node *xmlNode) (minOcc, maxOcc int, err error) {
+	minOcc, maxOcc = 1, 1
+
+	if val := node.attr("minOccurs"); val != "" {
+		if _, err = fmt.Sscan(val, &minOcc); err != nil {
+			return 0, 0, fmt.Errorf("%w: invalid minOccurs %q", ErrXSDInvalidSchema, val)
+		}
+	}
+
+	switch val := node.attr("maxOccurs"); val {
+	case "":
+	case "unbounded":
+		maxOcc = -1
+	default:
+		if _, err = fmt.Sscan(val, &maxOcc); err != nil {
+			return 0, 0, fmt.Errorf("%w: invalid maxOccurs %q", ErrXSDInvalidSchema, val)
+		}
+	}
+
+	if minOcc > xsdMaxOccurs {
+		return 0, 0, fmt.Errorf("%w: minOccurs %d is above the limit of %d",
+			ErrXSDUnsupported, minOcc, xsdMaxOccurs)
+	}
+
+	if maxOcc > xsdMaxOccurs {
+		return 0, 0, fmt.Errorf("%w: maxOccurs %d is above the limit of %d (use \"unbounded\" instead)",
+			ErrXSDUnsupported, maxOcc, xsdMaxOccurs)
+	}
+
+	return minOcc, maxOcc, nil
+}
+
+func (s *xsdSchema) compileParticle(node *xmlNode) (*xsdParticle, error) {
+	minOcc, maxOcc, err := parseOccurs(node)
+	if err != nil {
+		return nil, err
+	}
+
+	part := &xsdParticle{min: minOcc, max: maxOcc}
+
+	switch {
+	case node.is("element"):
+		part.kind = xsdParticleElement
+
+		if ref := node.attr("ref"); ref != "" {
+			part.elem, err = s.getElement(ref)
+		} else {
+			part.elem = &xsdElement{}
+			err = s.compileElement(node, part.elem)
+		}
+
+		return part, err
+	case node.is("any"):
+		part.kind = xsdParticleAny
+
+		return part, nil
+	case node.is("group"):
+		name, _ := s.resolve(node.attr("ref"))
+
+		group, ok := s.rawGroups[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: group %q", ErrXSDUnknownRef, node.attr("ref"))
+		}
+
+		for i := range group.Children {
+			if child := &group.Children[i]; child.is("sequence") || child.is("choice") || child.is("all") {
+				inner, innerErr := s.compileParticle(child)
+				if innerErr != nil {
+					return nil, innerErr
+				}
+
+				part.kind, part.children = inner.kind, inner.children
+
+				return part, nil
+			}
+		}
+
+		return nil, fmt.Errorf("%w: empty group %q", ErrXSDInvalidSchema, name)
+	case node.is("sequence"):
+		part.kind = xsdParticleSequence
+	case node.is("choice"):
+		part.kind = xsdParticleChoice
+	case node.is("all"):
+		part.kind = xsdParticleAll
+	}
+
+	for i := range node.Children {
+		child := &node.Children[i]
+		if child.is("annotation") {
+			continue
+		}
+
+		childPart, childErr := s.compileParticle(child)
+		if childErr != nil {
+			return nil, childErr
+		}
+
+		part.children = append(part.children, childPart)
+	}
+
+	return part, nil
+}
+
+// xsdNFA is the automaton recognizing the sequences of child elements allowed
+// by a content model. It is used to validate the children of an element as
+// they are read.
+type xsdNFA struct {
+	states []xsdNFAState
+	start  int
+	accept int
+}
+
+type xsdNFAState struct {
+	eps  []int // epsilon transitions
+	elem *xsdElement
+	any  bool
+	out  int // target of the element (or wildcard) transition
+}
+
+func newXSDNFA(content *xsdParticle) *xsdNFA {
+	nfa := &xsdNFA{}
+
+	if content == nil || content.kind == xsdParticleAll {
+		nfa.start = nfa.newState()
+		nfa.accept = nfa.start
+
+		return nfa
+	}
+
+	nfa.start, nfa.accept = nfa.build(content)
+
+	return nfa
+}
+
+func (n *xsdNFA) newState() int {
+	n.states = append(n.states, xsdNFAState{out: -1})
+
+	return len(n.states) - 1
+}
+
+func (n *xsdNFA) link(from, to int) {
+	n.states[from].eps = append(n.states[from].eps, to)
+}
+
+// build adds the states recognizing the given particle (with its occurrences)
+// to the automaton, and returns its start and end states.
+func (n *xsdNFA) build(part *xsdParticle) (start, end int) {
+	minOcc, maxOcc := part.min, part.max
+
+	start = n.newState()
+	cur := start
+
+	for range minOcc {
+		fragStart, fragEnd := n.buildOnce(part)
+		n.link(cur, fragStart)
+		cur = fragEnd
+	}
+
+	if maxOcc < 0 {
+		loop := n.newState()
+		n.link(cur, loop)
+
+		fragStart, fragEnd := n.buildOnce(part)
+		n.link(loop, fragStart)
+		n.link(fragEnd, loop)
+
+		return start, loop
+	}
+
+	for range maxOcc - minOcc {
+		fragStart, fragEnd := n.buildOnce(part)
+		next := n.newState()
+
+		n.link(cur, fragStart)
+		n.link(fragEnd, next)
+		n.link(cur, next)
+		cur = next
+	}
+
+	return start, cur
+}
+
+func (n *xsdNFA) buildOnce(part *xsdParticle) (start, end int) {
+	start, end = n.newState(), n.newState()
+
+	switch part.kind {
+	case xsdParticleElement:
+		n.states[start].elem, n.states[start].out = part.elem, end
+	case xsdParticleAny:
+		n.states[start].any, n.states[start].out = true, end
+	case xsdParticleChoice:
+		for _, child := range part.children {
+			childStart, childEnd := n.build(child)
+			n.link(start, childStart)
+			n.link(childEnd, end)
+		}
+	default: // sequence (or "all" groups nested in other groups, approximated as sequences)
+		cur := start
+
+		for _, child := range part.children {
+			childStart, childEnd := n.build(child)
+			n.link(cur, childStart)
+			cur = childEnd
+		}
+
+		n.link(cur, end)
+	}
+
+	return start, end
+}
+
+// closure returns the given states along with all the states reachable from
+// them through epsilon transitions.
+func (n *xsdNFA) closure(states []int) []int {
+	seen := make(map[int]bool, len(states))
+	stack := slices.Clone(states)
+
+	for len(stack) > 0 {
+		state := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if seen[state] {
+			continue
+		}
+
+		seen[state] = true
+		stack = append(stack, n.states[state].eps...)
+	}
+
+	res := make([]int, 0, len(seen))
+	for state := range seen {
+		res = append(res, state)
+	}
+
+	slices.Sort(res)
+
+	return res
+}
+
+// step returns the states reached from the given ones when reading an element
+// with the given name, along with the element's declaration (nil for a
+// wildcard).
+func (n *xsdNFA) step(states []int, name string) (next []int, elem *xsdElement) {
+	for _, state := range states {
+		switch st := &n.states[state]; {
+		case st.elem != nil && st.elem.name == name:
+			if elem == nil {
+				elem = st.elem
+			}
+
+			next = append(next, st.out)
+		case st.any:
+			next = append(next, st.out)
+		}
+	}
+
+	if len(next) == 0 {
+		return nil, nil
+	}
+
+	return n.closure(next), elem
+}
+
+// expected returns the names of the elements allowed after the given states.
+func (n *xsdNFA) expected(states []int) string {
+	var names []string
+
+	for _, state := range states {
+		switch st := &n.states[state]; {
+		case st.elem != nil:
+			names = append(names, "<"+st.elem.name+">")
+		case st.any:
+			names = append(names, "any element")
+		}
+	}
+
+	slices.Sort(names)
+	names = slices.Compact(names)
+
+	if len(names) == 0 {
+		return "no element"
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// xsdFrame is the validation state of an open element.
+type xsdFrame struct {
+	name    string
+	line    int
+	elem    *xsdElement
+	typ     *xsdType
+	skip    bool
+	isNil   bool
+	states  []int
+	allSeen map[string]int
+	text    strings.Builder
+	badText bool
+}
+
+func (x *xsdValidator) validate(reader io.Reader, report *validationReport) error {
+	decoder := xml.NewDecoder(reader)
+
+	var (
+		stack    []*xsdFrame
+		seenRoot bool
+	)
+
+	for !report.full() {
+		tok, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			if !seenRoot {
+				report.addf(0, "the document is empty")
+			}
+
+			return nil
+		}
+
+		line, _ := decoder.InputPos()
+
+		var syntaxErr *xml.SyntaxError
+
+		switch {
+		case errors.As(err, &syntaxErr):
+			report.addf(syntaxErr.Line, "malformed XML: %s", syntaxErr.Msg)
+
+			return nil
+		case err != nil:
+			return fmt.Errorf("failed to read the file: %w", err)
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			var parent *xsdFrame
+			if len(stack) > 0 {
+				parent = stack[len(stack)-1]
+			} else if seenRoot {
+				report.addf(line, "multiple root elements")
+			}
+
+			seenRoot = true
+			stack = append(stack, x.startElement(parent, &tok, line, report))
+		case xml.EndElement:
+			x.endElement(stack[len(stack)-1], report)
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				x.charData(stack[len(stack)-1], tok, line, report)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (x *xsdValidator) startElement(parent *xsdFrame, start *xml.StartElement, line int,
+	report *validationReport,
+) *xsdFrame {
+	frame := &xsdFrame{name: start.Name.Local, line: line}
+
+	switch {
+	case parent == nil:
+		if frame.elem = x.schema.elements[frame.name]; frame.elem == nil {
+			report.addf(line, "unexpected root element <%s>", frame.name)
+		}
+	case parent.skip, parent.typ.anyType:
+		frame.skip = true
+
+		return frame
+	case parent.isNil:
+		report.addf(line, "element <%s> is nil and cannot have children", parent.name)
+	case parent.typ.content == nil:
+		report.addf(line, "unexpected element <%s>: element <%s> cannot have child elements",
+			frame.name, parent.name)
+	case parent.typ.content.kind == xsdParticleAll:
+		frame.elem = x.stepAll(parent, frame.name, line, report)
+	default:
+		nfa := parent.typ.getNFA()
+
+		next, elem := nfa.step(parent.states, frame.name)
+		if next == nil {
+			report.addf(line, "unexpected element <%s> in <%s>, expected %s",
+				frame.name, parent.name, nfa.expected(parent.states))
+		} else {
+			parent.states = next
+			frame.elem = elem
+		}
+
+		// Matched by a wildcard: the content is not validated.
+		if next != nil && elem == nil {
+			frame.skip = true
+
+			return frame
+		}
+	}
+
+	if frame.elem == nil {
+		frame.skip = true
+
+		return frame
+	}
+
+	frame.typ = frame.elem.typ
+	if frame.typ.content != nil && frame.typ.content.kind != xsdParticleAll {
+		nfa := frame.typ.getNFA()
+		frame.states = nfa.closure([]int{nfa.start})
+	}
+
+	x.checkAttributes(frame, start.Attr, report)
+
+	return frame
+}
+
+func (x *xsdValidator) stepAll(parent *xsdFrame, name string, line int,
+	report *validationReport,
+) *xsdElement {
+	if parent.allSeen == nil {
+		parent.allSeen = map[string]int{}
+	}
+
+	for _, child := range parent.typ.content.children {
+		if child.elem != nil && child.elem.name == name {
+			if parent.allSeen[name]++; parent.allSeen[name] > max(child.max, 1) {
+				report.addf(line, "element <%s> appears more than once in <%s>", name, parent.name)
+			}
+
+			return child.elem
+		}
+	}
+
+	report.addf(line, "unexpected element <%s> in <%s>", name, parent.name)
+
+	return nil
+}
+
+func (x *xsdValidator) checkAttributes(frame *xsdFrame, attrs []xml.Attr, report *validationReport) {
+	seen := map[string]bool{}
+
+	for _, attr := range attrs {
+		switch {
+		case attr.Name.Space == "xmlns", attr.Name.Space == "" && attr.Name.Local == "xmlns",
+			attr.Name.Space == xmlNamespace:
+			continue
+		case attr.Name.Space == xsiNamespace:
+			if attr.Name.Local == "nil" && (attr.Value == "true" || attr.Value == "1") {
+				if !frame.elem.nillable {
+					report.addf(frame.line, "element <%s> is not nillable", frame.name)
+				}
+
+				frame.isNil = true
+			}
+
+			continue
+		}
+
+		decl := frame.typ.attribute(attr.Name.Local)
+		if decl == nil {
+			if !frame.typ.anyAttr {
+				report.addf(frame.line, "unexpected attribute %q on element <%s>",
+					attr.Name.Local, frame.name)
+			}
+
+			continue
+		}
+
+		seen[decl.name] = true
+
+		if err := decl.typ.validate(attr.Value); err != nil {
+			report.addf(frame.line, "attribute %q of element <%s>: %v", decl.name, frame.name, err)
+		} else if decl.fixed != nil && attr.Value != *decl.fixed {
+			report.addf(frame.line, "attribute %q of element <%s> must be %q",
+				decl.name, frame.name, *decl.fixed)
+		}
+	}
+
+	for _, decl := range frame.typ.attrs {
+		if decl.required && !seen[decl.name] {
+			report.addf(frame.line, "missing required attribute %q on element <%s>",
+				decl.name, frame.name)
+		}
+	}
+}
+
+func (x *xsdValidator) charData(frame *xsdFrame, data xml.CharData, line int,
+	report *validationReport,
+) {
+	switch {
+	case frame.skip, frame.typ.mixed:
+	case frame.typ.text != nil:
+		frame.text.Write(data)
+	case !frame.badText && len(bytes.TrimSpace(data)) != 0:
+		frame.badText = true
+		report.addf(line, "element <%s> cannot contain text", frame.name)
+	}
+}
+
+func (x *xsdValidator) endElement(frame *xsdFrame, report *validationReport) {
+	if frame.skip {
+		return
+	}
+
+	text := frame.text.String()
+
+	switch {
+	case frame.isNil:
+		if text != "" {
+			report.addf(frame.line, "element <%s> is nil and cannot have content", frame.name)
+		}
+	case frame.typ.text != nil:
+		if err := frame.typ.text.validate(text); err != nil {
+			report.addf(frame.line, "element <%s>: %v", frame.name, err)
+		} else if frame.elem.fixed != nil && text != *frame.elem.fixed {
+			report.addf(frame.line, "element <%s> must be %q", frame.name, *frame.elem.fixed)
+		}
+	case frame.typ.content == nil:
+	case frame.typ.content.kind == xsdParticleAll:
+		x.endAll(frame, report)
+	default:
+		nfa := frame.typ.getNFA()
+		if !slices.Contains(frame.states, nfa.accept) {
+			report.addf(frame.line, "element <%s> is incomplete, expected %s",
+				frame.name, nfa.expected(frame.states))
+		}
+	}
+}
+
+func (x *xsdValidator) endAll(frame *xsdFrame, report *validationReport) {
+	if len(frame.allSeen) == 0 && frame.typ.content.min == 0 {
+		return
+	}
+
+	for _, child := range frame.typ.content.children {
+		if child.elem != nil && child.min > 0 && frame.allSeen[child.elem.name] == 0 {
+			report.addf(frame.line, "element <%s> is incomplete, expected <%s>",
+				frame.name, child.elem.name)
+		}
+	}
+}
@@ -0,0 +1,484 @@
+package tasks
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	errXSDInvalidValue = errors.New("invalid value")
+	errXSDFacet        = errors.New("value not allowed")
+)
+
+// xsdSimpleType is a compiled XSD simple type. Derived types reference their
+// base type, and are valid if the value is valid for the base type, and
+// satisfies their own facets.
+type xsdSimpleType struct {
+	builtin string
+	check   func(string) error
+	base    *xsdSimpleType
+	item    *xsdSimpleType   // for lists
+	members []*xsdSimpleType // for unions
+	facets  xsdFacets
+}
+
+type xsdFacets struct {
+	enum                         []string
+	patterns                     []*regexp.Regexp
+	length, minLength, maxLength *int
+	minIncl, maxIncl             string
+	minExcl, maxExcl             string
+	totalDigits, fractionDigits  *int
+}
+
+// primitive returns the name of the built-in type from which the type derives.
+func (s *xsdSimpleType) primitive() string {
+	for typ := s; typ != nil; typ = typ.base {
+		if typ.builtin != "" {
+			return typ.builtin
+		}
+	}
+
+	return ""
+}
+
+func (s *xsdSimpleType) isList() bool {
+	for typ := s; typ != nil; typ = typ.base {
+		if typ.item != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *xsdSimpleType) normalize(value string) string {
+	switch s.primitive() {
+	case "string", "anySimpleType":
+		if !s.isList() {
+			return value
+		}
+	case "normalizedString":
+		return strings.NewReplacer("\t", " ", "\n", " ", "\r", " ").Replace(value)
+	}
+
+	return strings.Join(strings.Fields(value), " ")
+}
+
+func (s *xsdSimpleType) validate(value string) error {
+	if len(s.members) != 0 {
+		if !slices.ContainsFunc(s.members, func(m *xsdSimpleType) bool { return m.validate(value) == nil }) {
+			return fmt.Errorf("%w: %q does not match any of the union's types", errXSDInvalidValue, value)
+		}
+
+		return s.checkFacets(s.normalize(value))
+	}
+
+	if s.item != nil {
+		for _, item := range strings.Fields(value) {
+			if err := s.item.validate(item); err != nil {
+				return err
+			}
+		}
+	}
+
+	if s.base != nil {
+		if err := s.base.validate(value); err != nil {
+			return err
+		}
+	}
+
+	normalized := s.normalize(value)
+
+	if s.check != nil {
+		if err := s.check(normalized); err != nil {
+			return fmt.Errorf("%w: %q is not a valid %s", errXSDInvalidValue, normalized, s.builtin)
+		}
+	}
+
+	return s.checkFacets(normalized)
+}
+
+//nolint:gocyclo,cyclop //the facets are simple checks, splitting them would not help
+func (s *xsdSimpleType) checkFacets(value string) error {
+	facets := &s.facets
+
+	length := utf8.RuneCountInString(value)
+	if s.isList() {
+		length = len(strings.Fields(value))
+	}
+
+	switch {
+	case len(facets.enum) != 0 && !slices.Contains(facets.enum, value):
+		return fmt.Errorf("%w: %q is not one of %q", errXSDFacet, value, facets.enum)
+	case len(facets.patterns) != 0 && !slices.ContainsFunc(facets.patterns,
+		func(r *regexp.Regexp) bool { return r.MatchString(value) }):
+		return fmt.Errorf("%w: %q does not match the expected pattern", errXSDFacet, value)
+	case facets.length != nil && length != *facets.length:
+		return fmt.Errorf("%w: the length of %q must be %d", errXSDFacet, value, *facets.length)
+	case facets.minLength != nil && length < *facets.minLength:
+		return fmt.Errorf("%w: the length of %q must be at least %d", errXSDFacet, value, *facets.minLength)
+	case facets.maxLength != nil && length > *facets.maxLength:
+		return fmt.Errorf("%w: the length of %q must be at most %d", errXSDFacet, value, *facets.maxLength)
+	case facets.minIncl != "" && s.compare(value, facets.minIncl) < 0:
+		return fmt.Errorf("%w: %q must be greater than or equal to %s", errXSDFacet, value, facets.minIncl)
+	case facets.maxIncl != "" && s.compare(value, facets.maxIncl) > 0:
+		return fmt.Errorf("%w: %q must be lower than or equal to %s", errXSDFacet, value, facets.maxIncl)
+	case facets.minExcl != "" && s.compare(value, facets.minExcl) <= 0:
+		return fmt.Errorf("%w: %q must be greater than %s", errXSDFacet, value, facets.minExcl)
+	case facets.maxExcl != "" && s.compare(value, facets.maxExcl) >= 0:
+		return fmt.Errorf("%w: %q must be lower than %s", errXSDFacet, value, facets.maxExcl)
+	}
+
+	intDigits, fracDigits := countDigits(value)
+
+	switch {
+	case facets.totalDigits != nil && intDigits+fracDigits > *facets.totalDigits:
+		return fmt.Errorf("%w: %q has more than %d digits", errXSDFacet, value, *facets.totalDigits)
+	case facets.fractionDigits != nil && fracDigits > *facets.fractionDigits:
+		return fmt.Errorf("%w: %q has more than %d fraction digits", errXSDFacet, value,
+			*facets.fractionDigits)
+	}
+
+	return nil
+}
+
+// countDigits returns the number of significant digits of the given decimal
+// number, before and after the decimal point.
+func countDigits(value string) (intDigits, fracDigits int) {
+	intPart, fracPart, _ := strings.Cut(strings.TrimLeft(value, "+-"), ".")
+
+	return len(strings.TrimLeft(intPart, "0")), len(strings.TrimRight(fracPart, "0"))
+}
+
+// compare compares 2 values of the type. Values which cannot be compared are
+// considered equal.
+func (s *xsdSimpleType) compare(a, b string) int {
+	switch prim := s.primitive(); prim {
+	case "date", "dateTime", "time":
+		timeA, errA := parseXSDTime(prim, a)
+		timeB, errB := parseXSDTime(prim, b)
+
+		if errA != nil || errB != nil {
+			return 0
+		}
+
+		return timeA.Compare(timeB)
+	default:
+		ratA, okA := new(big.Rat).SetString(a)
+		ratB, okB := new(big.Rat).SetString(b)
+
+		if !okA || !okB {
+			return 0
+		}
+
+		return ratA.Cmp(ratB)
+	}
+}
+
+//nolint:gochecknoglobals //global var is needed here to compile the regexps once
+var (
+	xsdDecimal  = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)$`)
+	xsdInteger  = regexp.MustCompile(`^[+-]?\d+$`)
+	xsdDuration = regexp.MustCompile(`^-?P(\d+Y)?(\d+M)?(\d+D)?(T(\d+H)?(\d+M)?(\d+(\.\d+)?S)?)?$`)
+	xsdName     = regexp.MustCompile(`^[\pL_:][\pL\pN._:\-]*$`)
+	xsdNCName   = regexp.MustCompile(`^[\pL_][\pL\pN._\-]*$`)
+	xsdNMToken  = regexp.MustCompile(`^[\pL\pN._:\-]+$`)
+	xsdLanguage = regexp.MustCompile(`^[a-zA-Z]{1,8}(-[a-zA-Z0-9]{1,8})*$`)
+)
+
+func parseXSDTime(primitive, value string) (time.Time, error) {
+	var layouts []string
+
+	switch primitive {
+	case "date":
+		layouts = []string{"2006-01-02", "2006-01-02Z07:00"}
+	case "dateTime":
+		layouts = []string{"2006-01-02T15:04:05.999999999", "2006-01-02T15:04:05.999999999Z07:00"}
+	default:
+		layouts = []string{"15:04:05.999999999", "15:04:05.999999999Z07:00"}
+	}
+
+	var err error
+
+	for _, layout := range layouts {
+		var t time.Time
+		if t, err = time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, err //nolint:wrapcheck //wrapped by the caller
+}
+
+func matchXSD(reg *regexp.Regexp) func(string) error {
+	return func(value string) error {
+		if !reg.MatchString(value) {
+			return errXSDInvalidValue
+		}
+
+		return nil
+	}
+}
+
+func xsdIntegerRange(minVal, maxVal string) func(string) error {
+	return func(value string) error {
+		if !xsdInteger.MatchString(value) {
+			return errXSDInvalidValue
+		}
+
+		i, _ := new(big.Int).SetString(strings.TrimPrefix(value, "+"), 10)
+
+		if minVal != "" {
+			if bound, _ := new(big.Int).SetString(minVal, 10); i.Cmp(bound) < 0 {
+				return errXSDInvalidValue
+			}
+		}
+
+		if maxVal != "" {
+			if bound, _ := new(big.Int).SetString(maxVal, 10); i.Cmp(bound) > 0 {
+				return errXSDInvalidValue
+			}
+		}
+
+		return nil
+	}
+}
+
+func xsdTemporal(primitive string) func(string) error {
+	return func(value string) error {
+		_, err := parseXSDTime(primitive, value)
+
+		return err
+	}
+}
+
+//nolint:funlen //the list of built-in types is long, but simple
+func builtinSimpleType(name string) (*xsdSimpleType, error) {
+	var check func(string) error
+
+	switch name {
+	case "anySimpleType", "string", "normalizedString", "token", "QName", "NOTATION",
+		"gYear", "gYearMonth", "gMonth", "gMonthDay", "gDay", "ENTITIES", "IDREFS":
+	case "boolean":
+		check = func(value string) error {
+			if value != "true" && value != "false" && value != "1" && value != "0" {
+				return errXSDInvalidValue
+			}
+
+			return nil
+		}
+	case "decimal":
+		check = matchXSD(xsdDecimal)
+	case "integer":
+		check = matchXSD(xsdInteger)
+	case "long":
+		check = xsdIntegerRange("-9223372036854775808", "9223372036854775807")
+	case "int":
+		check = xsdIntegerRange("-2147483648", "2147483647")
+	case "short":
+		check = xsdIntegerRange("-32768", "32767")
+	case "byte":
+		check = xsdIntegerRange("-128", "127")
+	case "nonNegativeInteger":
+		check = xsdIntegerRange("0", "")
+	case "positiveInteger":
+		check = xsdIntegerRange("1", "")
+	case "nonPositiveInteger":
+		check = xsdIntegerRange("", "0")
+	case "negativeInteger":
+		check = xsdIntegerRange("", "-1")
+	case "unsignedLong":
+		check = xsdIntegerRange("0", "18446744073709551615")
+	case "unsignedInt":
+		check = xsdIntegerRange("0", "4294967295")
+	case "unsignedShort":
+		check = xsdIntegerRange("0", "65535")
+	case "unsignedByte":
+		check = xsdIntegerRange("0", "255")
+	case "float", "double":
+		check = func(value string) error {
+			if value == "INF" || value == "-INF" || value == "NaN" {
+				return nil
+			}
+
+			_, err := strconv.ParseFloat(value, 64)
+
+			return err //nolint:wrapcheck //wrapped by the caller
+		}
+	case "date", "dateTime", "time":
+		check = xsdTemporal(name)
+	case "duration":
+		check = func(value string) error {
+			if !xsdDuration.MatchString(value) || strings.HasSuffix(value, "P") ||
+				strings.HasSuffix(value, "T") {
+				return errXSDInvalidValue
+			}
+
+			return nil
+		}
+	case "hexBinary":
+		check = func(value string) error {
+			_, err := hex.DecodeString(value)
+
+			return err //nolint:wrapcheck //wrapped by the caller
+		}
+	case "base64Binary":
+		check = func(value string) error {
+			_, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(value, " ", ""))
+
+			return err //nolint:wrapcheck //wrapped by the caller
+		}
+	case "anyURI":
+		check = func(value string) error {
+			_, err := url.Parse(value)
+
+			return err //nolint:wrapcheck //wrapped by the caller
+		}
+	case "language":
+		check = matchXSD(xsdLanguage)
+	case "Name":
+		check = matchXSD(xsdName)
+	case "NCName", "ID", "IDREF", "ENTITY":
+		check = matchXSD(xsdNCName)
+	case "NMTOKEN":
+		check = matchXSD(xsdNMToken)
+	case "NMTOKENS":
+		return &xsdSimpleType{builtin: name, item: &xsdSimpleType{builtin: "NMTOKEN", check: matchXSD(xsdNMToken)}}, nil
+	default:
+		return nil, fmt.Errorf("%w: built-in type %q", ErrXSDUnknownRef, name)
+	}
+
+	return &xsdSimpleType{builtin: name, check: check}, nil
+}
+
+func (s *xsdSchema) compileSimpleType(node *xmlNode, simple *xsdSimpleType) error {
+	for i := range node.Children {
+		child := &node.Children[i]
+
+		switch {
+		case child.is("restriction"):
+			base, err := s.compileSimpleRef(child, "base")
+			if err != nil {
+				return err
+			}
+
+			simple.base = base
+
+			return s.compileFacets(child, simple)
+		case child.is("list"):
+			item, err := s.compileSimpleRef(child, "itemType")
+			simple.item = item
+
+			return err
+		case child.is("union"):
+			for _, member := range strings.Fields(child.attr("memberTypes")) {
+				typ, err := s.getSimpleType(member)
+				if err != nil {
+					return err
+				}
+
+				simple.members = append(simple.members, typ)
+			}
+
+			for j := range child.Children {
+				if inline := &child.Children[j]; inline.is("simpleType") {
+					typ := &xsdSimpleType{}
+					if err := s.compileSimpleType(inline, typ); err != nil {
+						return err
+					}
+
+					simple.members = append(simple.members, typ)
+				}
+			}
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: empty simple type", ErrXSDInvalidSchema)
+}
+
+// compileSimpleRef returns the simple type referenced by the given attribute of
+// the node, or defined inline in the node.
+func (s *xsdSchema) compileSimpleRef(node *xmlNode, attr string) (*xsdSimpleType, error) {
+	if ref := node.attr(attr); ref != "" {
+		return s.getSimpleType(ref)
+	}
+
+	for i := range node.Children {
+		if inline := &node.Children[i]; inline.is("simpleType") {
+			typ := &xsdSimpleType{}
+
+			return typ, s.compileSimpleType(inline, typ)
+		}
+	}
+
+	return nil, fmt.Errorf("%w: missing %q", ErrXSDInvalidSchema, attr)
+}
+
+func (s *xsdSchema) compileFacets(node *xmlNode, simple *xsdSimpleType) error {
+	facets := &simple.facets
+
+	for i := range node.Children {
+		child := &node.Children[i]
+		value := child.attr("value")
+
+		if child.XMLName.Space != xsdNamespace {
+			continue
+		}
+
+		var intFacet **int
+
+		switch child.XMLName.Local {
+		case "enumeration":
+			facets.enum = append(facets.enum, value)
+		case "pattern":
+			reg, err := regexp.Compile("^(?:" + value + ")$")
+			if err != nil {
+				return fmt.Errorf("%w: pattern %q: %w", ErrXSDUnsupported, value, err)
+			}
+
+			facets.patterns = append(facets.patterns, reg)
+		case "minInclusive":
+			facets.minIncl = value
+		case "maxInclusive":
+			facets.maxIncl = value
+		case "minExclusive":
+			facets.minExcl = value
+		case "maxExclusive":
+			facets.maxExcl = value
+		case "length":
+			intFacet = &facets.length
+		case "minLength":
+			intFacet = &facets.minLength
+		case "maxLength":
+			intFacet = &facets.maxLength
+		case "totalDigits":
+			intFacet = &facets.totalDigits
+		case "fractionDigits":
+			intFacet = &facets.fractionDigits
+		}
+
+		if intFacet != nil {
+			val, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("%w: invalid %s %q", ErrXSDInvalidSchema, child.XMLName.Local, value)
+			}
+
+			*intFacet = &val
+		}
+	}
+
+	return nil
+}
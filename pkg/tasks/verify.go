@@ -85,6 +85,10 @@ func (v *verify) ValidateDB(db database.ReadAccess, params map[string]string) er
 		return fmt.Errorf("failed to retrieve verification key from database: %w", err)
 	}
 
+	if err := cryptoKey.ResolveSecrets(); err != nil {
+		return fmt.Errorf("failed to resolve verification key: %w", err)
+	}
+
 	method, ok := VerifyMethods.Get(v.Method)
 	if !ok {
 		return fmt.Errorf("%w: %s", ErrVerifyUnknownMethod, v.Method)
@@ -0,0 +1,207 @@
+package tasks
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/fs"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/types"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils"
+)
+
+var (
+	ErrVerifyChecksumNoSource       = errors.New("no expected checksum source was given")
+	ErrVerifyChecksumManySources    = errors.New("only one expected checksum source can be given")
+	ErrVerifyChecksumNoInfo         = errors.New("the expected checksum transfer info is missing")
+	ErrVerifyChecksumInvalidSidecar = errors.New("the checksum file does not contain a checksum")
+	ErrVerifyChecksumNotInManifest  = errors.New("the file is not listed in the checksum manifest")
+)
+
+// bsdChecksumLine matches the lines of a checksum manifest in the BSD format,
+// i.e. "ALGO (file name) = checksum".
+//
+//nolint:gochecknoglobals //global var is needed here to compile the regexp once
+var bsdChecksumLine = regexp.MustCompile(`^[\w-]+ \((.+)\) = ([[:xdigit:]]+)$`)
+
+// verifyChecksumTask is a task which computes the checksum of the transfer file,
+// and compares it with an expected value. That value can be given directly,
+// taken from the transfer info, or read from a sidecar file or a manifest.
+type verifyChecksumTask struct {
+	Algorithm    string   `json:"algorithm"`
+	Expected     string   `json:"expected"`
+	TransferInfo string   `json:"transferInfo"`
+	Sidecar      jsonBool `json:"sidecar"`
+	SidecarPath  string   `json:"sidecarPath"`
+	Manifest     string   `json:"manifest"`
+
+	algo checksumAlgo
+}
+
+func (v *verifyChecksumTask) Validate(args map[string]string) error {
+	*v = verifyChecksumTask{}
+
+	if err := utils.JSONConvert(args, v); err != nil {
+		return fmt.Errorf("failed to parse the checksum verification arguments: %w", err)
+	}
+
+	nbSources := 0
+
+	for _, isSet := range []bool{
+		v.Expected != "", v.TransferInfo != "", bool(v.Sidecar) || v.SidecarPath != "", v.Manifest != "",
+	} {
+		if isSet {
+			nbSources++
+		}
+	}
+
+	switch {
+	case nbSources == 0:
+		return ErrVerifyChecksumNoSource
+	case nbSources > 1:
+		return ErrVerifyChecksumManySources
+	}
+
+	var err error
+	v.algo, err = getChecksumAlgo(v.Algorithm)
+
+	return err
+}
+
+func (v *verifyChecksumTask) Run(ctx context.Context, args map[string]string, _ *database.DB,
+	logger *log.Logger, transCtx *model.TransferContext, _ any,
+) error {
+	if err := v.Validate(args); err != nil {
+		logger.Error(err.Error())
+
+		return err
+	}
+
+	filePath := transCtx.Transfer.LocalPath
+
+	expected, err := v.getExpected(transCtx)
+	if err != nil {
+		logger.Errorf("Failed to retrieve the expected checksum: %v", err)
+
+		return err
+	}
+
+	actual, err := hashFile(ctx, filePath, v.algo)
+	if err != nil {
+		logger.Errorf("Failed to compute the file checksum: %v", err)
+
+		return err
+	}
+
+	if !strings.EqualFold(expected, actual) {
+		logger.Errorf("Checksum mismatch: expected %s, got %s", expected, actual)
+
+		return newError(types.TeIntegrity, "checksum mismatch: expected %s, got %s",
+			expected, actual)
+	}
+
+	logger.Debugf("File checksum verified: %s", actual)
+
+	return nil
+}
+
+func (v *verifyChecksumTask) getExpected(transCtx *model.TransferContext) (string, error) {
+	filePath := transCtx.Transfer.LocalPath
+
+	switch {
+	case v.Expected != "":
+		return strings.TrimSpace(v.Expected), nil
+	case v.TransferInfo != "":
+		value, ok := transCtx.Transfer.TransferInfo[v.TransferInfo]
+		if !ok {
+			return "", fmt.Errorf("%w: %q", ErrVerifyChecksumNoInfo, v.TransferInfo)
+		}
+
+		return strings.TrimSpace(fmt.Sprint(value)), nil
+	case v.Manifest != "":
+		return readManifest(v.Manifest, filepath.Base(filePath))
+	default:
+		sidecar := v.SidecarPath
+		if sidecar == "" {
+			sidecar = filePath + v.algo.extension
+		}
+
+		return readSidecar(sidecar)
+	}
+}
+
+// readSidecar returns the checksum contained in the given sidecar file. Only the
+// first field of the file is considered, so both bare checksums and files in
+// the "sha256sum" format are accepted.
+func readSidecar(path string) (string, error) {
+	content, err := fs.ReadFullFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the checksum file: %w", err)
+	}
+
+	fields := strings.Fields(string(content))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("%w %q", ErrVerifyChecksumInvalidSidecar, path)
+	}
+
+	return fields[0], nil
+}
+
+// manifestEntry is a line of a checksum manifest.
+type manifestEntry struct {
+	name, sum string
+}
+
+// parseManifest returns the entries of the given checksum manifest, in order.
+// Both the GNU ("checksum  file name") and BSD ("ALGO (file name) = checksum")
+// formats are accepted. Lines which are in neither format are ignored.
+func parseManifest(content []byte) []manifestEntry {
+	var entries []manifestEntry
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if match := bsdChecksumLine.FindStringSubmatch(line); match != nil {
+			entries = append(entries, manifestEntry{name: match[1], sum: match[2]})
+
+			continue
+		}
+
+		sum, name, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+
+		// In the GNU format, the name is prefixed with '*' in binary mode.
+		name = strings.TrimPrefix(strings.TrimLeft(name, " "), "*")
+		entries = append(entries, manifestEntry{name: name, sum: sum})
+	}
+
+	return entries
+}
+
+// readManifest returns the checksum of the given file listed in the given
+// manifest.
+func readManifest(path, fileName string) (string, error) {
+	content, err := fs.ReadFullFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the checksum manifest: %w", err)
+	}
+
+	for _, entry := range parseManifest(content) {
+		if filepath.Base(entry.name) == fileName {
+			return entry.sum, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %q", ErrVerifyChecksumNotInManifest, fileName)
+}
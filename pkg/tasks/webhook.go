@@ -0,0 +1,421 @@
+package tasks
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database"
+	"code.waarp.fr/apps/gateway/gateway/pkg/logging/log"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model/authentication/auth"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils"
+)
+
+const (
+	webhookDefaultMethod      = http.MethodPost
+	webhookDefaultContentType = "application/json"
+	webhookDefaultTimeout     = 30 * time.Second
+	webhookDefaultRetryDelay  = time.Second
+	webhookDefaultSignMethod  = SignMethodHMACSHA256
+	webhookDefaultSignHeader  = "X-Waarp-Signature"
+	webhookMaxResponseSize    = 1 << 20 // 1 MiB
+)
+
+var (
+	ErrWebhookNoURL              = errors.New("missing webhook URL")
+	ErrWebhookInvalidMethod      = errors.New("invalid webhook HTTP method")
+	ErrWebhookInvalidBody        = errors.New("the webhook body is not valid JSON")
+	ErrWebhookInvalidAttempts    = errors.New("the webhook number of attempts must be at least 1")
+	ErrWebhookIncompleteTLS      = errors.New(`the "tlsPartner", "tlsAccount" & "tlsCertificate" arguments must all be provided`)
+	ErrWebhookCertNotFound       = errors.New("webhook TLS certificate not found")
+	ErrWebhookNotHMACMethod      = errors.New("the webhook signature method must be an HMAC method")
+	ErrWebhookSignKeyNotFound    = errors.New("webhook signature key not found")
+	ErrWebhookUnexpectedStatus   = errors.New("unexpected webhook response status")
+	ErrWebhookInvalidResponse    = errors.New("the webhook response is not valid JSON")
+	ErrWebhookResponseInfoNotStr = errors.New("the webhook response info mapping values must be strings")
+)
+
+//nolint:gochecknoglobals //global var is needed here for the list of methods
+var webhookMethods = []string{
+	http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+}
+
+// webhookTask is a task which sends an HTTP(S) request to a given URL. The
+// request body can contain substitution variables, and the values of the
+// response (if it is a JSON object) can be copied to the transfer info.
+type webhookTask struct {
+	URL          string       `json:"url"`
+	Method       string       `json:"method"`
+	Headers      jsonObject   `json:"headers"`
+	Body         string       `json:"body"`
+	ContentType  string       `json:"contentType"`
+	Timeout      jsonDuration `json:"timeout"`
+	NbOfAttempts jsonInt      `json:"nbOfAttempts"`
+	RetryDelay   jsonDuration `json:"retryDelay"`
+	ResponseInfo jsonObject   `json:"responseInfo"`
+
+	// TLS client authentication
+	TLSPartner     string `json:"tlsPartner"`
+	TLSAccount     string `json:"tlsAccount"`
+	TLSCertificate string `json:"tlsCertificate"`
+
+	// HMAC request signature
+	SignKey    string `json:"signKey"`
+	SignMethod string `json:"signMethod"`
+	SignHeader string `json:"signHeader"`
+
+	clientCert *tls.Certificate
+	sign       signFunc
+}
+
+func (w *webhookTask) ValidateDB(db database.ReadAccess, args map[string]string) error {
+	*w = webhookTask{}
+
+	if err := utils.JSONConvert(args, w); err != nil {
+		return fmt.Errorf("failed to parse the webhook arguments: %w", err)
+	}
+
+	if w.URL == "" {
+		return ErrWebhookNoURL
+	}
+
+	if err := w.setDefaults(); err != nil {
+		return err
+	}
+
+	for _, path := range w.ResponseInfo {
+		if _, ok := path.(string); !ok {
+			return ErrWebhookResponseInfoNotStr
+		}
+	}
+
+	if err := w.loadClientCert(db); err != nil {
+		return err
+	}
+
+	return w.loadSignKey(db)
+}
+
+func (w *webhookTask) setDefaults() error {
+	w.Method = strings.ToUpper(w.Method)
+	if w.Method == "" {
+		w.Method = webhookDefaultMethod
+	} else if !slices.Contains(webhookMethods, w.Method) {
+		return fmt.Errorf("%w %q", ErrWebhookInvalidMethod, w.Method)
+	}
+
+	if w.ContentType == "" {
+		w.ContentType = webhookDefaultContentType
+	}
+
+	if w.Body != "" && strings.HasSuffix(w.ContentType, "json") && !json.Valid([]byte(w.Body)) {
+		return ErrWebhookInvalidBody
+	}
+
+	if w.Timeout.Duration == 0 {
+		w.Timeout.Duration = webhookDefaultTimeout
+	}
+
+	switch {
+	case w.NbOfAttempts == 0:
+		w.NbOfAttempts = 1
+	case w.NbOfAttempts < 0:
+		return ErrWebhookInvalidAttempts
+	}
+
+	if w.RetryDelay.Duration == 0 {
+		w.RetryDelay.Duration = webhookDefaultRetryDelay
+	}
+
+	if w.SignMethod == "" {
+		w.SignMethod = webhookDefaultSignMethod
+	}
+
+	if w.SignHeader == "" {
+		w.SignHeader = webhookDefaultSignHeader
+	}
+
+	return nil
+}
+
+// loadClientCert retrieves the TLS certificate (if any) used to authenticate
+// the request. That certificate must be one of the given partner account's
+// credentials.
+func (w *webhookTask) loadClientCert(db database.ReadAccess) error {
+	if w.TLSPartner == "" && w.TLSAccount == "" && w.TLSCertificate == "" {
+		return nil
+	}
+
+	if w.TLSPartner == "" || w.TLSAccount == "" || w.TLSCertificate == "" {
+		return ErrWebhookIncompleteTLS
+	}
+
+	var (
+		partner model.RemoteAgent
+		account model.RemoteAccount
+		cred    model.Credential
+	)
+
+	if err := db.Get(&partner, "name=?", w.TLSPartner).Owner().Run(); err != nil {
+		return fmt.Errorf("failed to retrieve partner %q: %w", w.TLSPartner, err)
+	}
+
+	if err := db.Get(&account, "login=? AND remote_agent_id=?", w.TLSAccount,
+		partner.ID).Run(); err != nil {
+		return fmt.Errorf("failed to retrieve account %q: %w", w.TLSAccount, err)
+	}
+
+	if err := db.Get(&cred, "name=? AND type=? AND remote_account_id=?", w.TLSCertificate,
+		auth.TLSCertificate, account.ID).Run(); database.IsNotFound(err) {
+		return fmt.Errorf("%w: %q", ErrWebhookCertNotFound, w.TLSCertificate)
+	} else if err != nil {
+		return fmt.Errorf("failed to retrieve TLS certificate %q: %w", w.TLSCertificate, err)
+	}
+
+	if err := cred.ResolveSecrets(); err != nil {
+		return fmt.Errorf("failed to resolve TLS certificate: %w", err)
+	}
+
+	cert, err := tls.X509KeyPair([]byte(cred.Value), []byte(cred.Value2))
+	if err != nil {
+		return fmt.Errorf("failed to parse TLS certificate %q: %w", w.TLSCertificate, err)
+	}
+
+	w.clientCert = &cert
+
+	return nil
+}
+
+// loadSignKey retrieves the HMAC key (if any) used to sign the request body.
+func (w *webhookTask) loadSignKey(db database.ReadAccess) error {
+	if w.SignKey == "" {
+		return nil
+	}
+
+	method, ok := SignMethods.Get(w.SignMethod)
+	if !ok {
+		return fmt.Errorf("%w %q", ErrSignUnknownMethod, w.SignMethod)
+	}
+
+	if !slices.Contains(method.KeyTypes, model.CryptoKeyTypeHMAC) {
+		return fmt.Errorf("%w (got %q)", ErrWebhookNotHMACMethod, w.SignMethod)
+	}
+
+	var cryptoKey model.CryptoKey
+	if err := db.Get(&cryptoKey, "name=?", w.SignKey).Owner().Run(); database.IsNotFound(err) {
+		return fmt.Errorf("%w: %q", ErrWebhookSignKeyNotFound, w.SignKey)
+	} else if err != nil {
+		return fmt.Errorf("failed to retrieve signature key from database: %w", err)
+	}
+
+	if err := cryptoKey.ResolveSecrets(); err != nil {
+		return fmt.Errorf("failed to resolve signature key: %w", err)
+	}
+
+	var err error
+	if w.sign, err = method.mkSigner(&cryptoKey); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (w *webhookTask) Run(ctx context.Context, args map[string]string, db *database.DB,
+	logger *log.Logger, transCtx *model.TransferContext, _ any,
+) error {
+	if err := w.ValidateDB(db, args); err != nil {
+		logger.Error(err.Error())
+
+		return err
+	}
+
+	client, err := w.makeClient(db, logger)
+	if err != nil {
+		return err
+	}
+
+	var signature string
+
+	if w.sign != nil {
+		sig, sigErr := w.sign(strings.NewReader(w.Body))
+		if sigErr != nil {
+			logger.Errorf("Failed to sign the webhook request: %v", sigErr)
+
+			return fmt.Errorf("failed to sign the webhook request: %w", sigErr)
+		}
+
+		signature = hex.EncodeToString(sig)
+	}
+
+	delay := w.RetryDelay.Duration
+
+	for attempt := 1; ; attempt++ {
+		resp, retry, reqErr := w.send(ctx, client, signature)
+		if reqErr == nil {
+			return w.mapResponse(logger, transCtx, resp)
+		}
+
+		if !retry || attempt >= int(w.NbOfAttempts) {
+			logger.Errorf("Webhook request to %q failed: %v", w.URL, reqErr)
+
+			return reqErr
+		}
+
+		logger.Warningf("Webhook request to %q failed (attempt %d/%d): %v", w.URL,
+			attempt, w.NbOfAttempts, reqErr)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("webhook request interrupted: %w", ctx.Err())
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+	}
+}
+
+func (w *webhookTask) makeClient(db database.ReadAccess, logger *log.Logger) (*http.Client, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if err := auth.AddTLSAuthorities(db, tlsConfig); err != nil {
+		logger.Warningf("Failed to add TLS authorities: %v", err)
+	}
+
+	if w.clientCert != nil {
+		tlsConfig.Certificates = []tls.Certificate{*w.clientCert}
+	}
+
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		transport = &http.Transport{}
+	}
+
+	transport = transport.Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &http.Client{Transport: transport, Timeout: w.Timeout.Duration}, nil
+}
+
+// send sends the webhook request, and returns the response body. If the request
+// failed, the returned boolean indicates whether the request can be retried.
+func (w *webhookTask) send(ctx context.Context, client *http.Client, signature string,
+) ([]byte, bool, error) {
+	var body io.Reader
+	if w.Body != "" {
+		body = strings.NewReader(w.Body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, w.Method, w.URL, body)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to make the webhook request: %w", err)
+	}
+
+	if w.Body != "" {
+		req.Header.Set("Content-Type", w.ContentType)
+	}
+
+	for name, value := range w.Headers {
+		req.Header.Set(name, fmt.Sprint(value))
+	}
+
+	if signature != "" {
+		req.Header.Set(w.SignHeader, signature)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, ctx.Err() == nil, fmt.Errorf("webhook request failed: %w", err)
+	}
+
+	defer resp.Body.Close() //nolint:errcheck //error is irrelevant here
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, webhookMaxResponseSize))
+	if err != nil {
+		return nil, true, fmt.Errorf("failed to read the webhook response: %w", err)
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		retry := resp.StatusCode >= http.StatusInternalServerError ||
+			resp.StatusCode == http.StatusTooManyRequests ||
+			resp.StatusCode == http.StatusRequestTimeout
+
+		return nil, retry, fmt.Errorf("%w %q: %s", ErrWebhookUnexpectedStatus, resp.Status,
+			bytes.TrimSpace(content))
+	}
+
+	return content, false, nil
+}
+
+// mapResponse copies the values of the response designated by the task's
+// "responseInfo" argument into the transfer info. The mapping associates the
+// transfer info keys with the (dot-separated) paths of the values in the
+// response JSON object.
+func (w *webhookTask) mapResponse(logger *log.Logger, transCtx *model.TransferContext,
+	content []byte,
+) error {
+	logger.Debugf("Webhook request to %q succeeded", w.URL)
+
+	if len(w.ResponseInfo) == 0 {
+		return nil
+	}
+
+	var response any
+	if err := json.Unmarshal(content, &response); err != nil {
+		logger.Errorf("Failed to parse the webhook response: %v", err)
+
+		return fmt.Errorf("%w: %w", ErrWebhookInvalidResponse, err)
+	}
+
+	for key, path := range w.ResponseInfo {
+		//nolint:forcetypeassert //type has already been checked during validation
+		value, ok := lookupJSONPath(response, path.(string))
+		if !ok {
+			logger.Warningf("Webhook response does not contain the value %q", path)
+
+			continue
+		}
+
+		setTransferInfo(logger, transCtx, Webhook, key, value)
+	}
+
+	return nil
+}
+
+// lookupJSONPath returns the value at the given dot-separated path in the given
+// JSON value. Non-string values are returned in their JSON form.
+func lookupJSONPath(value any, path string) (string, bool) {
+	for _, elem := range strings.Split(path, ".") {
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return "", false
+		}
+
+		if value, ok = obj[elem]; !ok {
+			return "", false
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case nil:
+		return "", true
+	default:
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return "", false
+		}
+
+		return string(raw), true
+	}
+}
@@ -0,0 +1,148 @@
+package tasks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.waarp.fr/apps/gateway/gateway/pkg/database/dbtest"
+	"code.waarp.fr/apps/gateway/gateway/pkg/model"
+	"code.waarp.fr/apps/gateway/gateway/pkg/utils/testhelpers"
+)
+
+func TestWebhookValidate(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.TestDatabase(t)
+
+	for name, test := range map[string]struct {
+		args map[string]string
+		err  error
+	}{
+		"Missing URL": {
+			args: map[string]string{},
+			err:  ErrWebhookNoURL,
+		},
+		"Invalid method": {
+			args: map[string]string{"url": "http://localhost", "method": "FOO"},
+			err:  ErrWebhookInvalidMethod,
+		},
+		"Invalid JSON body": {
+			args: map[string]string{"url": "http://localhost", "body": `{"foo":`},
+			err:  ErrWebhookInvalidBody,
+		},
+		"Incomplete TLS credentials": {
+			args: map[string]string{"url": "https://localhost", "tlsPartner": "partner"},
+			err:  ErrWebhookIncompleteTLS,
+		},
+		"Unknown signature key": {
+			args: map[string]string{"url": "http://localhost", "signKey": "unknown"},
+			err:  ErrWebhookSignKeyNotFound,
+		},
+		"Valid": {
+			args: map[string]string{
+				"url": "http://localhost", "method": "put", "body": `{"foo":"bar"}`,
+				"headers": `{"Authorization":"Bearer token"}`, "nbOfAttempts": "3",
+				"retryDelay": "10s", "timeout": "1m", "responseInfo": `{"ticket":"data.id"}`,
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := (&webhookTask{}).ValidateDB(db, test.args)
+			if test.err == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestWebhookRun(t *testing.T) {
+	t.Parallel()
+
+	const (
+		key  = "0123456789ABCDEF"
+		body = `{"file":"file.txt","rule":"rule"}`
+	)
+
+	logger := testhelpers.GetTestLogger(t)
+	db := dbtest.TestDatabase(t)
+
+	hmacKey := model.CryptoKey{Name: "hmac-key", Type: model.CryptoKeyTypeHMAC, Key: key}
+	require.NoError(t, db.Insert(&hmacKey).Run())
+
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(body))
+	expectedSig := hex.EncodeToString(mac.Sum(nil))
+
+	var count atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if count.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		content, err := io.ReadAll(r.Body)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, body, string(content))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.Equal(t, expectedSig, r.Header.Get("X-Waarp-Signature"))
+
+		_, _ = w.Write([]byte(`{"data":{"id":"ticket-123","count":2}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	transCtx := &model.TransferContext{Transfer: &model.Transfer{}}
+	args := map[string]string{
+		"url":          server.URL,
+		"method":       http.MethodPut,
+		"headers":      `{"Authorization":"Bearer token"}`,
+		"body":         body,
+		"signKey":      hmacKey.Name,
+		"nbOfAttempts": "2",
+		"retryDelay":   "1ms",
+		"responseInfo": `{"ticket":"data.id","count":"data.count","missing":"data.foo"}`,
+	}
+
+	require.NoError(t, (&webhookTask{}).Run(t.Context(), args, db, logger, transCtx, nil))
+	assert.Equal(t, int32(2), count.Load(), "the request should have been retried once")
+	assert.Equal(t, map[string]any{"ticket": "ticket-123", "count": "2"},
+		transCtx.Transfer.TransferInfo)
+
+	t.Run("Given a non-retryable error", func(t *testing.T) {
+		t.Parallel()
+
+		var failures atomic.Int32
+
+		failServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			failures.Add(1)
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		t.Cleanup(failServer.Close)
+
+		failArgs := map[string]string{"url": failServer.URL, "nbOfAttempts": "3", "retryDelay": "1ms"}
+
+		err := (&webhookTask{}).Run(t.Context(), failArgs, db, logger,
+			&model.TransferContext{Transfer: &model.Transfer{}}, nil)
+		require.ErrorIs(t, err, ErrWebhookUnexpectedStatus)
+		assert.Equal(t, int32(1), failures.Load(), "the request should not have been retried")
+	})
+}
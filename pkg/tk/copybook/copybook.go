@@ -0,0 +1,588 @@
+// Package copybook contains a parser for COBOL copybooks, which describe the
+// layout of mainframe records, along with a decoder for the fields of those
+// records.
+package copybook
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrEmpty              = errors.New("the copybook does not declare any field")
+	ErrSyntax             = errors.New("syntax error")
+	ErrUnsupported        = errors.New("unsupported declaration")
+	ErrInvalidPicture     = errors.New("invalid picture")
+	ErrInvalidData        = errors.New("invalid field data")
+	ErrRedefinesNotFound  = errors.New("redefined item not found")
+	errUnterminatedClause = errors.New("unterminated clause")
+)
+
+// Usage is the storage format of a numeric field.
+type Usage int
+
+const (
+	// UsageDisplay is the default storage format: one character per digit
+	// (zoned decimal for numeric fields).
+	UsageDisplay Usage = iota
+	// UsagePacked is the packed decimal format (COMP-3): two digits per byte,
+	// with the sign in the last half-byte.
+	UsagePacked
+	// UsageBinary is the big-endian binary format (COMP, COMP-4, COMP-5).
+	UsageBinary
+)
+
+// Field is an elementary item of a record layout.
+type Field struct {
+	// Name is the name of the field. For fields inside an OCCURS clause, the
+	// name is suffixed with the index of the occurrence (starting at 1).
+	Name   string
+	Offset int
+	Length int
+
+	Numeric bool
+	Usage   Usage
+	Digits  int
+	Scale   int
+	Signed  bool
+
+	// SignSeparate indicates that the sign of a DISPLAY numeric field is stored
+	// in its own character, either before or after the digits.
+	SignSeparate bool
+	SignLeading  bool
+}
+
+// Layout is the layout of a record, as described by a copybook. The fields
+// are listed in the order of the record, without the FILLER items.
+type Layout struct {
+	Fields []*Field
+	Length int
+}
+
+// item is a node of the copybook's items tree.
+type item struct {
+	level     int
+	name      string
+	picture   *picture
+	usage     Usage
+	hasUsage  bool
+	occurs    int
+	redefines string
+	signSep   bool
+	signLead  bool
+	children  []*item
+}
+
+func (i *item) isFiller() bool { return i.name == "" || strings.EqualFold(i.name, "FILLER") }
+
+// Parse parses the given copybook and returns the layout of its first record.
+// Only the subset of COBOL needed to describe data is supported: level numbers,
+// PICTURE, USAGE, OCCURS, REDEFINES and SIGN clauses. The other clauses are
+// ignored.
+func Parse(src string) (*Layout, error) {
+	statements := splitStatements(src)
+
+	var (
+		roots []*item
+		stack []*item
+	)
+
+	for _, stmt := range statements {
+		it, err := parseStatement(stmt)
+		if err != nil {
+			return nil, err
+		}
+
+		if it == nil {
+			continue
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].level >= it.level {
+			stack = stack[:len(stack)-1]
+		}
+
+		if len(stack) == 0 {
+			roots = append(roots, it)
+		} else {
+			parent := stack[len(stack)-1]
+			if parent.picture != nil {
+				return nil, fmt.Errorf("%w: elementary item %q cannot have sub-items",
+					ErrSyntax, parent.name)
+			}
+
+			if parent.hasUsage && !it.hasUsage {
+				it.usage, it.hasUsage = parent.usage, true
+			}
+
+			parent.children = append(parent.children, it)
+		}
+
+		stack = append(stack, it)
+	}
+
+	if len(roots) == 0 {
+		return nil, ErrEmpty
+	}
+
+	// When the copybook declares records (01 levels), only the first one is
+	// used, the others being alternative layouts of the same data. Otherwise,
+	// the top-level items are the record's fields.
+	record := &item{children: roots}
+	if roots[0].level == 1 {
+		record = roots[0]
+	}
+
+	layout := &Layout{}
+	if err := layout.add(record, 0, ""); err != nil {
+		return nil, err
+	}
+
+	if len(layout.Fields) == 0 {
+		return nil, ErrEmpty
+	}
+
+	return layout, nil
+}
+
+// add adds the given record item (and its children) to the layout, and sets
+// the layout's length.
+func (l *Layout) add(it *item, offset int, suffix string) error {
+	size, err := l.addItem(it, offset, suffix)
+	if err != nil {
+		return err
+	}
+
+	l.Length = offset + size
+
+	return nil
+}
+
+func (l *Layout) addItem(it *item, offset int, suffix string) (int, error) {
+	occurs := max(it.occurs, 1)
+	total := 0
+
+	for occ := 1; occ <= occurs; occ++ {
+		occSuffix := suffix
+		if it.occurs > 0 {
+			occSuffix = fmt.Sprintf("%s_%d", suffix, occ)
+		}
+
+		size, err := l.addOccurrence(it, offset+total, occSuffix)
+		if err != nil {
+			return 0, err
+		}
+
+		total += size
+	}
+
+	return total, nil
+}
+
+func (l *Layout) addOccurrence(it *item, offset int, suffix string) (int, error) {
+	if it.picture == nil {
+		return l.addGroup(it, offset, suffix)
+	}
+
+	field, err := it.field(offset)
+	if err != nil {
+		return 0, err
+	}
+
+	if !it.isFiller() {
+		field.Name += suffix
+		l.Fields = append(l.Fields, field)
+	}
+
+	return field.Length, nil
+}
+
+func (l *Layout) addGroup(it *item, offset int, suffix string) (int, error) {
+	size := 0
+	declared := map[string]bool{}
+
+	for _, child := range it.children {
+		if child.redefines != "" {
+			// A redefinition shares the space of the redefined item, so it is
+			// not part of the layout (only the first description is used).
+			if !declared[strings.ToUpper(child.redefines)] {
+				return 0, fmt.Errorf("%w: %q", ErrRedefinesNotFound, child.redefines)
+			}
+
+			continue
+		}
+
+		childSize, err := l.addItem(child, offset+size, suffix)
+		if err != nil {
+			return 0, err
+		}
+
+		declared[strings.ToUpper(child.name)] = true
+		size += childSize
+	}
+
+	return size, nil
+}
+
+func (i *item) field(offset int) (*Field, error) {
+	pic := i.picture
+	field := &Field{
+		Name:    i.name,
+		Offset:  offset,
+		Numeric: pic.numeric,
+		Usage:   i.usage,
+		Digits:  pic.digits,
+		Scale:   pic.scale,
+		Signed:  pic.signed,
+	}
+
+	if !pic.numeric {
+		if i.usage != UsageDisplay {
+			return nil, fmt.Errorf("%w: non-numeric item %q must have a DISPLAY usage",
+				ErrUnsupported, i.name)
+		}
+
+		field.Length = pic.length
+
+		return field, nil
+	}
+
+	switch i.usage {
+	case UsageDisplay:
+		field.Length = pic.digits
+		if i.signSep && pic.signed {
+			field.SignSeparate, field.SignLeading = true, i.signLead
+			field.Length++
+		}
+	case UsagePacked:
+		field.Length = pic.digits/2 + 1 //nolint:mnd //2 digits per byte, plus the sign
+	case UsageBinary:
+		const maxBinaryDigits = 18
+
+		switch {
+		case pic.digits <= 4: //nolint:mnd //COBOL binary sizes
+			field.Length = 2
+		case pic.digits <= 9: //nolint:mnd //COBOL binary sizes
+			field.Length = 4
+		case pic.digits <= maxBinaryDigits:
+			field.Length = 8
+		default:
+			return nil, fmt.Errorf("%w: binary item %q has too many digits", ErrUnsupported, i.name)
+		}
+	}
+
+	return field, nil
+}
+
+// splitStatements removes the comments and the sequence & indicator areas
+// from the copybook, and splits it into statements (separated by periods).
+func splitStatements(src string) []string {
+	const (
+		indicatorCol = 6
+		textEndCol   = 72
+	)
+
+	var text strings.Builder
+
+	for line := range strings.Lines(src) {
+		line = strings.TrimRight(line, "\r\n")
+
+		if isFixedFormatLine(line) {
+			switch line[indicatorCol] {
+			case '*', '/':
+				continue
+			}
+
+			line = line[indicatorCol+1 : min(len(line), textEndCol)]
+		}
+
+		if idx := strings.Index(line, "*>"); idx >= 0 {
+			line = line[:idx]
+		}
+
+		text.WriteString(line)
+		text.WriteByte(' ')
+	}
+
+	var (
+		statements []string
+		current    strings.Builder
+	)
+
+	content := text.String()
+	for i := 0; i < len(content); i++ {
+		// A period ends a statement only when followed by a space (periods
+		// can also appear inside pictures).
+		if content[i] == '.' && (i+1 == len(content) || content[i+1] == ' ') {
+			if stmt := strings.TrimSpace(current.String()); stmt != "" {
+				statements = append(statements, stmt)
+			}
+
+			current.Reset()
+
+			continue
+		}
+
+		current.WriteByte(content[i])
+	}
+
+	if stmt := strings.TrimSpace(current.String()); stmt != "" {
+		statements = append(statements, stmt)
+	}
+
+	return statements
+}
+
+// isFixedFormatLine returns whether the line uses the fixed reference format,
+// where the first 6 columns are a sequence number, and the 7th column is an
+// indicator.
+func isFixedFormatLine(line string) bool {
+	const indicatorCol = 6
+
+	if len(line) <= indicatorCol {
+		return false
+	}
+
+	// The sequence area is either blank, or a sequence number.
+	if seq := line[:indicatorCol]; strings.TrimSpace(seq) != "" && strings.Trim(seq, "0123456789") != "" {
+		return false
+	}
+
+	switch line[indicatorCol] {
+	case ' ', '*', '/', '-', 'D', 'd':
+		return true
+	default:
+		return false
+	}
+}
+
+// parseStatement parses a data description entry. It returns nil for the
+// entries which do not describe data (condition names, RENAMES...).
+func parseStatement(stmt string) (*item, error) {
+	const (
+		renamesLevel   = 66
+		standaloneLvl  = 77
+		conditionLevel = 88
+		maxLevel       = 49
+	)
+
+	tokens := tokenize(stmt)
+
+	level, err := strconv.Atoi(tokens[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: expected a level number, got %q", ErrSyntax, tokens[0])
+	}
+
+	switch {
+	case level == renamesLevel || level == conditionLevel:
+		return nil, nil //nolint:nilnil //these entries are ignored
+	case level == standaloneLvl:
+		level = 1
+	case level < 1 || level > maxLevel:
+		return nil, fmt.Errorf("%w: invalid level number %d", ErrSyntax, level)
+	}
+
+	it := &item{level: level}
+	tokens = tokens[1:]
+
+	if len(tokens) > 0 && !isClauseKeyword(tokens[0]) {
+		it.name, tokens = tokens[0], tokens[1:]
+	}
+
+	if err := it.parseClauses(tokens); err != nil {
+		return nil, fmt.Errorf("item %q: %w", it.name, err)
+	}
+
+	return it, nil
+}
+
+//nolint:funlen,gocyclo,cyclop //splitting the clauses parsing would be less readable
+func (i *item) parseClauses(tokens []string) error {
+	next := func() (string, error) {
+		if len(tokens) == 0 {
+			return "", errUnterminatedClause
+		}
+
+		tok := tokens[0]
+		tokens = tokens[1:]
+
+		return tok, nil
+	}
+
+	skipIf := func(words ...string) {
+		for len(tokens) > 0 && isOneOf(tokens[0], words...) {
+			tokens = tokens[1:]
+		}
+	}
+
+	for len(tokens) > 0 {
+		tok, _ := next()
+
+		switch upper := strings.ToUpper(tok); upper {
+		case "PIC", "PICTURE":
+			skipIf("IS")
+
+			str, err := next()
+			if err != nil {
+				return err
+			}
+
+			if i.picture, err = parsePicture(str); err != nil {
+				return err
+			}
+		case "USAGE":
+			skipIf("IS")
+
+			str, err := next()
+			if err != nil {
+				return err
+			}
+
+			if err := i.setUsage(str); err != nil {
+				return err
+			}
+		case "OCCURS":
+			str, err := next()
+			if err != nil {
+				return err
+			}
+
+			if i.occurs, err = strconv.Atoi(str); err != nil {
+				return fmt.Errorf("%w: invalid OCCURS count %q", ErrSyntax, str)
+			}
+
+			// With "OCCURS n TO m", the maximum number of occurrences is used.
+			if len(tokens) > 1 && isOneOf(tokens[0], "TO") {
+				if i.occurs, err = strconv.Atoi(tokens[1]); err != nil {
+					return fmt.Errorf("%w: invalid OCCURS count %q", ErrSyntax, tokens[1])
+				}
+
+				tokens = tokens[2:]
+			}
+
+			skipIf("TIMES")
+		case "DEPENDING":
+			skipIf("ON")
+
+			if _, err := next(); err != nil {
+				return err
+			}
+		case "REDEFINES":
+			str, err := next()
+			if err != nil {
+				return err
+			}
+
+			i.redefines = str
+		case "SIGN":
+			skipIf("IS")
+
+			i.signLead = len(tokens) > 0 && isOneOf(tokens[0], "LEADING")
+			skipIf("LEADING", "TRAILING")
+
+			if len(tokens) > 0 && isOneOf(tokens[0], "SEPARATE") {
+				i.signSep = true
+				skipIf("SEPARATE", "CHARACTER")
+			}
+		case "VALUE", "VALUES":
+			skipIf("IS", "ARE")
+			tokens = skipLiteral(tokens)
+		default:
+			if isUsageKeyword(upper) {
+				if err := i.setUsage(upper); err != nil {
+					return err
+				}
+			}
+			// The other clauses (JUSTIFIED, SYNCHRONIZED, BLANK WHEN ZERO,
+			// INDEXED BY...) do not change the layout of the data.
+		}
+	}
+
+	return nil
+}
+
+func (i *item) setUsage(usage string) error {
+	switch strings.ToUpper(usage) {
+	case "DISPLAY":
+		i.usage = UsageDisplay
+	case "COMP-3", "COMPUTATIONAL-3", "PACKED-DECIMAL":
+		i.usage = UsagePacked
+	case "COMP", "COMPUTATIONAL", "COMP-4", "COMPUTATIONAL-4", "COMP-5", "COMPUTATIONAL-5", "BINARY":
+		i.usage = UsageBinary
+	default:
+		return fmt.Errorf("%w: usage %q", ErrUnsupported, usage)
+	}
+
+	i.hasUsage = true
+
+	return nil
+}
+
+// tokenize splits the statement into words, keeping the quoted literals as
+// single tokens.
+func tokenize(stmt string) []string {
+	var (
+		tokens []string
+		quote  rune
+		cur    strings.Builder
+	)
+
+	for _, c := range stmt {
+		switch {
+		case quote != 0:
+			cur.WriteRune(c)
+
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+			cur.WriteRune(c)
+		case c == ' ' || c == '\t' || c == ',' || c == ';':
+			if cur.Len() > 0 {
+				tokens = append(tokens, cur.String())
+				cur.Reset()
+			}
+		default:
+			cur.WriteRune(c)
+		}
+	}
+
+	if cur.Len() > 0 {
+		tokens = append(tokens, cur.String())
+	}
+
+	return tokens
+}
+
+// skipLiteral skips the literal(s) of a VALUE clause.
+func skipLiteral(tokens []string) []string {
+	for len(tokens) > 0 && !isClauseKeyword(tokens[0]) {
+		tokens = tokens[1:]
+	}
+
+	return tokens
+}
+
+func isClauseKeyword(tok string) bool {
+	return isOneOf(tok, "PIC", "PICTURE", "USAGE", "OCCURS", "REDEFINES", "SIGN", "VALUE",
+		"VALUES", "JUSTIFIED", "JUST", "SYNC", "SYNCHRONIZED", "BLANK", "GLOBAL", "EXTERNAL",
+		"INDEXED", "ASCENDING", "DESCENDING", "DEPENDING") || isUsageKeyword(tok)
+}
+
+func isUsageKeyword(tok string) bool {
+	return isOneOf(tok, "DISPLAY", "COMP", "COMPUTATIONAL", "COMP-3", "COMPUTATIONAL-3",
+		"PACKED-DECIMAL", "COMP-4", "COMPUTATIONAL-4", "COMP-5", "COMPUTATIONAL-5", "BINARY",
+		"COMP-1", "COMPUTATIONAL-1", "COMP-2", "COMPUTATIONAL-2")
+}
+
+func isOneOf(tok string, words ...string) bool {
+	for _, word := range words {
+		if strings.EqualFold(tok, word) {
+			return true
+		}
+	}
+
+	return false
+}
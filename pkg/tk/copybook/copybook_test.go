@@ -0,0 +1,154 @@
+package copybook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/charmap"
+)
+
+const testCopybook = `
+000100* Customer record
+000200 01  CUSTOMER-REC.
+000300     05  CUST-ID          PIC 9(6).
+000400     05  CUST-NAME        PIC X(10).
+000500     05  CUST-BALANCE     PIC S9(5)V99 COMP-3.
+000600     05  FILLER           PIC X(2).
+000700     05  CUST-ORDERS      OCCURS 2 TIMES.
+000800         10  ORDER-QTY    PIC S9(4) COMP.
+000900         10  ORDER-AMOUNT PIC S9(3)V9 SIGN IS TRAILING.
+001000     05  CUST-STATUS      PIC X.
+001100         88  ACTIVE       VALUE 'A'.
+001200     05  CUST-ALT REDEFINES CUST-STATUS PIC 9.
+001300 01  OTHER-REC.
+001400     05  OTHER-FIELD      PIC X(80).
+`
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	layout, err := Parse(testCopybook)
+	require.NoError(t, err)
+
+	assert.Equal(t, 6+10+4+2+2*(2+4)+1, layout.Length)
+	assert.Equal(t, []*Field{
+		{Name: "CUST-ID", Offset: 0, Length: 6, Numeric: true, Digits: 6},
+		{Name: "CUST-NAME", Offset: 6, Length: 10},
+		{
+			Name: "CUST-BALANCE", Offset: 16, Length: 4, Numeric: true, Usage: UsagePacked,
+			Digits: 7, Scale: 2, Signed: true,
+		},
+		{
+			Name: "ORDER-QTY_1", Offset: 22, Length: 2, Numeric: true, Usage: UsageBinary,
+			Digits: 4, Signed: true,
+		},
+		{
+			Name: "ORDER-AMOUNT_1", Offset: 24, Length: 4, Numeric: true, Digits: 4,
+			Scale: 1, Signed: true,
+		},
+		{
+			Name: "ORDER-QTY_2", Offset: 28, Length: 2, Numeric: true, Usage: UsageBinary,
+			Digits: 4, Signed: true,
+		},
+		{
+			Name: "ORDER-AMOUNT_2", Offset: 30, Length: 4, Numeric: true, Digits: 4,
+			Scale: 1, Signed: true,
+		},
+		{Name: "CUST-STATUS", Offset: 34, Length: 1},
+	}, layout.Fields)
+}
+
+func TestParseErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		name     string
+		copybook string
+		wantErr  error
+	}{
+		{"Given an empty copybook", "      * nothing here\n", ErrEmpty},
+		{"Given an invalid level", "01 REC. 05 A PIC X. 60 B PIC X.", ErrSyntax},
+		{"Given an invalid picture", "01 REC. 05 A PIC X(0).", ErrInvalidPicture},
+		{"Given a floating point usage", "01 REC. 05 A COMP-1.", ErrUnsupported},
+		{"Given a binary text", "01 REC. 05 A PIC X(2) COMP.", ErrUnsupported},
+		{"Given a sub-item of an elementary item", "01 REC. 05 A PIC X. 10 B PIC X.", ErrSyntax},
+		{"Given an unknown redefinition", "01 REC. 05 A PIC X. 05 B REDEFINES C PIC X.", ErrRedefinesNotFound},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := Parse(test.copybook)
+			require.ErrorIs(t, err, test.wantErr)
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	t.Parallel()
+
+	layout, err := Parse(`
+       01  REC.
+           05  NAME     PIC X(5).
+           05  ZONED    PIC S9(3)V99.
+           05  PACKED   PIC S9(5) COMP-3.
+           05  BIN      PIC S9(9) BINARY.
+           05  UBIN     PIC 9(4) COMP-5.
+           05  SEP      PIC S9(2) SIGN LEADING SEPARATE.
+           05  EDITED   PIC ZZ9.99-.
+`)
+	require.NoError(t, err)
+
+	ebcdic := charmap.CodePage037
+
+	record := []byte{
+		0xC1, 0xC2, 0xC3, 0x40, 0x40, // "ABC  "
+		0xF1, 0xF2, 0xF3, 0xF4, 0xD5, // -123.45
+		0x00, 0x12, 0x3C, // +123
+		0xFF, 0xFF, 0xFF, 0xFE, // -2
+		0xFF, 0xFF, // 65535
+		0x60, 0xF4, 0xF2, // -42
+		0x40, 0xF1, 0xF2, 0x4B, 0xF5, 0xF0, 0x60, // " 12.50-"
+	}
+
+	var values []string
+
+	for _, field := range layout.Fields {
+		val, err := field.Decode(record, ebcdic)
+		require.NoError(t, err, field.Name)
+
+		values = append(values, val)
+	}
+
+	assert.Equal(t, []string{"ABC", "-123.45", "123", "-2", "65535", "-42", " 12.50-"}, values)
+
+	t.Run("Given ASCII zoned numbers", func(t *testing.T) {
+		t.Parallel()
+
+		field := layout.Fields[1]
+
+		val, err := field.Decode([]byte("ABC  1234}"), nil)
+		require.NoError(t, err)
+		assert.Equal(t, "-123.40", val)
+
+		val, err = field.Decode([]byte("ABC  0000A"), nil)
+		require.NoError(t, err)
+		assert.Equal(t, "0.01", val)
+
+		val, err = field.Decode([]byte("ABC       "), nil)
+		require.NoError(t, err)
+		assert.Empty(t, val, "Then blank numbers should be empty")
+	})
+
+	t.Run("Given invalid data", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := layout.Fields[2].Decode([]byte{
+			0xC1, 0xC2, 0xC3, 0x40, 0x40,
+			0xF1, 0xF2, 0xF3, 0xF4, 0xD5,
+			0x00, 0x12, 0x34,
+		}, ebcdic)
+		require.ErrorIs(t, err, ErrInvalidData)
+		assert.ErrorContains(t, err, "invalid packed sign")
+	})
+}
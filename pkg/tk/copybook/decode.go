@@ -0,0 +1,252 @@
+package copybook
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"golang.org/x/text/encoding"
+)
+
+// Decode decodes the value of the field from the given record, and returns it
+// as a string. Text fields are converted from the given character encoding
+// (if nil, the bytes are used as is), and their trailing spaces are removed.
+// Numeric fields are returned as decimal numbers (ex: "-123.45"). Fields which
+// are blank (spaces or null bytes) are returned as an empty string.
+//
+// If the record is too short to contain the field, an empty string is returned.
+func (f *Field) Decode(record []byte, enc encoding.Encoding) (string, error) {
+	if f.Offset >= len(record) {
+		return "", nil
+	}
+
+	end := f.Offset + f.Length
+	if end > len(record) {
+		return "", fmt.Errorf("%w: field %q is truncated", ErrInvalidData, f.Name)
+	}
+
+	data := record[f.Offset:end]
+
+	if !f.Numeric {
+		text, err := decodeText(data, enc)
+		if err != nil {
+			return "", fmt.Errorf("%w: field %q: %w", ErrInvalidData, f.Name, err)
+		}
+
+		return strings.TrimRight(text, " \x00"), nil
+	}
+
+	if isBlank(data) && f.Usage != UsageBinary {
+		return "", nil
+	}
+
+	var (
+		value string
+		err   error
+	)
+
+	switch f.Usage {
+	case UsagePacked:
+		value, err = f.decodePacked(data)
+	case UsageBinary:
+		value = f.decodeBinary(data)
+	default:
+		value, err = f.decodeZoned(data, enc)
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("%w: field %q: %w", ErrInvalidData, f.Name, err)
+	}
+
+	return value, nil
+}
+
+func decodeText(data []byte, enc encoding.Encoding) (string, error) {
+	if enc == nil {
+		return string(data), nil
+	}
+
+	text, err := enc.NewDecoder().Bytes(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode text: %w", err)
+	}
+
+	return string(text), nil
+}
+
+// isBlank returns whether the data only contains spaces (in ASCII or EBCDIC)
+// or null bytes.
+func isBlank(data []byte) bool {
+	const ebcdicSpace = 0x40
+
+	for _, b := range data {
+		if b != ' ' && b != ebcdicSpace && b != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// decodeZoned decodes a zoned decimal number (one digit per byte). The digits
+// are the low half-bytes, which works for both ASCII and EBCDIC. The sign is
+// either stored in a separate character, or "overpunched" on the last digit.
+//
+//nolint:mnd //zoned decimal constants
+func (f *Field) decodeZoned(data []byte, enc encoding.Encoding) (string, error) {
+	negative := false
+
+	if f.SignSeparate {
+		var signByte byte
+		if f.SignLeading {
+			signByte, data = data[0], data[1:]
+		} else {
+			signByte, data = data[len(data)-1], data[:len(data)-1]
+		}
+
+		sign, err := decodeText([]byte{signByte}, enc)
+		if err != nil {
+			return "", err
+		}
+
+		switch sign {
+		case "-":
+			negative = true
+		case "+", " ":
+		default:
+			return "", fmt.Errorf("invalid sign character %q", sign)
+		}
+	}
+
+	digits := make([]byte, len(data))
+
+	for i, b := range data {
+		last := i == len(data)-1 && f.Signed && !f.SignSeparate
+
+		digit, neg, ok := zonedDigit(b, last)
+		if !ok {
+			return "", fmt.Errorf("invalid digit 0x%02X", b)
+		}
+
+		digits[i] = '0' + digit
+		negative = negative || neg
+	}
+
+	return formatDecimal(string(digits), f.Scale, negative), nil
+}
+
+// zonedDigit decodes a zoned digit. The last digit of a signed number can
+// carry the sign, either as an EBCDIC zone (C or F for positive, D for
+// negative), or as an ASCII overpunch character ({, A-I for positive, }, J-R
+// and p-y for negative).
+//
+//nolint:mnd //zoned decimal constants
+func zonedDigit(b byte, last bool) (digit byte, negative, ok bool) {
+	switch {
+	case b >= '0' && b <= '9', b >= 0xF0 && b <= 0xF9:
+		return b & 0x0F, false, true
+	case !last:
+		return 0, false, false
+	case b >= 0xC0 && b <= 0xC9, b >= 0xA0 && b <= 0xA9, b >= 0xE0 && b <= 0xE9:
+		return b & 0x0F, false, true
+	case b >= 0xD0 && b <= 0xD9, b >= 0xB0 && b <= 0xB9:
+		return b & 0x0F, true, true
+	case b == '{':
+		return 0, false, true
+	case b >= 'A' && b <= 'I':
+		return b - 'A' + 1, false, true
+	case b == '}':
+		return 0, true, true
+	case b >= 'J' && b <= 'R':
+		return b - 'J' + 1, true, true
+	case b >= 'p' && b <= 'y':
+		return b - 'p', true, true
+	default:
+		return 0, false, false
+	}
+}
+
+// decodePacked decodes a packed decimal number (COMP-3): each byte contains
+// 2 digits, except the last one which contains a digit and the sign.
+//
+//nolint:mnd //packed decimal constants
+func (f *Field) decodePacked(data []byte) (string, error) {
+	digits := make([]byte, 0, len(data)*2)
+
+	for i, b := range data {
+		high, low := b>>4, b&0x0F
+
+		if high > 9 {
+			return "", fmt.Errorf("invalid packed digit 0x%X", high)
+		}
+
+		digits = append(digits, '0'+high)
+
+		if i < len(data)-1 {
+			if low > 9 {
+				return "", fmt.Errorf("invalid packed digit 0x%X", low)
+			}
+
+			digits = append(digits, '0'+low)
+
+			continue
+		}
+
+		switch low {
+		case 0x0C, 0x0F, 0x0A, 0x0E:
+			return formatDecimal(string(digits), f.Scale, false), nil
+		case 0x0D, 0x0B:
+			return formatDecimal(string(digits), f.Scale, true), nil
+		default:
+			return "", fmt.Errorf("invalid packed sign 0x%X", low)
+		}
+	}
+
+	return "", nil
+}
+
+// decodeBinary decodes a big-endian binary number.
+func (f *Field) decodeBinary(data []byte) string {
+	var value *big.Int
+
+	switch {
+	case !f.Signed:
+		value = new(big.Int).SetBytes(data)
+	case len(data) == 2: //nolint:mnd //16-bits integer
+		value = big.NewInt(int64(int16(binary.BigEndian.Uint16(data)))) //nolint:gosec //two's complement
+	case len(data) == 4: //nolint:mnd //32-bits integer
+		value = big.NewInt(int64(int32(binary.BigEndian.Uint32(data)))) //nolint:gosec //two's complement
+	default:
+		value = big.NewInt(int64(binary.BigEndian.Uint64(data))) //nolint:gosec //two's complement
+	}
+
+	digits := value.String()
+	negative := strings.HasPrefix(digits, "-")
+
+	return formatDecimal(strings.TrimPrefix(digits, "-"), f.Scale, negative)
+}
+
+// formatDecimal formats the given digits as a decimal number with the given
+// number of decimal places.
+func formatDecimal(digits string, scale int, negative bool) string {
+	if len(digits) <= scale {
+		digits = strings.Repeat("0", scale-len(digits)+1) + digits
+	}
+
+	intPart := strings.TrimLeft(digits[:len(digits)-scale], "0")
+	if intPart == "" {
+		intPart = "0"
+	}
+
+	result := intPart
+	if scale > 0 {
+		result += "." + digits[len(digits)-scale:]
+	}
+
+	if negative && strings.Trim(digits, "0") != "" {
+		result = "-" + result
+	}
+
+	return result
+}
@@ -0,0 +1,112 @@
+package copybook
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// picture is a parsed PICTURE clause.
+type picture struct {
+	numeric bool
+	digits  int
+	scale   int
+	signed  bool
+	length  int
+}
+
+// parsePicture parses the given PICTURE character string. Numeric pictures
+// (made of 9, S and V) are decoded as numbers; alphanumeric and edited
+// pictures are decoded as text.
+func parsePicture(str string) (*picture, error) {
+	symbols, err := expandPicture(str)
+	if err != nil {
+		return nil, err
+	}
+
+	pic := &picture{numeric: true}
+	afterPoint := false
+
+	for i, c := range symbols {
+		switch c {
+		case '9':
+			pic.digits++
+			if afterPoint {
+				pic.scale++
+			}
+		case 'S':
+			if i != 0 {
+				return nil, fmt.Errorf("%w %q: S must be the first symbol", ErrInvalidPicture, str)
+			}
+
+			pic.signed = true
+		case 'V':
+			if afterPoint {
+				return nil, fmt.Errorf("%w %q: V can only appear once", ErrInvalidPicture, str)
+			}
+
+			afterPoint = true
+		case 'P':
+			return nil, fmt.Errorf("%w: scaling positions in picture %q", ErrUnsupported, str)
+		default:
+			pic.numeric = false
+		}
+	}
+
+	if pic.numeric {
+		if pic.digits == 0 {
+			return nil, fmt.Errorf("%w %q: no digit", ErrInvalidPicture, str)
+		}
+
+		pic.length = pic.digits
+
+		return pic, nil
+	}
+
+	// Alphanumeric or edited picture: each symbol (except the implied decimal
+	// point and the sign) takes one character.
+	pic.length = len(strings.NewReplacer("V", "", "S", "").Replace(string(symbols)))
+	pic.digits, pic.scale, pic.signed = 0, 0, false
+
+	if pic.length == 0 {
+		return nil, fmt.Errorf("%w %q: empty picture", ErrInvalidPicture, str)
+	}
+
+	return pic, nil
+}
+
+// expandPicture expands the repetition factors of the given picture (ex:
+// X(3) becomes XXX).
+func expandPicture(str string) ([]byte, error) {
+	const maxRepeat = 1 << 20
+
+	upper := strings.ToUpper(str)
+	symbols := make([]byte, 0, len(upper))
+
+	for i := 0; i < len(upper); i++ {
+		if upper[i] != '(' {
+			symbols = append(symbols, upper[i])
+
+			continue
+		}
+
+		end := strings.IndexByte(upper[i:], ')')
+		if end < 0 || len(symbols) == 0 {
+			return nil, fmt.Errorf("%w %q: malformed repetition", ErrInvalidPicture, str)
+		}
+
+		count, err := strconv.Atoi(upper[i+1 : i+end])
+		if err != nil || count < 1 || count > maxRepeat {
+			return nil, fmt.Errorf("%w %q: invalid repetition factor", ErrInvalidPicture, str)
+		}
+
+		last := symbols[len(symbols)-1]
+		for range count - 1 {
+			symbols = append(symbols, last)
+		}
+
+		i += end
+	}
+
+	return symbols, nil
+}
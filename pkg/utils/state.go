@@ -62,6 +62,11 @@ const (
 
 	// StateError means that the service is not properly functioning.
 	StateError
+
+	// StateDraining means that the service is still running, but is not
+	// accepting any new transfer, and will stop once its running transfers
+	// are done.
+	StateDraining
 )
 
 func (s StateCode) MarshalJSON() ([]byte, error) {
@@ -77,6 +82,8 @@ func (s StateCode) String() string {
 		return "Running"
 	case StateError:
 		return "Error"
+	case StateDraining:
+		return "Draining"
 	default:
 		return "Unknown"
 	}